@@ -0,0 +1,41 @@
+package entity
+
+// OfficerSuggestion is an officer the assignment engine proposes for a
+// company, with why.
+type OfficerSuggestion struct {
+	Username string `json:"username"`
+	// Score weighs up load, drive type specialisation and past relationship
+	// with the company, from 0 to 1; the best fit scores highest.
+	Score float64 `json:"score"`
+	// Load is how many active companies the officer is assigned to.
+	Load    int      `json:"load"`
+	Reasons []string `json:"reasons"`
+}
+
+// RebalanceMove hands one company from an overloaded officer to a less
+// loaded one. Version is the company's version when the move was planned;
+// the move is not applied if the company has changed since.
+type RebalanceMove struct {
+	CompanyID   string `json:"companyId"`
+	CompanyName string `json:"companyName"`
+	Version     int    `json:"version"`
+	From        string `json:"from"`
+	To          string `json:"to"`
+	Reason      string `json:"reason"`
+}
+
+// RebalancePlan previews the moves that even out officer workloads.
+// TargetLoad is the most active companies any officer should end up with.
+type RebalancePlan struct {
+	TargetLoad int              `json:"targetLoad"`
+	Before     map[string]int   `json:"before"`
+	After      map[string]int   `json:"after"`
+	Moves      []*RebalanceMove `json:"moves"`
+}
+
+// RebalanceResult is what became of one move when applying a plan.
+type RebalanceResult struct {
+	RebalanceMove
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
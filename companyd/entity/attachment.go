@@ -0,0 +1,19 @@
+package entity
+
+// Attachment is a document kept with a company, such as a job description,
+// an MoU or an offer letter template. Its contents live in a blob store
+// under StorageKey.
+type Attachment struct {
+	ID        string `json:"id"`
+	CompanyID string `json:"companyId"`
+	Filename  string `json:"filename"`
+	// ContentType is sniffed from the contents on upload, never taken from
+	// the client.
+	ContentType string `json:"contentType"`
+	Size        int64  `json:"size"`
+	// Checksum is the SHA-256 of the contents, in lowercase hex.
+	Checksum   string `json:"checksum"`
+	StorageKey string `json:"-"`
+	UploadedBy string `json:"uploadedBy"`
+	CreatedAt  string `json:"createdAt"`
+}
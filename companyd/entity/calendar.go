@@ -6,6 +6,11 @@ type Event struct {
 	Type        string `json:"type"`
 	Title       string `json:"title"`
 	Description string `json:"description"`
-	CreatedBy   string `json:"created_by"`
-	CreatedAt   string `json:"created_at"`
+	// CompanyID ties the event to a company, or is empty. Events outlive
+	// their company and move with it in a merge.
+	CompanyID string `json:"company_id"`
+	// Season is the academic year the event's date falls in.
+	Season    string `json:"season"`
+	CreatedBy string `json:"created_by"`
+	CreatedAt string `json:"created_at"`
 }
@@ -1,19 +1,169 @@
 package entity
 
+import "time"
+
 type Company struct {
-	ID              string   `json:"id"`
-	CompanyName     string   `json:"companyName"`
-	CompanyAddress  string   `json:"companyAddress"`
-	Drive           string   `json:"drive"`
-	TypeOfDrive     string   `json:"typeOfDrive"`
-	FollowUp        string   `json:"followUp"`
-	IsContacted     bool     `json:"isContacted"`
-	Remarks         string   `json:"remarks"`
-	ContactDetails  string   `json:"contactDetails"`
-	HR1Details      string   `json:"hr1Details"`
-	HR2Details      string   `json:"hr2Details"`
-	Package         string   `json:"package"`
-	AssignedOfficer []string `json:"assignedOfficer"`
-	CreatedAt       string   `json:"createdAt"`
-	UpdatedAt       string   `json:"updatedAt"`
+	ID             string `json:"id"`
+	CompanyName    string `json:"companyName"`
+	CompanyAddress string `json:"companyAddress"`
+	Drive          string `json:"drive"`
+	TypeOfDrive    string `json:"typeOfDrive"`
+	FollowUp       string `json:"followUp"`
+	IsContacted    bool   `json:"isContacted"`
+	PipelineStatus string `json:"pipelineStatus"`
+	// Season is the academic year the company is recruited for, such as
+	// 2026-27. CarriedFrom is the company of an earlier season it was
+	// carried forward from, or empty.
+	Season          string       `json:"season"`
+	CarriedFrom     string       `json:"carriedFrom"`
+	Remarks         string       `json:"remarks"`
+	ContactDetails  string       `json:"contactDetails"`
+	HR1Details      string       `json:"hr1Details"`
+	HR2Details      string       `json:"hr2Details"`
+	Package         string       `json:"package"`
+	Compensation    Compensation `json:"compensation"`
+	AssignedOfficer []string     `json:"assignedOfficer"`
+	// CustomFields holds the values of the admin-defined fields and Tags
+	// the free-form labels people put on the company.
+	CustomFields CustomValues `json:"customFields"`
+	Tags         []string     `json:"tags"`
+	Version      int          `json:"version"`
+	Contacts     []*Contact   `json:"contacts"`
+	// LastInteractionAt and LastInteractionOutcome summarise the latest
+	// entry of the company's interaction timeline; the time is nil when
+	// nothing has been logged.
+	LastInteractionAt      *time.Time `json:"lastInteractionAt"`
+	LastInteractionOutcome string     `json:"lastInteractionOutcome"`
+	// ArchivedAt is set while the company has stopped recruiting; archived
+	// companies are left out of listings unless asked for.
+	ArchivedAt *time.Time `json:"archivedAt"`
+	ArchivedBy string     `json:"archivedBy"`
+	// DeletedAt is set while the company is in the trash, from which it can
+	// be restored until it is purged.
+	DeletedAt *time.Time `json:"deletedAt"`
+	DeletedBy string     `json:"deletedBy"`
+	CreatedAt string     `json:"createdAt"`
+	UpdatedAt string     `json:"updatedAt"`
+}
+
+// Compensation is a structured package. Base, Variable, Min and Max are
+// amounts in Currency per Unit; Stipend is always per month. Company.Package
+// keeps the text shown to people.
+type Compensation struct {
+	Base     *float64 `json:"base"`
+	Variable *float64 `json:"variable"`
+	Stipend  *float64 `json:"stipend"`
+	Currency string   `json:"currency"`
+	Unit     string   `json:"unit"`
+	Min      *float64 `json:"min"`
+	Max      *float64 `json:"max"`
+	// NeedsReview is set when the package text could not be parsed and
+	// someone should enter the figures by hand.
+	NeedsReview bool `json:"needsReview"`
+}
+
+// Copy returns c with its amounts copied, so the result shares no pointers
+// with c.
+func (c Compensation) Copy() Compensation {
+	copyAmount := func(v *float64) *float64 {
+		if v == nil {
+			return nil
+		}
+		copied := *v
+		return &copied
+	}
+	c.Base = copyAmount(c.Base)
+	c.Variable = copyAmount(c.Variable)
+	c.Stipend = copyAmount(c.Stipend)
+	c.Min = copyAmount(c.Min)
+	c.Max = copyAmount(c.Max)
+	return c
+}
+
+// CompanyUpdate holds the fields to change on a company. Nil fields are left
+// untouched, so the same type serves full replacements and partial patches.
+type CompanyUpdate struct {
+	CompanyName     *string
+	CompanyAddress  *string
+	Drive           *string
+	TypeOfDrive     *string
+	FollowUp        *string
+	IsContacted     *bool
+	Remarks         *string
+	ContactDetails  *string
+	HR1Details      *string
+	HR2Details      *string
+	Package         *string
+	Compensation    *Compensation
+	AssignedOfficer *[]string
+	// CustomFields and Tags replace the company's custom field values and
+	// tags as a whole.
+	CustomFields *CustomValues
+	Tags         *[]string
+	// PipelineStatus moves the company to another recruitment stage and is
+	// recorded as a status transition; set IsContacted with it.
+	PipelineStatus *string
+}
+
+// ApplyTo copies every set field of u onto c.
+func (u CompanyUpdate) ApplyTo(c *Company) {
+	setString := func(dst *string, src *string) {
+		if src != nil {
+			*dst = *src
+		}
+	}
+	setString(&c.CompanyName, u.CompanyName)
+	setString(&c.CompanyAddress, u.CompanyAddress)
+	setString(&c.Drive, u.Drive)
+	setString(&c.TypeOfDrive, u.TypeOfDrive)
+	setString(&c.FollowUp, u.FollowUp)
+	if u.IsContacted != nil {
+		c.IsContacted = *u.IsContacted
+	}
+	setString(&c.Remarks, u.Remarks)
+	setString(&c.ContactDetails, u.ContactDetails)
+	setString(&c.HR1Details, u.HR1Details)
+	setString(&c.HR2Details, u.HR2Details)
+	setString(&c.Package, u.Package)
+	if u.Compensation != nil {
+		c.Compensation = u.Compensation.Copy()
+	}
+	if u.AssignedOfficer != nil {
+		c.AssignedOfficer = append([]string{}, (*u.AssignedOfficer)...)
+	}
+	if u.CustomFields != nil {
+		c.CustomFields = u.CustomFields.Copy()
+	}
+	if u.Tags != nil {
+		c.Tags = append([]string{}, (*u.Tags)...)
+	}
+	setString(&c.PipelineStatus, u.PipelineStatus)
+}
+
+// CompanySearchResult is one hit from a company search. Snippet marks the
+// matched terms with <mark> tags; Fuzzy is set for typo-tolerant matches.
+type CompanySearchResult struct {
+	Company *Company `json:"company"`
+	Rank    float64  `json:"rank"`
+	Snippet string   `json:"snippet"`
+	Fuzzy   bool     `json:"fuzzy"`
+}
+
+// PackageStats summarises the packages of one group of companies. Group
+// fields are nil when the statistics are not grouped by them. Amounts are in
+// lakhs per annum for CTC and in currency units per month for stipends.
+type PackageStats struct {
+	TypeOfDrive *string `json:"typeOfDrive,omitempty"`
+	Season      *string `json:"season,omitempty"`
+	// Count is the number of companies with a figure; NeedsReview counts
+	// companies in the group whose package could not be parsed.
+	Count       int     `json:"count"`
+	NeedsReview int     `json:"needsReview"`
+	Mean        float64 `json:"mean"`
+	Median      float64 `json:"median"`
+	P25         float64 `json:"p25"`
+	P75         float64 `json:"p75"`
+	P90         float64 `json:"p90"`
+	Min         float64 `json:"min"`
+	Max         float64 `json:"max"`
 }
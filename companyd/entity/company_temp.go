@@ -16,8 +16,10 @@ type CompanyTemp struct {
 	Package         string   `json:"package"`
 	AssignedOfficer []string `json:"assignedOfficer"`
 	Status          string   `json:"status"`
-	CreatedBy       string   `json:"createdBy"`
-	CreatedAt       string   `json:"createdAt"`
-	UpdatedAt       string   `json:"updatedAt"`
+	BaseVersion     int      `json:"baseVersion"`
+	// Season is the season of the company the proposal is for.
+	Season    string `json:"season"`
+	CreatedBy string `json:"createdBy"`
+	CreatedAt string `json:"createdAt"`
+	UpdatedAt string `json:"updatedAt"`
 }
-
@@ -0,0 +1,16 @@
+package entity
+
+// Contact is a person at a company, typically someone in HR.
+type Contact struct {
+	ID          string `json:"id"`
+	CompanyID   string `json:"companyId"`
+	Name        string `json:"name"`
+	Designation string `json:"designation"`
+	Email       string `json:"email"`
+	Phone       string `json:"phone"`
+	LinkedIn    string `json:"linkedIn"`
+	IsPrimary   bool   `json:"isPrimary"`
+	Notes       string `json:"notes"`
+	CreatedAt   string `json:"createdAt"`
+	UpdatedAt   string `json:"updatedAt"`
+}
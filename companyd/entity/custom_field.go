@@ -0,0 +1,75 @@
+package entity
+
+import "encoding/json"
+
+// CustomField is a company attribute defined by an admin, such as a sector
+// or a bond requirement. Companies hold its value under Key in
+// Company.CustomFields.
+type CustomField struct {
+	ID string `json:"id"`
+	// Key names the field in CustomFields and in list filters; it cannot
+	// change once the field exists.
+	Key   string `json:"key"`
+	Label string `json:"label"`
+	// Type is text, number, date, enum or multi_select, and cannot change
+	// once the field exists.
+	Type string `json:"type"`
+	// Options are the choices of an enum or multi_select field.
+	Options []string `json:"options"`
+	// Required fields must have a value whenever a company's custom fields
+	// are set.
+	Required  bool   `json:"required"`
+	CreatedAt string `json:"createdAt"`
+	UpdatedAt string `json:"updatedAt"`
+}
+
+// CustomValues maps custom field keys to values: a string for text, enum
+// and date (YYYY-MM-DD) fields, a float64 for number fields and a []string
+// for multi_select fields. Fields without a value are left out.
+type CustomValues map[string]interface{}
+
+// UnmarshalJSON reads multi_select values as []string rather than
+// []interface{}, so that decoded values compare equal to the ones stored.
+func (v *CustomValues) UnmarshalJSON(data []byte) error {
+	var raw map[string]interface{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	if raw == nil {
+		*v = nil
+		return nil
+	}
+	values := CustomValues{}
+	for key, value := range raw {
+		if list, ok := value.([]interface{}); ok {
+			options := make([]string, 0, len(list))
+			for _, item := range list {
+				if s, ok := item.(string); ok {
+					options = append(options, s)
+				}
+			}
+			value = options
+		}
+		values[key] = value
+	}
+	*v = values
+	return nil
+}
+
+// Copy returns v with its lists copied, so the result shares nothing with v.
+func (v CustomValues) Copy() CustomValues {
+	copied := CustomValues{}
+	for key, value := range v {
+		if list, ok := value.([]string); ok {
+			value = append([]string{}, list...)
+		}
+		copied[key] = value
+	}
+	return copied
+}
+
+// TagCount is a tag with the number of companies carrying it.
+type TagCount struct {
+	Tag       string `json:"tag"`
+	Companies int    `json:"companies"`
+}
@@ -0,0 +1,24 @@
+package entity
+
+import "time"
+
+// Drive is one recruitment visit by a company, such as an internship drive
+// in July or a full-time drive in December.
+type Drive struct {
+	ID        string `json:"id"`
+	CompanyID string `json:"companyId"`
+	// Type is on-campus, off-campus, pool or virtual.
+	Type string `json:"type"`
+	// Season is the academic year, such as "2026-27", or empty when not
+	// known.
+	Season   string     `json:"season"`
+	StartsOn *time.Time `json:"startsOn"`
+	EndsOn   *time.Time `json:"endsOn"`
+	Roles    []string   `json:"roles"`
+	Package  string     `json:"package"`
+	// Status is planned, scheduled, ongoing, completed or cancelled.
+	Status    string `json:"status"`
+	Notes     string `json:"notes"`
+	CreatedAt string `json:"createdAt"`
+	UpdatedAt string `json:"updatedAt"`
+}
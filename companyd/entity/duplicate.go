@@ -0,0 +1,16 @@
+package entity
+
+// DuplicateMatch is an existing company that probably is the same recruiter
+// as a given name.
+type DuplicateMatch struct {
+	Company *Company `json:"company"`
+	// Similarity is 1 when the names are equal once normalised, and the
+	// trigram similarity of the normalised names otherwise.
+	Similarity float64 `json:"similarity"`
+}
+
+// DuplicateGroup is a set of companies that probably are the same recruiter,
+// oldest first.
+type DuplicateGroup struct {
+	Companies []*Company `json:"companies"`
+}
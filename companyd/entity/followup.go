@@ -0,0 +1,31 @@
+package entity
+
+import "time"
+
+// FollowUp is a dated task to get back to a company, owned by one officer.
+type FollowUp struct {
+	ID          string     `json:"id"`
+	CompanyID   string     `json:"companyId"`
+	Officer     string     `json:"officer"`
+	DueAt       time.Time  `json:"dueAt"`
+	Note        string     `json:"note"`
+	Status      string     `json:"status"`
+	CompletedBy string     `json:"completedBy"`
+	CompletedAt *time.Time `json:"completedAt"`
+	// RemindedAt is when the officer was sent a reminder, or nil if the
+	// reminder is still to come.
+	RemindedAt *time.Time `json:"remindedAt"`
+	CreatedAt  string     `json:"createdAt"`
+	UpdatedAt  string     `json:"updatedAt"`
+}
+
+// Notification is a message for one user, such as a follow-up reminder.
+type Notification struct {
+	ID         string `json:"id"`
+	Recipient  string `json:"recipient"`
+	FollowUpID string `json:"followUpId"`
+	CompanyID  string `json:"companyId"`
+	Message    string `json:"message"`
+	Read       bool   `json:"read"`
+	CreatedAt  string `json:"createdAt"`
+}
@@ -0,0 +1,106 @@
+package entity
+
+// CompanyChange says who changed a company and how.
+type CompanyChange struct {
+	ChangedBy string `json:"changedBy"`
+	// Source is create, import, edit, proposal, revert, merge, status,
+	// rollover or baseline; status marks a move through the pipeline,
+	// rollover a company carried forward from an earlier season, and
+	// baseline the version a company was at when history recording began.
+	Source string `json:"source"`
+	// ProposalID is the approved proposal, for Source proposal.
+	ProposalID string `json:"proposalId,omitempty"`
+	// RevertedTo is the version that was restored, for Source revert.
+	RevertedTo int `json:"revertedTo,omitempty"`
+	// MergedFrom is the duplicate company merged in, for Source merge.
+	MergedFrom string `json:"mergedFrom,omitempty"`
+}
+
+// CompanyRevision is one version of a company in its history.
+type CompanyRevision struct {
+	ID        string `json:"id"`
+	CompanyID string `json:"companyId"`
+	Version   int    `json:"version"`
+	CompanyChange
+	Snapshot CompanySnapshot `json:"snapshot"`
+	// Changes lists the fields that differ from the previous revision; it
+	// is empty for the first one.
+	Changes   []FieldChange `json:"changes"`
+	CreatedAt string        `json:"createdAt"`
+}
+
+// FieldChange is the before and after value of one company field, named as
+// in Company's JSON.
+type FieldChange struct {
+	Field  string      `json:"field"`
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
+
+// CompanySnapshot holds the editable fields of a company at one version.
+type CompanySnapshot struct {
+	CompanyName     string       `json:"companyName"`
+	CompanyAddress  string       `json:"companyAddress"`
+	Drive           string       `json:"drive"`
+	TypeOfDrive     string       `json:"typeOfDrive"`
+	FollowUp        string       `json:"followUp"`
+	IsContacted     bool         `json:"isContacted"`
+	PipelineStatus  string       `json:"pipelineStatus"`
+	Remarks         string       `json:"remarks"`
+	ContactDetails  string       `json:"contactDetails"`
+	HR1Details      string       `json:"hr1Details"`
+	HR2Details      string       `json:"hr2Details"`
+	Package         string       `json:"package"`
+	Compensation    Compensation `json:"compensation"`
+	AssignedOfficer []string     `json:"assignedOfficer"`
+	CustomFields    CustomValues `json:"customFields"`
+	Tags            []string     `json:"tags"`
+}
+
+// NewCompanySnapshot copies the editable fields of c.
+func NewCompanySnapshot(c *Company) CompanySnapshot {
+	return CompanySnapshot{
+		CompanyName:     c.CompanyName,
+		CompanyAddress:  c.CompanyAddress,
+		Drive:           c.Drive,
+		TypeOfDrive:     c.TypeOfDrive,
+		FollowUp:        c.FollowUp,
+		IsContacted:     c.IsContacted,
+		PipelineStatus:  c.PipelineStatus,
+		Remarks:         c.Remarks,
+		ContactDetails:  c.ContactDetails,
+		HR1Details:      c.HR1Details,
+		HR2Details:      c.HR2Details,
+		Package:         c.Package,
+		Compensation:    c.Compensation.Copy(),
+		AssignedOfficer: append([]string{}, c.AssignedOfficer...),
+		CustomFields:    c.CustomFields.Copy(),
+		Tags:            append([]string{}, c.Tags...),
+	}
+}
+
+// Update returns an update that sets every field to its value in s, except
+// the pipeline status and IsContacted, which only change through allowed
+// transitions.
+func (s CompanySnapshot) Update() CompanyUpdate {
+	compensation := s.Compensation.Copy()
+	officers := append([]string{}, s.AssignedOfficer...)
+	customFields := s.CustomFields.Copy()
+	tags := append([]string{}, s.Tags...)
+	return CompanyUpdate{
+		CompanyName:     &s.CompanyName,
+		CompanyAddress:  &s.CompanyAddress,
+		Drive:           &s.Drive,
+		TypeOfDrive:     &s.TypeOfDrive,
+		FollowUp:        &s.FollowUp,
+		Remarks:         &s.Remarks,
+		ContactDetails:  &s.ContactDetails,
+		HR1Details:      &s.HR1Details,
+		HR2Details:      &s.HR2Details,
+		Package:         &s.Package,
+		Compensation:    &compensation,
+		AssignedOfficer: &officers,
+		CustomFields:    &customFields,
+		Tags:            &tags,
+	}
+}
@@ -0,0 +1,36 @@
+package entity
+
+// ImportReport is the outcome of validating, and possibly committing, a
+// spreadsheet of companies.
+type ImportReport struct {
+	// Committed is true once every importable row has been created.
+	Committed bool               `json:"committed"`
+	Total     int                `json:"total"`
+	Invalid   int                `json:"invalid"`
+	Skipped   int                `json:"skipped"`
+	Created   int                `json:"created"`
+	Rows      []*ImportRowResult `json:"rows"`
+}
+
+// ImportRowResult is one spreadsheet row as it would be, or was, imported.
+type ImportRowResult struct {
+	// Line is the row number in the spreadsheet, counting the header as 1.
+	Line    int             `json:"line"`
+	Company CompanySnapshot `json:"company"`
+	// ID is set on rows created by a committed import.
+	ID       string        `json:"id,omitempty"`
+	Errors   []ImportError `json:"errors"`
+	Warnings []string      `json:"warnings"`
+	// Duplicates are existing companies the row probably duplicates.
+	Duplicates []*DuplicateMatch `json:"duplicates"`
+	// Skipped rows are exact duplicates of an existing company, left out
+	// when the caller asked to skip them.
+	Skipped bool `json:"skipped"`
+}
+
+// ImportError is a problem that stops a row from being imported.
+type ImportError struct {
+	Field   string `json:"field"`
+	Value   string `json:"value"`
+	Message string `json:"message"`
+}
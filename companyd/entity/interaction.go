@@ -0,0 +1,18 @@
+package entity
+
+import "time"
+
+// Interaction is one logged contact with a company, such as a call or a
+// meeting. Interactions are append-only, so together they form the
+// company's timeline.
+type Interaction struct {
+	ID         string    `json:"id"`
+	CompanyID  string    `json:"companyId"`
+	Type       string    `json:"type"`
+	OccurredAt time.Time `json:"occurredAt"`
+	Officer    string    `json:"officer"`
+	Outcome    string    `json:"outcome"`
+	Notes      string    `json:"notes"`
+	NextStep   string    `json:"nextStep"`
+	CreatedAt  string    `json:"createdAt"`
+}
@@ -0,0 +1,14 @@
+package entity
+
+import "time"
+
+// OfficerAssignment is a user assigned to look after a company. A company's
+// AssignedOfficer lists the same users by username, primary officer first.
+type OfficerAssignment struct {
+	UserID   string `json:"userId"`
+	Username string `json:"username"`
+	// Role is primary for the first officer and secondary for the rest.
+	Role       string    `json:"role"`
+	AssignedAt time.Time `json:"assignedAt"`
+	AssignedBy string    `json:"assignedBy"`
+}
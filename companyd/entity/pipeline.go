@@ -0,0 +1,57 @@
+package entity
+
+import "time"
+
+// Pipeline is the recruitment pipeline companies move through. Stages are in
+// funnel order, the first being where new companies start; Exits are stages
+// that leave the funnel, such as not_interested. Transitions lists the
+// stages each stage may move to.
+type Pipeline struct {
+	Stages      []string            `json:"stages"`
+	Exits       []string            `json:"exits"`
+	Transitions map[string][]string `json:"transitions"`
+}
+
+// StatusTransition records a company moving from one pipeline stage to
+// another. From is empty for the stage a company started at.
+type StatusTransition struct {
+	ID        string    `json:"id"`
+	CompanyID string    `json:"companyId"`
+	From      string    `json:"from"`
+	To        string    `json:"to"`
+	ChangedBy string    `json:"changedBy"`
+	ChangedAt time.Time `json:"changedAt"`
+}
+
+// PipelineHistory is a company's pipeline stage, the stages it can move to
+// next and how it got where it is, oldest transition first.
+type PipelineHistory struct {
+	Status      string              `json:"status"`
+	Allowed     []string            `json:"allowed"`
+	Transitions []*StatusTransition `json:"transitions"`
+}
+
+// Funnel reports how far the companies of a season got through the
+// pipeline. From and To are the season's bounds.
+type Funnel struct {
+	Season    string        `json:"season"`
+	From      time.Time     `json:"from"`
+	To        time.Time     `json:"to"`
+	Companies int           `json:"companies"`
+	Stages    []FunnelStage `json:"stages"`
+}
+
+// FunnelStage counts the companies at one stage of a Funnel. Reached counts
+// those that got at least this far and Current those at this stage now. For
+// funnel stages, Dropped counts the companies that left the funnel from this
+// stage, and ConversionRate is Reached as a fraction of the previous stage's
+// Reached; it is nil for the first stage, exits and after a stage no company
+// reached.
+type FunnelStage struct {
+	Stage          string   `json:"stage"`
+	Exit           bool     `json:"exit"`
+	Reached        int      `json:"reached"`
+	Current        int      `json:"current"`
+	Dropped        int      `json:"dropped"`
+	ConversionRate *float64 `json:"conversionRate"`
+}
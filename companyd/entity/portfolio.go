@@ -0,0 +1,27 @@
+package entity
+
+// OfficerPortfolio is what an officer starts the day with: their active
+// companies and what needs their attention.
+type OfficerPortfolio struct {
+	Officer string `json:"officer"`
+	// Companies groups the officer's active companies by pipeline stage.
+	Companies        map[string][]*Company `json:"companies"`
+	Stats            PortfolioStats        `json:"stats"`
+	OverdueFollowUps []*FollowUp           `json:"overdueFollowUps"`
+	// PendingProposals are the changes the officer proposed that await
+	// approval.
+	PendingProposals []*CompanyTemp `json:"pendingProposals"`
+	// UpcomingEvents are events tied to the officer's companies, soonest
+	// first.
+	UpcomingEvents []*Event `json:"upcomingEvents"`
+}
+
+// PortfolioStats counts the contents of an OfficerPortfolio.
+type PortfolioStats struct {
+	Companies        int `json:"companies"`
+	Contacted        int `json:"contacted"`
+	NotContacted     int `json:"notContacted"`
+	OverdueFollowUps int `json:"overdueFollowUps"`
+	PendingProposals int `json:"pendingProposals"`
+	UpcomingEvents   int `json:"upcomingEvents"`
+}
@@ -0,0 +1,63 @@
+package entity
+
+import "time"
+
+// Season is an academic year, such as 2026-27, running from July to June.
+// Companies, proposals and events each belong to one season; the active
+// season is the one listings show unless asked for another.
+type Season struct {
+	Name   string    `json:"name"`
+	Active bool      `json:"active"`
+	From   time.Time `json:"from"`
+	To     time.Time `json:"to"`
+	// Companies counts the season's companies, less those in the trash.
+	Companies int `json:"companies"`
+}
+
+// SeasonRollover is the outcome of carrying a season's companies forward
+// into the next. Carried are the new companies; Skipped lists the companies
+// left behind because they were carried forward before.
+type SeasonRollover struct {
+	From    string     `json:"from"`
+	To      string     `json:"to"`
+	Carried []*Company `json:"carried"`
+	Skipped []string   `json:"skipped"`
+}
+
+// SeasonReport sums up one season. Companies excludes those in the trash;
+// CarriedForward counts the ones carried over from an earlier season and
+// Stages counts them by pipeline stage. MedianPackage is the median annual
+// CTC in lakhs of packages in the default currency, nil when no company has
+// one.
+type SeasonReport struct {
+	Season         string         `json:"season"`
+	Active         bool           `json:"active"`
+	Companies      int            `json:"companies"`
+	CarriedForward int            `json:"carriedForward"`
+	Contacted      int            `json:"contacted"`
+	Stages         map[string]int `json:"stages"`
+	Drives         int            `json:"drives"`
+	Events         int            `json:"events"`
+	Proposals      int            `json:"proposals"`
+	MedianPackage  *float64       `json:"medianPackage"`
+}
+
+// SeasonChange compares a season with the one before it in a
+// SeasonComparison. Retained counts the companies of From carried forward
+// into To.
+type SeasonChange struct {
+	From          string   `json:"from"`
+	To            string   `json:"to"`
+	Companies     int      `json:"companies"`
+	Contacted     int      `json:"contacted"`
+	Drives        int      `json:"drives"`
+	Events        int      `json:"events"`
+	Retained      int      `json:"retained"`
+	MedianPackage *float64 `json:"medianPackage"`
+}
+
+// SeasonComparison reports seasons side by side, in the order asked for.
+type SeasonComparison struct {
+	Seasons []*SeasonReport `json:"seasons"`
+	Changes []SeasonChange  `json:"changes"`
+}
@@ -0,0 +1,164 @@
+package companyHandler
+
+import (
+	companyPresenter "backend/companyd/presenter"
+	"backend/companyd/usecase/company"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+// requireAssigner lets only admins and managers, who see every officer's
+// workload, through to the assignment engine. It writes the error response
+// itself and reports whether the caller may proceed.
+func requireAssigner(w http.ResponseWriter, r *http.Request) (caller, bool) {
+	who, ok := requireCaller(w, r, true)
+	if !ok {
+		return who, false
+	}
+	if !who.seesAllCompanies() {
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(map[string]string{
+			"error": "Only admins and managers can assign officers",
+		})
+		return who, false
+	}
+	return who, true
+}
+
+// SuggestOfficers ranks the officers who could take on a company, best fit
+// first, with the reasons for each score.
+func SuggestOfficers(service company.Usecase, w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if _, ok := requireAssigner(w, r); !ok {
+		return
+	}
+
+	suggestions, err := service.SuggestOfficers(mux.Vars(r)["id"])
+	if errors.Is(err, company.ErrNotFound) {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{
+			"error": "Company not found",
+		})
+		return
+	}
+	if err != nil {
+		log.Printf("Error suggesting officers: %v", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(suggestions)
+}
+
+// AssignOfficer makes the best suggested officer the company's primary
+// officer. Like any edit the request must carry the current ETag.
+func AssignOfficer(service company.Usecase, w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	id := mux.Vars(r)["id"]
+
+	who, ok := requireAssigner(w, r)
+	if !ok {
+		return
+	}
+	version, ok := requireIfMatch(w, r)
+	if !ok {
+		return
+	}
+
+	updated, officer, err := service.AssignOfficer(id, version, who.Username)
+	if errors.Is(err, company.ErrVersionMismatch) {
+		writeVersionConflict(service, w, id)
+		return
+	}
+	if errors.Is(err, company.ErrNotFound) {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{
+			"error": "Company not found",
+		})
+		return
+	}
+	if errors.Is(err, company.ErrNoCandidate) {
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(map[string]string{
+			"error": err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		log.Printf("Error assigning an officer: %v", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	w.Header().Set("ETag", etag(updated.Version))
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(companyPresenter.AssignedOfficer{Company: updated, Officer: officer})
+}
+
+// PreviewRebalance lists the moves that would even out officer workloads,
+// without applying any.
+func PreviewRebalance(service company.Usecase, w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if _, ok := requireAssigner(w, r); !ok {
+		return
+	}
+
+	plan, err := service.PlanRebalance()
+	if err != nil {
+		log.Printf("Error planning a rebalance: %v", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(plan)
+}
+
+// ApplyRebalance applies previewed moves and reports what became of each.
+// Moves whose company changed after the preview are skipped as stale.
+func ApplyRebalance(service company.Usecase, w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	who, ok := requireAssigner(w, r)
+	if !ok {
+		return
+	}
+
+	var req companyPresenter.ApplyRebalance
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || len(req.Moves) == 0 {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{
+			"error": "moves must list the moves to apply",
+		})
+		return
+	}
+
+	results, err := service.ApplyRebalance(req.Moves, who.Username)
+	if err != nil {
+		log.Printf("Error applying a rebalance: %v", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(results)
+}
@@ -0,0 +1,249 @@
+package companyHandler
+
+import (
+	"backend/companyd/entity"
+	"backend/companyd/usecase/company"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+// multipartOverhead is how far an upload's body may exceed the attachment
+// size limit, for part headers and the checksum field.
+const multipartOverhead = 64 << 10
+
+// writeAttachmentError maps attachment usecase errors to responses.
+func writeAttachmentError(w http.ResponseWriter, err error) {
+	var tooLarge *http.MaxBytesError
+	switch {
+	case errors.Is(err, company.ErrNotFound):
+		w.WriteHeader(http.StatusNotFound)
+		err = errors.New("Attachment not found")
+	case errors.Is(err, company.ErrUnknownCompany):
+		w.WriteHeader(http.StatusNotFound)
+		err = errors.New("Company not found")
+	case errors.Is(err, company.ErrInvalidAttachment), errors.Is(err, company.ErrChecksumMismatch):
+		w.WriteHeader(http.StatusBadRequest)
+	case errors.Is(err, company.ErrAttachmentTooLarge):
+		w.WriteHeader(http.StatusRequestEntityTooLarge)
+	case errors.As(err, &tooLarge):
+		w.WriteHeader(http.StatusRequestEntityTooLarge)
+		err = company.ErrAttachmentTooLarge
+	case errors.Is(err, company.ErrUnsupportedAttachment):
+		w.WriteHeader(http.StatusUnsupportedMediaType)
+	case errors.Is(err, company.ErrNoBlobStore):
+		w.WriteHeader(http.StatusServiceUnavailable)
+	default:
+		log.Printf("Error handling attachment: %v", err)
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+	json.NewEncoder(w).Encode(map[string]string{
+		"error": err.Error(),
+	})
+}
+
+// requireVisibleCompany identifies the caller and checks they may see the
+// company, which is who may see, add and remove its attachments. It writes
+// the error response itself and reports whether the caller may proceed.
+func requireVisibleCompany(service company.Usecase, w http.ResponseWriter, r *http.Request, companyID string, named bool) (caller, bool) {
+	who, ok := requireCaller(w, r, named)
+	if !ok {
+		return who, false
+	}
+	return who, canSeeCompany(service, w, who, companyID)
+}
+
+// canSeeCompany reports whether who may see the company, writing the error
+// response when not.
+func canSeeCompany(service company.Usecase, w http.ResponseWriter, who caller, companyID string) bool {
+	found, err := service.GetCompany(companyID)
+	if errors.Is(err, company.ErrNotFound) {
+		err = company.ErrUnknownCompany
+	}
+	if err != nil {
+		writeAttachmentError(w, err)
+		return false
+	}
+	if !who.canSee(found) {
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(map[string]string{
+			"error": "You can only see attachments of companies assigned to you",
+		})
+		return false
+	}
+	return true
+}
+
+// visibleAttachment loads an attachment whose company the caller may see.
+func visibleAttachment(service company.Usecase, w http.ResponseWriter, r *http.Request) (*entity.Attachment, bool) {
+	who, ok := requireCaller(w, r, false)
+	if !ok {
+		return nil, false
+	}
+	attachment, err := service.GetAttachment(mux.Vars(r)["id"])
+	if err != nil {
+		writeAttachmentError(w, err)
+		return nil, false
+	}
+	return attachment, canSeeCompany(service, w, who, attachment.CompanyID)
+}
+
+// UploadAttachment attaches the multipart field "file" to a company. An
+// optional "checksum" field before it holds the file's SHA-256 in hex, which
+// the upload is checked against.
+func UploadAttachment(service company.Usecase, w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	companyID := mux.Vars(r)["id"]
+	who, ok := requireVisibleCompany(service, w, r, companyID, true)
+	if !ok {
+		return
+	}
+
+	if limit := service.MaxAttachmentSize(); limit > 0 {
+		r.Body = http.MaxBytesReader(w, r.Body, limit+multipartOverhead)
+	}
+	reader, err := r.MultipartReader()
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{
+			"error": "Request must be multipart/form-data with a file field",
+		})
+		return
+	}
+
+	upload := company.AttachmentUpload{CompanyID: companyID, UploadedBy: who.Username}
+	for {
+		part, err := reader.NextPart()
+		if errors.Is(err, io.EOF) {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{
+				"error": "file is required",
+			})
+			return
+		}
+		if err != nil {
+			writeAttachmentError(w, invalidUpload(err))
+			return
+		}
+
+		switch part.FormName() {
+		case "checksum":
+			data, err := io.ReadAll(io.LimitReader(part, multipartOverhead))
+			if err != nil {
+				writeAttachmentError(w, invalidUpload(err))
+				return
+			}
+			upload.Checksum = string(data)
+		case "file":
+			upload.Filename = part.FileName()
+			upload.Content = part
+			created, err := service.UploadAttachment(upload)
+			if err != nil {
+				writeAttachmentError(w, err)
+				return
+			}
+			w.WriteHeader(http.StatusCreated)
+			json.NewEncoder(w).Encode(created)
+			return
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{
+				"error": "unexpected form field " + strconv.Quote(part.FormName()) + "; send checksum and file",
+			})
+			return
+		}
+	}
+}
+
+// invalidUpload reports a malformed multipart body as a bad request, unless
+// it was cut off for being too large.
+func invalidUpload(err error) error {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		return err
+	}
+	return errors.Join(company.ErrInvalidAttachment, err)
+}
+
+// ListAttachments returns a company's attachments, newest first.
+func ListAttachments(service company.Usecase, w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	companyID := mux.Vars(r)["id"]
+	if _, ok := requireVisibleCompany(service, w, r, companyID, false); !ok {
+		return
+	}
+
+	attachments, err := service.ListAttachments(companyID)
+	if err != nil {
+		writeAttachmentError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(attachments)
+}
+
+// DownloadAttachment sends an attachment's contents with the content type
+// sniffed on upload. The checksum doubles as the ETag.
+func DownloadAttachment(service company.Usecase, w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	attachment, ok := visibleAttachment(service, w, r)
+	if !ok {
+		return
+	}
+	tag := strconv.Quote(attachment.Checksum)
+	if match := r.Header.Get("If-None-Match"); match != "" && strings.Contains(match, tag) {
+		w.Header().Set("ETag", tag)
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	_, contents, err := service.OpenAttachment(attachment.ID)
+	if err != nil {
+		writeAttachmentError(w, err)
+		return
+	}
+	defer contents.Close()
+
+	w.Header().Set("Content-Type", attachment.ContentType)
+	w.Header().Set("Content-Length", strconv.FormatInt(attachment.Size, 10))
+	disposition := mime.FormatMediaType("attachment", map[string]string{"filename": attachment.Filename})
+	if disposition == "" {
+		disposition = "attachment"
+	}
+	w.Header().Set("Content-Disposition", disposition)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("ETag", tag)
+	w.WriteHeader(http.StatusOK)
+	if _, err := io.Copy(w, contents); err != nil {
+		log.Printf("Error sending attachment %s: %v", attachment.ID, err)
+	}
+}
+
+func DeleteAttachment(service company.Usecase, w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	attachment, ok := visibleAttachment(service, w, r)
+	if !ok {
+		return
+	}
+	if err := service.DeleteAttachment(attachment.ID); err != nil {
+		writeAttachmentError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]string{
+		"message": "Attachment deleted successfully",
+	})
+}
@@ -0,0 +1,76 @@
+package companyHandler
+
+import (
+	"backend/companyd/entity"
+	"errors"
+	"net/http"
+	"strings"
+)
+
+// Headers the frontend sends to identify the logged-in user. The API has no
+// sessions yet, so these are trusted the same way createdBy in request bodies
+// is; replace callerFromRequest once real authentication exists.
+const (
+	usernameHeader = "X-Username"
+	roleHeader     = "X-User-Role"
+)
+
+// caller is the user on whose behalf a request is made.
+type caller struct {
+	Username string
+	Role     string
+}
+
+func callerFromRequest(r *http.Request) (caller, error) {
+	c := caller{
+		Username: strings.TrimSpace(r.Header.Get(usernameHeader)),
+		Role:     strings.TrimSpace(r.Header.Get(roleHeader)),
+	}
+	if c.Role == "" {
+		return c, errors.New(roleHeader + " header is required")
+	}
+	if !c.seesAllCompanies() && c.Username == "" {
+		return c, errors.New(usernameHeader + " header is required")
+	}
+	return c, nil
+}
+
+// seesAllCompanies reports whether the caller may see every company. Only
+// admins and managers do; officers and unknown roles see their own.
+func (c caller) seesAllCompanies() bool {
+	return strings.EqualFold(c.Role, "Admin") || strings.EqualFold(c.Role, "Manager")
+}
+
+// isAdmin reports whether the caller has the Admin role.
+func (c caller) isAdmin() bool {
+	return strings.EqualFold(c.Role, "Admin")
+}
+
+// changedBy names the user to credit with a change in the company history,
+// or "" when the request does not say who made it.
+func changedBy(r *http.Request) string {
+	return strings.TrimSpace(r.Header.Get(usernameHeader))
+}
+
+// visibleOfficer is the officer a listing must be restricted to, or "" when
+// the caller sees every company.
+func (c caller) visibleOfficer() string {
+	if c.seesAllCompanies() {
+		return ""
+	}
+	return c.Username
+}
+
+// canSee reports whether the caller may see a company: admins and managers
+// see every company, officers the ones assigned to them.
+func (c caller) canSee(found *entity.Company) bool {
+	if c.seesAllCompanies() {
+		return true
+	}
+	for _, officer := range found.AssignedOfficer {
+		if officer == c.Username {
+			return true
+		}
+	}
+	return false
+}
@@ -1,12 +1,13 @@
 package companyHandler
 
 import (
+	"backend/companyd/entity"
 	companyPresenter "backend/companyd/presenter"
 	"backend/companyd/usecase/company"
 	"encoding/json"
+	"errors"
 	"log"
 	"net/http"
-	"os"
 	"regexp"
 	"strconv"
 	"strings"
@@ -14,43 +15,17 @@ import (
 	"github.com/gorilla/mux"
 )
 
-// Helper function to get allowed origins from environment variable
-func getAllowedOrigins() []string {
-	// Default origins if environment variable is not set
-	defaultOrigins := []string{
-		"https://0f22-2402-3a80-1325-cd70-dd05-94a2-213-dd84.ngrok-free.app",
-		"https://place-pro-platform-88.vercel.app",
-		"https://localhost:8081",
-		"http://localhost:8081",
-	}
-
-	// Get from environment variable
-	envOrigins := os.Getenv("CORS_ALLOWED_ORIGINS")
-	if envOrigins == "" {
-		return defaultOrigins
-	}
-
-	// Split by comma and trim whitespace
-	origins := strings.Split(envOrigins, ",")
-	for i, origin := range origins {
-		origins[i] = strings.TrimSpace(origin)
-	}
-
-	return origins
-}
+const uuidPattern = `[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}`
 
 // CORS middleware
-func enableCORS(next http.HandlerFunc) http.HandlerFunc {
+func enableCORS(allowedOrigins []string, next http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		log.Printf("Received request: %s %s", r.Method, r.URL.Path)
 
 		// Get the origin from the request
 		origin := r.Header.Get("Origin")
 
-		// List of allowed origins from environment variable
-		allowedOrigins := getAllowedOrigins()
-
-		// Check if the origin is in the allowed list
+		// Check if the origin is in the configured allowed list
 		for _, allowedOrigin := range allowedOrigins {
 			if origin == allowedOrigin {
 				w.Header().Set("Access-Control-Allow-Origin", origin)
@@ -58,8 +33,9 @@ func enableCORS(next http.HandlerFunc) http.HandlerFunc {
 			}
 		}
 
-		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Requested-With, ngrok-skip-browser-warning")
+		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
+		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Requested-With, ngrok-skip-browser-warning, If-Match, X-Username, X-User-Role")
+		w.Header().Set("Access-Control-Expose-Headers", "ETag, X-Total-Count, X-Next-Cursor, Link, Content-Disposition")
 		w.Header().Set("Access-Control-Allow-Credentials", "true")
 		w.Header().Set("Access-Control-Max-Age", "3600")
 		w.Header().Set("Content-Type", "application/json")
@@ -76,6 +52,27 @@ func enableCORS(next http.HandlerFunc) http.HandlerFunc {
 	}
 }
 
+// etag renders a company version as a strong entity tag.
+func etag(version int) string {
+	return `"` + strconv.Itoa(version) + `"`
+}
+
+// parseIfMatch extracts the company version from an If-Match header.
+func parseIfMatch(header string) (int, error) {
+	tag := strings.TrimSpace(header)
+	if strings.HasPrefix(tag, "W/") {
+		return 0, errors.New("weak entity tags cannot be used with If-Match")
+	}
+	if len(tag) < 2 || tag[0] != '"' || tag[len(tag)-1] != '"' {
+		return 0, errors.New("If-Match must be a quoted entity tag such as \"3\"")
+	}
+	version, err := strconv.Atoi(tag[1 : len(tag)-1])
+	if err != nil || version < 1 {
+		return 0, errors.New("If-Match does not contain a valid company version")
+	}
+	return version, nil
+}
+
 func CompanyHealth(w http.ResponseWriter, r *http.Request) {
 	response := map[string]string{
 		"message": "company service is running",
@@ -94,7 +91,26 @@ func CreateCompany(service company.Usecase, w http.ResponseWriter, r *http.Reque
 		return
 	}
 
-	company, err := service.CreateCompany(
+	// An empty AssignedOfficer with autoAssignOfficer lets the assignment
+	// engine choose.
+	var autoAssigned *entity.OfficerSuggestion
+	if createRequest.AutoAssignOfficer && len(createRequest.AssignedOfficer) == 0 {
+		suggestions, err := service.SuggestOfficersFor(createRequest.CompanyName, createRequest.TypeOfDrive)
+		if err != nil {
+			log.Printf("Error suggesting an officer: %v", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(map[string]string{
+				"error": err.Error(),
+			})
+			return
+		}
+		if len(suggestions) > 0 {
+			autoAssigned = suggestions[0]
+			createRequest.AssignedOfficer = []string{autoAssigned.Username}
+		}
+	}
+
+	created, err := service.CreateCompany(
 		createRequest.CompanyName,
 		createRequest.CompanyAddress,
 		createRequest.Drive,
@@ -107,7 +123,18 @@ func CreateCompany(service company.Usecase, w http.ResponseWriter, r *http.Reque
 		createRequest.Hr2Details,
 		createRequest.Package,
 		createRequest.AssignedOfficer,
+		createRequest.Compensation,
+		createRequest.CustomFields,
+		createRequest.Tags,
 	)
+	if errors.Is(err, company.ErrInvalidCompensation) || errors.Is(err, company.ErrUnknownOfficer) ||
+		errors.Is(err, company.ErrInvalidCustomValue) || errors.Is(err, company.ErrInvalidTag) {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{
+			"error": err.Error(),
+		})
+		return
+	}
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		json.NewEncoder(w).Encode(map[string]string{
@@ -116,12 +143,76 @@ func CreateCompany(service company.Usecase, w http.ResponseWriter, r *http.Reque
 		return
 	}
 
+	// The company is created either way; likely duplicates only warn, so
+	// that an officer can merge them or carry on.
+	response := companyPresenter.CreatedCompany{Company: created, AutoAssigned: autoAssigned}
+	duplicates, err := service.FindDuplicates(created.CompanyName, created.ID)
+	if err != nil {
+		log.Printf("Error looking for duplicates of %s: %v", created.ID, err)
+	}
+	if len(duplicates) > 0 {
+		response.Warning = "This company may already exist"
+		response.Duplicates = duplicates
+	}
+
+	w.Header().Set("ETag", etag(created.Version))
 	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(company)
+	json.NewEncoder(w).Encode(response)
 }
 
+func GetCompany(service company.Usecase, w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	id := mux.Vars(r)["id"]
+	found, err := service.GetCompany(id)
+	if errors.Is(err, company.ErrNotFound) {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{
+			"error": "Company not found",
+		})
+		return
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	w.Header().Set("ETag", etag(found.Version))
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(found)
+}
+
+// ListCompanies returns the companies matching the query parameters, in the
+// active season unless season says otherwise. The body stays a plain array;
+// the total and the next page are sent as headers.
 func ListCompanies(service company.Usecase, w http.ResponseWriter, r *http.Request) {
-	companies, err := service.ListCompanies()
+	query, err := parseListQuery(r.URL.Query())
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{
+			"error": err.Error(),
+		})
+		return
+	}
+	if err := inActiveSeason(service, r.URL.Query(), &query); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	page, err := service.QueryCompanies(query)
+	if errors.Is(err, company.ErrInvalidCustomValue) {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{
+			"error": err.Error(),
+		})
+		return
+	}
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		json.NewEncoder(w).Encode(map[string]string{
@@ -130,8 +221,13 @@ func ListCompanies(service company.Usecase, w http.ResponseWriter, r *http.Reque
 		return
 	}
 
+	w.Header().Set("X-Total-Count", strconv.Itoa(page.Total))
+	if page.NextCursor != "" {
+		w.Header().Set("X-Next-Cursor", page.NextCursor)
+		w.Header().Set("Link", "<"+nextPageURL(r.URL, page.NextCursor)+`>; rel="next"`)
+	}
 	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(companies)
+	json.NewEncoder(w).Encode(page.Companies)
 }
 
 func ListCompaniesByUsername(service company.Usecase, w http.ResponseWriter, r *http.Request) {
@@ -190,7 +286,7 @@ func DeleteCompany(service company.Usecase, w http.ResponseWriter, r *http.Reque
 		return
 	}
 
-	err := service.DeleteCompany(id)
+	err := service.DeleteCompany(id, changedBy(r))
 	if err != nil {
 		log.Printf("Error deleting company: %v", err)
 		w.WriteHeader(http.StatusInternalServerError)
@@ -232,6 +328,11 @@ func UpdateCompany(service company.Usecase, w http.ResponseWriter, r *http.Reque
 		return
 	}
 
+	version, ok := requireIfMatch(w, r)
+	if !ok {
+		return
+	}
+
 	var updateRequest companyPresenter.CreateCompany
 	if err := json.NewDecoder(r.Body).Decode(&updateRequest); err != nil {
 		w.WriteHeader(http.StatusBadRequest)
@@ -241,21 +342,75 @@ func UpdateCompany(service company.Usecase, w http.ResponseWriter, r *http.Reque
 		return
 	}
 
-	company, err := service.UpdateCompany(
-		id,
-		updateRequest.CompanyName,
-		updateRequest.CompanyAddress,
-		updateRequest.Drive,
-		updateRequest.TypeOfDrive,
-		updateRequest.FollowUp,
-		strconv.FormatBool(updateRequest.IsContacted),
-		updateRequest.Remarks,
-		updateRequest.ContactDetails,
-		updateRequest.Hr1Details,
-		updateRequest.Hr2Details,
-		updateRequest.Package,
-		updateRequest.AssignedOfficer,
-	)
+	// PUT replaces every field, so omitted fields are cleared. isContacted
+	// is ignored: it follows the pipeline status, which moves through
+	// PUT /company/{id}/status.
+	assignedOfficer := updateRequest.AssignedOfficer
+	customFields, tags := updateRequest.CustomFields, updateRequest.Tags
+	saveCompanyUpdate(service, w, id, version, entity.CompanyUpdate{
+		CompanyName:     &updateRequest.CompanyName,
+		CompanyAddress:  &updateRequest.CompanyAddress,
+		Drive:           &updateRequest.Drive,
+		TypeOfDrive:     &updateRequest.TypeOfDrive,
+		FollowUp:        &updateRequest.FollowUp,
+		Remarks:         &updateRequest.Remarks,
+		ContactDetails:  &updateRequest.ContactDetails,
+		HR1Details:      &updateRequest.Hr1Details,
+		HR2Details:      &updateRequest.Hr2Details,
+		Package:         &updateRequest.Package,
+		Compensation:    updateRequest.Compensation,
+		AssignedOfficer: &assignedOfficer,
+		CustomFields:    &customFields,
+		Tags:            &tags,
+	}, changedBy(r))
+}
+
+// requireIfMatch reads the company version a write was based on so that
+// concurrent edits are detected instead of silently overwritten. It writes
+// the error response itself and reports whether the caller may proceed.
+func requireIfMatch(w http.ResponseWriter, r *http.Request) (int, bool) {
+	ifMatch := r.Header.Get("If-Match")
+	if ifMatch == "" {
+		w.WriteHeader(http.StatusPreconditionRequired)
+		json.NewEncoder(w).Encode(map[string]string{
+			"error": "If-Match header with the company ETag is required",
+		})
+		return 0, false
+	}
+	version, err := parseIfMatch(ifMatch)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{
+			"error": err.Error(),
+		})
+		return 0, false
+	}
+	return version, true
+}
+
+// saveCompanyUpdate applies update at the given version on behalf of by and
+// writes the response shared by PUT and PATCH.
+func saveCompanyUpdate(service company.Usecase, w http.ResponseWriter, id string, version int, update entity.CompanyUpdate, by string) {
+	updated, err := service.UpdateCompany(id, version, update, by)
+	if errors.Is(err, company.ErrVersionMismatch) {
+		writeVersionConflict(service, w, id)
+		return
+	}
+	if errors.Is(err, company.ErrNotFound) {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{
+			"error": "Company not found",
+		})
+		return
+	}
+	if errors.Is(err, company.ErrInvalidCompensation) || errors.Is(err, company.ErrUnknownOfficer) ||
+		errors.Is(err, company.ErrInvalidCustomValue) || errors.Is(err, company.ErrInvalidTag) {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{
+			"error": err.Error(),
+		})
+		return
+	}
 	if err != nil {
 		log.Printf("Error updating company: %v", err)
 		w.WriteHeader(http.StatusInternalServerError)
@@ -265,8 +420,29 @@ func UpdateCompany(service company.Usecase, w http.ResponseWriter, r *http.Reque
 		return
 	}
 
+	w.Header().Set("ETag", etag(updated.Version))
 	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(company)
+	json.NewEncoder(w).Encode(updated)
+}
+
+// writeVersionConflict answers a stale If-Match with 412 and the current
+// record, so the client can merge and retry against the new ETag.
+func writeVersionConflict(service company.Usecase, w http.ResponseWriter, id string) {
+	current, err := service.GetCompany(id)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	w.Header().Set("ETag", etag(current.Version))
+	w.WriteHeader(http.StatusPreconditionFailed)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"error":   company.ErrVersionMismatch.Error(),
+		"current": current,
+	})
 }
 
 func CreateCompanyTemp(service company.Usecase, w http.ResponseWriter, r *http.Request) {
@@ -280,6 +456,13 @@ func CreateCompanyTemp(service company.Usecase, w http.ResponseWriter, r *http.R
 		})
 		return
 	}
+	if createRequest.IsContacted != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{
+			"error": "is_contacted follows the pipeline status; change it with PUT /company/{id}/status",
+		})
+		return
+	}
 
 	companyTemp, err := service.CreateCompanyTemp(
 		createRequest.CompanyID,
@@ -288,7 +471,7 @@ func CreateCompanyTemp(service company.Usecase, w http.ResponseWriter, r *http.R
 		createRequest.Drive,
 		createRequest.TypeOfDrive,
 		createRequest.FollowUp,
-		strconv.FormatBool(createRequest.IsContacted),
+		"false",
 		createRequest.Remarks,
 		createRequest.ContactDetails,
 		createRequest.Hr1Details,
@@ -297,6 +480,13 @@ func CreateCompanyTemp(service company.Usecase, w http.ResponseWriter, r *http.R
 		createRequest.AssignedOfficer,
 		createRequest.CreatedBy,
 	)
+	if errors.Is(err, company.ErrUnknownOfficer) {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{
+			"error": err.Error(),
+		})
+		return
+	}
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		json.NewEncoder(w).Encode(map[string]string{
@@ -306,13 +496,57 @@ func CreateCompanyTemp(service company.Usecase, w http.ResponseWriter, r *http.R
 	}
 
 	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(companyTemp)
+	json.NewEncoder(w).Encode(proposalResponse(companyTemp))
+}
+
+// proposalResponse adds the actions a proposal still allows.
+func proposalResponse(temp *entity.CompanyTemp) companyPresenter.CompanyTempResponse {
+	return companyPresenter.CompanyTempResponse{CompanyTemp: temp, AllowedActions: company.ProposalActions(temp.Status)}
 }
 
+// writeProposalError maps proposal usecase errors to responses. Moving a
+// proposal to a status its current one does not lead to is a 409 listing
+// the actions it still allows.
+func writeProposalError(w http.ResponseWriter, err error) {
+	var transition *company.ProposalTransitionError
+	switch {
+	case errors.As(err, &transition):
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"error":   err.Error(),
+			"from":    transition.From,
+			"to":      transition.To,
+			"allowed": transition.Allowed,
+		})
+		return
+	// A proposal naming an officer who has since been deleted cannot be
+	// applied either.
+	case errors.Is(err, company.ErrStaleProposal), errors.Is(err, company.ErrUnknownOfficer):
+		w.WriteHeader(http.StatusConflict)
+	case errors.Is(err, company.ErrNotFound):
+		w.WriteHeader(http.StatusNotFound)
+		err = errors.New("Company temp not found")
+	case errors.Is(err, company.ErrUnknownProposalStatus):
+		w.WriteHeader(http.StatusBadRequest)
+	default:
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+	json.NewEncoder(w).Encode(map[string]string{
+		"error": err.Error(),
+	})
+}
+
+// ListCompanyTemps returns the proposed changes of the active season, or of
+// the season given as season ("all" for every season).
 func ListCompanyTemps(service company.Usecase, w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
-	companyTemps, err := service.ListCompanyTemps()
+	season, err := seasonParam(service, r.URL.Query())
+	if err != nil {
+		writeSeasonError(w, err)
+		return
+	}
+	companyTemps, err := service.ListCompanyTemps(season)
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		json.NewEncoder(w).Encode(map[string]string{
@@ -321,8 +555,13 @@ func ListCompanyTemps(service company.Usecase, w http.ResponseWriter, r *http.Re
 		return
 	}
 
+	responses := make([]companyPresenter.CompanyTempResponse, 0, len(companyTemps))
+	for _, temp := range companyTemps {
+		responses = append(responses, proposalResponse(temp))
+	}
+
 	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(companyTemps)
+	json.NewEncoder(w).Encode(responses)
 }
 
 func UpdateCompanyTempStatus(service company.Usecase, w http.ResponseWriter, r *http.Request) {
@@ -349,12 +588,8 @@ func UpdateCompanyTempStatus(service company.Usecase, w http.ResponseWriter, r *
 		return
 	}
 
-	err := service.UpdateCompanyTempStatus(id, updateRequest.Status)
-	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(map[string]string{
-			"error": err.Error(),
-		})
+	if err := service.UpdateCompanyTempStatus(id, updateRequest.Status); err != nil {
+		writeProposalError(w, err)
 		return
 	}
 
@@ -377,12 +612,8 @@ func ApproveCompanyTemp(service company.Usecase, w http.ResponseWriter, r *http.
 		return
 	}
 
-	err := service.ApproveCompanyTemp(id)
-	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(map[string]string{
-			"error": err.Error(),
-		})
+	if err := service.ApproveCompanyTemp(id, changedBy(r)); err != nil {
+		writeProposalError(w, err)
 		return
 	}
 
@@ -400,6 +631,7 @@ func CreateEvent(service company.Usecase, w http.ResponseWriter, r *http.Request
 		Type        string `json:"type"`
 		Title       string `json:"title"`
 		Description string `json:"description"`
+		CompanyID   string `json:"company_id"`
 		CreatedBy   string `json:"created_by"`
 	}
 
@@ -425,8 +657,16 @@ func CreateEvent(service company.Usecase, w http.ResponseWriter, r *http.Request
 		createRequest.Type,
 		createRequest.Title,
 		createRequest.Description,
+		createRequest.CompanyID,
 		createRequest.CreatedBy,
 	)
+	if errors.Is(err, company.ErrUnknownCompany) {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{
+			"error": err.Error(),
+		})
+		return
+	}
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		json.NewEncoder(w).Encode(map[string]string{
@@ -439,10 +679,17 @@ func CreateEvent(service company.Usecase, w http.ResponseWriter, r *http.Request
 	json.NewEncoder(w).Encode(event)
 }
 
+// ListEvents returns the events of the active season, or of the season given
+// as season ("all" for every season).
 func ListEvents(service company.Usecase, w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
-	events, err := service.ListEvents()
+	season, err := seasonParam(service, r.URL.Query())
+	if err != nil {
+		writeSeasonError(w, err)
+		return
+	}
+	events, err := service.ListEvents(season)
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		json.NewEncoder(w).Encode(map[string]string{
@@ -460,6 +707,8 @@ func ListEvents(service company.Usecase, w http.ResponseWriter, r *http.Request)
 			"type":        event.Type,
 			"title":       event.Title,
 			"description": event.Description,
+			"companyId":   event.CompanyID,
+			"season":      event.Season,
 			"createdBy":   event.CreatedBy,
 			"createdAt":   event.CreatedAt,
 		}
@@ -469,11 +718,11 @@ func ListEvents(service company.Usecase, w http.ResponseWriter, r *http.Request)
 	json.NewEncoder(w).Encode(formattedEvents)
 }
 
-func RegisterHandlers(service company.Usecase, router *mux.Router) {
+func RegisterHandlers(service company.Usecase, router *mux.Router, allowedOrigins []string) {
 	// Add CORS middleware to all routes
 	router.Use(func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			enableCORS(next.ServeHTTP)(w, r)
+			enableCORS(allowedOrigins, next.ServeHTTP)(w, r)
 		})
 	})
 
@@ -484,6 +733,12 @@ func RegisterHandlers(service company.Usecase, router *mux.Router) {
 	router.HandleFunc("/company/list", func(w http.ResponseWriter, r *http.Request) {
 		ListCompanies(service, w, r)
 	}).Methods("GET", "OPTIONS")
+	router.HandleFunc("/company/search", func(w http.ResponseWriter, r *http.Request) {
+		SearchCompanies(service, w, r)
+	}).Methods("GET", "OPTIONS")
+	router.HandleFunc("/company/package/stats", func(w http.ResponseWriter, r *http.Request) {
+		PackageStats(service, w, r)
+	}).Methods("GET", "OPTIONS")
 	router.HandleFunc("/company/list/{id}", func(w http.ResponseWriter, r *http.Request) {
 		ListCompaniesByUsername(service, w, r)
 	}).Methods("GET", "OPTIONS")
@@ -514,4 +769,187 @@ func RegisterHandlers(service company.Usecase, router *mux.Router) {
 	router.HandleFunc("/event/list", func(w http.ResponseWriter, r *http.Request) {
 		ListEvents(service, w, r)
 	}).Methods("GET", "OPTIONS")
+	router.HandleFunc("/company/{id:"+uuidPattern+"}", func(w http.ResponseWriter, r *http.Request) {
+		GetCompany(service, w, r)
+	}).Methods("GET", "OPTIONS")
+	router.HandleFunc("/company/{id:"+uuidPattern+"}", func(w http.ResponseWriter, r *http.Request) {
+		PatchCompany(service, w, r)
+	}).Methods("PATCH")
+	router.HandleFunc("/contact/list", func(w http.ResponseWriter, r *http.Request) {
+		ListContacts(service, w, r)
+	}).Methods("GET", "OPTIONS")
+	router.HandleFunc("/contact/create", func(w http.ResponseWriter, r *http.Request) {
+		CreateContact(service, w, r)
+	}).Methods("POST", "OPTIONS")
+	router.HandleFunc("/contact/update/{id:"+uuidPattern+"}", func(w http.ResponseWriter, r *http.Request) {
+		UpdateContact(service, w, r)
+	}).Methods("PUT", "OPTIONS")
+	router.HandleFunc("/contact/delete/{id:"+uuidPattern+"}", func(w http.ResponseWriter, r *http.Request) {
+		DeleteContact(service, w, r)
+	}).Methods("DELETE", "OPTIONS")
+	router.HandleFunc("/contact/{id:"+uuidPattern+"}", func(w http.ResponseWriter, r *http.Request) {
+		GetContact(service, w, r)
+	}).Methods("GET", "OPTIONS")
+	router.HandleFunc("/drive/list", func(w http.ResponseWriter, r *http.Request) {
+		ListDrives(service, w, r)
+	}).Methods("GET", "OPTIONS")
+	router.HandleFunc("/drive/create", func(w http.ResponseWriter, r *http.Request) {
+		CreateDrive(service, w, r)
+	}).Methods("POST", "OPTIONS")
+	router.HandleFunc("/drive/update/{id:"+uuidPattern+"}", func(w http.ResponseWriter, r *http.Request) {
+		UpdateDrive(service, w, r)
+	}).Methods("PUT", "OPTIONS")
+	router.HandleFunc("/drive/delete/{id:"+uuidPattern+"}", func(w http.ResponseWriter, r *http.Request) {
+		DeleteDrive(service, w, r)
+	}).Methods("DELETE", "OPTIONS")
+	router.HandleFunc("/drive/{id:"+uuidPattern+"}", func(w http.ResponseWriter, r *http.Request) {
+		GetDrive(service, w, r)
+	}).Methods("GET", "OPTIONS")
+	router.HandleFunc("/followups/list", func(w http.ResponseWriter, r *http.Request) {
+		ListFollowUps(service, w, r)
+	}).Methods("GET", "OPTIONS")
+	router.HandleFunc("/followups/due", func(w http.ResponseWriter, r *http.Request) {
+		DueFollowUps(service, w, r)
+	}).Methods("GET", "OPTIONS")
+	router.HandleFunc("/followups/overdue", func(w http.ResponseWriter, r *http.Request) {
+		OverdueFollowUps(service, w, r)
+	}).Methods("GET", "OPTIONS")
+	router.HandleFunc("/followups/create", func(w http.ResponseWriter, r *http.Request) {
+		CreateFollowUp(service, w, r)
+	}).Methods("POST", "OPTIONS")
+	router.HandleFunc("/followups/update/{id:"+uuidPattern+"}", func(w http.ResponseWriter, r *http.Request) {
+		UpdateFollowUp(service, w, r)
+	}).Methods("PUT", "OPTIONS")
+	router.HandleFunc("/followups/complete/{id:"+uuidPattern+"}", func(w http.ResponseWriter, r *http.Request) {
+		CompleteFollowUp(service, w, r)
+	}).Methods("PUT", "OPTIONS")
+	router.HandleFunc("/followups/delete/{id:"+uuidPattern+"}", func(w http.ResponseWriter, r *http.Request) {
+		DeleteFollowUp(service, w, r)
+	}).Methods("DELETE", "OPTIONS")
+	router.HandleFunc("/followups/{id:"+uuidPattern+"}", func(w http.ResponseWriter, r *http.Request) {
+		GetFollowUp(service, w, r)
+	}).Methods("GET", "OPTIONS")
+	router.HandleFunc("/notifications/list", func(w http.ResponseWriter, r *http.Request) {
+		ListNotifications(service, w, r)
+	}).Methods("GET", "OPTIONS")
+	router.HandleFunc("/notifications/read/{id:"+uuidPattern+"}", func(w http.ResponseWriter, r *http.Request) {
+		MarkNotificationRead(service, w, r)
+	}).Methods("PUT", "OPTIONS")
+	router.HandleFunc("/interaction/list", func(w http.ResponseWriter, r *http.Request) {
+		ListInteractions(service, w, r)
+	}).Methods("GET", "OPTIONS")
+	router.HandleFunc("/interaction/create", func(w http.ResponseWriter, r *http.Request) {
+		CreateInteraction(service, w, r)
+	}).Methods("POST", "OPTIONS")
+	router.HandleFunc("/company/portfolio", func(w http.ResponseWriter, r *http.Request) {
+		OfficerPortfolio(service, w, r)
+	}).Methods("GET", "OPTIONS")
+	router.HandleFunc("/company/portfolio/{username}", func(w http.ResponseWriter, r *http.Request) {
+		OfficerPortfolio(service, w, r)
+	}).Methods("GET", "OPTIONS")
+	router.HandleFunc("/company/{id:"+uuidPattern+"}/officers", func(w http.ResponseWriter, r *http.Request) {
+		CompanyOfficers(service, w, r)
+	}).Methods("GET", "OPTIONS")
+	router.HandleFunc("/company/{id:"+uuidPattern+"}/officers/suggestions", func(w http.ResponseWriter, r *http.Request) {
+		SuggestOfficers(service, w, r)
+	}).Methods("GET", "OPTIONS")
+	router.HandleFunc("/company/{id:"+uuidPattern+"}/officers/assign", func(w http.ResponseWriter, r *http.Request) {
+		AssignOfficer(service, w, r)
+	}).Methods("POST", "OPTIONS")
+	router.HandleFunc("/company/rebalance", func(w http.ResponseWriter, r *http.Request) {
+		PreviewRebalance(service, w, r)
+	}).Methods("GET", "OPTIONS")
+	router.HandleFunc("/company/rebalance", func(w http.ResponseWriter, r *http.Request) {
+		ApplyRebalance(service, w, r)
+	}).Methods("POST", "OPTIONS")
+	router.HandleFunc("/company/{id:"+uuidPattern+"}/history", func(w http.ResponseWriter, r *http.Request) {
+		CompanyHistory(service, w, r)
+	}).Methods("GET", "OPTIONS")
+	router.HandleFunc("/company/{id:"+uuidPattern+"}/diff", func(w http.ResponseWriter, r *http.Request) {
+		DiffCompany(service, w, r)
+	}).Methods("GET", "OPTIONS")
+	router.HandleFunc("/company/{id:"+uuidPattern+"}/revert", func(w http.ResponseWriter, r *http.Request) {
+		RevertCompany(service, w, r)
+	}).Methods("POST", "OPTIONS")
+	router.HandleFunc("/company/duplicates", func(w http.ResponseWriter, r *http.Request) {
+		DuplicateReport(service, w, r)
+	}).Methods("GET", "OPTIONS")
+	router.HandleFunc("/company/duplicates/check", func(w http.ResponseWriter, r *http.Request) {
+		CheckDuplicates(service, w, r)
+	}).Methods("GET", "OPTIONS")
+	router.HandleFunc("/company/{id:"+uuidPattern+"}/merge", func(w http.ResponseWriter, r *http.Request) {
+		MergeCompanies(service, w, r)
+	}).Methods("POST", "OPTIONS")
+	router.HandleFunc("/company/trash", func(w http.ResponseWriter, r *http.Request) {
+		ListDeletedCompanies(service, w, r)
+	}).Methods("GET", "OPTIONS")
+	router.HandleFunc("/company/{id:"+uuidPattern+"}/restore", func(w http.ResponseWriter, r *http.Request) {
+		RestoreCompany(service, w, r)
+	}).Methods("POST", "OPTIONS")
+	router.HandleFunc("/company/{id:"+uuidPattern+"}/archive", func(w http.ResponseWriter, r *http.Request) {
+		ArchiveCompany(service, w, r)
+	}).Methods("POST", "OPTIONS")
+	router.HandleFunc("/company/{id:"+uuidPattern+"}/unarchive", func(w http.ResponseWriter, r *http.Request) {
+		UnarchiveCompany(service, w, r)
+	}).Methods("POST", "OPTIONS")
+	router.HandleFunc("/company/seasons", func(w http.ResponseWriter, r *http.Request) {
+		ListSeasons(service, w, r)
+	}).Methods("GET", "OPTIONS")
+	router.HandleFunc("/company/seasons/active", func(w http.ResponseWriter, r *http.Request) {
+		SetActiveSeason(service, w, r)
+	}).Methods("PUT", "OPTIONS")
+	router.HandleFunc("/company/seasons/rollover", func(w http.ResponseWriter, r *http.Request) {
+		RolloverSeason(service, w, r)
+	}).Methods("POST", "OPTIONS")
+	router.HandleFunc("/company/seasons/compare", func(w http.ResponseWriter, r *http.Request) {
+		CompareSeasons(service, w, r)
+	}).Methods("GET", "OPTIONS")
+	router.HandleFunc("/company/fields", func(w http.ResponseWriter, r *http.Request) {
+		ListCustomFields(service, w, r)
+	}).Methods("GET", "OPTIONS")
+	router.HandleFunc("/company/fields", func(w http.ResponseWriter, r *http.Request) {
+		CreateCustomField(service, w, r)
+	}).Methods("POST", "OPTIONS")
+	router.HandleFunc("/company/fields/{id:"+uuidPattern+"}", func(w http.ResponseWriter, r *http.Request) {
+		UpdateCustomField(service, w, r)
+	}).Methods("PUT", "OPTIONS")
+	router.HandleFunc("/company/fields/{id:"+uuidPattern+"}", func(w http.ResponseWriter, r *http.Request) {
+		DeleteCustomField(service, w, r)
+	}).Methods("DELETE", "OPTIONS")
+	router.HandleFunc("/company/tags", func(w http.ResponseWriter, r *http.Request) {
+		ListTags(service, w, r)
+	}).Methods("GET", "OPTIONS")
+	router.HandleFunc("/company/{id:"+uuidPattern+"}/attachments", func(w http.ResponseWriter, r *http.Request) {
+		ListAttachments(service, w, r)
+	}).Methods("GET", "OPTIONS")
+	router.HandleFunc("/company/{id:"+uuidPattern+"}/attachments", func(w http.ResponseWriter, r *http.Request) {
+		UploadAttachment(service, w, r)
+	}).Methods("POST", "OPTIONS")
+	router.HandleFunc("/company/attachments/{id:"+uuidPattern+"}", func(w http.ResponseWriter, r *http.Request) {
+		DownloadAttachment(service, w, r)
+	}).Methods("GET", "OPTIONS")
+	router.HandleFunc("/company/attachments/{id:"+uuidPattern+"}", func(w http.ResponseWriter, r *http.Request) {
+		DeleteAttachment(service, w, r)
+	}).Methods("DELETE", "OPTIONS")
+	router.HandleFunc("/company/pipeline", func(w http.ResponseWriter, r *http.Request) {
+		GetPipeline(service, w, r)
+	}).Methods("GET", "OPTIONS")
+	router.HandleFunc("/company/funnel", func(w http.ResponseWriter, r *http.Request) {
+		PipelineFunnel(service, w, r)
+	}).Methods("GET", "OPTIONS")
+	router.HandleFunc("/company/{id:"+uuidPattern+"}/status", func(w http.ResponseWriter, r *http.Request) {
+		ChangeCompanyStatus(service, w, r)
+	}).Methods("PUT", "OPTIONS")
+	router.HandleFunc("/company/{id:"+uuidPattern+"}/status/history", func(w http.ResponseWriter, r *http.Request) {
+		CompanyStatusHistory(service, w, r)
+	}).Methods("GET", "OPTIONS")
+	router.HandleFunc("/company/import", func(w http.ResponseWriter, r *http.Request) {
+		ImportCompanies(service, w, r)
+	}).Methods("POST", "OPTIONS")
+	router.HandleFunc("/company/export", func(w http.ResponseWriter, r *http.Request) {
+		ExportCompanies(service, w, r)
+	}).Methods("GET", "OPTIONS")
+	router.HandleFunc("/company/export/{username}", func(w http.ResponseWriter, r *http.Request) {
+		ExportCompanies(service, w, r)
+	}).Methods("GET", "OPTIONS")
 }
@@ -0,0 +1,3022 @@
+package companyHandler
+
+import (
+	"archive/zip"
+	"backend/companyd/entity"
+	companyPresenter "backend/companyd/presenter"
+	"backend/companyd/repository/localfs"
+	"backend/companyd/repository/memory"
+	"backend/companyd/usecase/company"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+const testOrigin = "http://localhost:8081"
+
+// testUsers are the users companies can be assigned to in these tests, with
+// their roles.
+var testUsers = []struct{ username, role string }{
+	{"admin", "Admin"},
+	{"manager", "Manager"},
+	{"officer", "Officer"},
+	{"alice", "Officer"},
+	{"bob", "Officer"},
+	{"carol", "Officer"},
+}
+
+func newTestRepository() *memory.Repository {
+	repo := memory.NewCompanyRepository()
+	for _, user := range testUsers {
+		repo.AddUser(user.username, user.role)
+	}
+	return repo
+}
+
+func newTestRouter(t *testing.T) *mux.Router {
+	t.Helper()
+	router := mux.NewRouter()
+	RegisterHandlers(company.NewService(newTestRepository()), router, []string{testOrigin})
+	return router
+}
+
+func doRequest(t *testing.T, router http.Handler, method, path string, body interface{}) *httptest.ResponseRecorder {
+	t.Helper()
+	return doRequestWithHeader(t, router, method, path, nil, body)
+}
+
+func doRequestWithHeader(t *testing.T, router http.Handler, method, path string, header http.Header, body interface{}) *httptest.ResponseRecorder {
+	t.Helper()
+	var reader *bytes.Reader
+	switch b := body.(type) {
+	case nil:
+		reader = bytes.NewReader(nil)
+	case string:
+		reader = bytes.NewReader([]byte(b))
+	default:
+		data, err := json.Marshal(b)
+		if err != nil {
+			t.Fatalf("marshal request body: %v", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+	req := httptest.NewRequest(method, path, reader)
+	req.Header.Set("Origin", testOrigin)
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec
+}
+
+func decode(t *testing.T, rec *httptest.ResponseRecorder, v interface{}) {
+	t.Helper()
+	if err := json.Unmarshal(rec.Body.Bytes(), v); err != nil {
+		t.Fatalf("decode response %q: %v", rec.Body.String(), err)
+	}
+}
+
+func expectStatus(t *testing.T, rec *httptest.ResponseRecorder, status int) {
+	t.Helper()
+	if rec.Code != status {
+		t.Fatalf("status = %d, want %d; body: %s", rec.Code, status, rec.Body.String())
+	}
+}
+
+func createCompany(t *testing.T, router http.Handler, name string, officers ...string) *entity.Company {
+	t.Helper()
+	rec := doRequest(t, router, http.MethodPost, "/company/create", companyPresenter.CreateCompany{
+		CompanyName:     name,
+		CompanyAddress:  "Chennai",
+		Drive:           "2026",
+		TypeOfDrive:     "on-campus",
+		IsContacted:     true,
+		Package:         "10 LPA",
+		AssignedOfficer: officers,
+	})
+	expectStatus(t, rec, http.StatusOK)
+	var created entity.Company
+	decode(t, rec, &created)
+	return &created
+}
+
+func createCompanyTemp(t *testing.T, router http.Handler, companyID, name string) *entity.CompanyTemp {
+	t.Helper()
+	rec := doRequest(t, router, http.MethodPost, "/company/temp/update", companyPresenter.CreateCompanyTemp{
+		CompanyID:       companyID,
+		CompanyName:     name,
+		Package:         "12 LPA",
+		AssignedOfficer: []string{"officer"},
+		CreatedBy:       "officer",
+	})
+	expectStatus(t, rec, http.StatusOK)
+	var temp entity.CompanyTemp
+	decode(t, rec, &temp)
+	return &temp
+}
+
+func TestCompanyHealth(t *testing.T) {
+	router := newTestRouter(t)
+	rec := doRequest(t, router, http.MethodGet, "/company/health", nil)
+	expectStatus(t, rec, http.StatusOK)
+
+	var body map[string]string
+	decode(t, rec, &body)
+	if body["status"] != "running" {
+		t.Errorf("status = %q, want running", body["status"])
+	}
+}
+
+func TestCORSPreflight(t *testing.T) {
+	router := newTestRouter(t)
+	rec := doRequest(t, router, http.MethodOptions, "/company/create", nil)
+	expectStatus(t, rec, http.StatusOK)
+	if got := rec.Header().Get("Access-Control-Allow-Origin"); got != testOrigin {
+		t.Errorf("Access-Control-Allow-Origin = %q, want %q", got, testOrigin)
+	}
+	if rec.Body.Len() != 0 {
+		t.Errorf("preflight body = %q, want empty", rec.Body.String())
+	}
+}
+
+func TestCORSRejectsUnknownOrigin(t *testing.T) {
+	router := newTestRouter(t)
+	req := httptest.NewRequest(http.MethodGet, "/company/health", nil)
+	req.Header.Set("Origin", "https://evil.example")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if got := rec.Header().Get("Access-Control-Allow-Origin"); got != "" {
+		t.Errorf("Access-Control-Allow-Origin = %q, want empty", got)
+	}
+}
+
+func TestCreateCompany(t *testing.T) {
+	router := newTestRouter(t)
+	created := createCompany(t, router, "Infosys", "officer")
+
+	if created.ID == "" {
+		t.Fatal("expected generated ID")
+	}
+	if created.CompanyName != "Infosys" || !created.IsContacted || created.Package != "10 LPA" {
+		t.Errorf("unexpected company: %+v", created)
+	}
+	if len(created.AssignedOfficer) != 1 || created.AssignedOfficer[0] != "officer" {
+		t.Errorf("AssignedOfficer = %v, want [officer]", created.AssignedOfficer)
+	}
+}
+
+func TestCreateCompanyInvalidBody(t *testing.T) {
+	router := newTestRouter(t)
+	rec := doRequest(t, router, http.MethodPost, "/company/create", "{not json")
+	expectStatus(t, rec, http.StatusBadRequest)
+}
+
+func TestListCompanies(t *testing.T) {
+	router := newTestRouter(t)
+
+	rec := doRequest(t, router, http.MethodGet, "/company/list", nil)
+	expectStatus(t, rec, http.StatusOK)
+	var empty []*entity.Company
+	decode(t, rec, &empty)
+	if len(empty) != 0 {
+		t.Fatalf("expected no companies, got %d", len(empty))
+	}
+
+	createCompany(t, router, "Infosys")
+	createCompany(t, router, "TCS")
+
+	rec = doRequest(t, router, http.MethodGet, "/company/list", nil)
+	expectStatus(t, rec, http.StatusOK)
+	var companies []*entity.Company
+	decode(t, rec, &companies)
+	if len(companies) != 2 {
+		t.Fatalf("expected 2 companies, got %d", len(companies))
+	}
+}
+
+func TestListCompaniesQuery(t *testing.T) {
+	router := newTestRouter(t)
+	for _, name := range []string{"Infosys", "TCS", "Wipro"} {
+		createCompany(t, router, name, "alice")
+	}
+	createCompany(t, router, "HCL", "bob")
+
+	rec := doRequest(t, router, http.MethodGet, "/company/list?officer=alice&sort=company_name&limit=2", nil)
+	expectStatus(t, rec, http.StatusOK)
+	if got := rec.Header().Get("X-Total-Count"); got != "3" {
+		t.Errorf("X-Total-Count = %q, want 3", got)
+	}
+	var companies []*entity.Company
+	decode(t, rec, &companies)
+	if len(companies) != 2 || companies[0].CompanyName != "Infosys" || companies[1].CompanyName != "TCS" {
+		t.Fatalf("unexpected first page: %+v", companies)
+	}
+
+	link := rec.Header().Get("Link")
+	if rec.Header().Get("X-Next-Cursor") == "" || !strings.HasSuffix(link, `>; rel="next"`) {
+		t.Fatalf("missing next page headers, Link = %q", link)
+	}
+	next := strings.TrimSuffix(strings.TrimPrefix(link, "<"), `>; rel="next"`)
+	rec = doRequest(t, router, http.MethodGet, next, nil)
+	expectStatus(t, rec, http.StatusOK)
+	decode(t, rec, &companies)
+	if len(companies) != 1 || companies[0].CompanyName != "Wipro" {
+		t.Fatalf("unexpected second page: %+v", companies)
+	}
+	if rec.Header().Get("Link") != "" || rec.Header().Get("X-Next-Cursor") != "" {
+		t.Error("last page should not link to a next page")
+	}
+}
+
+func TestListCompaniesQueryErrors(t *testing.T) {
+	router := newTestRouter(t)
+	createCompany(t, router, "Infosys")
+	createCompany(t, router, "TCS")
+
+	for _, query := range []string{
+		"is_contacted=maybe",
+		"package_min=ten",
+		"package_needs_review=maybe",
+		"created_after=yesterday",
+		"sort=password",
+		"limit=0",
+		"limit=100000",
+		"cursor=not-a-cursor",
+	} {
+		rec := doRequest(t, router, http.MethodGet, "/company/list?"+query, nil)
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want 400", query, rec.Code)
+		}
+	}
+
+	// A cursor only makes sense with the sort order it was issued for.
+	rec := doRequest(t, router, http.MethodGet, "/company/list?sort=company_name&limit=1", nil)
+	expectStatus(t, rec, http.StatusOK)
+	rec = doRequest(t, router, http.MethodGet, "/company/list?sort=-company_name&cursor="+rec.Header().Get("X-Next-Cursor"), nil)
+	expectStatus(t, rec, http.StatusBadRequest)
+}
+
+func TestSearchCompanies(t *testing.T) {
+	router := newTestRouter(t)
+	createCompany(t, router, "Infosys", "alice")
+	createCompany(t, router, "Infotech", "bob")
+
+	search := func(query, username, role string) *httptest.ResponseRecorder {
+		return doRequestWithHeader(t, router, http.MethodGet, "/company/search?"+query, http.Header{
+			"X-Username":  {username},
+			"X-User-Role": {role},
+		}, nil)
+	}
+
+	rec := search("q=info", "manager", "Manager")
+	expectStatus(t, rec, http.StatusOK)
+	var results []*entity.CompanySearchResult
+	decode(t, rec, &results)
+	if len(results) != 2 {
+		t.Fatalf("manager should see both companies, got %d", len(results))
+	}
+	if !strings.Contains(results[0].Snippet, "<mark>") {
+		t.Errorf("snippet %q has no highlight", results[0].Snippet)
+	}
+
+	rec = search("q=info", "alice", "Officer")
+	expectStatus(t, rec, http.StatusOK)
+	decode(t, rec, &results)
+	if len(results) != 1 || results[0].Company.CompanyName != "Infosys" {
+		t.Errorf("officer should only see assigned companies, got %+v", results)
+	}
+
+	rec = search("q=infosis", "alice", "Officer")
+	expectStatus(t, rec, http.StatusOK)
+	decode(t, rec, &results)
+	if len(results) != 1 || !results[0].Fuzzy {
+		t.Errorf("expected a fuzzy match, got %+v", results)
+	}
+
+	rec = search("q=nothing", "admin", "Admin")
+	expectStatus(t, rec, http.StatusOK)
+	if strings.TrimSpace(rec.Body.String()) != "[]" {
+		t.Errorf("body = %s, want []", rec.Body.String())
+	}
+}
+
+func TestSearchCompaniesErrors(t *testing.T) {
+	router := newTestRouter(t)
+
+	rec := doRequest(t, router, http.MethodGet, "/company/search?q=infosys", nil)
+	expectStatus(t, rec, http.StatusUnauthorized)
+
+	rec = doRequestWithHeader(t, router, http.MethodGet, "/company/search?q=infosys", http.Header{"X-User-Role": {"Officer"}}, nil)
+	expectStatus(t, rec, http.StatusUnauthorized)
+
+	manager := http.Header{"X-Username": {"manager"}, "X-User-Role": {"Manager"}}
+	for _, query := range []string{"q=", "q=%20-%20", "q=x&limit=0", "q=x&limit=abc"} {
+		rec = doRequestWithHeader(t, router, http.MethodGet, "/company/search?"+query, manager, nil)
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want 400", query, rec.Code)
+		}
+	}
+}
+
+func TestListCompaniesByUsername(t *testing.T) {
+	router := newTestRouter(t)
+	createCompany(t, router, "Infosys", "alice", "bob")
+	createCompany(t, router, "TCS", "bob")
+	createCompany(t, router, "Wipro")
+
+	tests := []struct {
+		username string
+		want     int
+	}{
+		{"alice", 1},
+		{"bob", 2},
+		{"carol", 0},
+	}
+	for _, tt := range tests {
+		rec := doRequest(t, router, http.MethodGet, "/company/list/"+tt.username, nil)
+		expectStatus(t, rec, http.StatusOK)
+		var companies []*entity.Company
+		decode(t, rec, &companies)
+		if len(companies) != tt.want {
+			t.Errorf("%s: got %d companies, want %d", tt.username, len(companies), tt.want)
+		}
+	}
+}
+
+func TestDeleteCompany(t *testing.T) {
+	router := newTestRouter(t)
+	created := createCompany(t, router, "Infosys")
+
+	rec := doRequest(t, router, http.MethodDelete, "/company/delete/"+created.ID, nil)
+	expectStatus(t, rec, http.StatusOK)
+
+	rec = doRequest(t, router, http.MethodGet, "/company/list", nil)
+	var companies []*entity.Company
+	decode(t, rec, &companies)
+	if len(companies) != 0 {
+		t.Errorf("expected company to be deleted, still have %d", len(companies))
+	}
+}
+
+func TestDeleteCompanyInvalidID(t *testing.T) {
+	router := newTestRouter(t)
+	rec := doRequest(t, router, http.MethodDelete, "/company/delete/not-a-uuid", nil)
+	expectStatus(t, rec, http.StatusBadRequest)
+}
+
+func TestDeleteCompanyWithPendingChange(t *testing.T) {
+	router := newTestRouter(t)
+	created := createCompany(t, router, "Infosys")
+	createCompanyTemp(t, router, created.ID, "Infosys Ltd")
+
+	rec := doRequest(t, router, http.MethodDelete, "/company/delete/"+created.ID, nil)
+	expectStatus(t, rec, http.StatusOK)
+}
+
+func TestCompanyTrashAndRestore(t *testing.T) {
+	router := newTestRouter(t)
+	created := createCompany(t, router, "Infosys")
+
+	rec := doRequestWithHeader(t, router, http.MethodDelete, "/company/delete/"+created.ID, http.Header{"X-Username": {"alice"}}, nil)
+	expectStatus(t, rec, http.StatusOK)
+	rec = doRequest(t, router, http.MethodGet, "/company/"+created.ID, nil)
+	expectStatus(t, rec, http.StatusNotFound)
+
+	rec = doRequest(t, router, http.MethodGet, "/company/trash", nil)
+	expectStatus(t, rec, http.StatusOK)
+	var trash []*entity.Company
+	decode(t, rec, &trash)
+	if len(trash) != 1 || trash[0].ID != created.ID || trash[0].DeletedBy != "alice" || trash[0].DeletedAt == nil {
+		t.Fatalf("trash = %+v", trash)
+	}
+
+	rec = doRequest(t, router, http.MethodPost, "/company/"+created.ID+"/restore", nil)
+	expectStatus(t, rec, http.StatusOK)
+	var restored entity.Company
+	decode(t, rec, &restored)
+	if restored.DeletedAt != nil || restored.CompanyName != "Infosys" {
+		t.Errorf("restored company = %+v", restored)
+	}
+	rec = doRequest(t, router, http.MethodGet, "/company/"+created.ID, nil)
+	expectStatus(t, rec, http.StatusOK)
+
+	rec = doRequest(t, router, http.MethodPost, "/company/"+created.ID+"/restore", nil)
+	expectStatus(t, rec, http.StatusNotFound)
+}
+
+func TestArchiveCompany(t *testing.T) {
+	router := newTestRouter(t)
+	archived := createCompany(t, router, "Infosys")
+	createCompany(t, router, "TCS")
+
+	rec := doRequestWithHeader(t, router, http.MethodPost, "/company/"+archived.ID+"/archive", http.Header{"X-Username": {"alice"}}, nil)
+	expectStatus(t, rec, http.StatusOK)
+	var got entity.Company
+	decode(t, rec, &got)
+	if got.ArchivedAt == nil || got.ArchivedBy != "alice" {
+		t.Errorf("archived company = %+v", got)
+	}
+
+	for query, want := range map[string]int{"": 1, "?archived=include": 2, "?archived=only": 1} {
+		rec = doRequest(t, router, http.MethodGet, "/company/list"+query, nil)
+		expectStatus(t, rec, http.StatusOK)
+		var companies []*entity.Company
+		decode(t, rec, &companies)
+		if len(companies) != want {
+			t.Errorf("/company/list%s returned %d companies, want %d", query, len(companies), want)
+		}
+	}
+	rec = doRequest(t, router, http.MethodGet, "/company/list?archived=yes", nil)
+	expectStatus(t, rec, http.StatusBadRequest)
+
+	rec = doRequest(t, router, http.MethodPost, "/company/"+archived.ID+"/unarchive", nil)
+	expectStatus(t, rec, http.StatusOK)
+	decode(t, rec, &got)
+	if got.ArchivedAt != nil {
+		t.Errorf("unarchived company = %+v", got)
+	}
+
+	rec = doRequest(t, router, http.MethodPost, "/company/00000000-0000-0000-0000-000000000000/archive", nil)
+	expectStatus(t, rec, http.StatusNotFound)
+}
+
+func ifMatch(version int) http.Header {
+	return http.Header{"If-Match": {fmt.Sprintf(`"%d"`, version)}}
+}
+
+func TestGetCompany(t *testing.T) {
+	router := newTestRouter(t)
+	created := createCompany(t, router, "Infosys")
+
+	rec := doRequest(t, router, http.MethodGet, "/company/"+created.ID, nil)
+	expectStatus(t, rec, http.StatusOK)
+	if got := rec.Header().Get("ETag"); got != `"1"` {
+		t.Errorf("ETag = %q, want \"1\"", got)
+	}
+	var found entity.Company
+	decode(t, rec, &found)
+	if found.ID != created.ID || found.Version != 1 {
+		t.Errorf("unexpected company: %+v", found)
+	}
+
+	rec = doRequest(t, router, http.MethodGet, "/company/00000000-0000-0000-0000-000000000000", nil)
+	expectStatus(t, rec, http.StatusNotFound)
+}
+
+func TestUpdateCompany(t *testing.T) {
+	router := newTestRouter(t)
+	created := createCompany(t, router, "Infosys", "alice")
+
+	rec := doRequestWithHeader(t, router, http.MethodPut, "/company/update/"+created.ID, ifMatch(created.Version), companyPresenter.CreateCompany{
+		CompanyName:     "Infosys Ltd",
+		IsContacted:     false,
+		AssignedOfficer: []string{"bob"},
+	})
+	expectStatus(t, rec, http.StatusOK)
+
+	var updated entity.Company
+	decode(t, rec, &updated)
+	if updated.ID != created.ID || updated.CompanyName != "Infosys Ltd" {
+		t.Errorf("unexpected company after update: %+v", updated)
+	}
+	// isContacted follows the pipeline status, which PUT does not change.
+	if !updated.IsContacted || updated.PipelineStatus != company.StageContacted {
+		t.Errorf("update changed the pipeline status: IsContacted %v, PipelineStatus %q", updated.IsContacted, updated.PipelineStatus)
+	}
+	if len(updated.AssignedOfficer) != 1 || updated.AssignedOfficer[0] != "bob" {
+		t.Errorf("AssignedOfficer = %v, want [bob]", updated.AssignedOfficer)
+	}
+	if updated.Version != 2 || rec.Header().Get("ETag") != `"2"` {
+		t.Errorf("version = %d, ETag = %q, want 2", updated.Version, rec.Header().Get("ETag"))
+	}
+}
+
+func TestUpdateCompanyErrors(t *testing.T) {
+	router := newTestRouter(t)
+
+	rec := doRequestWithHeader(t, router, http.MethodPut, "/company/update/not-a-uuid", ifMatch(1), companyPresenter.CreateCompany{})
+	expectStatus(t, rec, http.StatusBadRequest)
+
+	rec = doRequestWithHeader(t, router, http.MethodPut, "/company/update/00000000-0000-0000-0000-000000000000", ifMatch(1), "{")
+	expectStatus(t, rec, http.StatusBadRequest)
+
+	rec = doRequestWithHeader(t, router, http.MethodPut, "/company/update/00000000-0000-0000-0000-000000000000", ifMatch(1), companyPresenter.CreateCompany{})
+	expectStatus(t, rec, http.StatusNotFound)
+}
+
+func TestUpdateCompanyPreconditions(t *testing.T) {
+	router := newTestRouter(t)
+	created := createCompany(t, router, "Infosys")
+
+	rec := doRequest(t, router, http.MethodPut, "/company/update/"+created.ID, companyPresenter.CreateCompany{CompanyName: "x"})
+	expectStatus(t, rec, http.StatusPreconditionRequired)
+
+	rec = doRequestWithHeader(t, router, http.MethodPut, "/company/update/"+created.ID, http.Header{"If-Match": {"one"}}, companyPresenter.CreateCompany{CompanyName: "x"})
+	expectStatus(t, rec, http.StatusBadRequest)
+
+	rec = doRequestWithHeader(t, router, http.MethodPut, "/company/update/"+created.ID, ifMatch(1), companyPresenter.CreateCompany{CompanyName: "first"})
+	expectStatus(t, rec, http.StatusOK)
+
+	// A second writer still holding version 1 loses and gets the current record.
+	rec = doRequestWithHeader(t, router, http.MethodPut, "/company/update/"+created.ID, ifMatch(1), companyPresenter.CreateCompany{CompanyName: "second"})
+	expectStatus(t, rec, http.StatusPreconditionFailed)
+	if got := rec.Header().Get("ETag"); got != `"2"` {
+		t.Errorf("ETag = %q, want \"2\"", got)
+	}
+	var conflict struct {
+		Error   string         `json:"error"`
+		Current entity.Company `json:"current"`
+	}
+	decode(t, rec, &conflict)
+	if conflict.Error == "" || conflict.Current.CompanyName != "first" || conflict.Current.Version != 2 {
+		t.Errorf("unexpected conflict body: %+v", conflict)
+	}
+}
+
+func TestPatchCompany(t *testing.T) {
+	router := newTestRouter(t)
+	created := createCompany(t, router, "Infosys", "alice")
+
+	rec := doRequestWithHeader(t, router, http.MethodPatch, "/company/"+created.ID, ifMatch(created.Version), `{"followUp": "next week", "remarks": "called twice"}`)
+	expectStatus(t, rec, http.StatusOK)
+	var patched entity.Company
+	decode(t, rec, &patched)
+	if patched.FollowUp != "next week" || patched.Remarks != "called twice" {
+		t.Errorf("patch not applied: %+v", patched)
+	}
+	if patched.CompanyName != "Infosys" || patched.Package != "10 LPA" || len(patched.AssignedOfficer) != 1 {
+		t.Errorf("fields missing from the patch were changed: %+v", patched)
+	}
+	if rec.Header().Get("ETag") != `"2"` {
+		t.Errorf("ETag = %q, want \"2\"", rec.Header().Get("ETag"))
+	}
+
+	// null removes the value.
+	rec = doRequestWithHeader(t, router, http.MethodPatch, "/company/"+created.ID, ifMatch(2), `{"remarks": null, "assignedOfficer": null}`)
+	expectStatus(t, rec, http.StatusOK)
+	decode(t, rec, &patched)
+	if patched.Remarks != "" || len(patched.AssignedOfficer) != 0 {
+		t.Errorf("null members not cleared: %+v", patched)
+	}
+}
+
+func TestPatchCompanyErrors(t *testing.T) {
+	router := newTestRouter(t)
+	created := createCompany(t, router, "Infosys")
+	path := "/company/" + created.ID
+
+	rec := doRequest(t, router, http.MethodPatch, path, `{"remarks": "x"}`)
+	expectStatus(t, rec, http.StatusPreconditionRequired)
+
+	for _, body := range []string{`{`, `[]`, `null`, `{"remarks": 1}`, `{"isContacted": "yes"}`, `{"isContacted": true}`, `{"pipelineStatus": "interested"}`, `{"assignedOfficer": "bob"}`, `{"compensation": {"salary": 10}}`, `{"compensation": {"min": 12, "max": 8}}`, `{"unknown": "x"}`} {
+		rec = doRequestWithHeader(t, router, http.MethodPatch, path, ifMatch(1), body)
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("PATCH %s: status = %d, want 400", body, rec.Code)
+		}
+	}
+
+	rec = doRequestWithHeader(t, router, http.MethodPatch, path, ifMatch(7), `{"remarks": "x"}`)
+	expectStatus(t, rec, http.StatusPreconditionFailed)
+
+	rec = doRequestWithHeader(t, router, http.MethodPatch, "/company/00000000-0000-0000-0000-000000000000", ifMatch(1), `{"remarks": "x"}`)
+	expectStatus(t, rec, http.StatusNotFound)
+}
+
+func TestCompanyCompensation(t *testing.T) {
+	router := newTestRouter(t)
+
+	// Without a structured package, the text is parsed.
+	parsed := createCompany(t, router, "Infosys")
+	if parsed.Compensation.Base == nil || *parsed.Compensation.Base != 10 || parsed.Compensation.Unit != company.UnitLPA {
+		t.Errorf("Compensation = %+v, want base 10 LPA", parsed.Compensation)
+	}
+
+	// A structured package gets its display text from the amounts.
+	base, stipend := 12.0, 40000.0
+	rec := doRequest(t, router, http.MethodPost, "/company/create", companyPresenter.CreateCompany{
+		CompanyName:  "TCS",
+		Compensation: &entity.Compensation{Base: &base, Stipend: &stipend},
+	})
+	expectStatus(t, rec, http.StatusOK)
+	var created entity.Company
+	decode(t, rec, &created)
+	if created.Package != "12 LPA + INR 40000/month stipend" || created.Compensation.Currency != "INR" {
+		t.Errorf("unexpected package: %q %+v", created.Package, created.Compensation)
+	}
+
+	rec = doRequest(t, router, http.MethodPost, "/company/create", `{"companyName": "HCL", "compensation": {"base": -1, "unit": "weekly"}}`)
+	expectStatus(t, rec, http.StatusBadRequest)
+
+	// Patching the structured package regenerates the text and clears the
+	// review flag.
+	vague := createCompany(t, router, "Wipro")
+	rec = doRequestWithHeader(t, router, http.MethodPatch, "/company/"+vague.ID, ifMatch(vague.Version), `{"package": "Competitive"}`)
+	expectStatus(t, rec, http.StatusOK)
+	var patched entity.Company
+	decode(t, rec, &patched)
+	if !patched.Compensation.NeedsReview {
+		t.Errorf("unparseable package not flagged: %+v", patched.Compensation)
+	}
+	rec = doRequestWithHeader(t, router, http.MethodGet, "/company/list?package_needs_review=true", nil, nil)
+	var flagged []*entity.Company
+	decode(t, rec, &flagged)
+	if len(flagged) != 1 || flagged[0].ID != vague.ID {
+		t.Errorf("package_needs_review=true returned %d companies", len(flagged))
+	}
+
+	rec = doRequestWithHeader(t, router, http.MethodPatch, "/company/"+vague.ID, ifMatch(patched.Version), `{"compensation": {"min": 8, "max": 12}}`)
+	expectStatus(t, rec, http.StatusOK)
+	decode(t, rec, &patched)
+	if patched.Package != "8-12 LPA" || patched.Compensation.NeedsReview {
+		t.Errorf("unexpected package after patch: %q %+v", patched.Package, patched.Compensation)
+	}
+
+	// PUT without a structured package parses the new text.
+	rec = doRequestWithHeader(t, router, http.MethodPut, "/company/update/"+vague.ID, ifMatch(patched.Version), companyPresenter.CreateCompany{
+		CompanyName: "Wipro",
+		Package:     "$120k",
+	})
+	expectStatus(t, rec, http.StatusOK)
+	var updated entity.Company
+	decode(t, rec, &updated)
+	if updated.Compensation.Currency != "USD" || updated.Compensation.Base == nil || *updated.Compensation.Base != 120000 {
+		t.Errorf("unexpected compensation after PUT: %+v", updated.Compensation)
+	}
+}
+
+func TestPackageStats(t *testing.T) {
+	router := newTestRouter(t)
+	active := company.SeasonAt(time.Now())
+	previous := company.PreviousSeason(active)
+	admin := exportAs("Admin", "admin")
+	create := func(name, drive, typeOfDrive, pkg string) {
+		t.Helper()
+		rec := doRequest(t, router, http.MethodPost, "/company/create", companyPresenter.CreateCompany{
+			CompanyName: name,
+			Drive:       drive,
+			TypeOfDrive: typeOfDrive,
+			Package:     pkg,
+		})
+		expectStatus(t, rec, http.StatusOK)
+	}
+
+	// Zoho's drive text names this year, but it belongs to last season; TCS's
+	// names last year, but it belongs to this one.
+	rec := doRequestWithHeader(t, router, http.MethodPut, "/company/seasons/active", admin, companyPresenter.ActiveSeason{Season: previous})
+	expectStatus(t, rec, http.StatusOK)
+	create("Zoho", "2026", "on-campus", "10 LPA")
+	rec = doRequestWithHeader(t, router, http.MethodPut, "/company/seasons/active", admin, companyPresenter.ActiveSeason{Season: active})
+	expectStatus(t, rec, http.StatusOK)
+	create("Infosys", "2026", "on-campus", "6 LPA")
+	create("TCS", "2025", "on-campus", "8 LPA")
+	create("Wipro", "2026", "on-campus", "Competitive")
+	create("HCL", "2026", "off-campus", "12 LPA")
+
+	rec = doRequest(t, router, http.MethodGet, "/company/package/stats", nil)
+	expectStatus(t, rec, http.StatusOK)
+	var groups []*entity.PackageStats
+	decode(t, rec, &groups)
+	if len(groups) != 2 {
+		t.Fatalf("expected 2 groups in the active season, got %d", len(groups))
+	}
+	if *groups[0].TypeOfDrive != "off-campus" || *groups[0].Season != active || *groups[1].Season != active {
+		t.Errorf("groups out of order: %s/%s, %s/%s", *groups[0].TypeOfDrive, *groups[0].Season, *groups[1].TypeOfDrive, *groups[1].Season)
+	}
+	onCampus := groups[1]
+	if onCampus.Count != 2 || onCampus.NeedsReview != 1 || onCampus.Mean != 7 || onCampus.Median != 7 || onCampus.P90 != 7.8 {
+		t.Errorf("unexpected on-campus stats: %+v", onCampus)
+	}
+
+	rec = doRequest(t, router, http.MethodGet, "/company/package/stats?season=all", nil)
+	expectStatus(t, rec, http.StatusOK)
+	groups = nil
+	decode(t, rec, &groups)
+	if len(groups) != 3 || *groups[1].Season != previous || groups[1].Count != 1 || groups[1].Median != 10 {
+		t.Fatalf("every season: expected off-campus, then on-campus %s with Zoho alone, got %d groups", previous, len(groups))
+	}
+
+	rec = doRequest(t, router, http.MethodGet, "/company/package/stats?group_by=&season="+active, nil)
+	expectStatus(t, rec, http.StatusOK)
+	var overall []*entity.PackageStats
+	decode(t, rec, &overall)
+	if len(overall) != 1 {
+		t.Fatalf("expected one overall group, got %d", len(overall))
+	}
+	if overall[0].TypeOfDrive != nil || overall[0].Count != 3 || overall[0].Median != 8 || overall[0].Max != 12 {
+		t.Errorf("unexpected overall stats: %+v", overall[0])
+	}
+
+	for _, query := range []string{"group_by=officer", "metric=salary", "season=2026"} {
+		rec = doRequest(t, router, http.MethodGet, "/company/package/stats?"+query, nil)
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want 400", query, rec.Code)
+		}
+	}
+}
+
+func TestCreateCompanyTemp(t *testing.T) {
+	router := newTestRouter(t)
+	created := createCompany(t, router, "Infosys")
+
+	temp := createCompanyTemp(t, router, created.ID, "Infosys Ltd")
+	if temp.CompanyID != created.ID || temp.Status != "pending" || temp.CreatedBy != "officer" {
+		t.Errorf("unexpected company temp: %+v", temp)
+	}
+
+	// The {id} variant is routed to the same handler and reads the body.
+	rec := doRequest(t, router, http.MethodPost, "/company/temp/update/"+created.ID, companyPresenter.CreateCompanyTemp{
+		CompanyID:   created.ID,
+		CompanyName: "Infosys Limited",
+		CreatedBy:   "manager",
+	})
+	expectStatus(t, rec, http.StatusOK)
+}
+
+func TestCreateCompanyTempRejectsIsContacted(t *testing.T) {
+	router := newTestRouter(t)
+	created := createCompany(t, router, "Infosys")
+
+	// Approving never changes the pipeline status, so a proposal cannot
+	// carry the flag that follows it, whatever its value.
+	for _, contacted := range []bool{true, false} {
+		rec := doRequest(t, router, http.MethodPost, "/company/temp/update", companyPresenter.CreateCompanyTemp{
+			CompanyID:   created.ID,
+			CompanyName: "Infosys Ltd",
+			IsContacted: &contacted,
+			CreatedBy:   "officer",
+		})
+		expectStatus(t, rec, http.StatusBadRequest)
+		var body map[string]string
+		decode(t, rec, &body)
+		if !strings.Contains(body["error"], "/status") {
+			t.Errorf("is_contacted %t: error = %q", contacted, body["error"])
+		}
+	}
+
+	rec := doRequest(t, router, http.MethodGet, "/company/temp/list", nil)
+	expectStatus(t, rec, http.StatusOK)
+	var temps []*companyPresenter.CompanyTempResponse
+	decode(t, rec, &temps)
+	if len(temps) != 0 {
+		t.Errorf("expected no proposals, got %d", len(temps))
+	}
+}
+
+func TestCreateCompanyTempUnknownCompany(t *testing.T) {
+	router := newTestRouter(t)
+	rec := doRequest(t, router, http.MethodPost, "/company/temp/update", companyPresenter.CreateCompanyTemp{
+		CompanyID: "00000000-0000-0000-0000-000000000000",
+	})
+	expectStatus(t, rec, http.StatusInternalServerError)
+}
+
+func TestListCompanyTemps(t *testing.T) {
+	router := newTestRouter(t)
+	created := createCompany(t, router, "Infosys")
+	first := createCompanyTemp(t, router, created.ID, "first")
+	second := createCompanyTemp(t, router, created.ID, "second")
+
+	rec := doRequest(t, router, http.MethodGet, "/company/temp/list", nil)
+	expectStatus(t, rec, http.StatusOK)
+	var temps []*companyPresenter.CompanyTempResponse
+	decode(t, rec, &temps)
+	if len(temps) != 2 {
+		t.Fatalf("expected 2 temps, got %d", len(temps))
+	}
+	if temps[0].ID != second.ID || temps[1].ID != first.ID {
+		t.Errorf("expected newest first, got %s then %s", temps[0].CompanyName, temps[1].CompanyName)
+	}
+	if strings.Join(temps[0].AllowedActions, ",") != "approve,reject,withdraw" {
+		t.Errorf("pending proposal allows %v", temps[0].AllowedActions)
+	}
+}
+
+func TestUpdateCompanyTempStatus(t *testing.T) {
+	router := newTestRouter(t)
+	created := createCompany(t, router, "Infosys")
+	temp := createCompanyTemp(t, router, created.ID, "Infosys Ltd")
+
+	rec := doRequest(t, router, http.MethodPut, "/company/temp/status/"+temp.ID, map[string]string{"status": "rejected"})
+	expectStatus(t, rec, http.StatusOK)
+
+	rec = doRequest(t, router, http.MethodGet, "/company/temp/list", nil)
+	var temps []*entity.CompanyTemp
+	decode(t, rec, &temps)
+	if len(temps) != 1 || temps[0].Status != "rejected" {
+		t.Errorf("expected status rejected, got %+v", temps)
+	}
+
+	rec = doRequest(t, router, http.MethodPut, "/company/temp/status/"+temp.ID, "{")
+	expectStatus(t, rec, http.StatusBadRequest)
+}
+
+// listedProposal finds a proposal in the proposal list.
+func listedProposal(t *testing.T, router http.Handler, id string) *companyPresenter.CompanyTempResponse {
+	t.Helper()
+	rec := doRequest(t, router, http.MethodGet, "/company/temp/list", nil)
+	expectStatus(t, rec, http.StatusOK)
+	var temps []*companyPresenter.CompanyTempResponse
+	decode(t, rec, &temps)
+	for _, temp := range temps {
+		if temp.ID == id {
+			return temp
+		}
+	}
+	t.Fatalf("proposal %s not listed", id)
+	return nil
+}
+
+func TestProposalLifecycle(t *testing.T) {
+	router := newTestRouter(t)
+	created := createCompany(t, router, "Infosys")
+	rejected := createCompanyTemp(t, router, created.ID, "Infosys Ltd")
+	withdrawn := createCompanyTemp(t, router, created.ID, "Infosys Limited")
+
+	expectConflict := func(rec *httptest.ResponseRecorder, from, to string) {
+		t.Helper()
+		expectStatus(t, rec, http.StatusConflict)
+		var body struct {
+			Error   string   `json:"error"`
+			From    string   `json:"from"`
+			To      string   `json:"to"`
+			Allowed []string `json:"allowed"`
+		}
+		decode(t, rec, &body)
+		if body.From != from || body.To != to || body.Error == "" {
+			t.Errorf("conflict = %+v, want from %s to %s", body, from, to)
+		}
+		if from == company.ProposalPending && len(body.Allowed) != 3 || from != company.ProposalPending && len(body.Allowed) != 0 {
+			t.Errorf("allowed = %v for a %s proposal", body.Allowed, from)
+		}
+	}
+
+	// Statuses are checked before anything changes.
+	rec := doRequest(t, router, http.MethodPut, "/company/temp/status/"+rejected.ID, map[string]string{"status": "accepted"})
+	expectStatus(t, rec, http.StatusBadRequest)
+	rec = doRequest(t, router, http.MethodPut, "/company/temp/status/"+rejected.ID, map[string]string{"status": "approved"})
+	expectConflict(rec, company.ProposalPending, company.ProposalApproved)
+	rec = doRequest(t, router, http.MethodPut, "/company/temp/status/"+rejected.ID, map[string]string{"status": "superseded"})
+	expectConflict(rec, company.ProposalPending, company.ProposalSuperseded)
+	rec = doRequest(t, router, http.MethodPut, "/company/temp/status/00000000-0000-0000-0000-000000000000", map[string]string{"status": "rejected"})
+	expectStatus(t, rec, http.StatusNotFound)
+
+	rec = doRequest(t, router, http.MethodPut, "/company/temp/status/"+rejected.ID, map[string]string{"status": " Rejected "})
+	expectStatus(t, rec, http.StatusOK)
+	rec = doRequest(t, router, http.MethodPut, "/company/temp/status/"+withdrawn.ID, map[string]string{"status": "withdrawn"})
+	expectStatus(t, rec, http.StatusOK)
+
+	// A proposal that has left pending can neither be approved nor moved on.
+	rec = doRequest(t, router, http.MethodPut, "/company/temp/approve/"+rejected.ID, nil)
+	expectConflict(rec, company.ProposalRejected, company.ProposalApproved)
+	rec = doRequest(t, router, http.MethodPut, "/company/temp/status/"+rejected.ID, map[string]string{"status": "withdrawn"})
+	expectConflict(rec, company.ProposalRejected, company.ProposalWithdrawn)
+	rec = doRequest(t, router, http.MethodPut, "/company/temp/status/"+withdrawn.ID, map[string]string{"status": "rejected"})
+	expectConflict(rec, company.ProposalWithdrawn, company.ProposalRejected)
+
+	rec = doRequest(t, router, http.MethodGet, "/company/"+created.ID, nil)
+	var current entity.Company
+	decode(t, rec, &current)
+	if current.CompanyName != "Infosys" || current.Version != created.Version {
+		t.Errorf("a rejected proposal was applied: %+v", current)
+	}
+	for _, temp := range []*entity.CompanyTemp{rejected, withdrawn} {
+		listed := listedProposal(t, router, temp.ID)
+		if listed.AllowedActions == nil || len(listed.AllowedActions) != 0 {
+			t.Errorf("%s proposal allows %v, want none", listed.Status, listed.AllowedActions)
+		}
+	}
+}
+
+func TestApproveCompanyTemp(t *testing.T) {
+	router := newTestRouter(t)
+	created := createCompany(t, router, "Infosys", "alice")
+	temp := createCompanyTemp(t, router, created.ID, "Infosys Ltd")
+	other := createCompanyTemp(t, router, created.ID, "Infosys Limited")
+
+	rec := doRequest(t, router, http.MethodPut, "/company/temp/approve/"+temp.ID, nil)
+	expectStatus(t, rec, http.StatusOK)
+
+	rec = doRequest(t, router, http.MethodGet, "/company/list", nil)
+	var companies []*entity.Company
+	decode(t, rec, &companies)
+	if len(companies) != 1 {
+		t.Fatalf("expected 1 company, got %d", len(companies))
+	}
+	got := companies[0]
+	if got.CompanyName != "Infosys Ltd" || got.Package != "12 LPA" || len(got.AssignedOfficer) != 1 || got.AssignedOfficer[0] != "officer" {
+		t.Errorf("proposal not applied: %+v", got)
+	}
+
+	approved := listedProposal(t, router, temp.ID)
+	if approved.Status != company.ProposalApproved || len(approved.AllowedActions) != 0 {
+		t.Errorf("approved proposal: status %q, allowed %v", approved.Status, approved.AllowedActions)
+	}
+	superseded := listedProposal(t, router, other.ID)
+	if superseded.Status != company.ProposalSuperseded || len(superseded.AllowedActions) != 0 {
+		t.Errorf("other proposal: status %q, allowed %v", superseded.Status, superseded.AllowedActions)
+	}
+
+	// Approving twice, or approving a superseded proposal, is a conflict.
+	for _, id := range []string{temp.ID, other.ID} {
+		rec = doRequest(t, router, http.MethodPut, "/company/temp/approve/"+id, nil)
+		expectStatus(t, rec, http.StatusConflict)
+	}
+	rec = doRequest(t, router, http.MethodPut, "/company/temp/approve/00000000-0000-0000-0000-000000000000", nil)
+	expectStatus(t, rec, http.StatusNotFound)
+}
+
+func TestApproveStaleCompanyTemp(t *testing.T) {
+	router := newTestRouter(t)
+	created := createCompany(t, router, "Infosys")
+	temp := createCompanyTemp(t, router, created.ID, "Infosys Ltd")
+
+	rec := doRequestWithHeader(t, router, http.MethodPut, "/company/update/"+created.ID, ifMatch(created.Version), companyPresenter.CreateCompany{CompanyName: "Infosys Limited"})
+	expectStatus(t, rec, http.StatusOK)
+
+	rec = doRequest(t, router, http.MethodPut, "/company/temp/approve/"+temp.ID, nil)
+	expectStatus(t, rec, http.StatusConflict)
+}
+
+func createContact(t *testing.T, router http.Handler, req companyPresenter.SaveContact) *entity.Contact {
+	t.Helper()
+	rec := doRequest(t, router, http.MethodPost, "/contact/create", req)
+	expectStatus(t, rec, http.StatusCreated)
+	var created entity.Contact
+	decode(t, rec, &created)
+	return &created
+}
+
+func TestContactLifecycle(t *testing.T) {
+	router := newTestRouter(t)
+	infosys := createCompany(t, router, "Infosys")
+	tcs := createCompany(t, router, "TCS")
+
+	created := createContact(t, router, companyPresenter.SaveContact{
+		CompanyID: infosys.ID,
+		Name:      " Priya Sharma ",
+		Email:     "Priya@Example.com",
+		LinkedIn:  "https://www.linkedin.com/in/priya",
+		IsPrimary: true,
+	})
+	if created.Name != "Priya Sharma" || created.Email != "priya@example.com" || !created.IsPrimary {
+		t.Errorf("unexpected contact: %+v", created)
+	}
+
+	rec := doRequest(t, router, http.MethodGet, "/contact/"+created.ID, nil)
+	expectStatus(t, rec, http.StatusOK)
+
+	rec = doRequest(t, router, http.MethodGet, "/company/"+infosys.ID, nil)
+	expectStatus(t, rec, http.StatusOK)
+	var found entity.Company
+	decode(t, rec, &found)
+	if len(found.Contacts) != 1 || found.Contacts[0].ID != created.ID {
+		t.Errorf("company contacts = %+v, want the new contact", found.Contacts)
+	}
+
+	// Priya moves to TCS.
+	rec = doRequest(t, router, http.MethodPut, "/contact/update/"+created.ID, companyPresenter.SaveContact{
+		CompanyID:   tcs.ID,
+		Name:        "Priya Sharma",
+		Designation: "Head of Talent",
+		Email:       "priya@example.com",
+		IsPrimary:   true,
+	})
+	expectStatus(t, rec, http.StatusOK)
+
+	rec = doRequest(t, router, http.MethodGet, "/contact/list?email=PRIYA@example.com", nil)
+	expectStatus(t, rec, http.StatusOK)
+	var contacts []entity.Contact
+	decode(t, rec, &contacts)
+	if len(contacts) != 1 || contacts[0].CompanyID != tcs.ID || contacts[0].Designation != "Head of Talent" {
+		t.Errorf("contacts by email = %+v", contacts)
+	}
+
+	rec = doRequest(t, router, http.MethodGet, "/company/list", nil)
+	expectStatus(t, rec, http.StatusOK)
+	var companies []entity.Company
+	decode(t, rec, &companies)
+	for _, c := range companies {
+		if want := map[string]int{infosys.ID: 0, tcs.ID: 1}[c.ID]; len(c.Contacts) != want {
+			t.Errorf("%s has %d contacts, want %d", c.CompanyName, len(c.Contacts), want)
+		}
+	}
+
+	rec = doRequest(t, router, http.MethodDelete, "/contact/delete/"+created.ID, nil)
+	expectStatus(t, rec, http.StatusOK)
+	rec = doRequest(t, router, http.MethodDelete, "/contact/delete/"+created.ID, nil)
+	expectStatus(t, rec, http.StatusNotFound)
+	rec = doRequest(t, router, http.MethodGet, "/contact/"+created.ID, nil)
+	expectStatus(t, rec, http.StatusNotFound)
+}
+
+func TestContactValidation(t *testing.T) {
+	router := newTestRouter(t)
+	infosys := createCompany(t, router, "Infosys")
+	existing := createContact(t, router, companyPresenter.SaveContact{CompanyID: infosys.ID, Name: "Asha"})
+
+	for _, tc := range []struct {
+		name   string
+		method string
+		path   string
+		body   interface{}
+		status int
+	}{
+		{"missing name", http.MethodPost, "/contact/create", companyPresenter.SaveContact{CompanyID: infosys.ID}, http.StatusBadRequest},
+		{"bad company id", http.MethodPost, "/contact/create", companyPresenter.SaveContact{CompanyID: "infosys", Name: "Asha"}, http.StatusBadRequest},
+		{"unknown company", http.MethodPost, "/contact/create", companyPresenter.SaveContact{CompanyID: "00000000-0000-0000-0000-000000000000", Name: "Asha"}, http.StatusBadRequest},
+		{"bad email", http.MethodPost, "/contact/create", companyPresenter.SaveContact{CompanyID: infosys.ID, Name: "Asha", Email: "asha at infosys"}, http.StatusBadRequest},
+		{"bad linkedin", http.MethodPost, "/contact/create", companyPresenter.SaveContact{CompanyID: infosys.ID, Name: "Asha", LinkedIn: "https://example.com/asha"}, http.StatusBadRequest},
+		{"invalid body", http.MethodPost, "/contact/create", "{", http.StatusBadRequest},
+		{"update missing contact", http.MethodPut, "/contact/update/00000000-0000-0000-0000-000000000000", companyPresenter.SaveContact{CompanyID: infosys.ID, Name: "Asha"}, http.StatusNotFound},
+		{"update to unknown company", http.MethodPut, "/contact/update/" + existing.ID, companyPresenter.SaveContact{CompanyID: "00000000-0000-0000-0000-000000000000", Name: "Asha"}, http.StatusBadRequest},
+		{"list by bad company id", http.MethodGet, "/contact/list?company_id=infosys", nil, http.StatusBadRequest},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := doRequest(t, router, tc.method, tc.path, tc.body)
+			expectStatus(t, rec, tc.status)
+		})
+	}
+}
+
+func createDrive(t *testing.T, router http.Handler, req companyPresenter.SaveDrive) *entity.Drive {
+	t.Helper()
+	rec := doRequest(t, router, http.MethodPost, "/drive/create", req)
+	expectStatus(t, rec, http.StatusCreated)
+	var created entity.Drive
+	decode(t, rec, &created)
+	return &created
+}
+
+func TestDriveLifecycle(t *testing.T) {
+	router := newTestRouter(t)
+	infosys := createCompany(t, router, "Infosys")
+	tcs := createCompany(t, router, "TCS")
+
+	internship := createDrive(t, router, companyPresenter.SaveDrive{
+		CompanyID: infosys.ID,
+		Type:      "On Campus",
+		Season:    "2026/2027",
+		StartsOn:  "2026-07-14",
+		EndsOn:    "2026-07-16",
+		Roles:     []string{" SDE Intern ", ""},
+		Package:   "50k/month",
+	})
+	if internship.Type != company.DriveOnCampus || internship.Season != "2026-27" || internship.Status != company.DrivePlanned {
+		t.Errorf("unexpected drive: %+v", internship)
+	}
+	if len(internship.Roles) != 1 || internship.Roles[0] != "SDE Intern" {
+		t.Errorf("Roles = %v, want [SDE Intern]", internship.Roles)
+	}
+	if internship.StartsOn == nil || internship.StartsOn.Format("2006-01-02") != "2026-07-14" {
+		t.Errorf("StartsOn = %v, want 2026-07-14", internship.StartsOn)
+	}
+	fullTime := createDrive(t, router, companyPresenter.SaveDrive{CompanyID: infosys.ID, Type: "virtual", Season: "2026-27", StartsOn: "2026-12-01"})
+	createDrive(t, router, companyPresenter.SaveDrive{CompanyID: tcs.ID, Type: "pool", Season: "2025-26"})
+
+	rec := doRequest(t, router, http.MethodGet, "/drive/list?company_id="+infosys.ID, nil)
+	expectStatus(t, rec, http.StatusOK)
+	var drives []entity.Drive
+	decode(t, rec, &drives)
+	if len(drives) != 2 || drives[0].ID != internship.ID || drives[1].ID != fullTime.ID {
+		t.Errorf("Infosys drives = %+v, want the internship then the full-time drive", drives)
+	}
+	rec = doRequest(t, router, http.MethodGet, "/drive/list?season=2025-2026&type=pool", nil)
+	expectStatus(t, rec, http.StatusOK)
+	decode(t, rec, &drives)
+	if len(drives) != 1 || drives[0].CompanyID != tcs.ID {
+		t.Errorf("pool drives in 2025-26 = %+v", drives)
+	}
+
+	// Updating keeps the drive with its company and its status unless given.
+	rec = doRequest(t, router, http.MethodPut, "/drive/update/"+fullTime.ID, companyPresenter.SaveDrive{
+		CompanyID: tcs.ID,
+		Type:      "off-campus",
+		Season:    "2026-27",
+		Roles:     []string{"SDE", "Analyst"},
+	})
+	expectStatus(t, rec, http.StatusOK)
+	var updated entity.Drive
+	decode(t, rec, &updated)
+	if updated.CompanyID != infosys.ID || updated.Type != company.DriveOffCampus || updated.Status != company.DrivePlanned || updated.StartsOn != nil || len(updated.Roles) != 2 {
+		t.Errorf("updated drive = %+v", updated)
+	}
+	rec = doRequest(t, router, http.MethodPut, "/drive/update/"+fullTime.ID, companyPresenter.SaveDrive{Type: "off-campus", Status: "Completed"})
+	expectStatus(t, rec, http.StatusOK)
+	decode(t, rec, &updated)
+	if updated.Status != company.DriveCompleted {
+		t.Errorf("Status = %q, want completed", updated.Status)
+	}
+
+	rec = doRequest(t, router, http.MethodGet, "/drive/"+fullTime.ID, nil)
+	expectStatus(t, rec, http.StatusOK)
+	rec = doRequest(t, router, http.MethodDelete, "/drive/delete/"+fullTime.ID, nil)
+	expectStatus(t, rec, http.StatusOK)
+	rec = doRequest(t, router, http.MethodDelete, "/drive/delete/"+fullTime.ID, nil)
+	expectStatus(t, rec, http.StatusNotFound)
+	rec = doRequest(t, router, http.MethodGet, "/drive/"+fullTime.ID, nil)
+	expectStatus(t, rec, http.StatusNotFound)
+}
+
+func TestDriveValidation(t *testing.T) {
+	router := newTestRouter(t)
+	infosys := createCompany(t, router, "Infosys")
+
+	for _, tc := range []struct {
+		name   string
+		method string
+		path   string
+		body   interface{}
+		status int
+	}{
+		{"missing type", http.MethodPost, "/drive/create", companyPresenter.SaveDrive{CompanyID: infosys.ID}, http.StatusBadRequest},
+		{"unknown type", http.MethodPost, "/drive/create", companyPresenter.SaveDrive{CompanyID: infosys.ID, Type: "hackathon"}, http.StatusBadRequest},
+		{"bad company id", http.MethodPost, "/drive/create", companyPresenter.SaveDrive{CompanyID: "infosys", Type: "pool"}, http.StatusBadRequest},
+		{"unknown company", http.MethodPost, "/drive/create", companyPresenter.SaveDrive{CompanyID: "00000000-0000-0000-0000-000000000000", Type: "pool"}, http.StatusBadRequest},
+		{"bad season", http.MethodPost, "/drive/create", companyPresenter.SaveDrive{CompanyID: infosys.ID, Type: "pool", Season: "2026-28"}, http.StatusBadRequest},
+		{"bad date", http.MethodPost, "/drive/create", companyPresenter.SaveDrive{CompanyID: infosys.ID, Type: "pool", StartsOn: "next week"}, http.StatusBadRequest},
+		{"ends before it starts", http.MethodPost, "/drive/create", companyPresenter.SaveDrive{CompanyID: infosys.ID, Type: "pool", StartsOn: "2026-07-14", EndsOn: "2026-07-13"}, http.StatusBadRequest},
+		{"unknown status", http.MethodPost, "/drive/create", companyPresenter.SaveDrive{CompanyID: infosys.ID, Type: "pool", Status: "postponed"}, http.StatusBadRequest},
+		{"invalid body", http.MethodPost, "/drive/create", "{", http.StatusBadRequest},
+		{"update missing drive", http.MethodPut, "/drive/update/00000000-0000-0000-0000-000000000000", companyPresenter.SaveDrive{Type: "pool"}, http.StatusNotFound},
+		{"list by bad company id", http.MethodGet, "/drive/list?company_id=infosys", nil, http.StatusBadRequest},
+		{"list by bad season", http.MethodGet, "/drive/list?season=2026", nil, http.StatusBadRequest},
+		{"list by unknown type", http.MethodGet, "/drive/list?type=hackathon", nil, http.StatusBadRequest},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := doRequest(t, router, tc.method, tc.path, tc.body)
+			expectStatus(t, rec, tc.status)
+		})
+	}
+}
+
+func changeStatus(t *testing.T, router http.Handler, c *entity.Company, status string) *entity.Company {
+	t.Helper()
+	rec := doRequestWithHeader(t, router, http.MethodPut, "/company/"+c.ID+"/status", ifMatch(c.Version), companyPresenter.ChangeStatus{Status: status})
+	expectStatus(t, rec, http.StatusOK)
+	var moved entity.Company
+	decode(t, rec, &moved)
+	return &moved
+}
+
+func TestPipelineStatus(t *testing.T) {
+	router := newTestRouter(t)
+	infosys := createCompany(t, router, "Infosys")
+	if infosys.PipelineStatus != company.StageContacted {
+		t.Fatalf("contacted company created at %q", infosys.PipelineStatus)
+	}
+
+	rec := doRequestWithHeader(t, router, http.MethodPut, "/company/"+infosys.ID+"/status", ifMatch(infosys.Version), companyPresenter.ChangeStatus{Status: "interested"})
+	expectStatus(t, rec, http.StatusOK)
+	var moved entity.Company
+	decode(t, rec, &moved)
+	if moved.PipelineStatus != company.StageInterested || !moved.IsContacted || rec.Header().Get("ETag") != `"2"` {
+		t.Errorf("moved company = %+v, ETag %q", moved, rec.Header().Get("ETag"))
+	}
+
+	// Skipping stages is not allowed; the response lists where it can go.
+	rec = doRequestWithHeader(t, router, http.MethodPut, "/company/"+infosys.ID+"/status", ifMatch(2), companyPresenter.ChangeStatus{Status: "completed"})
+	expectStatus(t, rec, http.StatusConflict)
+	var conflict struct {
+		Error   string   `json:"error"`
+		From    string   `json:"from"`
+		To      string   `json:"to"`
+		Allowed []string `json:"allowed"`
+	}
+	decode(t, rec, &conflict)
+	if conflict.Error == "" || conflict.From != "interested" || conflict.To != "completed" || strings.Join(conflict.Allowed, ",") != "jd_received,not_interested" {
+		t.Errorf("conflict = %+v", conflict)
+	}
+
+	rec = doRequestWithHeader(t, router, http.MethodPut, "/company/"+infosys.ID+"/status", ifMatch(2), companyPresenter.ChangeStatus{Status: "hired"})
+	expectStatus(t, rec, http.StatusBadRequest)
+	rec = doRequestWithHeader(t, router, http.MethodPut, "/company/"+infosys.ID+"/status", ifMatch(1), companyPresenter.ChangeStatus{Status: "jd_received"})
+	expectStatus(t, rec, http.StatusPreconditionFailed)
+	rec = doRequest(t, router, http.MethodPut, "/company/"+infosys.ID+"/status", companyPresenter.ChangeStatus{Status: "jd_received"})
+	expectStatus(t, rec, http.StatusPreconditionRequired)
+	rec = doRequestWithHeader(t, router, http.MethodPut, "/company/00000000-0000-0000-0000-000000000000/status", ifMatch(1), companyPresenter.ChangeStatus{Status: "jd_received"})
+	expectStatus(t, rec, http.StatusNotFound)
+
+	header := ifMatch(2)
+	header.Set("X-Username", "alice")
+	rec = doRequestWithHeader(t, router, http.MethodPut, "/company/"+infosys.ID+"/status", header, companyPresenter.ChangeStatus{Status: "not_interested"})
+	expectStatus(t, rec, http.StatusOK)
+
+	rec = doRequest(t, router, http.MethodGet, "/company/"+infosys.ID+"/status/history", nil)
+	expectStatus(t, rec, http.StatusOK)
+	var history entity.PipelineHistory
+	decode(t, rec, &history)
+	if history.Status != company.StageNotInterested || strings.Join(history.Allowed, ",") != "prospect,contacted" {
+		t.Errorf("history = %+v", history)
+	}
+	var moves []string
+	for _, tr := range history.Transitions {
+		moves = append(moves, tr.From+">"+tr.To)
+	}
+	if strings.Join(moves, " ") != ">contacted contacted>interested interested>not_interested" || history.Transitions[2].ChangedBy != "alice" {
+		t.Errorf("transitions = %v", moves)
+	}
+	rec = doRequest(t, router, http.MethodGet, "/company/00000000-0000-0000-0000-000000000000/status/history", nil)
+	expectStatus(t, rec, http.StatusNotFound)
+
+	createCompany(t, router, "TCS")
+	rec = doRequest(t, router, http.MethodGet, "/company/list?pipeline_status=not_interested", nil)
+	expectStatus(t, rec, http.StatusOK)
+	var companies []*entity.Company
+	decode(t, rec, &companies)
+	if len(companies) != 1 || companies[0].ID != infosys.ID {
+		t.Errorf("not_interested companies = %+v", companies)
+	}
+
+	rec = doRequest(t, router, http.MethodGet, "/company/pipeline", nil)
+	expectStatus(t, rec, http.StatusOK)
+	var pipeline entity.Pipeline
+	decode(t, rec, &pipeline)
+	if len(pipeline.Stages) != 6 || pipeline.Stages[0] != company.StageProspect || strings.Join(pipeline.Exits, ",") != "not_interested" {
+		t.Errorf("pipeline = %+v", pipeline)
+	}
+}
+
+func TestPipelineFunnel(t *testing.T) {
+	router := newTestRouter(t)
+	infosys := createCompany(t, router, "Infosys")
+	for _, stage := range []string{"interested", "jd_received"} {
+		infosys = changeStatus(t, router, infosys, stage)
+	}
+	rec := doRequest(t, router, http.MethodPost, "/company/create", companyPresenter.CreateCompany{CompanyName: "TCS"})
+	expectStatus(t, rec, http.StatusOK)
+	var tcs entity.Company
+	decode(t, rec, &tcs)
+	changeStatus(t, router, &tcs, "not_interested")
+	createCompany(t, router, "Wipro")
+
+	rec = doRequest(t, router, http.MethodGet, "/company/funnel", nil)
+	expectStatus(t, rec, http.StatusOK)
+	var funnel entity.Funnel
+	decode(t, rec, &funnel)
+	if funnel.Season != company.SeasonAt(time.Now()) || funnel.Companies != 3 || len(funnel.Stages) != 7 {
+		t.Fatalf("funnel = %+v", funnel)
+	}
+	rate := func(r *float64) string {
+		if r == nil {
+			return "-"
+		}
+		return strconv.FormatFloat(*r, 'f', -1, 64)
+	}
+	var got []string
+	for _, s := range funnel.Stages {
+		got = append(got, fmt.Sprintf("%s:%d/%d/%d/%s", s.Stage, s.Reached, s.Current, s.Dropped, rate(s.ConversionRate)))
+	}
+	want := "prospect:3/0/1/- contacted:2/1/0/0.667 interested:1/0/0/0.5 jd_received:1/1/0/1 drive_scheduled:0/0/0/0 completed:0/0/0/- not_interested:1/1/0/-"
+	if strings.Join(got, " ") != want {
+		t.Errorf("stages (reached/current/dropped/rate) =\n%s\nwant\n%s", strings.Join(got, " "), want)
+	}
+
+	rec = doRequest(t, router, http.MethodGet, "/company/funnel?season=2020-21", nil)
+	expectStatus(t, rec, http.StatusOK)
+	decode(t, rec, &funnel)
+	if funnel.Season != "2020-21" || funnel.Companies != 0 || funnel.Stages[0].Reached != 0 {
+		t.Errorf("funnel of an old season = %+v", funnel)
+	}
+
+	rec = doRequest(t, router, http.MethodGet, "/company/funnel?season=2026", nil)
+	expectStatus(t, rec, http.StatusBadRequest)
+}
+
+func TestPipelineFunnelBySeason(t *testing.T) {
+	router := newTestRouter(t)
+	active := company.SeasonAt(time.Now())
+	previous := company.PreviousSeason(active)
+	admin := exportAs("Admin", "admin")
+	funnelOf := func(season string) entity.Funnel {
+		t.Helper()
+		rec := doRequest(t, router, http.MethodGet, "/company/funnel?season="+season, nil)
+		expectStatus(t, rec, http.StatusOK)
+		var funnel entity.Funnel
+		decode(t, rec, &funnel)
+		return funnel
+	}
+
+	rec := doRequestWithHeader(t, router, http.MethodPut, "/company/seasons/active", admin, companyPresenter.ActiveSeason{Season: previous})
+	expectStatus(t, rec, http.StatusOK)
+	old := changeStatus(t, router, createCompany(t, router, "Infosys"), "interested")
+	rec = doRequestWithHeader(t, router, http.MethodPost, "/company/seasons/rollover", admin, companyPresenter.Rollover{From: previous, To: active, Activate: true})
+	expectStatus(t, rec, http.StatusOK)
+	var rollover entity.SeasonRollover
+	decode(t, rec, &rollover)
+	if len(rollover.Carried) != 1 {
+		t.Fatalf("rollover = %+v", rollover)
+	}
+
+	// Last season's copy moves on during this season; it still counts only
+	// in last season's funnel, and this season's copy stays a prospect.
+	changeStatus(t, router, old, "jd_received")
+
+	current := funnelOf(active)
+	if current.Companies != 1 || current.Stages[0].Current != 1 || current.Stages[1].Reached != 0 {
+		t.Errorf("this season's funnel = %+v, want only the new copy, as a prospect", current)
+	}
+	last := funnelOf(previous)
+	if last.Companies != 1 || last.Stages[3].Stage != "jd_received" || last.Stages[3].Reached != 1 || last.Stages[3].Current != 1 {
+		t.Errorf("last season's funnel = %+v, want the old copy at jd_received", last)
+	}
+}
+
+func createFollowUp(t *testing.T, router http.Handler, req companyPresenter.SaveFollowUp) *entity.FollowUp {
+	t.Helper()
+	rec := doRequest(t, router, http.MethodPost, "/followups/create", req)
+	expectStatus(t, rec, http.StatusCreated)
+	var created entity.FollowUp
+	decode(t, rec, &created)
+	return &created
+}
+
+func followUpNotes(t *testing.T, rec *httptest.ResponseRecorder) []string {
+	t.Helper()
+	expectStatus(t, rec, http.StatusOK)
+	var followUps []*entity.FollowUp
+	decode(t, rec, &followUps)
+	notes := make([]string, len(followUps))
+	for i, f := range followUps {
+		notes[i] = f.Note
+	}
+	return notes
+}
+
+func TestFollowUpLifecycle(t *testing.T) {
+	router := newTestRouter(t)
+	infosys := createCompany(t, router, "Infosys", "alice")
+	alice := http.Header{"X-Username": {"alice"}, "X-User-Role": {"Officer"}}
+	manager := http.Header{"X-Username": {"manager"}, "X-User-Role": {"Manager"}}
+	now := time.Now().UTC()
+
+	// Without an officer, the company's assigned officer owns the follow-up.
+	soon := createFollowUp(t, router, companyPresenter.SaveFollowUp{CompanyID: infosys.ID, DueAt: now.Add(2 * time.Hour).Format(time.RFC3339), Note: "send the JD"})
+	if soon.Officer != "alice" || soon.Status != company.FollowUpOpen {
+		t.Errorf("unexpected follow-up: %+v", soon)
+	}
+	late := createFollowUp(t, router, companyPresenter.SaveFollowUp{CompanyID: infosys.ID, Officer: "alice", DueAt: now.Add(-time.Hour).Format(time.RFC3339), Note: "call back"})
+	createFollowUp(t, router, companyPresenter.SaveFollowUp{CompanyID: infosys.ID, Officer: "bob", DueAt: now.Add(3 * time.Hour).Format(time.RFC3339), Note: "bob's"})
+
+	if got := followUpNotes(t, doRequestWithHeader(t, router, http.MethodGet, "/followups/due", alice, nil)); strings.Join(got, ",") != "send the JD" {
+		t.Errorf("alice's due follow-ups = %v", got)
+	}
+	if got := followUpNotes(t, doRequestWithHeader(t, router, http.MethodGet, "/followups/due?within=1h", alice, nil)); len(got) != 0 {
+		t.Errorf("due within an hour = %v, want none", got)
+	}
+	if got := followUpNotes(t, doRequestWithHeader(t, router, http.MethodGet, "/followups/overdue", alice, nil)); strings.Join(got, ",") != "call back" {
+		t.Errorf("alice's overdue follow-ups = %v", got)
+	}
+	// Officers cannot look at someone else's; managers see everyone's.
+	if got := followUpNotes(t, doRequestWithHeader(t, router, http.MethodGet, "/followups/due?officer=bob", alice, nil)); strings.Join(got, ",") != "send the JD" {
+		t.Errorf("officer=bob for alice = %v", got)
+	}
+	if got := followUpNotes(t, doRequestWithHeader(t, router, http.MethodGet, "/followups/due", manager, nil)); strings.Join(got, ",") != "send the JD,bob's" {
+		t.Errorf("manager's due follow-ups = %v", got)
+	}
+	if got := followUpNotes(t, doRequestWithHeader(t, router, http.MethodGet, "/followups/due?officer=bob", manager, nil)); strings.Join(got, ",") != "bob's" {
+		t.Errorf("manager's view of bob = %v", got)
+	}
+
+	rec := doRequestWithHeader(t, router, http.MethodPut, "/followups/complete/"+soon.ID, alice, nil)
+	expectStatus(t, rec, http.StatusOK)
+	var completed entity.FollowUp
+	decode(t, rec, &completed)
+	if completed.Status != company.FollowUpDone || completed.CompletedBy != "alice" || completed.CompletedAt == nil {
+		t.Errorf("unexpected completed follow-up: %+v", completed)
+	}
+
+	// Rescheduling moves the overdue follow-up out of the overdue view.
+	rec = doRequestWithHeader(t, router, http.MethodPut, "/followups/update/"+late.ID, alice, companyPresenter.SaveFollowUp{DueAt: now.Add(48 * time.Hour).Format("2006-01-02"), Note: "call back after the exams"})
+	expectStatus(t, rec, http.StatusOK)
+	var rescheduled entity.FollowUp
+	decode(t, rec, &rescheduled)
+	if rescheduled.Officer != "alice" || rescheduled.CompanyID != infosys.ID || rescheduled.Note != "call back after the exams" {
+		t.Errorf("unexpected rescheduled follow-up: %+v", rescheduled)
+	}
+	if got := followUpNotes(t, doRequestWithHeader(t, router, http.MethodGet, "/followups/overdue", alice, nil)); len(got) != 0 {
+		t.Errorf("overdue after rescheduling = %v, want none", got)
+	}
+
+	if got := followUpNotes(t, doRequest(t, router, http.MethodGet, "/followups/list?company_id="+infosys.ID+"&status=open", nil)); strings.Join(got, ",") != "bob's,call back after the exams" {
+		t.Errorf("open follow-ups of Infosys = %v", got)
+	}
+
+	rec = doRequest(t, router, http.MethodDelete, "/followups/delete/"+late.ID, nil)
+	expectStatus(t, rec, http.StatusOK)
+	rec = doRequest(t, router, http.MethodGet, "/followups/"+late.ID, nil)
+	expectStatus(t, rec, http.StatusNotFound)
+}
+
+func TestFollowUpValidation(t *testing.T) {
+	router := newTestRouter(t)
+	infosys := createCompany(t, router, "Infosys", "alice")
+	unassigned := createCompany(t, router, "TCS")
+	due := time.Now().Add(time.Hour).Format(time.RFC3339)
+
+	for _, req := range []companyPresenter.SaveFollowUp{
+		{CompanyID: infosys.ID},
+		{CompanyID: infosys.ID, DueAt: "next tuesday"},
+		{CompanyID: "infosys", DueAt: due},
+		{CompanyID: infosys.ID, DueAt: due, Status: "snoozed"},
+		{CompanyID: "00000000-0000-0000-0000-000000000000", DueAt: due},
+		{CompanyID: unassigned.ID, DueAt: due},
+	} {
+		rec := doRequest(t, router, http.MethodPost, "/followups/create", req)
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("create %+v: status = %d, want 400", req, rec.Code)
+		}
+	}
+
+	rec := doRequest(t, router, http.MethodGet, "/followups/due", nil)
+	expectStatus(t, rec, http.StatusUnauthorized)
+	alice := http.Header{"X-Username": {"alice"}, "X-User-Role": {"Officer"}}
+	rec = doRequestWithHeader(t, router, http.MethodGet, "/followups/due?within=forever", alice, nil)
+	expectStatus(t, rec, http.StatusBadRequest)
+
+	created := createFollowUp(t, router, companyPresenter.SaveFollowUp{CompanyID: infosys.ID, DueAt: due})
+	// Completing records who did it, so a username is required even for admins.
+	rec = doRequestWithHeader(t, router, http.MethodPut, "/followups/complete/"+created.ID, http.Header{"X-User-Role": {"Admin"}}, nil)
+	expectStatus(t, rec, http.StatusUnauthorized)
+	rec = doRequestWithHeader(t, router, http.MethodPut, "/followups/update/00000000-0000-0000-0000-000000000000", alice, companyPresenter.SaveFollowUp{DueAt: due})
+	expectStatus(t, rec, http.StatusNotFound)
+}
+
+func TestFollowUpReminders(t *testing.T) {
+	service := company.NewService(newTestRepository())
+	router := mux.NewRouter()
+	RegisterHandlers(service, router, []string{testOrigin})
+	infosys := createCompany(t, router, "Infosys", "alice")
+	createFollowUp(t, router, companyPresenter.SaveFollowUp{CompanyID: infosys.ID, DueAt: time.Now().Add(time.Hour).Format(time.RFC3339), Note: "send the JD"})
+	createFollowUp(t, router, companyPresenter.SaveFollowUp{CompanyID: infosys.ID, DueAt: time.Now().Add(72 * time.Hour).Format(time.RFC3339), Note: "later"})
+
+	sent, err := service.SendReminders(24 * time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sent) != 1 || sent[0].Recipient != "alice" {
+		t.Fatalf("unexpected reminders: %+v", sent)
+	}
+
+	alice := http.Header{"X-Username": {"alice"}, "X-User-Role": {"Officer"}}
+	rec := doRequestWithHeader(t, router, http.MethodGet, "/notifications/list?unread=true", alice, nil)
+	expectStatus(t, rec, http.StatusOK)
+	var notifications []*entity.Notification
+	decode(t, rec, &notifications)
+	if len(notifications) != 1 || !strings.Contains(notifications[0].Message, "Infosys") {
+		t.Fatalf("unexpected notifications: %+v", notifications)
+	}
+
+	bob := http.Header{"X-Username": {"bob"}, "X-User-Role": {"Officer"}}
+	rec = doRequestWithHeader(t, router, http.MethodPut, "/notifications/read/"+notifications[0].ID, bob, nil)
+	expectStatus(t, rec, http.StatusNotFound)
+	rec = doRequestWithHeader(t, router, http.MethodPut, "/notifications/read/"+notifications[0].ID, alice, nil)
+	expectStatus(t, rec, http.StatusOK)
+
+	rec = doRequestWithHeader(t, router, http.MethodGet, "/notifications/list?unread=true", alice, nil)
+	var unread []*entity.Notification
+	decode(t, rec, &unread)
+	if len(unread) != 0 {
+		t.Errorf("expected no unread notifications, got %d", len(unread))
+	}
+}
+
+func TestInteractionTimeline(t *testing.T) {
+	router := newTestRouter(t)
+	infosys := createCompany(t, router, "Infosys", "alice")
+	createCompany(t, router, "TCS", "bob")
+	alice := http.Header{"X-Username": {"alice"}, "X-User-Role": {"Officer"}}
+
+	rec := doRequestWithHeader(t, router, http.MethodPost, "/interaction/create", alice, companyPresenter.SaveInteraction{
+		CompanyID: infosys.ID, Type: "Call", OccurredAt: time.Now().Add(-48 * time.Hour).Format(time.RFC3339), Outcome: "no answer",
+	})
+	expectStatus(t, rec, http.StatusCreated)
+	rec = doRequestWithHeader(t, router, http.MethodPost, "/interaction/create", alice, companyPresenter.SaveInteraction{
+		CompanyID: infosys.ID, Type: "meeting", Outcome: "interested", Notes: "met the HR head", NextStep: "send the JD",
+	})
+	expectStatus(t, rec, http.StatusCreated)
+	var created entity.Interaction
+	decode(t, rec, &created)
+	if created.Officer != "alice" || created.Type != company.InteractionMeeting || created.OccurredAt.IsZero() || created.NextStep != "send the JD" {
+		t.Errorf("unexpected interaction: %+v", created)
+	}
+
+	rec = doRequest(t, router, http.MethodGet, "/interaction/list?company_id="+infosys.ID, nil)
+	expectStatus(t, rec, http.StatusOK)
+	var timeline []*entity.Interaction
+	decode(t, rec, &timeline)
+	if len(timeline) != 2 || timeline[0].Outcome != "interested" || timeline[1].Outcome != "no answer" || timeline[1].Type != company.InteractionCall {
+		t.Fatalf("unexpected timeline: %+v", timeline)
+	}
+
+	rec = doRequest(t, router, http.MethodGet, "/company/"+infosys.ID, nil)
+	expectStatus(t, rec, http.StatusOK)
+	var found entity.Company
+	decode(t, rec, &found)
+	if found.LastInteractionAt == nil || !found.LastInteractionAt.Equal(created.OccurredAt) || found.LastInteractionOutcome != "interested" {
+		t.Errorf("last interaction = %v %q", found.LastInteractionAt, found.LastInteractionOutcome)
+	}
+	if found.Version != infosys.Version {
+		t.Errorf("logging an interaction bumped the version to %d", found.Version)
+	}
+
+	// Managers spot companies nobody has talked to in the last day.
+	rec = doRequest(t, router, http.MethodGet, "/company/list?last_interaction_before="+time.Now().Add(-24*time.Hour).UTC().Format(time.RFC3339), nil)
+	expectStatus(t, rec, http.StatusOK)
+	var neglected []*entity.Company
+	decode(t, rec, &neglected)
+	if len(neglected) != 1 || neglected[0].CompanyName != "TCS" || neglected[0].LastInteractionAt != nil {
+		t.Errorf("unexpected neglected companies: %+v", neglected)
+	}
+	rec = doRequest(t, router, http.MethodGet, "/company/list?sort=-last_interaction_at", nil)
+	expectStatus(t, rec, http.StatusOK)
+	var byLast []*entity.Company
+	decode(t, rec, &byLast)
+	if len(byLast) != 2 || byLast[0].CompanyName != "Infosys" {
+		t.Errorf("unexpected order by last interaction: %+v", byLast)
+	}
+}
+
+func TestInteractionValidation(t *testing.T) {
+	router := newTestRouter(t)
+	infosys := createCompany(t, router, "Infosys", "alice")
+	alice := http.Header{"X-Username": {"alice"}, "X-User-Role": {"Officer"}}
+
+	for _, req := range []companyPresenter.SaveInteraction{
+		{CompanyID: infosys.ID},
+		{CompanyID: infosys.ID, Type: "fax"},
+		{CompanyID: "infosys", Type: "call"},
+		{CompanyID: infosys.ID, Type: "call", OccurredAt: "yesterday"},
+		{CompanyID: infosys.ID, Type: "call", OccurredAt: time.Now().Add(time.Hour).Format(time.RFC3339)},
+		{CompanyID: "00000000-0000-0000-0000-000000000000", Type: "call"},
+	} {
+		rec := doRequestWithHeader(t, router, http.MethodPost, "/interaction/create", alice, req)
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("create %+v: status = %d, want 400", req, rec.Code)
+		}
+	}
+
+	// The officer comes from the caller, so a username is required.
+	rec := doRequest(t, router, http.MethodPost, "/interaction/create", companyPresenter.SaveInteraction{CompanyID: infosys.ID, Type: "call"})
+	expectStatus(t, rec, http.StatusUnauthorized)
+
+	for _, query := range []string{"type=fax", "company_id=infosys", "occurred_after=soon"} {
+		rec := doRequest(t, router, http.MethodGet, "/interaction/list?"+query, nil)
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("list %s: status = %d, want 400", query, rec.Code)
+		}
+	}
+}
+
+func TestCompanyHistoryAndRevert(t *testing.T) {
+	router := newTestRouter(t)
+	created := createCompany(t, router, "Infosys", "alice")
+
+	rec := doRequestWithHeader(t, router, http.MethodPatch, "/company/"+created.ID, http.Header{
+		"If-Match": {`"1"`}, "X-Username": {"alice"},
+	}, map[string]interface{}{"companyAddress": "Bangalore", "remarks": "met HR"})
+	expectStatus(t, rec, http.StatusOK)
+	temp := createCompanyTemp(t, router, created.ID, "Infosys Ltd")
+	rec = doRequestWithHeader(t, router, http.MethodPut, "/company/temp/approve/"+temp.ID, http.Header{"X-Username": {"manager"}}, nil)
+	expectStatus(t, rec, http.StatusOK)
+
+	rec = doRequest(t, router, http.MethodGet, "/company/"+created.ID+"/history", nil)
+	expectStatus(t, rec, http.StatusOK)
+	var history []*entity.CompanyRevision
+	decode(t, rec, &history)
+	if len(history) != 3 || history[0].Version != 3 || history[2].Version != 1 {
+		t.Fatalf("unexpected history: %+v", history)
+	}
+	if history[0].Source != company.RevisionProposal || history[0].ChangedBy != "manager" || history[0].ProposalID != temp.ID {
+		t.Errorf("approval recorded as %+v", history[0].CompanyChange)
+	}
+	edit := history[1]
+	if edit.Source != company.RevisionEdit || edit.ChangedBy != "alice" || len(edit.Changes) != 2 {
+		t.Fatalf("edit recorded as %+v", edit)
+	}
+	if edit.Changes[0].Field != "companyAddress" || edit.Changes[0].Before != "Chennai" || edit.Changes[0].After != "Bangalore" || edit.Changes[1].Field != "remarks" {
+		t.Errorf("unexpected edit changes: %+v", edit.Changes)
+	}
+	if history[2].Source != company.RevisionCreate || len(history[2].Changes) != 0 {
+		t.Errorf("unexpected first revision: %+v", history[2])
+	}
+
+	rec = doRequest(t, router, http.MethodGet, "/company/"+created.ID+"/diff?from=1&to=3", nil)
+	expectStatus(t, rec, http.StatusOK)
+	var diff struct {
+		From, To int
+		Changes  []entity.FieldChange
+	}
+	decode(t, rec, &diff)
+	fields := make([]string, len(diff.Changes))
+	for i, change := range diff.Changes {
+		fields[i] = change.Field
+	}
+	if diff.From != 1 || diff.To != 3 || strings.Join(fields, ",") != "companyName,companyAddress,drive,typeOfDrive,package,compensation,assignedOfficer" {
+		t.Errorf("diff 1..3 = %+v", diff)
+	}
+
+	admin := ifMatch(3)
+	admin.Set("X-Username", "admin")
+	admin.Set("X-User-Role", "Admin")
+	rec = doRequestWithHeader(t, router, http.MethodPost, "/company/"+created.ID+"/revert", admin, map[string]int{"version": 1})
+	expectStatus(t, rec, http.StatusOK)
+	if got := rec.Header().Get("ETag"); got != `"4"` {
+		t.Errorf("ETag = %q, want \"4\"", got)
+	}
+	var reverted entity.Company
+	decode(t, rec, &reverted)
+	if reverted.CompanyName != "Infosys" || reverted.CompanyAddress != "Chennai" || reverted.Package != "10 LPA" || len(reverted.AssignedOfficer) != 1 || reverted.AssignedOfficer[0] != "alice" {
+		t.Errorf("unexpected reverted company: %+v", reverted)
+	}
+
+	rec = doRequest(t, router, http.MethodGet, "/company/"+created.ID+"/history", nil)
+	expectStatus(t, rec, http.StatusOK)
+	decode(t, rec, &history)
+	if len(history) != 4 || history[0].Source != company.RevisionRevert || history[0].RevertedTo != 1 || history[0].ChangedBy != "admin" {
+		t.Errorf("revert recorded as %+v", history[0])
+	}
+	rec = doRequest(t, router, http.MethodGet, "/company/"+created.ID+"/diff?from=1&to=4", nil)
+	expectStatus(t, rec, http.StatusOK)
+	decode(t, rec, &diff)
+	if len(diff.Changes) != 0 {
+		t.Errorf("version 4 differs from version 1: %+v", diff.Changes)
+	}
+}
+
+func TestRevertCompanyValidation(t *testing.T) {
+	router := newTestRouter(t)
+	created := createCompany(t, router, "Infosys", "alice")
+	path := "/company/" + created.ID + "/revert"
+	as := func(role string, version int) http.Header {
+		header := ifMatch(version)
+		header.Set("X-Username", strings.ToLower(role))
+		header.Set("X-User-Role", role)
+		return header
+	}
+
+	rec := doRequestWithHeader(t, router, http.MethodPost, path, as("Manager", 1), map[string]int{"version": 1})
+	expectStatus(t, rec, http.StatusForbidden)
+	rec = doRequestWithHeader(t, router, http.MethodPost, path, http.Header{"X-User-Role": {"Admin"}}, map[string]int{"version": 1})
+	expectStatus(t, rec, http.StatusUnauthorized)
+	rec = doRequestWithHeader(t, router, http.MethodPost, path, http.Header{"X-Username": {"admin"}, "X-User-Role": {"Admin"}}, map[string]int{"version": 1})
+	expectStatus(t, rec, http.StatusPreconditionRequired)
+	rec = doRequestWithHeader(t, router, http.MethodPost, path, as("Admin", 1), map[string]int{"version": 0})
+	expectStatus(t, rec, http.StatusBadRequest)
+	rec = doRequestWithHeader(t, router, http.MethodPost, path, as("Admin", 1), map[string]int{"version": 7})
+	expectStatus(t, rec, http.StatusNotFound)
+	rec = doRequestWithHeader(t, router, http.MethodPost, path, as("Admin", 2), map[string]int{"version": 1})
+	expectStatus(t, rec, http.StatusPreconditionFailed)
+	rec = doRequestWithHeader(t, router, http.MethodPost, "/company/00000000-0000-0000-0000-000000000000/revert", as("Admin", 1), map[string]int{"version": 1})
+	expectStatus(t, rec, http.StatusNotFound)
+
+	for _, query := range []string{"", "from=1", "from=a&to=1", "from=0&to=1"} {
+		rec := doRequest(t, router, http.MethodGet, "/company/"+created.ID+"/diff?"+query, nil)
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("diff %q: status = %d, want 400", query, rec.Code)
+		}
+	}
+	rec = doRequest(t, router, http.MethodGet, "/company/"+created.ID+"/diff?from=1&to=2", nil)
+	expectStatus(t, rec, http.StatusNotFound)
+	rec = doRequest(t, router, http.MethodGet, "/company/00000000-0000-0000-0000-000000000000/history", nil)
+	expectStatus(t, rec, http.StatusNotFound)
+}
+
+func TestCreateCompanyWarnsOfDuplicates(t *testing.T) {
+	router := newTestRouter(t)
+	original := createCompany(t, router, "Infosys")
+
+	rec := doRequest(t, router, http.MethodPost, "/company/create", companyPresenter.CreateCompany{CompanyName: "INFOSYS LIMITED"})
+	expectStatus(t, rec, http.StatusOK)
+	var created companyPresenter.CreatedCompany
+	decode(t, rec, &created)
+	if created.Company == nil || created.CompanyName != "INFOSYS LIMITED" || created.Warning == "" {
+		t.Fatalf("created = %+v", created)
+	}
+	if len(created.Duplicates) != 1 || created.Duplicates[0].Company.ID != original.ID || created.Duplicates[0].Similarity != 1 {
+		t.Errorf("duplicates = %+v, want only %s", created.Duplicates, original.ID)
+	}
+
+	rec = doRequest(t, router, http.MethodPost, "/company/create", companyPresenter.CreateCompany{CompanyName: "Wipro"})
+	expectStatus(t, rec, http.StatusOK)
+	var unrelated map[string]interface{}
+	decode(t, rec, &unrelated)
+	if _, ok := unrelated["warning"]; ok {
+		t.Errorf("unexpected warning creating Wipro: %v", unrelated)
+	}
+
+	rec = doRequest(t, router, http.MethodGet, "/company/duplicates/check?name=Infosis", nil)
+	expectStatus(t, rec, http.StatusOK)
+	var matches []*entity.DuplicateMatch
+	decode(t, rec, &matches)
+	if len(matches) != 2 {
+		t.Errorf("check Infosis matched %d companies, want 2", len(matches))
+	}
+	rec = doRequest(t, router, http.MethodGet, "/company/duplicates/check", nil)
+	expectStatus(t, rec, http.StatusBadRequest)
+
+	rec = doRequest(t, router, http.MethodGet, "/company/duplicates", nil)
+	expectStatus(t, rec, http.StatusOK)
+	var groups []*entity.DuplicateGroup
+	decode(t, rec, &groups)
+	if len(groups) != 1 || len(groups[0].Companies) != 2 || groups[0].Companies[0].ID != original.ID {
+		t.Errorf("duplicate report = %+v, want Infosys grouped with INFOSYS LIMITED", groups)
+	}
+}
+
+func TestMergeCompanies(t *testing.T) {
+	router := newTestRouter(t)
+	survivor := createCompany(t, router, "Infosys", "alice")
+	duplicate := createCompany(t, router, "Infosys Ltd", "bob")
+	path := "/company/" + survivor.ID + "/merge"
+	as := func(role string, version int) http.Header {
+		header := ifMatch(version)
+		header.Set("X-Username", strings.ToLower(role))
+		header.Set("X-User-Role", role)
+		return header
+	}
+	body := companyPresenter.MergeCompany{DuplicateID: duplicate.ID}
+
+	rec := doRequestWithHeader(t, router, http.MethodPost, path, as("Manager", 1), body)
+	expectStatus(t, rec, http.StatusForbidden)
+	rec = doRequestWithHeader(t, router, http.MethodPost, path, http.Header{"X-Username": {"admin"}, "X-User-Role": {"Admin"}}, body)
+	expectStatus(t, rec, http.StatusPreconditionRequired)
+	rec = doRequestWithHeader(t, router, http.MethodPost, path, as("Admin", 1), map[string]string{})
+	expectStatus(t, rec, http.StatusBadRequest)
+	rec = doRequestWithHeader(t, router, http.MethodPost, path, as("Admin", 1), companyPresenter.MergeCompany{DuplicateID: survivor.ID})
+	expectStatus(t, rec, http.StatusBadRequest)
+	rec = doRequestWithHeader(t, router, http.MethodPost, path, as("Admin", 1), companyPresenter.MergeCompany{DuplicateID: "00000000-0000-0000-0000-000000000000"})
+	expectStatus(t, rec, http.StatusBadRequest)
+	rec = doRequestWithHeader(t, router, http.MethodPost, "/company/00000000-0000-0000-0000-000000000000/merge", as("Admin", 1), body)
+	expectStatus(t, rec, http.StatusNotFound)
+	rec = doRequestWithHeader(t, router, http.MethodPost, path, as("Admin", 2), body)
+	expectStatus(t, rec, http.StatusPreconditionFailed)
+
+	rec = doRequestWithHeader(t, router, http.MethodPost, path, as("Admin", 1), body)
+	expectStatus(t, rec, http.StatusOK)
+	if got := rec.Header().Get("ETag"); got != `"2"` {
+		t.Errorf("ETag = %s, want \"2\"", got)
+	}
+	var merged entity.Company
+	decode(t, rec, &merged)
+	if merged.CompanyName != "Infosys" || strings.Join(merged.AssignedOfficer, ",") != "alice,bob" {
+		t.Errorf("merged company = %+v", merged)
+	}
+	rec = doRequest(t, router, http.MethodGet, "/company/"+duplicate.ID, nil)
+	expectStatus(t, rec, http.StatusNotFound)
+
+	rec = doRequest(t, router, http.MethodGet, "/company/"+survivor.ID+"/history", nil)
+	var revisions []*entity.CompanyRevision
+	decode(t, rec, &revisions)
+	if len(revisions) == 0 || revisions[0].Source != company.RevisionMerge || revisions[0].MergedFrom != duplicate.ID || revisions[0].ChangedBy != "admin" {
+		t.Errorf("latest revision = %+v, want the merge", revisions[0])
+	}
+}
+
+func TestCreateEvent(t *testing.T) {
+	router := newTestRouter(t)
+	rec := doRequest(t, router, http.MethodPost, "/event/create", map[string]string{
+		"date":        "2026-07-01T10:00:00Z",
+		"type":        "drive",
+		"title":       "Infosys drive",
+		"description": "Hall A",
+		"created_by":  "manager",
+	})
+	expectStatus(t, rec, http.StatusOK)
+
+	var event entity.Event
+	decode(t, rec, &event)
+	if event.ID == "" || event.Title != "Infosys drive" || event.CreatedBy != "manager" {
+		t.Errorf("unexpected event: %+v", event)
+	}
+}
+
+func TestCreateEventValidation(t *testing.T) {
+	router := newTestRouter(t)
+
+	rec := doRequest(t, router, http.MethodPost, "/event/create", "{")
+	expectStatus(t, rec, http.StatusBadRequest)
+
+	rec = doRequest(t, router, http.MethodPost, "/event/create", map[string]string{"title": "missing fields"})
+	expectStatus(t, rec, http.StatusBadRequest)
+
+	rec = doRequest(t, router, http.MethodPost, "/event/create", map[string]string{
+		"date": "next tuesday", "type": "drive", "title": "bad date", "created_by": "manager",
+	})
+	expectStatus(t, rec, http.StatusInternalServerError)
+}
+
+func TestListEvents(t *testing.T) {
+	router := newTestRouter(t)
+	for _, date := range []string{"2026-07-01T10:00:00Z", "2026-09-01T10:00:00Z", "2026-08-01T10:00:00Z"} {
+		rec := doRequest(t, router, http.MethodPost, "/event/create", map[string]string{
+			"date": date, "type": "drive", "title": date, "created_by": "manager",
+		})
+		expectStatus(t, rec, http.StatusOK)
+	}
+
+	// Events are listed by season; name it so the test does not depend on
+	// today's date.
+	rec := doRequest(t, router, http.MethodGet, "/event/list?season=2026-27", nil)
+	expectStatus(t, rec, http.StatusOK)
+	var events []map[string]interface{}
+	decode(t, rec, &events)
+	if len(events) != 3 {
+		t.Fatalf("expected 3 events, got %d", len(events))
+	}
+	want := []string{"2026-09-01T10:00:00Z", "2026-08-01T10:00:00Z", "2026-07-01T10:00:00Z"}
+	for i, event := range events {
+		if event["title"] != want[i] {
+			t.Errorf("events[%d] = %v, want %s", i, event["title"], want[i])
+		}
+		if _, ok := event["createdBy"]; !ok {
+			t.Errorf("events[%d] missing createdBy key", i)
+		}
+		if event["season"] != "2026-27" {
+			t.Errorf("events[%d] season = %v, want 2026-27", i, event["season"])
+		}
+	}
+
+	rec = doRequest(t, router, http.MethodGet, "/event/list?season=2025-26", nil)
+	decode(t, rec, &events)
+	if len(events) != 0 {
+		t.Errorf("2025-26 events = %v, want none", events)
+	}
+	rec = doRequest(t, router, http.MethodGet, "/event/list?season=someday", nil)
+	expectStatus(t, rec, http.StatusBadRequest)
+}
+
+// uploadSpreadsheet posts data to the import endpoint along with the given
+// form fields.
+func uploadSpreadsheet(t *testing.T, router http.Handler, data []byte, fields map[string]string) *httptest.ResponseRecorder {
+	t.Helper()
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	part, err := form.CreateFormFile("file", "companies")
+	if err != nil {
+		t.Fatal(err)
+	}
+	part.Write(data)
+	for name, value := range fields {
+		form.WriteField(name, value)
+	}
+	form.Close()
+	header := http.Header{"Content-Type": {form.FormDataContentType()}, "X-Username": {"admin"}}
+	return doRequestWithHeader(t, router, http.MethodPost, "/company/import", header, body.String())
+}
+
+// buildXLSX writes a one-sheet workbook; cells starting with "=" are stored
+// as shared strings and the rest as inline strings.
+func buildXLSX(t *testing.T, rows [][]string) []byte {
+	t.Helper()
+	var sheet, shared strings.Builder
+	count := 0
+	for i, row := range rows {
+		fmt.Fprintf(&sheet, `<row r="%d">`, i+1)
+		for j, cell := range row {
+			if cell == "" {
+				continue
+			}
+			ref := fmt.Sprintf("%c%d", 'A'+j, i+1)
+			if strings.HasPrefix(cell, "=") {
+				fmt.Fprintf(&shared, `<si><t>%s</t></si>`, strings.TrimPrefix(cell, "="))
+				fmt.Fprintf(&sheet, `<c r="%s" t="s"><v>%d</v></c>`, ref, count)
+				count++
+			} else {
+				fmt.Fprintf(&sheet, `<c r="%s" t="inlineStr"><is><t>%s</t></is></c>`, ref, cell)
+			}
+		}
+		sheet.WriteString(`</row>`)
+	}
+	parts := map[string]string{
+		"xl/workbook.xml": `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+			`<sheets><sheet name="Companies" sheetId="1" r:id="rId1"/></sheets></workbook>`,
+		"xl/_rels/workbook.xml.rels": `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/></Relationships>`,
+		"xl/sharedStrings.xml":     `<sst xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` + shared.String() + `</sst>`,
+		"xl/worksheets/sheet1.xml": `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>` + sheet.String() + `</sheetData></worksheet>`,
+	}
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	for name, content := range parts {
+		f, err := archive.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		f.Write([]byte(content))
+	}
+	if err := archive.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestImportCompaniesPreview(t *testing.T) {
+	router := newTestRouter(t)
+	createCompany(t, router, "Infosys Ltd", "alice")
+	data := "\xef\xbb\xbfCompany,City,Contacted,CTC,Officers,Notes\n" +
+		"Infosys,Bangalore,yes,10 LPA,alice; bob,\n" +
+		"\n" +
+		"Wipro,Chennai,maybe,competitive,,call back\n" +
+		",Pune,no,,,\n"
+
+	rec := uploadSpreadsheet(t, router, []byte(data), nil)
+	expectStatus(t, rec, http.StatusOK)
+	var got companyPresenter.ImportCompanies
+	decode(t, rec, &got)
+	if got.Mapping["Company"] != "companyName" || got.Mapping["Officers"] != "assignedOfficer" || strings.Join(got.IgnoredColumns, ",") != "City" {
+		t.Errorf("mapping = %v, ignored = %v", got.Mapping, got.IgnoredColumns)
+	}
+	if got.Committed || got.Total != 3 || got.Invalid != 2 || len(got.Rows) != 3 {
+		t.Fatalf("report = %+v", got.ImportReport)
+	}
+	infosys, wipro, blank := got.Rows[0], got.Rows[1], got.Rows[2]
+	if infosys.Line != 2 || !infosys.Company.IsContacted || strings.Join(infosys.Company.AssignedOfficer, ",") != "alice,bob" || len(infosys.Errors) != 0 {
+		t.Errorf("Infosys row = %+v", infosys)
+	}
+	if len(infosys.Duplicates) != 1 || infosys.Duplicates[0].Company.CompanyName != "Infosys Ltd" {
+		t.Errorf("Infosys duplicates = %+v", infosys.Duplicates)
+	}
+	if wipro.Line != 4 || len(wipro.Errors) != 1 || wipro.Errors[0].Field != "isContacted" || len(wipro.Warnings) != 1 {
+		t.Errorf("Wipro row = %+v", wipro)
+	}
+	if blank.Line != 5 || len(blank.Errors) != 1 || blank.Errors[0].Field != "companyName" {
+		t.Errorf("nameless row = %+v", blank)
+	}
+
+	rec = doRequest(t, router, http.MethodGet, "/company/list", nil)
+	var companies []entity.Company
+	decode(t, rec, &companies)
+	if len(companies) != 1 {
+		t.Errorf("preview created companies: %d listed", len(companies))
+	}
+}
+
+func TestImportCompaniesCommit(t *testing.T) {
+	router := newTestRouter(t)
+	createCompany(t, router, "Infosys", "alice")
+	data := buildXLSX(t, [][]string{
+		{"=Name", "Address", "=Package", "=Officer"},
+		{"=TCS", "", "12 LPA", "=bob"},
+		{},
+		{"Infosys Limited", "Bangalore"},
+		{"=Zoho", "Chennai"},
+	})
+	mapping := `{"Name":"companyName","Address":"companyAddress","Package":"package","Officer":"assignedOfficer"}`
+
+	rec := uploadSpreadsheet(t, router, data, map[string]string{"mapping": mapping, "commit": "true", "skip_duplicates": "true"})
+	expectStatus(t, rec, http.StatusOK)
+	var got companyPresenter.ImportCompanies
+	decode(t, rec, &got)
+	if !got.Committed || got.Total != 3 || got.Created != 2 || got.Skipped != 1 {
+		t.Fatalf("report = %+v", got.ImportReport)
+	}
+	tcs, infosys, zoho := got.Rows[0], got.Rows[1], got.Rows[2]
+	if tcs.ID == "" || tcs.Company.Package != "12 LPA" || strings.Join(tcs.Company.AssignedOfficer, ",") != "bob" {
+		t.Errorf("TCS row = %+v", tcs)
+	}
+	if !infosys.Skipped || infosys.ID != "" || infosys.Line != 4 {
+		t.Errorf("Infosys row = %+v, want it skipped as a duplicate", infosys)
+	}
+	if zoho.ID == "" || zoho.Line != 5 || zoho.Company.CompanyAddress != "Chennai" {
+		t.Errorf("Zoho row = %+v", zoho)
+	}
+
+	rec = doRequest(t, router, http.MethodGet, "/company/"+zoho.ID+"/history", nil)
+	expectStatus(t, rec, http.StatusOK)
+	var revisions []entity.CompanyRevision
+	decode(t, rec, &revisions)
+	if len(revisions) != 1 || revisions[0].Source != company.RevisionImport || revisions[0].ChangedBy != "admin" {
+		t.Errorf("history of imported company = %+v", revisions)
+	}
+}
+
+func TestImportCompaniesAllOrNothing(t *testing.T) {
+	router := newTestRouter(t)
+	data := []byte("companyName,isContacted\nInfosys,yes\nWipro,perhaps\ninfosys,no\n")
+
+	rec := uploadSpreadsheet(t, router, data, map[string]string{"commit": "true"})
+	expectStatus(t, rec, http.StatusUnprocessableEntity)
+	var got companyPresenter.ImportCompanies
+	decode(t, rec, &got)
+	if got.Committed || got.Invalid != 2 || got.Rows[0].ID != "" {
+		t.Errorf("report = %+v", got.ImportReport)
+	}
+	rec = doRequest(t, router, http.MethodGet, "/company/list", nil)
+	var companies []entity.Company
+	decode(t, rec, &companies)
+	if len(companies) != 0 {
+		t.Errorf("failed import created %d companies", len(companies))
+	}
+
+	rec = uploadSpreadsheet(t, router, data, map[string]string{"commit": "true", "report": "csv"})
+	expectStatus(t, rec, http.StatusUnprocessableEntity)
+	if got := rec.Header().Get("Content-Disposition"); !strings.Contains(got, "attachment") {
+		t.Errorf("Content-Disposition = %q", got)
+	}
+	want := "line,field,value,error\n" +
+		"3,isContacted,perhaps,isContacted must be yes or no\n" +
+		"4,companyName,infosys,same company as line 2\n"
+	if rec.Body.String() != want {
+		t.Errorf("error report = %q, want %q", rec.Body.String(), want)
+	}
+}
+
+func TestImportCompaniesErrors(t *testing.T) {
+	router := newTestRouter(t)
+	data := []byte("Company,City\nInfosys,Bangalore\n")
+
+	rec := doRequest(t, router, http.MethodPost, "/company/import", "Company\nInfosys\n")
+	expectStatus(t, rec, http.StatusBadRequest)
+	tests := []struct {
+		name   string
+		data   []byte
+		fields map[string]string
+	}{
+		{"empty file", []byte("\n\n"), nil},
+		{"header only", []byte("Company,City\n"), nil},
+		{"no name column", []byte("Firm,City\nInfosys,Bangalore\n"), nil},
+		{"malformed mapping", data, map[string]string{"mapping": "[]"}},
+		{"unknown column", data, map[string]string{"mapping": `{"Company":"companyName","State":"companyAddress"}`}},
+		{"unknown field", data, map[string]string{"mapping": `{"Company":"companyName","City":"city"}`}},
+		{"field mapped twice", data, map[string]string{"mapping": `{"Company":"companyName","City":"companyName"}`}},
+		{"corrupt xlsx", []byte("PK\x03\x04 not really a zip"), nil},
+		{"invalid csv", []byte("Company\n\"Infosys\n"), nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := uploadSpreadsheet(t, router, tt.data, tt.fields)
+			expectStatus(t, rec, http.StatusBadRequest)
+		})
+	}
+}
+
+func TestCompanyOfficers(t *testing.T) {
+	router := newTestRouter(t)
+	created := createCompany(t, router, "Infosys", "bob", "alice")
+
+	rec := doRequest(t, router, http.MethodGet, "/company/"+created.ID+"/officers", nil)
+	expectStatus(t, rec, http.StatusOK)
+	var officers []entity.OfficerAssignment
+	decode(t, rec, &officers)
+	if len(officers) != 2 || officers[0].Username != "bob" || officers[0].Role != "primary" || officers[1].Username != "alice" || officers[1].Role != "secondary" {
+		t.Fatalf("unexpected officers: %+v", officers)
+	}
+	if officers[0].UserID == "" || officers[0].AssignedAt.IsZero() {
+		t.Errorf("incomplete assignment: %+v", officers[0])
+	}
+
+	rec = doRequestWithHeader(t, router, http.MethodPatch, "/company/"+created.ID, http.Header{"If-Match": {`"1"`}, "X-Username": {"manager"}}, `{"assignedOfficer": ["carol", "bob"]}`)
+	expectStatus(t, rec, http.StatusOK)
+	rec = doRequest(t, router, http.MethodGet, "/company/"+created.ID+"/officers", nil)
+	decode(t, rec, &officers)
+	if len(officers) != 2 || officers[0].Username != "carol" || officers[0].AssignedBy != "manager" || officers[1].Username != "bob" || officers[1].Role != "secondary" {
+		t.Errorf("unexpected officers after reassigning: %+v", officers)
+	}
+
+	rec = doRequest(t, router, http.MethodGet, "/company/00000000-0000-0000-0000-000000000000/officers", nil)
+	expectStatus(t, rec, http.StatusNotFound)
+}
+
+func TestUnknownOfficers(t *testing.T) {
+	router := newTestRouter(t)
+
+	rec := doRequest(t, router, http.MethodPost, "/company/create", companyPresenter.CreateCompany{CompanyName: "Infosys", AssignedOfficer: []string{"alice", "mallory"}})
+	expectStatus(t, rec, http.StatusBadRequest)
+	if !strings.Contains(rec.Body.String(), "mallory") {
+		t.Errorf("error does not name the unknown officer: %s", rec.Body.String())
+	}
+
+	created := createCompany(t, router, "TCS", "alice")
+	rec = doRequestWithHeader(t, router, http.MethodPatch, "/company/"+created.ID, ifMatch(1), `{"assignedOfficer": ["mallory"]}`)
+	expectStatus(t, rec, http.StatusBadRequest)
+
+	rec = doRequest(t, router, http.MethodPost, "/company/temp/update", companyPresenter.CreateCompanyTemp{CompanyID: created.ID, CompanyName: "TCS", AssignedOfficer: []string{"mallory"}, CreatedBy: "officer"})
+	expectStatus(t, rec, http.StatusBadRequest)
+
+	rec = uploadSpreadsheet(t, router, []byte("Company,Officers\nWipro,alice; mallory\n"), map[string]string{"commit": "true"})
+	expectStatus(t, rec, http.StatusUnprocessableEntity)
+	var report companyPresenter.ImportCompanies
+	decode(t, rec, &report)
+	if report.Invalid != 1 || len(report.Rows[0].Errors) != 1 || report.Rows[0].Errors[0].Field != "assignedOfficer" || !strings.Contains(report.Rows[0].Errors[0].Message, "mallory") {
+		t.Errorf("unexpected import report: %+v", report.Rows[0])
+	}
+}
+
+func TestAssignOfficer(t *testing.T) {
+	router := newTestRouter(t)
+	createCompany(t, router, "Infosys", "alice")
+	tcs := createCompany(t, router, "TCS", "alice")
+	createCompany(t, router, "Accenture", "bob")
+
+	// Carol and officer are free; carol comes first alphabetically.
+	rec := doRequest(t, router, http.MethodPost, "/company/create", companyPresenter.CreateCompany{CompanyName: "Wipro", TypeOfDrive: "on-campus", AutoAssignOfficer: true})
+	expectStatus(t, rec, http.StatusOK)
+	var created companyPresenter.CreatedCompany
+	decode(t, rec, &created)
+	if created.AutoAssigned == nil || created.AutoAssigned.Username != "carol" || strings.Join(created.AssignedOfficer, ",") != "carol" {
+		t.Fatalf("auto-assigned %+v, officers %v; want carol", created.AutoAssigned, created.AssignedOfficer)
+	}
+
+	rec = doRequestWithHeader(t, router, http.MethodGet, "/company/"+tcs.ID+"/officers/suggestions", exportAs("Officer", "bob"), nil)
+	expectStatus(t, rec, http.StatusForbidden)
+	rec = doRequestWithHeader(t, router, http.MethodGet, "/company/"+tcs.ID+"/officers/suggestions", exportAs("Manager", "manager"), nil)
+	expectStatus(t, rec, http.StatusOK)
+	var suggestions []entity.OfficerSuggestion
+	decode(t, rec, &suggestions)
+	if len(suggestions) != 3 || suggestions[0].Username != "officer" || suggestions[0].Load != 0 {
+		t.Fatalf("unexpected suggestions: %+v", suggestions)
+	}
+	for _, s := range suggestions {
+		if s.Username == "alice" || len(s.Reasons) == 0 {
+			t.Errorf("unexpected suggestion: %+v", s)
+		}
+	}
+
+	header := exportAs("Manager", "manager")
+	header.Set("If-Match", `"1"`)
+	rec = doRequestWithHeader(t, router, http.MethodPost, "/company/"+tcs.ID+"/officers/assign", header, nil)
+	expectStatus(t, rec, http.StatusOK)
+	var assigned companyPresenter.AssignedOfficer
+	decode(t, rec, &assigned)
+	if assigned.Officer.Username != "officer" || strings.Join(assigned.Company.AssignedOfficer, ",") != "officer,alice" {
+		t.Errorf("assigned %s, officers %v; want officer,alice", assigned.Officer.Username, assigned.Company.AssignedOfficer)
+	}
+	if got := rec.Header().Get("ETag"); got != `"2"` {
+		t.Errorf("ETag = %s, want \"2\"", got)
+	}
+
+	rec = doRequestWithHeader(t, router, http.MethodPost, "/company/"+tcs.ID+"/officers/assign", header, nil)
+	expectStatus(t, rec, http.StatusPreconditionFailed)
+}
+
+func TestRebalance(t *testing.T) {
+	router := newTestRouter(t)
+	for _, name := range []string{"Accenture", "Cognizant", "Infosys", "TCS", "Wipro"} {
+		createCompany(t, router, name, "alice")
+	}
+	createCompany(t, router, "Zoho", "bob")
+
+	rec := doRequestWithHeader(t, router, http.MethodGet, "/company/rebalance", exportAs("Officer", "alice"), nil)
+	expectStatus(t, rec, http.StatusForbidden)
+	rec = doRequestWithHeader(t, router, http.MethodGet, "/company/rebalance", exportAs("Manager", "manager"), nil)
+	expectStatus(t, rec, http.StatusOK)
+	var plan entity.RebalancePlan
+	decode(t, rec, &plan)
+	if plan.TargetLoad != 2 || plan.Before["alice"] != 5 || len(plan.Moves) != 3 {
+		t.Fatalf("unexpected plan: %+v", plan)
+	}
+	if plan.After["alice"] != 2 || plan.After["bob"] != 2 || plan.After["carol"] != 1 || plan.After["officer"] != 1 {
+		t.Errorf("unexpected loads after: %v", plan.After)
+	}
+	for _, move := range plan.Moves {
+		if move.From != "alice" || move.Version != 1 || move.Reason == "" {
+			t.Errorf("unexpected move: %+v", move)
+		}
+	}
+
+	// Previewing changes nothing.
+	rec = doRequest(t, router, http.MethodGet, "/company/list/alice", nil)
+	var companies []entity.Company
+	decode(t, rec, &companies)
+	if len(companies) != 5 {
+		t.Fatalf("alice has %d companies after the preview, want 5", len(companies))
+	}
+
+	// A company edited after the preview is skipped.
+	stale := plan.Moves[0]
+	rec = doRequestWithHeader(t, router, http.MethodPatch, "/company/"+stale.CompanyID, ifMatch(1), `{"remarks": "met HR"}`)
+	expectStatus(t, rec, http.StatusOK)
+
+	rec = doRequestWithHeader(t, router, http.MethodPost, "/company/rebalance", exportAs("Manager", "manager"), companyPresenter.ApplyRebalance{})
+	expectStatus(t, rec, http.StatusBadRequest)
+	rec = doRequestWithHeader(t, router, http.MethodPost, "/company/rebalance", exportAs("Manager", "manager"), map[string]interface{}{"moves": plan.Moves})
+	expectStatus(t, rec, http.StatusOK)
+	var results []entity.RebalanceResult
+	decode(t, rec, &results)
+	if len(results) != 3 || results[0].Status != company.MoveStale || results[1].Status != company.MoveApplied || results[2].Status != company.MoveApplied {
+		t.Fatalf("unexpected results: %+v", results)
+	}
+
+	rec = doRequest(t, router, http.MethodGet, "/company/list/alice", nil)
+	decode(t, rec, &companies)
+	if len(companies) != 3 {
+		t.Errorf("alice has %d companies after rebalancing, want 3", len(companies))
+	}
+	rec = doRequest(t, router, http.MethodGet, "/company/"+plan.Moves[1].CompanyID+"/officers", nil)
+	var officers []entity.OfficerAssignment
+	decode(t, rec, &officers)
+	if len(officers) != 1 || officers[0].Username != plan.Moves[1].To || officers[0].AssignedBy != "manager" {
+		t.Errorf("unexpected officers after the move: %+v", officers)
+	}
+}
+
+func TestOfficerPortfolio(t *testing.T) {
+	router := newTestRouter(t)
+	infosys := createCompany(t, router, "Infosys", "alice")
+	rec := doRequest(t, router, http.MethodPost, "/company/create", companyPresenter.CreateCompany{CompanyName: "TCS", AssignedOfficer: []string{"alice", "bob"}})
+	expectStatus(t, rec, http.StatusOK)
+	var tcs entity.Company
+	decode(t, rec, &tcs)
+	wipro := createCompany(t, router, "Wipro", "bob")
+	now := time.Now().UTC()
+
+	createFollowUp(t, router, companyPresenter.SaveFollowUp{CompanyID: infosys.ID, Officer: "alice", DueAt: now.Add(-time.Hour).Format(time.RFC3339), Note: "call back"})
+	createFollowUp(t, router, companyPresenter.SaveFollowUp{CompanyID: infosys.ID, Officer: "alice", DueAt: now.Add(time.Hour).Format(time.RFC3339), Note: "not yet"})
+	createCompanyTemp(t, router, infosys.ID, "Infosys Limited")
+	rec = doRequest(t, router, http.MethodPost, "/company/temp/update", companyPresenter.CreateCompanyTemp{CompanyID: tcs.ID, CompanyName: "TCS Ltd", CreatedBy: "alice"})
+	expectStatus(t, rec, http.StatusOK)
+	for _, e := range []struct {
+		title     string
+		companyID string
+		in        time.Duration
+	}{
+		{"TCS drive", tcs.ID, 72 * time.Hour},
+		{"Infosys pre-placement talk", infosys.ID, 24 * time.Hour},
+		{"Infosys last year", infosys.ID, -24 * time.Hour},
+		{"Infosys next month", infosys.ID, 30 * 24 * time.Hour},
+		{"Wipro drive", wipro.ID, 24 * time.Hour},
+		{"Placement cell meeting", "", 24 * time.Hour},
+	} {
+		rec = doRequest(t, router, http.MethodPost, "/event/create", map[string]string{
+			"date": now.Add(e.in).Format(time.RFC3339), "type": "drive", "title": e.title, "company_id": e.companyID, "created_by": "manager",
+		})
+		expectStatus(t, rec, http.StatusOK)
+	}
+
+	alice := exportAs("Officer", "alice")
+	rec = doRequestWithHeader(t, router, http.MethodGet, "/company/portfolio", alice, nil)
+	expectStatus(t, rec, http.StatusOK)
+	var portfolio entity.OfficerPortfolio
+	decode(t, rec, &portfolio)
+	want := entity.PortfolioStats{Companies: 2, Contacted: 1, NotContacted: 1, OverdueFollowUps: 1, PendingProposals: 1, UpcomingEvents: 2}
+	if portfolio.Officer != "alice" || portfolio.Stats != want {
+		t.Fatalf("stats = %+v, want %+v", portfolio.Stats, want)
+	}
+	if got := portfolio.Companies[company.StageContacted]; len(got) != 1 || got[0].ID != infosys.ID {
+		t.Errorf("contacted companies = %+v", got)
+	}
+	if got := portfolio.Companies[company.StageProspect]; len(got) != 1 || got[0].ID != tcs.ID {
+		t.Errorf("prospects = %+v", got)
+	}
+	if got, ok := portfolio.Companies[company.StageNotInterested]; !ok || len(got) != 0 {
+		t.Errorf("not_interested companies = %+v, want an empty group", got)
+	}
+	if portfolio.OverdueFollowUps[0].Note != "call back" || portfolio.PendingProposals[0].CompanyName != "TCS Ltd" {
+		t.Errorf("unexpected follow-ups %+v or proposals %+v", portfolio.OverdueFollowUps[0], portfolio.PendingProposals[0])
+	}
+	if e := portfolio.UpcomingEvents; e[0].Title != "Infosys pre-placement talk" || e[1].Title != "TCS drive" {
+		t.Errorf("upcoming events = %s, %s", e[0].Title, e[1].Title)
+	}
+
+	rec = doRequestWithHeader(t, router, http.MethodGet, "/company/portfolio/alice?within=1h", exportAs("Manager", "manager"), nil)
+	expectStatus(t, rec, http.StatusOK)
+	decode(t, rec, &portfolio)
+	if portfolio.Stats.UpcomingEvents != 0 {
+		t.Errorf("events within an hour = %+v, want none", portfolio.UpcomingEvents)
+	}
+
+	rec = doRequestWithHeader(t, router, http.MethodGet, "/company/portfolio/bob", alice, nil)
+	expectStatus(t, rec, http.StatusForbidden)
+	rec = doRequestWithHeader(t, router, http.MethodGet, "/company/portfolio/mallory", exportAs("Manager", "manager"), nil)
+	expectStatus(t, rec, http.StatusNotFound)
+	rec = doRequestWithHeader(t, router, http.MethodGet, "/company/portfolio?within=forever", alice, nil)
+	expectStatus(t, rec, http.StatusBadRequest)
+
+	rec = doRequest(t, router, http.MethodPost, "/event/create", map[string]string{
+		"date": now.Format(time.RFC3339), "type": "drive", "title": "Ghost drive", "company_id": "00000000-0000-0000-0000-000000000000", "created_by": "manager",
+	})
+	expectStatus(t, rec, http.StatusBadRequest)
+}
+
+func exportAs(role, username string) http.Header {
+	return http.Header{"X-User-Role": {role}, "X-Username": {username}}
+}
+
+func TestExportCompaniesCSV(t *testing.T) {
+	router := newTestRouter(t)
+	rec := doRequest(t, router, http.MethodPost, "/company/create", companyPresenter.CreateCompany{
+		CompanyName:     "Infosys",
+		Drive:           "2026",
+		Remarks:         "=HYPERLINK(\"http://example.com\")",
+		Hr1Details:      "Asha, asha@infosys.com",
+		AssignedOfficer: []string{"alice"},
+	})
+	expectStatus(t, rec, http.StatusOK)
+	createCompany(t, router, "Wipro", "bob")
+
+	rec = doRequestWithHeader(t, router, http.MethodGet, "/company/export?officer=alice&columns=companyName,hr1Details,remarks", exportAs("Manager", "manager"), nil)
+	expectStatus(t, rec, http.StatusOK)
+	if got := rec.Header().Get("Content-Type"); !strings.HasPrefix(got, "text/csv") {
+		t.Errorf("Content-Type = %q", got)
+	}
+	if got := rec.Header().Get("Content-Disposition"); !strings.Contains(got, `filename="companies-alice-`) {
+		t.Errorf("Content-Disposition = %q", got)
+	}
+	rows, err := readCSV(rec.Body.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][]string{{"Company", "HR 1", "Remarks"}, {"Infosys", "Asha, asha@infosys.com", "'=HYPERLINK(\"http://example.com\")"}}
+	if fmt.Sprint(rows) != fmt.Sprint(want) {
+		t.Errorf("export = %q, want %q", rows, want)
+	}
+
+	headings := func(header http.Header, query string) []string {
+		t.Helper()
+		rec := doRequestWithHeader(t, router, http.MethodGet, "/company/export"+query, header, nil)
+		expectStatus(t, rec, http.StatusOK)
+		rows, err := readCSV(rec.Body.Bytes())
+		if err != nil || len(rows) == 0 {
+			t.Fatalf("export %s: rows %v, err %v", query, rows, err)
+		}
+		return rows[0]
+	}
+	if got := strings.Join(headings(exportAs("Admin", "admin"), ""), ","); !strings.Contains(got, "HR 1") || !strings.Contains(got, "Contact details") {
+		t.Errorf("admin export headings = %s, want HR contact details", got)
+	}
+	if got := strings.Join(headings(exportAs("Admin", "admin"), "?omit_contacts=true"), ","); strings.Contains(got, "HR") || strings.Contains(got, "Contact details") {
+		t.Errorf("export headings with omit_contacts = %s", got)
+	}
+	if got := strings.Join(headings(exportAs("Officer", "alice"), ""), ","); strings.Contains(got, "HR") {
+		t.Errorf("officer export headings = %s, want no HR contact details", got)
+	}
+
+	rec = doRequestWithHeader(t, router, http.MethodGet, "/company/export", exportAs("Officer", "bob"), nil)
+	expectStatus(t, rec, http.StatusOK)
+	if rows, _ := readCSV(rec.Body.Bytes()); len(rows) != 2 || rows[1][0] != "Wipro" {
+		t.Errorf("officer export = %q, want only Wipro", rows)
+	}
+	rec = doRequestWithHeader(t, router, http.MethodGet, "/company/export/alice", exportAs("Admin", "admin"), nil)
+	expectStatus(t, rec, http.StatusOK)
+	if rows, _ := readCSV(rec.Body.Bytes()); len(rows) != 2 || rows[1][0] != "Infosys" {
+		t.Errorf("export of alice's companies = %q", rows)
+	}
+}
+
+func TestExportCompaniesErrors(t *testing.T) {
+	router := newTestRouter(t)
+	tests := []struct {
+		name   string
+		path   string
+		header http.Header
+		status int
+	}{
+		{"no role", "/company/export", nil, http.StatusUnauthorized},
+		{"bad filter", "/company/export?is_contacted=maybe", exportAs("Admin", "admin"), http.StatusBadRequest},
+		{"unknown format", "/company/export?format=doc", exportAs("Admin", "admin"), http.StatusBadRequest},
+		{"unknown column", "/company/export?columns=companyName,salary", exportAs("Admin", "admin"), http.StatusBadRequest},
+		{"repeated column", "/company/export?columns=companyName,companyName", exportAs("Admin", "admin"), http.StatusBadRequest},
+		{"omitted column requested", "/company/export?columns=hr1Details&omit_contacts=true", exportAs("Admin", "admin"), http.StatusBadRequest},
+		{"officer exports another officer", "/company/export/bob", exportAs("Officer", "alice"), http.StatusForbidden},
+		{"officer filters on another officer", "/company/export?officer=bob", exportAs("Officer", "alice"), http.StatusForbidden},
+		{"officer includes contacts", "/company/export?omit_contacts=false", exportAs("Officer", "alice"), http.StatusForbidden},
+		{"officer requests contacts", "/company/export?columns=hr1Details", exportAs("Officer", "alice"), http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := doRequestWithHeader(t, router, http.MethodGet, tt.path, tt.header, nil)
+			expectStatus(t, rec, tt.status)
+		})
+	}
+}
+
+func TestExportCompaniesXLSX(t *testing.T) {
+	router := newTestRouter(t)
+	createCompany(t, router, "Infosys & Co", "alice", "bob")
+	createCompany(t, router, "Wipro <India>")
+
+	rec := doRequestWithHeader(t, router, http.MethodGet, "/company/export?format=xlsx&sort=company_name&columns=companyName,assignedOfficer,isContacted", exportAs("Admin", "admin"), nil)
+	expectStatus(t, rec, http.StatusOK)
+	if got := rec.Header().Get("Content-Disposition"); !strings.HasSuffix(got, `.xlsx"`) {
+		t.Errorf("Content-Disposition = %q", got)
+	}
+	rows, err := readXLSX(rec.Body.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][]string{{"Company", "Officers", "Contacted"}, {"Infosys & Co", "alice, bob", "yes"}, {"Wipro <India>", "", "yes"}}
+	if fmt.Sprint(rows) != fmt.Sprint(want) {
+		t.Errorf("export = %q, want %q", rows, want)
+	}
+}
+
+func TestExportCompaniesPDF(t *testing.T) {
+	router := newTestRouter(t)
+	// More than one page of the listing, and many pages of the report.
+	for i := 0; i <= exportPageSize; i++ {
+		createCompany(t, router, fmt.Sprintf("Company %03d", i), "alice")
+	}
+
+	rec := doRequestWithHeader(t, router, http.MethodGet, "/company/export", exportAs("Admin", "admin"), nil)
+	expectStatus(t, rec, http.StatusOK)
+	if rows, _ := readCSV(rec.Body.Bytes()); len(rows) != exportPageSize+2 {
+		t.Errorf("CSV export has %d rows, want %d", len(rows), exportPageSize+2)
+	}
+
+	rec = doRequestWithHeader(t, router, http.MethodGet, "/company/export?format=pdf&drive=2026", exportAs("Admin", "admin"), nil)
+	expectStatus(t, rec, http.StatusOK)
+	if got := rec.Header().Get("Content-Type"); got != "application/pdf" {
+		t.Errorf("Content-Type = %q", got)
+	}
+	pdf := rec.Body.String()
+	if !strings.HasPrefix(pdf, "%PDF-1.4\n") || !strings.HasSuffix(pdf, "%%EOF\n") {
+		t.Fatalf("not a PDF: %.40q…%q", pdf, pdf[max(0, len(pdf)-20):])
+	}
+	for _, text := range []string{"(Company report)", "(Company 000)", "(Company 500)", "drive=2026"} {
+		if !strings.Contains(pdf, text) {
+			t.Errorf("PDF does not contain %s", text)
+		}
+	}
+	var pages int
+	if i := strings.Index(pdf, "/Type /Pages"); i < 0 {
+		t.Fatal("PDF has no page tree")
+	} else {
+		fmt.Sscanf(pdf[strings.Index(pdf[i:], "/Count ")+i:], "/Count %d", &pages)
+	}
+	if pages < 2 {
+		t.Errorf("PDF has %d pages, want several", pages)
+	}
+
+	// Every cross-reference entry must point at its object.
+	var xref, size int
+	fmt.Sscanf(pdf[strings.LastIndex(pdf, "startxref"):], "startxref\n%d", &xref)
+	if _, err := fmt.Sscanf(pdf[xref:], "xref\n0 %d\n", &size); err != nil {
+		t.Fatalf("startxref %d does not point at the xref table: %v", xref, err)
+	}
+	entries := strings.Split(pdf[xref:], "\n")[3 : 3+size-1]
+	for i, entry := range entries {
+		var offset int
+		fmt.Sscanf(entry, "%d", &offset)
+		if want := fmt.Sprintf("%d 0 obj\n", i+1); !strings.HasPrefix(pdf[offset:], want) {
+			t.Errorf("xref entry %d points at %.12q", i+1, pdf[offset:])
+		}
+	}
+}
+
+func TestSeasons(t *testing.T) {
+	router := newTestRouter(t)
+	active := company.SeasonAt(time.Now())
+	next := company.NextSeason(active)
+	admin := exportAs("Admin", "admin")
+
+	rec := doRequest(t, router, http.MethodGet, "/company/seasons", nil)
+	expectStatus(t, rec, http.StatusOK)
+	var seasons []*entity.Season
+	decode(t, rec, &seasons)
+	if len(seasons) != 1 || seasons[0].Name != active || !seasons[0].Active || seasons[0].From.IsZero() {
+		t.Fatalf("seasons = %+v, want only %s, active", seasons, active)
+	}
+
+	rec = doRequestWithHeader(t, router, http.MethodPut, "/company/seasons/active", exportAs("Manager", "manager"), companyPresenter.ActiveSeason{Season: next})
+	expectStatus(t, rec, http.StatusForbidden)
+	rec = doRequestWithHeader(t, router, http.MethodPut, "/company/seasons/active", admin, companyPresenter.ActiveSeason{Season: "next year"})
+	expectStatus(t, rec, http.StatusBadRequest)
+	rec = doRequestWithHeader(t, router, http.MethodPut, "/company/seasons/active", admin, companyPresenter.ActiveSeason{Season: next})
+	expectStatus(t, rec, http.StatusOK)
+	var season entity.Season
+	decode(t, rec, &season)
+	if season.Name != next || !season.Active {
+		t.Errorf("active season = %+v, want %s", season, next)
+	}
+
+	// New companies join the active season, and listings show it alone.
+	created := createCompany(t, router, "Infosys", "alice")
+	if created.Season != next {
+		t.Errorf("new company in %q, want %s", created.Season, next)
+	}
+	rec = doRequestWithHeader(t, router, http.MethodPut, "/company/seasons/active", admin, companyPresenter.ActiveSeason{Season: active})
+	expectStatus(t, rec, http.StatusOK)
+	rec = doRequest(t, router, http.MethodGet, "/company/list", nil)
+	expectStatus(t, rec, http.StatusOK)
+	if got := rec.Header().Get("X-Total-Count"); got != "0" {
+		t.Errorf("active season lists %s companies, want 0", got)
+	}
+	rec = doRequest(t, router, http.MethodGet, "/company/list?season=all", nil)
+	if got := rec.Header().Get("X-Total-Count"); got != "1" {
+		t.Errorf("every season lists %s companies, want 1", got)
+	}
+	rec = doRequest(t, router, http.MethodGet, "/company/list?season="+next, nil)
+	if got := rec.Header().Get("X-Total-Count"); got != "1" {
+		t.Errorf("%s lists %s companies, want 1", next, got)
+	}
+	rec = doRequest(t, router, http.MethodGet, "/company/list?season=soon", nil)
+	expectStatus(t, rec, http.StatusBadRequest)
+}
+
+func TestRolloverSeason(t *testing.T) {
+	router := newTestRouter(t)
+	active := company.SeasonAt(time.Now())
+	next := company.NextSeason(active)
+	admin := exportAs("Admin", "admin")
+	infosys := createCompany(t, router, "Infosys", "alice")
+	tcs := createCompany(t, router, "TCS", "bob")
+	rec := doRequestWithHeader(t, router, http.MethodPost, "/company/"+tcs.ID+"/archive", admin, nil)
+	expectStatus(t, rec, http.StatusOK)
+
+	rec = doRequestWithHeader(t, router, http.MethodPost, "/company/seasons/rollover", exportAs("Manager", "manager"), companyPresenter.Rollover{})
+	expectStatus(t, rec, http.StatusForbidden)
+	rec = doRequestWithHeader(t, router, http.MethodPost, "/company/seasons/rollover", admin, companyPresenter.Rollover{To: active})
+	expectStatus(t, rec, http.StatusBadRequest)
+	rec = doRequestWithHeader(t, router, http.MethodPost, "/company/seasons/rollover", admin, companyPresenter.Rollover{CompanyIDs: []string{"00000000-0000-0000-0000-000000000000"}})
+	expectStatus(t, rec, http.StatusBadRequest)
+
+	// Archived companies stay behind; the rest start over as prospects.
+	rec = doRequestWithHeader(t, router, http.MethodPost, "/company/seasons/rollover", admin, companyPresenter.Rollover{Activate: true})
+	expectStatus(t, rec, http.StatusOK)
+	var rollover entity.SeasonRollover
+	decode(t, rec, &rollover)
+	if rollover.From != active || rollover.To != next || len(rollover.Carried) != 1 || len(rollover.Skipped) != 0 {
+		t.Fatalf("rollover = %+v, want Infosys carried from %s to %s", rollover, active, next)
+	}
+	copied := rollover.Carried[0]
+	if copied.CarriedFrom != infosys.ID || copied.Season != next || copied.PipelineStatus != company.StageProspect || strings.Join(copied.AssignedOfficer, ",") != "alice" {
+		t.Errorf("carried company = %+v", copied)
+	}
+
+	// The next season is now active, and a second rollover carries nothing.
+	rec = doRequest(t, router, http.MethodGet, "/company/list", nil)
+	var companies []*entity.Company
+	decode(t, rec, &companies)
+	if len(companies) != 1 || companies[0].ID != copied.ID {
+		t.Errorf("active season companies = %+v, want the copy", companies)
+	}
+	rec = doRequestWithHeader(t, router, http.MethodPost, "/company/seasons/rollover", admin, companyPresenter.Rollover{From: active, To: next})
+	expectStatus(t, rec, http.StatusOK)
+	decode(t, rec, &rollover)
+	if len(rollover.Carried) != 0 || len(rollover.Skipped) != 1 || rollover.Skipped[0] != infosys.ID {
+		t.Errorf("repeated rollover = %+v, want Infosys skipped", rollover)
+	}
+
+	rec = doRequest(t, router, http.MethodGet, "/company/seasons/compare?seasons=2026-27,tomorrow", nil)
+	expectStatus(t, rec, http.StatusBadRequest)
+	rec = doRequest(t, router, http.MethodGet, "/company/seasons/compare", nil)
+	expectStatus(t, rec, http.StatusOK)
+	var comparison entity.SeasonComparison
+	decode(t, rec, &comparison)
+	if len(comparison.Seasons) != 2 || comparison.Seasons[0].Season != active || comparison.Seasons[1].Season != next {
+		t.Fatalf("compared %+v, want %s and %s", comparison.Seasons, active, next)
+	}
+	previous, current := comparison.Seasons[0], comparison.Seasons[1]
+	if previous.Companies != 2 || current.Companies != 1 || current.CarriedForward != 1 || !current.Active || current.Stages[company.StageProspect] != 1 {
+		t.Errorf("season reports = %+v, %+v", previous, current)
+	}
+	if change := comparison.Changes[0]; change.Companies != -1 || change.Retained != 1 {
+		t.Errorf("change = %+v, want one company fewer, one retained", change)
+	}
+}
+
+func TestCompareSeasonsMedianPackage(t *testing.T) {
+	router := newTestRouter(t)
+	active := company.SeasonAt(time.Now())
+	previous := company.PreviousSeason(active)
+	admin := exportAs("Admin", "admin")
+	create := func(name, pkg string) {
+		t.Helper()
+		rec := doRequest(t, router, http.MethodPost, "/company/create", companyPresenter.CreateCompany{CompanyName: name, Package: pkg})
+		expectStatus(t, rec, http.StatusOK)
+	}
+
+	rec := doRequestWithHeader(t, router, http.MethodPut, "/company/seasons/active", admin, companyPresenter.ActiveSeason{Season: previous})
+	expectStatus(t, rec, http.StatusOK)
+	create("Zoho", "10 LPA")
+	create("Stripe", "$120k")
+	rec = doRequestWithHeader(t, router, http.MethodPut, "/company/seasons/active", admin, companyPresenter.ActiveSeason{Season: active})
+	expectStatus(t, rec, http.StatusOK)
+	create("Infosys", "6 LPA")
+	create("TCS", "8 LPA")
+	create("Google", "$200k")
+
+	// The dollar packages would drag both medians down; they are left out.
+	rec = doRequest(t, router, http.MethodGet, "/company/seasons/compare", nil)
+	expectStatus(t, rec, http.StatusOK)
+	var comparison entity.SeasonComparison
+	decode(t, rec, &comparison)
+	if len(comparison.Seasons) != 2 || len(comparison.Changes) != 1 {
+		t.Fatalf("unexpected comparison: %+v", comparison)
+	}
+	last, current := comparison.Seasons[0], comparison.Seasons[1]
+	if last.Companies != 2 || last.MedianPackage == nil || *last.MedianPackage != 10 {
+		t.Errorf("%s = %+v, want a median of 10", previous, last)
+	}
+	if current.Companies != 3 || current.MedianPackage == nil || *current.MedianPackage != 7 {
+		t.Errorf("%s = %+v, want a median of 7", active, current)
+	}
+	if change := comparison.Changes[0].MedianPackage; change == nil {
+		t.Error("no median change")
+	} else if *change != -3 {
+		t.Errorf("median change = %v, want -3", *change)
+	}
+}
+
+func createCustomField(t *testing.T, router http.Handler, req companyPresenter.CustomField) *entity.CustomField {
+	t.Helper()
+	rec := doRequestWithHeader(t, router, http.MethodPost, "/company/fields", exportAs("Admin", "admin"), req)
+	expectStatus(t, rec, http.StatusCreated)
+	var field entity.CustomField
+	decode(t, rec, &field)
+	return &field
+}
+
+func TestCustomFields(t *testing.T) {
+	router := newTestRouter(t)
+	admin := exportAs("Admin", "admin")
+
+	rec := doRequestWithHeader(t, router, http.MethodPost, "/company/fields", exportAs("Manager", "manager"), companyPresenter.CustomField{Key: "sector", Type: "text"})
+	expectStatus(t, rec, http.StatusForbidden)
+	for _, req := range []companyPresenter.CustomField{
+		{Key: "Sector", Type: "text"},
+		{Key: "sector", Type: "colour"},
+		{Key: "sector", Type: "enum"},
+	} {
+		rec = doRequestWithHeader(t, router, http.MethodPost, "/company/fields", admin, req)
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("create %+v: status = %d, want 400", req, rec.Code)
+		}
+	}
+
+	sector := createCustomField(t, router, companyPresenter.CustomField{Key: "sector", Label: "Sector", Type: "enum", Options: []string{"IT", "Core", "IT"}})
+	if sector.Label != "Sector" || len(sector.Options) != 2 {
+		t.Errorf("sector = %+v, want two options", sector)
+	}
+	createCustomField(t, router, companyPresenter.CustomField{Key: "bond_months", Type: "number"})
+	createCustomField(t, router, companyPresenter.CustomField{Key: "branches", Label: "Hiring branches", Type: "multi_select", Options: []string{"CSE", "ECE", "ME"}})
+	rec = doRequestWithHeader(t, router, http.MethodPost, "/company/fields", admin, companyPresenter.CustomField{Key: "sector", Type: "text"})
+	expectStatus(t, rec, http.StatusConflict)
+
+	// Key and type stay as they were.
+	rec = doRequestWithHeader(t, router, http.MethodPut, "/company/fields/"+sector.ID, admin, companyPresenter.CustomField{Key: "industry", Label: "Industry", Type: "text", Options: []string{"IT", "Core", "Finance"}})
+	expectStatus(t, rec, http.StatusOK)
+	var updated entity.CustomField
+	decode(t, rec, &updated)
+	if updated.Key != "sector" || updated.Type != "enum" || updated.Label != "Industry" || len(updated.Options) != 3 {
+		t.Errorf("updated = %+v", updated)
+	}
+	rec = doRequestWithHeader(t, router, http.MethodPut, "/company/fields/00000000-0000-0000-0000-000000000000", admin, companyPresenter.CustomField{Label: "x"})
+	expectStatus(t, rec, http.StatusNotFound)
+
+	rec = doRequest(t, router, http.MethodGet, "/company/fields", nil)
+	expectStatus(t, rec, http.StatusOK)
+	var fields []*entity.CustomField
+	decode(t, rec, &fields)
+	if len(fields) != 3 || fields[0].Key != "sector" || fields[2].Key != "branches" {
+		t.Errorf("fields = %+v", fields)
+	}
+
+	rec = doRequestWithHeader(t, router, http.MethodDelete, "/company/fields/"+sector.ID, exportAs("Manager", "manager"), nil)
+	expectStatus(t, rec, http.StatusForbidden)
+	rec = doRequestWithHeader(t, router, http.MethodDelete, "/company/fields/"+sector.ID, admin, nil)
+	expectStatus(t, rec, http.StatusOK)
+	rec = doRequestWithHeader(t, router, http.MethodDelete, "/company/fields/"+sector.ID, admin, nil)
+	expectStatus(t, rec, http.StatusNotFound)
+}
+
+func TestCompanyCustomValuesAndTags(t *testing.T) {
+	router := newTestRouter(t)
+	createCustomField(t, router, companyPresenter.CustomField{Key: "sector", Type: "enum", Options: []string{"IT", "Core"}, Required: true})
+	createCustomField(t, router, companyPresenter.CustomField{Key: "bond_months", Type: "number"})
+	createCustomField(t, router, companyPresenter.CustomField{Key: "branches", Type: "multi_select", Options: []string{"CSE", "ECE", "ME"}})
+
+	create := func(name string, values entity.CustomValues, tags ...string) *httptest.ResponseRecorder {
+		return doRequest(t, router, http.MethodPost, "/company/create", companyPresenter.CreateCompany{CompanyName: name, AssignedOfficer: []string{"alice"}, CustomFields: values, Tags: tags})
+	}
+	for _, values := range []entity.CustomValues{
+		{},
+		{"sector": "Finance"},
+		{"sector": "IT", "bond_months": "a year"},
+		{"sector": "IT", "branches": []string{"CIVIL"}},
+		{"sector": "IT", "location": "Pune"},
+	} {
+		if rec := create("Infosys", values); rec.Code != http.StatusBadRequest {
+			t.Errorf("create with %v: status = %d, want 400", values, rec.Code)
+		}
+	}
+	if rec := create("Infosys", entity.CustomValues{"sector": "IT"}, strings.Repeat("x", 41)); rec.Code != http.StatusBadRequest {
+		t.Errorf("create with a long tag: status = %d, want 400", rec.Code)
+	}
+
+	rec := create("Infosys", entity.CustomValues{"sector": "it", "bond_months": "12", "branches": []string{"cse", "ECE"}}, "Dream", " mass  recruiter", "dream")
+	expectStatus(t, rec, http.StatusOK)
+	var infosys entity.Company
+	decode(t, rec, &infosys)
+	want := entity.CustomValues{"sector": "IT", "bond_months": 12.0, "branches": []string{"CSE", "ECE"}}
+	if fmt.Sprint(infosys.CustomFields) != fmt.Sprint(want) || fmt.Sprint(infosys.Tags) != "[dream mass recruiter]" {
+		t.Errorf("created values %v tags %q", infosys.CustomFields, infosys.Tags)
+	}
+	rec = create("L&T", entity.CustomValues{"sector": "Core", "bond_months": 24, "branches": []string{"ME"}}, "dream")
+	expectStatus(t, rec, http.StatusOK)
+
+	list := func(query string) []string {
+		t.Helper()
+		rec := doRequest(t, router, http.MethodGet, "/company/list?"+query, nil)
+		expectStatus(t, rec, http.StatusOK)
+		var companies []*entity.Company
+		decode(t, rec, &companies)
+		var names []string
+		for _, c := range companies {
+			names = append(names, c.CompanyName)
+		}
+		sort.Strings(names)
+		return names
+	}
+	for query, want := range map[string]string{
+		"tag=dream":                         "[Infosys L&T]",
+		"tag=Mass+Recruiter":                "[Infosys]",
+		"tag=dream&tag=placed":              "[]",
+		"field.sector=core":                 "[L&T]",
+		"field.bond_months=12.0":            "[Infosys]",
+		"field.branches=ece":                "[Infosys]",
+		"field.branches=ME&tag=dream":       "[L&T]",
+		"field.sector=IT&field.branches=ME": "[]",
+	} {
+		if got := fmt.Sprint(list(query)); got != want {
+			t.Errorf("list?%s = %s, want %s", query, got, want)
+		}
+	}
+	for _, query := range []string{"field.location=Pune", "field.sector=Finance", "field.bond_months=many", "field.sector="} {
+		rec := doRequest(t, router, http.MethodGet, "/company/list?"+query, nil)
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("list?%s: status = %d, want 400", query, rec.Code)
+		}
+	}
+
+	// PATCH merges custom fields by key and replaces tags.
+	rec = doRequestWithHeader(t, router, http.MethodPatch, "/company/"+infosys.ID, ifMatch(infosys.Version), `{"customFields": {"bond_months": null, "branches": ["ME"]}, "tags": ["service"]}`)
+	expectStatus(t, rec, http.StatusOK)
+	var patched entity.Company
+	decode(t, rec, &patched)
+	want = entity.CustomValues{"sector": "IT", "branches": []string{"ME"}}
+	if fmt.Sprint(patched.CustomFields) != fmt.Sprint(want) || fmt.Sprint(patched.Tags) != "[service]" {
+		t.Errorf("patched values %v tags %q", patched.CustomFields, patched.Tags)
+	}
+	rec = doRequestWithHeader(t, router, http.MethodPatch, "/company/"+infosys.ID, ifMatch(patched.Version), `{"customFields": {"sector": null}}`)
+	expectStatus(t, rec, http.StatusBadRequest)
+	rec = doRequestWithHeader(t, router, http.MethodPatch, "/company/"+infosys.ID, ifMatch(patched.Version), `{"customFields": ["IT"]}`)
+	expectStatus(t, rec, http.StatusBadRequest)
+
+	rec = doRequest(t, router, http.MethodGet, "/company/tags", nil)
+	expectStatus(t, rec, http.StatusOK)
+	var tags []*entity.TagCount
+	decode(t, rec, &tags)
+	if len(tags) != 2 || tags[0].Tag != "dream" || tags[0].Companies != 1 || tags[1].Tag != "service" {
+		t.Errorf("tags = %+v", tags)
+	}
+}
+
+func TestExportCustomFields(t *testing.T) {
+	router := newTestRouter(t)
+	createCustomField(t, router, companyPresenter.CustomField{Key: "sector", Label: "Sector", Type: "text"})
+	createCustomField(t, router, companyPresenter.CustomField{Key: "branches", Label: "Hiring branches", Type: "multi_select", Options: []string{"CSE", "ECE"}})
+	rec := doRequest(t, router, http.MethodPost, "/company/create", companyPresenter.CreateCompany{
+		CompanyName:  "Infosys",
+		CustomFields: entity.CustomValues{"sector": "IT", "branches": []string{"CSE", "ECE"}},
+		Tags:         []string{"dream", "service"},
+	})
+	expectStatus(t, rec, http.StatusOK)
+
+	rec = doRequestWithHeader(t, router, http.MethodGet, "/company/export?columns=companyName,tags,field.sector,field.branches", exportAs("Admin", "admin"), nil)
+	expectStatus(t, rec, http.StatusOK)
+	rows, err := readCSV(rec.Body.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][]string{{"Company", "Tags", "Sector", "Hiring branches"}, {"Infosys", "dream, service", "IT", "CSE, ECE"}}
+	if fmt.Sprint(rows) != fmt.Sprint(want) {
+		t.Errorf("export = %q, want %q", rows, want)
+	}
+
+	rec = doRequestWithHeader(t, router, http.MethodGet, "/company/export", exportAs("Admin", "admin"), nil)
+	expectStatus(t, rec, http.StatusOK)
+	if rows, _ := readCSV(rec.Body.Bytes()); len(rows) != 2 || !strings.HasSuffix(strings.Join(rows[0], ","), "Sector,Hiring branches") {
+		t.Errorf("default export headings = %q, want the custom fields last", rows[0])
+	}
+	rec = doRequestWithHeader(t, router, http.MethodGet, "/company/export?columns=field.location", exportAs("Admin", "admin"), nil)
+	expectStatus(t, rec, http.StatusBadRequest)
+	rec = doRequestWithHeader(t, router, http.MethodGet, "/company/export?field.sector=Core", exportAs("Admin", "admin"), nil)
+	expectStatus(t, rec, http.StatusOK)
+	if rows, _ := readCSV(rec.Body.Bytes()); len(rows) != 1 {
+		t.Errorf("filtered export = %q, want only headings", rows)
+	}
+}
+
+// newAttachmentRouter returns a router whose service keeps attachments of up
+// to maxSize bytes in a local store under the returned directory.
+func newAttachmentRouter(t *testing.T, maxSize int64) (*mux.Router, company.Usecase, string) {
+	t.Helper()
+	dir := t.TempDir()
+	blobs, err := localfs.NewBlobStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	service := company.NewServiceWithAttachments(newTestRepository(), company.DefaultPipeline(), blobs, maxSize)
+	router := mux.NewRouter()
+	RegisterHandlers(service, router, []string{testOrigin})
+	return router, service, dir
+}
+
+// uploadAttachment posts data as the file of an attachment, preceded by a
+// checksum field when checksum is set.
+func uploadAttachment(t *testing.T, router http.Handler, companyID string, header http.Header, filename string, data []byte, checksum string) *httptest.ResponseRecorder {
+	t.Helper()
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	if checksum != "" {
+		form.WriteField("checksum", checksum)
+	}
+	part, err := form.CreateFormFile("file", filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	part.Write(data)
+	form.Close()
+	request := http.Header{"Content-Type": {form.FormDataContentType()}}
+	for key, values := range header {
+		request[key] = values
+	}
+	return doRequestWithHeader(t, router, http.MethodPost, "/company/"+companyID+"/attachments", request, body.String())
+}
+
+// storedBlobs counts the files in a local blob store.
+func storedBlobs(t *testing.T, dir string) int {
+	t.Helper()
+	count := 0
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err == nil && !d.IsDir() {
+			count++
+		}
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return count
+}
+
+func TestCompanyAttachments(t *testing.T) {
+	router, _, dir := newAttachmentRouter(t, 1024)
+	infosys := createCompany(t, router, "Infosys", "alice")
+	alice := exportAs("Officer", "alice")
+	pdf := []byte("%PDF-1.7\n1 0 obj << /Type /Catalog >> endobj\n%%EOF\n")
+	sum := sha256.Sum256(pdf)
+	checksum := hex.EncodeToString(sum[:])
+
+	rec := uploadAttachment(t, router, infosys.ID, alice, `C:\Users\alice\JD 2026.pdf`, pdf, strings.ToUpper(checksum))
+	expectStatus(t, rec, http.StatusCreated)
+	var jd entity.Attachment
+	decode(t, rec, &jd)
+	if jd.CompanyID != infosys.ID || jd.Filename != "JD 2026.pdf" || jd.ContentType != "application/pdf" || jd.Size != int64(len(pdf)) || jd.Checksum != checksum || jd.UploadedBy != "alice" {
+		t.Errorf("uploaded attachment = %+v", jd)
+	}
+	if strings.Contains(rec.Body.String(), "storageKey") || strings.Contains(rec.Body.String(), infosys.ID+"/") {
+		t.Errorf("response exposes the storage key: %s", rec.Body.String())
+	}
+	if storedBlobs(t, dir) != 1 {
+		t.Errorf("store holds %d blobs, want 1", storedBlobs(t, dir))
+	}
+
+	rec = doRequestWithHeader(t, router, http.MethodGet, "/company/"+infosys.ID+"/attachments", exportAs("Manager", "manager"), nil)
+	expectStatus(t, rec, http.StatusOK)
+	var listed []*entity.Attachment
+	decode(t, rec, &listed)
+	if len(listed) != 1 || listed[0].ID != jd.ID {
+		t.Errorf("attachments = %+v, want the JD", listed)
+	}
+
+	rec = doRequestWithHeader(t, router, http.MethodGet, "/company/attachments/"+jd.ID, alice, nil)
+	expectStatus(t, rec, http.StatusOK)
+	if !bytes.Equal(rec.Body.Bytes(), pdf) {
+		t.Errorf("downloaded %q, want the uploaded PDF", rec.Body.String())
+	}
+	for header, want := range map[string]string{
+		"Content-Type":           "application/pdf",
+		"Content-Length":         strconv.Itoa(len(pdf)),
+		"Content-Disposition":    `attachment; filename="JD 2026.pdf"`,
+		"X-Content-Type-Options": "nosniff",
+		"ETag":                   `"` + checksum + `"`,
+	} {
+		if got := rec.Header().Get(header); got != want {
+			t.Errorf("%s = %q, want %q", header, got, want)
+		}
+	}
+	rec = doRequestWithHeader(t, router, http.MethodGet, "/company/attachments/"+jd.ID, http.Header{
+		"X-User-Role": {"Officer"}, "X-Username": {"alice"}, "If-None-Match": {`"` + checksum + `"`},
+	}, nil)
+	expectStatus(t, rec, http.StatusNotModified)
+
+	rec = doRequestWithHeader(t, router, http.MethodDelete, "/company/attachments/"+jd.ID, alice, nil)
+	expectStatus(t, rec, http.StatusOK)
+	rec = doRequestWithHeader(t, router, http.MethodGet, "/company/attachments/"+jd.ID, alice, nil)
+	expectStatus(t, rec, http.StatusNotFound)
+	if storedBlobs(t, dir) != 0 {
+		t.Errorf("store holds %d blobs after deleting, want none", storedBlobs(t, dir))
+	}
+}
+
+func TestCompanyAttachmentPermissions(t *testing.T) {
+	router, _, _ := newAttachmentRouter(t, 1024)
+	infosys := createCompany(t, router, "Infosys", "alice")
+	rec := uploadAttachment(t, router, infosys.ID, exportAs("Officer", "alice"), "jd.txt", []byte("Software engineer, 12 LPA"), "")
+	expectStatus(t, rec, http.StatusCreated)
+	var jd entity.Attachment
+	decode(t, rec, &jd)
+
+	bob := exportAs("Officer", "bob")
+	expectStatus(t, uploadAttachment(t, router, infosys.ID, bob, "mou.txt", []byte("MoU"), ""), http.StatusForbidden)
+	expectStatus(t, doRequestWithHeader(t, router, http.MethodGet, "/company/"+infosys.ID+"/attachments", bob, nil), http.StatusForbidden)
+	expectStatus(t, doRequestWithHeader(t, router, http.MethodGet, "/company/attachments/"+jd.ID, bob, nil), http.StatusForbidden)
+	expectStatus(t, doRequestWithHeader(t, router, http.MethodDelete, "/company/attachments/"+jd.ID, bob, nil), http.StatusForbidden)
+
+	expectStatus(t, uploadAttachment(t, router, infosys.ID, nil, "mou.txt", []byte("MoU"), ""), http.StatusUnauthorized)
+	expectStatus(t, doRequest(t, router, http.MethodGet, "/company/attachments/"+jd.ID, nil), http.StatusUnauthorized)
+	expectStatus(t, doRequestWithHeader(t, router, http.MethodGet, "/company/attachments/"+jd.ID, exportAs("Admin", ""), nil), http.StatusOK)
+	expectStatus(t, doRequestWithHeader(t, router, http.MethodGet, "/company/00000000-0000-0000-0000-000000000000/attachments", exportAs("Admin", "admin"), nil), http.StatusNotFound)
+
+	// Attachments go to the trash with their company.
+	expectStatus(t, doRequest(t, router, http.MethodDelete, "/company/delete/"+infosys.ID, nil), http.StatusOK)
+	expectStatus(t, doRequestWithHeader(t, router, http.MethodGet, "/company/attachments/"+jd.ID, exportAs("Admin", "admin"), nil), http.StatusNotFound)
+}
+
+func TestUploadAttachmentErrors(t *testing.T) {
+	router, _, dir := newAttachmentRouter(t, 1024)
+	infosys := createCompany(t, router, "Infosys", "alice")
+	alice := exportAs("Officer", "alice")
+
+	docx := []byte("PK\x03\x04\x14\x00\x06\x00" + strings.Repeat("\x00", 40))
+	rec := uploadAttachment(t, router, infosys.ID, alice, "offer.docx", docx, "")
+	expectStatus(t, rec, http.StatusCreated)
+	var offer entity.Attachment
+	decode(t, rec, &offer)
+	if offer.ContentType != "application/vnd.openxmlformats-officedocument.wordprocessingml.document" {
+		t.Errorf("docx content type = %q", offer.ContentType)
+	}
+
+	cases := []struct {
+		name     string
+		filename string
+		data     []byte
+		checksum string
+		status   int
+	}{
+		{"html", "jd.pdf", []byte("<html><script>alert(1)</script></html>"), "", http.StatusUnsupportedMediaType},
+		{"zip", "archive.zip", docx, "", http.StatusUnsupportedMediaType},
+		{"executable", "setup.exe", []byte("MZ\x90\x00\x03\x00\x00\x00"), "", http.StatusUnsupportedMediaType},
+		{"too large", "jd.txt", []byte(strings.Repeat("a", 1025)), "", http.StatusRequestEntityTooLarge},
+		{"far too large", "jd.txt", []byte(strings.Repeat("a", 200<<10)), "", http.StatusRequestEntityTooLarge},
+		{"checksum mismatch", "jd.txt", []byte("Software engineer"), strings.Repeat("0", 64), http.StatusBadRequest},
+		{"malformed checksum", "jd.txt", []byte("Software engineer"), "abc", http.StatusBadRequest},
+		{"empty", "jd.txt", nil, "", http.StatusBadRequest},
+		{"no name", "", []byte("Software engineer"), "", http.StatusBadRequest},
+	}
+	for _, c := range cases {
+		rec := uploadAttachment(t, router, infosys.ID, alice, c.filename, c.data, c.checksum)
+		if rec.Code != c.status {
+			t.Errorf("%s: status %d, want %d: %s", c.name, rec.Code, c.status, rec.Body.String())
+		}
+	}
+	if n := storedBlobs(t, dir); n != 1 {
+		t.Errorf("store holds %d blobs after failed uploads, want only the docx", n)
+	}
+
+	rec = doRequestWithHeader(t, router, http.MethodPost, "/company/"+infosys.ID+"/attachments", alice, `{"file": "jd.pdf"}`)
+	expectStatus(t, rec, http.StatusBadRequest)
+
+	// Without a blob store there is nowhere to keep attachments.
+	plain := newTestRouter(t)
+	tcs := createCompany(t, plain, "TCS", "alice")
+	expectStatus(t, uploadAttachment(t, plain, tcs.ID, alice, "jd.txt", []byte("JD"), ""), http.StatusServiceUnavailable)
+}
+
+func TestPurgeRemovesAttachmentContents(t *testing.T) {
+	router, service, dir := newAttachmentRouter(t, 1024)
+	infosys := createCompany(t, router, "Infosys", "alice")
+	tcs := createCompany(t, router, "TCS", "alice")
+	alice := exportAs("Officer", "alice")
+	expectStatus(t, uploadAttachment(t, router, infosys.ID, alice, "jd.txt", []byte("Infosys JD"), ""), http.StatusCreated)
+	expectStatus(t, uploadAttachment(t, router, tcs.ID, alice, "jd.txt", []byte("TCS JD"), ""), http.StatusCreated)
+
+	expectStatus(t, doRequest(t, router, http.MethodDelete, "/company/delete/"+infosys.ID, nil), http.StatusOK)
+	purged, err := service.PurgeDeletedCompanies(-time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if purged != 1 {
+		t.Errorf("purged %d companies, want 1", purged)
+	}
+	if n := storedBlobs(t, dir); n != 1 {
+		t.Errorf("store holds %d blobs after purging, want only TCS's", n)
+	}
+}
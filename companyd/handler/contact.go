@@ -0,0 +1,175 @@
+package companyHandler
+
+import (
+	"backend/companyd/entity"
+	companyPresenter "backend/companyd/presenter"
+	"backend/companyd/usecase/company"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"net/mail"
+	"regexp"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+var uuidRegex = regexp.MustCompile(`(?i)^` + uuidPattern + `$`)
+
+// contactFromRequest validates a create or update body and normalises it
+// into a contact.
+func contactFromRequest(r *http.Request) (entity.Contact, error) {
+	var req companyPresenter.SaveContact
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return entity.Contact{}, errors.New("Invalid request body")
+	}
+
+	contact := entity.Contact{
+		CompanyID:   strings.TrimSpace(req.CompanyID),
+		Name:        strings.TrimSpace(req.Name),
+		Designation: strings.TrimSpace(req.Designation),
+		Email:       strings.ToLower(strings.TrimSpace(req.Email)),
+		Phone:       strings.TrimSpace(req.Phone),
+		LinkedIn:    strings.TrimSpace(req.LinkedIn),
+		IsPrimary:   req.IsPrimary,
+		Notes:       strings.TrimSpace(req.Notes),
+	}
+
+	var errs []string
+	if !uuidRegex.MatchString(contact.CompanyID) {
+		errs = append(errs, "companyId must be a company UUID")
+	}
+	if contact.Name == "" {
+		errs = append(errs, "name is required")
+	}
+	if contact.Email != "" {
+		if addr, err := mail.ParseAddress(contact.Email); err != nil || addr.Address != contact.Email {
+			errs = append(errs, "email is not a valid address")
+		}
+	}
+	if contact.LinkedIn != "" && !strings.Contains(strings.ToLower(contact.LinkedIn), "linkedin.com/") {
+		errs = append(errs, "linkedIn must be a linkedin.com URL")
+	}
+	if len(errs) > 0 {
+		return contact, errors.New(strings.Join(errs, "; "))
+	}
+	return contact, nil
+}
+
+// writeContactError maps contact usecase errors to responses.
+func writeContactError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, company.ErrNotFound):
+		w.WriteHeader(http.StatusNotFound)
+		err = errors.New("Contact not found")
+	case errors.Is(err, company.ErrUnknownCompany):
+		w.WriteHeader(http.StatusBadRequest)
+	default:
+		log.Printf("Error saving contact: %v", err)
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+	json.NewEncoder(w).Encode(map[string]string{
+		"error": err.Error(),
+	})
+}
+
+// ListContacts returns contacts, optionally filtered by company_id (repeatable)
+// and email. Filtering by email finds one person across companies.
+func ListContacts(service company.Usecase, w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	filter := company.ContactFilter{
+		CompanyIDs: r.URL.Query()["company_id"],
+		Email:      strings.TrimSpace(r.URL.Query().Get("email")),
+	}
+	for _, id := range filter.CompanyIDs {
+		if !uuidRegex.MatchString(id) {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{
+				"error": "company_id must be a company UUID",
+			})
+			return
+		}
+	}
+
+	contacts, err := service.ListContacts(filter)
+	if err != nil {
+		writeContactError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(contacts)
+}
+
+func GetContact(service company.Usecase, w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	contact, err := service.GetContact(mux.Vars(r)["id"])
+	if err != nil {
+		writeContactError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(contact)
+}
+
+func CreateContact(service company.Usecase, w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	contact, err := contactFromRequest(r)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	created, err := service.CreateContact(contact)
+	if err != nil {
+		writeContactError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(created)
+}
+
+func UpdateContact(service company.Usecase, w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	contact, err := contactFromRequest(r)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	updated, err := service.UpdateContact(mux.Vars(r)["id"], contact)
+	if err != nil {
+		writeContactError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(updated)
+}
+
+func DeleteContact(service company.Usecase, w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if err := service.DeleteContact(mux.Vars(r)["id"]); err != nil {
+		writeContactError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]string{
+		"message": "Contact deleted successfully",
+	})
+}
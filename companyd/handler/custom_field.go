@@ -0,0 +1,158 @@
+package companyHandler
+
+import (
+	"backend/companyd/entity"
+	companyPresenter "backend/companyd/presenter"
+	"backend/companyd/usecase/company"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+// writeCustomFieldError maps custom field usecase errors to responses.
+func writeCustomFieldError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, company.ErrInvalidCustomField):
+		w.WriteHeader(http.StatusBadRequest)
+	case errors.Is(err, company.ErrFieldExists):
+		w.WriteHeader(http.StatusConflict)
+	case errors.Is(err, company.ErrNotFound):
+		w.WriteHeader(http.StatusNotFound)
+		err = errors.New("Custom field not found")
+	default:
+		log.Printf("Error handling custom fields: %v", err)
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+	json.NewEncoder(w).Encode(map[string]string{
+		"error": err.Error(),
+	})
+}
+
+// requireAdmin lets admins through and answers everyone else with 403 and
+// message.
+func requireAdmin(w http.ResponseWriter, r *http.Request, message string) bool {
+	who, ok := requireCaller(w, r, false)
+	if !ok {
+		return false
+	}
+	if !who.isAdmin() {
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(map[string]string{
+			"error": message,
+		})
+		return false
+	}
+	return true
+}
+
+// decodeCustomField reads a custom field definition from the request body.
+func decodeCustomField(w http.ResponseWriter, r *http.Request) (entity.CustomField, bool) {
+	var req companyPresenter.CustomField
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{
+			"error": "Invalid request body",
+		})
+		return entity.CustomField{}, false
+	}
+	return entity.CustomField{Key: req.Key, Label: req.Label, Type: req.Type, Options: req.Options, Required: req.Required}, true
+}
+
+// ListCustomFields returns the custom field definitions, oldest first.
+func ListCustomFields(service company.Usecase, w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	fields, err := service.ListCustomFields()
+	if err != nil {
+		writeCustomFieldError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(fields)
+}
+
+// CreateCustomField defines a custom field. Only admins may define fields.
+func CreateCustomField(service company.Usecase, w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if !requireAdmin(w, r, "Only admins can define custom fields") {
+		return
+	}
+	field, ok := decodeCustomField(w, r)
+	if !ok {
+		return
+	}
+
+	created, err := service.CreateCustomField(field)
+	if err != nil {
+		writeCustomFieldError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(created)
+}
+
+// UpdateCustomField changes a custom field's label, options and whether it
+// is required. Only admins may change fields.
+func UpdateCustomField(service company.Usecase, w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if !requireAdmin(w, r, "Only admins can change custom fields") {
+		return
+	}
+	field, ok := decodeCustomField(w, r)
+	if !ok {
+		return
+	}
+
+	updated, err := service.UpdateCustomField(mux.Vars(r)["id"], field)
+	if err != nil {
+		writeCustomFieldError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(updated)
+}
+
+// DeleteCustomField removes a custom field and every company's value for
+// it. Only admins may remove fields.
+func DeleteCustomField(service company.Usecase, w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if !requireAdmin(w, r, "Only admins can remove custom fields") {
+		return
+	}
+	if err := service.DeleteCustomField(mux.Vars(r)["id"]); err != nil {
+		writeCustomFieldError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]string{
+		"message": "Custom field deleted successfully",
+	})
+}
+
+// ListTags returns the tags in use with how many companies carry each.
+func ListTags(service company.Usecase, w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	tags, err := service.ListTags()
+	if err != nil {
+		log.Printf("Error listing tags: %v", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(tags)
+}
@@ -0,0 +1,213 @@
+package companyHandler
+
+import (
+	"backend/companyd/entity"
+	companyPresenter "backend/companyd/presenter"
+	"backend/companyd/usecase/company"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+// driveFromRequest validates a create or update body and normalises it into
+// a drive. Only creating reads the company.
+func driveFromRequest(r *http.Request, create bool) (entity.Drive, error) {
+	var req companyPresenter.SaveDrive
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return entity.Drive{}, errors.New("Invalid request body")
+	}
+
+	drive := entity.Drive{
+		Package: strings.TrimSpace(req.Package),
+		Status:  strings.ToLower(strings.TrimSpace(req.Status)),
+		Notes:   strings.TrimSpace(req.Notes),
+		Roles:   []string{},
+	}
+	for _, role := range req.Roles {
+		if role = strings.TrimSpace(role); role != "" {
+			drive.Roles = append(drive.Roles, role)
+		}
+	}
+
+	var errs []string
+	if create {
+		drive.CompanyID = strings.TrimSpace(req.CompanyID)
+		if !uuidRegex.MatchString(drive.CompanyID) {
+			errs = append(errs, "companyId must be a company UUID")
+		}
+	}
+	if t, ok := company.NormalizeDriveType(req.Type); ok {
+		drive.Type = t
+	} else {
+		errs = append(errs, "type must be one of "+strings.Join(company.DriveTypes, ", "))
+	}
+	if season := strings.TrimSpace(req.Season); season != "" {
+		if drive.Season, _ = company.ParseSeason(season); drive.Season == "" {
+			errs = append(errs, "season must be an academic year such as 2026-27")
+		}
+	}
+	if req.StartsOn != "" {
+		startsOn, err := parseDateParam(req.StartsOn)
+		if err != nil {
+			errs = append(errs, "startsOn must be a date such as 2026-07-14")
+		} else {
+			startsOn = startsOn.UTC()
+			drive.StartsOn = &startsOn
+		}
+	}
+	if req.EndsOn != "" {
+		endsOn, err := parseDateParam(req.EndsOn)
+		if err != nil {
+			errs = append(errs, "endsOn must be a date such as 2026-07-16")
+		} else {
+			endsOn = endsOn.UTC()
+			drive.EndsOn = &endsOn
+		}
+	}
+	if drive.StartsOn != nil && drive.EndsOn != nil && drive.EndsOn.Before(*drive.StartsOn) {
+		errs = append(errs, "endsOn must not be before startsOn")
+	}
+	if drive.Status != "" && !company.IsDriveStatus(drive.Status) {
+		errs = append(errs, "status must be one of "+strings.Join(company.DriveStatuses, ", "))
+	}
+	if len(errs) > 0 {
+		return drive, errors.New(strings.Join(errs, "; "))
+	}
+	return drive, nil
+}
+
+// writeDriveError maps drive usecase errors to responses.
+func writeDriveError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, company.ErrNotFound):
+		w.WriteHeader(http.StatusNotFound)
+		err = errors.New("Drive not found")
+	case errors.Is(err, company.ErrUnknownCompany):
+		w.WriteHeader(http.StatusBadRequest)
+	default:
+		log.Printf("Error saving drive: %v", err)
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+	json.NewEncoder(w).Encode(map[string]string{
+		"error": err.Error(),
+	})
+}
+
+// ListDrives returns drives soonest first, optionally filtered by
+// company_id, season, type and status.
+func ListDrives(service company.Usecase, w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	query := r.URL.Query()
+	filter := company.DriveFilter{
+		CompanyID: strings.TrimSpace(query.Get("company_id")),
+		Type:      strings.ToLower(strings.TrimSpace(query.Get("type"))),
+		Status:    strings.ToLower(strings.TrimSpace(query.Get("status"))),
+	}
+	var errs []string
+	if filter.CompanyID != "" && !uuidRegex.MatchString(filter.CompanyID) {
+		errs = append(errs, "company_id must be a company UUID")
+	}
+	if season := strings.TrimSpace(query.Get("season")); season != "" {
+		if filter.Season, _ = company.ParseSeason(season); filter.Season == "" {
+			errs = append(errs, "season must be an academic year such as 2026-27")
+		}
+	}
+	if filter.Type != "" && !company.IsDriveType(filter.Type) {
+		errs = append(errs, "type must be one of "+strings.Join(company.DriveTypes, ", "))
+	}
+	if filter.Status != "" && !company.IsDriveStatus(filter.Status) {
+		errs = append(errs, "status must be one of "+strings.Join(company.DriveStatuses, ", "))
+	}
+	if len(errs) > 0 {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{
+			"error": strings.Join(errs, "; "),
+		})
+		return
+	}
+
+	drives, err := service.ListDrives(filter)
+	if err != nil {
+		writeDriveError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(drives)
+}
+
+func GetDrive(service company.Usecase, w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	drive, err := service.GetDrive(mux.Vars(r)["id"])
+	if err != nil {
+		writeDriveError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(drive)
+}
+
+func CreateDrive(service company.Usecase, w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	drive, err := driveFromRequest(r, true)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	created, err := service.CreateDrive(drive)
+	if err != nil {
+		writeDriveError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(created)
+}
+
+func UpdateDrive(service company.Usecase, w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	drive, err := driveFromRequest(r, false)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	updated, err := service.UpdateDrive(mux.Vars(r)["id"], drive)
+	if err != nil {
+		writeDriveError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(updated)
+}
+
+func DeleteDrive(service company.Usecase, w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if err := service.DeleteDrive(mux.Vars(r)["id"]); err != nil {
+		writeDriveError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]string{
+		"message": "Drive deleted successfully",
+	})
+}
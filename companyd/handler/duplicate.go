@@ -0,0 +1,123 @@
+package companyHandler
+
+import (
+	companyPresenter "backend/companyd/presenter"
+	"backend/companyd/usecase/company"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+// DuplicateReport lists groups of companies that are probably the same
+// recruiter, oldest company first in each group.
+func DuplicateReport(service company.Usecase, w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	groups, err := service.DuplicateReport()
+	if err != nil {
+		log.Printf("Error building duplicate report: %v", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(groups)
+}
+
+// CheckDuplicates lists the companies a name would probably duplicate, so
+// that a form can warn before anything is created.
+func CheckDuplicates(service company.Usecase, w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	name := strings.TrimSpace(r.URL.Query().Get("name"))
+	if name == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{
+			"error": "name is required",
+		})
+		return
+	}
+
+	matches, err := service.FindDuplicates(name, "")
+	if err != nil {
+		log.Printf("Error checking duplicates: %v", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(matches)
+}
+
+// MergeCompanies folds the duplicate named in the body into the company in
+// the path, which survives. Only admins may merge, and the request must carry
+// the survivor's current ETag.
+func MergeCompanies(service company.Usecase, w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	id := mux.Vars(r)["id"]
+
+	who, ok := requireCaller(w, r, true)
+	if !ok {
+		return
+	}
+	if !who.isAdmin() {
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(map[string]string{
+			"error": "Only admins can merge companies",
+		})
+		return
+	}
+	version, ok := requireIfMatch(w, r)
+	if !ok {
+		return
+	}
+
+	var req companyPresenter.MergeCompany
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.DuplicateID == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{
+			"error": "duplicateId must name the company to merge in",
+		})
+		return
+	}
+
+	merged, err := service.MergeCompanies(id, version, req.DuplicateID, who.Username)
+	switch {
+	case errors.Is(err, company.ErrVersionMismatch):
+		writeVersionConflict(service, w, id)
+		return
+	case errors.Is(err, company.ErrNotFound):
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{
+			"error": "Company not found",
+		})
+		return
+	case errors.Is(err, company.ErrUnknownCompany), errors.Is(err, company.ErrSelfMerge), errors.Is(err, company.ErrUnknownOfficer):
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{
+			"error": err.Error(),
+		})
+		return
+	case err != nil:
+		log.Printf("Error merging company %s into %s: %v", req.DuplicateID, id, err)
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	w.Header().Set("ETag", etag(merged.Version))
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(merged)
+}
@@ -0,0 +1,387 @@
+package companyHandler
+
+import (
+	"backend/companyd/entity"
+	"backend/companyd/usecase/company"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// exportPageSize is how many companies an export reads at a time, so that
+// large exports are streamed rather than held in memory.
+const exportPageSize = maxPageSize
+
+// exportColumn is a company field that can appear in an export.
+type exportColumn struct {
+	Field   string
+	Heading string
+	// Width is the column's share of a PDF page, relative to the others.
+	Width float64
+	// Contact marks HR contact details, which officers cannot export.
+	Contact bool
+	Value   func(c *entity.Company) string
+}
+
+func exportTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.UTC().Format("2006-01-02 15:04")
+}
+
+func exportBool(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
+
+// exportColumns lists every column in its default order.
+var exportColumns = []exportColumn{
+	{"id", "ID", 2.2, false, func(c *entity.Company) string { return c.ID }},
+	{"companyName", "Company", 1.6, false, func(c *entity.Company) string { return c.CompanyName }},
+	{"companyAddress", "Address", 1.4, false, func(c *entity.Company) string { return c.CompanyAddress }},
+	{"drive", "Drive", 0.7, false, func(c *entity.Company) string { return c.Drive }},
+	{"typeOfDrive", "Type of drive", 0.9, false, func(c *entity.Company) string { return c.TypeOfDrive }},
+	{"pipelineStatus", "Stage", 0.9, false, func(c *entity.Company) string { return c.PipelineStatus }},
+	{"isContacted", "Contacted", 0.6, false, func(c *entity.Company) string { return exportBool(c.IsContacted) }},
+	{"package", "Package", 0.9, false, func(c *entity.Company) string { return c.Package }},
+	{"assignedOfficer", "Officers", 1, false, func(c *entity.Company) string { return strings.Join(c.AssignedOfficer, ", ") }},
+	{"tags", "Tags", 1, false, func(c *entity.Company) string { return strings.Join(c.Tags, ", ") }},
+	{"followUp", "Follow-up", 1.4, false, func(c *entity.Company) string { return c.FollowUp }},
+	{"lastInteractionAt", "Last interaction", 0.9, false, func(c *entity.Company) string { return exportTime(c.LastInteractionAt) }},
+	{"lastInteractionOutcome", "Outcome", 1, false, func(c *entity.Company) string { return c.LastInteractionOutcome }},
+	{"remarks", "Remarks", 1.8, false, func(c *entity.Company) string { return c.Remarks }},
+	{"contactDetails", "Contact details", 1.4, true, func(c *entity.Company) string { return c.ContactDetails }},
+	{"hr1Details", "HR 1", 1.4, true, func(c *entity.Company) string { return c.HR1Details }},
+	{"hr2Details", "HR 2", 1.4, true, func(c *entity.Company) string { return c.HR2Details }},
+	{"createdAt", "Created", 1, false, func(c *entity.Company) string { return c.CreatedAt }},
+	{"updatedAt", "Updated", 1, false, func(c *entity.Company) string { return c.UpdatedAt }},
+	{"archivedAt", "Archived", 0.9, false, func(c *entity.Company) string { return exportTime(c.ArchivedAt) }},
+}
+
+// defaultExportColumns leaves out the bookkeeping columns. Every custom
+// field follows them.
+var defaultExportColumns = []string{
+	"companyName", "companyAddress", "drive", "typeOfDrive", "pipelineStatus", "package", "assignedOfficer", "tags",
+	"followUp", "lastInteractionAt", "lastInteractionOutcome", "remarks", "contactDetails", "hr1Details", "hr2Details",
+}
+
+// customFieldColumn exports a custom field as field.<key>, headed by its
+// label.
+func customFieldColumn(field *entity.CustomField) exportColumn {
+	key := field.Key
+	return exportColumn{fieldParamPrefix + key, field.Label, 1, false, func(c *entity.Company) string {
+		return company.FormatCustomValue(c.CustomFields[key])
+	}}
+}
+
+func findExportColumn(columns []exportColumn, field string) (exportColumn, bool) {
+	for _, c := range columns {
+		if c.Field == field {
+			return c, true
+		}
+	}
+	return exportColumn{}, false
+}
+
+// selectExportColumns reads the columns and omit_contacts parameters, with
+// a column for each of customFields. HR contact details are left out for
+// officers, and for anyone who asks; only admins and managers may export
+// them.
+func selectExportColumns(values url.Values, who caller, customFields []*entity.CustomField) ([]exportColumn, int, error) {
+	omit := !who.seesAllCompanies()
+	if v := values.Get("omit_contacts"); v != "" {
+		omitContacts, err := strconv.ParseBool(v)
+		if err != nil {
+			return nil, http.StatusBadRequest, fmt.Errorf("omit_contacts must be true or false")
+		}
+		if !omitContacts && omit {
+			return nil, http.StatusForbidden, fmt.Errorf("only admins and managers can export HR contact details")
+		}
+		omit = omitContacts
+	}
+
+	available := exportColumns
+	fields := defaultExportColumns
+	if len(customFields) > 0 {
+		available = append([]exportColumn{}, exportColumns...)
+		fields = append([]string{}, defaultExportColumns...)
+		for _, field := range customFields {
+			column := customFieldColumn(field)
+			available = append(available, column)
+			fields = append(fields, column.Field)
+		}
+	}
+	explicit := values.Get("columns") != ""
+	if explicit {
+		fields = strings.Split(values.Get("columns"), ",")
+	}
+	var columns []exportColumn
+	seen := map[string]bool{}
+	var errs []string
+	for _, field := range fields {
+		field = strings.TrimSpace(field)
+		column, ok := findExportColumn(available, field)
+		switch {
+		case !ok:
+			errs = append(errs, fmt.Sprintf("cannot export column %q", field))
+		case seen[field]:
+			errs = append(errs, fmt.Sprintf("column %q is listed twice", field))
+		case column.Contact && omit && explicit:
+			if !who.seesAllCompanies() {
+				return nil, http.StatusForbidden, fmt.Errorf("only admins and managers can export HR contact details")
+			}
+			errs = append(errs, fmt.Sprintf("column %q is an HR contact detail, which omit_contacts leaves out", field))
+		case column.Contact && omit:
+		default:
+			columns = append(columns, column)
+		}
+		seen[field] = true
+	}
+	if len(errs) > 0 {
+		return nil, http.StatusBadRequest, fmt.Errorf("%s", strings.Join(errs, "; "))
+	}
+	return columns, http.StatusOK, nil
+}
+
+// exporter writes the rows of one export in a file format.
+type exporter interface {
+	WriteRow(cells []string) error
+	// Flush sends the rows written so far to the client.
+	Flush() error
+	Close() error
+}
+
+// csvExporter writes UTF-8 CSV with a byte order mark, so that Excel reads
+// it correctly.
+type csvExporter struct {
+	w *csv.Writer
+}
+
+func newCSVExporter(w io.Writer, headings []string) (*csvExporter, error) {
+	if _, err := io.WriteString(w, "\xef\xbb\xbf"); err != nil {
+		return nil, err
+	}
+	e := &csvExporter{w: csv.NewWriter(w)}
+	return e, e.w.Write(headings)
+}
+
+func (e *csvExporter) WriteRow(cells []string) error {
+	safe := make([]string, len(cells))
+	for i, cell := range cells {
+		// Spreadsheets run cells that start like a formula.
+		if cell != "" && strings.ContainsRune("=+-@\t\r", rune(cell[0])) {
+			cell = "'" + cell
+		}
+		safe[i] = cell
+	}
+	return e.w.Write(safe)
+}
+
+func (e *csvExporter) Flush() error {
+	e.w.Flush()
+	return e.w.Error()
+}
+
+func (e *csvExporter) Close() error {
+	return e.Flush()
+}
+
+// exportFormats maps the format parameter to a content type and file
+// extension.
+var exportFormats = map[string]struct {
+	contentType string
+	extension   string
+}{
+	"csv":  {"text/csv; charset=utf-8", "csv"},
+	"xlsx": {"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", "xlsx"},
+	"pdf":  {"application/pdf", "pdf"},
+}
+
+var unsafeFilename = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// exportFilters describes the filters of an export for the PDF subtitle.
+func exportFilters(values url.Values, officer, season string) string {
+	var filters []string
+	if officer != "" {
+		filters = append(filters, "officer "+officer)
+	}
+	if season != "" && values.Get("season") == "" {
+		filters = append(filters, "season "+season)
+	}
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		switch key {
+		case "format", "columns", "omit_contacts", "limit", "cursor", "officer":
+			continue
+		}
+		if v := values.Get(key); v != "" {
+			filters = append(filters, key+"="+v)
+		}
+	}
+	if len(filters) == 0 {
+		return "all companies"
+	}
+	return strings.Join(filters, ", ")
+}
+
+// ExportCompanies writes the companies matching the /company/list filters as
+// CSV, XLSX or PDF, chosen by format. /company/export/{username} exports the
+// companies assigned to one officer. Like the list, exports cover the active
+// season unless season says otherwise. Officers may only export their own
+// companies. Companies are read and written a page at a time; limit and
+// cursor are ignored.
+func ExportCompanies(service company.Usecase, w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	fail := func(status int, message string) {
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(map[string]string{
+			"error": message,
+		})
+	}
+
+	who, ok := requireCaller(w, r, false)
+	if !ok {
+		return
+	}
+	values := r.URL.Query()
+	query, err := parseListQuery(values)
+	if err != nil {
+		fail(http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := inActiveSeason(service, values, &query); err != nil {
+		fail(http.StatusInternalServerError, err.Error())
+		return
+	}
+	if username := mux.Vars(r)["username"]; username != "" {
+		query.Officer = username
+	}
+	if officer := who.visibleOfficer(); officer != "" {
+		if query.Officer != "" && query.Officer != officer {
+			fail(http.StatusForbidden, "Officers can only export their own companies")
+			return
+		}
+		query.Officer = officer
+	}
+	query.Limit, query.After = exportPageSize, nil
+
+	formatName := values.Get("format")
+	if formatName == "" {
+		formatName = "csv"
+	}
+	format, ok := exportFormats[formatName]
+	if !ok {
+		fail(http.StatusBadRequest, "format must be csv, xlsx or pdf")
+		return
+	}
+	customFields, err := service.ListCustomFields()
+	if err != nil {
+		log.Printf("Error exporting companies: %v", err)
+		fail(http.StatusInternalServerError, err.Error())
+		return
+	}
+	columns, status, err := selectExportColumns(values, who, customFields)
+	if err != nil {
+		fail(status, err.Error())
+		return
+	}
+
+	// Fetch the first page before answering, so that a failing query still
+	// gets an error status.
+	page, err := service.QueryCompanies(query)
+	if errors.Is(err, company.ErrInvalidCustomValue) {
+		fail(http.StatusBadRequest, err.Error())
+		return
+	}
+	if err != nil {
+		log.Printf("Error exporting companies: %v", err)
+		fail(http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	now := time.Now().UTC()
+	name := "companies"
+	if query.Officer != "" {
+		name += "-" + unsafeFilename.ReplaceAllString(query.Officer, "_")
+	}
+	filename := fmt.Sprintf("%s-%s.%s", name, now.Format("2006-01-02"), format.extension)
+	w.Header().Set("Content-Type", format.contentType)
+	w.Header().Set("Content-Disposition", `attachment; filename="`+filename+`"`)
+	w.Header().Set("X-Total-Count", strconv.Itoa(page.Total))
+	w.WriteHeader(http.StatusOK)
+
+	headings := make([]string, len(columns))
+	for i, c := range columns {
+		headings[i] = c.Heading
+	}
+	var out exporter
+	switch formatName {
+	case "xlsx":
+		out, err = newXLSXExporter(w, "Companies", headings)
+	case "pdf":
+		widths := make([]float64, len(columns))
+		for i, c := range columns {
+			widths[i] = c.Width
+		}
+		out, err = newPDFExporter(w, pdfReport{
+			Title:    "Company report",
+			Subtitle: fmt.Sprintf("%d companies · %s · generated %s UTC", page.Total, exportFilters(values, query.Officer, query.Season), now.Format("2006-01-02 15:04")),
+			Headings: headings,
+			Widths:   widths,
+		})
+	default:
+		out, err = newCSVExporter(w, headings)
+	}
+
+	for err == nil {
+		for _, c := range page.Companies {
+			cells := make([]string, len(columns))
+			for i, column := range columns {
+				cells[i] = column.Value(c)
+			}
+			if err = out.WriteRow(cells); err != nil {
+				break
+			}
+		}
+		if err == nil {
+			err = out.Flush()
+		}
+		if f, ok := w.(http.Flusher); ok {
+			f.Flush()
+		}
+		if err != nil || page.NextCursor == "" {
+			break
+		}
+		if query.After, err = company.DecodeCursor(page.NextCursor); err == nil {
+			page, err = service.QueryCompanies(query)
+		}
+	}
+	if err == nil {
+		err = out.Close()
+	}
+	if err != nil {
+		// The status has been sent; drop the connection so that the client
+		// does not mistake a partial file for a whole one.
+		log.Printf("Error exporting companies: %v", err)
+		panic(http.ErrAbortHandler)
+	}
+}
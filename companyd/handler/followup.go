@@ -0,0 +1,351 @@
+package companyHandler
+
+import (
+	"backend/companyd/entity"
+	companyPresenter "backend/companyd/presenter"
+	"backend/companyd/usecase/company"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// Bounds of the within parameter of /followups/due.
+const (
+	defaultDueWindow = 24 * time.Hour
+	maxDueWindow     = 90 * 24 * time.Hour
+)
+
+// followUpFromRequest validates a create or update body and normalises it
+// into a follow-up. Updates keep the follow-up's company, so companyId is
+// only checked on create.
+func followUpFromRequest(r *http.Request, create bool) (entity.FollowUp, error) {
+	var req companyPresenter.SaveFollowUp
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return entity.FollowUp{}, errors.New("Invalid request body")
+	}
+
+	followUp := entity.FollowUp{
+		CompanyID: strings.TrimSpace(req.CompanyID),
+		Officer:   strings.TrimSpace(req.Officer),
+		Note:      strings.TrimSpace(req.Note),
+		Status:    strings.TrimSpace(req.Status),
+	}
+
+	var errs []string
+	if create && !uuidRegex.MatchString(followUp.CompanyID) {
+		errs = append(errs, "companyId must be a company UUID")
+	}
+	if due, err := parseDateParam(strings.TrimSpace(req.DueAt)); err != nil {
+		errs = append(errs, "dueAt must be an RFC 3339 timestamp or a YYYY-MM-DD date")
+	} else {
+		followUp.DueAt = due.UTC()
+	}
+	if followUp.Status != "" && !company.IsFollowUpStatus(followUp.Status) {
+		errs = append(errs, "status must be "+company.FollowUpOpen+", "+company.FollowUpDone+" or "+company.FollowUpCancelled)
+	}
+	if len(errs) > 0 {
+		return followUp, errors.New(strings.Join(errs, "; "))
+	}
+	return followUp, nil
+}
+
+// writeFollowUpError maps follow-up usecase errors to responses.
+func writeFollowUpError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, company.ErrNotFound):
+		w.WriteHeader(http.StatusNotFound)
+		err = errors.New("Follow-up not found")
+	case errors.Is(err, company.ErrUnknownCompany), errors.Is(err, company.ErrNoOfficer):
+		w.WriteHeader(http.StatusBadRequest)
+	default:
+		log.Printf("Error saving follow-up: %v", err)
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+	json.NewEncoder(w).Encode(map[string]string{
+		"error": err.Error(),
+	})
+}
+
+// requireCaller identifies the user a request is made for, writing a 401 when
+// it cannot. With named set, admins and managers must send X-Username too.
+func requireCaller(w http.ResponseWriter, r *http.Request, named bool) (caller, bool) {
+	who, err := callerFromRequest(r)
+	if err == nil && named && who.Username == "" {
+		err = errors.New(usernameHeader + " header is required")
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]string{
+			"error": err.Error(),
+		})
+		return who, false
+	}
+	return who, true
+}
+
+// ListFollowUps returns follow-ups ordered by due time, optionally filtered by
+// company_id, officer and status.
+func ListFollowUps(service company.Usecase, w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	values := r.URL.Query()
+	filter := company.FollowUpFilter{
+		CompanyID: values.Get("company_id"),
+		Officer:   values.Get("officer"),
+		Status:    values.Get("status"),
+	}
+	var errs []string
+	if filter.CompanyID != "" && !uuidRegex.MatchString(filter.CompanyID) {
+		errs = append(errs, "company_id must be a company UUID")
+	}
+	if filter.Status != "" && !company.IsFollowUpStatus(filter.Status) {
+		errs = append(errs, "status must be "+company.FollowUpOpen+", "+company.FollowUpDone+" or "+company.FollowUpCancelled)
+	}
+	if len(errs) > 0 {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{
+			"error": strings.Join(errs, "; "),
+		})
+		return
+	}
+
+	followUps, err := service.ListFollowUps(filter)
+	if err != nil {
+		writeFollowUpError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(followUps)
+}
+
+// DueFollowUps lists the caller's open follow-ups falling due within the
+// next day, or the Go duration given as within (e.g. 72h). Admins and
+// managers see every officer's unless they pass officer.
+func DueFollowUps(service company.Usecase, w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	who, ok := requireCaller(w, r, false)
+	if !ok {
+		return
+	}
+	within := defaultDueWindow
+	if v := r.URL.Query().Get("within"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 || d > maxDueWindow {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{
+				"error": "within must be a duration such as 48h, at most " + strconv.Itoa(int(maxDueWindow.Hours())) + "h",
+			})
+			return
+		}
+		within = d
+	}
+
+	followUps, err := service.DueFollowUps(followUpOfficer(who, r), within)
+	if err != nil {
+		writeFollowUpError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(followUps)
+}
+
+// OverdueFollowUps lists the caller's open follow-ups whose due time has
+// passed, oldest first.
+func OverdueFollowUps(service company.Usecase, w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	who, ok := requireCaller(w, r, false)
+	if !ok {
+		return
+	}
+
+	followUps, err := service.OverdueFollowUps(followUpOfficer(who, r))
+	if err != nil {
+		writeFollowUpError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(followUps)
+}
+
+// followUpOfficer is the officer whose follow-ups a view shows: officers
+// always see their own, others whoever the officer parameter names.
+func followUpOfficer(who caller, r *http.Request) string {
+	if officer := who.visibleOfficer(); officer != "" {
+		return officer
+	}
+	return r.URL.Query().Get("officer")
+}
+
+func GetFollowUp(service company.Usecase, w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	followUp, err := service.GetFollowUp(mux.Vars(r)["id"])
+	if err != nil {
+		writeFollowUpError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(followUp)
+}
+
+func CreateFollowUp(service company.Usecase, w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	followUp, err := followUpFromRequest(r, true)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	created, err := service.CreateFollowUp(followUp)
+	if err != nil {
+		writeFollowUpError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(created)
+}
+
+// UpdateFollowUp reschedules, reassigns or changes the status of a
+// follow-up. Setting the status to done records the caller as completedBy.
+func UpdateFollowUp(service company.Usecase, w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	who, ok := requireCaller(w, r, true)
+	if !ok {
+		return
+	}
+	followUp, err := followUpFromRequest(r, false)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	updated, err := service.UpdateFollowUp(mux.Vars(r)["id"], followUp, who.Username)
+	if err != nil {
+		writeFollowUpError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(updated)
+}
+
+// CompleteFollowUp marks a follow-up done by the caller.
+func CompleteFollowUp(service company.Usecase, w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	who, ok := requireCaller(w, r, true)
+	if !ok {
+		return
+	}
+
+	completed, err := service.CompleteFollowUp(mux.Vars(r)["id"], who.Username)
+	if err != nil {
+		writeFollowUpError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(completed)
+}
+
+func DeleteFollowUp(service company.Usecase, w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if err := service.DeleteFollowUp(mux.Vars(r)["id"]); err != nil {
+		writeFollowUpError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]string{
+		"message": "Follow-up deleted successfully",
+	})
+}
+
+// ListNotifications returns the caller's notifications, newest first; with
+// unread=true only those not yet marked read.
+func ListNotifications(service company.Usecase, w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	who, ok := requireCaller(w, r, true)
+	if !ok {
+		return
+	}
+	unreadOnly := false
+	if v := r.URL.Query().Get("unread"); v != "" {
+		var err error
+		if unreadOnly, err = strconv.ParseBool(v); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{
+				"error": "unread must be true or false",
+			})
+			return
+		}
+	}
+
+	notifications, err := service.ListNotifications(who.Username, unreadOnly)
+	if err != nil {
+		log.Printf("Error listing notifications: %v", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(notifications)
+}
+
+// MarkNotificationRead marks one of the caller's notifications read.
+func MarkNotificationRead(service company.Usecase, w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	who, ok := requireCaller(w, r, true)
+	if !ok {
+		return
+	}
+
+	err := service.MarkNotificationRead(mux.Vars(r)["id"], who.Username)
+	if errors.Is(err, company.ErrNotFound) {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{
+			"error": "Notification not found",
+		})
+		return
+	}
+	if err != nil {
+		log.Printf("Error marking notification read: %v", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]string{
+		"message": "Notification marked as read",
+	})
+}
@@ -0,0 +1,131 @@
+package companyHandler
+
+import (
+	companyPresenter "backend/companyd/presenter"
+	"backend/companyd/usecase/company"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+// writeHistoryError maps company history usecase errors to responses.
+func writeHistoryError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, company.ErrNotFound):
+		w.WriteHeader(http.StatusNotFound)
+		err = errors.New("Company not found")
+	case errors.Is(err, company.ErrUnknownVersion):
+		w.WriteHeader(http.StatusNotFound)
+	case errors.Is(err, company.ErrUnknownOfficer):
+		// The version names an officer who has since been deleted.
+		w.WriteHeader(http.StatusConflict)
+	default:
+		log.Printf("Error reading company history: %v", err)
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+	json.NewEncoder(w).Encode(map[string]string{
+		"error": err.Error(),
+	})
+}
+
+// CompanyHistory lists every version of a company, newest first, with who
+// made it and the fields it changed.
+func CompanyHistory(service company.Usecase, w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	revisions, err := service.CompanyHistory(mux.Vars(r)["id"])
+	if err != nil {
+		writeHistoryError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(revisions)
+}
+
+// DiffCompany compares two versions of a company given as from and to.
+func DiffCompany(service company.Usecase, w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	values := r.URL.Query()
+	var errs []string
+	from, err := strconv.Atoi(values.Get("from"))
+	if err != nil || from < 1 {
+		errs = append(errs, "from must be a version number")
+	}
+	to, err := strconv.Atoi(values.Get("to"))
+	if err != nil || to < 1 {
+		errs = append(errs, "to must be a version number")
+	}
+	if len(errs) > 0 {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{
+			"error": strings.Join(errs, "; "),
+		})
+		return
+	}
+
+	changes, err := service.DiffCompany(mux.Vars(r)["id"], from, to)
+	if err != nil {
+		writeHistoryError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"from":    from,
+		"to":      to,
+		"changes": changes,
+	})
+}
+
+// RevertCompany restores a company to an earlier version. Only admins may
+// revert, and like any edit the request must carry the current ETag.
+func RevertCompany(service company.Usecase, w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	id := mux.Vars(r)["id"]
+
+	who, ok := requireCaller(w, r, true)
+	if !ok {
+		return
+	}
+	if !who.isAdmin() {
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(map[string]string{
+			"error": "Only admins can revert a company",
+		})
+		return
+	}
+	version, ok := requireIfMatch(w, r)
+	if !ok {
+		return
+	}
+
+	var req companyPresenter.RevertCompany
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Version < 1 {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{
+			"error": "version must be the version number to revert to",
+		})
+		return
+	}
+
+	reverted, err := service.RevertCompany(id, version, req.Version, who.Username)
+	if errors.Is(err, company.ErrVersionMismatch) {
+		writeVersionConflict(service, w, id)
+		return
+	}
+	if err != nil {
+		writeHistoryError(w, err)
+		return
+	}
+
+	w.Header().Set("ETag", etag(reverted.Version))
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(reverted)
+}
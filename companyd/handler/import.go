@@ -0,0 +1,275 @@
+package companyHandler
+
+import (
+	"backend/companyd/entity"
+	companyPresenter "backend/companyd/presenter"
+	"backend/companyd/usecase/company"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// maxImportSize bounds the size of an uploaded spreadsheet.
+const maxImportSize = 10 << 20
+
+// importAliases maps normalised column headings to the field they are
+// mapped to when the request carries no mapping.
+var importAliases = map[string]string{
+	"company":          "companyName",
+	"name":             "companyName",
+	"address":          "companyAddress",
+	"location":         "companyAddress",
+	"drivetype":        "typeOfDrive",
+	"type":             "typeOfDrive",
+	"contacted":        "isContacted",
+	"notes":            "remarks",
+	"comments":         "remarks",
+	"contact":          "contactDetails",
+	"hr1":              "hr1Details",
+	"hr2":              "hr2Details",
+	"ctc":              "package",
+	"salary":           "package",
+	"compensation":     "package",
+	"officer":          "assignedOfficer",
+	"officers":         "assignedOfficer",
+	"assignedofficers": "assignedOfficer",
+}
+
+func normalizeHeading(heading string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(heading) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// autoMapping maps each heading that names a company field, or one of its
+// aliases, to that field. The first column wins when several name the same
+// field.
+func autoMapping(header []string) map[string]string {
+	fields := map[string]string{}
+	for _, f := range company.ImportFields {
+		fields[strings.ToLower(f)] = f
+	}
+	mapping := map[string]string{}
+	used := map[string]bool{}
+	for _, heading := range header {
+		key := normalizeHeading(heading)
+		field, ok := fields[key]
+		if !ok {
+			field, ok = importAliases[key]
+		}
+		if ok && !used[field] {
+			mapping[heading] = field
+			used[field] = true
+		}
+	}
+	return mapping
+}
+
+// checkMapping lists what is wrong with a caller's column mapping.
+func checkMapping(header []string, mapping map[string]string) []string {
+	headings := map[string]bool{}
+	for _, h := range header {
+		headings[h] = true
+	}
+	var problems []string
+	columns := make([]string, 0, len(mapping))
+	for column := range mapping {
+		columns = append(columns, column)
+	}
+	sort.Strings(columns)
+	mappedBy := map[string]string{}
+	for _, column := range columns {
+		field := mapping[column]
+		if !headings[column] {
+			problems = append(problems, fmt.Sprintf("column %q is not in the spreadsheet", column))
+		}
+		if !company.IsImportField(field) {
+			problems = append(problems, fmt.Sprintf("%q is not a company field", field))
+		} else if other, ok := mappedBy[field]; ok {
+			problems = append(problems, fmt.Sprintf("columns %q and %q are both mapped to %s", other, column, field))
+		} else {
+			mappedBy[field] = column
+		}
+	}
+	if _, ok := mappedBy["companyName"]; !ok {
+		problems = append(problems, "no column is mapped to companyName")
+	}
+	return problems
+}
+
+func isBlankRow(row []string) bool {
+	for _, cell := range row {
+		if strings.TrimSpace(cell) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// ImportCompanies previews or commits companies from an uploaded CSV or XLSX
+// file. The first row holds the column headings; the optional mapping form
+// field is a JSON object from heading to company field. With commit=true the
+// rows are created all-or-nothing, and with report=csv the response is a CSV
+// of the rows' errors rather than JSON.
+func ImportCompanies(service company.Usecase, w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	badRequest := func(message string) {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{
+			"error": message,
+		})
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxImportSize+1<<20)
+	if err := r.ParseMultipartForm(maxImportSize); err != nil {
+		badRequest("Upload the spreadsheet as multipart/form-data in the file field")
+		return
+	}
+	file, _, err := r.FormFile("file")
+	if err != nil {
+		badRequest("file is required")
+		return
+	}
+	defer file.Close()
+	data, err := io.ReadAll(io.LimitReader(file, maxImportSize+1))
+	if err != nil {
+		badRequest(err.Error())
+		return
+	}
+	if len(data) > maxImportSize {
+		badRequest(fmt.Sprintf("The spreadsheet must be at most %d MB", maxImportSize>>20))
+		return
+	}
+	rows, err := readSpreadsheet(data)
+	if err != nil {
+		badRequest(err.Error())
+		return
+	}
+
+	// The header is the first row with anything in it.
+	start := 0
+	for start < len(rows) && isBlankRow(rows[start]) {
+		start++
+	}
+	if start == len(rows) {
+		badRequest("The spreadsheet is empty")
+		return
+	}
+	header := make([]string, len(rows[start]))
+	for i, heading := range rows[start] {
+		header[i] = strings.TrimSpace(heading)
+	}
+
+	var mapping map[string]string
+	if raw := r.FormValue("mapping"); raw != "" {
+		if err := json.Unmarshal([]byte(raw), &mapping); err != nil {
+			badRequest("mapping must be a JSON object from column heading to company field")
+			return
+		}
+	} else {
+		mapping = autoMapping(header)
+	}
+	if problems := checkMapping(header, mapping); len(problems) > 0 {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"error":    "Invalid column mapping",
+			"problems": problems,
+			"header":   header,
+			"fields":   company.ImportFields,
+		})
+		return
+	}
+	ignored := []string{}
+	for _, heading := range header {
+		if _, ok := mapping[heading]; !ok && heading != "" {
+			ignored = append(ignored, heading)
+		}
+	}
+
+	var importRows []company.ImportRow
+	for i := start + 1; i < len(rows); i++ {
+		if isBlankRow(rows[i]) {
+			continue
+		}
+		values := map[string]string{}
+		for column, cell := range rows[i] {
+			if column < len(header) {
+				if field, ok := mapping[header[column]]; ok {
+					values[field] = cell
+				}
+			}
+		}
+		importRows = append(importRows, company.ImportRow{Line: i + 1, Values: values})
+	}
+	if len(importRows) == 0 {
+		badRequest("The spreadsheet has no data rows")
+		return
+	}
+	if len(importRows) > company.MaxImportRows {
+		badRequest(fmt.Sprintf("An import can hold at most %d rows", company.MaxImportRows))
+		return
+	}
+
+	commit, _ := strconv.ParseBool(r.FormValue("commit"))
+	skipDuplicates, _ := strconv.ParseBool(r.FormValue("skip_duplicates"))
+	report, err := service.ImportCompanies(importRows, company.ImportOptions{
+		Commit:         commit,
+		SkipDuplicates: skipDuplicates,
+		ImportedBy:     changedBy(r),
+	})
+	status := http.StatusOK
+	switch {
+	case errors.Is(err, company.ErrInvalidImport):
+		status = http.StatusUnprocessableEntity
+	case errors.Is(err, company.ErrUnknownOfficer):
+		// An officer was deleted after the rows were checked.
+		badRequest(err.Error())
+		return
+	case err != nil:
+		log.Printf("Error importing companies: %v", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	if r.FormValue("report") == "csv" {
+		writeImportErrors(w, report, status)
+		return
+	}
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(companyPresenter.ImportCompanies{
+		Mapping:        mapping,
+		IgnoredColumns: ignored,
+		ImportReport:   report,
+	})
+}
+
+// writeImportErrors writes one CSV line per error in the report, so that the
+// spreadsheet can be fixed and uploaded again.
+func writeImportErrors(w http.ResponseWriter, report *entity.ImportReport, status int) {
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", `attachment; filename="company-import-errors.csv"`)
+	w.WriteHeader(status)
+	out := csv.NewWriter(w)
+	out.Write([]string{"line", "field", "value", "error"})
+	for _, row := range report.Rows {
+		for _, e := range row.Errors {
+			out.Write([]string{strconv.Itoa(row.Line), e.Field, e.Value, e.Message})
+		}
+	}
+	out.Flush()
+}
@@ -0,0 +1,144 @@
+package companyHandler
+
+import (
+	"backend/companyd/entity"
+	companyPresenter "backend/companyd/presenter"
+	"backend/companyd/usecase/company"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+)
+
+var interactionTypesText = strings.Join(company.InteractionTypes, ", ")
+
+// interactionFromRequest validates a create body and normalises it into an
+// interaction. The officer is filled in from the caller.
+func interactionFromRequest(r *http.Request) (entity.Interaction, error) {
+	var req companyPresenter.SaveInteraction
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return entity.Interaction{}, errors.New("Invalid request body")
+	}
+
+	interaction := entity.Interaction{
+		CompanyID: strings.TrimSpace(req.CompanyID),
+		Type:      strings.ToLower(strings.TrimSpace(req.Type)),
+		Outcome:   strings.TrimSpace(req.Outcome),
+		Notes:     strings.TrimSpace(req.Notes),
+		NextStep:  strings.TrimSpace(req.NextStep),
+	}
+
+	var errs []string
+	if !uuidRegex.MatchString(interaction.CompanyID) {
+		errs = append(errs, "companyId must be a company UUID")
+	}
+	if !company.IsInteractionType(interaction.Type) {
+		errs = append(errs, "type must be one of "+interactionTypesText)
+	}
+	if v := strings.TrimSpace(req.OccurredAt); v != "" {
+		occurredAt, err := parseDateParam(v)
+		if err != nil {
+			errs = append(errs, "occurredAt must be an RFC 3339 timestamp or a YYYY-MM-DD date")
+		}
+		interaction.OccurredAt = occurredAt
+	}
+	if len(errs) > 0 {
+		return interaction, errors.New(strings.Join(errs, "; "))
+	}
+	return interaction, nil
+}
+
+// ListInteractions returns a timeline of interactions, newest first,
+// optionally filtered by company_id, officer, type and an occurred_after /
+// occurred_before window.
+func ListInteractions(service company.Usecase, w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	values := r.URL.Query()
+	filter := company.InteractionFilter{
+		CompanyID: values.Get("company_id"),
+		Officer:   values.Get("officer"),
+		Type:      strings.ToLower(values.Get("type")),
+	}
+	var errs []string
+	if filter.CompanyID != "" && !uuidRegex.MatchString(filter.CompanyID) {
+		errs = append(errs, "company_id must be a company UUID")
+	}
+	if filter.Type != "" && !company.IsInteractionType(filter.Type) {
+		errs = append(errs, "type must be one of "+interactionTypesText)
+	}
+	for _, p := range []struct {
+		name string
+		dst  **time.Time
+	}{{"occurred_after", &filter.Since}, {"occurred_before", &filter.Until}} {
+		if v := values.Get(p.name); v != "" {
+			t, err := parseDateParam(v)
+			if err != nil {
+				errs = append(errs, p.name+" must be an RFC 3339 timestamp or a YYYY-MM-DD date")
+			}
+			*p.dst = &t
+		}
+	}
+	if len(errs) > 0 {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{
+			"error": strings.Join(errs, "; "),
+		})
+		return
+	}
+
+	interactions, err := service.ListInteractions(filter)
+	if err != nil {
+		log.Printf("Error listing interactions: %v", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(interactions)
+}
+
+// CreateInteraction logs an interaction by the caller. Interactions cannot be
+// edited or deleted afterwards; log a correcting entry instead.
+func CreateInteraction(service company.Usecase, w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	who, ok := requireCaller(w, r, true)
+	if !ok {
+		return
+	}
+	interaction, err := interactionFromRequest(r)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{
+			"error": err.Error(),
+		})
+		return
+	}
+	interaction.Officer = who.Username
+
+	created, err := service.CreateInteraction(interaction)
+	switch {
+	case errors.Is(err, company.ErrUnknownCompany), errors.Is(err, company.ErrFutureInteraction):
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{
+			"error": err.Error(),
+		})
+		return
+	case err != nil:
+		log.Printf("Error saving interaction: %v", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(created)
+}
@@ -0,0 +1,169 @@
+package companyHandler
+
+import (
+	"backend/companyd/usecase/company"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxPageSize bounds the limit query parameter.
+const maxPageSize = 500
+
+// fieldParamPrefix starts the name of a custom field filter parameter.
+const fieldParamPrefix = "field."
+
+// parseListQuery reads the /company/list query parameters. Every problem is
+// reported at once so that a client can fix its request in one go. Callers
+// scope a query without a season parameter to the active season with
+// inActiveSeason.
+func parseListQuery(values url.Values) (company.ListQuery, error) {
+	var q company.ListQuery
+	var errs []string
+
+	q.Drive = values.Get("drive")
+	q.TypeOfDrive = values.Get("type_of_drive")
+	q.Officer = values.Get("officer")
+	q.PipelineStatus = values.Get("pipeline_status")
+
+	// An empty season is left for the caller to fill in with the active one.
+	if v := values.Get("season"); v != "" && v != company.AllSeasons {
+		season, ok := company.ParseSeason(v)
+		if !ok {
+			errs = append(errs, "season must be an academic year such as 2026-27, or all")
+		}
+		q.Season = season
+	}
+
+	q.Archived = values.Get("archived")
+	if !company.IsArchivedFilter(q.Archived) {
+		errs = append(errs, "archived must be include or only")
+	}
+
+	if v := values.Get("is_contacted"); v != "" {
+		contacted, err := strconv.ParseBool(v)
+		if err != nil {
+			errs = append(errs, "is_contacted must be true or false")
+		}
+		q.IsContacted = &contacted
+	}
+
+	if v := values.Get("package_needs_review"); v != "" {
+		needsReview, err := strconv.ParseBool(v)
+		if err != nil {
+			errs = append(errs, "package_needs_review must be true or false")
+		}
+		q.PackageNeedsReview = &needsReview
+	}
+
+	q.PackageCurrency = strings.ToUpper(strings.TrimSpace(values.Get("currency")))
+	for _, p := range []struct {
+		name string
+		dst  **float64
+	}{{"package_min", &q.PackageMin}, {"package_max", &q.PackageMax}} {
+		if v := values.Get(p.name); v != "" {
+			amount, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				errs = append(errs, p.name+" must be a number")
+			}
+			*p.dst = &amount
+		}
+	}
+
+	for _, p := range []struct {
+		name string
+		dst  **time.Time
+	}{
+		{"created_after", &q.CreatedAfter},
+		{"created_before", &q.CreatedBefore},
+		{"updated_after", &q.UpdatedAfter},
+		{"updated_before", &q.UpdatedBefore},
+		{"last_interaction_after", &q.LastInteractionAfter},
+		{"last_interaction_before", &q.LastInteractionBefore},
+	} {
+		if v := values.Get(p.name); v != "" {
+			t, err := parseDateParam(v)
+			if err != nil {
+				errs = append(errs, p.name+" must be an RFC 3339 timestamp or a YYYY-MM-DD date")
+			}
+			*p.dst = &t
+		}
+	}
+
+	// tag may repeat; a company must carry every tag given.
+	if tags := values["tag"]; len(tags) > 0 {
+		normalized, err := company.NormalizeTags(tags)
+		if err != nil {
+			errs = append(errs, "tag: "+err.Error())
+		}
+		q.Tags = normalized
+	}
+
+	// field.<key>=<value> filters on a custom field; QueryCompanies checks
+	// the key and value against the field's definition.
+	var fieldParams []string
+	for name := range values {
+		if strings.HasPrefix(name, fieldParamPrefix) {
+			fieldParams = append(fieldParams, name)
+		}
+	}
+	sort.Strings(fieldParams)
+	for _, name := range fieldParams {
+		for _, v := range values[name] {
+			q.Fields = append(q.Fields, company.FieldFilter{Key: strings.TrimPrefix(name, fieldParamPrefix), Value: v})
+		}
+	}
+
+	// sort=package orders ascending, sort=-package descending.
+	q.Sort, q.Desc = company.DefaultSort, true
+	if v := values.Get("sort"); v != "" {
+		q.Sort, q.Desc = strings.TrimPrefix(v, "-"), strings.HasPrefix(v, "-")
+		if !company.IsSortKey(q.Sort) {
+			errs = append(errs, fmt.Sprintf("cannot sort by %q", q.Sort))
+		}
+	}
+
+	if v := values.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit < 1 || limit > maxPageSize {
+			errs = append(errs, fmt.Sprintf("limit must be between 1 and %d", maxPageSize))
+		}
+		q.Limit = limit
+	}
+
+	if v := values.Get("cursor"); v != "" {
+		cursor, err := company.DecodeCursor(v)
+		if err == nil && (cursor.Sort != q.Sort || cursor.Desc != q.Desc) {
+			err = errors.New("cursor was issued for a different sort order")
+		}
+		if err != nil {
+			errs = append(errs, "cursor: "+err.Error())
+		}
+		q.After = cursor
+	}
+
+	if len(errs) > 0 {
+		return q, errors.New(strings.Join(errs, "; "))
+	}
+	return q, nil
+}
+
+func parseDateParam(v string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339Nano, v); err == nil {
+		return t, nil
+	}
+	return time.Parse("2006-01-02", v)
+}
+
+// nextPageURL returns the request URL with its cursor replaced.
+func nextPageURL(u *url.URL, cursor string) string {
+	values := u.Query()
+	values.Set("cursor", cursor)
+	next := *u
+	next.RawQuery = values.Encode()
+	return next.RequestURI()
+}
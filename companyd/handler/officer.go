@@ -0,0 +1,37 @@
+package companyHandler
+
+import (
+	"backend/companyd/usecase/company"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+// CompanyOfficers lists the officers assigned to a company, primary first,
+// with when and by whom each was assigned.
+func CompanyOfficers(service company.Usecase, w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	officers, err := service.ListCompanyOfficers(mux.Vars(r)["id"])
+	if errors.Is(err, company.ErrNotFound) {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{
+			"error": "Company not found",
+		})
+		return
+	}
+	if err != nil {
+		log.Printf("Error listing company officers: %v", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(officers)
+}
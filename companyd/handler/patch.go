@@ -0,0 +1,149 @@
+package companyHandler
+
+import (
+	"backend/companyd/entity"
+	"backend/companyd/usecase/company"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+// PatchCompany applies a JSON Merge Patch (RFC 7396) to a company. Only the
+// members present in the body are changed; a null member resets the field to
+// its zero value. customFields is merged key by key, so a null value removes
+// that field's value; tags are replaced as a whole.
+func PatchCompany(service company.Usecase, w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	id := mux.Vars(r)["id"]
+
+	version, ok := requireIfMatch(w, r)
+	if !ok {
+		return
+	}
+
+	update, customFields, err := decodeMergePatch(r.Body)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{
+			"error": err.Error(),
+		})
+		return
+	}
+	if customFields != nil {
+		current, err := service.GetCompany(id)
+		if errors.Is(err, company.ErrNotFound) {
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(map[string]string{
+				"error": "Company not found",
+			})
+			return
+		}
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(map[string]string{
+				"error": err.Error(),
+			})
+			return
+		}
+		// A stale current record is harmless: the update is then refused
+		// for its version.
+		merged := current.CustomFields.Copy()
+		for key, value := range customFields {
+			if value == nil {
+				delete(merged, key)
+			} else {
+				merged[key] = value
+			}
+		}
+		update.CustomFields = &merged
+	}
+
+	saveCompanyUpdate(service, w, id, version, update, changedBy(r))
+}
+
+// decodeMergePatch turns a merge patch document into a CompanyUpdate. Unknown
+// members are rejected so that typos do not silently become no-ops. A
+// customFields object is returned separately, to be merged into the
+// company's values; a nil value in it removes that key.
+func decodeMergePatch(body io.Reader) (entity.CompanyUpdate, entity.CustomValues, error) {
+	var update entity.CompanyUpdate
+	var customFields entity.CustomValues
+	var patch map[string]json.RawMessage
+	if err := json.NewDecoder(body).Decode(&patch); err != nil || patch == nil {
+		return update, nil, fmt.Errorf("request body must be a JSON object")
+	}
+
+	stringFields := map[string]**string{
+		"companyName":    &update.CompanyName,
+		"companyAddress": &update.CompanyAddress,
+		"drive":          &update.Drive,
+		"typeOfDrive":    &update.TypeOfDrive,
+		"followUp":       &update.FollowUp,
+		"remarks":        &update.Remarks,
+		"contactDetails": &update.ContactDetails,
+		"hr1Details":     &update.HR1Details,
+		"hr2Details":     &update.HR2Details,
+		"package":        &update.Package,
+	}
+
+	for key, raw := range patch {
+		isNull := bytes.Equal(bytes.TrimSpace(raw), []byte("null"))
+		switch {
+		case stringFields[key] != nil:
+			value := new(string)
+			if !isNull {
+				if err := json.Unmarshal(raw, value); err != nil {
+					return update, nil, fmt.Errorf("%s must be a string", key)
+				}
+			}
+			*stringFields[key] = value
+		case key == "isContacted" || key == "pipelineStatus":
+			return update, nil, fmt.Errorf("%s follows the pipeline status; change it with PUT /company/{id}/status", key)
+		case key == "assignedOfficer":
+			value := []string{}
+			if !isNull {
+				if err := json.Unmarshal(raw, &value); err != nil {
+					return update, nil, fmt.Errorf("%s must be an array of strings", key)
+				}
+			}
+			update.AssignedOfficer = &value
+		case key == "compensation":
+			// The structured package is replaced as a whole; without a
+			// "package" member, its display text is regenerated.
+			value := new(entity.Compensation)
+			if !isNull {
+				decoder := json.NewDecoder(bytes.NewReader(raw))
+				decoder.DisallowUnknownFields()
+				if err := decoder.Decode(value); err != nil {
+					return update, nil, fmt.Errorf("%s must be an object of package amounts", key)
+				}
+			}
+			update.Compensation = value
+		case key == "customFields":
+			if isNull {
+				update.CustomFields = &entity.CustomValues{}
+				customFields = nil
+				continue
+			}
+			if err := json.Unmarshal(raw, &customFields); err != nil || customFields == nil {
+				return update, nil, fmt.Errorf("%s must be an object of values by field key", key)
+			}
+		case key == "tags":
+			value := []string{}
+			if !isNull {
+				if err := json.Unmarshal(raw, &value); err != nil {
+					return update, nil, fmt.Errorf("%s must be an array of strings", key)
+				}
+			}
+			update.Tags = &value
+		default:
+			return update, nil, fmt.Errorf("unknown field %q", key)
+		}
+	}
+	return update, customFields, nil
+}
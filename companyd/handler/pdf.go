@@ -0,0 +1,345 @@
+package companyHandler
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// Layout of the PDF report, in points on a landscape A4 page.
+const (
+	pdfPageWidth  = 842
+	pdfPageHeight = 595
+	pdfMargin     = 36
+	pdfFontSize   = 7
+	pdfLeading    = 9
+	pdfPadding    = 3
+	// pdfMaxLines bounds the lines of one cell; longer text is cut short.
+	pdfMaxLines = 4
+)
+
+// Objects written before the pages. The page tree is written last, once
+// every page is known.
+const (
+	pdfCatalog = 1 + iota
+	pdfPages
+	pdfFont
+	pdfBoldFont
+	pdfInfo
+	pdfFirstFree
+)
+
+// helveticaWidths are the widths of the printable ASCII characters in
+// Helvetica, in thousandths of the font size.
+var helveticaWidths = [95]int{
+	278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+	1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+	333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+	556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+}
+
+// pdfWidth estimates the width of WinAnsi-encoded text. Bold text is
+// measured a little wide so that it never overflows its column.
+func pdfWidth(text string, size float64, bold bool) float64 {
+	total := 0
+	for i := 0; i < len(text); i++ {
+		if c := text[i]; c >= 32 && c < 127 {
+			total += helveticaWidths[c-32]
+		} else {
+			total += 556
+		}
+	}
+	width := float64(total) * size / 1000
+	if bold {
+		width *= 1.08
+	}
+	return width
+}
+
+// winAnsi are the characters outside Latin-1 that the standard fonts can
+// show, with their WinAnsiEncoding codes.
+var winAnsi = map[rune]byte{
+	'€': 0x80, '‚': 0x82, '„': 0x84, '…': 0x85, '‘': 0x91, '’': 0x92,
+	'“': 0x93, '”': 0x94, '•': 0x95, '–': 0x96, '—': 0x97, '™': 0x99,
+}
+
+// pdfEncode converts s to WinAnsiEncoding, the encoding of the standard
+// fonts. Characters they cannot show become "?", except the rupee sign.
+func pdfEncode(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '₹':
+			b.WriteString("Rs.")
+		case r == '\t':
+			b.WriteByte(' ')
+		case r >= 32 && r < 127, r >= 0xa0 && r <= 0xff:
+			b.WriteByte(byte(r))
+		case winAnsi[r] != 0:
+			b.WriteByte(winAnsi[r])
+		case r == '\n':
+			b.WriteByte('\n')
+		case r == '\r':
+		default:
+			b.WriteByte('?')
+		}
+	}
+	return b.String()
+}
+
+// pdfWrap breaks encoded text into at most pdfMaxLines lines that fit width,
+// ending the last with an ellipsis when text is left over.
+func pdfWrap(text string, width, size float64, bold bool) []string {
+	fits := func(s string) bool { return pdfWidth(s, size, bold) <= width }
+	var lines []string
+	for _, paragraph := range strings.Split(text, "\n") {
+		line := ""
+		for _, word := range strings.Fields(paragraph) {
+			candidate := word
+			if line != "" {
+				candidate = line + " " + word
+			}
+			if fits(candidate) {
+				line = candidate
+				continue
+			}
+			if line != "" {
+				lines = append(lines, line)
+			}
+			// Break words that are wider than the column.
+			for !fits(word) {
+				cut := len(word) - 1
+				for cut > 1 && !fits(word[:cut]) {
+					cut--
+				}
+				lines = append(lines, word[:cut])
+				word = word[cut:]
+			}
+			line = word
+		}
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	if len(lines) > pdfMaxLines {
+		last := lines[pdfMaxLines-1]
+		for last != "" && !fits(last+"\x85") {
+			last = last[:len(last)-1]
+		}
+		lines = append(lines[:pdfMaxLines-1], strings.TrimRight(last, " ")+"\x85")
+	}
+	return lines
+}
+
+// pdfString quotes encoded text as a PDF literal string.
+func pdfString(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, `(`, `\(`, `)`, `\)`)
+	return "(" + r.Replace(s) + ")"
+}
+
+// countingWriter tracks the offset of each PDF object for the
+// cross-reference table.
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// pdfReport describes the table a pdfExporter draws.
+type pdfReport struct {
+	Title    string
+	Subtitle string
+	Headings []string
+	// Widths are the columns' relative widths.
+	Widths []float64
+}
+
+// pdfExporter streams a tabular report as a PDF using the standard
+// Helvetica fonts, so no font is embedded. Only the page being drawn is held
+// in memory; the heading row is repeated on every page.
+type pdfExporter struct {
+	out     *countingWriter
+	report  pdfReport
+	widths  []float64
+	offsets map[int]int64
+	next    int
+	pages   []int
+	page    bytes.Buffer
+	y       float64
+	rows    int
+}
+
+func newPDFExporter(w io.Writer, report pdfReport) (*pdfExporter, error) {
+	e := &pdfExporter{
+		out:     &countingWriter{w: w},
+		report:  report,
+		offsets: map[int]int64{},
+		next:    pdfFirstFree,
+	}
+	total := 0.0
+	for _, width := range report.Widths {
+		total += width
+	}
+	for _, width := range report.Widths {
+		e.widths = append(e.widths, width/total*(pdfPageWidth-2*pdfMargin))
+	}
+
+	// The comment's high bytes mark the file as binary.
+	if _, err := io.WriteString(e.out, "%PDF-1.4\n%\xe2\xe3\xcf\xd3\n"); err != nil {
+		return nil, err
+	}
+	objects := []struct {
+		num  int
+		body string
+	}{
+		{pdfCatalog, fmt.Sprintf("<< /Type /Catalog /Pages %d 0 R >>", pdfPages)},
+		{pdfFont, "<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>"},
+		{pdfBoldFont, "<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>"},
+		{pdfInfo, fmt.Sprintf("<< /Title %s /CreationDate (D:%s) >>", pdfString(pdfEncode(report.Title)), time.Now().UTC().Format("20060102150405Z"))},
+	}
+	for _, o := range objects {
+		if err := e.writeObject(o.num, o.body); err != nil {
+			return nil, err
+		}
+	}
+	e.startPage()
+	return e, nil
+}
+
+func (e *pdfExporter) writeObject(num int, body string) error {
+	e.offsets[num] = e.out.n
+	_, err := fmt.Fprintf(e.out, "%d 0 obj\n%s\nendobj\n", num, body)
+	return err
+}
+
+func (e *pdfExporter) text(x, y float64, size float64, bold bool, s string) {
+	font := "F1"
+	if bold {
+		font = "F2"
+	}
+	fmt.Fprintf(&e.page, "BT /%s %g Tf %.2f %.2f Td %s Tj ET\n", font, size, x, y, pdfString(s))
+}
+
+func (e *pdfExporter) startPage() {
+	e.page.Reset()
+	e.y = pdfPageHeight - pdfMargin
+	if len(e.pages) == 0 {
+		e.text(pdfMargin, e.y-14, 14, true, pdfEncode(e.report.Title))
+		e.text(pdfMargin, e.y-28, 8, false, pdfEncode(e.report.Subtitle))
+		e.y -= 40
+	}
+	e.drawRow(e.report.Headings, true, 0.85)
+	// Rule under the heading row.
+	fmt.Fprintf(&e.page, "0.5 w %d %.2f m %d %.2f l S\n", pdfMargin, e.y, pdfPageWidth-pdfMargin, e.y)
+}
+
+// drawRow draws cells below e.y, shaded with the given grey unless it is 1.
+func (e *pdfExporter) drawRow(cells []string, bold bool, shade float64) {
+	wrapped := make([][]string, len(cells))
+	lines := 1
+	for i, cell := range cells {
+		if i < len(e.widths) {
+			wrapped[i] = pdfWrap(pdfEncode(cell), e.widths[i]-2*pdfPadding, pdfFontSize, bold)
+			lines = max(lines, len(wrapped[i]))
+		}
+	}
+	height := float64(lines*pdfLeading + 2*pdfPadding)
+	if shade < 1 {
+		fmt.Fprintf(&e.page, "%g g %d %.2f %d %.2f re f 0 g\n", shade, pdfMargin, e.y-height, pdfPageWidth-2*pdfMargin, height)
+	}
+	x := float64(pdfMargin)
+	for i, cellLines := range wrapped {
+		for j, line := range cellLines {
+			e.text(x+pdfPadding, e.y-pdfPadding-pdfFontSize-float64(j*pdfLeading), pdfFontSize, bold, line)
+		}
+		if i < len(e.widths) {
+			x += e.widths[i]
+		}
+	}
+	e.y -= height
+}
+
+// rowHeight is the height drawRow would give cells.
+func (e *pdfExporter) rowHeight(cells []string) float64 {
+	lines := 1
+	for i, cell := range cells {
+		if i < len(e.widths) {
+			lines = max(lines, len(pdfWrap(pdfEncode(cell), e.widths[i]-2*pdfPadding, pdfFontSize, false)))
+		}
+	}
+	return float64(lines*pdfLeading + 2*pdfPadding)
+}
+
+func (e *pdfExporter) finishPage() error {
+	number := len(e.pages) + 1
+	footer := fmt.Sprintf("Page %d", number)
+	e.text(pdfPageWidth-pdfMargin-pdfWidth(footer, pdfFontSize, false), pdfMargin/2, pdfFontSize, false, footer)
+
+	content, page := e.next, e.next+1
+	e.next += 2
+	if err := e.writeObject(content, fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", e.page.Len(), e.page.String())); err != nil {
+		return err
+	}
+	e.pages = append(e.pages, page)
+	return e.writeObject(page, fmt.Sprintf(
+		"<< /Type /Page /Parent %d 0 R /MediaBox [0 0 %d %d] /Resources << /Font << /F1 %d 0 R /F2 %d 0 R >> >> /Contents %d 0 R >>",
+		pdfPages, pdfPageWidth, pdfPageHeight, pdfFont, pdfBoldFont, content))
+}
+
+func (e *pdfExporter) WriteRow(cells []string) error {
+	// Leave room for the footer.
+	if e.y-e.rowHeight(cells) < pdfMargin {
+		if err := e.finishPage(); err != nil {
+			return err
+		}
+		e.startPage()
+	}
+	shade := 1.0
+	if e.rows%2 == 1 {
+		shade = 0.95
+	}
+	e.rows++
+	e.drawRow(cells, false, shade)
+	return nil
+}
+
+// Flush does nothing: each page is written once it is full.
+func (e *pdfExporter) Flush() error {
+	return nil
+}
+
+func (e *pdfExporter) Close() error {
+	if e.rows == 0 {
+		e.text(pdfMargin+pdfPadding, e.y-pdfPadding-pdfFontSize-pdfLeading, pdfFontSize, false, "No companies match these filters.")
+	}
+	if err := e.finishPage(); err != nil {
+		return err
+	}
+
+	kids := make([]string, len(e.pages))
+	for i, page := range e.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", page)
+	}
+	if err := e.writeObject(pdfPages, fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(e.pages))); err != nil {
+		return err
+	}
+
+	xref := e.out.n
+	var table strings.Builder
+	fmt.Fprintf(&table, "xref\n0 %d\n0000000000 65535 f \n", e.next)
+	for num := 1; num < e.next; num++ {
+		fmt.Fprintf(&table, "%010d 00000 n \n", e.offsets[num])
+	}
+	fmt.Fprintf(&table, "trailer\n<< /Size %d /Root %d 0 R /Info %d 0 R >>\nstartxref\n%d\n%%%%EOF\n", e.next, pdfCatalog, pdfInfo, xref)
+	_, err := io.WriteString(e.out, table.String())
+	return err
+}
@@ -0,0 +1,129 @@
+package companyHandler
+
+import (
+	companyPresenter "backend/companyd/presenter"
+	"backend/companyd/usecase/company"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+// writePipelineError maps pipeline usecase errors to responses. A transition
+// the pipeline does not allow is a 409 listing the stages the company can
+// move to instead.
+func writePipelineError(w http.ResponseWriter, err error) {
+	var transition *company.TransitionError
+	switch {
+	case errors.As(err, &transition):
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"error":   err.Error(),
+			"from":    transition.From,
+			"to":      transition.To,
+			"allowed": transition.Allowed,
+		})
+		return
+	case errors.Is(err, company.ErrNotFound):
+		w.WriteHeader(http.StatusNotFound)
+		err = errors.New("Company not found")
+	case errors.Is(err, company.ErrUnknownStage):
+		w.WriteHeader(http.StatusBadRequest)
+	default:
+		log.Printf("Error reading the pipeline: %v", err)
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+	json.NewEncoder(w).Encode(map[string]string{
+		"error": err.Error(),
+	})
+}
+
+// GetPipeline returns the stages companies move through and the transitions
+// allowed between them.
+func GetPipeline(service company.Usecase, w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(service.Pipeline())
+}
+
+// ChangeCompanyStatus moves a company to another pipeline stage. Like any
+// edit, the request must carry the company's current ETag.
+func ChangeCompanyStatus(service company.Usecase, w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	id := mux.Vars(r)["id"]
+
+	version, ok := requireIfMatch(w, r)
+	if !ok {
+		return
+	}
+
+	var req companyPresenter.ChangeStatus
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || strings.TrimSpace(req.Status) == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{
+			"error": "status must be a stage of the pipeline",
+		})
+		return
+	}
+
+	updated, err := service.ChangeStatus(id, version, strings.TrimSpace(req.Status), changedBy(r))
+	if errors.Is(err, company.ErrVersionMismatch) {
+		writeVersionConflict(service, w, id)
+		return
+	}
+	if err != nil {
+		writePipelineError(w, err)
+		return
+	}
+
+	w.Header().Set("ETag", etag(updated.Version))
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(updated)
+}
+
+// CompanyStatusHistory returns a company's pipeline stage, the stages it can
+// move to next and every transition it went through, oldest first.
+func CompanyStatusHistory(service company.Usecase, w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	history, err := service.StatusHistory(mux.Vars(r)["id"])
+	if err != nil {
+		writePipelineError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(history)
+}
+
+// PipelineFunnel reports, for the season given as season (such as 2026-27)
+// or the active one, how many companies reached each pipeline stage and
+// the conversion rate from one stage to the next.
+func PipelineFunnel(service company.Usecase, w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	season := r.URL.Query().Get("season")
+	if season != "" {
+		parsed, ok := company.ParseSeason(season)
+		if !ok {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{
+				"error": "season must be an academic year such as 2026-27",
+			})
+			return
+		}
+		season = parsed
+	}
+
+	funnel, err := service.Funnel(season)
+	if err != nil {
+		writePipelineError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(funnel)
+}
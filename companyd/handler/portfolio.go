@@ -0,0 +1,79 @@
+package companyHandler
+
+import (
+	"backend/companyd/usecase/company"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// defaultEventWindow is how far ahead a portfolio looks for events.
+const defaultEventWindow = 7 * 24 * time.Hour
+
+// OfficerPortfolio gathers an officer's companies, overdue follow-ups,
+// pending proposals and events within the next week, or the Go duration
+// given as within. Without a username in the path it is the caller's own.
+// Officers may only see their own portfolio.
+func OfficerPortfolio(service company.Usecase, w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	who, ok := requireCaller(w, r, false)
+	if !ok {
+		return
+	}
+	username := mux.Vars(r)["username"]
+	if username == "" {
+		username = who.Username
+	}
+	if username == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{
+			"error": usernameHeader + " header or a username in the path is required",
+		})
+		return
+	}
+	if !who.seesAllCompanies() && username != who.Username {
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(map[string]string{
+			"error": "Officers can only see their own portfolio",
+		})
+		return
+	}
+	within := defaultEventWindow
+	if v := r.URL.Query().Get("within"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 || d > maxDueWindow {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{
+				"error": "within must be a duration such as 48h, at most " + strconv.Itoa(int(maxDueWindow.Hours())) + "h",
+			})
+			return
+		}
+		within = d
+	}
+
+	portfolio, err := service.OfficerPortfolio(username, within)
+	if errors.Is(err, company.ErrNotFound) {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{
+			"error": "Officer not found",
+		})
+		return
+	}
+	if err != nil {
+		log.Printf("Error building the portfolio of %s: %v", username, err)
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(portfolio)
+}
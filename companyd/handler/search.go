@@ -0,0 +1,64 @@
+package companyHandler
+
+import (
+	"backend/companyd/entity"
+	"backend/companyd/usecase/company"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+)
+
+// maxSearchResults bounds the limit query parameter of /company/search.
+const maxSearchResults = 100
+
+// SearchCompanies answers /company/search?q=. Officers only see companies
+// assigned to them.
+func SearchCompanies(service company.Usecase, w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	who, ok := requireCaller(w, r, false)
+	if !ok {
+		return
+	}
+
+	query := company.SearchQuery{
+		Text:    r.URL.Query().Get("q"),
+		Officer: who.visibleOfficer(),
+	}
+	if v := r.URL.Query().Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit < 1 || limit > maxSearchResults {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{
+				"error": "limit must be between 1 and " + strconv.Itoa(maxSearchResults),
+			})
+			return
+		}
+		query.Limit = limit
+	}
+
+	results, err := service.SearchCompanies(query)
+	if errors.Is(err, company.ErrEmptySearch) {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{
+			"error": "q must contain at least one word",
+		})
+		return
+	}
+	if err != nil {
+		log.Printf("Error searching companies: %v", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	if results == nil {
+		results = []*entity.CompanySearchResult{}
+	}
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(results)
+}
@@ -0,0 +1,196 @@
+package companyHandler
+
+import (
+	companyPresenter "backend/companyd/presenter"
+	"backend/companyd/usecase/company"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// writeSeasonError maps season usecase errors to responses.
+func writeSeasonError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, company.ErrInvalidSeason),
+		errors.Is(err, company.ErrInvalidRollover),
+		errors.Is(err, company.ErrUnknownCompany):
+		w.WriteHeader(http.StatusBadRequest)
+	default:
+		log.Printf("Error handling seasons: %v", err)
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+	json.NewEncoder(w).Encode(map[string]string{
+		"error": err.Error(),
+	})
+}
+
+// seasonParam reads the season parameter of a listing: the active season
+// when absent, every season for "all", otherwise the season named.
+func seasonParam(service company.Usecase, values url.Values) (string, error) {
+	switch season := values.Get("season"); season {
+	case "":
+		return service.ActiveSeason()
+	case company.AllSeasons:
+		return "", nil
+	default:
+		parsed, ok := company.ParseSeason(season)
+		if !ok {
+			return "", company.ErrInvalidSeason
+		}
+		return parsed, nil
+	}
+}
+
+// inActiveSeason scopes a query parsed by parseListQuery to the active
+// season when the request names no season.
+func inActiveSeason(service company.Usecase, values url.Values, query *company.ListQuery) error {
+	if values.Get("season") != "" {
+		return nil
+	}
+	season, err := service.ActiveSeason()
+	query.Season = season
+	return err
+}
+
+// ListSeasons returns every season, oldest first, with the active one
+// marked.
+func ListSeasons(service company.Usecase, w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	seasons, err := service.ListSeasons()
+	if err != nil {
+		writeSeasonError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(seasons)
+}
+
+// SetActiveSeason changes the season listings and new companies default to.
+// Only admins may change it.
+func SetActiveSeason(service company.Usecase, w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	who, ok := requireCaller(w, r, false)
+	if !ok {
+		return
+	}
+	if !who.isAdmin() {
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(map[string]string{
+			"error": "Only admins can change the active season",
+		})
+		return
+	}
+
+	var req companyPresenter.ActiveSeason
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{
+			"error": "Invalid request body",
+		})
+		return
+	}
+
+	season, err := service.SetActiveSeason(req.Season)
+	if err != nil {
+		writeSeasonError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(season)
+}
+
+// RolloverSeason carries a season's companies forward into the next season
+// with their pipeline status reset. Only admins may roll a season over.
+func RolloverSeason(service company.Usecase, w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	who, ok := requireCaller(w, r, false)
+	if !ok {
+		return
+	}
+	if !who.isAdmin() {
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(map[string]string{
+			"error": "Only admins can roll a season over",
+		})
+		return
+	}
+
+	var req companyPresenter.Rollover
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{
+			"error": "Invalid request body",
+		})
+		return
+	}
+	if req.From == "" {
+		active, err := service.ActiveSeason()
+		if err != nil {
+			writeSeasonError(w, err)
+			return
+		}
+		req.From = active
+	}
+	if req.To == "" {
+		from, ok := company.ParseSeason(req.From)
+		if !ok {
+			writeSeasonError(w, company.ErrInvalidSeason)
+			return
+		}
+		req.To = company.NextSeason(from)
+	}
+
+	rollover, err := service.RolloverSeason(req.From, req.To, req.CompanyIDs, changedBy(r))
+	if err != nil {
+		writeSeasonError(w, err)
+		return
+	}
+	if req.Activate {
+		if _, err := service.SetActiveSeason(rollover.To); err != nil {
+			writeSeasonError(w, err)
+			return
+		}
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(rollover)
+}
+
+// CompareSeasons reports on the seasons given as a comma-separated seasons
+// parameter, or on the active season and the one before it, and how each
+// changed from the last.
+func CompareSeasons(service company.Usecase, w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	var seasons []string
+	for _, season := range strings.Split(r.URL.Query().Get("seasons"), ",") {
+		if season = strings.TrimSpace(season); season != "" {
+			seasons = append(seasons, season)
+		}
+	}
+	if len(seasons) == 0 {
+		active, err := service.ActiveSeason()
+		if err != nil {
+			writeSeasonError(w, err)
+			return
+		}
+		seasons = []string{company.PreviousSeason(active), active}
+	}
+
+	comparison, err := service.CompareSeasons(seasons)
+	if err != nil {
+		writeSeasonError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(comparison)
+}
@@ -0,0 +1,84 @@
+package companyHandler
+
+import (
+	"backend/companyd/usecase/company"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// PackageStats answers /company/package/stats with the distribution of
+// packages, grouped by type of drive and season unless group_by says
+// otherwise.
+func PackageStats(service company.Usecase, w http.ResponseWriter, r *http.Request) {
+	query, err := parsePackageStatsQuery(r.URL.Query())
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	stats, err := service.PackageStats(query)
+	if err != nil {
+		log.Printf("Error computing package statistics: %v", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(stats)
+}
+
+// parsePackageStatsQuery reads the /company/package/stats query parameters.
+// An empty group_by asks for a single overall group; season is the active
+// one when absent and every season for "all".
+func parsePackageStatsQuery(values url.Values) (company.PackageStatsQuery, error) {
+	q := company.PackageStatsQuery{
+		TypeOfDrive: values.Get("type_of_drive"),
+		Season:      values.Get("season"),
+		Metric:      values.Get("metric"),
+		Currency:    strings.ToUpper(values.Get("currency")),
+		GroupBy:     []string{company.GroupByTypeOfDrive, company.GroupBySeason},
+	}
+	var errs []string
+
+	if values.Has("group_by") {
+		q.GroupBy = nil
+		for _, key := range strings.Split(values.Get("group_by"), ",") {
+			key = strings.TrimSpace(key)
+			if key == "" {
+				continue
+			}
+			if !company.IsPackageGroup(key) {
+				errs = append(errs, fmt.Sprintf("cannot group by %q", key))
+			}
+			q.GroupBy = append(q.GroupBy, key)
+		}
+	}
+
+	if q.Season != "" && q.Season != company.AllSeasons {
+		season, ok := company.ParseSeason(q.Season)
+		if !ok {
+			errs = append(errs, company.ErrInvalidSeason.Error())
+		}
+		q.Season = season
+	}
+
+	if q.Metric != "" && !company.IsPackageMetric(q.Metric) {
+		errs = append(errs, fmt.Sprintf("metric must be %s or %s", company.MetricCTC, company.MetricStipend))
+	}
+
+	if len(errs) > 0 {
+		return q, errors.New(strings.Join(errs, "; "))
+	}
+	return q, nil
+}
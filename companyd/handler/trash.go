@@ -0,0 +1,74 @@
+package companyHandler
+
+import (
+	"backend/companyd/entity"
+	"backend/companyd/usecase/company"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+// ListDeletedCompanies lists the companies in the trash, most recently
+// deleted first. They stay there until restored or purged.
+func ListDeletedCompanies(service company.Usecase, w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	companies, err := service.ListDeletedCompanies()
+	if err != nil {
+		log.Printf("Error listing deleted companies: %v", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(companies)
+}
+
+// RestoreCompany takes a company out of the trash.
+func RestoreCompany(service company.Usecase, w http.ResponseWriter, r *http.Request) {
+	restored, err := service.RestoreCompany(mux.Vars(r)["id"])
+	writeCompanyState(w, restored, err, "Company not found in trash")
+}
+
+// ArchiveCompany hides a company from the default list without deleting it.
+func ArchiveCompany(service company.Usecase, w http.ResponseWriter, r *http.Request) {
+	archived, err := service.ArchiveCompany(mux.Vars(r)["id"], changedBy(r))
+	writeCompanyState(w, archived, err, "Company not found")
+}
+
+// UnarchiveCompany returns an archived company to the default list.
+func UnarchiveCompany(service company.Usecase, w http.ResponseWriter, r *http.Request) {
+	unarchived, err := service.UnarchiveCompany(mux.Vars(r)["id"])
+	writeCompanyState(w, unarchived, err, "Company not found")
+}
+
+// writeCompanyState answers a restore, archive or unarchive request.
+func writeCompanyState(w http.ResponseWriter, c *entity.Company, err error, notFound string) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if errors.Is(err, company.ErrNotFound) {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{
+			"error": notFound,
+		})
+		return
+	}
+	if err != nil {
+		log.Printf("Error changing company state: %v", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	w.Header().Set("ETag", etag(c.Version))
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(c)
+}
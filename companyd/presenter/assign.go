@@ -0,0 +1,15 @@
+package companyPresenter
+
+import "backend/companyd/entity"
+
+// AssignedOfficer is a company after the assignment engine has given it a
+// primary officer, and why that officer was chosen.
+type AssignedOfficer struct {
+	Company *entity.Company           `json:"company"`
+	Officer *entity.OfficerSuggestion `json:"officer"`
+}
+
+// ApplyRebalance is the moves of a previewed rebalance plan to apply.
+type ApplyRebalance struct {
+	Moves []entity.RebalanceMove `json:"moves"`
+}
@@ -1,5 +1,7 @@
 package companyPresenter
 
+import "backend/companyd/entity"
+
 type CreateCompany struct {
 	CompanyName     string `json:"companyName"`
 	CompanyAddress  string `json:"companyAddress"`
@@ -13,4 +15,14 @@ type CreateCompany struct {
 	Hr2Details      string `json:"hr2Details"`
 	Package         string `json:"package"`
 	AssignedOfficer []string `json:"assignedOfficer"`
+	// Compensation is the structured package. When omitted, it is parsed
+	// from Package.
+	Compensation *entity.Compensation `json:"compensation"`
+	// CustomFields holds values of the admin-defined fields, by key, and
+	// Tags free-form labels.
+	CustomFields entity.CustomValues `json:"customFields"`
+	Tags         []string            `json:"tags"`
+	// AutoAssignOfficer asks the assignment engine to pick the officer when
+	// AssignedOfficer is empty.
+	AutoAssignOfficer bool `json:"autoAssignOfficer"`
 }
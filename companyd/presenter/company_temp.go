@@ -1,5 +1,10 @@
 package companyPresenter
 
+import "backend/companyd/entity"
+
+// CreateCompanyTemp is a proposed update. IsContacted is only decoded so
+// that a proposal sending it can be rejected: the flag follows the pipeline
+// status, which proposals never change.
 type CreateCompanyTemp struct {
 	CompanyID       string   `json:"company_id"`
 	CompanyName     string   `json:"company_name"`
@@ -7,7 +12,7 @@ type CreateCompanyTemp struct {
 	Drive           string   `json:"drive"`
 	TypeOfDrive     string   `json:"type_of_drive"`
 	FollowUp        string   `json:"follow_up"`
-	IsContacted     bool     `json:"is_contacted"`
+	IsContacted     *bool    `json:"is_contacted"`
 	Remarks         string   `json:"remarks"`
 	ContactDetails  string   `json:"contact_details"`
 	Hr1Details      string   `json:"hr1_details"`
@@ -17,23 +22,10 @@ type CreateCompanyTemp struct {
 	CreatedBy       string   `json:"created_by"`
 }
 
+// CompanyTempResponse is a proposal together with the actions that can
+// still be taken on it: approve, reject and withdraw while it is pending,
+// none once it is approved, rejected, withdrawn or superseded.
 type CompanyTempResponse struct {
-	ID              string   `json:"id"`
-	CompanyID       string   `json:"company_id"`
-	CompanyName     string   `json:"company_name"`
-	CompanyAddress  string   `json:"company_address"`
-	Drive           string   `json:"drive"`
-	TypeOfDrive     string   `json:"type_of_drive"`
-	FollowUp        string   `json:"follow_up"`
-	IsContacted     bool     `json:"is_contacted"`
-	Remarks         string   `json:"remarks"`
-	ContactDetails  string   `json:"contact_details"`
-	Hr1Details      string   `json:"hr1_details"`
-	Hr2Details      string   `json:"hr2_details"`
-	Package         string   `json:"package"`
-	AssignedOfficer []string `json:"assigned_officer"`
-	CreatedBy       string   `json:"created_by"`
-	Status          string   `json:"status"`
-	CreatedAt       string   `json:"created_at"`
-	UpdatedAt       string   `json:"updated_at"`
+	*entity.CompanyTemp
+	AllowedActions []string `json:"allowedActions"`
 }
@@ -0,0 +1,12 @@
+package companyPresenter
+
+type SaveContact struct {
+	CompanyID   string `json:"companyId"`
+	Name        string `json:"name"`
+	Designation string `json:"designation"`
+	Email       string `json:"email"`
+	Phone       string `json:"phone"`
+	LinkedIn    string `json:"linkedIn"`
+	IsPrimary   bool   `json:"isPrimary"`
+	Notes       string `json:"notes"`
+}
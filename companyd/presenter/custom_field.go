@@ -0,0 +1,11 @@
+package companyPresenter
+
+// CustomField defines or changes a custom field. Key and Type are only read
+// when the field is created.
+type CustomField struct {
+	Key      string   `json:"key"`
+	Label    string   `json:"label"`
+	Type     string   `json:"type"`
+	Options  []string `json:"options"`
+	Required bool     `json:"required"`
+}
@@ -0,0 +1,16 @@
+package companyPresenter
+
+// SaveDrive creates or updates a drive. Type may be free text such as
+// "On Campus"; dates are YYYY-MM-DD or RFC 3339. CompanyID is only read
+// when creating, since a drive stays with its company.
+type SaveDrive struct {
+	CompanyID string   `json:"companyId"`
+	Type      string   `json:"type"`
+	Season    string   `json:"season"`
+	StartsOn  string   `json:"startsOn"`
+	EndsOn    string   `json:"endsOn"`
+	Roles     []string `json:"roles"`
+	Package   string   `json:"package"`
+	Status    string   `json:"status"`
+	Notes     string   `json:"notes"`
+}
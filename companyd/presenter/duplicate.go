@@ -0,0 +1,17 @@
+package companyPresenter
+
+import "backend/companyd/entity"
+
+// CreatedCompany is a newly created company together with the existing
+// companies it probably duplicates and, when the assignment engine chose its
+// officer, why.
+type CreatedCompany struct {
+	*entity.Company
+	Warning      string                    `json:"warning,omitempty"`
+	Duplicates   []*entity.DuplicateMatch  `json:"duplicates,omitempty"`
+	AutoAssigned *entity.OfficerSuggestion `json:"autoAssigned,omitempty"`
+}
+
+type MergeCompany struct {
+	DuplicateID string `json:"duplicateId"`
+}
@@ -0,0 +1,9 @@
+package companyPresenter
+
+type SaveFollowUp struct {
+	CompanyID string `json:"companyId"`
+	Officer   string `json:"officer"`
+	DueAt     string `json:"dueAt"`
+	Note      string `json:"note"`
+	Status    string `json:"status"`
+}
@@ -0,0 +1,5 @@
+package companyPresenter
+
+type RevertCompany struct {
+	Version int `json:"version"`
+}
@@ -0,0 +1,12 @@
+package companyPresenter
+
+import "backend/companyd/entity"
+
+// ImportCompanies is the response to a spreadsheet import: the column
+// mapping that was applied and the import report.
+type ImportCompanies struct {
+	// Mapping maps each used spreadsheet column to a company field.
+	Mapping        map[string]string `json:"mapping"`
+	IgnoredColumns []string          `json:"ignoredColumns"`
+	*entity.ImportReport
+}
@@ -0,0 +1,10 @@
+package companyPresenter
+
+type SaveInteraction struct {
+	CompanyID  string `json:"companyId"`
+	Type       string `json:"type"`
+	OccurredAt string `json:"occurredAt"`
+	Outcome    string `json:"outcome"`
+	Notes      string `json:"notes"`
+	NextStep   string `json:"nextStep"`
+}
@@ -0,0 +1,6 @@
+package companyPresenter
+
+// ChangeStatus moves a company to another stage of the recruitment pipeline.
+type ChangeStatus struct {
+	Status string `json:"status"`
+}
@@ -0,0 +1,16 @@
+package companyPresenter
+
+// ActiveSeason sets the season listings and new companies default to.
+type ActiveSeason struct {
+	Season string `json:"season"`
+}
+
+// Rollover carries a season's companies forward. From defaults to the active
+// season and To to the season after From; CompanyIDs limits the rollover to
+// some companies. Activate makes To the active season afterwards.
+type Rollover struct {
+	From       string   `json:"from"`
+	To         string   `json:"to"`
+	CompanyIDs []string `json:"companyIds"`
+	Activate   bool     `json:"activate"`
+}
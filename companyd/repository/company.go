@@ -2,159 +2,470 @@ package repository
 
 import (
 	"backend/companyd/entity"
+	"backend/companyd/repository/pgtypes"
+	"backend/companyd/usecase/company"
 	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
 
 	"github.com/lib/pq"
 )
 
+// companyFields are the columns of companies read into a Company, less its
+// officers, which scanCompany reads last.
+const companyFields = `id, company_name, company_address, drive, type_of_drive, follow_up, is_contacted, remarks, contact_details, hr1_details, hr2_details, package, ` + compensationColumns + `, version, last_interaction_at, last_interaction_outcome, archived_at, archived_by, deleted_at, deleted_by, created_at, updated_at, pipeline_status, season, COALESCE(carried_from::text, ''), custom_fields, tags`
+
+const companyColumns = companyFields + `, ` + assignedOfficerColumn
+
+// legacyCompanyColumns read the officers from the assigned_officer array,
+// for the data migrations that ran before company_officers existed.
+const legacyCompanyColumns = companyFields + `, assigned_officer`
+
+// compensationColumns hold Company.Compensation, in the order of
+// compensationArgs.
+const compensationColumns = `package_base, package_variable, package_stipend, package_currency, package_unit, package_min, package_max, package_needs_review`
+
+const companyTempColumns = `id, company_id, company_name, company_address, drive, type_of_drive, follow_up, is_contacted, remarks, contact_details, hr1_details, hr2_details, package, assigned_officer, status, COALESCE(base_version, 0), season, created_by, created_at, updated_at`
+
 type Repository struct {
 	db *sql.DB
 }
 
+var _ company.Repository = (*Repository)(nil)
+
 func NewCompanyRepository(db *sql.DB) *Repository {
 	return &Repository{db: db}
 }
 
-func (r *Repository) CreateCompany(companyName, companyAddress, drive, typeOfDrive, followUp, isContacted, remarks, contactDetails, hr1Details, hr2Details, pkg string, assignedOfficer []string) (*entity.Company, error) {
-	query := `
-		INSERT INTO companies (company_name, company_address, drive, type_of_drive, follow_up, is_contacted, remarks, contact_details, hr1_details, hr2_details, package, assigned_officer) 
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
-		RETURNING id, company_name, company_address, drive, type_of_drive, follow_up, is_contacted, remarks, contact_details, hr1_details, hr2_details, package, assigned_officer, created_at, updated_at`
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
 
+func scanCompany(row scanner) (*entity.Company, error) {
 	var company entity.Company
-	var assignedOfficerResult []string
-	err := r.db.QueryRow(query, companyName, companyAddress, drive, typeOfDrive, followUp, isContacted, remarks, contactDetails, hr1Details, hr2Details, pkg, pq.Array(assignedOfficer)).Scan(
-		&company.ID, &company.CompanyName, &company.CompanyAddress, &company.Drive, &company.TypeOfDrive, &company.FollowUp, &company.IsContacted, &company.Remarks, &company.ContactDetails, &company.HR1Details, &company.HR2Details, &company.Package, pq.Array(&assignedOfficerResult), &company.CreatedAt, &company.UpdatedAt,
+	var assignedOfficer, tags []string
+	var customFields []byte
+	var base, variable, stipend, min, max sql.NullFloat64
+	var lastInteractionAt, archivedAt, deletedAt sql.NullTime
+	err := row.Scan(
+		&company.ID, &company.CompanyName, &company.CompanyAddress, &company.Drive, &company.TypeOfDrive, &company.FollowUp, &company.IsContacted, &company.Remarks, &company.ContactDetails, &company.HR1Details, &company.HR2Details, &company.Package,
+		&base, &variable, &stipend, &company.Compensation.Currency, &company.Compensation.Unit, &min, &max, &company.Compensation.NeedsReview,
+		&company.Version, &lastInteractionAt, &company.LastInteractionOutcome,
+		&archivedAt, &company.ArchivedBy, &deletedAt, &company.DeletedBy, &company.CreatedAt, &company.UpdatedAt, &company.PipelineStatus, &company.Season, &company.CarriedFrom,
+		&customFields, pq.Array(&tags), pq.Array(&assignedOfficer),
 	)
 	if err != nil {
 		return nil, err
 	}
-	company.AssignedOfficer = assignedOfficerResult
+	if err := json.Unmarshal(customFields, &company.CustomFields); err != nil {
+		return nil, err
+	}
+	company.AssignedOfficer = assignedOfficer
+	company.Tags = tags
+	company.Compensation.Base = nullAmount(base)
+	company.Compensation.Variable = nullAmount(variable)
+	company.Compensation.Stipend = nullAmount(stipend)
+	company.Compensation.Min = nullAmount(min)
+	company.Compensation.Max = nullAmount(max)
+	company.LastInteractionAt = nullTime(lastInteractionAt)
+	company.ArchivedAt = nullTime(archivedAt)
+	company.DeletedAt = nullTime(deletedAt)
 	return &company, nil
 }
 
-func (r *Repository) DeleteCompany(id string) error {
-	query := `DELETE FROM companies WHERE id = $1`
-	_, err := r.db.Exec(query, id)
-	return err
+func nullAmount(v sql.NullFloat64) *float64 {
+	if !v.Valid {
+		return nil
+	}
+	return &v.Float64
 }
 
-func (r *Repository) ListCompanies() ([]*entity.Company, error) {
-	query := `
-		SELECT id, company_name, company_address, drive, type_of_drive, follow_up, is_contacted, remarks, contact_details, hr1_details, hr2_details, package, assigned_officer, created_at, updated_at 
-		FROM companies`
+// customFieldsArg is the JSON stored in custom_fields.
+func customFieldsArg(values entity.CustomValues) (string, error) {
+	if values == nil {
+		values = entity.CustomValues{}
+	}
+	data, err := json.Marshal(values)
+	return string(data), err
+}
 
-	rows, err := r.db.Query(query)
+// tagsArg is the array stored in tags, which is never NULL.
+func tagsArg(tags []string) interface{} {
+	if tags == nil {
+		tags = []string{}
+	}
+	return pq.Array(tags)
+}
+
+// compensationArgs are the values of compensationColumns followed by
+// package_amount, the annual CTC used by the package filters and sort.
+func compensationArgs(c entity.Compensation) []interface{} {
+	var amount interface{}
+	if ctc, ok := company.AnnualCTC(c); ok {
+		amount = ctc
+	}
+	return []interface{}{c.Base, c.Variable, c.Stipend, c.Currency, c.Unit, c.Min, c.Max, c.NeedsReview, amount}
+}
+
+// setCompensation overwrites the structured package of a company.
+func setCompensation(tx *sql.Tx, id string, c entity.Compensation) error {
+	_, err := tx.Exec(`
+		UPDATE companies
+		SET package_base = $1,
+			package_variable = $2,
+			package_stipend = $3,
+			package_currency = $4,
+			package_unit = $5,
+			package_min = $6,
+			package_max = $7,
+			package_needs_review = $8,
+			package_amount = $9
+		WHERE id = $10`,
+		append(compensationArgs(c), id)...)
+	return err
+}
+
+func scanCompanyTemp(row scanner) (*entity.CompanyTemp, error) {
+	var companyTemp entity.CompanyTemp
+	var assignedOfficer []string
+	err := row.Scan(
+		&companyTemp.ID, &companyTemp.CompanyID, &companyTemp.CompanyName, &companyTemp.CompanyAddress, &companyTemp.Drive, &companyTemp.TypeOfDrive, &companyTemp.FollowUp, &companyTemp.IsContacted, &companyTemp.Remarks, &companyTemp.ContactDetails, &companyTemp.HR1Details, &companyTemp.HR2Details, &companyTemp.Package, pq.Array(&assignedOfficer), &companyTemp.Status, &companyTemp.BaseVersion, &companyTemp.Season, &companyTemp.CreatedBy, &companyTemp.CreatedAt, &companyTemp.UpdatedAt,
+	)
 	if err != nil {
 		return nil, err
 	}
+	companyTemp.AssignedOfficer = assignedOfficer
+	return &companyTemp, nil
+}
+
+func scanCompanies(rows *sql.Rows) ([]*entity.Company, error) {
 	defer rows.Close()
 
 	var companies []*entity.Company
 	for rows.Next() {
-		var company entity.Company
-		var assignedOfficer []string
-		err := rows.Scan(
-			&company.ID, &company.CompanyName, &company.CompanyAddress, &company.Drive, &company.TypeOfDrive, &company.FollowUp, &company.IsContacted, &company.Remarks, &company.ContactDetails, &company.HR1Details, &company.HR2Details, &company.Package, pq.Array(&assignedOfficer), &company.CreatedAt, &company.UpdatedAt,
-		)
+		company, err := scanCompany(rows)
 		if err != nil {
 			return nil, err
 		}
-		company.AssignedOfficer = assignedOfficer
-		companies = append(companies, &company)
+		companies = append(companies, company)
 	}
-	return companies, nil
+	return companies, rows.Err()
 }
 
-func (r *Repository) UpdateCompany(id, companyName, companyAddress, drive, typeOfDrive, followUp, isContacted, remarks, contactDetails, hr1Details, hr2Details, pkg string, assignedOfficer []string) (*entity.Company, error) {
-	query := `
-		UPDATE companies 
-		SET company_name = $1, 
-			company_address = $2, 
-			drive = $3, 
-			type_of_drive = $4, 
-			follow_up = $5, 
-			is_contacted = $6, 
-			remarks = $7, 
-			contact_details = $8, 
-			hr1_details = $9, 
-			hr2_details = $10, 
-			package = $11, 
-			assigned_officer = $12,
-			updated_at = CURRENT_TIMESTAMP
-		WHERE id = $13
-		RETURNING id, company_name, company_address, drive, type_of_drive, follow_up, is_contacted, remarks, contact_details, hr1_details, hr2_details, package, assigned_officer, created_at, updated_at`
+const insertCompany = `
+	INSERT INTO companies (company_name, company_address, drive, type_of_drive, follow_up, is_contacted, remarks, contact_details, hr1_details, hr2_details, package, pipeline_status, season, carried_from, custom_fields, tags, ` + compensationColumns + `, package_amount)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, NULLIF($14, '')::uuid, $15::jsonb, $16, $17, $18, $19, $20, $21, $22, $23, $24, $25)
+	RETURNING ` + companyColumns
 
-	var company entity.Company
-	var assignedOfficerResult []string
-	err := r.db.QueryRow(query,
-		companyName, companyAddress, drive, typeOfDrive, followUp, isContacted, remarks,
-		contactDetails, hr1Details, hr2Details, pkg, pq.Array(assignedOfficer), id).Scan(
-		&company.ID, &company.CompanyName, &company.CompanyAddress, &company.Drive,
-		&company.TypeOfDrive, &company.FollowUp, &company.IsContacted, &company.Remarks,
-		&company.ContactDetails, &company.HR1Details, &company.HR2Details, &company.Package,
-		pq.Array(&assignedOfficerResult), &company.CreatedAt, &company.UpdatedAt,
-	)
+// insertCompanyWith inserts a company of a season at a pipeline stage,
+// recording the stage as its first transition, and assigns its officers.
+// carriedFrom is the company it was carried forward from, or empty.
+func insertCompanyWith(tx *sql.Tx, args []interface{}, status, season, carriedFrom string, compensation entity.Compensation, customFields entity.CustomValues, tags []string, officers []string, assignedBy string) (*entity.Company, error) {
+	custom, err := customFieldsArg(customFields)
 	if err != nil {
 		return nil, err
 	}
-	company.AssignedOfficer = assignedOfficerResult
-	return &company, nil
+	args = append(append(args, status, season, carriedFrom, custom, tagsArg(tags)), compensationArgs(compensation)...)
+	created, err := scanCompany(tx.QueryRow(insertCompany, args...))
+	if err != nil {
+		return nil, err
+	}
+	if err := recordTransition(tx, created.ID, "", status, assignedBy); err != nil {
+		return nil, err
+	}
+	if len(officers) == 0 {
+		return created, nil
+	}
+	if err := setOfficers(tx, created.ID, officers, assignedBy); err != nil {
+		return nil, err
+	}
+	return scanCompany(tx.QueryRow(`SELECT `+companyColumns+` FROM companies WHERE id = $1`, created.ID))
 }
 
-func (r *Repository) ListCompaniesByUsername(username string) ([]*entity.Company, error) {
-	query := `
-		SELECT id, company_name, company_address, drive, type_of_drive, follow_up, is_contacted, remarks, contact_details, hr1_details, hr2_details, package, assigned_officer, created_at, updated_at 
-		FROM companies 
-		WHERE $1 = ANY(assigned_officer)`
+func (r *Repository) CreateCompany(companyName, companyAddress, drive, typeOfDrive, followUp, isContacted, remarks, contactDetails, hr1Details, hr2Details, pkg string, assignedOfficer []string, compensation entity.Compensation, customFields entity.CustomValues, tags []string, season string) (*entity.Company, error) {
+	args := []interface{}{companyName, companyAddress, drive, typeOfDrive, followUp, isContacted, remarks, contactDetails, hr1Details, hr2Details, pkg}
+	contacted, err := pgtypes.ParseBool(isContacted)
+	if err != nil {
+		return nil, err
+	}
 
-	rows, err := r.db.Query(query, username)
+	tx, err := r.db.Begin()
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
+	defer tx.Rollback()
 
-	var companies []*entity.Company
-	for rows.Next() {
-		var company entity.Company
-		var assignedOfficer []string
-		err := rows.Scan(
-			&company.ID, &company.CompanyName, &company.CompanyAddress, &company.Drive, &company.TypeOfDrive, &company.FollowUp, &company.IsContacted, &company.Remarks, &company.ContactDetails, &company.HR1Details, &company.HR2Details, &company.Package, pq.Array(&assignedOfficer), &company.CreatedAt, &company.UpdatedAt,
-		)
+	created, err := insertCompanyWith(tx, args, company.InitialStage(contacted), season, "", compensation, customFields, tags, assignedOfficer, "")
+	if err != nil {
+		return nil, err
+	}
+	if err := recordRevision(tx, created, entity.CompanyChange{Source: company.RevisionCreate}); err != nil {
+		return nil, err
+	}
+	return created, tx.Commit()
+}
+
+func (r *Repository) ImportCompanies(companies []entity.CompanySnapshot, season, importedBy string) ([]*entity.Company, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	change := entity.CompanyChange{ChangedBy: importedBy, Source: company.RevisionImport}
+	created := make([]*entity.Company, 0, len(companies))
+	for _, c := range companies {
+		args := []interface{}{c.CompanyName, c.CompanyAddress, c.Drive, c.TypeOfDrive, c.FollowUp, c.IsContacted, c.Remarks, c.ContactDetails, c.HR1Details, c.HR2Details, c.Package}
+		status := c.PipelineStatus
+		if status == "" {
+			status = company.InitialStage(c.IsContacted)
+		}
+		imported, err := insertCompanyWith(tx, args, status, season, "", c.Compensation, c.CustomFields, c.Tags, c.AssignedOfficer, importedBy)
 		if err != nil {
 			return nil, err
 		}
-		company.AssignedOfficer = assignedOfficer
-		companies = append(companies, &company)
+		if err := recordRevision(tx, imported, change); err != nil {
+			return nil, err
+		}
+		created = append(created, imported)
 	}
-	return companies, nil
+	return created, tx.Commit()
 }
 
-func (r *Repository) CreateCompanyTemp(companyId, companyName, companyAddress, drive, typeOfDrive, followUp, isContacted, remarks, contactDetails, hr1Details, hr2Details, pkg string, assignedOfficer []string, createdBy string) (*entity.CompanyTemp, error) {
+// GetCompany does not find companies in the trash.
+func (r *Repository) GetCompany(id string) (*entity.Company, error) {
+	found, err := scanCompany(r.db.QueryRow(`SELECT `+companyColumns+` FROM companies WHERE id = $1 AND deleted_at IS NULL`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, company.ErrNotFound
+	}
+	return found, err
+}
+
+// DeleteCompany keeps the original deletion time of a company already in the
+// trash, so deleting it again does not postpone its purge.
+func (r *Repository) DeleteCompany(id string, deletedBy string) error {
+	query := `UPDATE companies SET deleted_at = CURRENT_TIMESTAMP, deleted_by = $2 WHERE id = $1 AND deleted_at IS NULL`
+	_, err := r.db.Exec(query, id, deletedBy)
+	return err
+}
+
+func (r *Repository) RestoreCompany(id string) (*entity.Company, error) {
+	restored, err := scanCompany(r.db.QueryRow(`
+		UPDATE companies SET deleted_at = NULL, deleted_by = ''
+		WHERE id = $1 AND deleted_at IS NOT NULL
+		RETURNING `+companyColumns, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, company.ErrNotFound
+	}
+	return restored, err
+}
+
+// SetCompanyArchived leaves version and updated_at alone: archiving is not an
+// edit of the company's details.
+func (r *Repository) SetCompanyArchived(id string, archived bool, by string) (*entity.Company, error) {
+	updated, err := scanCompany(r.db.QueryRow(`
+		UPDATE companies
+		SET archived_at = CASE WHEN $2 THEN COALESCE(archived_at, CURRENT_TIMESTAMP) END,
+			archived_by = CASE WHEN $2 THEN $3 ELSE '' END
+		WHERE id = $1 AND deleted_at IS NULL
+		RETURNING `+companyColumns, id, archived, by))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, company.ErrNotFound
+	}
+	return updated, err
+}
+
+func (r *Repository) ListDeletedCompanies() ([]*entity.Company, error) {
+	rows, err := r.db.Query(`SELECT ` + companyColumns + ` FROM companies WHERE deleted_at IS NOT NULL ORDER BY deleted_at DESC, id`)
+	if err != nil {
+		return nil, err
+	}
+	return scanCompanies(rows)
+}
+
+// PurgeCompanies removes pending proposals first, since companies_temp
+// references companies without ON DELETE; everything else cascades.
+func (r *Repository) PurgeCompanies(deletedBefore time.Time) (int, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM companies_temp WHERE company_id IN (SELECT id FROM companies WHERE deleted_at < $1)`, deletedBefore); err != nil {
+		return 0, err
+	}
+	result, err := tx.Exec(`DELETE FROM companies WHERE deleted_at < $1`, deletedBefore)
+	if err != nil {
+		return 0, err
+	}
+	purged, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	return int(purged), tx.Commit()
+}
+
+func (r *Repository) ListCompanies() ([]*entity.Company, error) {
+	rows, err := r.db.Query(`SELECT ` + companyColumns + ` FROM companies WHERE deleted_at IS NULL`)
+	if err != nil {
+		return nil, err
+	}
+	return scanCompanies(rows)
+}
+
+// UpdateCompany applies the set fields of update only if the company is
+// still at the given version, and bumps the version on success. Unset fields
+// are passed as NULL and keep their current value. The new version is
+// recorded in the company's history in the same transaction.
+func (r *Repository) UpdateCompany(id string, version int, update entity.CompanyUpdate, change entity.CompanyChange) (*entity.Company, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	updated, err := r.updateCompany(tx, id, version, update, change)
+	if err != nil {
+		return nil, err
+	}
+	return updated, tx.Commit()
+}
+
+// updateCompany is UpdateCompany within tx. A change of pipeline status is
+// recorded as a transition.
+func (r *Repository) updateCompany(tx *sql.Tx, id string, version int, update entity.CompanyUpdate, change entity.CompanyChange) (*entity.Company, error) {
+	var previousStatus string
+	if update.PipelineStatus != nil {
+		err := tx.QueryRow(`SELECT pipeline_status FROM companies WHERE id = $1 AND deleted_at IS NULL FOR UPDATE`, id).Scan(&previousStatus)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, company.ErrNotFound
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	query := `
-		INSERT INTO companies_temp (company_id, company_name, company_address, drive, type_of_drive, follow_up, is_contacted, remarks, contact_details, hr1_details, hr2_details, package, assigned_officer, created_by) 
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
-		RETURNING id, company_id, company_name, company_address, drive, type_of_drive, follow_up, is_contacted, remarks, contact_details, hr1_details, hr2_details, package, assigned_officer, status, created_by, created_at, updated_at`
+		UPDATE companies
+		SET company_name = COALESCE($1, company_name),
+			company_address = COALESCE($2, company_address),
+			drive = COALESCE($3, drive),
+			type_of_drive = COALESCE($4, type_of_drive),
+			follow_up = COALESCE($5, follow_up),
+			is_contacted = COALESCE($6, is_contacted),
+			remarks = COALESCE($7, remarks),
+			contact_details = COALESCE($8, contact_details),
+			hr1_details = COALESCE($9, hr1_details),
+			hr2_details = COALESCE($10, hr2_details),
+			package = COALESCE($11, package),
+			package_base = CASE WHEN $14 THEN $15::numeric ELSE package_base END,
+			package_variable = CASE WHEN $14 THEN $16::numeric ELSE package_variable END,
+			package_stipend = CASE WHEN $14 THEN $17::numeric ELSE package_stipend END,
+			package_currency = CASE WHEN $14 THEN $18 ELSE package_currency END,
+			package_unit = CASE WHEN $14 THEN $19 ELSE package_unit END,
+			package_min = CASE WHEN $14 THEN $20::numeric ELSE package_min END,
+			package_max = CASE WHEN $14 THEN $21::numeric ELSE package_max END,
+			package_needs_review = CASE WHEN $14 THEN $22::boolean ELSE package_needs_review END,
+			package_amount = CASE WHEN $14 THEN $23::numeric ELSE package_amount END,
+			pipeline_status = COALESCE($24, pipeline_status),
+			custom_fields = COALESCE($25::jsonb, custom_fields),
+			tags = COALESCE($26, tags),
+			version = version + 1,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE id = $12 AND version = $13 AND deleted_at IS NULL
+		RETURNING ` + companyColumns
 
-	var companyTemp entity.CompanyTemp
-	var assignedOfficerResult []string
-	err := r.db.QueryRow(query, companyId, companyName, companyAddress, drive, typeOfDrive, followUp, isContacted, remarks, contactDetails, hr1Details, hr2Details, pkg, pq.Array(assignedOfficer), createdBy).Scan(
-		&companyTemp.ID, &companyTemp.CompanyID, &companyTemp.CompanyName, &companyTemp.CompanyAddress, &companyTemp.Drive, &companyTemp.TypeOfDrive, &companyTemp.FollowUp, &companyTemp.IsContacted, &companyTemp.Remarks, &companyTemp.ContactDetails, &companyTemp.HR1Details, &companyTemp.HR2Details, &companyTemp.Package, pq.Array(&assignedOfficerResult), &companyTemp.Status, &companyTemp.CreatedBy, &companyTemp.CreatedAt, &companyTemp.UpdatedAt,
-	)
+	var compensation entity.Compensation
+	if update.Compensation != nil {
+		compensation = *update.Compensation
+	}
+	args := []interface{}{
+		update.CompanyName, update.CompanyAddress, update.Drive, update.TypeOfDrive, update.FollowUp, update.IsContacted, update.Remarks,
+		update.ContactDetails, update.HR1Details, update.HR2Details, update.Package, id, version, update.Compensation != nil,
+	}
+
+	args = append(append(args, compensationArgs(compensation)...), update.PipelineStatus)
+	var customFields, tags interface{}
+	if update.CustomFields != nil {
+		custom, err := customFieldsArg(*update.CustomFields)
+		if err != nil {
+			return nil, err
+		}
+		customFields = custom
+	}
+	if update.Tags != nil {
+		tags = tagsArg(*update.Tags)
+	}
+	args = append(args, customFields, tags)
+
+	updated, err := scanCompany(tx.QueryRow(query, args...))
+	if errors.Is(err, sql.ErrNoRows) {
+		// Either the company is gone or someone else updated it first.
+		if _, getErr := r.GetCompany(id); getErr != nil {
+			return nil, getErr
+		}
+		return nil, company.ErrVersionMismatch
+	}
 	if err != nil {
 		return nil, err
 	}
-	companyTemp.AssignedOfficer = assignedOfficerResult
-	return &companyTemp, nil
+	if update.PipelineStatus != nil && *update.PipelineStatus != previousStatus {
+		if err := recordTransition(tx, id, previousStatus, *update.PipelineStatus, change.ChangedBy); err != nil {
+			return nil, err
+		}
+	}
+	if update.AssignedOfficer != nil {
+		if err := setOfficers(tx, id, *update.AssignedOfficer, change.ChangedBy); err != nil {
+			return nil, err
+		}
+		if updated, err = scanCompany(tx.QueryRow(`SELECT `+companyColumns+` FROM companies WHERE id = $1`, id)); err != nil {
+			return nil, err
+		}
+	}
+	if err := recordRevision(tx, updated, change); err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+func (r *Repository) ListCompaniesByUsername(username string) ([]*entity.Company, error) {
+	query := `
+		SELECT ` + companyColumns + `
+		FROM companies
+		WHERE ` + officerFilter("$1") + ` AND archived_at IS NULL AND deleted_at IS NULL`
+
+	rows, err := r.db.Query(query, username)
+	if err != nil {
+		return nil, err
+	}
+	return scanCompanies(rows)
+}
+
+// CreateCompanyTemp records the company's current version alongside the
+// proposal so that approval can detect edits made in the meantime, and
+// files the proposal under the company's season.
+func (r *Repository) CreateCompanyTemp(companyId, companyName, companyAddress, drive, typeOfDrive, followUp, isContacted, remarks, contactDetails, hr1Details, hr2Details, pkg string, assignedOfficer []string, createdBy string) (*entity.CompanyTemp, error) {
+	query := `
+		INSERT INTO companies_temp (company_id, company_name, company_address, drive, type_of_drive, follow_up, is_contacted, remarks, contact_details, hr1_details, hr2_details, package, assigned_officer, created_by, base_version, season)
+		SELECT $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, version, season FROM companies WHERE id = $1 AND deleted_at IS NULL
+		RETURNING ` + companyTempColumns
+
+	return scanCompanyTemp(r.db.QueryRow(query, companyId, companyName, companyAddress, drive, typeOfDrive, followUp, isContacted, remarks, contactDetails, hr1Details, hr2Details, pkg, pq.Array(assignedOfficer), createdBy))
 }
 
-func (r *Repository) ListCompanyTemps() ([]*entity.CompanyTemp, error) {
+func (r *Repository) ListCompanyTemps(season string) ([]*entity.CompanyTemp, error) {
+	f := &listFilter{}
+	if season != "" {
+		f.add("season = ?", season)
+	}
 	query := `
-		SELECT id, company_id, company_name, company_address, drive, type_of_drive, follow_up, is_contacted, remarks, contact_details, hr1_details, hr2_details, package, assigned_officer, status, created_by, created_at, updated_at 
-		FROM companies_temp
+		SELECT ` + companyTempColumns + `
+		FROM companies_temp` + f.where() + `
 		ORDER BY created_at DESC`
 
-	rows, err := r.db.Query(query)
+	rows, err := r.db.Query(query, f.args...)
 	if err != nil {
 		return nil, err
 	}
@@ -162,27 +473,39 @@ func (r *Repository) ListCompanyTemps() ([]*entity.CompanyTemp, error) {
 
 	var companyTemps []*entity.CompanyTemp
 	for rows.Next() {
-		var companyTemp entity.CompanyTemp
-		var assignedOfficer []string
-		err := rows.Scan(
-			&companyTemp.ID, &companyTemp.CompanyID, &companyTemp.CompanyName, &companyTemp.CompanyAddress, &companyTemp.Drive, &companyTemp.TypeOfDrive, &companyTemp.FollowUp, &companyTemp.IsContacted, &companyTemp.Remarks, &companyTemp.ContactDetails, &companyTemp.HR1Details, &companyTemp.HR2Details, &companyTemp.Package, pq.Array(&assignedOfficer), &companyTemp.Status, &companyTemp.CreatedBy, &companyTemp.CreatedAt, &companyTemp.UpdatedAt,
-		)
+		companyTemp, err := scanCompanyTemp(rows)
 		if err != nil {
 			return nil, err
 		}
-		companyTemp.AssignedOfficer = assignedOfficer
-		companyTemps = append(companyTemps, &companyTemp)
+		companyTemps = append(companyTemps, companyTemp)
 	}
-	return companyTemps, nil
+	return companyTemps, rows.Err()
 }
 
-func (r *Repository) UpdateCompanyTempStatus(id string, status string) error {
-	query := `UPDATE companies_temp SET status = $1, updated_at = CURRENT_TIMESTAMP WHERE id = $2`
-	_, err := r.db.Exec(query, status, id)
-	return err
+func (r *Repository) GetCompanyTemp(id string) (*entity.CompanyTemp, error) {
+	companyTemp, err := scanCompanyTemp(r.db.QueryRow(`SELECT `+companyTempColumns+` FROM companies_temp WHERE id = $1`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, company.ErrNotFound
+	}
+	return companyTemp, err
 }
 
-func (r *Repository) ApproveCompanyTemp(id string) error {
+func (r *Repository) UpdateCompanyTempStatus(id string, from, to string) error {
+	query := `UPDATE companies_temp SET status = $1, updated_at = CURRENT_TIMESTAMP WHERE id = $2 AND status = $3`
+	result, err := r.db.Exec(query, to, id, from)
+	if err != nil {
+		return err
+	}
+	if n, err := result.RowsAffected(); err != nil || n == 0 {
+		if err == nil {
+			err = company.ErrNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+func (r *Repository) ApproveCompanyTemp(id string, approvedBy string) error {
 	// Start a transaction
 	tx, err := r.db.Begin()
 	if err != nil {
@@ -190,50 +513,90 @@ func (r *Repository) ApproveCompanyTemp(id string) error {
 	}
 	defer tx.Rollback()
 
-	// Get the company temp data
-	var companyTemp entity.CompanyTemp
-	var assignedOfficer []string
-	err = tx.QueryRow(`
-		SELECT company_id, company_name, company_address, drive, type_of_drive, follow_up, is_contacted, remarks, contact_details, hr1_details, hr2_details, package, assigned_officer 
-		FROM companies_temp 
-		WHERE id = $1`, id).Scan(
-		&companyTemp.CompanyID, &companyTemp.CompanyName, &companyTemp.CompanyAddress, &companyTemp.Drive,
-		&companyTemp.TypeOfDrive, &companyTemp.FollowUp, &companyTemp.IsContacted, &companyTemp.Remarks,
-		&companyTemp.ContactDetails, &companyTemp.HR1Details, &companyTemp.HR2Details, &companyTemp.Package,
-		pq.Array(&assignedOfficer))
+	// Get the company temp data, locking it so that only one approval or
+	// status change can move it on from pending.
+	companyTemp, err := scanCompanyTemp(tx.QueryRow(`SELECT `+companyTempColumns+` FROM companies_temp WHERE id = $1 FOR UPDATE`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return company.ErrNotFound
+	}
 	if err != nil {
 		return err
 	}
-	companyTemp.AssignedOfficer = assignedOfficer
+	if companyTemp.Status != company.ProposalPending {
+		return company.NewProposalTransitionError(companyTemp.Status, company.ProposalApproved)
+	}
 
-	// Update the company with the temp data
+	// Lock the company row and make sure nobody changed it since the
+	// proposal was made. Proposals created before versioning have no base
+	// version and are applied as before.
+	var currentVersion int
+	var currentPackage string
+	err = tx.QueryRow(`SELECT version, COALESCE(package, '') FROM companies WHERE id = $1 AND deleted_at IS NULL FOR UPDATE`, companyTemp.CompanyID).Scan(&currentVersion, &currentPackage)
+	if errors.Is(err, sql.ErrNoRows) {
+		return company.ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if companyTemp.BaseVersion != 0 && companyTemp.BaseVersion != currentVersion {
+		return company.ErrStaleProposal
+	}
+
+	// Update the company with the temp data. The pipeline status, and so
+	// is_contacted, only changes through its own transitions.
 	_, err = tx.Exec(`
-		UPDATE companies 
+		UPDATE companies
 		SET company_name = $1,
 			company_address = $2,
 			drive = $3,
 			type_of_drive = $4,
 			follow_up = $5,
-			is_contacted = $6,
-			remarks = $7,
-			contact_details = $8,
-			hr1_details = $9,
-			hr2_details = $10,
-			package = $11,
-			assigned_officer = $12,
+			remarks = $6,
+			contact_details = $7,
+			hr1_details = $8,
+			hr2_details = $9,
+			package = $10,
+			version = version + 1,
 			updated_at = CURRENT_TIMESTAMP
-		WHERE id = $13`,
+		WHERE id = $11`,
 		companyTemp.CompanyName, companyTemp.CompanyAddress, companyTemp.Drive,
-		companyTemp.TypeOfDrive, companyTemp.FollowUp, companyTemp.IsContacted,
+		companyTemp.TypeOfDrive, companyTemp.FollowUp,
 		companyTemp.Remarks, companyTemp.ContactDetails, companyTemp.HR1Details,
-		companyTemp.HR2Details, companyTemp.Package, pq.Array(companyTemp.AssignedOfficer),
-		companyTemp.CompanyID)
+		companyTemp.HR2Details, companyTemp.Package, companyTemp.CompanyID)
+	if err != nil {
+		return err
+	}
+	if err := setOfficers(tx, companyTemp.CompanyID, companyTemp.AssignedOfficer, approvedBy); err != nil {
+		return err
+	}
+
+	// Proposals carry only the package text; keep the structured package
+	// unless the text changed.
+	if companyTemp.Package != currentPackage {
+		if err := setCompensation(tx, companyTemp.CompanyID, company.ParseCompensation(companyTemp.Package)); err != nil {
+			return err
+		}
+	}
+
+	approved, err := scanCompany(tx.QueryRow(`SELECT `+companyColumns+` FROM companies WHERE id = $1`, companyTemp.CompanyID))
 	if err != nil {
 		return err
 	}
+	change := entity.CompanyChange{ChangedBy: approvedBy, Source: company.RevisionProposal, ProposalID: companyTemp.ID}
+	if err := recordRevision(tx, approved, change); err != nil {
+		return err
+	}
 
-	// Delete the temp record
-	_, err = tx.Exec("DELETE FROM companies_temp WHERE id = $1", id)
+	// Keep the proposal as approved. The company's other pending proposals
+	// were made against the version this one replaced.
+	_, err = tx.Exec(`UPDATE companies_temp SET status = $1, updated_at = CURRENT_TIMESTAMP WHERE id = $2`, company.ProposalApproved, id)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(`
+		UPDATE companies_temp SET status = $1, updated_at = CURRENT_TIMESTAMP
+		WHERE company_id = $2 AND status = $3 AND id <> $4`,
+		company.ProposalSuperseded, companyTemp.CompanyID, company.ProposalPending, id)
 	if err != nil {
 		return err
 	}
@@ -242,20 +605,26 @@ func (r *Repository) ApproveCompanyTemp(id string) error {
 	return tx.Commit()
 }
 
-func (r *Repository) CreateEvent(date, eventType, title, description, createdBy string) (*entity.Event, error) {
-	var event entity.Event
+func (r *Repository) CreateEvent(date, eventType, title, description, companyID, createdBy string) (*entity.Event, error) {
+	parsed, err := pgtypes.ParseTimestamp(date)
+	if err != nil {
+		return nil, err
+	}
 
-	err := r.db.QueryRow(`
-		INSERT INTO events (id, date, type, title, description, created_by, created_at)
-		VALUES (uuid_generate_v4(), $1, $2, $3, $4, $5, CURRENT_TIMESTAMP)
-		RETURNING id, date, type, title, description, created_by, created_at`,
-		date, eventType, title, description, createdBy,
+	var event entity.Event
+	err = r.db.QueryRow(`
+		INSERT INTO events (id, date, type, title, description, company_id, created_by, created_at, season)
+		VALUES (uuid_generate_v4(), $1, $2, $3, $4, NULLIF($5, '')::uuid, $6, CURRENT_TIMESTAMP, $7)
+		RETURNING id, date, type, title, description, COALESCE(company_id::text, ''), season, created_by, created_at`,
+		parsed, eventType, title, description, companyID, createdBy, company.SeasonAt(parsed),
 	).Scan(
 		&event.ID,
 		&event.Date,
 		&event.Type,
 		&event.Title,
 		&event.Description,
+		&event.CompanyID,
+		&event.Season,
 		&event.CreatedBy,
 		&event.CreatedAt,
 	)
@@ -266,13 +635,17 @@ func (r *Repository) CreateEvent(date, eventType, title, description, createdBy
 	return &event, nil
 }
 
-func (r *Repository) ListEvents() ([]*entity.Event, error) {
+func (r *Repository) ListEvents(season string) ([]*entity.Event, error) {
+	f := &listFilter{}
+	if season != "" {
+		f.add("season = ?", season)
+	}
 	query := `
-		SELECT id, date, type, title, description, created_by, created_at 
-		FROM events
+		SELECT id, date, type, title, description, COALESCE(company_id::text, ''), season, created_by, created_at
+		FROM events` + f.where() + `
 		ORDER BY date DESC`
 
-	rows, err := r.db.Query(query)
+	rows, err := r.db.Query(query, f.args...)
 	if err != nil {
 		return nil, err
 	}
@@ -287,6 +660,8 @@ func (r *Repository) ListEvents() ([]*entity.Event, error) {
 			&event.Type,
 			&event.Title,
 			&event.Description,
+			&event.CompanyID,
+			&event.Season,
 			&event.CreatedBy,
 			&event.CreatedAt,
 		)
@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"backend/companyd/repository/contract"
+	"backend/companyd/usecase/company"
+	"database/sql"
+	"os"
+	"testing"
+
+	_ "github.com/lib/pq"
+)
+
+// openTestDB connects to the database named by TEST_POSTGRES_DSN, applies
+// init.sql, empties the company tables and adds contract.Officers as users.
+// The test is skipped when the variable is not set.
+func openTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	dsn := os.Getenv("TEST_POSTGRES_DSN")
+	if dsn == "" {
+		t.Skip("TEST_POSTGRES_DSN not set")
+	}
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	schema, err := os.ReadFile("../../init.sql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(string(schema)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(`TRUNCATE companies_temp, companies, events CASCADE`); err != nil {
+		t.Fatal(err)
+	}
+	for _, officer := range contract.Officers {
+		_, err := db.Exec(`
+			INSERT INTO users (username, email, role, password) VALUES ($1, $2, 'Officer', 'password')
+			ON CONFLICT (username) DO NOTHING`, officer, officer+"@example.com")
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	return db
+}
+
+func TestRepositoryContract(t *testing.T) {
+	contract.Run(t, func(t *testing.T) company.Repository {
+		return NewCompanyRepository(openTestDB(t))
+	})
+}
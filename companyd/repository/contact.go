@@ -0,0 +1,135 @@
+package repository
+
+import (
+	"backend/companyd/entity"
+	"backend/companyd/usecase/company"
+	"database/sql"
+	"errors"
+
+	"github.com/lib/pq"
+)
+
+const contactColumns = `id, company_id, name, designation, email, phone, linkedin, is_primary, notes, created_at, updated_at`
+
+func scanContact(row scanner) (*entity.Contact, error) {
+	var contact entity.Contact
+	err := row.Scan(&contact.ID, &contact.CompanyID, &contact.Name, &contact.Designation, &contact.Email, &contact.Phone, &contact.LinkedIn, &contact.IsPrimary, &contact.Notes, &contact.CreatedAt, &contact.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &contact, nil
+}
+
+// demotePrimary clears the primary flag on the company's other contacts so
+// that idx_contacts_primary allows the new one.
+func demotePrimary(tx *sql.Tx, companyID, keepID string) error {
+	_, err := tx.Exec(`UPDATE contacts SET is_primary = false, updated_at = CURRENT_TIMESTAMP WHERE company_id = $1 AND is_primary AND id::text <> $2`, companyID, keepID)
+	return err
+}
+
+func (r *Repository) CreateContact(contact entity.Contact) (*entity.Contact, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if contact.IsPrimary {
+		if err := demotePrimary(tx, contact.CompanyID, ""); err != nil {
+			return nil, err
+		}
+	}
+	created, err := insertContact(tx, contact)
+	if err != nil {
+		return nil, err
+	}
+	return created, tx.Commit()
+}
+
+func insertContact(tx *sql.Tx, contact entity.Contact) (*entity.Contact, error) {
+	return scanContact(tx.QueryRow(`
+		INSERT INTO contacts (company_id, name, designation, email, phone, linkedin, is_primary, notes)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING `+contactColumns,
+		contact.CompanyID, contact.Name, contact.Designation, contact.Email, contact.Phone, contact.LinkedIn, contact.IsPrimary, contact.Notes))
+}
+
+func (r *Repository) GetContact(id string) (*entity.Contact, error) {
+	found, err := scanContact(r.db.QueryRow(`SELECT `+contactColumns+` FROM contacts WHERE id = $1`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, company.ErrNotFound
+	}
+	return found, err
+}
+
+func (r *Repository) ListContacts(filter company.ContactFilter) ([]*entity.Contact, error) {
+	f := &listFilter{}
+	if len(filter.CompanyIDs) > 0 {
+		f.add("company_id = ANY(?::uuid[])", pq.Array(filter.CompanyIDs))
+	}
+	if filter.Email != "" {
+		f.add("lower(email) = lower(?)", filter.Email)
+	}
+
+	rows, err := r.db.Query(`SELECT `+contactColumns+` FROM contacts`+f.where()+` ORDER BY is_primary DESC, created_at, id`, f.args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	contacts := []*entity.Contact{}
+	for rows.Next() {
+		contact, err := scanContact(rows)
+		if err != nil {
+			return nil, err
+		}
+		contacts = append(contacts, contact)
+	}
+	return contacts, rows.Err()
+}
+
+func (r *Repository) UpdateContact(id string, contact entity.Contact) (*entity.Contact, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if contact.IsPrimary {
+		if err := demotePrimary(tx, contact.CompanyID, id); err != nil {
+			return nil, err
+		}
+	}
+	updated, err := scanContact(tx.QueryRow(`
+		UPDATE contacts
+		SET company_id = $1,
+			name = $2,
+			designation = $3,
+			email = $4,
+			phone = $5,
+			linkedin = $6,
+			is_primary = $7,
+			notes = $8,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE id = $9
+		RETURNING `+contactColumns,
+		contact.CompanyID, contact.Name, contact.Designation, contact.Email, contact.Phone, contact.LinkedIn, contact.IsPrimary, contact.Notes, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, company.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return updated, tx.Commit()
+}
+
+func (r *Repository) DeleteContact(id string) error {
+	result, err := r.db.Exec(`DELETE FROM contacts WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return company.ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+package contract
+
+import (
+	"backend/companyd/entity"
+	"backend/companyd/usecase/company"
+	"errors"
+	"testing"
+	"time"
+)
+
+func mustCreateAttachment(t *testing.T, repo company.Repository, companyID, filename string) *entity.Attachment {
+	t.Helper()
+	created, err := repo.CreateAttachment(entity.Attachment{
+		CompanyID:   companyID,
+		Filename:    filename,
+		ContentType: "application/pdf",
+		Size:        1024,
+		Checksum:    "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08",
+		StorageKey:  companyID + "/" + filename,
+		UploadedBy:  "alice",
+	})
+	if err != nil {
+		t.Fatalf("CreateAttachment(%q): %v", filename, err)
+	}
+	return created
+}
+
+func testAttachmentCRUD(t *testing.T, repo company.Repository) {
+	infosys := mustCreate(t, repo, "Infosys")
+	tcs := mustCreate(t, repo, "TCS")
+	jd := mustCreateAttachment(t, repo, infosys.ID, "jd.pdf")
+	time.Sleep(2 * time.Millisecond)
+	mou := mustCreateAttachment(t, repo, infosys.ID, "mou.pdf")
+	mustCreateAttachment(t, repo, tcs.ID, "offer.pdf")
+
+	if jd.ID == "" || jd.CompanyID != infosys.ID || jd.Filename != "jd.pdf" || jd.Size != 1024 || jd.StorageKey != infosys.ID+"/jd.pdf" || jd.UploadedBy != "alice" || jd.CreatedAt == "" {
+		t.Errorf("created attachment = %+v", jd)
+	}
+	found, err := repo.GetAttachment(jd.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *found != *jd {
+		t.Errorf("GetAttachment = %+v, want %+v", found, jd)
+	}
+
+	attachments, err := repo.ListAttachments(infosys.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(attachments) != 2 || attachments[0].ID != mou.ID || attachments[1].ID != jd.ID {
+		t.Errorf("attachments = %+v, want mou.pdf then jd.pdf", attachments)
+	}
+
+	if err := repo.DeleteAttachment(jd.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.GetAttachment(jd.ID); !errors.Is(err, company.ErrNotFound) {
+		t.Errorf("deleted attachment: err = %v, want ErrNotFound", err)
+	}
+	if err := repo.DeleteAttachment(jd.ID); !errors.Is(err, company.ErrNotFound) {
+		t.Errorf("second delete: err = %v, want ErrNotFound", err)
+	}
+	attachments, err = repo.ListAttachments(infosys.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(attachments) != 1 || attachments[0].ID != mou.ID {
+		t.Errorf("attachments after delete = %+v, want only mou.pdf", attachments)
+	}
+}
+
+func testAttachmentsFollowCompany(t *testing.T, repo company.Repository) {
+	survivor := mustCreate(t, repo, "Infosys", "alice")
+	duplicate := mustCreate(t, repo, "Infosys Ltd", "bob")
+	trashed := mustCreate(t, repo, "TCS")
+	moved := mustCreateAttachment(t, repo, duplicate.ID, "jd.pdf")
+	removed := mustCreateAttachment(t, repo, trashed.ID, "mou.pdf")
+
+	mustMerge(t, repo, survivor, duplicate)
+	found, err := repo.GetAttachment(moved.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if found.CompanyID != survivor.ID {
+		t.Errorf("attachment after merging belongs to %s, want the survivor %s", found.CompanyID, survivor.ID)
+	}
+
+	if err := repo.DeleteCompany(trashed.ID, "alice"); err != nil {
+		t.Fatal(err)
+	}
+	listed, err := repo.ListTrashedAttachments(hoursFromNow(-1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(listed) != 0 {
+		t.Errorf("attachments of companies trashed over an hour ago = %+v, want none", listed)
+	}
+	listed, err = repo.ListTrashedAttachments(hoursFromNow(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(listed) != 1 || listed[0].ID != removed.ID || listed[0].StorageKey != removed.StorageKey {
+		t.Errorf("trashed attachments = %+v, want mou.pdf", listed)
+	}
+
+	if _, err := repo.PurgeCompanies(hoursFromNow(1)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.GetAttachment(removed.ID); !errors.Is(err, company.ErrNotFound) {
+		t.Errorf("attachment of purged company: err = %v, want ErrNotFound", err)
+	}
+	if _, err := repo.GetAttachment(moved.ID); err != nil {
+		t.Errorf("attachment of survivor after purge: %v", err)
+	}
+}
@@ -0,0 +1,123 @@
+package contract
+
+import (
+	"backend/companyd/entity"
+	"backend/companyd/usecase/company"
+	"reflect"
+	"testing"
+)
+
+func testCompensationRoundTrip(t *testing.T, repo company.Repository) {
+	structured := entity.Compensation{Base: ptr(8.0), Variable: ptr(1.5), Stipend: ptr(25000.0), Currency: "INR", Unit: company.UnitLPA}
+	created, err := repo.CreateCompany("Infosys", "", "2026", "on-campus", "", "false", "", "", "", "", "8 LPA + 1.5 LPA variable", nil, structured, nil, nil, testSeason)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(created.Compensation, structured) {
+		t.Errorf("created compensation = %+v, want %+v", created.Compensation, structured)
+	}
+
+	found, err := repo.GetCompany(created.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(found.Compensation, structured) {
+		t.Errorf("stored compensation = %+v, want %+v", found.Compensation, structured)
+	}
+	page := mustQuery(t, repo, company.ListQuery{PackageMin: ptr(9.5), PackageMax: ptr(9.5)})
+	if got := names(page.Companies); len(got) != 1 {
+		t.Errorf("package_amount should be base plus variable, 9.5; got %v", got)
+	}
+
+	// Updating other fields keeps the package.
+	remarks := "visited"
+	updated, err := repo.UpdateCompany(created.ID, created.Version, entity.CompanyUpdate{Remarks: &remarks}, edit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(updated.Compensation, structured) {
+		t.Errorf("compensation after unrelated update = %+v", updated.Compensation)
+	}
+
+	ranged := entity.Compensation{Currency: "INR", Unit: company.UnitLPA, Min: ptr(6.0), Max: ptr(9.0)}
+	updated, err = repo.UpdateCompany(created.ID, updated.Version, entity.CompanyUpdate{Compensation: &ranged}, edit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(updated.Compensation, ranged) {
+		t.Errorf("updated compensation = %+v, want %+v", updated.Compensation, ranged)
+	}
+	page = mustQuery(t, repo, company.ListQuery{PackageMin: ptr(7.5), PackageMax: ptr(7.5)})
+	if got := names(page.Companies); len(got) != 1 {
+		t.Errorf("package_amount should be the middle of the range, 7.5; got %v", got)
+	}
+}
+
+func testQueryCompaniesPackageCurrency(t *testing.T, repo company.Repository) {
+	for _, c := range []struct{ name, pkg string }{
+		{"Infosys", "10 LPA"},
+		{"Stripe", "USD 10 LPA"},
+		{"Revolut", "GBP 9 LPA"},
+	} {
+		if _, err := repo.CreateCompany(c.name, "", "2026", "on-campus", "", "false", "", "", "", "", c.pkg, nil, company.ParseCompensation(c.pkg), nil, nil, testSeason); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name  string
+		query company.ListQuery
+		want  []string
+	}{
+		{"default currency", company.ListQuery{PackageMin: ptr(8.0), Sort: "company_name"}, []string{"Infosys"}},
+		{"named currency", company.ListQuery{PackageMax: ptr(12.0), PackageCurrency: "USD"}, []string{"Stripe"}},
+		{"out of range", company.ListQuery{PackageMin: ptr(9.5), PackageCurrency: "GBP"}, nil},
+		{"currency without bounds", company.ListQuery{PackageCurrency: "USD", Sort: "company_name"}, []string{"Infosys", "Revolut", "Stripe"}},
+	}
+	for _, tt := range tests {
+		page := mustQuery(t, repo, tt.query)
+		if got := names(page.Companies); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func testApproveCompanyTempCompensation(t *testing.T, repo company.Repository) {
+	structured := entity.Compensation{Base: ptr(10.0), Currency: "INR", Unit: company.UnitLPA}
+	created, err := repo.CreateCompany("Infosys", "", "2026", "on-campus", "", "false", "", "", "", "", "10 LPA", nil, structured, nil, nil, testSeason)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A proposal that keeps the package text keeps the structured package.
+	temp, err := repo.CreateCompanyTemp(created.ID, "Infosys Ltd", "", "2026", "on-campus", "", "false", "", "", "", "", "10 LPA", nil, "officer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.ApproveCompanyTemp(temp.ID, "manager"); err != nil {
+		t.Fatal(err)
+	}
+	found, err := repo.GetCompany(created.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(found.Compensation, structured) {
+		t.Errorf("compensation after approval = %+v, want %+v", found.Compensation, structured)
+	}
+
+	// A proposal that changes it is parsed.
+	temp, err = repo.CreateCompanyTemp(created.ID, "Infosys Ltd", "", "2026", "on-campus", "", "false", "", "", "", "", "Competitive", nil, "officer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.ApproveCompanyTemp(temp.ID, "manager"); err != nil {
+		t.Fatal(err)
+	}
+	found, err = repo.GetCompany(created.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if found.Package != "Competitive" || !found.Compensation.NeedsReview || found.Compensation.Base != nil {
+		t.Errorf("unexpected company after approving new package text: %q %+v", found.Package, found.Compensation)
+	}
+}
@@ -0,0 +1,159 @@
+package contract
+
+import (
+	"backend/companyd/entity"
+	"backend/companyd/usecase/company"
+	"errors"
+	"testing"
+)
+
+const missingID = "00000000-0000-0000-0000-000000000000"
+
+func mustCreateContact(t *testing.T, repo company.Repository, companyID, name, email string, primary bool) *entity.Contact {
+	t.Helper()
+	created, err := repo.CreateContact(entity.Contact{CompanyID: companyID, Name: name, Designation: "HR", Email: email, IsPrimary: primary})
+	if err != nil {
+		t.Fatalf("CreateContact(%q): %v", name, err)
+	}
+	return created
+}
+
+func mustListContacts(t *testing.T, repo company.Repository, filter company.ContactFilter) []*entity.Contact {
+	t.Helper()
+	contacts, err := repo.ListContacts(filter)
+	if err != nil {
+		t.Fatalf("ListContacts(%+v): %v", filter, err)
+	}
+	return contacts
+}
+
+func contactNames(contacts []*entity.Contact) []string {
+	out := make([]string, len(contacts))
+	for i, c := range contacts {
+		out[i] = c.Name
+	}
+	return out
+}
+
+func testContactCRUD(t *testing.T, repo company.Repository) {
+	infosys := mustCreate(t, repo, "Infosys")
+
+	created, err := repo.CreateContact(entity.Contact{
+		CompanyID:   infosys.ID,
+		Name:        "Priya Sharma",
+		Designation: "Talent Acquisition",
+		Email:       "priya@infosys.com",
+		Phone:       "+91 98765 43210",
+		LinkedIn:    "https://www.linkedin.com/in/priya",
+		IsPrimary:   true,
+		Notes:       "prefers email",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created.ID == "" || created.CreatedAt == "" || created.UpdatedAt == "" {
+		t.Errorf("expected generated id and timestamps, got %+v", created)
+	}
+
+	found, err := repo.GetContact(created.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *found != *created {
+		t.Errorf("GetContact = %+v, want %+v", found, created)
+	}
+
+	change := *created
+	change.Designation = "HR Manager"
+	change.Phone = ""
+	updated, err := repo.UpdateContact(created.ID, change)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.ID != created.ID || updated.Designation != "HR Manager" || updated.Phone != "" || updated.CreatedAt != created.CreatedAt {
+		t.Errorf("unexpected update result: %+v", updated)
+	}
+
+	if err := repo.DeleteContact(created.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.GetContact(created.ID); !errors.Is(err, company.ErrNotFound) {
+		t.Errorf("GetContact after delete: err = %v, want ErrNotFound", err)
+	}
+	if _, err := repo.UpdateContact(missingID, change); !errors.Is(err, company.ErrNotFound) {
+		t.Errorf("UpdateContact(missing): err = %v, want ErrNotFound", err)
+	}
+	if err := repo.DeleteContact(missingID); !errors.Is(err, company.ErrNotFound) {
+		t.Errorf("DeleteContact(missing): err = %v, want ErrNotFound", err)
+	}
+}
+
+func testContactSinglePrimary(t *testing.T, repo company.Repository) {
+	infosys := mustCreate(t, repo, "Infosys")
+	tcs := mustCreate(t, repo, "TCS")
+	first := mustCreateContact(t, repo, infosys.ID, "Asha", "asha@infosys.com", true)
+	mustCreateContact(t, repo, infosys.ID, "Bala", "bala@infosys.com", false)
+	other := mustCreateContact(t, repo, tcs.ID, "Chitra", "chitra@tcs.com", true)
+	third := mustCreateContact(t, repo, infosys.ID, "Deepa", "deepa@infosys.com", true)
+
+	contacts := mustListContacts(t, repo, company.ContactFilter{CompanyIDs: []string{infosys.ID}})
+	if got := contactNames(contacts); len(got) != 3 || got[0] != "Deepa" || got[1] != "Asha" || got[2] != "Bala" {
+		t.Errorf("contacts = %v, want the new primary first, then by creation", got)
+	}
+	for _, c := range contacts {
+		if c.IsPrimary != (c.ID == third.ID) {
+			t.Errorf("%s: IsPrimary = %v", c.Name, c.IsPrimary)
+		}
+	}
+	if found, err := repo.GetContact(other.ID); err != nil || !found.IsPrimary {
+		t.Errorf("another company's primary contact was demoted: %+v, %v", found, err)
+	}
+
+	promote := *first
+	promote.IsPrimary = true
+	if _, err := repo.UpdateContact(first.ID, promote); err != nil {
+		t.Fatal(err)
+	}
+	if found, err := repo.GetContact(third.ID); err != nil || found.IsPrimary {
+		t.Errorf("previous primary not demoted: %+v, %v", found, err)
+	}
+}
+
+func testListContactsFilters(t *testing.T, repo company.Repository) {
+	infosys := mustCreate(t, repo, "Infosys")
+	tcs := mustCreate(t, repo, "TCS")
+	wipro := mustCreate(t, repo, "Wipro")
+	mustCreateContact(t, repo, infosys.ID, "Priya at Infosys", "priya@example.com", false)
+	mustCreateContact(t, repo, tcs.ID, "Ravi", "ravi@tcs.com", false)
+	mustCreateContact(t, repo, wipro.ID, "Priya at Wipro", "Priya@Example.com", false)
+
+	if got := mustListContacts(t, repo, company.ContactFilter{}); len(got) != 3 {
+		t.Errorf("unfiltered list has %d contacts, want 3", len(got))
+	}
+	got := contactNames(mustListContacts(t, repo, company.ContactFilter{Email: "PRIYA@example.com"}))
+	if len(got) != 2 || got[0] != "Priya at Infosys" || got[1] != "Priya at Wipro" {
+		t.Errorf("email filter = %v, want the same person at both companies", got)
+	}
+	got = contactNames(mustListContacts(t, repo, company.ContactFilter{CompanyIDs: []string{infosys.ID, tcs.ID}}))
+	if len(got) != 2 || got[0] != "Priya at Infosys" || got[1] != "Ravi" {
+		t.Errorf("company filter = %v", got)
+	}
+	if got := mustListContacts(t, repo, company.ContactFilter{CompanyIDs: []string{tcs.ID}, Email: "priya@example.com"}); len(got) != 0 {
+		t.Errorf("combined filters = %v, want none", contactNames(got))
+	}
+}
+
+func testDeleteCompanyDeletesContacts(t *testing.T, repo company.Repository) {
+	infosys := mustCreate(t, repo, "Infosys")
+	tcs := mustCreate(t, repo, "TCS")
+	removed := mustCreateContact(t, repo, infosys.ID, "Asha", "asha@infosys.com", true)
+	mustCreateContact(t, repo, tcs.ID, "Ravi", "ravi@tcs.com", true)
+
+	mustPurge(t, repo, infosys.ID)
+	if _, err := repo.GetContact(removed.ID); !errors.Is(err, company.ErrNotFound) {
+		t.Errorf("contact of deleted company: err = %v, want ErrNotFound", err)
+	}
+	if got := contactNames(mustListContacts(t, repo, company.ContactFilter{})); len(got) != 1 || got[0] != "Ravi" {
+		t.Errorf("remaining contacts = %v", got)
+	}
+}
@@ -0,0 +1,819 @@
+// Package contract holds the behaviour every company.Repository
+// implementation must share. Each backend runs it from its own tests.
+package contract
+
+import (
+	"backend/companyd/entity"
+	"backend/companyd/usecase/company"
+	"errors"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Officers are the usernames the tests assign to companies. newRepo must
+// return a repository in which each of them is a user with the Officer role.
+var Officers = []string{"alice", "bob", "bobby", "carol", "dave", "officer"}
+
+// testSeason is the season the tests create companies in.
+const testSeason = "2026-27"
+
+// Run exercises repo-independent semantics against a fresh, empty repository
+// returned by newRepo for every subtest.
+func Run(t *testing.T, newRepo func(t *testing.T) company.Repository) {
+	tests := []struct {
+		name string
+		fn   func(t *testing.T, repo company.Repository)
+	}{
+		{"CreateAndListCompanies", testCreateAndListCompanies},
+		{"CreateCompanyRejectsInvalidBool", testCreateCompanyRejectsInvalidBool},
+		{"ListCompaniesByUsername", testListCompaniesByUsername},
+		{"CompanyOfficers", testCompanyOfficers},
+		{"UnknownOfficers", testUnknownOfficers},
+		{"OfficerUsernames", testOfficerUsernames},
+		{"QueryCompaniesFilters", testQueryCompaniesFilters},
+		{"QueryCompaniesPagination", testQueryCompaniesPagination},
+		{"SearchCompanies", testSearchCompanies},
+		{"SearchCompaniesFuzzy", testSearchCompaniesFuzzy},
+		{"CompensationRoundTrip", testCompensationRoundTrip},
+		{"QueryCompaniesPackageCurrency", testQueryCompaniesPackageCurrency},
+		{"UpdateCompany", testUpdateCompany},
+		{"UpdateCompanyClearsFields", testUpdateCompanyClearsFields},
+		{"UpdateMissingCompany", testUpdateMissingCompany},
+		{"UpdateStaleCompany", testUpdateStaleCompany},
+		{"GetCompany", testGetCompany},
+		{"DeleteCompany", testDeleteCompany},
+		{"DeleteReferencedCompany", testDeleteReferencedCompany},
+		{"RestoreCompany", testRestoreCompany},
+		{"ArchiveCompany", testArchiveCompany},
+		{"PurgeCompanies", testPurgeCompanies},
+		{"CreateCompanyTempUnknownCompany", testCreateCompanyTempUnknownCompany},
+		{"ListCompanyTempsNewestFirst", testListCompanyTempsNewestFirst},
+		{"UpdateCompanyTempStatus", testUpdateCompanyTempStatus},
+		{"ApproveCompanyTemp", testApproveCompanyTemp},
+		{"ApproveMissingCompanyTemp", testApproveMissingCompanyTemp},
+		{"ApproveStaleCompanyTemp", testApproveStaleCompanyTemp},
+		{"ApproveSupersedesPendingProposals", testApproveSupersedesPendingProposals},
+		{"ApproveOnlyPendingCompanyTemp", testApproveOnlyPendingCompanyTemp},
+		{"ApproveCompanyTempCompensation", testApproveCompanyTempCompensation},
+		{"ContactCRUD", testContactCRUD},
+		{"ContactSinglePrimary", testContactSinglePrimary},
+		{"ListContactsFilters", testListContactsFilters},
+		{"DeleteCompanyDeletesContacts", testDeleteCompanyDeletesContacts},
+		{"DriveCRUD", testDriveCRUD},
+		{"ListDrivesFilters", testListDrivesFilters},
+		{"DrivesFollowCompany", testDrivesFollowCompany},
+		{"FollowUpCRUD", testFollowUpCRUD},
+		{"ListFollowUpsFilters", testListFollowUpsFilters},
+		{"CreateReminders", testCreateReminders},
+		{"DeleteCompanyDeletesFollowUps", testDeleteCompanyDeletesFollowUps},
+		{"InteractionTimeline", testInteractionTimeline},
+		{"InteractionUpdatesCompany", testInteractionUpdatesCompany},
+		{"QueryCompaniesByLastInteraction", testQueryCompaniesByLastInteraction},
+		{"DeleteCompanyDeletesInteractions", testDeleteCompanyDeletesInteractions},
+		{"CompanyHistory", testCompanyHistory},
+		{"DeleteCompanyDeletesHistory", testDeleteCompanyDeletesHistory},
+		{"MergeCompanies", testMergeCompanies},
+		{"MergeCompaniesErrors", testMergeCompaniesErrors},
+		{"ImportCompanies", testImportCompanies},
+		{"PipelineStatus", testPipelineStatus},
+		{"DeleteCompanyDeletesTransitions", testDeleteCompanyDeletesTransitions},
+		{"EventsOrderedByDateDesc", testEventsOrderedByDateDesc},
+		{"CreateEventRejectsInvalidDate", testCreateEventRejectsInvalidDate},
+		{"EventCompany", testEventCompany},
+		{"EventMovesWithMerge", testEventMovesWithMerge},
+		{"ActiveSeason", testActiveSeason},
+		{"RolloverCompanies", testRolloverCompanies},
+		{"SeasonFilters", testSeasonFilters},
+		{"CustomFieldCRUD", testCustomFieldCRUD},
+		{"CustomValuesRoundTrip", testCustomValuesRoundTrip},
+		{"CustomFieldFilters", testCustomFieldFilters},
+		{"DeleteCustomFieldRemovesValues", testDeleteCustomFieldRemovesValues},
+		{"AttachmentCRUD", testAttachmentCRUD},
+		{"AttachmentsFollowCompany", testAttachmentsFollowCompany},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fn(t, newRepo(t))
+		})
+	}
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func mustCreate(t *testing.T, repo company.Repository, name string, officers ...string) *entity.Company {
+	t.Helper()
+	created, err := repo.CreateCompany(name, "Chennai", "2026", "on-campus", "call back", "true", "remarks", "contact", "hr1", "hr2", "10 LPA", officers, company.ParseCompensation("10 LPA"), nil, nil, testSeason)
+	if err != nil {
+		t.Fatalf("CreateCompany(%q): %v", name, err)
+	}
+	return created
+}
+
+func mustCreateTemp(t *testing.T, repo company.Repository, companyID, name string) *entity.CompanyTemp {
+	t.Helper()
+	temp, err := repo.CreateCompanyTemp(companyID, name, "Pune", "2027", "off-campus", "", "false", "", "", "", "", "12 LPA", []string{"officer"}, "officer")
+	if err != nil {
+		t.Fatalf("CreateCompanyTemp(%q): %v", name, err)
+	}
+	return temp
+}
+
+func testCreateAndListCompanies(t *testing.T, repo company.Repository) {
+	companies, err := repo.ListCompanies()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(companies) != 0 {
+		t.Fatalf("expected empty repository, got %d companies", len(companies))
+	}
+
+	created := mustCreate(t, repo, "Infosys", "alice", "bob")
+	if created.ID == "" || created.CreatedAt == "" || created.UpdatedAt == "" {
+		t.Errorf("expected generated id and timestamps, got %+v", created)
+	}
+	if created.CompanyName != "Infosys" || created.CompanyAddress != "Chennai" || !created.IsContacted || created.Package != "10 LPA" {
+		t.Errorf("unexpected company: %+v", created)
+	}
+	if len(created.AssignedOfficer) != 2 || created.AssignedOfficer[0] != "alice" || created.AssignedOfficer[1] != "bob" {
+		t.Errorf("AssignedOfficer = %v, want [alice bob]", created.AssignedOfficer)
+	}
+	if _, err := time.Parse(time.RFC3339Nano, created.CreatedAt); err != nil {
+		t.Errorf("CreatedAt %q is not RFC 3339: %v", created.CreatedAt, err)
+	}
+
+	empty := mustCreate(t, repo, "TCS")
+	if empty.AssignedOfficer == nil || len(empty.AssignedOfficer) != 0 {
+		t.Errorf("AssignedOfficer = %#v, want empty non-nil slice", empty.AssignedOfficer)
+	}
+
+	companies, err = repo.ListCompanies()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(companies) != 2 {
+		t.Fatalf("expected 2 companies, got %d", len(companies))
+	}
+}
+
+func testCreateCompanyRejectsInvalidBool(t *testing.T, repo company.Repository) {
+	if _, err := repo.CreateCompany("Infosys", "", "", "", "", "maybe", "", "", "", "", "", nil, entity.Compensation{}, nil, nil, testSeason); err == nil {
+		t.Error("expected error for invalid is_contacted value")
+	}
+}
+
+func testListCompaniesByUsername(t *testing.T, repo company.Repository) {
+	mustCreate(t, repo, "Infosys", "alice", "bob")
+	mustCreate(t, repo, "TCS", "bob")
+	mustCreate(t, repo, "Wipro")
+	// Membership is exact, not a substring match.
+	mustCreate(t, repo, "HCL", "bobby")
+
+	for username, want := range map[string]int{"alice": 1, "bob": 2, "bobby": 1, "carol": 0} {
+		companies, err := repo.ListCompaniesByUsername(username)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(companies) != want {
+			t.Errorf("ListCompaniesByUsername(%q) returned %d companies, want %d", username, len(companies), want)
+		}
+	}
+}
+
+func mustQuery(t *testing.T, repo company.Repository, q company.ListQuery) *company.CompanyPage {
+	t.Helper()
+	page, err := repo.QueryCompanies(q)
+	if err != nil {
+		t.Fatalf("QueryCompanies(%+v): %v", q, err)
+	}
+	return page
+}
+
+func names(companies []*entity.Company) []string {
+	var out []string
+	for _, c := range companies {
+		out = append(out, c.CompanyName)
+	}
+	return out
+}
+
+func testQueryCompaniesFilters(t *testing.T, repo company.Repository) {
+	seed := []struct {
+		name, drive, typeOfDrive, contacted, pkg string
+		officers                                 []string
+	}{
+		{"Infosys", "2026", "on-campus", "true", "10 LPA", []string{"alice"}},
+		{"TCS", "2026", "off-campus", "false", "7.5 LPA", []string{"bob"}},
+		{"Wipro", "2027", "on-campus", "false", "12,00,000 INR", []string{"alice", "bob"}},
+		{"HCL", "2027", "pool", "true", "Competitive", nil},
+	}
+	for _, c := range seed {
+		if _, err := repo.CreateCompany(c.name, "", c.drive, c.typeOfDrive, "", c.contacted, "", "", "", "", c.pkg, c.officers, company.ParseCompensation(c.pkg), nil, nil, testSeason); err != nil {
+			t.Fatal(err)
+		}
+	}
+	hourAgo, inAnHour := time.Now().Add(-time.Hour), time.Now().Add(time.Hour)
+
+	tests := []struct {
+		name  string
+		query company.ListQuery
+		want  []string
+	}{
+		{"all", company.ListQuery{Sort: "company_name"}, []string{"HCL", "Infosys", "TCS", "Wipro"}},
+		{"drive", company.ListQuery{Drive: "2026", Sort: "company_name"}, []string{"Infosys", "TCS"}},
+		{"type of drive", company.ListQuery{TypeOfDrive: "on-campus", Sort: "company_name"}, []string{"Infosys", "Wipro"}},
+		{"contacted", company.ListQuery{IsContacted: ptr(false), Sort: "company_name"}, []string{"TCS", "Wipro"}},
+		{"officer", company.ListQuery{Officer: "bob", Sort: "company_name"}, []string{"TCS", "Wipro"}},
+		{"package range", company.ListQuery{PackageMin: ptr(8.0), PackageMax: ptr(11.0), Sort: "company_name"}, []string{"Infosys"}},
+		{"package above 10 LPA", company.ListQuery{PackageMin: ptr(10.5), Sort: "company_name"}, []string{"Wipro"}},
+		{"package needs review", company.ListQuery{PackageNeedsReview: ptr(true)}, []string{"HCL"}},
+		{"package min", company.ListQuery{PackageMin: ptr(7.5), Sort: "package"}, []string{"TCS", "Infosys", "Wipro"}},
+		{"sort by package desc", company.ListQuery{Sort: "package", Desc: true}, []string{"Wipro", "Infosys", "TCS", "HCL"}},
+		{"created window", company.ListQuery{CreatedAfter: &hourAgo, CreatedBefore: &inAnHour, Sort: "company_name"}, []string{"HCL", "Infosys", "TCS", "Wipro"}},
+		{"created in future", company.ListQuery{CreatedAfter: &inAnHour}, nil},
+		{"updated before", company.ListQuery{UpdatedBefore: &hourAgo}, nil},
+		{"combined", company.ListQuery{Drive: "2027", IsContacted: ptr(true)}, []string{"HCL"}},
+	}
+	for _, tt := range tests {
+		page := mustQuery(t, repo, tt.query)
+		got := names(page.Companies)
+		if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+		if page.Total != len(tt.want) || page.NextCursor != "" {
+			t.Errorf("%s: total = %d, next cursor = %q; want %d and none", tt.name, page.Total, page.NextCursor, len(tt.want))
+		}
+	}
+}
+
+func testQueryCompaniesPagination(t *testing.T, repo company.Repository) {
+	// Duplicate names exercise the ID tie-breaker.
+	for _, name := range []string{"E", "B", "D", "A", "C", "B", "A"} {
+		mustCreate(t, repo, name)
+		time.Sleep(time.Millisecond)
+	}
+
+	for _, q := range []company.ListQuery{
+		{Sort: "company_name", Limit: 3},
+		{Sort: "company_name", Desc: true, Limit: 2},
+		{Sort: "created_at", Desc: true, Limit: 3},
+		{Sort: "is_contacted", Limit: 4},
+	} {
+		all := mustQuery(t, repo, company.ListQuery{Sort: q.Sort, Desc: q.Desc})
+		var paged []*entity.Company
+		for pages := 0; ; pages++ {
+			if pages > len(all.Companies) {
+				t.Fatalf("%+v: pagination did not terminate", q)
+			}
+			page := mustQuery(t, repo, q)
+			if page.Total != 7 {
+				t.Errorf("%+v: total = %d, want 7", q, page.Total)
+			}
+			if len(page.Companies) > q.Limit {
+				t.Fatalf("%+v: page has %d companies, limit is %d", q, len(page.Companies), q.Limit)
+			}
+			paged = append(paged, page.Companies...)
+			if page.NextCursor == "" {
+				break
+			}
+			cursor, err := company.DecodeCursor(page.NextCursor)
+			if err != nil {
+				t.Fatal(err)
+			}
+			q.After = cursor
+		}
+
+		if len(paged) != len(all.Companies) {
+			t.Fatalf("%+v: paged through %d companies, want %d", q, len(paged), len(all.Companies))
+		}
+		for i := range paged {
+			if paged[i].ID != all.Companies[i].ID {
+				t.Errorf("%+v: position %d is %s, want %s", q, i, paged[i].ID, all.Companies[i].ID)
+			}
+		}
+	}
+
+	if got := names(mustQuery(t, repo, company.ListQuery{Sort: "company_name", Limit: 3}).Companies); strings.Join(got, "") != "AAB" {
+		t.Errorf("first page = %v, want [A A B]", got)
+	}
+	if got := names(mustQuery(t, repo, company.ListQuery{}).Companies); strings.Join(got, "") != "EBDACBA" {
+		t.Errorf("default order = %v, want creation order", got)
+	}
+}
+
+func seedSearch(t *testing.T, repo company.Repository) {
+	t.Helper()
+	seed := []struct {
+		name, address, remarks, hr1 string
+		officers                    []string
+	}{
+		{"Infosys", "Pune", "Hiring for cloud roles this season", "Priya, talent acquisition", []string{"alice"}},
+		{"Cloudera", "Bangalore", "Data platform internships", "", []string{"bob"}},
+		{"Wipro", "Pune", "Support engineers", "Rahul", []string{"bob"}},
+		{"Tata & Sons", "Mumbai", "Cloud migration practice", "", []string{"alice"}},
+	}
+	for _, c := range seed {
+		if _, err := repo.CreateCompany(c.name, c.address, "2026", "on-campus", "", "false", c.remarks, "", c.hr1, "", "", c.officers, entity.Compensation{}, nil, nil, testSeason); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func mustSearch(t *testing.T, repo company.Repository, q company.SearchQuery) []*entity.CompanySearchResult {
+	t.Helper()
+	if q.Limit == 0 {
+		q.Limit = 20
+	}
+	results, err := repo.SearchCompanies(q)
+	if err != nil {
+		t.Fatalf("SearchCompanies(%+v): %v", q, err)
+	}
+	return results
+}
+
+func resultNames(results []*entity.CompanySearchResult) []string {
+	var out []string
+	for _, r := range results {
+		out = append(out, r.Company.CompanyName)
+	}
+	sort.Strings(out)
+	return out
+}
+
+func testSearchCompanies(t *testing.T, repo company.Repository) {
+	seedSearch(t, repo)
+
+	tests := []struct {
+		query   company.SearchQuery
+		want    []string
+		topHit  string
+		snippet string
+	}{
+		// Every term must match, across different fields.
+		{company.SearchQuery{Text: "cloud pune"}, []string{"Infosys"}, "Infosys", "<mark>cloud</mark>"},
+		// Prefix matching.
+		{company.SearchQuery{Text: "infos"}, []string{"Infosys"}, "Infosys", "<mark>Infosys</mark>"},
+		{company.SearchQuery{Text: "priya"}, []string{"Infosys"}, "Infosys", "<mark>Priya"},
+		// A match in the name outranks a match in the remarks.
+		{company.SearchQuery{Text: "cloud"}, []string{"Cloudera", "Infosys", "Tata & Sons"}, "Cloudera", ""},
+		// Officers only see their own companies.
+		{company.SearchQuery{Text: "cloud", Officer: "alice"}, []string{"Infosys", "Tata & Sons"}, "", ""},
+		{company.SearchQuery{Text: "cloud", Officer: "carol"}, nil, "", ""},
+		{company.SearchQuery{Text: "pune", Limit: 1}, nil, "", ""},
+	}
+	for _, tt := range tests {
+		results := mustSearch(t, repo, tt.query)
+		if tt.query.Limit == 1 {
+			if len(results) != 1 {
+				t.Errorf("%+v: got %d results, want 1", tt.query, len(results))
+			}
+			continue
+		}
+		if got := resultNames(results); strings.Join(got, ",") != strings.Join(tt.want, ",") {
+			t.Errorf("%+v: got %v, want %v", tt.query, got, tt.want)
+			continue
+		}
+		for _, r := range results {
+			if r.Fuzzy || r.Rank <= 0 {
+				t.Errorf("%+v: unexpected result %+v", tt.query, r)
+			}
+		}
+		if tt.topHit != "" && results[0].Company.CompanyName != tt.topHit {
+			t.Errorf("%+v: top hit is %s, want %s", tt.query, results[0].Company.CompanyName, tt.topHit)
+		}
+		if tt.snippet != "" && !strings.Contains(results[0].Snippet, tt.snippet) {
+			t.Errorf("%+v: snippet %q does not contain %q", tt.query, results[0].Snippet, tt.snippet)
+		}
+	}
+
+	// Snippets are safe to render as HTML.
+	results := mustSearch(t, repo, company.SearchQuery{Text: "tata"})
+	if len(results) != 1 || !strings.Contains(results[0].Snippet, "<mark>") || strings.Contains(results[0].Snippet, "& ") {
+		t.Errorf("snippet not escaped: %+v", results)
+	}
+}
+
+func testSearchCompaniesFuzzy(t *testing.T, repo company.Repository) {
+	seedSearch(t, repo)
+
+	results := mustSearch(t, repo, company.SearchQuery{Text: "infosis"})
+	if len(results) == 0 || results[0].Company.CompanyName != "Infosys" || !results[0].Fuzzy {
+		t.Fatalf("expected a fuzzy match for Infosys, got %+v", results)
+	}
+
+	if results := mustSearch(t, repo, company.SearchQuery{Text: "infosis", Officer: "bob"}); len(results) != 0 {
+		t.Errorf("fuzzy search ignored visibility: %+v", results)
+	}
+	if results := mustSearch(t, repo, company.SearchQuery{Text: "zzzzqqq"}); len(results) != 0 {
+		t.Errorf("expected no results, got %+v", results)
+	}
+}
+
+func testUpdateCompany(t *testing.T, repo company.Repository) {
+	created := mustCreate(t, repo, "Infosys", "alice")
+
+	updated, err := repo.UpdateCompany(created.ID, created.Version, entity.CompanyUpdate{
+		CompanyName:     ptr("Infosys Ltd"),
+		CompanyAddress:  ptr("Bangalore"),
+		IsContacted:     ptr(false),
+		Package:         ptr("15 LPA"),
+		AssignedOfficer: &[]string{"bob", "carol"},
+	}, edit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.ID != created.ID || updated.CompanyName != "Infosys Ltd" || updated.IsContacted || updated.Package != "15 LPA" {
+		t.Errorf("unexpected company after update: %+v", updated)
+	}
+	if len(updated.AssignedOfficer) != 2 || updated.AssignedOfficer[0] != "bob" {
+		t.Errorf("AssignedOfficer = %v, want [bob carol]", updated.AssignedOfficer)
+	}
+	if updated.CreatedAt != created.CreatedAt {
+		t.Errorf("CreatedAt changed from %s to %s", created.CreatedAt, updated.CreatedAt)
+	}
+	if created.Version != 1 || updated.Version != created.Version+1 {
+		t.Errorf("Version went from %d to %d, want 1 to 2", created.Version, updated.Version)
+	}
+	// Fields left nil keep their previous value.
+	if updated.Drive != created.Drive || updated.Remarks != created.Remarks || updated.HR1Details != created.HR1Details {
+		t.Errorf("unset fields changed: %+v", updated)
+	}
+}
+
+func testUpdateCompanyClearsFields(t *testing.T, repo company.Repository) {
+	created := mustCreate(t, repo, "Infosys", "alice")
+
+	updated, err := repo.UpdateCompany(created.ID, created.Version, entity.CompanyUpdate{
+		Remarks:         ptr(""),
+		AssignedOfficer: &[]string{},
+	}, edit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.Remarks != "" || updated.CompanyName != "Infosys" {
+		t.Errorf("unexpected company after update: %+v", updated)
+	}
+	if updated.AssignedOfficer == nil || len(updated.AssignedOfficer) != 0 {
+		t.Errorf("AssignedOfficer = %#v, want empty non-nil slice", updated.AssignedOfficer)
+	}
+}
+
+func testUpdateMissingCompany(t *testing.T, repo company.Repository) {
+	_, err := repo.UpdateCompany("00000000-0000-0000-0000-000000000000", 1, entity.CompanyUpdate{CompanyName: ptr("x")}, edit)
+	if !errors.Is(err, company.ErrNotFound) {
+		t.Errorf("err = %v, want ErrNotFound", err)
+	}
+}
+
+func testUpdateStaleCompany(t *testing.T, repo company.Repository) {
+	created := mustCreate(t, repo, "Infosys")
+	if _, err := repo.UpdateCompany(created.ID, created.Version, entity.CompanyUpdate{CompanyName: ptr("first")}, edit); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := repo.UpdateCompany(created.ID, created.Version, entity.CompanyUpdate{CompanyName: ptr("second")}, edit)
+	if !errors.Is(err, company.ErrVersionMismatch) {
+		t.Fatalf("err = %v, want ErrVersionMismatch", err)
+	}
+	current, err := repo.GetCompany(created.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if current.CompanyName != "first" || current.Version != 2 {
+		t.Errorf("stale update was applied: %+v", current)
+	}
+}
+
+func testGetCompany(t *testing.T, repo company.Repository) {
+	created := mustCreate(t, repo, "Infosys", "alice")
+
+	found, err := repo.GetCompany(created.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if found.ID != created.ID || found.CompanyName != "Infosys" || found.Version != 1 || len(found.AssignedOfficer) != 1 {
+		t.Errorf("unexpected company: %+v", found)
+	}
+
+	if _, err := repo.GetCompany("00000000-0000-0000-0000-000000000000"); !errors.Is(err, company.ErrNotFound) {
+		t.Errorf("err = %v, want ErrNotFound", err)
+	}
+}
+
+func testDeleteCompany(t *testing.T, repo company.Repository) {
+	created := mustCreate(t, repo, "Infosys")
+	kept := mustCreate(t, repo, "TCS")
+
+	if err := repo.DeleteCompany(created.ID, "alice"); err != nil {
+		t.Fatal(err)
+	}
+	companies, err := repo.ListCompanies()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(companies) != 1 || companies[0].ID != kept.ID {
+		t.Errorf("expected only %s to remain, got %+v", kept.ID, companies)
+	}
+	if _, err := repo.GetCompany(created.ID); !errors.Is(err, company.ErrNotFound) {
+		t.Errorf("GetCompany of deleted company: err = %v, want ErrNotFound", err)
+	}
+
+	if err := repo.DeleteCompany("00000000-0000-0000-0000-000000000000", "alice"); err != nil {
+		t.Errorf("deleting a missing company should be a no-op, got %v", err)
+	}
+}
+
+func testDeleteReferencedCompany(t *testing.T, repo company.Repository) {
+	created := mustCreate(t, repo, "Infosys")
+	mustCreateTemp(t, repo, created.ID, "Infosys Ltd")
+
+	if err := repo.DeleteCompany(created.ID, "alice"); err != nil {
+		t.Fatalf("deleting a company with pending changes: %v", err)
+	}
+	if purged, err := repo.PurgeCompanies(hoursFromNow(1)); err != nil || purged != 1 {
+		t.Fatalf("PurgeCompanies = %d, %v, want 1", purged, err)
+	}
+	temps, err := repo.ListCompanyTemps("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(temps) != 0 {
+		t.Errorf("purged company still has %d pending changes", len(temps))
+	}
+}
+
+func testCreateCompanyTempUnknownCompany(t *testing.T, repo company.Repository) {
+	_, err := repo.CreateCompanyTemp("00000000-0000-0000-0000-000000000000", "x", "", "", "", "", "false", "", "", "", "", "", nil, "officer")
+	if err == nil {
+		t.Error("expected error proposing a change to a missing company")
+	}
+}
+
+func testListCompanyTempsNewestFirst(t *testing.T, repo company.Repository) {
+	created := mustCreate(t, repo, "Infosys")
+	first := mustCreateTemp(t, repo, created.ID, "first")
+	time.Sleep(2 * time.Millisecond)
+	second := mustCreateTemp(t, repo, created.ID, "second")
+
+	if first.Status != "pending" || first.CompanyID != created.ID || first.CreatedBy != "officer" {
+		t.Errorf("unexpected company temp: %+v", first)
+	}
+
+	temps, err := repo.ListCompanyTemps("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(temps) != 2 {
+		t.Fatalf("expected 2 temps, got %d", len(temps))
+	}
+	if temps[0].ID != second.ID || temps[1].ID != first.ID {
+		t.Errorf("expected newest first, got %s then %s", temps[0].CompanyName, temps[1].CompanyName)
+	}
+}
+
+func testUpdateCompanyTempStatus(t *testing.T, repo company.Repository) {
+	created := mustCreate(t, repo, "Infosys")
+	temp := mustCreateTemp(t, repo, created.ID, "Infosys Ltd")
+
+	if err := repo.UpdateCompanyTempStatus(temp.ID, company.ProposalPending, company.ProposalRejected); err != nil {
+		t.Fatal(err)
+	}
+	temps, err := repo.ListCompanyTemps("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(temps) != 1 || temps[0].Status != "rejected" {
+		t.Errorf("expected status rejected, got %+v", temps)
+	}
+
+	// The update only applies to a proposal still at the expected status.
+	if err := repo.UpdateCompanyTempStatus(temp.ID, company.ProposalPending, company.ProposalWithdrawn); !errors.Is(err, company.ErrNotFound) {
+		t.Errorf("update of a rejected proposal: err = %v, want ErrNotFound", err)
+	}
+	if err := repo.UpdateCompanyTempStatus("00000000-0000-0000-0000-000000000000", company.ProposalPending, company.ProposalRejected); !errors.Is(err, company.ErrNotFound) {
+		t.Errorf("update of a missing proposal: err = %v, want ErrNotFound", err)
+	}
+	found, err := repo.GetCompanyTemp(temp.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if found.Status != company.ProposalRejected || found.CompanyName != "Infosys Ltd" {
+		t.Errorf("GetCompanyTemp = %+v, want the rejected proposal", found)
+	}
+	if _, err := repo.GetCompanyTemp("00000000-0000-0000-0000-000000000000"); !errors.Is(err, company.ErrNotFound) {
+		t.Errorf("missing proposal: err = %v, want ErrNotFound", err)
+	}
+}
+
+func testApproveCompanyTemp(t *testing.T, repo company.Repository) {
+	created := mustCreate(t, repo, "Infosys", "alice")
+	temp := mustCreateTemp(t, repo, created.ID, "Infosys Ltd")
+	if temp.BaseVersion != created.Version {
+		t.Errorf("BaseVersion = %d, want %d", temp.BaseVersion, created.Version)
+	}
+
+	if err := repo.ApproveCompanyTemp(temp.ID, "manager"); err != nil {
+		t.Fatal(err)
+	}
+
+	companies, err := repo.ListCompanies()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(companies) != 1 {
+		t.Fatalf("expected 1 company, got %d", len(companies))
+	}
+	got := companies[0]
+	if got.CompanyName != "Infosys Ltd" || got.CompanyAddress != "Pune" || got.Package != "12 LPA" {
+		t.Errorf("proposal not applied: %+v", got)
+	}
+	// Proposals do not move a company through the pipeline.
+	if !got.IsContacted || got.PipelineStatus != company.StageContacted {
+		t.Errorf("proposal changed the pipeline status: IsContacted %v, PipelineStatus %q", got.IsContacted, got.PipelineStatus)
+	}
+	if len(got.AssignedOfficer) != 1 || got.AssignedOfficer[0] != "officer" {
+		t.Errorf("AssignedOfficer = %v, want [officer]", got.AssignedOfficer)
+	}
+	if got.Version != created.Version+1 {
+		t.Errorf("Version = %d, want %d", got.Version, created.Version+1)
+	}
+
+	approved, err := repo.GetCompanyTemp(temp.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if approved.Status != company.ProposalApproved {
+		t.Errorf("approved proposal has status %q", approved.Status)
+	}
+}
+
+func testApproveSupersedesPendingProposals(t *testing.T, repo company.Repository) {
+	infosys := mustCreate(t, repo, "Infosys")
+	tcs := mustCreate(t, repo, "TCS")
+	first := mustCreateTemp(t, repo, infosys.ID, "Infosys Ltd")
+	second := mustCreateTemp(t, repo, infosys.ID, "Infosys Limited")
+	rejected := mustCreateTemp(t, repo, infosys.ID, "Infosys Pvt")
+	other := mustCreateTemp(t, repo, tcs.ID, "TCS Ltd")
+	if err := repo.UpdateCompanyTempStatus(rejected.ID, company.ProposalPending, company.ProposalRejected); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := repo.ApproveCompanyTemp(first.ID, "manager"); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{
+		first.ID:    company.ProposalApproved,
+		second.ID:   company.ProposalSuperseded,
+		rejected.ID: company.ProposalRejected,
+		other.ID:    company.ProposalPending,
+	}
+	for id, status := range want {
+		found, err := repo.GetCompanyTemp(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if found.Status != status {
+			t.Errorf("proposal %q has status %q, want %q", found.CompanyName, found.Status, status)
+		}
+	}
+}
+
+func testApproveOnlyPendingCompanyTemp(t *testing.T, repo company.Repository) {
+	created := mustCreate(t, repo, "Infosys")
+	temp := mustCreateTemp(t, repo, created.ID, "Infosys Ltd")
+	if err := repo.UpdateCompanyTempStatus(temp.ID, company.ProposalPending, company.ProposalRejected); err != nil {
+		t.Fatal(err)
+	}
+
+	err := repo.ApproveCompanyTemp(temp.ID, "manager")
+	var transition *company.ProposalTransitionError
+	if !errors.As(err, &transition) || transition.From != company.ProposalRejected || transition.To != company.ProposalApproved {
+		t.Fatalf("err = %v, want a ProposalTransitionError from rejected to approved", err)
+	}
+	if !errors.Is(err, company.ErrInvalidProposalTransition) {
+		t.Errorf("err = %v does not wrap ErrInvalidProposalTransition", err)
+	}
+	current, err := repo.GetCompany(created.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if current.CompanyName != "Infosys" || current.Version != created.Version {
+		t.Errorf("rejected proposal was applied: %+v", current)
+	}
+	found, err := repo.GetCompanyTemp(temp.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if found.Status != company.ProposalRejected {
+		t.Errorf("proposal status = %q, want rejected", found.Status)
+	}
+}
+
+func testApproveMissingCompanyTemp(t *testing.T, repo company.Repository) {
+	if err := repo.ApproveCompanyTemp("00000000-0000-0000-0000-000000000000", "manager"); !errors.Is(err, company.ErrNotFound) {
+		t.Errorf("err = %v, want ErrNotFound", err)
+	}
+}
+
+func testApproveStaleCompanyTemp(t *testing.T, repo company.Repository) {
+	created := mustCreate(t, repo, "Infosys")
+	temp := mustCreateTemp(t, repo, created.ID, "Infosys Ltd")
+	if _, err := repo.UpdateCompany(created.ID, created.Version, entity.CompanyUpdate{CompanyName: ptr("Infosys Limited")}, edit); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := repo.ApproveCompanyTemp(temp.ID, "manager"); !errors.Is(err, company.ErrStaleProposal) {
+		t.Fatalf("err = %v, want ErrStaleProposal", err)
+	}
+	current, err := repo.GetCompany(created.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if current.CompanyName != "Infosys Limited" {
+		t.Errorf("stale proposal was applied: %+v", current)
+	}
+}
+
+func testEventsOrderedByDateDesc(t *testing.T, repo company.Repository) {
+	for _, date := range []string{"2026-07-01T10:00:00Z", "2026-09-01T10:00:00.5Z", "2026-09-01T10:00:00Z", "2026-08-01"} {
+		event, err := repo.CreateEvent(date, "drive", date, "desc", "", "manager")
+		if err != nil {
+			t.Fatalf("CreateEvent(%q): %v", date, err)
+		}
+		if event.ID == "" || event.CreatedBy != "manager" {
+			t.Errorf("unexpected event: %+v", event)
+		}
+	}
+
+	events, err := repo.ListEvents("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"2026-09-01T10:00:00.5Z", "2026-09-01T10:00:00Z", "2026-08-01", "2026-07-01T10:00:00Z"}
+	if len(events) != len(want) {
+		t.Fatalf("expected %d events, got %d", len(want), len(events))
+	}
+	for i, event := range events {
+		if event.Title != want[i] {
+			t.Errorf("events[%d] = %s, want %s", i, event.Title, want[i])
+		}
+	}
+}
+
+func testEventCompany(t *testing.T, repo company.Repository) {
+	infosys := mustCreate(t, repo, "Infosys", "alice")
+	tcs := mustCreate(t, repo, "TCS", "bob")
+	for _, c := range []*entity.Company{infosys, tcs} {
+		event, err := repo.CreateEvent("2026-09-01T10:00:00Z", "drive", c.CompanyName, "", c.ID, "manager")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if event.CompanyID != c.ID {
+			t.Errorf("CompanyID = %q, want %s", event.CompanyID, c.ID)
+		}
+	}
+	if _, err := repo.CreateEvent("2026-09-02T10:00:00Z", "meeting", "Placement cell", "", "", "manager"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Purged companies leave their events behind, untied.
+	mustPurge(t, repo, infosys.ID)
+	mustPurge(t, repo, tcs.ID)
+	events, err := repo.ListEvents("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 3 {
+		t.Fatalf("%d events after purging their company, want 3", len(events))
+	}
+	for _, event := range events {
+		if event.CompanyID != "" {
+			t.Errorf("event %s still tied to %s", event.Title, event.CompanyID)
+		}
+	}
+}
+
+func testEventMovesWithMerge(t *testing.T, repo company.Repository) {
+	survivor := mustCreate(t, repo, "Infosys", "alice")
+	duplicate := mustCreate(t, repo, "Infosys Ltd", "bob")
+	if _, err := repo.CreateEvent("2026-09-01T10:00:00Z", "drive", "Drive", "", duplicate.ID, "manager"); err != nil {
+		t.Fatal(err)
+	}
+	mustMerge(t, repo, survivor, duplicate)
+	events, err := repo.ListEvents("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 1 || events[0].CompanyID != survivor.ID {
+		t.Errorf("events after merging = %+v, want one tied to the survivor", events)
+	}
+}
+
+func testCreateEventRejectsInvalidDate(t *testing.T, repo company.Repository) {
+	if _, err := repo.CreateEvent("next tuesday", "drive", "title", "", "", "manager"); err == nil {
+		t.Error("expected error for invalid event date")
+	}
+}
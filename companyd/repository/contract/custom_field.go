@@ -0,0 +1,183 @@
+package contract
+
+import (
+	"backend/companyd/entity"
+	"backend/companyd/usecase/company"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func mustCreateCustomField(t *testing.T, repo company.Repository, key, fieldType string, options ...string) *entity.CustomField {
+	t.Helper()
+	field, err := repo.CreateCustomField(entity.CustomField{Key: key, Label: key, Type: fieldType, Options: options})
+	if err != nil {
+		t.Fatalf("CreateCustomField(%q): %v", key, err)
+	}
+	return field
+}
+
+func mustCreateWithFields(t *testing.T, repo company.Repository, name string, values entity.CustomValues, tags ...string) *entity.Company {
+	t.Helper()
+	created, err := repo.CreateCompany(name, "", "2026", "on-campus", "", "false", "", "", "", "", "", nil, entity.Compensation{}, values, tags, testSeason)
+	if err != nil {
+		t.Fatalf("CreateCompany(%q): %v", name, err)
+	}
+	return created
+}
+
+func testCustomFieldCRUD(t *testing.T, repo company.Repository) {
+	sector := mustCreateCustomField(t, repo, "sector", company.FieldEnum, "IT", "Core")
+	if sector.ID == "" || sector.Label != "sector" || !reflect.DeepEqual(sector.Options, []string{"IT", "Core"}) || sector.CreatedAt == "" {
+		t.Errorf("created field = %+v", sector)
+	}
+	mustCreateCustomField(t, repo, "bond_months", company.FieldNumber)
+
+	if _, err := repo.CreateCustomField(entity.CustomField{Key: "sector", Label: "Sector", Type: company.FieldText}); !errors.Is(err, company.ErrFieldExists) {
+		t.Errorf("duplicate key: err = %v, want ErrFieldExists", err)
+	}
+
+	updated, err := repo.UpdateCustomField(sector.ID, entity.CustomField{Label: "Sector", Options: []string{"IT", "Core", "Finance"}, Required: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.Key != "sector" || updated.Type != company.FieldEnum || updated.Label != "Sector" || len(updated.Options) != 3 || !updated.Required {
+		t.Errorf("updated field = %+v", updated)
+	}
+	found, err := repo.GetCustomField(sector.ID)
+	if err != nil || !reflect.DeepEqual(found, updated) {
+		t.Errorf("GetCustomField = %+v, %v, want %+v", found, err, updated)
+	}
+
+	fields, err := repo.ListCustomFields()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fields) != 2 || fields[0].Key != "sector" || fields[1].Key != "bond_months" {
+		t.Errorf("fields = %+v, want sector then bond_months", fields)
+	}
+
+	if err := repo.DeleteCustomField(sector.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.GetCustomField(sector.ID); !errors.Is(err, company.ErrNotFound) {
+		t.Errorf("deleted field: err = %v, want ErrNotFound", err)
+	}
+	if err := repo.DeleteCustomField(sector.ID); !errors.Is(err, company.ErrNotFound) {
+		t.Errorf("second delete: err = %v, want ErrNotFound", err)
+	}
+	if _, err := repo.UpdateCustomField(sector.ID, entity.CustomField{Label: "Sector"}); !errors.Is(err, company.ErrNotFound) {
+		t.Errorf("update of deleted field: err = %v, want ErrNotFound", err)
+	}
+}
+
+func testCustomValuesRoundTrip(t *testing.T, repo company.Repository) {
+	values := entity.CustomValues{
+		"sector":      "IT",
+		"bond_months": 12.0,
+		"branches":    []string{"CSE", "ECE"},
+	}
+	created := mustCreateWithFields(t, repo, "Infosys", values, "dream", "mass recruiter")
+	if !reflect.DeepEqual(created.CustomFields, values) || !reflect.DeepEqual(created.Tags, []string{"dream", "mass recruiter"}) {
+		t.Errorf("created values %v tags %v", created.CustomFields, created.Tags)
+	}
+	found, err := repo.GetCompany(created.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(found.CustomFields, values) || !reflect.DeepEqual(found.Tags, created.Tags) {
+		t.Errorf("stored values %v tags %v", found.CustomFields, found.Tags)
+	}
+
+	plain := mustCreate(t, repo, "TCS")
+	if plain.CustomFields == nil || len(plain.CustomFields) != 0 || plain.Tags == nil || len(plain.Tags) != 0 {
+		t.Errorf("company without values: %v tags %v, want empty and non-nil", plain.CustomFields, plain.Tags)
+	}
+
+	// Leaving them out of an update keeps them; setting them replaces them.
+	updated, err := repo.UpdateCompany(created.ID, created.Version, entity.CompanyUpdate{Remarks: ptr("called")}, entity.CompanyChange{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(updated.CustomFields, values) || len(updated.Tags) != 2 {
+		t.Errorf("untouched values %v tags %v", updated.CustomFields, updated.Tags)
+	}
+	replaced := entity.CustomValues{"sector": "Core"}
+	tags := []string{}
+	updated, err = repo.UpdateCompany(created.ID, updated.Version, entity.CompanyUpdate{CustomFields: &replaced, Tags: &tags}, entity.CompanyChange{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(updated.CustomFields, replaced) || len(updated.Tags) != 0 {
+		t.Errorf("replaced values %v tags %v", updated.CustomFields, updated.Tags)
+	}
+}
+
+func testCustomFieldFilters(t *testing.T, repo company.Repository) {
+	infosys := mustCreateWithFields(t, repo, "Infosys", entity.CustomValues{"sector": "IT", "bond_months": 12.0, "branches": []string{"CSE", "ECE"}}, "dream", "mass recruiter")
+	tcs := mustCreateWithFields(t, repo, "TCS", entity.CustomValues{"sector": "IT", "branches": []string{"CSE"}}, "mass recruiter")
+	lnt := mustCreateWithFields(t, repo, "L&T", entity.CustomValues{"sector": "Core", "bond_months": 24.0, "branches": []string{"ME", "ECE"}}, "dream")
+	trashed := mustCreateWithFields(t, repo, "Wipro", entity.CustomValues{"sector": "IT"}, "dream")
+	if err := repo.DeleteCompany(trashed.ID, "admin"); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		name  string
+		query company.ListQuery
+		want  []string
+	}{
+		{"tag", company.ListQuery{Tags: []string{"dream"}}, []string{infosys.ID, lnt.ID}},
+		{"every tag", company.ListQuery{Tags: []string{"dream", "mass recruiter"}}, []string{infosys.ID}},
+		{"text", company.ListQuery{Fields: []company.FieldFilter{{Key: "sector", Value: "IT"}}}, []string{infosys.ID, tcs.ID}},
+		{"number", company.ListQuery{Fields: []company.FieldFilter{{Key: "bond_months", Value: 24.0}}}, []string{lnt.ID}},
+		{"option", company.ListQuery{Fields: []company.FieldFilter{{Key: "branches", Value: "ECE", Multi: true}}}, []string{infosys.ID, lnt.ID}},
+		{"fields and tags", company.ListQuery{
+			Tags:   []string{"mass recruiter"},
+			Fields: []company.FieldFilter{{Key: "sector", Value: "IT"}, {Key: "branches", Value: "ECE", Multi: true}},
+		}, []string{infosys.ID}},
+		{"no match", company.ListQuery{Fields: []company.FieldFilter{{Key: "sector", Value: "Finance"}}}, nil},
+	}
+	for _, c := range cases {
+		page, err := repo.QueryCompanies(c.query)
+		if err != nil {
+			t.Fatalf("%s: %v", c.name, err)
+		}
+		var got []string
+		for _, found := range page.Companies {
+			got = append(got, found.ID)
+		}
+		if !reflect.DeepEqual(got, c.want) || page.Total != len(c.want) {
+			t.Errorf("%s: got %v (total %d), want %v", c.name, got, page.Total, c.want)
+		}
+	}
+
+	tags, err := repo.ListTags()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []*entity.TagCount{{Tag: "dream", Companies: 2}, {Tag: "mass recruiter", Companies: 2}}
+	if !reflect.DeepEqual(tags, want) {
+		t.Errorf("tags = %+v, want %+v", tags, want)
+	}
+}
+
+func testDeleteCustomFieldRemovesValues(t *testing.T, repo company.Repository) {
+	sector := mustCreateCustomField(t, repo, "sector", company.FieldText)
+	mustCreateCustomField(t, repo, "location", company.FieldText)
+	created := mustCreateWithFields(t, repo, "Infosys", entity.CustomValues{"sector": "IT", "location": "Pune"})
+
+	if err := repo.DeleteCustomField(sector.ID); err != nil {
+		t.Fatal(err)
+	}
+	found, err := repo.GetCompany(created.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(found.CustomFields, entity.CustomValues{"location": "Pune"}) {
+		t.Errorf("values after delete = %v, want only location", found.CustomFields)
+	}
+	if found.Version != created.Version {
+		t.Errorf("deleting a field changed the company's version to %d", found.Version)
+	}
+}
@@ -0,0 +1,155 @@
+package contract
+
+import (
+	"backend/companyd/entity"
+	"backend/companyd/usecase/company"
+	"errors"
+	"testing"
+	"time"
+)
+
+func mustCreateDrive(t *testing.T, repo company.Repository, companyID, season string, startsOn *time.Time) *entity.Drive {
+	t.Helper()
+	created, err := repo.CreateDrive(entity.Drive{CompanyID: companyID, Type: company.DriveOnCampus, Season: season, StartsOn: startsOn, Roles: []string{}, Status: company.DrivePlanned})
+	if err != nil {
+		t.Fatalf("CreateDrive(%q): %v", season, err)
+	}
+	return created
+}
+
+func mustListDrives(t *testing.T, repo company.Repository, filter company.DriveFilter) []*entity.Drive {
+	t.Helper()
+	drives, err := repo.ListDrives(filter)
+	if err != nil {
+		t.Fatalf("ListDrives(%+v): %v", filter, err)
+	}
+	return drives
+}
+
+func driveIDs(drives []*entity.Drive) []string {
+	out := make([]string, len(drives))
+	for i, d := range drives {
+		out[i] = d.ID
+	}
+	return out
+}
+
+func testDriveCRUD(t *testing.T, repo company.Repository) {
+	infosys := mustCreate(t, repo, "Infosys")
+	startsOn := time.Date(2026, 7, 14, 0, 0, 0, 0, time.UTC)
+	endsOn := startsOn.AddDate(0, 0, 2)
+
+	created, err := repo.CreateDrive(entity.Drive{
+		CompanyID: infosys.ID,
+		Type:      company.DriveVirtual,
+		Season:    "2026-27",
+		StartsOn:  &startsOn,
+		EndsOn:    &endsOn,
+		Roles:     []string{"SDE", "Analyst"},
+		Package:   "12 LPA",
+		Status:    company.DriveScheduled,
+		Notes:     "internships",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created.ID == "" || created.CreatedAt == "" || created.UpdatedAt == "" {
+		t.Errorf("expected generated id and timestamps, got %+v", created)
+	}
+
+	found, err := repo.GetDrive(created.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if found.CompanyID != infosys.ID || found.Type != company.DriveVirtual || found.Season != "2026-27" || found.Package != "12 LPA" || found.Status != company.DriveScheduled || found.Notes != "internships" {
+		t.Errorf("GetDrive = %+v", found)
+	}
+	if found.StartsOn == nil || !found.StartsOn.Equal(startsOn) || found.EndsOn == nil || !found.EndsOn.Equal(endsOn) {
+		t.Errorf("dates = %v to %v, want %v to %v", found.StartsOn, found.EndsOn, startsOn, endsOn)
+	}
+	if len(found.Roles) != 2 || found.Roles[0] != "SDE" || found.Roles[1] != "Analyst" {
+		t.Errorf("Roles = %v, want [SDE Analyst]", found.Roles)
+	}
+
+	updated, err := repo.UpdateDrive(created.ID, entity.Drive{CompanyID: infosys.ID, Type: company.DriveOffCampus, Season: "2026-27", Roles: []string{}, Status: company.DriveCompleted})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.Type != company.DriveOffCampus || updated.Status != company.DriveCompleted || updated.StartsOn != nil || updated.EndsOn != nil || len(updated.Roles) != 0 || updated.Package != "" {
+		t.Errorf("UpdateDrive = %+v", updated)
+	}
+	if updated.CreatedAt != created.CreatedAt {
+		t.Errorf("CreatedAt changed from %s to %s", created.CreatedAt, updated.CreatedAt)
+	}
+
+	if err := repo.DeleteDrive(created.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.GetDrive(created.ID); !errors.Is(err, company.ErrNotFound) {
+		t.Errorf("GetDrive after delete: err = %v, want ErrNotFound", err)
+	}
+	if err := repo.DeleteDrive(created.ID); !errors.Is(err, company.ErrNotFound) {
+		t.Errorf("DeleteDrive twice: err = %v, want ErrNotFound", err)
+	}
+	if _, err := repo.UpdateDrive(missingID, entity.Drive{Type: company.DriveOnCampus, Status: company.DrivePlanned}); !errors.Is(err, company.ErrNotFound) {
+		t.Errorf("UpdateDrive missing: err = %v, want ErrNotFound", err)
+	}
+}
+
+func testListDrivesFilters(t *testing.T, repo company.Repository) {
+	infosys := mustCreate(t, repo, "Infosys")
+	tcs := mustCreate(t, repo, "TCS")
+	july := time.Date(2026, 7, 1, 0, 0, 0, 0, time.UTC)
+	december := time.Date(2026, 12, 1, 0, 0, 0, 0, time.UTC)
+	undated := mustCreateDrive(t, repo, infosys.ID, "2026-27", nil)
+	late := mustCreateDrive(t, repo, infosys.ID, "2026-27", &december)
+	early := mustCreateDrive(t, repo, tcs.ID, "2026-27", &july)
+	last := mustCreateDrive(t, repo, tcs.ID, "2025-26", &july)
+
+	tests := []struct {
+		filter company.DriveFilter
+		want   []*entity.Drive
+	}{
+		{company.DriveFilter{}, []*entity.Drive{early, last, late, undated}},
+		{company.DriveFilter{CompanyID: infosys.ID}, []*entity.Drive{late, undated}},
+		{company.DriveFilter{Season: "2026-27"}, []*entity.Drive{early, late, undated}},
+		{company.DriveFilter{CompanyID: tcs.ID, Season: "2025-26"}, []*entity.Drive{last}},
+		{company.DriveFilter{Type: company.DrivePool}, nil},
+		{company.DriveFilter{Status: company.DrivePlanned}, []*entity.Drive{early, last, late, undated}},
+	}
+	for _, tt := range tests {
+		got, want := driveIDs(mustListDrives(t, repo, tt.filter)), driveIDs(tt.want)
+		if len(got) != len(want) {
+			t.Errorf("ListDrives(%+v) = %v, want %v", tt.filter, got, want)
+			continue
+		}
+		for i := range got {
+			if got[i] != want[i] {
+				t.Errorf("ListDrives(%+v) = %v, want %v", tt.filter, got, want)
+				break
+			}
+		}
+	}
+}
+
+func testDrivesFollowCompany(t *testing.T, repo company.Repository) {
+	survivor := mustCreate(t, repo, "Infosys", "alice")
+	duplicate := mustCreate(t, repo, "Infosys Ltd", "bob")
+	purged := mustCreate(t, repo, "TCS")
+	moved := mustCreateDrive(t, repo, duplicate.ID, "2026-27", nil)
+	removed := mustCreateDrive(t, repo, purged.ID, "2026-27", nil)
+
+	mustMerge(t, repo, survivor, duplicate)
+	found, err := repo.GetDrive(moved.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if found.CompanyID != survivor.ID {
+		t.Errorf("drive after merging belongs to %s, want the survivor %s", found.CompanyID, survivor.ID)
+	}
+
+	mustPurge(t, repo, purged.ID)
+	if _, err := repo.GetDrive(removed.ID); !errors.Is(err, company.ErrNotFound) {
+		t.Errorf("drive of deleted company: err = %v, want ErrNotFound", err)
+	}
+}
@@ -0,0 +1,215 @@
+package contract
+
+import (
+	"backend/companyd/entity"
+	"backend/companyd/usecase/company"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func mustCreateFollowUp(t *testing.T, repo company.Repository, companyID, officer string, due time.Time, note string) *entity.FollowUp {
+	t.Helper()
+	created, err := repo.CreateFollowUp(entity.FollowUp{CompanyID: companyID, Officer: officer, DueAt: due, Note: note, Status: company.FollowUpOpen})
+	if err != nil {
+		t.Fatalf("CreateFollowUp(%q): %v", note, err)
+	}
+	return created
+}
+
+func mustListFollowUps(t *testing.T, repo company.Repository, filter company.FollowUpFilter) []string {
+	t.Helper()
+	followUps, err := repo.ListFollowUps(filter)
+	if err != nil {
+		t.Fatalf("ListFollowUps(%+v): %v", filter, err)
+	}
+	notes := make([]string, len(followUps))
+	for i, f := range followUps {
+		notes[i] = f.Note
+	}
+	return notes
+}
+
+// hoursFromNow is truncated to the second, which every backend stores exactly.
+func hoursFromNow(hours int) time.Time {
+	return time.Now().UTC().Truncate(time.Second).Add(time.Duration(hours) * time.Hour)
+}
+
+func testFollowUpCRUD(t *testing.T, repo company.Repository) {
+	infosys := mustCreate(t, repo, "Infosys", "alice")
+	due := hoursFromNow(48)
+
+	created := mustCreateFollowUp(t, repo, infosys.ID, "alice", due, "call back about drive dates")
+	if created.ID == "" || created.CreatedAt == "" || created.Status != company.FollowUpOpen || !created.DueAt.Equal(due) {
+		t.Errorf("unexpected created follow-up: %+v", created)
+	}
+	if created.CompletedAt != nil || created.RemindedAt != nil {
+		t.Errorf("new follow-up should not be completed or reminded: %+v", created)
+	}
+
+	found, err := repo.GetFollowUp(created.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if found.Note != created.Note || !found.DueAt.Equal(due) || found.Officer != "alice" || found.CompanyID != infosys.ID {
+		t.Errorf("GetFollowUp = %+v, want %+v", found, created)
+	}
+
+	completedAt := hoursFromNow(0)
+	change := *found
+	change.Status = company.FollowUpDone
+	change.CompletedBy = "bob"
+	change.CompletedAt = &completedAt
+	change.Officer = "bob"
+	updated, err := repo.UpdateFollowUp(created.ID, change)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.Status != company.FollowUpDone || updated.CompletedBy != "bob" || updated.CompletedAt == nil || !updated.CompletedAt.Equal(completedAt) || updated.Officer != "bob" {
+		t.Errorf("unexpected update result: %+v", updated)
+	}
+	if updated.CreatedAt != created.CreatedAt || updated.CompanyID != infosys.ID {
+		t.Errorf("update changed creation time or company: %+v", updated)
+	}
+
+	if _, err := repo.UpdateFollowUp(missingID, change); !errors.Is(err, company.ErrNotFound) {
+		t.Errorf("UpdateFollowUp(missing) error = %v, want ErrNotFound", err)
+	}
+	if err := repo.DeleteFollowUp(created.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.GetFollowUp(created.ID); !errors.Is(err, company.ErrNotFound) {
+		t.Errorf("GetFollowUp after delete error = %v, want ErrNotFound", err)
+	}
+	if err := repo.DeleteFollowUp(created.ID); !errors.Is(err, company.ErrNotFound) {
+		t.Errorf("second DeleteFollowUp error = %v, want ErrNotFound", err)
+	}
+}
+
+func testListFollowUpsFilters(t *testing.T, repo company.Repository) {
+	infosys := mustCreate(t, repo, "Infosys", "alice")
+	tcs := mustCreate(t, repo, "TCS", "bob")
+	mustCreateFollowUp(t, repo, infosys.ID, "alice", hoursFromNow(5), "later")
+	mustCreateFollowUp(t, repo, infosys.ID, "alice", hoursFromNow(-2), "overdue")
+	mustCreateFollowUp(t, repo, tcs.ID, "bob", hoursFromNow(1), "soon")
+	done := mustCreateFollowUp(t, repo, tcs.ID, "alice", hoursFromNow(2), "done")
+	change := *done
+	change.Status = company.FollowUpDone
+	if _, err := repo.UpdateFollowUp(done.ID, change); err != nil {
+		t.Fatal(err)
+	}
+
+	now := hoursFromNow(0)
+	inFiveHours := hoursFromNow(5)
+	for _, tc := range []struct {
+		name   string
+		filter company.FollowUpFilter
+		want   []string
+	}{
+		{"all, by due time", company.FollowUpFilter{}, []string{"overdue", "soon", "done", "later"}},
+		{"company", company.FollowUpFilter{CompanyID: infosys.ID}, []string{"overdue", "later"}},
+		{"officer", company.FollowUpFilter{Officer: "alice"}, []string{"overdue", "done", "later"}},
+		{"status", company.FollowUpFilter{Status: company.FollowUpOpen}, []string{"overdue", "soon", "later"}},
+		{"due before is exclusive", company.FollowUpFilter{DueBefore: &inFiveHours}, []string{"overdue", "soon", "done"}},
+		{"due after is inclusive", company.FollowUpFilter{DueAfter: &inFiveHours}, []string{"later"}},
+		{"overdue for alice", company.FollowUpFilter{Officer: "alice", Status: company.FollowUpOpen, DueBefore: &now}, []string{"overdue"}},
+	} {
+		got := mustListFollowUps(t, repo, tc.filter)
+		if strings.Join(got, ",") != strings.Join(tc.want, ",") {
+			t.Errorf("%s: got %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
+
+func testCreateReminders(t *testing.T, repo company.Repository) {
+	infosys := mustCreate(t, repo, "Infosys", "alice")
+	soon := mustCreateFollowUp(t, repo, infosys.ID, "alice", hoursFromNow(1), "send the JD")
+	mustCreateFollowUp(t, repo, infosys.ID, "bob", hoursFromNow(-1), "confirm the venue")
+	mustCreateFollowUp(t, repo, infosys.ID, "alice", hoursFromNow(72), "next week")
+	cancelled := mustCreateFollowUp(t, repo, infosys.ID, "alice", hoursFromNow(2), "cancelled")
+	change := *cancelled
+	change.Status = company.FollowUpCancelled
+	if _, err := repo.UpdateFollowUp(cancelled.ID, change); err != nil {
+		t.Fatal(err)
+	}
+
+	sent, err := repo.CreateReminders(hoursFromNow(24))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sent) != 2 {
+		t.Fatalf("sent %d reminders, want 2: %+v", len(sent), sent)
+	}
+	if sent[0].Recipient != "bob" || sent[1].Recipient != "alice" || sent[1].FollowUpID != soon.ID || sent[1].CompanyID != infosys.ID {
+		t.Errorf("unexpected reminders: %+v, %+v", sent[0], sent[1])
+	}
+	if !strings.Contains(sent[1].Message, "Infosys") || !strings.Contains(sent[1].Message, "send the JD") || sent[1].Read {
+		t.Errorf("unexpected reminder message: %+v", sent[1])
+	}
+	if reminded, _ := repo.GetFollowUp(soon.ID); reminded.RemindedAt == nil {
+		t.Error("follow-up not marked reminded")
+	}
+
+	// Each follow-up is reminded once.
+	if again, err := repo.CreateReminders(hoursFromNow(24)); err != nil || len(again) != 0 {
+		t.Errorf("second run sent %d reminders, err %v", len(again), err)
+	}
+
+	// Rescheduling clears RemindedAt, which re-arms the reminder.
+	reminded, err := repo.GetFollowUp(soon.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reminded.DueAt = hoursFromNow(3)
+	reminded.RemindedAt = nil
+	if _, err := repo.UpdateFollowUp(soon.ID, *reminded); err != nil {
+		t.Fatal(err)
+	}
+	if again, err := repo.CreateReminders(hoursFromNow(24)); err != nil || len(again) != 1 {
+		t.Errorf("rescheduled follow-up sent %d reminders, err %v", len(again), err)
+	}
+
+	notifications, err := repo.ListNotifications("alice", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(notifications) != 2 {
+		t.Fatalf("alice has %d notifications, want 2", len(notifications))
+	}
+	if err := repo.MarkNotificationRead(notifications[0].ID, "bob"); !errors.Is(err, company.ErrNotFound) {
+		t.Errorf("marking someone else's notification error = %v, want ErrNotFound", err)
+	}
+	if err := repo.MarkNotificationRead(notifications[0].ID, "alice"); err != nil {
+		t.Fatal(err)
+	}
+	unread, err := repo.ListNotifications("alice", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(unread) != 1 || unread[0].ID != notifications[1].ID {
+		t.Errorf("unread notifications = %+v, want only %s", unread, notifications[1].ID)
+	}
+}
+
+func testDeleteCompanyDeletesFollowUps(t *testing.T, repo company.Repository) {
+	infosys := mustCreate(t, repo, "Infosys", "alice")
+	tcs := mustCreate(t, repo, "TCS", "alice")
+	mustCreateFollowUp(t, repo, infosys.ID, "alice", hoursFromNow(1), "infosys")
+	mustCreateFollowUp(t, repo, tcs.ID, "alice", hoursFromNow(1), "tcs")
+	if _, err := repo.CreateReminders(hoursFromNow(2)); err != nil {
+		t.Fatal(err)
+	}
+
+	mustPurge(t, repo, infosys.ID)
+	if got := mustListFollowUps(t, repo, company.FollowUpFilter{}); len(got) != 1 || got[0] != "tcs" {
+		t.Errorf("follow-ups after delete = %v, want [tcs]", got)
+	}
+	notifications, err := repo.ListNotifications("alice", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(notifications) != 1 || notifications[0].CompanyID != tcs.ID {
+		t.Errorf("notifications after delete = %+v, want only the TCS reminder", notifications)
+	}
+}
@@ -0,0 +1,92 @@
+package contract
+
+import (
+	"backend/companyd/entity"
+	"backend/companyd/usecase/company"
+	"testing"
+)
+
+// edit attributes a direct edit to no one in particular.
+var edit = entity.CompanyChange{Source: company.RevisionEdit}
+
+func mustListRevisions(t *testing.T, repo company.Repository, companyID string) []*entity.CompanyRevision {
+	t.Helper()
+	revisions, err := repo.ListCompanyRevisions(companyID)
+	if err != nil {
+		t.Fatalf("ListCompanyRevisions(%s): %v", companyID, err)
+	}
+	return revisions
+}
+
+func testCompanyHistory(t *testing.T, repo company.Repository) {
+	created := mustCreate(t, repo, "Infosys", "alice")
+	updated, err := repo.UpdateCompany(created.ID, created.Version, entity.CompanyUpdate{
+		CompanyAddress:  ptr("Bangalore"),
+		AssignedOfficer: &[]string{"alice", "bob"},
+	}, entity.CompanyChange{ChangedBy: "alice", Source: company.RevisionEdit})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A rejected update records nothing.
+	if _, err := repo.UpdateCompany(created.ID, created.Version, entity.CompanyUpdate{CompanyName: ptr("stale")}, edit); err == nil {
+		t.Fatal("stale update succeeded")
+	}
+	temp := mustCreateTemp(t, repo, created.ID, "Infosys Ltd")
+	if err := repo.ApproveCompanyTemp(temp.ID, "manager"); err != nil {
+		t.Fatal(err)
+	}
+
+	revisions := mustListRevisions(t, repo, created.ID)
+	if len(revisions) != 3 {
+		t.Fatalf("got %d revisions, want 3: %+v", len(revisions), revisions)
+	}
+	for i, revision := range revisions {
+		if revision.ID == "" || revision.CompanyID != created.ID || revision.Version != i+1 || revision.CreatedAt == "" {
+			t.Errorf("revision %d: unexpected %+v", i, revision)
+		}
+	}
+
+	first, second, third := revisions[0], revisions[1], revisions[2]
+	if first.Source != company.RevisionCreate || first.ChangedBy != "" {
+		t.Errorf("first revision change = %+v, want a create", first.CompanyChange)
+	}
+	if first.Snapshot.CompanyName != "Infosys" || first.Snapshot.CompanyAddress != "Chennai" || first.Snapshot.Package != "10 LPA" || first.Snapshot.Compensation.Base == nil {
+		t.Errorf("first snapshot = %+v", first.Snapshot)
+	}
+	if first.CreatedAt != created.UpdatedAt {
+		t.Errorf("first revision at %s, want the company's %s", first.CreatedAt, created.UpdatedAt)
+	}
+
+	if second.ChangedBy != "alice" || second.Source != company.RevisionEdit {
+		t.Errorf("second revision change = %+v", second.CompanyChange)
+	}
+	if second.Snapshot.CompanyAddress != "Bangalore" || len(second.Snapshot.AssignedOfficer) != 2 || second.Snapshot.CompanyName != "Infosys" {
+		t.Errorf("second snapshot = %+v", second.Snapshot)
+	}
+	if second.CreatedAt != updated.UpdatedAt {
+		t.Errorf("second revision at %s, want the company's %s", second.CreatedAt, updated.UpdatedAt)
+	}
+
+	if third.ChangedBy != "manager" || third.Source != company.RevisionProposal || third.ProposalID != temp.ID {
+		t.Errorf("third revision change = %+v", third.CompanyChange)
+	}
+	if third.Snapshot.CompanyName != "Infosys Ltd" || third.Snapshot.CompanyAddress != "Pune" || !third.Snapshot.IsContacted {
+		t.Errorf("third snapshot = %+v", third.Snapshot)
+	}
+
+	if got := mustListRevisions(t, repo, missingID); len(got) != 0 {
+		t.Errorf("unknown company has %d revisions", len(got))
+	}
+}
+
+func testDeleteCompanyDeletesHistory(t *testing.T, repo company.Repository) {
+	infosys := mustCreate(t, repo, "Infosys")
+	tcs := mustCreate(t, repo, "TCS")
+	mustPurge(t, repo, infosys.ID)
+	if got := mustListRevisions(t, repo, infosys.ID); len(got) != 0 {
+		t.Errorf("deleted company still has %d revisions", len(got))
+	}
+	if got := mustListRevisions(t, repo, tcs.ID); len(got) != 1 {
+		t.Errorf("TCS has %d revisions, want 1", len(got))
+	}
+}
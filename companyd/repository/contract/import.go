@@ -0,0 +1,38 @@
+package contract
+
+import (
+	"backend/companyd/entity"
+	"backend/companyd/usecase/company"
+	"strings"
+	"testing"
+)
+
+func testImportCompanies(t *testing.T, repo company.Repository) {
+	rows := []entity.CompanySnapshot{
+		{CompanyName: "Infosys", CompanyAddress: "Bangalore", IsContacted: true, Package: "10 LPA", Compensation: company.ParseCompensation("10 LPA"), AssignedOfficer: []string{"alice", "bob"}},
+		{CompanyName: "Wipro", AssignedOfficer: []string{}},
+	}
+	created, err := repo.ImportCompanies(rows, testSeason, "admin")
+	if err != nil {
+		t.Fatalf("ImportCompanies: %v", err)
+	}
+	if len(created) != 2 || created[0].CompanyName != "Infosys" || created[1].CompanyName != "Wipro" {
+		t.Fatalf("ImportCompanies = %+v, want Infosys and Wipro in order", created)
+	}
+
+	got, err := repo.GetCompany(created[0].ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Version != 1 || got.Season != testSeason || got.CompanyAddress != "Bangalore" || !got.IsContacted || got.PipelineStatus != company.StageContacted || strings.Join(got.AssignedOfficer, ",") != "alice,bob" || got.Compensation.Base == nil {
+		t.Errorf("imported company = %+v", got)
+	}
+	if all := mustQuery(t, repo, company.ListQuery{Sort: "company_name"}); strings.Join(names(all.Companies), ",") != "Infosys,Wipro" {
+		t.Errorf("companies after import = %v", names(all.Companies))
+	}
+
+	revisions := mustListRevisions(t, repo, created[1].ID)
+	if len(revisions) != 1 || revisions[0].Source != company.RevisionImport || revisions[0].ChangedBy != "admin" || revisions[0].Snapshot.CompanyName != "Wipro" {
+		t.Errorf("revisions of imported company = %+v", revisions)
+	}
+}
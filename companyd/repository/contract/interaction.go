@@ -0,0 +1,168 @@
+package contract
+
+import (
+	"backend/companyd/entity"
+	"backend/companyd/usecase/company"
+	"strings"
+	"testing"
+	"time"
+)
+
+func mustCreateInteraction(t *testing.T, repo company.Repository, companyID, officer string, occurredAt time.Time, outcome string) *entity.Interaction {
+	t.Helper()
+	created, err := repo.CreateInteraction(entity.Interaction{CompanyID: companyID, Type: company.InteractionCall, OccurredAt: occurredAt, Officer: officer, Outcome: outcome})
+	if err != nil {
+		t.Fatalf("CreateInteraction(%q): %v", outcome, err)
+	}
+	return created
+}
+
+func mustListInteractions(t *testing.T, repo company.Repository, filter company.InteractionFilter) []string {
+	t.Helper()
+	interactions, err := repo.ListInteractions(filter)
+	if err != nil {
+		t.Fatalf("ListInteractions(%+v): %v", filter, err)
+	}
+	outcomes := make([]string, len(interactions))
+	for i, interaction := range interactions {
+		outcomes[i] = interaction.Outcome
+	}
+	return outcomes
+}
+
+func testInteractionTimeline(t *testing.T, repo company.Repository) {
+	infosys := mustCreate(t, repo, "Infosys", "alice")
+	tcs := mustCreate(t, repo, "TCS", "bob")
+
+	occurredAt := hoursFromNow(-3)
+	created, err := repo.CreateInteraction(entity.Interaction{
+		CompanyID:  infosys.ID,
+		Type:       company.InteractionMeeting,
+		OccurredAt: occurredAt,
+		Officer:    "alice",
+		Outcome:    "interested",
+		Notes:      "met the HR head",
+		NextStep:   "send the JD",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created.ID == "" || created.CreatedAt == "" || !created.OccurredAt.Equal(occurredAt) || created.Type != company.InteractionMeeting {
+		t.Errorf("unexpected created interaction: %+v", created)
+	}
+	if created.Notes != "met the HR head" || created.NextStep != "send the JD" || created.CompanyID != infosys.ID {
+		t.Errorf("interaction fields not stored: %+v", created)
+	}
+	mustCreateInteraction(t, repo, infosys.ID, "alice", hoursFromNow(-1), "asked for slots")
+	mustCreateInteraction(t, repo, infosys.ID, "bob", hoursFromNow(-48), "no answer")
+	mustCreateInteraction(t, repo, tcs.ID, "bob", hoursFromNow(-2), "declined")
+
+	since := hoursFromNow(-24)
+	until := hoursFromNow(-2)
+	for _, tc := range []struct {
+		name   string
+		filter company.InteractionFilter
+		want   []string
+	}{
+		{"all, newest first", company.InteractionFilter{}, []string{"asked for slots", "declined", "interested", "no answer"}},
+		{"company", company.InteractionFilter{CompanyID: infosys.ID}, []string{"asked for slots", "interested", "no answer"}},
+		{"officer", company.InteractionFilter{Officer: "bob"}, []string{"declined", "no answer"}},
+		{"type", company.InteractionFilter{Type: company.InteractionMeeting}, []string{"interested"}},
+		{"since is inclusive, until exclusive", company.InteractionFilter{Since: &since, Until: &until}, []string{"interested"}},
+	} {
+		got := mustListInteractions(t, repo, tc.filter)
+		if strings.Join(got, ",") != strings.Join(tc.want, ",") {
+			t.Errorf("%s: got %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
+
+func testInteractionUpdatesCompany(t *testing.T, repo company.Repository) {
+	infosys := mustCreate(t, repo, "Infosys", "alice")
+	if infosys.LastInteractionAt != nil || infosys.LastInteractionOutcome != "" {
+		t.Errorf("new company has a last interaction: %v %q", infosys.LastInteractionAt, infosys.LastInteractionOutcome)
+	}
+
+	latest := hoursFromNow(-1)
+	mustCreateInteraction(t, repo, infosys.ID, "alice", latest, "asked for slots")
+	// A backdated entry joins the timeline without replacing the latest.
+	mustCreateInteraction(t, repo, infosys.ID, "alice", hoursFromNow(-5), "no answer")
+
+	found, err := repo.GetCompany(infosys.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if found.LastInteractionAt == nil || !found.LastInteractionAt.Equal(latest) || found.LastInteractionOutcome != "asked for slots" {
+		t.Errorf("last interaction = %v %q, want %s asked for slots", found.LastInteractionAt, found.LastInteractionOutcome, latest)
+	}
+	// Logging an interaction is not an edit of the company.
+	if found.Version != infosys.Version || found.UpdatedAt != infosys.UpdatedAt {
+		t.Errorf("interaction changed version or updated_at: %d %s", found.Version, found.UpdatedAt)
+	}
+
+	// Updates to the company keep its last interaction.
+	name := "Infosys Ltd"
+	updated, err := repo.UpdateCompany(infosys.ID, found.Version, entity.CompanyUpdate{CompanyName: &name}, edit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.LastInteractionAt == nil || updated.LastInteractionOutcome != "asked for slots" {
+		t.Errorf("update lost the last interaction: %v %q", updated.LastInteractionAt, updated.LastInteractionOutcome)
+	}
+}
+
+func testQueryCompaniesByLastInteraction(t *testing.T, repo company.Repository) {
+	recent := mustCreate(t, repo, "Recent")
+	stale := mustCreate(t, repo, "Stale")
+	mustCreate(t, repo, "Never")
+	mustCreateInteraction(t, repo, recent.ID, "alice", hoursFromNow(-1), "interested")
+	mustCreateInteraction(t, repo, stale.ID, "alice", hoursFromNow(-24*30), "no answer")
+
+	weekAgo := hoursFromNow(-24 * 7)
+	for _, tc := range []struct {
+		name string
+		q    company.ListQuery
+		want []string
+	}{
+		{"neglected includes never contacted", company.ListQuery{LastInteractionBefore: &weekAgo, Sort: "company_name"}, []string{"Never", "Stale"}},
+		{"recently contacted", company.ListQuery{LastInteractionAfter: &weekAgo}, []string{"Recent"}},
+		{"oldest first", company.ListQuery{Sort: "last_interaction_at"}, []string{"Never", "Stale", "Recent"}},
+		{"newest first", company.ListQuery{Sort: "last_interaction_at", Desc: true}, []string{"Recent", "Stale", "Never"}},
+	} {
+		got := names(mustQuery(t, repo, tc.q).Companies)
+		if strings.Join(got, ",") != strings.Join(tc.want, ",") {
+			t.Errorf("%s: got %v, want %v", tc.name, got, tc.want)
+		}
+	}
+
+	// Keyset pagination works across companies without interactions.
+	q := company.ListQuery{Sort: "last_interaction_at", Limit: 1}
+	var paged []string
+	for {
+		page := mustQuery(t, repo, q)
+		paged = append(paged, names(page.Companies)...)
+		if page.NextCursor == "" {
+			break
+		}
+		cursor, err := company.DecodeCursor(page.NextCursor)
+		if err != nil {
+			t.Fatal(err)
+		}
+		q.After = cursor
+	}
+	if strings.Join(paged, ",") != "Never,Stale,Recent" {
+		t.Errorf("paged by last interaction: got %v", paged)
+	}
+}
+
+func testDeleteCompanyDeletesInteractions(t *testing.T, repo company.Repository) {
+	infosys := mustCreate(t, repo, "Infosys", "alice")
+	tcs := mustCreate(t, repo, "TCS", "alice")
+	mustCreateInteraction(t, repo, infosys.ID, "alice", hoursFromNow(-1), "infosys")
+	mustCreateInteraction(t, repo, tcs.ID, "alice", hoursFromNow(-1), "tcs")
+
+	mustPurge(t, repo, infosys.ID)
+	if got := mustListInteractions(t, repo, company.InteractionFilter{}); len(got) != 1 || got[0] != "tcs" {
+		t.Errorf("interactions after delete = %v, want [tcs]", got)
+	}
+}
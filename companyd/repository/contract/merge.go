@@ -0,0 +1,97 @@
+package contract
+
+import (
+	"backend/companyd/entity"
+	"backend/companyd/usecase/company"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func mustMerge(t *testing.T, repo company.Repository, survivor, duplicate *entity.Company) *entity.Company {
+	t.Helper()
+	change := entity.CompanyChange{ChangedBy: "admin", Source: company.RevisionMerge, MergedFrom: duplicate.ID}
+	merged, err := repo.MergeCompanies(survivor.ID, duplicate.ID, survivor.Version, company.MergeFields(survivor, duplicate), change)
+	if err != nil {
+		t.Fatalf("MergeCompanies: %v", err)
+	}
+	return merged
+}
+
+func testMergeCompanies(t *testing.T, repo company.Repository) {
+	survivor := mustCreate(t, repo, "Infosys", "alice")
+	duplicate := mustCreate(t, repo, "Infosys Ltd", "bob", "alice")
+	mustCreateContact(t, repo, survivor.ID, "Asha", "asha@infosys.com", true)
+	mustCreateContact(t, repo, duplicate.ID, "Ravi", "ravi@infosys.com", true)
+	temp := mustCreateTemp(t, repo, duplicate.ID, "Infosys Limited")
+	mustCreateFollowUp(t, repo, duplicate.ID, "bob", hoursFromNow(1), "call back")
+	mustCreateInteraction(t, repo, duplicate.ID, "bob", hoursFromNow(-1), "interested")
+
+	merged := mustMerge(t, repo, survivor, duplicate)
+	if merged.CompanyName != "Infosys" || merged.Version != survivor.Version+1 || strings.Join(merged.AssignedOfficer, ",") != "alice,bob" {
+		t.Errorf("merged company = %+v", merged)
+	}
+	if merged.LastInteractionAt == nil || merged.LastInteractionOutcome != "interested" {
+		t.Errorf("merged last interaction = %v %q, want the duplicate's", merged.LastInteractionAt, merged.LastInteractionOutcome)
+	}
+	if _, err := repo.GetCompany(duplicate.ID); !errors.Is(err, company.ErrNotFound) {
+		t.Errorf("GetCompany of merged duplicate: err = %v, want ErrNotFound", err)
+	}
+
+	contacts := mustListContacts(t, repo, company.ContactFilter{CompanyIDs: []string{survivor.ID}})
+	if len(contacts) != 2 {
+		t.Fatalf("survivor has contacts %v, want Asha and Ravi", contactNames(contacts))
+	}
+	for _, c := range contacts {
+		if c.IsPrimary != (c.Name == "Asha") {
+			t.Errorf("contact %s primary = %v", c.Name, c.IsPrimary)
+		}
+	}
+
+	temps, err := repo.ListCompanyTemps("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(temps) != 1 || temps[0].ID != temp.ID || temps[0].CompanyID != survivor.ID || temps[0].BaseVersion != survivor.Version {
+		t.Errorf("proposals after merge = %+v, want %s moved to the survivor at version %d", temps, temp.ID, survivor.Version)
+	}
+	if got := mustListFollowUps(t, repo, company.FollowUpFilter{CompanyID: survivor.ID}); len(got) != 1 || got[0] != "call back" {
+		t.Errorf("survivor follow-ups = %v", got)
+	}
+	if got := mustListInteractions(t, repo, company.InteractionFilter{CompanyID: survivor.ID}); len(got) != 1 {
+		t.Errorf("survivor interactions = %v", got)
+	}
+
+	revisions := mustListRevisions(t, repo, survivor.ID)
+	last := revisions[len(revisions)-1]
+	if last.Version != merged.Version || last.Source != company.RevisionMerge || last.MergedFrom != duplicate.ID || last.ChangedBy != "admin" {
+		t.Errorf("merge revision = %+v", last)
+	}
+	if got := mustListRevisions(t, repo, duplicate.ID); len(got) != 0 {
+		t.Errorf("merged duplicate still has %d revisions", len(got))
+	}
+}
+
+func testMergeCompaniesErrors(t *testing.T, repo company.Repository) {
+	survivor := mustCreate(t, repo, "Infosys")
+	duplicate := mustCreate(t, repo, "Infosys Ltd")
+	change := entity.CompanyChange{Source: company.RevisionMerge, MergedFrom: duplicate.ID}
+	update := company.MergeFields(survivor, duplicate)
+
+	if _, err := repo.MergeCompanies(survivor.ID, duplicate.ID, survivor.Version+1, update, change); !errors.Is(err, company.ErrVersionMismatch) {
+		t.Errorf("stale merge: err = %v, want ErrVersionMismatch", err)
+	}
+	if _, err := repo.MergeCompanies(survivor.ID, missingID, survivor.Version, update, change); !errors.Is(err, company.ErrUnknownCompany) {
+		t.Errorf("missing duplicate: err = %v, want ErrUnknownCompany", err)
+	}
+	if _, err := repo.MergeCompanies(missingID, duplicate.ID, 1, update, change); !errors.Is(err, company.ErrNotFound) {
+		t.Errorf("missing survivor: err = %v, want ErrNotFound", err)
+	}
+	// A failed merge leaves both companies alone.
+	if _, err := repo.GetCompany(duplicate.ID); err != nil {
+		t.Errorf("duplicate after failed merges: %v", err)
+	}
+	if got, err := repo.GetCompany(survivor.ID); err != nil || got.Version != survivor.Version {
+		t.Errorf("survivor after failed merges = %+v, %v", got, err)
+	}
+}
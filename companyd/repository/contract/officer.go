@@ -0,0 +1,168 @@
+package contract
+
+import (
+	"backend/companyd/entity"
+	"backend/companyd/usecase/company"
+	"errors"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func mustListOfficers(t *testing.T, repo company.Repository, companyID string) []*entity.OfficerAssignment {
+	t.Helper()
+	officers, err := repo.ListCompanyOfficers(companyID)
+	if err != nil {
+		t.Fatalf("ListCompanyOfficers(%s): %v", companyID, err)
+	}
+	return officers
+}
+
+// officerRoles renders assignments as username:role pairs.
+func officerRoles(officers []*entity.OfficerAssignment) string {
+	var out []string
+	for _, o := range officers {
+		out = append(out, o.Username+":"+o.Role)
+	}
+	return strings.Join(out, ",")
+}
+
+func testCompanyOfficers(t *testing.T, repo company.Repository) {
+	created := mustCreate(t, repo, "Infosys", "bob", "alice", "bob")
+	if got := strings.Join(created.AssignedOfficer, ","); got != "bob,alice" {
+		t.Errorf("AssignedOfficer = %s, want bob,alice", got)
+	}
+	before := mustListOfficers(t, repo, created.ID)
+	if got := officerRoles(before); got != "bob:primary,alice:secondary" {
+		t.Fatalf("officers = %s, want bob:primary,alice:secondary", got)
+	}
+	for _, o := range before {
+		if o.UserID == "" || o.AssignedAt.IsZero() {
+			t.Errorf("incomplete assignment: %+v", o)
+		}
+	}
+
+	// Alice stays assigned, so she keeps when and by whom.
+	officers := []string{"alice", "carol"}
+	updated, err := repo.UpdateCompany(created.ID, created.Version, entity.CompanyUpdate{AssignedOfficer: &officers}, entity.CompanyChange{ChangedBy: "manager", Source: company.RevisionEdit})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(updated.AssignedOfficer, ","); got != "alice,carol" {
+		t.Errorf("AssignedOfficer = %s, want alice,carol", got)
+	}
+	after := mustListOfficers(t, repo, created.ID)
+	if got := officerRoles(after); got != "alice:primary,carol:secondary" {
+		t.Fatalf("officers = %s, want alice:primary,carol:secondary", got)
+	}
+	if !after[0].AssignedAt.Equal(before[1].AssignedAt) || after[0].AssignedBy != before[1].AssignedBy {
+		t.Errorf("alice reassigned: %+v, was %+v", after[0], before[1])
+	}
+	if after[1].AssignedBy != "manager" {
+		t.Errorf("carol assigned by %q, want manager", after[1].AssignedBy)
+	}
+	revisions := mustListRevisions(t, repo, created.ID)
+	if latest := revisions[len(revisions)-1]; strings.Join(latest.Snapshot.AssignedOfficer, ",") != "alice,carol" {
+		t.Errorf("revision snapshot officers = %v, want alice,carol", latest.Snapshot.AssignedOfficer)
+	}
+	if companies, err := repo.ListCompaniesByUsername("bob"); err != nil || len(companies) != 0 {
+		t.Errorf("bob still has %d companies (%v)", len(companies), err)
+	}
+
+	// Other updates leave the officers alone.
+	remarks := "met HR"
+	if updated, err = repo.UpdateCompany(created.ID, updated.Version, entity.CompanyUpdate{Remarks: &remarks}, entity.CompanyChange{Source: company.RevisionEdit}); err != nil {
+		t.Fatal(err)
+	}
+	if got := officerRoles(mustListOfficers(t, repo, created.ID)); got != "alice:primary,carol:secondary" || strings.Join(updated.AssignedOfficer, ",") != "alice,carol" {
+		t.Errorf("officers = %s (%v) after editing remarks", got, updated.AssignedOfficer)
+	}
+
+	temp := mustCreateTemp(t, repo, created.ID, "Infosys Ltd")
+	if err := repo.ApproveCompanyTemp(temp.ID, "admin"); err != nil {
+		t.Fatal(err)
+	}
+	approved := mustListOfficers(t, repo, created.ID)
+	if got := officerRoles(approved); got != "officer:primary" || approved[0].AssignedBy != "admin" {
+		t.Errorf("officers after approval = %s by %q, want officer:primary by admin", got, approved[0].AssignedBy)
+	}
+
+	none := []string{}
+	if _, err := repo.UpdateCompany(created.ID, updated.Version+1, entity.CompanyUpdate{AssignedOfficer: &none}, entity.CompanyChange{Source: company.RevisionEdit}); err != nil {
+		t.Fatal(err)
+	}
+	if got := mustListOfficers(t, repo, created.ID); len(got) != 0 {
+		t.Errorf("officers after unassigning = %s, want none", officerRoles(got))
+	}
+	found, err := repo.GetCompany(created.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if found.AssignedOfficer == nil || len(found.AssignedOfficer) != 0 {
+		t.Errorf("AssignedOfficer = %#v, want empty", found.AssignedOfficer)
+	}
+}
+
+func testUnknownOfficers(t *testing.T, repo company.Repository) {
+	unknown, err := repo.UnknownOfficers([]string{"alice", "mallory", "trudy", "mallory"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(unknown, ","); got != "mallory,trudy" {
+		t.Errorf("UnknownOfficers = %s, want mallory,trudy", got)
+	}
+
+	_, err = repo.CreateCompany("Infosys", "", "2026", "on-campus", "", "false", "", "", "", "", "", []string{"alice", "mallory"}, entity.Compensation{}, nil, nil, testSeason)
+	if !errors.Is(err, company.ErrUnknownOfficer) || !strings.Contains(err.Error(), "mallory") {
+		t.Errorf("CreateCompany with an unknown officer: %v, want ErrUnknownOfficer naming mallory", err)
+	}
+	if companies, _ := repo.ListCompanies(); len(companies) != 0 {
+		t.Errorf("created %d companies despite the unknown officer", len(companies))
+	}
+
+	created := mustCreate(t, repo, "TCS", "alice")
+	officers := []string{"bob", "mallory"}
+	remarks := "met HR"
+	_, err = repo.UpdateCompany(created.ID, created.Version, entity.CompanyUpdate{Remarks: &remarks, AssignedOfficer: &officers}, entity.CompanyChange{Source: company.RevisionEdit})
+	if !errors.Is(err, company.ErrUnknownOfficer) {
+		t.Errorf("UpdateCompany with an unknown officer: %v, want ErrUnknownOfficer", err)
+	}
+	found, err := repo.GetCompany(created.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if found.Version != created.Version || found.Remarks != created.Remarks || strings.Join(found.AssignedOfficer, ",") != "alice" {
+		t.Errorf("failed update changed the company: %+v", found)
+	}
+}
+
+func testOfficerUsernames(t *testing.T, repo company.Repository) {
+	usernames, err := repo.ListOfficerUsernames()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !sort.StringsAreSorted(usernames) {
+		t.Errorf("ListOfficerUsernames = %v, want alphabetical", usernames)
+	}
+	// The database may hold other users too, but never the seeded admin or
+	// manager.
+	for _, officer := range Officers {
+		if !containsString(usernames, officer) {
+			t.Errorf("ListOfficerUsernames = %v, missing %s", usernames, officer)
+		}
+	}
+	for _, other := range []string{"admin", "manager"} {
+		if containsString(usernames, other) {
+			t.Errorf("ListOfficerUsernames = %v, includes %s", usernames, other)
+		}
+	}
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
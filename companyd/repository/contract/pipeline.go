@@ -0,0 +1,104 @@
+package contract
+
+import (
+	"backend/companyd/entity"
+	"backend/companyd/usecase/company"
+	"testing"
+	"time"
+)
+
+func mustListTransitions(t *testing.T, repo company.Repository, filter company.StatusTransitionFilter) []*entity.StatusTransition {
+	t.Helper()
+	transitions, err := repo.ListStatusTransitions(filter)
+	if err != nil {
+		t.Fatalf("ListStatusTransitions(%+v): %v", filter, err)
+	}
+	return transitions
+}
+
+func testPipelineStatus(t *testing.T, repo company.Repository) {
+	contacted := mustCreate(t, repo, "Infosys")
+	prospect, err := repo.CreateCompany("TCS", "", "", "", "", "false", "", "", "", "", "", nil, entity.Compensation{}, nil, nil, testSeason)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if contacted.PipelineStatus != company.StageContacted || prospect.PipelineStatus != company.StageProspect {
+		t.Errorf("new companies at %q and %q, want contacted and prospect", contacted.PipelineStatus, prospect.PipelineStatus)
+	}
+
+	status, isContacted := company.StageInterested, true
+	update := entity.CompanyUpdate{PipelineStatus: &status, IsContacted: &isContacted}
+	change := entity.CompanyChange{ChangedBy: "alice", Source: company.RevisionStatus}
+	moved, err := repo.UpdateCompany(contacted.ID, contacted.Version, update, change)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if moved.PipelineStatus != company.StageInterested || !moved.IsContacted || moved.Version != contacted.Version+1 {
+		t.Errorf("moved company = %+v", moved)
+	}
+	// An update that leaves the status alone records no transition.
+	name := "Infosys Ltd"
+	if _, err := repo.UpdateCompany(contacted.ID, moved.Version, entity.CompanyUpdate{CompanyName: &name}, entity.CompanyChange{Source: company.RevisionEdit}); err != nil {
+		t.Fatal(err)
+	}
+
+	transitions := mustListTransitions(t, repo, company.StatusTransitionFilter{CompanyID: contacted.ID})
+	if len(transitions) != 2 {
+		t.Fatalf("got %d transitions, want 2: %+v", len(transitions), transitions)
+	}
+	first, second := transitions[0], transitions[1]
+	if first.From != "" || first.To != company.StageContacted || first.ID == "" || first.ChangedAt.IsZero() {
+		t.Errorf("first transition = %+v", first)
+	}
+	if second.From != company.StageContacted || second.To != company.StageInterested || second.ChangedBy != "alice" || second.ChangedAt.Before(first.ChangedAt) {
+		t.Errorf("second transition = %+v", second)
+	}
+
+	if got := mustListTransitions(t, repo, company.StatusTransitionFilter{}); len(got) != 3 {
+		t.Errorf("all transitions = %d, want 3", len(got))
+	}
+	past := time.Now().Add(-time.Hour)
+	if got := mustListTransitions(t, repo, company.StatusTransitionFilter{Before: &past}); len(got) != 0 {
+		t.Errorf("transitions before an hour ago = %+v, want none", got)
+	}
+
+	// Transitions follow their company's season, and leave with it for the
+	// trash.
+	other, err := repo.CreateCompany("Wipro", "", "", "", "", "false", "", "", "", "", "", nil, entity.Compensation{}, nil, nil, "2025-26")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := mustListTransitions(t, repo, company.StatusTransitionFilter{Season: testSeason}); len(got) != 3 {
+		t.Errorf("%s transitions = %d, want 3", testSeason, len(got))
+	}
+	if got := mustListTransitions(t, repo, company.StatusTransitionFilter{Season: "2025-26"}); len(got) != 1 || got[0].CompanyID != other.ID {
+		t.Errorf("2025-26 transitions = %+v, want Wipro's", got)
+	}
+	if err := repo.DeleteCompany(prospect.ID, "admin"); err != nil {
+		t.Fatal(err)
+	}
+	if got := mustListTransitions(t, repo, company.StatusTransitionFilter{Season: testSeason, CompanyID: prospect.ID}); len(got) != 0 {
+		t.Errorf("transitions of a company in the trash = %+v, want none", got)
+	}
+
+	page, err := repo.QueryCompanies(company.ListQuery{PipelineStatus: company.StageInterested})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(page.Companies) != 1 || page.Companies[0].ID != contacted.ID {
+		t.Errorf("interested companies = %+v", page.Companies)
+	}
+}
+
+func testDeleteCompanyDeletesTransitions(t *testing.T, repo company.Repository) {
+	created := mustCreate(t, repo, "Infosys")
+	if err := repo.DeleteCompany(created.ID, "admin"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.PurgeCompanies(time.Now().Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if got := mustListTransitions(t, repo, company.StatusTransitionFilter{CompanyID: created.ID}); len(got) != 0 {
+		t.Errorf("purged company still has transitions: %+v", got)
+	}
+}
@@ -0,0 +1,182 @@
+package contract
+
+import (
+	"backend/companyd/entity"
+	"backend/companyd/usecase/company"
+	"errors"
+	"testing"
+)
+
+func mustListSeasons(t *testing.T, repo company.Repository) map[string]*entity.Season {
+	t.Helper()
+	seasons, err := repo.ListSeasons()
+	if err != nil {
+		t.Fatal(err)
+	}
+	byName := map[string]*entity.Season{}
+	for i, season := range seasons {
+		if i > 0 && seasons[i-1].Name >= season.Name {
+			t.Errorf("seasons out of order: %q before %q", seasons[i-1].Name, season.Name)
+		}
+		byName[season.Name] = season
+	}
+	return byName
+}
+
+func testActiveSeason(t *testing.T, repo company.Repository) {
+	if seasons := mustListSeasons(t, repo); len(seasons) != 0 {
+		t.Fatalf("expected no seasons, got %v", seasons)
+	}
+
+	mustCreate(t, repo, "Infosys")
+	mustCreate(t, repo, "TCS")
+	trashed := mustCreate(t, repo, "Wipro")
+	if err := repo.DeleteCompany(trashed.ID, "admin"); err != nil {
+		t.Fatal(err)
+	}
+	seasons := mustListSeasons(t, repo)
+	if len(seasons) != 1 || seasons[testSeason] == nil || seasons[testSeason].Companies != 2 || seasons[testSeason].Active {
+		t.Fatalf("seasons = %v, want %s with 2 companies, inactive", seasons, testSeason)
+	}
+
+	if err := repo.SetActiveSeason("2027-28"); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.SetActiveSeason(testSeason); err != nil {
+		t.Fatal(err)
+	}
+	seasons = mustListSeasons(t, repo)
+	if len(seasons) != 2 {
+		t.Fatalf("got %d seasons, want 2", len(seasons))
+	}
+	if !seasons[testSeason].Active || seasons[testSeason].Companies != 2 {
+		t.Errorf("%s = %+v, want active with 2 companies", testSeason, seasons[testSeason])
+	}
+	if next := seasons["2027-28"]; next.Active || next.Companies != 0 {
+		t.Errorf("2027-28 = %+v, want inactive and empty", next)
+	}
+}
+
+func testRolloverCompanies(t *testing.T, repo company.Repository) {
+	original := mustCreate(t, repo, "Infosys", "alice", "bob")
+	mustCreateContact(t, repo, original.ID, "Asha", "asha@infosys.com", true)
+	mustCreateContact(t, repo, original.ID, "Ravi", "ravi@infosys.com", false)
+
+	carried, err := repo.RolloverCompanies([]string{original.ID}, "2027-28", "admin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(carried) != 1 {
+		t.Fatalf("carried %d companies, want 1", len(carried))
+	}
+	copied := carried[0]
+	if copied.ID == original.ID || copied.Season != "2027-28" || copied.CarriedFrom != original.ID {
+		t.Errorf("copy = %+v, want a new company in 2027-28 carried from %s", copied, original.ID)
+	}
+	if copied.CompanyName != "Infosys" || copied.CompanyAddress != "Chennai" || copied.Package != "10 LPA" || copied.Compensation.Base == nil {
+		t.Errorf("copy lost details: %+v", copied)
+	}
+	if copied.PipelineStatus != company.StageProspect || copied.IsContacted || copied.FollowUp != "" || copied.Version != 1 {
+		t.Errorf("copy status = %q contacted %v follow-up %q version %d, want a fresh prospect", copied.PipelineStatus, copied.IsContacted, copied.FollowUp, copied.Version)
+	}
+	if len(copied.AssignedOfficer) != 2 || copied.AssignedOfficer[0] != "alice" || copied.AssignedOfficer[1] != "bob" {
+		t.Errorf("copy officers = %v, want [alice bob]", copied.AssignedOfficer)
+	}
+
+	contacts, err := repo.ListContacts(company.ContactFilter{CompanyIDs: []string{copied.ID}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(contacts) != 2 {
+		t.Fatalf("copy has %d contacts, want 2", len(contacts))
+	}
+	primaries := 0
+	for _, contact := range contacts {
+		if contact.CompanyID != copied.ID {
+			t.Errorf("contact %+v not moved to the copy", contact)
+		}
+		if contact.IsPrimary {
+			primaries++
+		}
+	}
+	if primaries != 1 {
+		t.Errorf("copy has %d primary contacts, want 1", primaries)
+	}
+
+	revisions, err := repo.ListCompanyRevisions(copied.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(revisions) != 1 || revisions[0].Source != company.RevisionRollover || revisions[0].ChangedBy != "admin" {
+		t.Errorf("copy revisions = %+v, want one rollover by admin", revisions)
+	}
+
+	// The original stays in its season untouched.
+	kept, err := repo.GetCompany(original.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if kept.Season != testSeason || kept.PipelineStatus != original.PipelineStatus || kept.Version != original.Version {
+		t.Errorf("original changed: %+v", kept)
+	}
+	if seasons := mustListSeasons(t, repo); seasons["2027-28"] == nil || seasons["2027-28"].Companies != 1 {
+		t.Errorf("seasons = %v, want 2027-28 with 1 company", seasons)
+	}
+
+	if _, err := repo.RolloverCompanies([]string{original.ID, "00000000-0000-0000-0000-000000000000"}, "2028-29", "admin"); !errors.Is(err, company.ErrUnknownCompany) {
+		t.Errorf("rollover of a missing company: err = %v, want ErrUnknownCompany", err)
+	}
+	if seasons := mustListSeasons(t, repo); seasons["2028-29"] != nil && seasons["2028-29"].Companies != 0 {
+		t.Errorf("failed rollover left companies behind: %+v", seasons["2028-29"])
+	}
+}
+
+func testSeasonFilters(t *testing.T, repo company.Repository) {
+	current := mustCreate(t, repo, "Infosys")
+	carried, err := repo.RolloverCompanies([]string{current.ID}, "2027-28", "admin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	next := carried[0]
+
+	page, err := repo.QueryCompanies(company.ListQuery{Season: "2027-28"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if page.Total != 1 || len(page.Companies) != 1 || page.Companies[0].ID != next.ID {
+		t.Errorf("2027-28 companies = %+v, want only the copy", page.Companies)
+	}
+	if page, err = repo.QueryCompanies(company.ListQuery{}); err != nil || page.Total != 2 {
+		t.Errorf("every season: total %v, err %v, want 2", page, err)
+	}
+
+	mustCreateTemp(t, repo, current.ID, "Infosys Ltd")
+	mustCreateTemp(t, repo, next.ID, "Infosys Limited")
+	temps, err := repo.ListCompanyTemps("2027-28")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(temps) != 1 || temps[0].CompanyID != next.ID || temps[0].Season != "2027-28" {
+		t.Errorf("2027-28 proposals = %+v, want the copy's", temps)
+	}
+	if temps, err := repo.ListCompanyTemps(""); err != nil || len(temps) != 2 {
+		t.Errorf("every proposal: %d, err %v, want 2", len(temps), err)
+	}
+
+	// Events belong to the season of their date: July starts a season.
+	for _, date := range []string{"2027-06-30T10:00:00Z", "2027-07-01T10:00:00Z"} {
+		if _, err := repo.CreateEvent(date, "drive", date, "", "", "manager"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	events, err := repo.ListEvents(testSeason)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 1 || events[0].Title != "2027-06-30T10:00:00Z" || events[0].Season != testSeason {
+		t.Errorf("%s events = %+v", testSeason, events)
+	}
+	if events, err := repo.ListEvents(""); err != nil || len(events) != 2 {
+		t.Errorf("every event: %d, err %v, want 2", len(events), err)
+	}
+}
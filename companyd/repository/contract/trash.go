@@ -0,0 +1,134 @@
+package contract
+
+import (
+	"backend/companyd/usecase/company"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// mustPurge deletes a company and empties the trash straight away.
+func mustPurge(t *testing.T, repo company.Repository, id string) {
+	t.Helper()
+	if err := repo.DeleteCompany(id, "alice"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.PurgeCompanies(hoursFromNow(1)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func testRestoreCompany(t *testing.T, repo company.Repository) {
+	infosys := mustCreate(t, repo, "Infosys", "alice")
+	tcs := mustCreate(t, repo, "TCS", "alice")
+
+	if _, err := repo.RestoreCompany(infosys.ID); !errors.Is(err, company.ErrNotFound) {
+		t.Errorf("restoring a company not in the trash: err = %v, want ErrNotFound", err)
+	}
+
+	if err := repo.DeleteCompany(infosys.ID, "alice"); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(2 * time.Millisecond)
+	if err := repo.DeleteCompany(tcs.ID, "bob"); err != nil {
+		t.Fatal(err)
+	}
+	// Deleting again keeps the original deletion.
+	if err := repo.DeleteCompany(infosys.ID, "carol"); err != nil {
+		t.Fatal(err)
+	}
+
+	trash, err := repo.ListDeletedCompanies()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := names(trash); len(got) != 2 || got[0] != "TCS" || got[1] != "Infosys" {
+		t.Fatalf("trash = %v, want [TCS Infosys]", got)
+	}
+	if trash[1].DeletedAt == nil || trash[1].DeletedBy != "alice" {
+		t.Errorf("Infosys deleted at %v by %q, want a time and alice", trash[1].DeletedAt, trash[1].DeletedBy)
+	}
+	if got, err := repo.ListCompaniesByUsername("alice"); err != nil || len(got) != 0 {
+		t.Errorf("ListCompaniesByUsername(alice) = %v, %v, want none", names(got), err)
+	}
+
+	restored, err := repo.RestoreCompany(infosys.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if restored.DeletedAt != nil || restored.DeletedBy != "" || restored.CompanyName != "Infosys" {
+		t.Errorf("restored company = %+v", restored)
+	}
+	if _, err := repo.GetCompany(infosys.ID); err != nil {
+		t.Errorf("GetCompany after restore: %v", err)
+	}
+	if trash, err = repo.ListDeletedCompanies(); err != nil || len(trash) != 1 || trash[0].ID != tcs.ID {
+		t.Errorf("trash after restore = %v, %v, want [TCS]", names(trash), err)
+	}
+	if _, err := repo.RestoreCompany(missingID); !errors.Is(err, company.ErrNotFound) {
+		t.Errorf("restoring a missing company: err = %v, want ErrNotFound", err)
+	}
+}
+
+func testArchiveCompany(t *testing.T, repo company.Repository) {
+	infosys := mustCreate(t, repo, "Infosys", "alice")
+	mustCreate(t, repo, "TCS", "alice")
+
+	archived, err := repo.SetCompanyArchived(infosys.ID, true, "bob")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if archived.ArchivedAt == nil || archived.ArchivedBy != "bob" || archived.Version != infosys.Version {
+		t.Errorf("archived company = %+v", archived)
+	}
+
+	for filter, want := range map[string]string{
+		company.ArchivedExclude: "TCS",
+		company.ArchivedInclude: "Infosys,TCS",
+		company.ArchivedOnly:    "Infosys",
+	} {
+		got := names(mustQuery(t, repo, company.ListQuery{Archived: filter, Sort: "company_name"}).Companies)
+		if strings.Join(got, ",") != want {
+			t.Errorf("archived=%q lists %v, want %s", filter, got, want)
+		}
+	}
+	if got, err := repo.ListCompaniesByUsername("alice"); err != nil || len(got) != 1 {
+		t.Errorf("ListCompaniesByUsername(alice) = %v, %v, want [TCS]", names(got), err)
+	}
+	if _, err := repo.GetCompany(infosys.ID); err != nil {
+		t.Errorf("GetCompany of archived company: %v", err)
+	}
+
+	unarchived, err := repo.SetCompanyArchived(infosys.ID, false, "bob")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if unarchived.ArchivedAt != nil || unarchived.ArchivedBy != "" {
+		t.Errorf("unarchived company = %+v", unarchived)
+	}
+	if _, err := repo.SetCompanyArchived(missingID, true, "bob"); !errors.Is(err, company.ErrNotFound) {
+		t.Errorf("archiving a missing company: err = %v, want ErrNotFound", err)
+	}
+}
+
+func testPurgeCompanies(t *testing.T, repo company.Repository) {
+	infosys := mustCreate(t, repo, "Infosys")
+	tcs := mustCreate(t, repo, "TCS")
+	if err := repo.DeleteCompany(infosys.ID, "alice"); err != nil {
+		t.Fatal(err)
+	}
+
+	if purged, err := repo.PurgeCompanies(hoursFromNow(-1)); err != nil || purged != 0 {
+		t.Errorf("purging before the deletion = %d, %v, want 0", purged, err)
+	}
+	if purged, err := repo.PurgeCompanies(hoursFromNow(1)); err != nil || purged != 1 {
+		t.Errorf("purging after the deletion = %d, %v, want 1", purged, err)
+	}
+	if _, err := repo.RestoreCompany(infosys.ID); !errors.Is(err, company.ErrNotFound) {
+		t.Errorf("restoring a purged company: err = %v, want ErrNotFound", err)
+	}
+	if _, err := repo.GetCompany(tcs.ID); err != nil {
+		t.Errorf("purge removed a live company: %v", err)
+	}
+}
@@ -0,0 +1,125 @@
+package repository
+
+import (
+	"backend/companyd/entity"
+	"backend/companyd/usecase/company"
+	"database/sql"
+	"errors"
+
+	"github.com/lib/pq"
+)
+
+const customFieldColumns = `id, key, label, type, options, required, created_at, updated_at`
+
+func scanCustomField(row scanner) (*entity.CustomField, error) {
+	var field entity.CustomField
+	var options []string
+	err := row.Scan(&field.ID, &field.Key, &field.Label, &field.Type, pq.Array(&options), &field.Required, &field.CreatedAt, &field.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	field.Options = options
+	if field.Options == nil {
+		field.Options = []string{}
+	}
+	return &field, nil
+}
+
+func (r *Repository) ListCustomFields() ([]*entity.CustomField, error) {
+	rows, err := r.db.Query(`SELECT ` + customFieldColumns + ` FROM custom_fields ORDER BY created_at, key`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	fields := []*entity.CustomField{}
+	for rows.Next() {
+		field, err := scanCustomField(rows)
+		if err != nil {
+			return nil, err
+		}
+		fields = append(fields, field)
+	}
+	return fields, rows.Err()
+}
+
+func (r *Repository) GetCustomField(id string) (*entity.CustomField, error) {
+	found, err := scanCustomField(r.db.QueryRow(`SELECT `+customFieldColumns+` FROM custom_fields WHERE id = $1`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, company.ErrNotFound
+	}
+	return found, err
+}
+
+func (r *Repository) CreateCustomField(field entity.CustomField) (*entity.CustomField, error) {
+	created, err := scanCustomField(r.db.QueryRow(`
+		INSERT INTO custom_fields (key, label, type, options, required)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (key) DO NOTHING
+		RETURNING `+customFieldColumns,
+		field.Key, field.Label, field.Type, pq.Array(field.Options), field.Required))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, company.ErrFieldExists
+	}
+	return created, err
+}
+
+func (r *Repository) UpdateCustomField(id string, field entity.CustomField) (*entity.CustomField, error) {
+	updated, err := scanCustomField(r.db.QueryRow(`
+		UPDATE custom_fields
+		SET label = $1,
+			options = $2,
+			required = $3,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE id = $4
+		RETURNING `+customFieldColumns,
+		field.Label, pq.Array(field.Options), field.Required, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, company.ErrNotFound
+	}
+	return updated, err
+}
+
+func (r *Repository) DeleteCustomField(id string) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var key string
+	err = tx.QueryRow(`DELETE FROM custom_fields WHERE id = $1 RETURNING key`, id).Scan(&key)
+	if errors.Is(err, sql.ErrNoRows) {
+		return company.ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`UPDATE companies SET custom_fields = custom_fields - $1 WHERE custom_fields ? $1`, key); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *Repository) ListTags() ([]*entity.TagCount, error) {
+	rows, err := r.db.Query(`
+		SELECT tag, COUNT(*)
+		FROM companies, unnest(tags) AS tag
+		WHERE deleted_at IS NULL
+		GROUP BY tag
+		ORDER BY tag`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tags := []*entity.TagCount{}
+	for rows.Next() {
+		var tag entity.TagCount
+		if err := rows.Scan(&tag.Tag, &tag.Companies); err != nil {
+			return nil, err
+		}
+		tags = append(tags, &tag)
+	}
+	return tags, rows.Err()
+}
@@ -0,0 +1,121 @@
+package repository
+
+import (
+	"backend/companyd/entity"
+	"backend/companyd/usecase/company"
+	"database/sql"
+	"errors"
+
+	"github.com/lib/pq"
+)
+
+const driveColumns = `id, company_id, type, season, starts_on, ends_on, roles, package, status, notes, created_at, updated_at`
+
+func scanDrive(row scanner) (*entity.Drive, error) {
+	var drive entity.Drive
+	var startsOn, endsOn sql.NullTime
+	err := row.Scan(&drive.ID, &drive.CompanyID, &drive.Type, &drive.Season, &startsOn, &endsOn, pq.Array(&drive.Roles), &drive.Package, &drive.Status, &drive.Notes, &drive.CreatedAt, &drive.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	drive.StartsOn = nullTime(startsOn)
+	drive.EndsOn = nullTime(endsOn)
+	if drive.Roles == nil {
+		drive.Roles = []string{}
+	}
+	return &drive, nil
+}
+
+func (r *Repository) CreateDrive(drive entity.Drive) (*entity.Drive, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	created, err := insertDrive(tx, drive)
+	if err != nil {
+		return nil, err
+	}
+	return created, tx.Commit()
+}
+
+func insertDrive(tx *sql.Tx, drive entity.Drive) (*entity.Drive, error) {
+	return scanDrive(tx.QueryRow(`
+		INSERT INTO drives (company_id, type, season, starts_on, ends_on, roles, package, status, notes)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING `+driveColumns,
+		drive.CompanyID, drive.Type, drive.Season, drive.StartsOn, drive.EndsOn, pq.Array(drive.Roles), drive.Package, drive.Status, drive.Notes))
+}
+
+func (r *Repository) GetDrive(id string) (*entity.Drive, error) {
+	found, err := scanDrive(r.db.QueryRow(`SELECT `+driveColumns+` FROM drives WHERE id = $1`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, company.ErrNotFound
+	}
+	return found, err
+}
+
+func (r *Repository) ListDrives(filter company.DriveFilter) ([]*entity.Drive, error) {
+	f := &listFilter{}
+	if filter.CompanyID != "" {
+		f.add("company_id = ?", filter.CompanyID)
+	}
+	if filter.Season != "" {
+		f.add("season = ?", filter.Season)
+	}
+	if filter.Type != "" {
+		f.add("type = ?", filter.Type)
+	}
+	if filter.Status != "" {
+		f.add("status = ?", filter.Status)
+	}
+
+	rows, err := r.db.Query(`SELECT `+driveColumns+` FROM drives`+f.where()+` ORDER BY starts_on NULLS LAST, created_at, id`, f.args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	drives := []*entity.Drive{}
+	for rows.Next() {
+		drive, err := scanDrive(rows)
+		if err != nil {
+			return nil, err
+		}
+		drives = append(drives, drive)
+	}
+	return drives, rows.Err()
+}
+
+func (r *Repository) UpdateDrive(id string, drive entity.Drive) (*entity.Drive, error) {
+	updated, err := scanDrive(r.db.QueryRow(`
+		UPDATE drives
+		SET type = $1,
+			season = $2,
+			starts_on = $3,
+			ends_on = $4,
+			roles = $5,
+			package = $6,
+			status = $7,
+			notes = $8,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE id = $9
+		RETURNING `+driveColumns,
+		drive.Type, drive.Season, drive.StartsOn, drive.EndsOn, pq.Array(drive.Roles), drive.Package, drive.Status, drive.Notes, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, company.ErrNotFound
+	}
+	return updated, err
+}
+
+func (r *Repository) DeleteDrive(id string) error {
+	result, err := r.db.Exec(`DELETE FROM drives WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return company.ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,230 @@
+package repository
+
+import (
+	"backend/companyd/entity"
+	"backend/companyd/usecase/company"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+const followUpColumns = `id, company_id, officer, due_at, note, status, completed_by, completed_at, reminded_at, created_at, updated_at`
+
+const notificationColumns = `id, recipient, COALESCE(follow_up_id::text, ''), COALESCE(company_id::text, ''), message, read, created_at`
+
+func scanFollowUp(row scanner) (*entity.FollowUp, error) {
+	var followUp entity.FollowUp
+	var completedAt, remindedAt sql.NullTime
+	err := row.Scan(&followUp.ID, &followUp.CompanyID, &followUp.Officer, &followUp.DueAt, &followUp.Note, &followUp.Status, &followUp.CompletedBy, &completedAt, &remindedAt, &followUp.CreatedAt, &followUp.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	followUp.DueAt = followUp.DueAt.UTC()
+	followUp.CompletedAt = nullTime(completedAt)
+	followUp.RemindedAt = nullTime(remindedAt)
+	return &followUp, nil
+}
+
+func scanNotification(row scanner) (*entity.Notification, error) {
+	var notification entity.Notification
+	err := row.Scan(&notification.ID, &notification.Recipient, &notification.FollowUpID, &notification.CompanyID, &notification.Message, &notification.Read, &notification.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &notification, nil
+}
+
+func nullTime(t sql.NullTime) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	utc := t.Time.UTC()
+	return &utc
+}
+
+func (r *Repository) CreateFollowUp(followUp entity.FollowUp) (*entity.FollowUp, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	created, err := insertFollowUp(tx, followUp)
+	if err != nil {
+		return nil, err
+	}
+	return created, tx.Commit()
+}
+
+func insertFollowUp(tx *sql.Tx, followUp entity.FollowUp) (*entity.FollowUp, error) {
+	return scanFollowUp(tx.QueryRow(`
+		INSERT INTO follow_ups (company_id, officer, due_at, note, status)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING `+followUpColumns,
+		followUp.CompanyID, followUp.Officer, followUp.DueAt, followUp.Note, followUp.Status))
+}
+
+func (r *Repository) GetFollowUp(id string) (*entity.FollowUp, error) {
+	found, err := scanFollowUp(r.db.QueryRow(`SELECT `+followUpColumns+` FROM follow_ups WHERE id = $1`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, company.ErrNotFound
+	}
+	return found, err
+}
+
+func (r *Repository) ListFollowUps(filter company.FollowUpFilter) ([]*entity.FollowUp, error) {
+	f := &listFilter{}
+	if filter.CompanyID != "" {
+		f.add("company_id = ?", filter.CompanyID)
+	}
+	if filter.Officer != "" {
+		f.add("officer = ?", filter.Officer)
+	}
+	if filter.Status != "" {
+		f.add("status = ?", filter.Status)
+	}
+	if filter.DueAfter != nil {
+		f.add("due_at >= ?", *filter.DueAfter)
+	}
+	if filter.DueBefore != nil {
+		f.add("due_at < ?", *filter.DueBefore)
+	}
+
+	rows, err := r.db.Query(`SELECT `+followUpColumns+` FROM follow_ups`+f.where()+` ORDER BY due_at, created_at, id`, f.args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	followUps := []*entity.FollowUp{}
+	for rows.Next() {
+		followUp, err := scanFollowUp(rows)
+		if err != nil {
+			return nil, err
+		}
+		followUps = append(followUps, followUp)
+	}
+	return followUps, rows.Err()
+}
+
+func (r *Repository) UpdateFollowUp(id string, followUp entity.FollowUp) (*entity.FollowUp, error) {
+	updated, err := scanFollowUp(r.db.QueryRow(`
+		UPDATE follow_ups
+		SET officer = $1,
+			due_at = $2,
+			note = $3,
+			status = $4,
+			completed_by = $5,
+			completed_at = $6,
+			reminded_at = $7,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE id = $8
+		RETURNING `+followUpColumns,
+		followUp.Officer, followUp.DueAt, followUp.Note, followUp.Status, followUp.CompletedBy, followUp.CompletedAt, followUp.RemindedAt, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, company.ErrNotFound
+	}
+	return updated, err
+}
+
+func (r *Repository) DeleteFollowUp(id string) error {
+	result, err := r.db.Exec(`DELETE FROM follow_ups WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return company.ErrNotFound
+	}
+	return nil
+}
+
+// CreateReminders locks the follow-ups it reminds with SKIP LOCKED, so
+// schedulers on several servers never notify twice for the same one.
+// Follow-ups of companies in the trash are held back until they are restored.
+func (r *Repository) CreateReminders(dueBefore time.Time) ([]*entity.Notification, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.Query(`
+		SELECT `+followUpColumns+`
+		FROM follow_ups
+		WHERE status = $1 AND reminded_at IS NULL AND due_at < $2
+			AND company_id IN (SELECT id FROM companies WHERE deleted_at IS NULL)
+		ORDER BY due_at, created_at, id
+		FOR UPDATE SKIP LOCKED`,
+		company.FollowUpOpen, dueBefore)
+	if err != nil {
+		return nil, err
+	}
+	var due []*entity.FollowUp
+	for rows.Next() {
+		followUp, err := scanFollowUp(rows)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+		due = append(due, followUp)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	sent := []*entity.Notification{}
+	for _, followUp := range due {
+		var name string
+		if err := tx.QueryRow(`SELECT COALESCE(company_name, '') FROM companies WHERE id = $1`, followUp.CompanyID).Scan(&name); err != nil {
+			return nil, err
+		}
+		notification, err := scanNotification(tx.QueryRow(`
+			INSERT INTO notifications (recipient, follow_up_id, company_id, message)
+			VALUES ($1, $2, $3, $4)
+			RETURNING `+notificationColumns,
+			followUp.Officer, followUp.ID, followUp.CompanyID, company.ReminderMessage(name, followUp, now)))
+		if err != nil {
+			return nil, err
+		}
+		if _, err := tx.Exec(`UPDATE follow_ups SET reminded_at = $1 WHERE id = $2`, now, followUp.ID); err != nil {
+			return nil, err
+		}
+		sent = append(sent, notification)
+	}
+	return sent, tx.Commit()
+}
+
+func (r *Repository) ListNotifications(recipient string, unreadOnly bool) ([]*entity.Notification, error) {
+	query := `SELECT ` + notificationColumns + ` FROM notifications WHERE recipient = $1`
+	if unreadOnly {
+		query += ` AND NOT read`
+	}
+	rows, err := r.db.Query(query+` ORDER BY created_at DESC, id`, recipient)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	notifications := []*entity.Notification{}
+	for rows.Next() {
+		notification, err := scanNotification(rows)
+		if err != nil {
+			return nil, err
+		}
+		notifications = append(notifications, notification)
+	}
+	return notifications, rows.Err()
+}
+
+func (r *Repository) MarkNotificationRead(id, recipient string) error {
+	result, err := r.db.Exec(`UPDATE notifications SET read = true WHERE id = $1 AND recipient = $2`, id, recipient)
+	if err != nil {
+		return err
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return company.ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"backend/companyd/entity"
+	"backend/companyd/usecase/company"
+	"database/sql"
+)
+
+const interactionColumns = `id, company_id, type, occurred_at, officer, outcome, notes, next_step, created_at`
+
+func scanInteraction(row scanner) (*entity.Interaction, error) {
+	var interaction entity.Interaction
+	err := row.Scan(&interaction.ID, &interaction.CompanyID, &interaction.Type, &interaction.OccurredAt, &interaction.Officer, &interaction.Outcome, &interaction.Notes, &interaction.NextStep, &interaction.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	interaction.OccurredAt = interaction.OccurredAt.UTC()
+	return &interaction, nil
+}
+
+// CreateInteraction inserts the interaction and moves the company's last
+// interaction forward in the same transaction. It leaves version and
+// updated_at alone: logging a call does not edit the company.
+func (r *Repository) CreateInteraction(interaction entity.Interaction) (*entity.Interaction, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	created, err := insertInteraction(tx, interaction)
+	if err != nil {
+		return nil, err
+	}
+	return created, tx.Commit()
+}
+
+func insertInteraction(tx *sql.Tx, interaction entity.Interaction) (*entity.Interaction, error) {
+	created, err := scanInteraction(tx.QueryRow(`
+		INSERT INTO interactions (company_id, type, occurred_at, officer, outcome, notes, next_step)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING `+interactionColumns,
+		interaction.CompanyID, interaction.Type, interaction.OccurredAt, interaction.Officer, interaction.Outcome, interaction.Notes, interaction.NextStep))
+	if err != nil {
+		return nil, err
+	}
+	_, err = tx.Exec(`
+		UPDATE companies
+		SET last_interaction_at = $1, last_interaction_outcome = $2
+		WHERE id = $3 AND (last_interaction_at IS NULL OR last_interaction_at <= $1)`,
+		created.OccurredAt, created.Outcome, created.CompanyID)
+	return created, err
+}
+
+func (r *Repository) ListInteractions(filter company.InteractionFilter) ([]*entity.Interaction, error) {
+	f := &listFilter{}
+	if filter.CompanyID != "" {
+		f.add("company_id = ?", filter.CompanyID)
+	}
+	if filter.Officer != "" {
+		f.add("officer = ?", filter.Officer)
+	}
+	if filter.Type != "" {
+		f.add("type = ?", filter.Type)
+	}
+	if filter.Since != nil {
+		f.add("occurred_at >= ?", *filter.Since)
+	}
+	if filter.Until != nil {
+		f.add("occurred_at < ?", *filter.Until)
+	}
+
+	rows, err := r.db.Query(`SELECT `+interactionColumns+` FROM interactions`+f.where()+` ORDER BY occurred_at DESC, created_at DESC, id`, f.args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	interactions := []*entity.Interaction{}
+	for rows.Next() {
+		interaction, err := scanInteraction(rows)
+		if err != nil {
+			return nil, err
+		}
+		interactions = append(interactions, interaction)
+	}
+	return interactions, rows.Err()
+}
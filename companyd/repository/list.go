@@ -0,0 +1,168 @@
+package repository
+
+import (
+	"backend/companyd/entity"
+	"backend/companyd/usecase/company"
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+// sortExpression returns the SQL a listing is ordered by. Nullable text
+// columns are coalesced so that keyset comparisons never meet a NULL.
+func sortExpression(key string) string {
+	switch key {
+	case "is_contacted", "created_at", "updated_at":
+		return key
+	case "package":
+		return "COALESCE(package_amount, -1)"
+	case "last_interaction_at":
+		// The zero time.Time, matching company.SortValue.
+		return "COALESCE(last_interaction_at, '0001-01-01T00:00:00Z'::timestamptz)"
+	default:
+		return "COALESCE(" + key + ", '')"
+	}
+}
+
+// listFilter accumulates WHERE clauses and their positional arguments.
+type listFilter struct {
+	clauses []string
+	args    []interface{}
+}
+
+func (f *listFilter) add(clause string, arg interface{}) {
+	f.args = append(f.args, arg)
+	f.clauses = append(f.clauses, strings.ReplaceAll(clause, "?", "$"+strconv.Itoa(len(f.args))))
+}
+
+// require adds a clause that takes no arguments.
+func (f *listFilter) require(clause string) {
+	f.clauses = append(f.clauses, clause)
+}
+
+func (f *listFilter) where() string {
+	if len(f.clauses) == 0 {
+		return ""
+	}
+	return " WHERE " + strings.Join(f.clauses, " AND ")
+}
+
+func queryFilter(q company.ListQuery) *listFilter {
+	f := &listFilter{}
+	f.require("deleted_at IS NULL")
+	switch q.Archived {
+	case company.ArchivedExclude:
+		f.require("archived_at IS NULL")
+	case company.ArchivedOnly:
+		f.require("archived_at IS NOT NULL")
+	}
+	if q.Drive != "" {
+		f.add("drive = ?", q.Drive)
+	}
+	if q.TypeOfDrive != "" {
+		f.add("type_of_drive = ?", q.TypeOfDrive)
+	}
+	if q.IsContacted != nil {
+		f.add("is_contacted = ?", *q.IsContacted)
+	}
+	if q.PipelineStatus != "" {
+		f.add("pipeline_status = ?", q.PipelineStatus)
+	}
+	if q.Season != "" {
+		f.add("season = ?", q.Season)
+	}
+	if q.Officer != "" {
+		f.add(officerFilter("?"), q.Officer)
+	}
+	if len(q.Tags) > 0 {
+		f.add("tags @> ?", pq.Array(q.Tags))
+	}
+	for _, field := range q.Fields {
+		// Containment matches a number however it was written, and an
+		// option among those of a multi_select field.
+		value := field.Value
+		if field.Multi {
+			value = []interface{}{value}
+		}
+		document, _ := json.Marshal(map[string]interface{}{field.Key: value})
+		f.add("custom_fields @> ?::jsonb", string(document))
+	}
+	if currency := company.PackageBoundsCurrency(q); currency != "" {
+		f.add("package_currency = ?", currency)
+	}
+	if q.PackageMin != nil {
+		f.add("package_amount >= ?", *q.PackageMin)
+	}
+	if q.PackageMax != nil {
+		f.add("package_amount <= ?", *q.PackageMax)
+	}
+	if q.PackageNeedsReview != nil {
+		f.add("package_needs_review = ?", *q.PackageNeedsReview)
+	}
+	if q.CreatedAfter != nil {
+		f.add("created_at >= ?", *q.CreatedAfter)
+	}
+	if q.CreatedBefore != nil {
+		f.add("created_at < ?", *q.CreatedBefore)
+	}
+	if q.UpdatedAfter != nil {
+		f.add("updated_at >= ?", *q.UpdatedAfter)
+	}
+	if q.UpdatedBefore != nil {
+		f.add("updated_at < ?", *q.UpdatedBefore)
+	}
+	if q.LastInteractionAfter != nil {
+		f.add("last_interaction_at >= ?", *q.LastInteractionAfter)
+	}
+	if q.LastInteractionBefore != nil {
+		f.add("(last_interaction_at IS NULL OR last_interaction_at < ?)", *q.LastInteractionBefore)
+	}
+	return f
+}
+
+func (r *Repository) QueryCompanies(q company.ListQuery) (*company.CompanyPage, error) {
+	sortKey := q.Sort
+	if sortKey == "" {
+		sortKey = company.DefaultSort
+	}
+	expr := sortExpression(sortKey)
+	direction, comparison := "ASC", ">"
+	if q.Desc {
+		direction, comparison = "DESC", "<"
+	}
+
+	f := queryFilter(q)
+	page := &company.CompanyPage{Companies: []*entity.Company{}}
+	if err := r.db.QueryRow(`SELECT COUNT(*) FROM companies`+f.where(), f.args...).Scan(&page.Total); err != nil {
+		return nil, err
+	}
+
+	if q.After != nil {
+		f.args = append(f.args, q.After.Value, q.After.ID)
+		f.clauses = append(f.clauses, "("+expr+", id) "+comparison+" ($"+strconv.Itoa(len(f.args)-1)+", $"+strconv.Itoa(len(f.args))+")")
+	}
+	query := `SELECT ` + companyColumns + ` FROM companies` + f.where() + ` ORDER BY ` + expr + ` ` + direction + `, id ` + direction
+	if q.Limit > 0 {
+		// Fetch one extra row to learn whether there is a next page.
+		query += ` LIMIT ` + strconv.Itoa(q.Limit+1)
+	}
+
+	rows, err := r.db.Query(query, f.args...)
+	if err != nil {
+		return nil, err
+	}
+	companies, err := scanCompanies(rows)
+	if err != nil {
+		return nil, err
+	}
+	if q.Limit > 0 && len(companies) > q.Limit {
+		companies = companies[:q.Limit]
+		page.NextCursor = company.EncodeCursor(q, companies[len(companies)-1])
+	}
+	if companies != nil {
+		page.Companies = companies
+	}
+	return page, nil
+}
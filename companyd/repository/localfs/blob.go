@@ -0,0 +1,91 @@
+// Package localfs keeps attachment contents as files under a directory on
+// the server, implementing company.BlobStore.
+package localfs
+
+import (
+	"backend/companyd/usecase/company"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// BlobStore stores each key as a file of the same relative path under its
+// directory.
+type BlobStore struct {
+	dir string
+}
+
+// NewBlobStore returns a store under dir, creating the directory if needed.
+func NewBlobStore(dir string) (*BlobStore, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, fmt.Errorf("creating attachment directory: %w", err)
+	}
+	return &BlobStore{dir: dir}, nil
+}
+
+// path maps a key to its file, refusing keys that would leave the
+// directory.
+func (s *BlobStore) path(key string) (string, error) {
+	if key == "" || strings.Contains(key, `\`) || path.IsAbs(key) || path.Clean(key) != key || key == ".." || strings.HasPrefix(key, "../") {
+		return "", fmt.Errorf("invalid blob key %q", key)
+	}
+	return filepath.Join(s.dir, filepath.FromSlash(key)), nil
+}
+
+// Put writes to a temporary file next to the blob and renames it into
+// place, so readers never see a partial blob.
+func (s *BlobStore) Put(key string, r io.Reader) error {
+	name, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(name), 0o750); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(name), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), name)
+}
+
+func (s *BlobStore) Get(key string) (io.ReadCloser, error) {
+	name, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(name)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, company.ErrNotFound
+	}
+	return f, err
+}
+
+func (s *BlobStore) Delete(key string) error {
+	name, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(name); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package localfs
+
+import (
+	"backend/companyd/usecase/company"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var _ company.BlobStore = (*BlobStore)(nil)
+
+func readBlob(t *testing.T, store *BlobStore, key string) string {
+	t.Helper()
+	contents, err := store.Get(key)
+	if err != nil {
+		t.Fatalf("Get(%q): %v", key, err)
+	}
+	defer contents.Close()
+	data, err := io.ReadAll(contents)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestBlobStore(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "attachments")
+	store, err := NewBlobStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := store.Put("company/jd", strings.NewReader("first")); err != nil {
+		t.Fatal(err)
+	}
+	if got := readBlob(t, store, "company/jd"); got != "first" {
+		t.Errorf("contents = %q, want first", got)
+	}
+	if err := store.Put("company/jd", strings.NewReader("second")); err != nil {
+		t.Fatal(err)
+	}
+	if got := readBlob(t, store, "company/jd"); got != "second" {
+		t.Errorf("replaced contents = %q, want second", got)
+	}
+	entries, err := os.ReadDir(filepath.Join(dir, "company"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("directory holds %d files, want only the blob", len(entries))
+	}
+
+	if err := store.Delete("company/jd"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Get("company/jd"); !errors.Is(err, company.ErrNotFound) {
+		t.Errorf("deleted blob: err = %v, want ErrNotFound", err)
+	}
+	if err := store.Delete("company/jd"); err != nil {
+		t.Errorf("deleting a missing blob: %v", err)
+	}
+}
+
+type failingReader struct{}
+
+func (failingReader) Read([]byte) (int, error) {
+	return 0, errors.New("connection reset")
+}
+
+func TestBlobStoreFailedPut(t *testing.T) {
+	store, err := NewBlobStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Put("company/jd", io.MultiReader(strings.NewReader("partial"), failingReader{})); err == nil {
+		t.Fatal("Put succeeded with a failing reader")
+	}
+	if _, err := store.Get("company/jd"); !errors.Is(err, company.ErrNotFound) {
+		t.Errorf("failed put left a blob: err = %v", err)
+	}
+}
+
+func TestBlobStoreRejectsEscapingKeys(t *testing.T) {
+	store, err := NewBlobStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{"", "../secret", "company/../../secret", "/etc/passwd", `company\..\secret`, "company/./jd", ".."} {
+		if err := store.Put(key, strings.NewReader("x")); err == nil {
+			t.Errorf("Put(%q) succeeded", key)
+		}
+		if _, err := store.Get(key); err == nil || errors.Is(err, company.ErrNotFound) {
+			t.Errorf("Get(%q): err = %v, want an invalid key", key, err)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package memory
+
+import (
+	"backend/companyd/entity"
+	"backend/companyd/usecase/company"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func (r *Repository) CreateAttachment(attachment entity.Attachment) (*entity.Attachment, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	attachment.ID = uuid.NewString()
+	attachment.CreatedAt = r.timestamp()
+	stored := attachment
+	r.attachments = append(r.attachments, &stored)
+	copied := stored
+	return &copied, nil
+}
+
+func (r *Repository) GetAttachment(id string) (*entity.Attachment, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, attachment := range r.attachments {
+		if attachment.ID == id {
+			copied := *attachment
+			return &copied, nil
+		}
+	}
+	return nil, company.ErrNotFound
+}
+
+func (r *Repository) ListAttachments(companyID string) ([]*entity.Attachment, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	attachments := r.attachmentsWhere(func(a *entity.Attachment) bool { return a.CompanyID == companyID })
+	sort.SliceStable(attachments, func(i, j int) bool {
+		return after(attachments[i].CreatedAt, attachments[j].CreatedAt)
+	})
+	return attachments, nil
+}
+
+func (r *Repository) ListTrashedAttachments(deletedBefore time.Time) ([]*entity.Attachment, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	expired := map[string]bool{}
+	for _, c := range r.companies {
+		if c.DeletedAt != nil && c.DeletedAt.Before(deletedBefore) {
+			expired[c.ID] = true
+		}
+	}
+	return r.attachmentsWhere(func(a *entity.Attachment) bool { return expired[a.CompanyID] }), nil
+}
+
+func (r *Repository) DeleteAttachment(id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, attachment := range r.attachments {
+		if attachment.ID == id {
+			r.attachments = append(r.attachments[:i], r.attachments[i+1:]...)
+			return nil
+		}
+	}
+	return company.ErrNotFound
+}
+
+// attachmentsWhere copies the attachments that match, oldest first. Callers
+// hold r.mu.
+func (r *Repository) attachmentsWhere(match func(*entity.Attachment) bool) []*entity.Attachment {
+	attachments := []*entity.Attachment{}
+	for _, attachment := range r.attachments {
+		if match(attachment) {
+			copied := *attachment
+			attachments = append(attachments, &copied)
+		}
+	}
+	return attachments
+}
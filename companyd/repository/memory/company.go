@@ -0,0 +1,609 @@
+package memory
+
+import (
+	"backend/companyd/entity"
+	"backend/companyd/repository/pgtypes"
+	"backend/companyd/usecase/company"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Repository is an in-memory implementation of company.Repository. It mirrors
+// the behaviour of the Postgres repository closely enough to be used in tests
+// and local development without a database.
+type Repository struct {
+	mu            sync.Mutex
+	companies     []*entity.Company
+	temps         []*entity.CompanyTemp
+	events        []*entity.Event
+	contacts      []*entity.Contact
+	drives        []*entity.Drive
+	followUps     []*entity.FollowUp
+	notifications []*entity.Notification
+	interactions  []*entity.Interaction
+	revisions     []*entity.CompanyRevision
+	transitions   []*entity.StatusTransition
+	customFields  []*entity.CustomField
+	attachments   []*entity.Attachment
+	// seasons are the seasons set active or rolled over into, by name, and
+	// activeSeason the one set active.
+	seasons      map[string]bool
+	activeSeason string
+	// users maps usernames to IDs and roles to their roles; assignments
+	// holds each company's officers, primary first.
+	users       map[string]string
+	roles       map[string]string
+	assignments map[string][]*entity.OfficerAssignment
+	now         func() time.Time
+}
+
+var _ company.Repository = (*Repository)(nil)
+
+func NewCompanyRepository() *Repository {
+	return &Repository{now: time.Now}
+}
+
+// timestamp formats times the same way database/sql renders a TIMESTAMPTZ
+// scanned into a string.
+func (r *Repository) timestamp() string {
+	return r.now().UTC().Format(time.RFC3339Nano)
+}
+
+func (r *Repository) CreateCompany(companyName, companyAddress, drive, typeOfDrive, followUp, isContacted, remarks, contactDetails, hr1Details, hr2Details, pkg string, assignedOfficer []string, compensation entity.Compensation, customFields entity.CustomValues, tags []string, season string) (*entity.Company, error) {
+	contacted, err := pgtypes.ParseBool(isContacted)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.checkOfficers(assignedOfficer); err != nil {
+		return nil, err
+	}
+	now := r.timestamp()
+	company := &entity.Company{
+		ID:             uuid.NewString(),
+		CompanyName:    companyName,
+		CompanyAddress: companyAddress,
+		Drive:          drive,
+		TypeOfDrive:    typeOfDrive,
+		FollowUp:       followUp,
+		IsContacted:    contacted,
+		PipelineStatus: company.InitialStage(contacted),
+		Season:         season,
+		Remarks:        remarks,
+		ContactDetails: contactDetails,
+		HR1Details:     hr1Details,
+		HR2Details:     hr2Details,
+		Package:        pkg,
+		Compensation:   compensation.Copy(),
+		CustomFields:   customFields.Copy(),
+		Tags:           copyStrings(tags),
+		Version:        1,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+	r.setOfficers(company, assignedOfficer, "")
+	r.companies = append(r.companies, company)
+	r.recordTransition(company.ID, "", company.PipelineStatus, "")
+	r.recordCreated(company)
+	return copyCompany(company), nil
+}
+
+func (r *Repository) ImportCompanies(companies []entity.CompanySnapshot, season, importedBy string) ([]*entity.Company, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	change := entity.CompanyChange{ChangedBy: importedBy, Source: company.RevisionImport}
+	for _, c := range companies {
+		if err := r.checkOfficers(c.AssignedOfficer); err != nil {
+			return nil, err
+		}
+	}
+	now := r.timestamp()
+	created := make([]*entity.Company, 0, len(companies))
+	for _, c := range companies {
+		imported := &entity.Company{ID: uuid.NewString(), Season: season, Version: 1, CreatedAt: now, UpdatedAt: now}
+		c.Update().ApplyTo(imported)
+		imported.IsContacted = c.IsContacted
+		imported.PipelineStatus = c.PipelineStatus
+		if imported.PipelineStatus == "" {
+			imported.PipelineStatus = company.InitialStage(c.IsContacted)
+		}
+		r.setOfficers(imported, imported.AssignedOfficer, importedBy)
+		r.companies = append(r.companies, imported)
+		r.recordTransition(imported.ID, "", imported.PipelineStatus, importedBy)
+		r.recordRevision(imported, change)
+		created = append(created, copyCompany(imported))
+	}
+	return created, nil
+}
+
+func (r *Repository) GetCompany(id string) (*entity.Company, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	found := r.findCompany(id)
+	if found == nil {
+		return nil, company.ErrNotFound
+	}
+	return copyCompany(found), nil
+}
+
+func (r *Repository) DeleteCompany(id string, deletedBy string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if target := r.findCompany(id); target != nil {
+		deletedAt := r.now().UTC()
+		target.DeletedAt = &deletedAt
+		target.DeletedBy = deletedBy
+	}
+	return nil
+}
+
+func (r *Repository) RestoreCompany(id string) (*entity.Company, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, c := range r.companies {
+		if c.ID == id && c.DeletedAt != nil {
+			c.DeletedAt = nil
+			c.DeletedBy = ""
+			return copyCompany(c), nil
+		}
+	}
+	return nil, company.ErrNotFound
+}
+
+func (r *Repository) SetCompanyArchived(id string, archived bool, by string) (*entity.Company, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	target := r.findCompany(id)
+	if target == nil {
+		return nil, company.ErrNotFound
+	}
+	switch {
+	case !archived:
+		target.ArchivedAt = nil
+		target.ArchivedBy = ""
+	case target.ArchivedAt == nil:
+		archivedAt := r.now().UTC()
+		target.ArchivedAt = &archivedAt
+		target.ArchivedBy = by
+	default:
+		target.ArchivedBy = by
+	}
+	return copyCompany(target), nil
+}
+
+func (r *Repository) ListDeletedCompanies() ([]*entity.Company, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// Walk backwards so that ties keep the newest deletion first.
+	companies := []*entity.Company{}
+	for i := len(r.companies) - 1; i >= 0; i-- {
+		if r.companies[i].DeletedAt != nil {
+			companies = append(companies, copyCompany(r.companies[i]))
+		}
+	}
+	sort.SliceStable(companies, func(i, j int) bool {
+		return companies[i].DeletedAt.After(*companies[j].DeletedAt)
+	})
+	return companies, nil
+}
+
+func (r *Repository) PurgeCompanies(deletedBefore time.Time) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var expired []string
+	for _, c := range r.companies {
+		if c.DeletedAt != nil && c.DeletedAt.Before(deletedBefore) {
+			expired = append(expired, c.ID)
+		}
+	}
+	for _, id := range expired {
+		r.purge(id)
+	}
+	return len(expired), nil
+}
+
+// purge removes a company with everything that references it. Callers hold
+// r.mu.
+func (r *Repository) purge(id string) {
+	for i, company := range r.companies {
+		if company.ID == id {
+			r.companies = append(r.companies[:i], r.companies[i+1:]...)
+			break
+		}
+	}
+
+	// Pending proposals are removed explicitly; contacts, drives,
+	// attachments, follow_ups, notifications, interactions, company_history
+	// and company_status_transitions are ON DELETE CASCADE.
+	keptTemps := r.temps[:0]
+	for _, temp := range r.temps {
+		if temp.CompanyID != id {
+			keptTemps = append(keptTemps, temp)
+		}
+	}
+	r.temps = keptTemps
+	kept := r.contacts[:0]
+	for _, contact := range r.contacts {
+		if contact.CompanyID != id {
+			kept = append(kept, contact)
+		}
+	}
+	r.contacts = kept
+	keptDrives := r.drives[:0]
+	for _, drive := range r.drives {
+		if drive.CompanyID != id {
+			keptDrives = append(keptDrives, drive)
+		}
+	}
+	r.drives = keptDrives
+	keptAttachments := r.attachments[:0]
+	for _, attachment := range r.attachments {
+		if attachment.CompanyID != id {
+			keptAttachments = append(keptAttachments, attachment)
+		}
+	}
+	r.attachments = keptAttachments
+	keptFollowUps := r.followUps[:0]
+	for _, followUp := range r.followUps {
+		if followUp.CompanyID != id {
+			keptFollowUps = append(keptFollowUps, followUp)
+		}
+	}
+	r.followUps = keptFollowUps
+	keptNotifications := r.notifications[:0]
+	for _, notification := range r.notifications {
+		if notification.CompanyID != id {
+			keptNotifications = append(keptNotifications, notification)
+		}
+	}
+	r.notifications = keptNotifications
+	keptInteractions := r.interactions[:0]
+	for _, interaction := range r.interactions {
+		if interaction.CompanyID != id {
+			keptInteractions = append(keptInteractions, interaction)
+		}
+	}
+	r.interactions = keptInteractions
+	keptRevisions := r.revisions[:0]
+	for _, revision := range r.revisions {
+		if revision.CompanyID != id {
+			keptRevisions = append(keptRevisions, revision)
+		}
+	}
+	r.revisions = keptRevisions
+	keptTransitions := r.transitions[:0]
+	for _, transition := range r.transitions {
+		if transition.CompanyID != id {
+			keptTransitions = append(keptTransitions, transition)
+		}
+	}
+	r.transitions = keptTransitions
+	delete(r.assignments, id)
+	// events.company_id and companies.carried_from are ON DELETE SET NULL.
+	for _, event := range r.events {
+		if event.CompanyID == id {
+			event.CompanyID = ""
+		}
+	}
+	for _, company := range r.companies {
+		if company.CarriedFrom == id {
+			company.CarriedFrom = ""
+		}
+	}
+}
+
+func (r *Repository) ListCompanies() ([]*entity.Company, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var companies []*entity.Company
+	for _, company := range r.companies {
+		if company.DeletedAt == nil {
+			companies = append(companies, copyCompany(company))
+		}
+	}
+	return companies, nil
+}
+
+func (r *Repository) UpdateCompany(id string, version int, update entity.CompanyUpdate, change entity.CompanyChange) (*entity.Company, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	target := r.findCompany(id)
+	if target == nil {
+		return nil, company.ErrNotFound
+	}
+	if target.Version != version {
+		return nil, company.ErrVersionMismatch
+	}
+	if update.AssignedOfficer != nil {
+		if err := r.checkOfficers(*update.AssignedOfficer); err != nil {
+			return nil, err
+		}
+	}
+	r.applyUpdate(target, update, change)
+	target.Version++
+	target.UpdatedAt = r.timestamp()
+	r.recordRevision(target, change)
+	return copyCompany(target), nil
+}
+
+func (r *Repository) ListCompaniesByUsername(username string) ([]*entity.Company, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var companies []*entity.Company
+	for _, company := range r.companies {
+		if company.DeletedAt != nil || company.ArchivedAt != nil {
+			continue
+		}
+		for _, officer := range company.AssignedOfficer {
+			if officer == username {
+				companies = append(companies, copyCompany(company))
+				break
+			}
+		}
+	}
+	return companies, nil
+}
+
+func (r *Repository) CreateCompanyTemp(companyId, companyName, companyAddress, drive, typeOfDrive, followUp, isContacted, remarks, contactDetails, hr1Details, hr2Details, pkg string, assignedOfficer []string, createdBy string) (*entity.CompanyTemp, error) {
+	contacted, err := pgtypes.ParseBool(isContacted)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	target := r.findCompany(companyId)
+	if target == nil {
+		return nil, fmt.Errorf("company %q does not exist", companyId)
+	}
+
+	now := r.timestamp()
+	temp := &entity.CompanyTemp{
+		ID:              uuid.NewString(),
+		CompanyID:       companyId,
+		CompanyName:     companyName,
+		CompanyAddress:  companyAddress,
+		Drive:           drive,
+		TypeOfDrive:     typeOfDrive,
+		FollowUp:        followUp,
+		IsContacted:     contacted,
+		Remarks:         remarks,
+		ContactDetails:  contactDetails,
+		HR1Details:      hr1Details,
+		HR2Details:      hr2Details,
+		Package:         pkg,
+		AssignedOfficer: copyStrings(assignedOfficer),
+		Status:          company.ProposalPending,
+		BaseVersion:     target.Version,
+		Season:          target.Season,
+		CreatedBy:       createdBy,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+	r.temps = append(r.temps, temp)
+	return copyCompanyTemp(temp), nil
+}
+
+func (r *Repository) ListCompanyTemps(season string) ([]*entity.CompanyTemp, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var temps []*entity.CompanyTemp
+	for i := len(r.temps) - 1; i >= 0; i-- {
+		if season == "" || r.temps[i].Season == season {
+			temps = append(temps, copyCompanyTemp(r.temps[i]))
+		}
+	}
+	// ORDER BY created_at DESC; newest insertions first on ties.
+	sort.SliceStable(temps, func(i, j int) bool {
+		return after(temps[i].CreatedAt, temps[j].CreatedAt)
+	})
+	return temps, nil
+}
+
+func (r *Repository) GetCompanyTemp(id string) (*entity.CompanyTemp, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	temp := r.findCompanyTemp(id)
+	if temp == nil {
+		return nil, company.ErrNotFound
+	}
+	return copyCompanyTemp(temp), nil
+}
+
+func (r *Repository) UpdateCompanyTempStatus(id string, from, to string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	temp := r.findCompanyTemp(id)
+	if temp == nil || temp.Status != from {
+		return company.ErrNotFound
+	}
+	temp.Status = to
+	temp.UpdatedAt = r.timestamp()
+	return nil
+}
+
+func (r *Repository) ApproveCompanyTemp(id string, approvedBy string) error {
+	// Holding the lock for the whole operation gives the same all-or-nothing
+	// behaviour as the Postgres transaction.
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	temp := r.findCompanyTemp(id)
+	if temp == nil {
+		return company.ErrNotFound
+	}
+	if temp.Status != company.ProposalPending {
+		return company.NewProposalTransitionError(temp.Status, company.ProposalApproved)
+	}
+
+	target := r.findCompany(temp.CompanyID)
+	if target == nil {
+		return company.ErrNotFound
+	}
+	if temp.BaseVersion != 0 && temp.BaseVersion != target.Version {
+		return company.ErrStaleProposal
+	}
+	if err := r.checkOfficers(temp.AssignedOfficer); err != nil {
+		return err
+	}
+
+	target.CompanyName = temp.CompanyName
+	target.CompanyAddress = temp.CompanyAddress
+	target.Drive = temp.Drive
+	target.TypeOfDrive = temp.TypeOfDrive
+	target.FollowUp = temp.FollowUp
+	target.Remarks = temp.Remarks
+	target.ContactDetails = temp.ContactDetails
+	target.HR1Details = temp.HR1Details
+	target.HR2Details = temp.HR2Details
+	// Proposals carry only the package text; keep the structured package
+	// unless the text changed.
+	if temp.Package != target.Package {
+		target.Package = temp.Package
+		target.Compensation = company.ParseCompensation(temp.Package)
+	}
+	r.setOfficers(target, temp.AssignedOfficer, approvedBy)
+	target.Version++
+	target.UpdatedAt = r.timestamp()
+	r.recordRevision(target, entity.CompanyChange{ChangedBy: approvedBy, Source: company.RevisionProposal, ProposalID: temp.ID})
+
+	// Keep the proposal as approved. The company's other pending proposals
+	// were made against the version this one replaced.
+	now := r.timestamp()
+	for _, t := range r.temps {
+		switch {
+		case t.ID == id:
+			t.Status = company.ProposalApproved
+		case t.CompanyID == temp.CompanyID && t.Status == company.ProposalPending:
+			t.Status = company.ProposalSuperseded
+		default:
+			continue
+		}
+		t.UpdatedAt = now
+	}
+	return nil
+}
+
+func (r *Repository) CreateEvent(date, eventType, title, description, companyID, createdBy string) (*entity.Event, error) {
+	parsed, err := pgtypes.ParseTimestamp(date)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	event := &entity.Event{
+		ID:          uuid.NewString(),
+		Date:        parsed.UTC().Format(time.RFC3339Nano),
+		Type:        eventType,
+		Title:       title,
+		Description: description,
+		CompanyID:   companyID,
+		Season:      company.SeasonAt(parsed),
+		CreatedBy:   createdBy,
+		CreatedAt:   r.timestamp(),
+	}
+	r.events = append(r.events, event)
+	copied := *event
+	return &copied, nil
+}
+
+func (r *Repository) ListEvents(season string) ([]*entity.Event, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var events []*entity.Event
+	for _, event := range r.events {
+		if season != "" && event.Season != season {
+			continue
+		}
+		copied := *event
+		events = append(events, &copied)
+	}
+	// ORDER BY date DESC
+	sort.SliceStable(events, func(i, j int) bool {
+		return after(events[i].Date, events[j].Date)
+	})
+	return events, nil
+}
+
+// findCompany does not find companies in the trash.
+func (r *Repository) findCompany(id string) *entity.Company {
+	for _, company := range r.companies {
+		if company.ID == id && company.DeletedAt == nil {
+			return company
+		}
+	}
+	return nil
+}
+
+func (r *Repository) findCompanyTemp(id string) *entity.CompanyTemp {
+	for _, temp := range r.temps {
+		if temp.ID == id {
+			return temp
+		}
+	}
+	return nil
+}
+
+// after compares two timestamps produced by this repository.
+func after(a, b string) bool {
+	ta, _ := time.Parse(time.RFC3339Nano, a)
+	tb, _ := time.Parse(time.RFC3339Nano, b)
+	return ta.After(tb)
+}
+
+func copyStrings(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return append([]string{}, values...)
+}
+
+func copyCompany(company *entity.Company) *entity.Company {
+	copied := *company
+	copied.AssignedOfficer = copyStrings(company.AssignedOfficer)
+	copied.Compensation = company.Compensation.Copy()
+	copied.CustomFields = company.CustomFields.Copy()
+	copied.Tags = copyStrings(company.Tags)
+	copied.LastInteractionAt = copyTime(company.LastInteractionAt)
+	copied.ArchivedAt = copyTime(company.ArchivedAt)
+	copied.DeletedAt = copyTime(company.DeletedAt)
+	return &copied
+}
+
+func copyTime(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	copied := *t
+	return &copied
+}
+
+func copyCompanyTemp(temp *entity.CompanyTemp) *entity.CompanyTemp {
+	copied := *temp
+	copied.AssignedOfficer = copyStrings(temp.AssignedOfficer)
+	return &copied
+}
@@ -0,0 +1,17 @@
+package memory
+
+import (
+	"backend/companyd/repository/contract"
+	"backend/companyd/usecase/company"
+	"testing"
+)
+
+func TestRepositoryContract(t *testing.T) {
+	contract.Run(t, func(t *testing.T) company.Repository {
+		repo := NewCompanyRepository()
+		for _, officer := range contract.Officers {
+			repo.AddUser(officer, company.OfficerUserRole)
+		}
+		return repo
+	})
+}
@@ -0,0 +1,113 @@
+package memory
+
+import (
+	"backend/companyd/entity"
+	"backend/companyd/usecase/company"
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+func (r *Repository) CreateContact(contact entity.Contact) (*entity.Contact, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.timestamp()
+	contact.ID = uuid.NewString()
+	contact.CreatedAt = now
+	contact.UpdatedAt = now
+	if contact.IsPrimary {
+		r.demotePrimary(contact.CompanyID, contact.ID)
+	}
+	r.contacts = append(r.contacts, &contact)
+	copied := contact
+	return &copied, nil
+}
+
+func (r *Repository) GetContact(id string) (*entity.Contact, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	found := r.findContact(id)
+	if found == nil {
+		return nil, company.ErrNotFound
+	}
+	copied := *found
+	return &copied, nil
+}
+
+func (r *Repository) ListContacts(filter company.ContactFilter) ([]*entity.Contact, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	contacts := []*entity.Contact{}
+	for _, contact := range r.contacts {
+		if len(filter.CompanyIDs) > 0 && !containsString(filter.CompanyIDs, contact.CompanyID) {
+			continue
+		}
+		if filter.Email != "" && !strings.EqualFold(contact.Email, filter.Email) {
+			continue
+		}
+		copied := *contact
+		contacts = append(contacts, &copied)
+	}
+	sort.SliceStable(contacts, func(i, j int) bool {
+		if contacts[i].IsPrimary != contacts[j].IsPrimary {
+			return contacts[i].IsPrimary
+		}
+		return after(contacts[j].CreatedAt, contacts[i].CreatedAt)
+	})
+	return contacts, nil
+}
+
+func (r *Repository) UpdateContact(id string, contact entity.Contact) (*entity.Contact, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	target := r.findContact(id)
+	if target == nil {
+		return nil, company.ErrNotFound
+	}
+	contact.ID = target.ID
+	contact.CreatedAt = target.CreatedAt
+	contact.UpdatedAt = r.timestamp()
+	if contact.IsPrimary {
+		r.demotePrimary(contact.CompanyID, id)
+	}
+	*target = contact
+	return &contact, nil
+}
+
+func (r *Repository) DeleteContact(id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, contact := range r.contacts {
+		if contact.ID == id {
+			r.contacts = append(r.contacts[:i], r.contacts[i+1:]...)
+			return nil
+		}
+	}
+	return company.ErrNotFound
+}
+
+// demotePrimary clears the primary flag on every other contact of the
+// company, since a company has at most one primary contact.
+func (r *Repository) demotePrimary(companyID, keepID string) {
+	for _, contact := range r.contacts {
+		if contact.CompanyID == companyID && contact.ID != keepID && contact.IsPrimary {
+			contact.IsPrimary = false
+			contact.UpdatedAt = r.timestamp()
+		}
+	}
+}
+
+func (r *Repository) findContact(id string) *entity.Contact {
+	for _, contact := range r.contacts {
+		if contact.ID == id {
+			return contact
+		}
+	}
+	return nil
+}
@@ -0,0 +1,121 @@
+package memory
+
+import (
+	"backend/companyd/entity"
+	"backend/companyd/usecase/company"
+	"sort"
+
+	"github.com/google/uuid"
+)
+
+func copyCustomField(field *entity.CustomField) *entity.CustomField {
+	copied := *field
+	copied.Options = copyStrings(field.Options)
+	return &copied
+}
+
+// findCustomField returns the custom field with id, or nil. Callers hold
+// r.mu.
+func (r *Repository) findCustomField(id string) *entity.CustomField {
+	for _, field := range r.customFields {
+		if field.ID == id {
+			return field
+		}
+	}
+	return nil
+}
+
+func (r *Repository) ListCustomFields() ([]*entity.CustomField, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	fields := make([]*entity.CustomField, 0, len(r.customFields))
+	for _, field := range r.customFields {
+		fields = append(fields, copyCustomField(field))
+	}
+	return fields, nil
+}
+
+func (r *Repository) GetCustomField(id string) (*entity.CustomField, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	found := r.findCustomField(id)
+	if found == nil {
+		return nil, company.ErrNotFound
+	}
+	return copyCustomField(found), nil
+}
+
+func (r *Repository) CreateCustomField(field entity.CustomField) (*entity.CustomField, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, existing := range r.customFields {
+		if existing.Key == field.Key {
+			return nil, company.ErrFieldExists
+		}
+	}
+	now := r.timestamp()
+	field.ID = uuid.NewString()
+	field.CreatedAt, field.UpdatedAt = now, now
+	created := copyCustomField(&field)
+	r.customFields = append(r.customFields, created)
+	return copyCustomField(created), nil
+}
+
+func (r *Repository) UpdateCustomField(id string, field entity.CustomField) (*entity.CustomField, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	target := r.findCustomField(id)
+	if target == nil {
+		return nil, company.ErrNotFound
+	}
+	target.Label = field.Label
+	target.Options = copyStrings(field.Options)
+	target.Required = field.Required
+	target.UpdatedAt = r.timestamp()
+	return copyCustomField(target), nil
+}
+
+func (r *Repository) DeleteCustomField(id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, field := range r.customFields {
+		if field.ID != id {
+			continue
+		}
+		r.customFields = append(r.customFields[:i], r.customFields[i+1:]...)
+		for _, c := range r.companies {
+			delete(c.CustomFields, field.Key)
+		}
+		return nil
+	}
+	return company.ErrNotFound
+}
+
+func (r *Repository) ListTags() ([]*entity.TagCount, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	byTag := map[string]*entity.TagCount{}
+	for _, c := range r.companies {
+		if c.DeletedAt != nil {
+			continue
+		}
+		for _, tag := range c.Tags {
+			if byTag[tag] == nil {
+				byTag[tag] = &entity.TagCount{Tag: tag}
+			}
+			byTag[tag].Companies++
+		}
+	}
+	tags := make([]*entity.TagCount, 0, len(byTag))
+	for _, tag := range byTag {
+		tags = append(tags, tag)
+	}
+	sort.Slice(tags, func(i, j int) bool { return tags[i].Tag < tags[j].Tag })
+	return tags, nil
+}
@@ -0,0 +1,114 @@
+package memory
+
+import (
+	"backend/companyd/entity"
+	"backend/companyd/usecase/company"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func (r *Repository) CreateDrive(drive entity.Drive) (*entity.Drive, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.timestamp()
+	drive.ID = uuid.NewString()
+	drive.CreatedAt = now
+	drive.UpdatedAt = now
+	stored := copyDrive(&drive)
+	r.drives = append(r.drives, stored)
+	return copyDrive(stored), nil
+}
+
+func (r *Repository) GetDrive(id string) (*entity.Drive, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	found := r.findDrive(id)
+	if found == nil {
+		return nil, company.ErrNotFound
+	}
+	return copyDrive(found), nil
+}
+
+func (r *Repository) ListDrives(filter company.DriveFilter) ([]*entity.Drive, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	drives := []*entity.Drive{}
+	for _, drive := range r.drives {
+		if filter.Matches(drive) {
+			drives = append(drives, copyDrive(drive))
+		}
+	}
+	// Drives without a start date come last, as NULLS LAST does.
+	sort.SliceStable(drives, func(i, j int) bool {
+		a, b := drives[i].StartsOn, drives[j].StartsOn
+		if a == nil || b == nil {
+			return a != nil && b == nil
+		}
+		if !a.Equal(*b) {
+			return a.Before(*b)
+		}
+		return after(drives[j].CreatedAt, drives[i].CreatedAt)
+	})
+	return drives, nil
+}
+
+func (r *Repository) UpdateDrive(id string, drive entity.Drive) (*entity.Drive, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	target := r.findDrive(id)
+	if target == nil {
+		return nil, company.ErrNotFound
+	}
+	drive.ID = target.ID
+	drive.CompanyID = target.CompanyID
+	drive.CreatedAt = target.CreatedAt
+	drive.UpdatedAt = r.timestamp()
+	*target = *copyDrive(&drive)
+	return copyDrive(target), nil
+}
+
+func (r *Repository) DeleteDrive(id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, drive := range r.drives {
+		if drive.ID == id {
+			r.drives = append(r.drives[:i], r.drives[i+1:]...)
+			return nil
+		}
+	}
+	return company.ErrNotFound
+}
+
+func (r *Repository) findDrive(id string) *entity.Drive {
+	for _, drive := range r.drives {
+		if drive.ID == id {
+			return drive
+		}
+	}
+	return nil
+}
+
+// copyDrive copies a drive so that callers cannot change what is stored,
+// with its dates in UTC as the databases return them.
+func copyDrive(drive *entity.Drive) *entity.Drive {
+	copied := *drive
+	copied.StartsOn = utcTime(drive.StartsOn)
+	copied.EndsOn = utcTime(drive.EndsOn)
+	copied.Roles = append([]string{}, drive.Roles...)
+	return &copied
+}
+
+func utcTime(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	utc := t.UTC()
+	return &utc
+}
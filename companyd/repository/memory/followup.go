@@ -0,0 +1,179 @@
+package memory
+
+import (
+	"backend/companyd/entity"
+	"backend/companyd/usecase/company"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func (r *Repository) CreateFollowUp(followUp entity.FollowUp) (*entity.FollowUp, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.timestamp()
+	followUp.ID = uuid.NewString()
+	followUp.DueAt = followUp.DueAt.UTC()
+	followUp.CreatedAt = now
+	followUp.UpdatedAt = now
+	r.followUps = append(r.followUps, &followUp)
+	copied := followUp
+	return &copied, nil
+}
+
+func (r *Repository) GetFollowUp(id string) (*entity.FollowUp, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	found := r.findFollowUp(id)
+	if found == nil {
+		return nil, company.ErrNotFound
+	}
+	copied := *found
+	return &copied, nil
+}
+
+func (r *Repository) ListFollowUps(filter company.FollowUpFilter) ([]*entity.FollowUp, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	followUps := []*entity.FollowUp{}
+	for _, followUp := range r.followUps {
+		if filter.Matches(followUp) {
+			copied := *followUp
+			followUps = append(followUps, &copied)
+		}
+	}
+	sortFollowUps(followUps)
+	return followUps, nil
+}
+
+// sortFollowUps orders by due time, then creation, like the SQL repositories.
+func sortFollowUps(followUps []*entity.FollowUp) {
+	sort.SliceStable(followUps, func(i, j int) bool {
+		if !followUps[i].DueAt.Equal(followUps[j].DueAt) {
+			return followUps[i].DueAt.Before(followUps[j].DueAt)
+		}
+		return after(followUps[j].CreatedAt, followUps[i].CreatedAt)
+	})
+}
+
+func (r *Repository) UpdateFollowUp(id string, followUp entity.FollowUp) (*entity.FollowUp, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	target := r.findFollowUp(id)
+	if target == nil {
+		return nil, company.ErrNotFound
+	}
+	followUp.ID = target.ID
+	followUp.CompanyID = target.CompanyID
+	followUp.DueAt = followUp.DueAt.UTC()
+	followUp.CreatedAt = target.CreatedAt
+	followUp.UpdatedAt = r.timestamp()
+	*target = followUp
+	return &followUp, nil
+}
+
+func (r *Repository) DeleteFollowUp(id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, followUp := range r.followUps {
+		if followUp.ID == id {
+			r.followUps = append(r.followUps[:i], r.followUps[i+1:]...)
+			// notifications.follow_up_id is ON DELETE CASCADE.
+			kept := r.notifications[:0]
+			for _, notification := range r.notifications {
+				if notification.FollowUpID != id {
+					kept = append(kept, notification)
+				}
+			}
+			r.notifications = kept
+			return nil
+		}
+	}
+	return company.ErrNotFound
+}
+
+func (r *Repository) CreateReminders(dueBefore time.Time) ([]*entity.Notification, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var due []*entity.FollowUp
+	filter := company.FollowUpFilter{Status: company.FollowUpOpen, DueBefore: &dueBefore}
+	for _, followUp := range r.followUps {
+		// Follow-ups of companies in the trash wait until they are restored.
+		if followUp.RemindedAt == nil && filter.Matches(followUp) && r.findCompany(followUp.CompanyID) != nil {
+			due = append(due, followUp)
+		}
+	}
+	sortFollowUps(due)
+
+	now := r.now().UTC()
+	sent := []*entity.Notification{}
+	for _, followUp := range due {
+		name := ""
+		if c := r.findCompany(followUp.CompanyID); c != nil {
+			name = c.CompanyName
+		}
+		notification := &entity.Notification{
+			ID:         uuid.NewString(),
+			Recipient:  followUp.Officer,
+			FollowUpID: followUp.ID,
+			CompanyID:  followUp.CompanyID,
+			Message:    company.ReminderMessage(name, followUp, now),
+			CreatedAt:  r.timestamp(),
+		}
+		r.notifications = append(r.notifications, notification)
+		remindedAt := now
+		followUp.RemindedAt = &remindedAt
+		copied := *notification
+		sent = append(sent, &copied)
+	}
+	return sent, nil
+}
+
+func (r *Repository) ListNotifications(recipient string, unreadOnly bool) ([]*entity.Notification, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// Newest first, walking backwards so that ties keep that order too.
+	notifications := []*entity.Notification{}
+	for i := len(r.notifications) - 1; i >= 0; i-- {
+		notification := r.notifications[i]
+		if notification.Recipient != recipient || (unreadOnly && notification.Read) {
+			continue
+		}
+		copied := *notification
+		notifications = append(notifications, &copied)
+	}
+	sort.SliceStable(notifications, func(i, j int) bool {
+		return after(notifications[i].CreatedAt, notifications[j].CreatedAt)
+	})
+	return notifications, nil
+}
+
+func (r *Repository) MarkNotificationRead(id, recipient string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, notification := range r.notifications {
+		if notification.ID == id && notification.Recipient == recipient {
+			notification.Read = true
+			return nil
+		}
+	}
+	return company.ErrNotFound
+}
+
+func (r *Repository) findFollowUp(id string) *entity.FollowUp {
+	for _, followUp := range r.followUps {
+		if followUp.ID == id {
+			return followUp
+		}
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+package memory
+
+import (
+	"backend/companyd/entity"
+	"backend/companyd/usecase/company"
+
+	"github.com/google/uuid"
+)
+
+func (r *Repository) ListCompanyRevisions(companyID string) ([]*entity.CompanyRevision, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// Revisions are appended as versions are made, so they are in order.
+	revisions := []*entity.CompanyRevision{}
+	for _, revision := range r.revisions {
+		if revision.CompanyID == companyID {
+			revisions = append(revisions, copyRevision(revision))
+		}
+	}
+	return revisions, nil
+}
+
+func (r *Repository) recordCreated(c *entity.Company) {
+	r.recordRevision(c, entity.CompanyChange{Source: company.RevisionCreate})
+}
+
+// recordRevision snapshots c at its current version. Callers hold r.mu.
+func (r *Repository) recordRevision(c *entity.Company, change entity.CompanyChange) {
+	r.revisions = append(r.revisions, &entity.CompanyRevision{
+		ID:            uuid.NewString(),
+		CompanyID:     c.ID,
+		Version:       c.Version,
+		CompanyChange: change,
+		Snapshot:      entity.NewCompanySnapshot(c),
+		CreatedAt:     c.UpdatedAt,
+	})
+}
+
+func copyRevision(revision *entity.CompanyRevision) *entity.CompanyRevision {
+	copied := *revision
+	copied.Snapshot.Compensation = revision.Snapshot.Compensation.Copy()
+	copied.Snapshot.AssignedOfficer = append([]string{}, revision.Snapshot.AssignedOfficer...)
+	return &copied
+}
@@ -0,0 +1,45 @@
+package memory
+
+import (
+	"backend/companyd/entity"
+	"backend/companyd/usecase/company"
+	"sort"
+
+	"github.com/google/uuid"
+)
+
+func (r *Repository) CreateInteraction(interaction entity.Interaction) (*entity.Interaction, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	interaction.ID = uuid.NewString()
+	interaction.OccurredAt = interaction.OccurredAt.UTC()
+	interaction.CreatedAt = r.timestamp()
+	r.interactions = append(r.interactions, &interaction)
+
+	if c := r.findCompany(interaction.CompanyID); c != nil && company.IsLaterInteraction(c, interaction.OccurredAt) {
+		occurredAt := interaction.OccurredAt
+		c.LastInteractionAt = &occurredAt
+		c.LastInteractionOutcome = interaction.Outcome
+	}
+	copied := interaction
+	return &copied, nil
+}
+
+func (r *Repository) ListInteractions(filter company.InteractionFilter) ([]*entity.Interaction, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// Newest first, walking backwards so that ties keep that order too.
+	interactions := []*entity.Interaction{}
+	for i := len(r.interactions) - 1; i >= 0; i-- {
+		if filter.Matches(r.interactions[i]) {
+			copied := *r.interactions[i]
+			interactions = append(interactions, &copied)
+		}
+	}
+	sort.SliceStable(interactions, func(i, j int) bool {
+		return interactions[i].OccurredAt.After(interactions[j].OccurredAt)
+	})
+	return interactions, nil
+}
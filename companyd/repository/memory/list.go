@@ -0,0 +1,148 @@
+package memory
+
+import (
+	"backend/companyd/entity"
+	"backend/companyd/usecase/company"
+	"sort"
+	"strings"
+	"time"
+)
+
+func (r *Repository) QueryCompanies(q company.ListQuery) (*company.CompanyPage, error) {
+	sortKey := q.Sort
+	if sortKey == "" {
+		sortKey = company.DefaultSort
+	}
+
+	r.mu.Lock()
+	var matched []*entity.Company
+	for _, c := range r.companies {
+		if matchesQuery(q, c) {
+			matched = append(matched, copyCompany(c))
+		}
+	}
+	r.mu.Unlock()
+
+	// precedes reports whether the row at (value, id) comes before c, ordering
+	// by the sort value and then by ID in the requested direction.
+	precedes := func(value interface{}, id string, c *entity.Company) bool {
+		cmp := compareValues(value, company.SortValue(sortKey, c))
+		if cmp == 0 {
+			cmp = strings.Compare(id, c.ID)
+		}
+		if q.Desc {
+			return cmp > 0
+		}
+		return cmp < 0
+	}
+	sort.Slice(matched, func(i, j int) bool {
+		return precedes(company.SortValue(sortKey, matched[i]), matched[i].ID, matched[j])
+	})
+
+	page := &company.CompanyPage{Total: len(matched), Companies: []*entity.Company{}}
+	for _, c := range matched {
+		if q.After != nil && !precedes(q.After.Value, q.After.ID, c) {
+			continue
+		}
+		if q.Limit > 0 && len(page.Companies) == q.Limit {
+			page.NextCursor = company.EncodeCursor(q, page.Companies[len(page.Companies)-1])
+			break
+		}
+		page.Companies = append(page.Companies, c)
+	}
+	return page, nil
+}
+
+func matchesQuery(q company.ListQuery, c *entity.Company) bool {
+	if c.DeletedAt != nil || !company.MatchesArchived(q.Archived, c) {
+		return false
+	}
+	if q.Drive != "" && c.Drive != q.Drive {
+		return false
+	}
+	if q.TypeOfDrive != "" && c.TypeOfDrive != q.TypeOfDrive {
+		return false
+	}
+	if q.IsContacted != nil && c.IsContacted != *q.IsContacted {
+		return false
+	}
+	if q.PipelineStatus != "" && c.PipelineStatus != q.PipelineStatus {
+		return false
+	}
+	if q.Season != "" && c.Season != q.Season {
+		return false
+	}
+	if q.Officer != "" && !containsString(c.AssignedOfficer, q.Officer) {
+		return false
+	}
+	for _, tag := range q.Tags {
+		if !containsString(c.Tags, tag) {
+			return false
+		}
+	}
+	for _, field := range q.Fields {
+		if !field.Matches(c.CustomFields) {
+			return false
+		}
+	}
+	if q.PackageMin != nil || q.PackageMax != nil {
+		amount, ok := company.AnnualCTC(c.Compensation)
+		if !ok || c.Compensation.Currency != company.PackageBoundsCurrency(q) || (q.PackageMin != nil && amount < *q.PackageMin) || (q.PackageMax != nil && amount > *q.PackageMax) {
+			return false
+		}
+	}
+	if q.PackageNeedsReview != nil && c.Compensation.NeedsReview != *q.PackageNeedsReview {
+		return false
+	}
+	if q.LastInteractionAfter != nil && (c.LastInteractionAt == nil || c.LastInteractionAt.Before(*q.LastInteractionAfter)) {
+		return false
+	}
+	if q.LastInteractionBefore != nil && c.LastInteractionAt != nil && !c.LastInteractionAt.Before(*q.LastInteractionBefore) {
+		return false
+	}
+	return inWindow(c.CreatedAt, q.CreatedAfter, q.CreatedBefore) && inWindow(c.UpdatedAt, q.UpdatedAfter, q.UpdatedBefore)
+}
+
+// inWindow reports whether stamp falls in [from, until).
+func inWindow(stamp string, from, until *time.Time) bool {
+	t, _ := time.Parse(time.RFC3339Nano, stamp)
+	return (from == nil || !t.Before(*from)) && (until == nil || t.Before(*until))
+}
+
+func containsString(values []string, want string) bool {
+	for _, v := range values {
+		if v == want {
+			return true
+		}
+	}
+	return false
+}
+
+// compareValues compares two sort values of the same type.
+func compareValues(a, b interface{}) int {
+	switch a := a.(type) {
+	case string:
+		return strings.Compare(a, b.(string))
+	case bool:
+		switch b := b.(bool); {
+		case a == b:
+			return 0
+		case b:
+			return -1
+		default:
+			return 1
+		}
+	case float64:
+		b := b.(float64)
+		switch {
+		case a < b:
+			return -1
+		case a > b:
+			return 1
+		}
+		return 0
+	case time.Time:
+		return a.Compare(b.(time.Time))
+	}
+	return 0
+}
@@ -0,0 +1,88 @@
+package memory
+
+import (
+	"backend/companyd/entity"
+	"backend/companyd/usecase/company"
+)
+
+func (r *Repository) MergeCompanies(survivorID, duplicateID string, version int, update entity.CompanyUpdate, change entity.CompanyChange) (*entity.Company, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	survivor := r.findCompany(survivorID)
+	if survivor == nil {
+		return nil, company.ErrNotFound
+	}
+	if survivor.Version != version {
+		return nil, company.ErrVersionMismatch
+	}
+	duplicate := r.findCompany(duplicateID)
+	if duplicate == nil {
+		return nil, company.ErrUnknownCompany
+	}
+	if update.AssignedOfficer != nil {
+		if err := r.checkOfficers(*update.AssignedOfficer); err != nil {
+			return nil, err
+		}
+	}
+
+	survivorHasPrimary := false
+	for _, contact := range r.contacts {
+		if contact.CompanyID == survivorID && contact.IsPrimary {
+			survivorHasPrimary = true
+		}
+	}
+	for _, contact := range r.contacts {
+		if contact.CompanyID == duplicateID {
+			contact.CompanyID = survivorID
+			contact.IsPrimary = contact.IsPrimary && !survivorHasPrimary
+		}
+	}
+	for _, temp := range r.temps {
+		if temp.CompanyID == duplicateID {
+			temp.CompanyID = survivorID
+			temp.BaseVersion = version
+		}
+	}
+	for _, followUp := range r.followUps {
+		if followUp.CompanyID == duplicateID {
+			followUp.CompanyID = survivorID
+		}
+	}
+	for _, notification := range r.notifications {
+		if notification.CompanyID == duplicateID {
+			notification.CompanyID = survivorID
+		}
+	}
+	for _, interaction := range r.interactions {
+		if interaction.CompanyID == duplicateID {
+			interaction.CompanyID = survivorID
+		}
+	}
+	for _, event := range r.events {
+		if event.CompanyID == duplicateID {
+			event.CompanyID = survivorID
+		}
+	}
+	for _, drive := range r.drives {
+		if drive.CompanyID == duplicateID {
+			drive.CompanyID = survivorID
+		}
+	}
+	for _, attachment := range r.attachments {
+		if attachment.CompanyID == duplicateID {
+			attachment.CompanyID = survivorID
+		}
+	}
+	if d, s := duplicate.LastInteractionAt, survivor.LastInteractionAt; d != nil && (s == nil || d.After(*s)) {
+		survivor.LastInteractionAt = copyTime(duplicate.LastInteractionAt)
+		survivor.LastInteractionOutcome = duplicate.LastInteractionOutcome
+	}
+
+	r.applyUpdate(survivor, update, change)
+	survivor.Version++
+	survivor.UpdatedAt = r.timestamp()
+	r.recordRevision(survivor, change)
+	r.purge(duplicateID)
+	return copyCompany(survivor), nil
+}
@@ -0,0 +1,110 @@
+package memory
+
+import (
+	"backend/companyd/entity"
+	"backend/companyd/usecase/company"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AddUser registers a user with role that companies can be assigned to,
+// standing in for the users table, and returns its ID.
+func (r *Repository) AddUser(username, role string) string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if id, ok := r.users[username]; ok {
+		return id
+	}
+	if r.users == nil {
+		r.users = map[string]string{}
+		r.roles = map[string]string{}
+	}
+	id := uuid.NewString()
+	r.users[username] = id
+	r.roles[username] = role
+	return id
+}
+
+// unknownOfficers returns the usernames that are not users. Callers hold
+// r.mu.
+func (r *Repository) unknownOfficers(usernames []string) []string {
+	var unknown []string
+	for _, username := range company.UniqueOfficers(usernames) {
+		if _, ok := r.users[username]; !ok {
+			unknown = append(unknown, username)
+		}
+	}
+	return unknown
+}
+
+// checkOfficers is the UnknownOfficersError, if any, for usernames. Callers
+// hold r.mu.
+func (r *Repository) checkOfficers(usernames []string) error {
+	if unknown := r.unknownOfficers(usernames); len(unknown) > 0 {
+		return company.UnknownOfficersError(unknown)
+	}
+	return nil
+}
+
+// setOfficers makes usernames, which checkOfficers has accepted, the
+// officers of c. Officers who stay assigned keep their assignedAt and
+// assignedBy. Callers hold r.mu.
+func (r *Repository) setOfficers(c *entity.Company, usernames []string, assignedBy string) {
+	usernames = company.UniqueOfficers(usernames)
+	previous := map[string]*entity.OfficerAssignment{}
+	for _, a := range r.assignments[c.ID] {
+		previous[a.Username] = a
+	}
+	now := r.now().UTC().Truncate(time.Microsecond)
+	assignments := make([]*entity.OfficerAssignment, len(usernames))
+	for i, username := range usernames {
+		a := previous[username]
+		if a == nil {
+			a = &entity.OfficerAssignment{UserID: r.users[username], Username: username, AssignedAt: now, AssignedBy: assignedBy}
+		}
+		a.Role = company.OfficerRole(i)
+		assignments[i] = a
+	}
+	if r.assignments == nil {
+		r.assignments = map[string][]*entity.OfficerAssignment{}
+	}
+	r.assignments[c.ID] = assignments
+	c.AssignedOfficer = usernames
+}
+
+func (r *Repository) UnknownOfficers(usernames []string) ([]string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.unknownOfficers(usernames), nil
+}
+
+func (r *Repository) ListCompanyOfficers(companyID string) ([]*entity.OfficerAssignment, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	officers := []*entity.OfficerAssignment{}
+	for _, a := range r.assignments[companyID] {
+		copied := *a
+		officers = append(officers, &copied)
+	}
+	return officers, nil
+}
+
+func (r *Repository) ListOfficerUsernames() ([]string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	usernames := []string{}
+	for username, role := range r.roles {
+		if strings.EqualFold(role, company.OfficerUserRole) {
+			usernames = append(usernames, username)
+		}
+	}
+	sort.Strings(usernames)
+	return usernames, nil
+}
@@ -0,0 +1,59 @@
+package memory
+
+import (
+	"backend/companyd/entity"
+	"backend/companyd/usecase/company"
+	"sort"
+
+	"github.com/google/uuid"
+)
+
+// applyUpdate applies update to c, assigning its officers and recording a
+// change of pipeline status as a transition. Callers hold r.mu.
+func (r *Repository) applyUpdate(c *entity.Company, update entity.CompanyUpdate, change entity.CompanyChange) {
+	previousStatus := c.PipelineStatus
+	update.ApplyTo(c)
+	if update.AssignedOfficer != nil {
+		r.setOfficers(c, *update.AssignedOfficer, change.ChangedBy)
+	}
+	if c.PipelineStatus != previousStatus {
+		r.recordTransition(c.ID, previousStatus, c.PipelineStatus, change.ChangedBy)
+	}
+}
+
+// recordTransition records a company moving from one pipeline stage to
+// another. Callers hold r.mu.
+func (r *Repository) recordTransition(companyID, from, to, by string) {
+	r.transitions = append(r.transitions, &entity.StatusTransition{
+		ID:        uuid.NewString(),
+		CompanyID: companyID,
+		From:      from,
+		To:        to,
+		ChangedBy: by,
+		ChangedAt: r.now().UTC(),
+	})
+}
+
+func (r *Repository) ListStatusTransitions(filter company.StatusTransitionFilter) ([]*entity.StatusTransition, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	transitions := []*entity.StatusTransition{}
+	for _, t := range r.transitions {
+		if filter.CompanyID != "" && t.CompanyID != filter.CompanyID {
+			continue
+		}
+		if filter.Before != nil && !t.ChangedAt.Before(*filter.Before) {
+			continue
+		}
+		if c := r.findCompany(t.CompanyID); filter.Season != "" && (c == nil || c.Season != filter.Season) {
+			continue
+		}
+		copied := *t
+		transitions = append(transitions, &copied)
+	}
+	sort.SliceStable(transitions, func(i, j int) bool {
+		return transitions[i].ChangedAt.Before(transitions[j].ChangedAt)
+	})
+	return transitions, nil
+}
@@ -0,0 +1,19 @@
+package memory
+
+import (
+	"backend/companyd/entity"
+	"backend/companyd/usecase/company"
+)
+
+func (r *Repository) SearchCompanies(q company.SearchQuery) ([]*entity.CompanySearchResult, error) {
+	r.mu.Lock()
+	var visible []*entity.Company
+	for _, c := range r.companies {
+		if c.DeletedAt == nil && (q.Officer == "" || containsString(c.AssignedOfficer, q.Officer)) {
+			visible = append(visible, copyCompany(c))
+		}
+	}
+	r.mu.Unlock()
+
+	return company.MatchCompanies(visible, company.SearchTerms(q.Text), q.Limit), nil
+}
@@ -0,0 +1,107 @@
+package memory
+
+import (
+	"backend/companyd/entity"
+	"backend/companyd/usecase/company"
+	"sort"
+
+	"github.com/google/uuid"
+)
+
+func (r *Repository) ListSeasons() ([]*entity.Season, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	byName := map[string]*entity.Season{}
+	for name := range r.seasons {
+		byName[name] = &entity.Season{Name: name, Active: name == r.activeSeason}
+	}
+	for _, c := range r.companies {
+		if c.Season == "" {
+			continue
+		}
+		season := byName[c.Season]
+		if season == nil {
+			season = &entity.Season{Name: c.Season}
+			byName[c.Season] = season
+		}
+		if c.DeletedAt == nil {
+			season.Companies++
+		}
+	}
+	seasons := []*entity.Season{}
+	for _, season := range byName {
+		seasons = append(seasons, season)
+	}
+	sort.Slice(seasons, func(i, j int) bool { return seasons[i].Name < seasons[j].Name })
+	return seasons, nil
+}
+
+func (r *Repository) SetActiveSeason(season string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.addSeason(season)
+	r.activeSeason = season
+	return nil
+}
+
+// addSeason records a season. Callers hold r.mu.
+func (r *Repository) addSeason(season string) {
+	if r.seasons == nil {
+		r.seasons = map[string]bool{}
+	}
+	r.seasons[season] = true
+}
+
+func (r *Repository) RolloverCompanies(ids []string, season, by string) ([]*entity.Company, error) {
+	// Holding the lock for the whole operation gives the same all-or-nothing
+	// behaviour as the Postgres transaction.
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	originals := make([]*entity.Company, len(ids))
+	for i, id := range ids {
+		if originals[i] = r.findCompany(id); originals[i] == nil {
+			return nil, company.ErrUnknownCompany
+		}
+	}
+
+	r.addSeason(season)
+	change := entity.CompanyChange{ChangedBy: by, Source: company.RevisionRollover}
+	now := r.timestamp()
+	carried := make([]*entity.Company, 0, len(ids))
+	for _, original := range originals {
+		copied := copyCompany(original)
+		copied.ID = uuid.NewString()
+		copied.Season = season
+		copied.CarriedFrom = original.ID
+		copied.FollowUp = ""
+		copied.IsContacted = false
+		copied.PipelineStatus = company.StageProspect
+		copied.Version = 1
+		copied.LastInteractionAt = nil
+		copied.LastInteractionOutcome = ""
+		copied.ArchivedAt, copied.ArchivedBy = nil, ""
+		copied.CreatedAt, copied.UpdatedAt = now, now
+		r.setOfficers(copied, original.AssignedOfficer, by)
+		r.companies = append(r.companies, copied)
+
+		var contacts []*entity.Contact
+		for _, contact := range r.contacts {
+			if contact.CompanyID == original.ID {
+				c := *contact
+				c.ID = uuid.NewString()
+				c.CompanyID = copied.ID
+				c.CreatedAt, c.UpdatedAt = now, now
+				contacts = append(contacts, &c)
+			}
+		}
+		r.contacts = append(r.contacts, contacts...)
+
+		r.recordTransition(copied.ID, "", copied.PipelineStatus, by)
+		r.recordRevision(copied, change)
+		carried = append(carried, copyCompany(copied))
+	}
+	return carried, nil
+}
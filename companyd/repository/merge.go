@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"backend/companyd/entity"
+	"backend/companyd/usecase/company"
+	"database/sql"
+	"errors"
+)
+
+func (r *Repository) MergeCompanies(survivorID, duplicateID string, version int, update entity.CompanyUpdate, change entity.CompanyChange) (*entity.Company, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// Lock the duplicate so nothing is added to it while its records move.
+	var lastInteractionAt sql.NullTime
+	var lastInteractionOutcome string
+	err = tx.QueryRow(`SELECT last_interaction_at, last_interaction_outcome FROM companies WHERE id = $1 AND deleted_at IS NULL FOR UPDATE`, duplicateID).
+		Scan(&lastInteractionAt, &lastInteractionOutcome)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, company.ErrUnknownCompany
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	moves := []struct {
+		query string
+		args  []interface{}
+	}{
+		// The unique primary index allows one primary contact per company.
+		{`UPDATE contacts SET is_primary = false
+			WHERE company_id = $1 AND EXISTS (SELECT 1 FROM contacts WHERE company_id = $2 AND is_primary)`, []interface{}{duplicateID, survivorID}},
+		{`UPDATE contacts SET company_id = $2 WHERE company_id = $1`, []interface{}{duplicateID, survivorID}},
+		{`UPDATE companies_temp SET company_id = $2, base_version = $3 WHERE company_id = $1`, []interface{}{duplicateID, survivorID, version}},
+		{`UPDATE follow_ups SET company_id = $2 WHERE company_id = $1`, []interface{}{duplicateID, survivorID}},
+		{`UPDATE notifications SET company_id = $2 WHERE company_id = $1`, []interface{}{duplicateID, survivorID}},
+		{`UPDATE interactions SET company_id = $2 WHERE company_id = $1`, []interface{}{duplicateID, survivorID}},
+		{`UPDATE events SET company_id = $2 WHERE company_id = $1`, []interface{}{duplicateID, survivorID}},
+		{`UPDATE drives SET company_id = $2 WHERE company_id = $1`, []interface{}{duplicateID, survivorID}},
+		{`UPDATE attachments SET company_id = $2 WHERE company_id = $1`, []interface{}{duplicateID, survivorID}},
+	}
+	for _, m := range moves {
+		if _, err := tx.Exec(m.query, m.args...); err != nil {
+			return nil, err
+		}
+	}
+	if lastInteractionAt.Valid {
+		_, err = tx.Exec(`
+			UPDATE companies
+			SET last_interaction_at = $1, last_interaction_outcome = $2
+			WHERE id = $3 AND (last_interaction_at IS NULL OR last_interaction_at < $1)`,
+			lastInteractionAt.Time, lastInteractionOutcome, survivorID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	merged, err := r.updateCompany(tx, survivorID, version, update, change)
+	if err != nil {
+		return nil, err
+	}
+	// The duplicate's history goes with it.
+	if _, err := tx.Exec(`DELETE FROM companies WHERE id = $1`, duplicateID); err != nil {
+		return nil, err
+	}
+	return merged, tx.Commit()
+}
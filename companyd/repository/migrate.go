@@ -0,0 +1,402 @@
+package repository
+
+import (
+	"backend/companyd/entity"
+	"backend/companyd/usecase/company"
+	"database/sql"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// dataMigrations rewrite existing rows after init.sql has created the schema.
+// Each runs once per database, recorded by name in schema_migrations; append
+// new ones, never reorder or rename.
+var dataMigrations = []struct {
+	name string
+	run  func(tx *sql.Tx) error
+}{
+	{"0001_import_contacts", importContacts},
+	{"0002_structure_packages", structurePackages},
+	{"0003_import_follow_ups", importFollowUps},
+	{"0004_import_remarks", importRemarks},
+	{"0005_record_company_history", recordBaselines},
+	{"0006_assign_officers", assignOfficers},
+	{"0007_import_drives", importDrives},
+	{"0008_record_pipeline_status", recordPipelineStatus},
+	{"0009_assign_seasons", assignSeasons},
+	{"0010_normalize_proposal_statuses", normalizeProposalStatuses},
+}
+
+// Migrate runs the data migrations that have not been applied yet. Several
+// servers may start at once: the first to insert a migration's row holds its
+// lock, and the others find the row already there and skip it.
+func Migrate(db *sql.DB) error {
+	for _, m := range dataMigrations {
+		if err := runDataMigration(db, m.name, m.run); err != nil {
+			return fmt.Errorf("migration %s: %w", m.name, err)
+		}
+	}
+	return nil
+}
+
+func runDataMigration(db *sql.DB, name string, run func(tx *sql.Tx) error) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`INSERT INTO schema_migrations (name) VALUES ($1) ON CONFLICT (name) DO NOTHING`, name)
+	if err != nil {
+		return err
+	}
+	if n, err := result.RowsAffected(); err != nil || n == 0 {
+		return err
+	}
+	if err := run(tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// importContacts parses the free-text HR fields of companies that have no
+// contacts yet into contact rows. The text fields themselves are kept.
+func importContacts(tx *sql.Tx) error {
+	rows, err := tx.Query(`SELECT ` + legacyCompanyColumns + ` FROM companies WHERE NOT EXISTS (SELECT 1 FROM contacts WHERE contacts.company_id = companies.id)`)
+	if err != nil {
+		return err
+	}
+	var companies []*entity.Company
+	for rows.Next() {
+		c, err := scanCompany(rows)
+		if err != nil {
+			rows.Close()
+			return err
+		}
+		companies = append(companies, c)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, c := range companies {
+		for _, contact := range company.ImportContacts(c) {
+			if _, err := insertContact(tx, contact); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// structurePackages parses every free-text package into the structured
+// compensation columns. Packages it cannot read are flagged with
+// package_needs_review for someone to enter by hand.
+func structurePackages(tx *sql.Tx) error {
+	rows, err := tx.Query(`SELECT id, COALESCE(package, '') FROM companies`)
+	if err != nil {
+		return err
+	}
+	packages := map[string]string{}
+	for rows.Next() {
+		var id, pkg string
+		if err := rows.Scan(&id, &pkg); err != nil {
+			rows.Close()
+			return err
+		}
+		packages[id] = pkg
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for id, pkg := range packages {
+		if err := setCompensation(tx, id, company.ParseCompensation(pkg)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// importFollowUps turns the free-text follow_up of companies that have no
+// follow-ups yet into dated follow-ups. The text field itself is kept.
+func importFollowUps(tx *sql.Tx) error {
+	rows, err := tx.Query(`SELECT ` + legacyCompanyColumns + ` FROM companies WHERE NOT EXISTS (SELECT 1 FROM follow_ups WHERE follow_ups.company_id = companies.id)`)
+	if err != nil {
+		return err
+	}
+	var companies []*entity.Company
+	for rows.Next() {
+		c, err := scanCompany(rows)
+		if err != nil {
+			rows.Close()
+			return err
+		}
+		companies = append(companies, c)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for _, c := range companies {
+		if followUp, ok := company.ImportFollowUp(c, now); ok {
+			if _, err := insertFollowUp(tx, followUp); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// importRemarks starts the interaction timeline of companies that have none
+// with their free-text remarks. The remarks field itself is kept.
+func importRemarks(tx *sql.Tx) error {
+	rows, err := tx.Query(`SELECT ` + legacyCompanyColumns + ` FROM companies WHERE NOT EXISTS (SELECT 1 FROM interactions WHERE interactions.company_id = companies.id)`)
+	if err != nil {
+		return err
+	}
+	var companies []*entity.Company
+	for rows.Next() {
+		c, err := scanCompany(rows)
+		if err != nil {
+			rows.Close()
+			return err
+		}
+		companies = append(companies, c)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, c := range companies {
+		if interaction, ok := company.ImportRemarks(c); ok {
+			if _, err := insertInteraction(tx, interaction); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// recordBaselines starts the history of companies that have none with their
+// current version, so later changes can be diffed against it.
+func recordBaselines(tx *sql.Tx) error {
+	rows, err := tx.Query(`SELECT ` + legacyCompanyColumns + ` FROM companies WHERE NOT EXISTS (SELECT 1 FROM company_history WHERE company_history.company_id = companies.id)`)
+	if err != nil {
+		return err
+	}
+	var companies []*entity.Company
+	for rows.Next() {
+		c, err := scanCompany(rows)
+		if err != nil {
+			rows.Close()
+			return err
+		}
+		companies = append(companies, c)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, c := range companies {
+		if err := recordRevision(tx, c, entity.CompanyChange{Source: company.RevisionBaseline}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// assignOfficers copies the assigned_officer array of companies that have no
+// company_officers rows into that table, dated when the company was last
+// updated. Usernames that are not users are logged and dropped; the array
+// itself is left as it was.
+func assignOfficers(tx *sql.Tx) error {
+	rows, err := tx.Query(`
+		SELECT id, assigned_officer, updated_at FROM companies
+		WHERE cardinality(assigned_officer) > 0
+		AND NOT EXISTS (SELECT 1 FROM company_officers WHERE company_officers.company_id = companies.id)`)
+	if err != nil {
+		return err
+	}
+	type assignment struct {
+		companyID string
+		officers  []string
+		at        time.Time
+	}
+	var assignments []assignment
+	for rows.Next() {
+		var a assignment
+		if err := rows.Scan(&a.companyID, pq.Array(&a.officers), &a.at); err != nil {
+			rows.Close()
+			return err
+		}
+		assignments = append(assignments, a)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, a := range assignments {
+		officers := company.UniqueOfficers(a.officers)
+		ids, err := userIDs(tx, officers)
+		if err != nil {
+			return err
+		}
+		position := 0
+		for _, username := range officers {
+			userID, ok := ids[username]
+			if !ok {
+				log.Printf("Company %s: officer %q is not a user; not assigned", a.companyID, username)
+				continue
+			}
+			_, err := tx.Exec(`
+				INSERT INTO company_officers (company_id, user_id, role, position, assigned_at, assigned_by)
+				VALUES ($1, $2, $3, $4, $5, $6)`,
+				a.companyID, userID, company.OfficerRole(position), position, a.at, company.MigrationAssigner)
+			if err != nil {
+				return err
+			}
+			position++
+		}
+	}
+	return nil
+}
+
+// importDrives turns the drive and type_of_drive of companies that have no
+// drives yet into one drive each. The text fields themselves are kept.
+func importDrives(tx *sql.Tx) error {
+	rows, err := tx.Query(`
+		SELECT id, COALESCE(drive, ''), COALESCE(type_of_drive, ''), COALESCE(package, '') FROM companies
+		WHERE NOT EXISTS (SELECT 1 FROM drives WHERE drives.company_id = companies.id)`)
+	if err != nil {
+		return err
+	}
+	var drives []entity.Drive
+	for rows.Next() {
+		var id, drive, typeOfDrive, pkg string
+		if err := rows.Scan(&id, &drive, &typeOfDrive, &pkg); err != nil {
+			rows.Close()
+			return err
+		}
+		if imported, ok := company.ImportDrive(id, drive, typeOfDrive, pkg); ok {
+			drives = append(drives, imported)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, drive := range drives {
+		if _, err := insertDrive(tx, drive); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// recordPipelineStatus moves contacted companies that have no status
+// transitions yet to the contacted stage, and starts their transitions with
+// the stage they are at, dated when the company was created.
+func recordPipelineStatus(tx *sql.Tx) error {
+	noTransitions := `NOT EXISTS (SELECT 1 FROM company_status_transitions WHERE company_status_transitions.company_id = companies.id)`
+	_, err := tx.Exec(`UPDATE companies SET pipeline_status = $1 WHERE is_contacted AND `+noTransitions, company.StageContacted)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(`
+		INSERT INTO company_status_transitions (company_id, from_status, to_status, changed_by, changed_at)
+		SELECT id, '', pipeline_status, '', COALESCE(created_at, CURRENT_TIMESTAMP) FROM companies
+		WHERE ` + noTransitions)
+	return err
+}
+
+// assignSeasons files companies, proposals and events that have no season
+// yet under one. A company belongs to the season of its latest drive, or
+// else the season it was created in; a proposal to its company's season and
+// an event to the season its date falls in.
+func assignSeasons(tx *sql.Tx) error {
+	rows, err := tx.Query(`
+		SELECT id, COALESCE(created_at, CURRENT_TIMESTAMP),
+			COALESCE((SELECT MAX(season) FROM drives WHERE drives.company_id = companies.id AND season <> ''), '')
+		FROM companies WHERE season = ''`)
+	if err != nil {
+		return err
+	}
+	seasons := map[string]string{}
+	for rows.Next() {
+		var id, driveSeason string
+		var createdAt time.Time
+		if err := rows.Scan(&id, &createdAt, &driveSeason); err != nil {
+			rows.Close()
+			return err
+		}
+		seasons[id] = driveSeason
+		if driveSeason == "" {
+			seasons[id] = company.SeasonAt(createdAt)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	for id, season := range seasons {
+		if _, err := tx.Exec(`UPDATE companies SET season = $2 WHERE id = $1`, id, season); err != nil {
+			return err
+		}
+	}
+
+	_, err = tx.Exec(`
+		UPDATE companies_temp SET season = companies.season
+		FROM companies WHERE companies.id = companies_temp.company_id AND companies_temp.season = ''`)
+	if err != nil {
+		return err
+	}
+
+	rows, err = tx.Query(`SELECT id, date FROM events WHERE season = ''`)
+	if err != nil {
+		return err
+	}
+	events := map[string]string{}
+	for rows.Next() {
+		var id string
+		var date time.Time
+		if err := rows.Scan(&id, &date); err != nil {
+			rows.Close()
+			return err
+		}
+		events[id] = company.SeasonAt(date)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	for id, season := range events {
+		if _, err := tx.Exec(`UPDATE events SET season = $2 WHERE id = $1`, id, season); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// normalizeProposalStatuses lowercases the statuses clients could set to
+// anything before proposals had a lifecycle, treating a missing one as
+// pending. Any other status is left as it is and allows no actions.
+func normalizeProposalStatuses(tx *sql.Tx) error {
+	_, err := tx.Exec(`
+		UPDATE companies_temp SET status = CASE
+			WHEN TRIM(COALESCE(status, '')) = '' THEN 'pending'
+			ELSE LOWER(TRIM(status))
+		END`)
+	return err
+}
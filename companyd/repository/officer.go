@@ -0,0 +1,141 @@
+package repository
+
+import (
+	"backend/companyd/entity"
+	"backend/companyd/usecase/company"
+	"database/sql"
+
+	"github.com/lib/pq"
+)
+
+// assignedOfficerColumn reads Company.AssignedOfficer from company_officers,
+// primary officer first.
+const assignedOfficerColumn = `COALESCE((SELECT array_agg(u.username::text ORDER BY o.position) FROM company_officers o JOIN users u ON u.id = o.user_id WHERE o.company_id = companies.id), '{}')`
+
+// officerFilter matches the companies assigned to the username in
+// placeholder.
+func officerFilter(placeholder string) string {
+	return `EXISTS (SELECT 1 FROM company_officers o JOIN users u ON u.id = o.user_id WHERE o.company_id = companies.id AND u.username = ` + placeholder + `)`
+}
+
+// userIDs looks up the users with the given usernames.
+func userIDs(q interface {
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+}, usernames []string) (map[string]string, error) {
+	ids := map[string]string{}
+	if len(usernames) == 0 {
+		return ids, nil
+	}
+	rows, err := q.Query(`SELECT id, username FROM users WHERE username = ANY($1)`, pq.Array(usernames))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var id, username string
+		if err := rows.Scan(&id, &username); err != nil {
+			return nil, err
+		}
+		ids[username] = id
+	}
+	return ids, rows.Err()
+}
+
+// setOfficers makes usernames the officers of a company, the first as its
+// primary officer. Officers who stay assigned keep their assigned_at and
+// assigned_by.
+func setOfficers(tx *sql.Tx, companyID string, usernames []string, assignedBy string) error {
+	usernames = company.UniqueOfficers(usernames)
+	ids, err := userIDs(tx, usernames)
+	if err != nil {
+		return err
+	}
+	var unknown, assigned []string
+	for _, username := range usernames {
+		if id, ok := ids[username]; ok {
+			assigned = append(assigned, id)
+		} else {
+			unknown = append(unknown, username)
+		}
+	}
+	if len(unknown) > 0 {
+		return company.UnknownOfficersError(unknown)
+	}
+
+	if _, err := tx.Exec(`DELETE FROM company_officers WHERE company_id = $1 AND NOT (user_id = ANY($2::uuid[]))`, companyID, pq.Array(assigned)); err != nil {
+		return err
+	}
+	// Demote everyone first: a company has at most one primary officer.
+	if _, err := tx.Exec(`UPDATE company_officers SET role = $2 WHERE company_id = $1`, companyID, company.OfficerSecondary); err != nil {
+		return err
+	}
+	for i, userID := range assigned {
+		_, err := tx.Exec(`
+			INSERT INTO company_officers (company_id, user_id, role, position, assigned_by)
+			VALUES ($1, $2, $3, $4, $5)
+			ON CONFLICT (company_id, user_id) DO UPDATE SET role = EXCLUDED.role, position = EXCLUDED.position`,
+			companyID, userID, company.OfficerRole(i), i, assignedBy)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// UnknownOfficers returns the usernames that are not users.
+func (r *Repository) UnknownOfficers(usernames []string) ([]string, error) {
+	ids, err := userIDs(r.db, usernames)
+	if err != nil {
+		return nil, err
+	}
+	var unknown []string
+	for _, username := range company.UniqueOfficers(usernames) {
+		if _, ok := ids[username]; !ok {
+			unknown = append(unknown, username)
+		}
+	}
+	return unknown, nil
+}
+
+func (r *Repository) ListCompanyOfficers(companyID string) ([]*entity.OfficerAssignment, error) {
+	rows, err := r.db.Query(`
+		SELECT o.user_id, u.username, o.role, o.assigned_at, o.assigned_by
+		FROM company_officers o JOIN users u ON u.id = o.user_id
+		WHERE o.company_id = $1
+		ORDER BY o.position`, companyID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	officers := []*entity.OfficerAssignment{}
+	for rows.Next() {
+		var officer entity.OfficerAssignment
+		if err := rows.Scan(&officer.UserID, &officer.Username, &officer.Role, &officer.AssignedAt, &officer.AssignedBy); err != nil {
+			return nil, err
+		}
+		officers = append(officers, &officer)
+	}
+	return officers, rows.Err()
+}
+
+func (r *Repository) ListOfficerUsernames() ([]string, error) {
+	rows, err := r.db.Query(`
+		SELECT username FROM users
+		WHERE lower(role) = lower($1)
+		ORDER BY username`, company.OfficerUserRole)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	usernames := []string{}
+	for rows.Next() {
+		var username string
+		if err := rows.Scan(&username); err != nil {
+			return nil, err
+		}
+		usernames = append(usernames, username)
+	}
+	return usernames, rows.Err()
+}
@@ -0,0 +1,41 @@
+// Package pgtypes parses values the way Postgres does, so that repositories
+// which are not backed by Postgres accept and reject the same input.
+package pgtypes
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ParseBool accepts the same spellings Postgres accepts for a BOOLEAN.
+func ParseBool(value string) (bool, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "t", "true", "y", "yes", "on", "1":
+		return true, nil
+	case "f", "false", "n", "no", "off", "0":
+		return false, nil
+	}
+	return false, fmt.Errorf("invalid input syntax for type boolean: %q", value)
+}
+
+var timestampLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05Z07:00",
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02",
+}
+
+// ParseTimestamp parses the common input formats of a TIMESTAMPTZ column.
+// Values without a zone are taken as UTC.
+func ParseTimestamp(value string) (time.Time, error) {
+	for _, layout := range timestampLayouts {
+		if t, err := time.Parse(layout, strings.TrimSpace(value)); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid input syntax for type timestamp with time zone: %s", strconv.Quote(value))
+}
@@ -0,0 +1,48 @@
+package repository
+
+import (
+	"backend/companyd/entity"
+	"backend/companyd/usecase/company"
+	"database/sql"
+)
+
+const transitionColumns = `id, company_id, from_status, to_status, changed_by, changed_at`
+
+// recordTransition records, in tx, a company moving from one pipeline stage
+// to another.
+func recordTransition(tx *sql.Tx, companyID, from, to, by string) error {
+	_, err := tx.Exec(`
+		INSERT INTO company_status_transitions (company_id, from_status, to_status, changed_by)
+		VALUES ($1, $2, $3, $4)`,
+		companyID, from, to, by)
+	return err
+}
+
+func (r *Repository) ListStatusTransitions(filter company.StatusTransitionFilter) ([]*entity.StatusTransition, error) {
+	f := &listFilter{}
+	if filter.CompanyID != "" {
+		f.add("company_id = ?", filter.CompanyID)
+	}
+	if filter.Season != "" {
+		f.add("company_id IN (SELECT id FROM companies WHERE season = ? AND deleted_at IS NULL)", filter.Season)
+	}
+	if filter.Before != nil {
+		f.add("changed_at < ?", *filter.Before)
+	}
+
+	rows, err := r.db.Query(`SELECT `+transitionColumns+` FROM company_status_transitions`+f.where()+` ORDER BY changed_at, id`, f.args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	transitions := []*entity.StatusTransition{}
+	for rows.Next() {
+		var t entity.StatusTransition
+		if err := rows.Scan(&t.ID, &t.CompanyID, &t.From, &t.To, &t.ChangedBy, &t.ChangedAt); err != nil {
+			return nil, err
+		}
+		transitions = append(transitions, &t)
+	}
+	return transitions, rows.Err()
+}
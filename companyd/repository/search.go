@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"backend/companyd/entity"
+	"backend/companyd/usecase/company"
+	"html"
+	"strconv"
+	"strings"
+)
+
+// searchDocument is the text indexed by idx_companies_search_trgm in
+// init.sql. It must stay identical to the index expression for the trigram
+// index to be used.
+const searchDocument = `(coalesce(company_name, '') || ' ' || coalesce(company_address, '') || ' ' || coalesce(remarks, '') || ' ' || coalesce(contact_details, '') || ' ' || coalesce(hr1_details, '') || ' ' || coalesce(hr2_details, ''))`
+
+// ts_headline does not escape the document, so matches are delimited with
+// control characters and turned into <mark> tags after escaping in Go.
+const (
+	markStart = "\x02"
+	markStop  = "\x03"
+)
+
+const headlineOptions = `StartSel=` + markStart + `, StopSel=` + markStop + `, MaxWords=20, MinWords=5, MaxFragments=2, FragmentDelimiter=" … "`
+
+func escapeSnippet(snippet string) string {
+	escaped := html.EscapeString(snippet)
+	escaped = strings.ReplaceAll(escaped, markStart, "<mark>")
+	return strings.ReplaceAll(escaped, markStop, "</mark>")
+}
+
+// prefixQuery turns search terms into a tsquery that requires every term,
+// each matched as a prefix. Terms contain only letters and digits.
+func prefixQuery(terms []string) string {
+	parts := make([]string, len(terms))
+	for i, term := range terms {
+		parts[i] = term + ":*"
+	}
+	return strings.Join(parts, " & ")
+}
+
+// SearchCompanies ranks full-text matches with ts_rank_cd over the weighted
+// search_vector column. When nothing matches, it falls back to pg_trgm word
+// similarity so that misspelled queries still find something; the <% operator
+// uses pg_trgm.word_similarity_threshold, which defaults to
+// company.FuzzyThreshold.
+func (r *Repository) SearchCompanies(q company.SearchQuery) ([]*entity.CompanySearchResult, error) {
+	terms := company.SearchTerms(q.Text)
+
+	args := []interface{}{prefixQuery(terms), q.Limit}
+	visibility := ""
+	if q.Officer != "" {
+		args = append(args, q.Officer)
+		visibility = ` AND ` + officerFilter("$"+strconv.Itoa(len(args)))
+	}
+
+	results, err := r.searchRows(`
+		SELECT `+companyColumns+`, ts_rank_cd(search_vector, query) AS rank, ts_headline('english', `+searchDocument+`, query, '`+headlineOptions+`'), false
+		FROM companies, to_tsquery('english', $1) AS query
+		WHERE search_vector @@ query AND deleted_at IS NULL`+visibility+`
+		ORDER BY rank DESC, company_name, id
+		LIMIT $2`, args...)
+	if err != nil || len(results) > 0 {
+		return results, err
+	}
+
+	args[0] = strings.Join(terms, " ")
+	return r.searchRows(`
+		SELECT `+companyColumns+`, word_similarity($1, `+searchDocument+`) AS rank, coalesce(company_name, ''), true
+		FROM companies
+		WHERE $1 <% `+searchDocument+` AND deleted_at IS NULL`+visibility+`
+		ORDER BY rank DESC, company_name, id
+		LIMIT $2`, args...)
+}
+
+func (r *Repository) searchRows(query string, args ...interface{}) ([]*entity.CompanySearchResult, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var results []*entity.CompanySearchResult
+	for rows.Next() {
+		var result entity.CompanySearchResult
+		c, err := scanCompany(searchScanner{rows, &result})
+		if err != nil {
+			return nil, err
+		}
+		result.Company = c
+		result.Snippet = escapeSnippet(result.Snippet)
+		results = append(results, &result)
+	}
+	return results, rows.Err()
+}
+
+// searchScanner appends the rank, snippet and fuzzy columns to the
+// destinations scanCompany passes in.
+type searchScanner struct {
+	row    scanner
+	result *entity.CompanySearchResult
+}
+
+func (s searchScanner) Scan(dest ...interface{}) error {
+	return s.row.Scan(append(dest, &s.result.Rank, &s.result.Snippet, &s.result.Fuzzy)...)
+}
@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"backend/companyd/entity"
+	"backend/companyd/usecase/company"
+	"database/sql"
+	"errors"
+)
+
+// seasonNames are the seasons recorded in seasons and those companies
+// belong to.
+const seasonNames = `
+	SELECT name, active FROM seasons
+	UNION
+	SELECT DISTINCT season, false FROM companies
+	WHERE season <> '' AND season NOT IN (SELECT name FROM seasons)`
+
+func (r *Repository) ListSeasons() ([]*entity.Season, error) {
+	rows, err := r.db.Query(`
+		SELECT s.name, s.active, COUNT(c.id)
+		FROM (` + seasonNames + `) s
+		LEFT JOIN companies c ON c.season = s.name AND c.deleted_at IS NULL
+		GROUP BY s.name, s.active
+		ORDER BY s.name`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	seasons := []*entity.Season{}
+	for rows.Next() {
+		var season entity.Season
+		if err := rows.Scan(&season.Name, &season.Active, &season.Companies); err != nil {
+			return nil, err
+		}
+		seasons = append(seasons, &season)
+	}
+	return seasons, rows.Err()
+}
+
+func (r *Repository) SetActiveSeason(season string) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`UPDATE seasons SET active = false WHERE active AND name <> $1`, season); err != nil {
+		return err
+	}
+	_, err = tx.Exec(`
+		INSERT INTO seasons (name, active) VALUES ($1, true)
+		ON CONFLICT (name) DO UPDATE SET active = true`, season)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *Repository) RolloverCompanies(ids []string, season, by string) ([]*entity.Company, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`INSERT INTO seasons (name) VALUES ($1) ON CONFLICT (name) DO NOTHING`, season); err != nil {
+		return nil, err
+	}
+	change := entity.CompanyChange{ChangedBy: by, Source: company.RevisionRollover}
+	carried := make([]*entity.Company, 0, len(ids))
+	for _, id := range ids {
+		original, err := scanCompany(tx.QueryRow(`SELECT `+companyColumns+` FROM companies WHERE id = $1 AND deleted_at IS NULL`, id))
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, company.ErrUnknownCompany
+		}
+		if err != nil {
+			return nil, err
+		}
+		args := []interface{}{original.CompanyName, original.CompanyAddress, original.Drive, original.TypeOfDrive, "", false, original.Remarks, original.ContactDetails, original.HR1Details, original.HR2Details, original.Package}
+		copied, err := insertCompanyWith(tx, args, company.StageProspect, season, original.ID, original.Compensation, original.CustomFields, original.Tags, original.AssignedOfficer, by)
+		if err != nil {
+			return nil, err
+		}
+		_, err = tx.Exec(`
+			INSERT INTO contacts (company_id, name, designation, email, phone, linkedin, is_primary, notes)
+			SELECT $1, name, designation, email, phone, linkedin, is_primary, notes FROM contacts WHERE company_id = $2
+			ORDER BY created_at, id`,
+			copied.ID, original.ID)
+		if err != nil {
+			return nil, err
+		}
+		if err := recordRevision(tx, copied, change); err != nil {
+			return nil, err
+		}
+		carried = append(carried, copied)
+	}
+	return carried, tx.Commit()
+}
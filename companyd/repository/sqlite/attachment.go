@@ -0,0 +1,83 @@
+package sqlite
+
+import (
+	"backend/companyd/entity"
+	"backend/companyd/usecase/company"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const attachmentColumns = `id, company_id, filename, content_type, size, checksum, storage_key, uploaded_by, created_at`
+
+func scanAttachment(row scanner) (*entity.Attachment, error) {
+	var a entity.Attachment
+	err := row.Scan(&a.ID, &a.CompanyID, &a.Filename, &a.ContentType, &a.Size, &a.Checksum, &a.StorageKey, &a.UploadedBy, &a.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	a.CreatedAt = displayTime(a.CreatedAt)
+	return &a, nil
+}
+
+func scanAttachments(rows *sql.Rows) ([]*entity.Attachment, error) {
+	defer rows.Close()
+
+	attachments := []*entity.Attachment{}
+	for rows.Next() {
+		a, err := scanAttachment(rows)
+		if err != nil {
+			return nil, err
+		}
+		attachments = append(attachments, a)
+	}
+	return attachments, rows.Err()
+}
+
+func (r *Repository) CreateAttachment(a entity.Attachment) (*entity.Attachment, error) {
+	return scanAttachment(r.db.QueryRow(`
+		INSERT INTO attachments (id, company_id, filename, content_type, size, checksum, storage_key, uploaded_by, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+		RETURNING `+attachmentColumns,
+		uuid.NewString(), a.CompanyID, a.Filename, a.ContentType, a.Size, a.Checksum, a.StorageKey, a.UploadedBy, formatTime(time.Now())))
+}
+
+func (r *Repository) GetAttachment(id string) (*entity.Attachment, error) {
+	found, err := scanAttachment(r.db.QueryRow(`SELECT `+attachmentColumns+` FROM attachments WHERE id = ?`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, company.ErrNotFound
+	}
+	return found, err
+}
+
+func (r *Repository) ListAttachments(companyID string) ([]*entity.Attachment, error) {
+	rows, err := r.db.Query(`SELECT `+attachmentColumns+` FROM attachments WHERE company_id = ? ORDER BY created_at DESC, id`, companyID)
+	if err != nil {
+		return nil, err
+	}
+	return scanAttachments(rows)
+}
+
+func (r *Repository) ListTrashedAttachments(deletedBefore time.Time) ([]*entity.Attachment, error) {
+	rows, err := r.db.Query(`
+		SELECT `+attachmentColumns+` FROM attachments
+		WHERE company_id IN (SELECT id FROM companies WHERE deleted_at < ?)
+		ORDER BY created_at, id`, formatTime(deletedBefore))
+	if err != nil {
+		return nil, err
+	}
+	return scanAttachments(rows)
+}
+
+func (r *Repository) DeleteAttachment(id string) error {
+	result, err := r.db.Exec(`DELETE FROM attachments WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return company.ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,687 @@
+package sqlite
+
+import (
+	"backend/companyd/entity"
+	"backend/companyd/repository/pgtypes"
+	"backend/companyd/usecase/company"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// companyFields are the columns of companies read into a Company, less its
+// officers, which scanCompany reads last.
+const companyFields = `id, company_name, company_address, drive, type_of_drive, follow_up, is_contacted, remarks, contact_details, hr1_details, hr2_details, package, ` + compensationColumns + `, version, last_interaction_at, last_interaction_outcome, archived_at, archived_by, deleted_at, deleted_by, created_at, updated_at, pipeline_status, season, COALESCE(carried_from, ''), custom_fields, tags`
+
+const companyColumns = companyFields + `, ` + assignedOfficerColumn
+
+// legacyCompanyColumns read the officers from the assigned_officer array,
+// for the data migrations that ran before company_officers existed.
+const legacyCompanyColumns = companyFields + `, assigned_officer`
+
+// compensationColumns hold Company.Compensation, in the order of
+// compensationArgs.
+const compensationColumns = `package_base, package_variable, package_stipend, package_currency, package_unit, package_min, package_max, package_needs_review`
+
+const companyTempColumns = `id, company_id, company_name, company_address, drive, type_of_drive, follow_up, is_contacted, remarks, contact_details, hr1_details, hr2_details, package, assigned_officer, status, COALESCE(base_version, 0), season, created_by, created_at, updated_at`
+
+// Repository is the SQLite implementation of company.Repository.
+type Repository struct {
+	db *sql.DB
+}
+
+var _ company.Repository = (*Repository)(nil)
+
+func NewCompanyRepository(db *sql.DB) *Repository {
+	return &Repository{db: db}
+}
+
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanCompany(row scanner) (*entity.Company, error) {
+	var company entity.Company
+	var customFields, tags, assignedOfficer string
+	var base, variable, stipend, min, max sql.NullFloat64
+	var lastInteractionAt, archivedAt, deletedAt sql.NullString
+	err := row.Scan(
+		&company.ID, &company.CompanyName, &company.CompanyAddress, &company.Drive, &company.TypeOfDrive, &company.FollowUp, &company.IsContacted, &company.Remarks, &company.ContactDetails, &company.HR1Details, &company.HR2Details, &company.Package,
+		&base, &variable, &stipend, &company.Compensation.Currency, &company.Compensation.Unit, &min, &max, &company.Compensation.NeedsReview,
+		&company.Version, &lastInteractionAt, &company.LastInteractionOutcome,
+		&archivedAt, &company.ArchivedBy, &deletedAt, &company.DeletedBy, &company.CreatedAt, &company.UpdatedAt, &company.PipelineStatus, &company.Season, &company.CarriedFrom,
+		&customFields, &tags, &assignedOfficer,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(customFields), &company.CustomFields); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(tags), &company.Tags); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(assignedOfficer), &company.AssignedOfficer); err != nil {
+		return nil, err
+	}
+	company.Compensation.Base = nullAmount(base)
+	company.Compensation.Variable = nullAmount(variable)
+	company.Compensation.Stipend = nullAmount(stipend)
+	company.Compensation.Min = nullAmount(min)
+	company.Compensation.Max = nullAmount(max)
+	if company.LastInteractionAt, err = parseNullTime(lastInteractionAt); err != nil {
+		return nil, err
+	}
+	if company.ArchivedAt, err = parseNullTime(archivedAt); err != nil {
+		return nil, err
+	}
+	if company.DeletedAt, err = parseNullTime(deletedAt); err != nil {
+		return nil, err
+	}
+	company.CreatedAt = displayTime(company.CreatedAt)
+	company.UpdatedAt = displayTime(company.UpdatedAt)
+	return &company, nil
+}
+
+func scanCompanyTemp(row scanner) (*entity.CompanyTemp, error) {
+	var companyTemp entity.CompanyTemp
+	var assignedOfficer string
+	err := row.Scan(
+		&companyTemp.ID, &companyTemp.CompanyID, &companyTemp.CompanyName, &companyTemp.CompanyAddress, &companyTemp.Drive, &companyTemp.TypeOfDrive, &companyTemp.FollowUp, &companyTemp.IsContacted, &companyTemp.Remarks, &companyTemp.ContactDetails, &companyTemp.HR1Details, &companyTemp.HR2Details, &companyTemp.Package, &assignedOfficer, &companyTemp.Status, &companyTemp.BaseVersion, &companyTemp.Season, &companyTemp.CreatedBy, &companyTemp.CreatedAt, &companyTemp.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(assignedOfficer), &companyTemp.AssignedOfficer); err != nil {
+		return nil, err
+	}
+	companyTemp.CreatedAt = displayTime(companyTemp.CreatedAt)
+	companyTemp.UpdatedAt = displayTime(companyTemp.UpdatedAt)
+	return &companyTemp, nil
+}
+
+func officersJSON(assignedOfficer []string) (string, error) {
+	if assignedOfficer == nil {
+		assignedOfficer = []string{}
+	}
+	data, err := json.Marshal(assignedOfficer)
+	return string(data), err
+}
+
+func tagsJSON(tags []string) (string, error) {
+	if tags == nil {
+		tags = []string{}
+	}
+	data, err := json.Marshal(tags)
+	return string(data), err
+}
+
+// customFieldsJSON is the JSON stored in custom_fields.
+func customFieldsJSON(values entity.CustomValues) (string, error) {
+	if values == nil {
+		values = entity.CustomValues{}
+	}
+	data, err := json.Marshal(values)
+	return string(data), err
+}
+
+func nullAmount(v sql.NullFloat64) *float64 {
+	if !v.Valid {
+		return nil
+	}
+	return &v.Float64
+}
+
+// compensationArgs are the values of compensationColumns followed by
+// package_amount, the annual CTC used by the package filters and sort.
+func compensationArgs(c entity.Compensation) []interface{} {
+	var amount interface{}
+	if ctc, ok := company.AnnualCTC(c); ok {
+		amount = ctc
+	}
+	return []interface{}{c.Base, c.Variable, c.Stipend, c.Currency, c.Unit, c.Min, c.Max, c.NeedsReview, amount}
+}
+
+// setCompensation overwrites the structured package of a company.
+func setCompensation(tx *sql.Tx, id string, c entity.Compensation) error {
+	_, err := tx.Exec(`
+		UPDATE companies
+		SET package_base = ?,
+			package_variable = ?,
+			package_stipend = ?,
+			package_currency = ?,
+			package_unit = ?,
+			package_min = ?,
+			package_max = ?,
+			package_needs_review = ?,
+			package_amount = ?
+		WHERE id = ?`,
+		append(compensationArgs(c), id)...)
+	return err
+}
+
+const insertCompany = `
+	INSERT INTO companies (id, company_name, company_address, drive, type_of_drive, follow_up, is_contacted, remarks, contact_details, hr1_details, hr2_details, package, created_at, updated_at, pipeline_status, season, carried_from, custom_fields, tags, ` + compensationColumns + `, package_amount)
+	VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, NULLIF(?, ''), ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	RETURNING ` + companyColumns
+
+// insertCompanyWith inserts a company of a season at a pipeline stage,
+// recording the stage as its first transition, and assigns its officers.
+// carriedFrom is the company it was carried forward from, or empty.
+func insertCompanyWith(tx *sql.Tx, args []interface{}, status, season, carriedFrom string, compensation entity.Compensation, customFields entity.CustomValues, tags []string, officers []string, assignedBy string) (*entity.Company, error) {
+	custom, err := customFieldsJSON(customFields)
+	if err != nil {
+		return nil, err
+	}
+	tagList, err := tagsJSON(tags)
+	if err != nil {
+		return nil, err
+	}
+	args = append(append(args, status, season, carriedFrom, custom, tagList), compensationArgs(compensation)...)
+	created, err := scanCompany(tx.QueryRow(insertCompany, args...))
+	if err != nil {
+		return nil, err
+	}
+	if err := recordTransition(tx, created.ID, "", status, assignedBy); err != nil {
+		return nil, err
+	}
+	if len(officers) == 0 {
+		return created, nil
+	}
+	if err := setOfficers(tx, created.ID, officers, assignedBy); err != nil {
+		return nil, err
+	}
+	return scanCompany(tx.QueryRow(`SELECT `+companyColumns+` FROM companies WHERE id = ?`, created.ID))
+}
+
+func (r *Repository) CreateCompany(companyName, companyAddress, drive, typeOfDrive, followUp, isContacted, remarks, contactDetails, hr1Details, hr2Details, pkg string, assignedOfficer []string, compensation entity.Compensation, customFields entity.CustomValues, tags []string, season string) (*entity.Company, error) {
+	contacted, err := pgtypes.ParseBool(isContacted)
+	if err != nil {
+		return nil, err
+	}
+
+	now := formatTime(time.Now())
+	args := []interface{}{uuid.NewString(), companyName, companyAddress, drive, typeOfDrive, followUp, contacted, remarks, contactDetails, hr1Details, hr2Details, pkg, now, now}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	created, err := insertCompanyWith(tx, args, company.InitialStage(contacted), season, "", compensation, customFields, tags, assignedOfficer, "")
+	if err != nil {
+		return nil, err
+	}
+	if err := recordRevision(tx, created, entity.CompanyChange{Source: company.RevisionCreate}); err != nil {
+		return nil, err
+	}
+	return created, tx.Commit()
+}
+
+func (r *Repository) ImportCompanies(companies []entity.CompanySnapshot, season, importedBy string) ([]*entity.Company, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	change := entity.CompanyChange{ChangedBy: importedBy, Source: company.RevisionImport}
+	now := formatTime(time.Now())
+	created := make([]*entity.Company, 0, len(companies))
+	for _, c := range companies {
+		args := []interface{}{uuid.NewString(), c.CompanyName, c.CompanyAddress, c.Drive, c.TypeOfDrive, c.FollowUp, c.IsContacted, c.Remarks, c.ContactDetails, c.HR1Details, c.HR2Details, c.Package, now, now}
+		status := c.PipelineStatus
+		if status == "" {
+			status = company.InitialStage(c.IsContacted)
+		}
+		imported, err := insertCompanyWith(tx, args, status, season, "", c.Compensation, c.CustomFields, c.Tags, c.AssignedOfficer, importedBy)
+		if err != nil {
+			return nil, err
+		}
+		if err := recordRevision(tx, imported, change); err != nil {
+			return nil, err
+		}
+		created = append(created, imported)
+	}
+	return created, tx.Commit()
+}
+
+// GetCompany does not find companies in the trash.
+func (r *Repository) GetCompany(id string) (*entity.Company, error) {
+	found, err := scanCompany(r.db.QueryRow(`SELECT `+companyColumns+` FROM companies WHERE id = ? AND deleted_at IS NULL`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, company.ErrNotFound
+	}
+	return found, err
+}
+
+// DeleteCompany keeps the original deletion time of a company already in the
+// trash, so deleting it again does not postpone its purge.
+func (r *Repository) DeleteCompany(id string, deletedBy string) error {
+	_, err := r.db.Exec(`UPDATE companies SET deleted_at = ?, deleted_by = ? WHERE id = ? AND deleted_at IS NULL`, formatTime(time.Now()), deletedBy, id)
+	return err
+}
+
+func (r *Repository) RestoreCompany(id string) (*entity.Company, error) {
+	restored, err := scanCompany(r.db.QueryRow(`
+		UPDATE companies SET deleted_at = NULL, deleted_by = ''
+		WHERE id = ? AND deleted_at IS NOT NULL
+		RETURNING `+companyColumns, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, company.ErrNotFound
+	}
+	return restored, err
+}
+
+// SetCompanyArchived leaves version and updated_at alone: archiving is not an
+// edit of the company's details.
+func (r *Repository) SetCompanyArchived(id string, archived bool, by string) (*entity.Company, error) {
+	updated, err := scanCompany(r.db.QueryRow(`
+		UPDATE companies
+		SET archived_at = CASE WHEN ?2 THEN COALESCE(archived_at, ?4) END,
+			archived_by = CASE WHEN ?2 THEN ?3 ELSE '' END
+		WHERE id = ?1 AND deleted_at IS NULL
+		RETURNING `+companyColumns, id, archived, by, formatTime(time.Now())))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, company.ErrNotFound
+	}
+	return updated, err
+}
+
+func (r *Repository) ListDeletedCompanies() ([]*entity.Company, error) {
+	return r.listCompanies(`SELECT ` + companyColumns + ` FROM companies WHERE deleted_at IS NOT NULL ORDER BY deleted_at DESC, rowid DESC`)
+}
+
+// PurgeCompanies removes pending proposals first, since companies_temp
+// references companies without ON DELETE; everything else cascades.
+func (r *Repository) PurgeCompanies(deletedBefore time.Time) (int, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	cutoff := formatTime(deletedBefore)
+	if _, err := tx.Exec(`DELETE FROM companies_temp WHERE company_id IN (SELECT id FROM companies WHERE deleted_at < ?)`, cutoff); err != nil {
+		return 0, err
+	}
+	result, err := tx.Exec(`DELETE FROM companies WHERE deleted_at < ?`, cutoff)
+	if err != nil {
+		return 0, err
+	}
+	purged, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	return int(purged), tx.Commit()
+}
+
+func (r *Repository) ListCompanies() ([]*entity.Company, error) {
+	return r.listCompanies(`SELECT ` + companyColumns + ` FROM companies WHERE deleted_at IS NULL`)
+}
+
+func (r *Repository) listCompanies(query string, args ...interface{}) ([]*entity.Company, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var companies []*entity.Company
+	for rows.Next() {
+		company, err := scanCompany(rows)
+		if err != nil {
+			return nil, err
+		}
+		companies = append(companies, company)
+	}
+	return companies, rows.Err()
+}
+
+// UpdateCompany applies the set fields of update only if the company is
+// still at the given version, and records the new version in the company's
+// history. Unset fields are bound as NULL.
+func (r *Repository) UpdateCompany(id string, version int, update entity.CompanyUpdate, change entity.CompanyChange) (*entity.Company, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	updated, err := updateCompany(tx, id, version, update, change)
+	if err != nil {
+		return nil, err
+	}
+	return updated, tx.Commit()
+}
+
+// updateCompany is UpdateCompany within tx. A change of pipeline status is
+// recorded as a transition.
+func updateCompany(tx *sql.Tx, id string, version int, update entity.CompanyUpdate, change entity.CompanyChange) (*entity.Company, error) {
+	var previousStatus string
+	if update.PipelineStatus != nil {
+		err := tx.QueryRow(`SELECT pipeline_status FROM companies WHERE id = ? AND deleted_at IS NULL`, id).Scan(&previousStatus)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, company.ErrNotFound
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var compensation entity.Compensation
+	if update.Compensation != nil {
+		compensation = *update.Compensation
+	}
+
+	query := `
+		UPDATE companies
+		SET company_name = COALESCE(?1, company_name),
+			company_address = COALESCE(?2, company_address),
+			drive = COALESCE(?3, drive),
+			type_of_drive = COALESCE(?4, type_of_drive),
+			follow_up = COALESCE(?5, follow_up),
+			is_contacted = COALESCE(?6, is_contacted),
+			remarks = COALESCE(?7, remarks),
+			contact_details = COALESCE(?8, contact_details),
+			hr1_details = COALESCE(?9, hr1_details),
+			hr2_details = COALESCE(?10, hr2_details),
+			package = COALESCE(?11, package),
+			package_base = CASE WHEN ?14 THEN ?15 ELSE package_base END,
+			package_variable = CASE WHEN ?14 THEN ?16 ELSE package_variable END,
+			package_stipend = CASE WHEN ?14 THEN ?17 ELSE package_stipend END,
+			package_currency = CASE WHEN ?14 THEN ?18 ELSE package_currency END,
+			package_unit = CASE WHEN ?14 THEN ?19 ELSE package_unit END,
+			package_min = CASE WHEN ?14 THEN ?20 ELSE package_min END,
+			package_max = CASE WHEN ?14 THEN ?21 ELSE package_max END,
+			package_needs_review = CASE WHEN ?14 THEN ?22 ELSE package_needs_review END,
+			package_amount = CASE WHEN ?14 THEN ?23 ELSE package_amount END,
+			pipeline_status = COALESCE(?25, pipeline_status),
+			custom_fields = COALESCE(?26, custom_fields),
+			tags = COALESCE(?27, tags),
+			version = version + 1,
+			updated_at = ?24
+		WHERE id = ?12 AND version = ?13 AND deleted_at IS NULL
+		RETURNING ` + companyColumns
+
+	args := []interface{}{
+		update.CompanyName, update.CompanyAddress, update.Drive, update.TypeOfDrive, update.FollowUp, update.IsContacted, update.Remarks,
+		update.ContactDetails, update.HR1Details, update.HR2Details, update.Package, id, version, update.Compensation != nil,
+	}
+	args = append(args, compensationArgs(compensation)...)
+	args = append(args, formatTime(time.Now()), update.PipelineStatus)
+	var customFields, tags interface{}
+	if update.CustomFields != nil {
+		custom, err := customFieldsJSON(*update.CustomFields)
+		if err != nil {
+			return nil, err
+		}
+		customFields = custom
+	}
+	if update.Tags != nil {
+		tagList, err := tagsJSON(*update.Tags)
+		if err != nil {
+			return nil, err
+		}
+		tags = tagList
+	}
+	args = append(args, customFields, tags)
+
+	updated, err := scanCompany(tx.QueryRow(query, args...))
+	if errors.Is(err, sql.ErrNoRows) {
+		// Either the company is gone or someone else updated it first.
+		var exists int
+		err = tx.QueryRow(`SELECT 1 FROM companies WHERE id = ? AND deleted_at IS NULL`, id).Scan(&exists)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, company.ErrNotFound
+		}
+		if err != nil {
+			return nil, err
+		}
+		return nil, company.ErrVersionMismatch
+	}
+	if err != nil {
+		return nil, err
+	}
+	if update.PipelineStatus != nil && *update.PipelineStatus != previousStatus {
+		if err := recordTransition(tx, id, previousStatus, *update.PipelineStatus, change.ChangedBy); err != nil {
+			return nil, err
+		}
+	}
+	if update.AssignedOfficer != nil {
+		if err := setOfficers(tx, id, *update.AssignedOfficer, change.ChangedBy); err != nil {
+			return nil, err
+		}
+		if updated, err = scanCompany(tx.QueryRow(`SELECT `+companyColumns+` FROM companies WHERE id = ?`, id)); err != nil {
+			return nil, err
+		}
+	}
+	if err := recordRevision(tx, updated, change); err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+func (r *Repository) ListCompaniesByUsername(username string) ([]*entity.Company, error) {
+	query := `
+		SELECT ` + companyColumns + `
+		FROM companies
+		WHERE ` + officerFilter + `
+			AND archived_at IS NULL AND deleted_at IS NULL`
+
+	return r.listCompanies(query, username)
+}
+
+func (r *Repository) CreateCompanyTemp(companyId, companyName, companyAddress, drive, typeOfDrive, followUp, isContacted, remarks, contactDetails, hr1Details, hr2Details, pkg string, assignedOfficer []string, createdBy string) (*entity.CompanyTemp, error) {
+	contacted, err := pgtypes.ParseBool(isContacted)
+	if err != nil {
+		return nil, err
+	}
+	officers, err := officersJSON(assignedOfficer)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `
+		INSERT INTO companies_temp (id, company_id, company_name, company_address, drive, type_of_drive, follow_up, is_contacted, remarks, contact_details, hr1_details, hr2_details, package, assigned_officer, status, base_version, created_by, created_at, updated_at, season)
+		SELECT ?1, ?2, ?3, ?4, ?5, ?6, ?7, ?8, ?9, ?10, ?11, ?12, ?13, ?14, 'pending', version, ?15, ?16, ?17, season FROM companies WHERE id = ?2 AND deleted_at IS NULL
+		RETURNING ` + companyTempColumns
+
+	now := formatTime(time.Now())
+	return scanCompanyTemp(r.db.QueryRow(query, uuid.NewString(), companyId, companyName, companyAddress, drive, typeOfDrive, followUp, contacted, remarks, contactDetails, hr1Details, hr2Details, pkg, officers, createdBy, now, now))
+}
+
+func (r *Repository) ListCompanyTemps(season string) ([]*entity.CompanyTemp, error) {
+	f := &listFilter{}
+	if season != "" {
+		f.add("season = ?", season)
+	}
+	// rowid breaks ties between proposals created in the same microsecond.
+	rows, err := r.db.Query(`SELECT `+companyTempColumns+` FROM companies_temp`+f.where()+` ORDER BY created_at DESC, rowid DESC`, f.args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var companyTemps []*entity.CompanyTemp
+	for rows.Next() {
+		companyTemp, err := scanCompanyTemp(rows)
+		if err != nil {
+			return nil, err
+		}
+		companyTemps = append(companyTemps, companyTemp)
+	}
+	return companyTemps, rows.Err()
+}
+
+func (r *Repository) GetCompanyTemp(id string) (*entity.CompanyTemp, error) {
+	companyTemp, err := scanCompanyTemp(r.db.QueryRow(`SELECT `+companyTempColumns+` FROM companies_temp WHERE id = ?`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, company.ErrNotFound
+	}
+	return companyTemp, err
+}
+
+func (r *Repository) UpdateCompanyTempStatus(id string, from, to string) error {
+	result, err := r.db.Exec(`UPDATE companies_temp SET status = ?, updated_at = ? WHERE id = ? AND status = ?`, to, formatTime(time.Now()), id, from)
+	if err != nil {
+		return err
+	}
+	if n, err := result.RowsAffected(); err != nil || n == 0 {
+		if err == nil {
+			err = company.ErrNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+func (r *Repository) ApproveCompanyTemp(id string, approvedBy string) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	companyTemp, err := scanCompanyTemp(tx.QueryRow(`SELECT `+companyTempColumns+` FROM companies_temp WHERE id = ?`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return company.ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+	// Marking the proposal approved first takes the write lock, so that only
+	// one approval or status change can move it on from pending.
+	now := formatTime(time.Now())
+	result, err := tx.Exec(`UPDATE companies_temp SET status = ?, updated_at = ? WHERE id = ? AND status = ?`, company.ProposalApproved, now, id, company.ProposalPending)
+	if err != nil {
+		return err
+	}
+	if n, err := result.RowsAffected(); err != nil || n == 0 {
+		if err == nil {
+			err = company.NewProposalTransitionError(companyTemp.Status, company.ProposalApproved)
+		}
+		return err
+	}
+
+	var currentVersion int
+	var currentPackage string
+	err = tx.QueryRow(`SELECT version, COALESCE(package, '') FROM companies WHERE id = ? AND deleted_at IS NULL`, companyTemp.CompanyID).Scan(&currentVersion, &currentPackage)
+	if errors.Is(err, sql.ErrNoRows) {
+		return company.ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if companyTemp.BaseVersion != 0 && companyTemp.BaseVersion != currentVersion {
+		return company.ErrStaleProposal
+	}
+
+	// The pipeline status, and so is_contacted, only changes through its own
+	// transitions.
+	_, err = tx.Exec(`
+		UPDATE companies
+		SET company_name = ?,
+			company_address = ?,
+			drive = ?,
+			type_of_drive = ?,
+			follow_up = ?,
+			remarks = ?,
+			contact_details = ?,
+			hr1_details = ?,
+			hr2_details = ?,
+			package = ?,
+			version = version + 1,
+			updated_at = ?
+		WHERE id = ?`,
+		companyTemp.CompanyName, companyTemp.CompanyAddress, companyTemp.Drive,
+		companyTemp.TypeOfDrive, companyTemp.FollowUp,
+		companyTemp.Remarks, companyTemp.ContactDetails, companyTemp.HR1Details,
+		companyTemp.HR2Details, companyTemp.Package, now,
+		companyTemp.CompanyID)
+	if err != nil {
+		return err
+	}
+	if err := setOfficers(tx, companyTemp.CompanyID, companyTemp.AssignedOfficer, approvedBy); err != nil {
+		return err
+	}
+
+	// Proposals carry only the package text; keep the structured package
+	// unless the text changed.
+	if companyTemp.Package != currentPackage {
+		if err := setCompensation(tx, companyTemp.CompanyID, company.ParseCompensation(companyTemp.Package)); err != nil {
+			return err
+		}
+	}
+
+	approved, err := scanCompany(tx.QueryRow(`SELECT `+companyColumns+` FROM companies WHERE id = ?`, companyTemp.CompanyID))
+	if err != nil {
+		return err
+	}
+	change := entity.CompanyChange{ChangedBy: approvedBy, Source: company.RevisionProposal, ProposalID: companyTemp.ID}
+	if err := recordRevision(tx, approved, change); err != nil {
+		return err
+	}
+
+	// The company's other pending proposals were made against the version
+	// this one replaced.
+	_, err = tx.Exec(`UPDATE companies_temp SET status = ?, updated_at = ? WHERE company_id = ? AND status = ? AND id <> ?`,
+		company.ProposalSuperseded, now, companyTemp.CompanyID, company.ProposalPending, id)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *Repository) CreateEvent(date, eventType, title, description, companyID, createdBy string) (*entity.Event, error) {
+	parsed, err := pgtypes.ParseTimestamp(date)
+	if err != nil {
+		return nil, err
+	}
+
+	var event entity.Event
+	err = r.db.QueryRow(`
+		INSERT INTO events (id, date, type, title, description, company_id, created_by, created_at, season)
+		VALUES (?, ?, ?, ?, ?, NULLIF(?, ''), ?, ?, ?)
+		RETURNING id, date, type, title, description, COALESCE(company_id, ''), season, created_by, created_at`,
+		uuid.NewString(), formatTime(parsed), eventType, title, description, companyID, createdBy, formatTime(time.Now()), company.SeasonAt(parsed),
+	).Scan(&event.ID, &event.Date, &event.Type, &event.Title, &event.Description, &event.CompanyID, &event.Season, &event.CreatedBy, &event.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	event.Date = displayTime(event.Date)
+	event.CreatedAt = displayTime(event.CreatedAt)
+	return &event, nil
+}
+
+func (r *Repository) ListEvents(season string) ([]*entity.Event, error) {
+	f := &listFilter{}
+	if season != "" {
+		f.add("season = ?", season)
+	}
+	rows, err := r.db.Query(`
+		SELECT id, date, type, title, description, COALESCE(company_id, ''), season, created_by, created_at
+		FROM events`+f.where()+`
+		ORDER BY date DESC`, f.args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []*entity.Event
+	for rows.Next() {
+		var event entity.Event
+		if err := rows.Scan(&event.ID, &event.Date, &event.Type, &event.Title, &event.Description, &event.CompanyID, &event.Season, &event.CreatedBy, &event.CreatedAt); err != nil {
+			return nil, err
+		}
+		event.Date = displayTime(event.Date)
+		event.CreatedAt = displayTime(event.CreatedAt)
+		events = append(events, &event)
+	}
+	return events, rows.Err()
+}
@@ -0,0 +1,429 @@
+package sqlite
+
+import (
+	"backend/companyd/entity"
+	"backend/companyd/repository/contract"
+	"backend/companyd/usecase/company"
+	userSQLite "backend/userd/repository/sqlite"
+	"database/sql"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func openTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "test.db")
+	db, err := sql.Open("sqlite3", "file:"+path+"?_foreign_keys=on&_busy_timeout=5000")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	if err := userSQLite.Migrate(db); err != nil {
+		t.Fatal(err)
+	}
+	if err := Migrate(db); err != nil {
+		t.Fatal(err)
+	}
+	for _, officer := range contract.Officers {
+		_, err := db.Exec(`
+			INSERT INTO users (id, username, email, role, password, created_at)
+			VALUES (?, ?, ?, 'Officer', 'password', ?)
+			ON CONFLICT (username) DO NOTHING`,
+			uuid.NewString(), officer, officer+"@example.com", formatTime(time.Now()))
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	return db
+}
+
+func TestRepositoryContract(t *testing.T) {
+	contract.Run(t, func(t *testing.T) company.Repository {
+		return NewCompanyRepository(openTestDB(t))
+	})
+}
+
+func TestMigrateImportsContacts(t *testing.T) {
+	db := openTestDB(t)
+	repo := NewCompanyRepository(db)
+	created, err := repo.CreateCompany("Infosys", "Bengaluru", "2026", "on-campus", "", "false", "",
+		"careers@infosys.com", "Priya Sharma, Talent Acquisition, priya@infosys.com", "NA", "10 LPA", nil, company.ParseCompensation("10 LPA"), nil, nil, "2026-27")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The migration already ran on the empty database; forget it to rerun.
+	if _, err := db.Exec(`DELETE FROM schema_migrations WHERE name = '0001_import_contacts'`); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		if err := Migrate(db); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	contacts, err := repo.ListContacts(company.ContactFilter{CompanyIDs: []string{created.ID}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(contacts) != 2 {
+		t.Fatalf("imported %d contacts, want 2: %+v", len(contacts), contacts)
+	}
+	primary := contacts[0]
+	if !primary.IsPrimary || primary.Name != "Priya Sharma" || primary.Designation != "Talent Acquisition" || primary.Email != "priya@infosys.com" {
+		t.Errorf("unexpected primary contact: %+v", primary)
+	}
+	if contacts[1].IsPrimary || contacts[1].Email != "careers@infosys.com" || contacts[1].Notes != "Imported from contact_details: careers@infosys.com" {
+		t.Errorf("unexpected second contact: %+v", contacts[1])
+	}
+}
+
+func TestMigrateStructuresPackages(t *testing.T) {
+	db := openTestDB(t)
+	repo := NewCompanyRepository(db)
+	var ids []string
+	for _, pkg := range []string{"12,00,000 INR", "Competitive"} {
+		created, err := repo.CreateCompany("Infosys", "", "2026", "on-campus", "", "false", "", "", "", "", pkg, nil, entity.Compensation{}, nil, nil, "2026-27")
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, created.ID)
+	}
+
+	if _, err := db.Exec(`DELETE FROM schema_migrations WHERE name = '0002_structure_packages'`); err != nil {
+		t.Fatal(err)
+	}
+	if err := Migrate(db); err != nil {
+		t.Fatal(err)
+	}
+
+	parsed, err := repo.GetCompany(ids[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parsed.Compensation.Base == nil || *parsed.Compensation.Base != 12 || parsed.Compensation.Unit != company.UnitLPA || parsed.Compensation.NeedsReview {
+		t.Errorf("unexpected compensation: %+v", parsed.Compensation)
+	}
+	flagged, err := repo.GetCompany(ids[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !flagged.Compensation.NeedsReview || flagged.Package != "Competitive" {
+		t.Errorf("unparseable package not flagged: %q %+v", flagged.Package, flagged.Compensation)
+	}
+}
+
+func TestMigrateImportsFollowUps(t *testing.T) {
+	db := openTestDB(t)
+	repo := NewCompanyRepository(db)
+	dated, err := repo.CreateCompany("Infosys", "", "2026", "on-campus", "Call HR on 14th March 2026 about slots", "false", "", "", "", "", "", []string{"alice"}, entity.Compensation{}, nil, nil, "2026-27")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range []struct{ followUp, officer string }{{"N/A", "alice"}, {"Send brochure", ""}} {
+		var officers []string
+		if c.officer != "" {
+			officers = []string{c.officer}
+		}
+		if _, err := repo.CreateCompany("TCS", "", "2026", "on-campus", c.followUp, "false", "", "", "", "", "", officers, entity.Compensation{}, nil, nil, "2026-27"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Before 0006_assign_officers, officers were only in the array.
+	if _, err := db.Exec(`UPDATE companies SET assigned_officer = ` + assignedOfficerColumn); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(`DELETE FROM schema_migrations WHERE name = '0003_import_follow_ups'`); err != nil {
+		t.Fatal(err)
+	}
+	if err := Migrate(db); err != nil {
+		t.Fatal(err)
+	}
+
+	followUps, err := repo.ListFollowUps(company.FollowUpFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(followUps) != 1 {
+		t.Fatalf("imported %d follow-ups, want 1: %+v", len(followUps), followUps)
+	}
+	imported := followUps[0]
+	if imported.CompanyID != dated.ID || imported.Officer != "alice" || imported.Status != company.FollowUpOpen {
+		t.Errorf("unexpected imported follow-up: %+v", imported)
+	}
+	if want := time.Date(2026, time.March, 14, 0, 0, 0, 0, time.UTC); !imported.DueAt.Equal(want) {
+		t.Errorf("DueAt = %s, want %s", imported.DueAt, want)
+	}
+}
+
+func TestMigrateImportsRemarks(t *testing.T) {
+	db := openTestDB(t)
+	repo := NewCompanyRepository(db)
+	noted, err := repo.CreateCompany("Infosys", "", "2026", "on-campus", "", "false", "HR wants the drive in March", "", "", "", "", []string{"alice"}, entity.Compensation{}, nil, nil, "2026-27")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.CreateCompany("TCS", "", "2026", "on-campus", "", "false", "NA", "", "", "", "", nil, entity.Compensation{}, nil, nil, "2026-27"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Before 0006_assign_officers, officers were only in the array.
+	if _, err := db.Exec(`UPDATE companies SET assigned_officer = ` + assignedOfficerColumn); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(`DELETE FROM schema_migrations WHERE name = '0004_import_remarks'`); err != nil {
+		t.Fatal(err)
+	}
+	if err := Migrate(db); err != nil {
+		t.Fatal(err)
+	}
+
+	interactions, err := repo.ListInteractions(company.InteractionFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(interactions) != 1 {
+		t.Fatalf("imported %d interactions, want 1: %+v", len(interactions), interactions)
+	}
+	imported := interactions[0]
+	if imported.CompanyID != noted.ID || imported.Officer != "alice" || imported.Type != company.InteractionOther || !strings.Contains(imported.Notes, "drive in March") {
+		t.Errorf("unexpected imported interaction: %+v", imported)
+	}
+	found, err := repo.GetCompany(noted.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if found.LastInteractionAt == nil || found.LastInteractionAt.Format(time.RFC3339Nano) != noted.UpdatedAt || found.Remarks != noted.Remarks {
+		t.Errorf("last interaction %v, want %s; remarks %q", found.LastInteractionAt, noted.UpdatedAt, found.Remarks)
+	}
+}
+
+func TestMigrateRecordsBaselines(t *testing.T) {
+	db := openTestDB(t)
+	repo := NewCompanyRepository(db)
+	created, err := repo.CreateCompany("Infosys", "", "2026", "on-campus", "", "false", "", "", "", "", "10 LPA", []string{"alice"}, entity.Compensation{}, nil, nil, "2026-27")
+	if err != nil {
+		t.Fatal(err)
+	}
+	remarks := "met HR"
+	updated, err := repo.UpdateCompany(created.ID, created.Version, entity.CompanyUpdate{Remarks: &remarks}, entity.CompanyChange{Source: company.RevisionEdit})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Companies from before history recording have none.
+	if _, err := db.Exec(`DELETE FROM company_history`); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(`DELETE FROM schema_migrations WHERE name = '0005_record_company_history'`); err != nil {
+		t.Fatal(err)
+	}
+	if err := Migrate(db); err != nil {
+		t.Fatal(err)
+	}
+
+	revisions, err := repo.ListCompanyRevisions(created.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(revisions) != 1 {
+		t.Fatalf("recorded %d revisions, want 1: %+v", len(revisions), revisions)
+	}
+	baseline := revisions[0]
+	if baseline.Version != updated.Version || baseline.Source != company.RevisionBaseline || baseline.Snapshot.Remarks != "met HR" || baseline.CreatedAt != updated.UpdatedAt {
+		t.Errorf("unexpected baseline: %+v", baseline)
+	}
+}
+
+func TestMigrateAssignsOfficers(t *testing.T) {
+	db := openTestDB(t)
+	repo := NewCompanyRepository(db)
+	created, err := repo.CreateCompany("Infosys", "", "2026", "on-campus", "", "false", "", "", "", "", "", nil, entity.Compensation{}, nil, nil, "2026-27")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(`UPDATE companies SET assigned_officer = '["bob", "ghost", "alice", "bob"]'`); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(`DELETE FROM schema_migrations WHERE name = '0006_assign_officers'`); err != nil {
+		t.Fatal(err)
+	}
+	if err := Migrate(db); err != nil {
+		t.Fatal(err)
+	}
+
+	found, err := repo.GetCompany(created.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(found.AssignedOfficer, ",") != "bob,alice" {
+		t.Errorf("AssignedOfficer = %v, want [bob alice]", found.AssignedOfficer)
+	}
+	officers, err := repo.ListCompanyOfficers(created.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(officers) != 2 {
+		t.Fatalf("assigned %d officers, want 2: %+v", len(officers), officers)
+	}
+	for i, want := range []struct{ username, role string }{{"bob", company.OfficerPrimary}, {"alice", company.OfficerSecondary}} {
+		got := officers[i]
+		if got.Username != want.username || got.Role != want.role || got.AssignedBy != company.MigrationAssigner || got.UserID == "" {
+			t.Errorf("officer %d = %+v, want %s as %s", i, got, want.username, want.role)
+		}
+		if got.AssignedAt.Format(time.RFC3339Nano) != created.UpdatedAt {
+			t.Errorf("officer %d assigned at %s, want %s", i, got.AssignedAt, created.UpdatedAt)
+		}
+	}
+}
+
+func TestMigrateImportsDrives(t *testing.T) {
+	db := openTestDB(t)
+	repo := NewCompanyRepository(db)
+	created, err := repo.CreateCompany("Infosys", "", "2026", "Pool Campus", "", "false", "", "", "", "", "12 LPA", nil, entity.Compensation{}, nil, nil, "2026-27")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.CreateCompany("Unknown", "", "", "", "", "false", "", "", "", "", "", nil, entity.Compensation{}, nil, nil, "2026-27"); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := db.Exec(`DELETE FROM schema_migrations WHERE name = '0007_import_drives'`); err != nil {
+			t.Fatal(err)
+		}
+		if err := Migrate(db); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	drives, err := repo.ListDrives(company.DriveFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(drives) != 1 {
+		t.Fatalf("imported %d drives, want 1: %+v", len(drives), drives)
+	}
+	got := drives[0]
+	if got.CompanyID != created.ID || got.Type != company.DrivePool || got.Season != "2025-26" || got.Package != "12 LPA" || got.Status != company.DrivePlanned {
+		t.Errorf("imported %+v", got)
+	}
+	if got.Notes != "Imported from drive: 2026" {
+		t.Errorf("Notes = %q, want the original drive text", got.Notes)
+	}
+}
+
+func TestMigrateRecordsPipelineStatus(t *testing.T) {
+	db := openTestDB(t)
+	repo := NewCompanyRepository(db)
+	contacted, err := repo.CreateCompany("Infosys", "", "", "", "", "true", "", "", "", "", "", nil, entity.Compensation{}, nil, nil, "2026-27")
+	if err != nil {
+		t.Fatal(err)
+	}
+	prospect, err := repo.CreateCompany("TCS", "", "", "", "", "false", "", "", "", "", "", nil, entity.Compensation{}, nil, nil, "2026-27")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Companies created before the pipeline had only is_contacted.
+	if _, err := db.Exec(`DELETE FROM company_status_transitions; UPDATE companies SET pipeline_status = 'prospect'`); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := db.Exec(`DELETE FROM schema_migrations WHERE name = '0008_record_pipeline_status'`); err != nil {
+			t.Fatal(err)
+		}
+		if err := Migrate(db); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, tt := range []struct {
+		created *entity.Company
+		want    string
+	}{{contacted, company.StageContacted}, {prospect, company.StageProspect}} {
+		got, err := repo.GetCompany(tt.created.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.PipelineStatus != tt.want {
+			t.Errorf("%s: PipelineStatus = %q, want %q", got.CompanyName, got.PipelineStatus, tt.want)
+		}
+		transitions, err := repo.ListStatusTransitions(company.StatusTransitionFilter{CompanyID: got.ID})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(transitions) != 1 || transitions[0].From != "" || transitions[0].To != tt.want || transitions[0].ChangedAt.Format(time.RFC3339Nano) != got.CreatedAt {
+			t.Errorf("%s: transitions = %+v, want one to %s when created", got.CompanyName, transitions, tt.want)
+		}
+	}
+}
+
+func TestMigrateAssignsSeasons(t *testing.T) {
+	db := openTestDB(t)
+	repo := NewCompanyRepository(db)
+	withDrive, err := repo.CreateCompany("Infosys", "", "", "", "", "false", "", "", "", "", "", nil, entity.Compensation{}, nil, nil, "2026-27")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, season := range []string{"2024-25", "2025-26"} {
+		if _, err := repo.CreateDrive(entity.Drive{CompanyID: withDrive.ID, Type: company.DriveOnCampus, Season: season, Roles: []string{}, Status: company.DrivePlanned}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	created, err := repo.CreateCompany("TCS", "", "", "", "", "false", "", "", "", "", "", nil, entity.Compensation{}, nil, nil, "2026-27")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.CreateCompanyTemp(withDrive.ID, "Infosys Ltd", "", "", "", "", "false", "", "", "", "", "", nil, "officer"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.CreateEvent("2025-03-01T10:00:00Z", "drive", "Infosys", "", "", "manager"); err != nil {
+		t.Fatal(err)
+	}
+	// Rows written before seasons existed had none.
+	if _, err := db.Exec(`UPDATE companies SET season = '', created_at = '2025-08-01T00:00:00.000000Z' WHERE id = ?`, created.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(`UPDATE companies SET season = ''; UPDATE companies_temp SET season = ''; UPDATE events SET season = ''`); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := db.Exec(`DELETE FROM schema_migrations WHERE name = '0009_assign_seasons'`); err != nil {
+			t.Fatal(err)
+		}
+		if err := Migrate(db); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, tt := range []struct {
+		id, want string
+	}{{withDrive.ID, "2025-26"}, {created.ID, "2025-26"}} {
+		got, err := repo.GetCompany(tt.id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.Season != tt.want {
+			t.Errorf("%s: Season = %q, want %q", got.CompanyName, got.Season, tt.want)
+		}
+	}
+	temps, err := repo.ListCompanyTemps("2025-26")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(temps) != 1 {
+		t.Errorf("2025-26 proposals = %+v, want the one for Infosys", temps)
+	}
+	events, err := repo.ListEvents("2024-25")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 1 {
+		t.Errorf("2024-25 events = %+v, want the one dated March 2025", events)
+	}
+}
@@ -0,0 +1,145 @@
+package sqlite
+
+import (
+	"backend/companyd/entity"
+	"backend/companyd/usecase/company"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const contactColumns = `id, company_id, name, designation, email, phone, linkedin, is_primary, notes, created_at, updated_at`
+
+func scanContact(row scanner) (*entity.Contact, error) {
+	var contact entity.Contact
+	err := row.Scan(&contact.ID, &contact.CompanyID, &contact.Name, &contact.Designation, &contact.Email, &contact.Phone, &contact.LinkedIn, &contact.IsPrimary, &contact.Notes, &contact.CreatedAt, &contact.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	contact.CreatedAt = displayTime(contact.CreatedAt)
+	contact.UpdatedAt = displayTime(contact.UpdatedAt)
+	return &contact, nil
+}
+
+// demotePrimary clears the primary flag on the company's other contacts so
+// that idx_contacts_primary allows the new one.
+func demotePrimary(tx *sql.Tx, companyID, keepID, now string) error {
+	_, err := tx.Exec(`UPDATE contacts SET is_primary = 0, updated_at = ? WHERE company_id = ? AND is_primary AND id <> ?`, now, companyID, keepID)
+	return err
+}
+
+func (r *Repository) CreateContact(contact entity.Contact) (*entity.Contact, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	created, err := insertContact(tx, contact, formatTime(time.Now()))
+	if err != nil {
+		return nil, err
+	}
+	return created, tx.Commit()
+}
+
+func insertContact(tx *sql.Tx, contact entity.Contact, now string) (*entity.Contact, error) {
+	id := uuid.NewString()
+	if contact.IsPrimary {
+		if err := demotePrimary(tx, contact.CompanyID, id, now); err != nil {
+			return nil, err
+		}
+	}
+	return scanContact(tx.QueryRow(`
+		INSERT INTO contacts (id, company_id, name, designation, email, phone, linkedin, is_primary, notes, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		RETURNING `+contactColumns,
+		id, contact.CompanyID, contact.Name, contact.Designation, contact.Email, contact.Phone, contact.LinkedIn, contact.IsPrimary, contact.Notes, now, now))
+}
+
+func (r *Repository) GetContact(id string) (*entity.Contact, error) {
+	found, err := scanContact(r.db.QueryRow(`SELECT `+contactColumns+` FROM contacts WHERE id = ?`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, company.ErrNotFound
+	}
+	return found, err
+}
+
+func (r *Repository) ListContacts(filter company.ContactFilter) ([]*entity.Contact, error) {
+	f := &listFilter{}
+	if len(filter.CompanyIDs) > 0 {
+		ids, err := json.Marshal(filter.CompanyIDs)
+		if err != nil {
+			return nil, err
+		}
+		f.add("company_id IN (SELECT value FROM json_each(?))", string(ids))
+	}
+	if filter.Email != "" {
+		f.add("lower(email) = lower(?)", filter.Email)
+	}
+
+	rows, err := r.db.Query(`SELECT `+contactColumns+` FROM contacts`+f.where()+` ORDER BY is_primary DESC, created_at, rowid`, f.args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	contacts := []*entity.Contact{}
+	for rows.Next() {
+		contact, err := scanContact(rows)
+		if err != nil {
+			return nil, err
+		}
+		contacts = append(contacts, contact)
+	}
+	return contacts, rows.Err()
+}
+
+func (r *Repository) UpdateContact(id string, contact entity.Contact) (*entity.Contact, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	now := formatTime(time.Now())
+	if contact.IsPrimary {
+		if err := demotePrimary(tx, contact.CompanyID, id, now); err != nil {
+			return nil, err
+		}
+	}
+	updated, err := scanContact(tx.QueryRow(`
+		UPDATE contacts
+		SET company_id = ?,
+			name = ?,
+			designation = ?,
+			email = ?,
+			phone = ?,
+			linkedin = ?,
+			is_primary = ?,
+			notes = ?,
+			updated_at = ?
+		WHERE id = ?
+		RETURNING `+contactColumns,
+		contact.CompanyID, contact.Name, contact.Designation, contact.Email, contact.Phone, contact.LinkedIn, contact.IsPrimary, contact.Notes, now, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, company.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return updated, tx.Commit()
+}
+
+func (r *Repository) DeleteContact(id string) error {
+	result, err := r.db.Exec(`DELETE FROM contacts WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return company.ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,142 @@
+package sqlite
+
+import (
+	"backend/companyd/entity"
+	"backend/companyd/usecase/company"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const customFieldColumns = `id, key, label, type, options, required, created_at, updated_at`
+
+func scanCustomField(row scanner) (*entity.CustomField, error) {
+	var field entity.CustomField
+	var options string
+	err := row.Scan(&field.ID, &field.Key, &field.Label, &field.Type, &options, &field.Required, &field.CreatedAt, &field.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(options), &field.Options); err != nil {
+		return nil, err
+	}
+	field.CreatedAt = displayTime(field.CreatedAt)
+	field.UpdatedAt = displayTime(field.UpdatedAt)
+	return &field, nil
+}
+
+func (r *Repository) ListCustomFields() ([]*entity.CustomField, error) {
+	rows, err := r.db.Query(`SELECT ` + customFieldColumns + ` FROM custom_fields ORDER BY created_at, key`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	fields := []*entity.CustomField{}
+	for rows.Next() {
+		field, err := scanCustomField(rows)
+		if err != nil {
+			return nil, err
+		}
+		fields = append(fields, field)
+	}
+	return fields, rows.Err()
+}
+
+func (r *Repository) GetCustomField(id string) (*entity.CustomField, error) {
+	found, err := scanCustomField(r.db.QueryRow(`SELECT `+customFieldColumns+` FROM custom_fields WHERE id = ?`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, company.ErrNotFound
+	}
+	return found, err
+}
+
+func (r *Repository) CreateCustomField(field entity.CustomField) (*entity.CustomField, error) {
+	options, err := tagsJSON(field.Options)
+	if err != nil {
+		return nil, err
+	}
+	now := formatTime(time.Now())
+	created, err := scanCustomField(r.db.QueryRow(`
+		INSERT INTO custom_fields (id, key, label, type, options, required, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (key) DO NOTHING
+		RETURNING `+customFieldColumns,
+		uuid.NewString(), field.Key, field.Label, field.Type, options, field.Required, now, now))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, company.ErrFieldExists
+	}
+	return created, err
+}
+
+func (r *Repository) UpdateCustomField(id string, field entity.CustomField) (*entity.CustomField, error) {
+	options, err := tagsJSON(field.Options)
+	if err != nil {
+		return nil, err
+	}
+	updated, err := scanCustomField(r.db.QueryRow(`
+		UPDATE custom_fields
+		SET label = ?,
+			options = ?,
+			required = ?,
+			updated_at = ?
+		WHERE id = ?
+		RETURNING `+customFieldColumns,
+		field.Label, options, field.Required, formatTime(time.Now()), id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, company.ErrNotFound
+	}
+	return updated, err
+}
+
+func (r *Repository) DeleteCustomField(id string) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var key string
+	err = tx.QueryRow(`DELETE FROM custom_fields WHERE id = ? RETURNING key`, id).Scan(&key)
+	if errors.Is(err, sql.ErrNoRows) {
+		return company.ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+	// Keys are letters, digits and underscores, so they need no quoting in
+	// a JSON path.
+	_, err = tx.Exec(`
+		UPDATE companies SET custom_fields = json_remove(custom_fields, '$.' || ?1)
+		WHERE json_type(custom_fields, '$.' || ?1) IS NOT NULL`, key)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *Repository) ListTags() ([]*entity.TagCount, error) {
+	rows, err := r.db.Query(`
+		SELECT t.value, COUNT(*)
+		FROM companies, json_each(companies.tags) t
+		WHERE companies.deleted_at IS NULL
+		GROUP BY t.value
+		ORDER BY t.value`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tags := []*entity.TagCount{}
+	for rows.Next() {
+		var tag entity.TagCount
+		if err := rows.Scan(&tag.Tag, &tag.Companies); err != nil {
+			return nil, err
+		}
+		tags = append(tags, &tag)
+	}
+	return tags, rows.Err()
+}
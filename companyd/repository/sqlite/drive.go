@@ -0,0 +1,149 @@
+package sqlite
+
+import (
+	"backend/companyd/entity"
+	"backend/companyd/usecase/company"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const driveColumns = `id, company_id, type, season, starts_on, ends_on, roles, package, status, notes, created_at, updated_at`
+
+func scanDrive(row scanner) (*entity.Drive, error) {
+	var drive entity.Drive
+	var startsOn, endsOn sql.NullString
+	var roles string
+	err := row.Scan(&drive.ID, &drive.CompanyID, &drive.Type, &drive.Season, &startsOn, &endsOn, &roles, &drive.Package, &drive.Status, &drive.Notes, &drive.CreatedAt, &drive.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	if drive.StartsOn, err = parseNullTime(startsOn); err != nil {
+		return nil, err
+	}
+	if drive.EndsOn, err = parseNullTime(endsOn); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(roles), &drive.Roles); err != nil {
+		return nil, err
+	}
+	if drive.Roles == nil {
+		drive.Roles = []string{}
+	}
+	drive.CreatedAt = displayTime(drive.CreatedAt)
+	drive.UpdatedAt = displayTime(drive.UpdatedAt)
+	return &drive, nil
+}
+
+func rolesJSON(roles []string) (string, error) {
+	if roles == nil {
+		roles = []string{}
+	}
+	data, err := json.Marshal(roles)
+	return string(data), err
+}
+
+func (r *Repository) CreateDrive(drive entity.Drive) (*entity.Drive, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	created, err := insertDrive(tx, drive, formatTime(time.Now()))
+	if err != nil {
+		return nil, err
+	}
+	return created, tx.Commit()
+}
+
+func insertDrive(tx *sql.Tx, drive entity.Drive, now string) (*entity.Drive, error) {
+	roles, err := rolesJSON(drive.Roles)
+	if err != nil {
+		return nil, err
+	}
+	return scanDrive(tx.QueryRow(`
+		INSERT INTO drives (id, company_id, type, season, starts_on, ends_on, roles, package, status, notes, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		RETURNING `+driveColumns,
+		uuid.NewString(), drive.CompanyID, drive.Type, drive.Season, nullTime(drive.StartsOn), nullTime(drive.EndsOn), roles, drive.Package, drive.Status, drive.Notes, now, now))
+}
+
+func (r *Repository) GetDrive(id string) (*entity.Drive, error) {
+	found, err := scanDrive(r.db.QueryRow(`SELECT `+driveColumns+` FROM drives WHERE id = ?`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, company.ErrNotFound
+	}
+	return found, err
+}
+
+func (r *Repository) ListDrives(filter company.DriveFilter) ([]*entity.Drive, error) {
+	f := &listFilter{}
+	if filter.CompanyID != "" {
+		f.add("company_id = ?", filter.CompanyID)
+	}
+	if filter.Season != "" {
+		f.add("season = ?", filter.Season)
+	}
+	if filter.Type != "" {
+		f.add("type = ?", filter.Type)
+	}
+	if filter.Status != "" {
+		f.add("status = ?", filter.Status)
+	}
+
+	rows, err := r.db.Query(`SELECT `+driveColumns+` FROM drives`+f.where()+` ORDER BY starts_on IS NULL, starts_on, created_at, rowid`, f.args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	drives := []*entity.Drive{}
+	for rows.Next() {
+		drive, err := scanDrive(rows)
+		if err != nil {
+			return nil, err
+		}
+		drives = append(drives, drive)
+	}
+	return drives, rows.Err()
+}
+
+func (r *Repository) UpdateDrive(id string, drive entity.Drive) (*entity.Drive, error) {
+	roles, err := rolesJSON(drive.Roles)
+	if err != nil {
+		return nil, err
+	}
+	updated, err := scanDrive(r.db.QueryRow(`
+		UPDATE drives
+		SET type = ?,
+			season = ?,
+			starts_on = ?,
+			ends_on = ?,
+			roles = ?,
+			package = ?,
+			status = ?,
+			notes = ?,
+			updated_at = ?
+		WHERE id = ?
+		RETURNING `+driveColumns,
+		drive.Type, drive.Season, nullTime(drive.StartsOn), nullTime(drive.EndsOn), roles, drive.Package, drive.Status, drive.Notes, formatTime(time.Now()), id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, company.ErrNotFound
+	}
+	return updated, err
+}
+
+func (r *Repository) DeleteDrive(id string) error {
+	result, err := r.db.Exec(`DELETE FROM drives WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return company.ErrNotFound
+	}
+	return nil
+}
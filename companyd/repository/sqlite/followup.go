@@ -0,0 +1,256 @@
+package sqlite
+
+import (
+	"backend/companyd/entity"
+	"backend/companyd/usecase/company"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const followUpColumns = `id, company_id, officer, due_at, note, status, completed_by, completed_at, reminded_at, created_at, updated_at`
+
+const notificationColumns = `id, recipient, COALESCE(follow_up_id, ''), COALESCE(company_id, ''), message, read, created_at`
+
+func scanFollowUp(row scanner) (*entity.FollowUp, error) {
+	var followUp entity.FollowUp
+	var dueAt string
+	var completedAt, remindedAt sql.NullString
+	err := row.Scan(&followUp.ID, &followUp.CompanyID, &followUp.Officer, &dueAt, &followUp.Note, &followUp.Status, &followUp.CompletedBy, &completedAt, &remindedAt, &followUp.CreatedAt, &followUp.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	if followUp.DueAt, err = time.Parse(timeLayout, dueAt); err != nil {
+		return nil, err
+	}
+	if followUp.CompletedAt, err = parseNullTime(completedAt); err != nil {
+		return nil, err
+	}
+	if followUp.RemindedAt, err = parseNullTime(remindedAt); err != nil {
+		return nil, err
+	}
+	followUp.CreatedAt = displayTime(followUp.CreatedAt)
+	followUp.UpdatedAt = displayTime(followUp.UpdatedAt)
+	return &followUp, nil
+}
+
+func scanNotification(row scanner) (*entity.Notification, error) {
+	var notification entity.Notification
+	err := row.Scan(&notification.ID, &notification.Recipient, &notification.FollowUpID, &notification.CompanyID, &notification.Message, &notification.Read, &notification.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	notification.CreatedAt = displayTime(notification.CreatedAt)
+	return &notification, nil
+}
+
+func parseNullTime(stored sql.NullString) (*time.Time, error) {
+	if !stored.Valid {
+		return nil, nil
+	}
+	t, err := time.Parse(timeLayout, stored.String)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// nullTime stores an optional time in the fixed-width layout.
+func nullTime(t *time.Time) interface{} {
+	if t == nil {
+		return nil
+	}
+	return formatTime(*t)
+}
+
+func (r *Repository) CreateFollowUp(followUp entity.FollowUp) (*entity.FollowUp, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	created, err := insertFollowUp(tx, followUp, formatTime(time.Now()))
+	if err != nil {
+		return nil, err
+	}
+	return created, tx.Commit()
+}
+
+func insertFollowUp(tx *sql.Tx, followUp entity.FollowUp, now string) (*entity.FollowUp, error) {
+	return scanFollowUp(tx.QueryRow(`
+		INSERT INTO follow_ups (id, company_id, officer, due_at, note, status, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		RETURNING `+followUpColumns,
+		uuid.NewString(), followUp.CompanyID, followUp.Officer, formatTime(followUp.DueAt), followUp.Note, followUp.Status, now, now))
+}
+
+func (r *Repository) GetFollowUp(id string) (*entity.FollowUp, error) {
+	found, err := scanFollowUp(r.db.QueryRow(`SELECT `+followUpColumns+` FROM follow_ups WHERE id = ?`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, company.ErrNotFound
+	}
+	return found, err
+}
+
+func (r *Repository) ListFollowUps(filter company.FollowUpFilter) ([]*entity.FollowUp, error) {
+	f := &listFilter{}
+	if filter.CompanyID != "" {
+		f.add("company_id = ?", filter.CompanyID)
+	}
+	if filter.Officer != "" {
+		f.add("officer = ?", filter.Officer)
+	}
+	if filter.Status != "" {
+		f.add("status = ?", filter.Status)
+	}
+	if filter.DueAfter != nil {
+		f.add("due_at >= ?", formatTime(*filter.DueAfter))
+	}
+	if filter.DueBefore != nil {
+		f.add("due_at < ?", formatTime(*filter.DueBefore))
+	}
+
+	rows, err := r.db.Query(`SELECT `+followUpColumns+` FROM follow_ups`+f.where()+` ORDER BY due_at, created_at, rowid`, f.args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	followUps := []*entity.FollowUp{}
+	for rows.Next() {
+		followUp, err := scanFollowUp(rows)
+		if err != nil {
+			return nil, err
+		}
+		followUps = append(followUps, followUp)
+	}
+	return followUps, rows.Err()
+}
+
+func (r *Repository) UpdateFollowUp(id string, followUp entity.FollowUp) (*entity.FollowUp, error) {
+	updated, err := scanFollowUp(r.db.QueryRow(`
+		UPDATE follow_ups
+		SET officer = ?,
+			due_at = ?,
+			note = ?,
+			status = ?,
+			completed_by = ?,
+			completed_at = ?,
+			reminded_at = ?,
+			updated_at = ?
+		WHERE id = ?
+		RETURNING `+followUpColumns,
+		followUp.Officer, formatTime(followUp.DueAt), followUp.Note, followUp.Status, followUp.CompletedBy, nullTime(followUp.CompletedAt), nullTime(followUp.RemindedAt), formatTime(time.Now()), id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, company.ErrNotFound
+	}
+	return updated, err
+}
+
+func (r *Repository) DeleteFollowUp(id string) error {
+	result, err := r.db.Exec(`DELETE FROM follow_ups WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return company.ErrNotFound
+	}
+	return nil
+}
+
+// CreateReminders claims each follow-up with a conditional update before
+// notifying, so a reminder is sent once even if two schedulers overlap.
+// Follow-ups of companies in the trash are held back until they are restored.
+func (r *Repository) CreateReminders(dueBefore time.Time) ([]*entity.Notification, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.Query(`
+		SELECT `+followUpColumns+`
+		FROM follow_ups
+		WHERE status = ? AND reminded_at IS NULL AND due_at < ?
+			AND company_id IN (SELECT id FROM companies WHERE deleted_at IS NULL)
+		ORDER BY due_at, created_at, rowid`,
+		company.FollowUpOpen, formatTime(dueBefore))
+	if err != nil {
+		return nil, err
+	}
+	var due []*entity.FollowUp
+	for rows.Next() {
+		followUp, err := scanFollowUp(rows)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+		due = append(due, followUp)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	sent := []*entity.Notification{}
+	for _, followUp := range due {
+		result, err := tx.Exec(`UPDATE follow_ups SET reminded_at = ? WHERE id = ? AND reminded_at IS NULL`, formatTime(now), followUp.ID)
+		if err != nil {
+			return nil, err
+		}
+		if n, err := result.RowsAffected(); err != nil || n == 0 {
+			continue
+		}
+		var name string
+		if err := tx.QueryRow(`SELECT COALESCE(company_name, '') FROM companies WHERE id = ?`, followUp.CompanyID).Scan(&name); err != nil {
+			return nil, err
+		}
+		notification, err := scanNotification(tx.QueryRow(`
+			INSERT INTO notifications (id, recipient, follow_up_id, company_id, message, created_at)
+			VALUES (?, ?, ?, ?, ?, ?)
+			RETURNING `+notificationColumns,
+			uuid.NewString(), followUp.Officer, followUp.ID, followUp.CompanyID, company.ReminderMessage(name, followUp, now), formatTime(now)))
+		if err != nil {
+			return nil, err
+		}
+		sent = append(sent, notification)
+	}
+	return sent, tx.Commit()
+}
+
+func (r *Repository) ListNotifications(recipient string, unreadOnly bool) ([]*entity.Notification, error) {
+	query := `SELECT ` + notificationColumns + ` FROM notifications WHERE recipient = ?`
+	if unreadOnly {
+		query += ` AND NOT read`
+	}
+	rows, err := r.db.Query(query+` ORDER BY created_at DESC, rowid DESC`, recipient)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	notifications := []*entity.Notification{}
+	for rows.Next() {
+		notification, err := scanNotification(rows)
+		if err != nil {
+			return nil, err
+		}
+		notifications = append(notifications, notification)
+	}
+	return notifications, rows.Err()
+}
+
+func (r *Repository) MarkNotificationRead(id, recipient string) error {
+	result, err := r.db.Exec(`UPDATE notifications SET read = 1 WHERE id = ? AND recipient = ?`, id, recipient)
+	if err != nil {
+		return err
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return company.ErrNotFound
+	}
+	return nil
+}
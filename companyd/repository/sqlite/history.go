@@ -0,0 +1,57 @@
+package sqlite
+
+import (
+	"backend/companyd/entity"
+	"database/sql"
+	"encoding/json"
+
+	"github.com/google/uuid"
+)
+
+const revisionColumns = `id, company_id, version, changed_by, source, proposal_id, reverted_to, merged_from, snapshot, created_at`
+
+func scanRevision(row scanner) (*entity.CompanyRevision, error) {
+	var revision entity.CompanyRevision
+	var snapshot string
+	err := row.Scan(&revision.ID, &revision.CompanyID, &revision.Version, &revision.ChangedBy, &revision.Source, &revision.ProposalID, &revision.RevertedTo, &revision.MergedFrom, &snapshot, &revision.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(snapshot), &revision.Snapshot); err != nil {
+		return nil, err
+	}
+	revision.CreatedAt = displayTime(revision.CreatedAt)
+	return &revision, nil
+}
+
+// recordRevision snapshots c, as just written in tx, into its history. The
+// revision is dated with the company's updated_at.
+func recordRevision(tx *sql.Tx, c *entity.Company, change entity.CompanyChange) error {
+	snapshot, err := json.Marshal(entity.NewCompanySnapshot(c))
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(`
+		INSERT INTO company_history (id, company_id, version, changed_by, source, proposal_id, reverted_to, merged_from, snapshot, created_at)
+		VALUES (?1, ?2, ?3, ?4, ?5, ?6, ?7, ?8, ?9, (SELECT updated_at FROM companies WHERE id = ?2))`,
+		uuid.NewString(), c.ID, c.Version, change.ChangedBy, change.Source, change.ProposalID, change.RevertedTo, change.MergedFrom, string(snapshot))
+	return err
+}
+
+func (r *Repository) ListCompanyRevisions(companyID string) ([]*entity.CompanyRevision, error) {
+	rows, err := r.db.Query(`SELECT `+revisionColumns+` FROM company_history WHERE company_id = ? ORDER BY version`, companyID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	revisions := []*entity.CompanyRevision{}
+	for rows.Next() {
+		revision, err := scanRevision(rows)
+		if err != nil {
+			return nil, err
+		}
+		revisions = append(revisions, revision)
+	}
+	return revisions, rows.Err()
+}
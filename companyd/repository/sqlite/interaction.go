@@ -0,0 +1,96 @@
+package sqlite
+
+import (
+	"backend/companyd/entity"
+	"backend/companyd/usecase/company"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const interactionColumns = `id, company_id, type, occurred_at, officer, outcome, notes, next_step, created_at`
+
+func scanInteraction(row scanner) (*entity.Interaction, error) {
+	var interaction entity.Interaction
+	var occurredAt string
+	err := row.Scan(&interaction.ID, &interaction.CompanyID, &interaction.Type, &occurredAt, &interaction.Officer, &interaction.Outcome, &interaction.Notes, &interaction.NextStep, &interaction.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	if interaction.OccurredAt, err = time.Parse(timeLayout, occurredAt); err != nil {
+		return nil, err
+	}
+	interaction.CreatedAt = displayTime(interaction.CreatedAt)
+	return &interaction, nil
+}
+
+// CreateInteraction inserts the interaction and moves the company's last
+// interaction forward in the same transaction, without touching version or
+// updated_at. The fixed-width time layout makes the text comparison safe.
+func (r *Repository) CreateInteraction(interaction entity.Interaction) (*entity.Interaction, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	created, err := insertInteraction(tx, interaction, formatTime(time.Now()))
+	if err != nil {
+		return nil, err
+	}
+	return created, tx.Commit()
+}
+
+func insertInteraction(tx *sql.Tx, interaction entity.Interaction, now string) (*entity.Interaction, error) {
+	created, err := scanInteraction(tx.QueryRow(`
+		INSERT INTO interactions (id, company_id, type, occurred_at, officer, outcome, notes, next_step, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+		RETURNING `+interactionColumns,
+		uuid.NewString(), interaction.CompanyID, interaction.Type, formatTime(interaction.OccurredAt), interaction.Officer, interaction.Outcome, interaction.Notes, interaction.NextStep, now))
+	if err != nil {
+		return nil, err
+	}
+	occurredAt := formatTime(created.OccurredAt)
+	_, err = tx.Exec(`
+		UPDATE companies
+		SET last_interaction_at = ?, last_interaction_outcome = ?
+		WHERE id = ? AND (last_interaction_at IS NULL OR last_interaction_at <= ?)`,
+		occurredAt, created.Outcome, created.CompanyID, occurredAt)
+	return created, err
+}
+
+func (r *Repository) ListInteractions(filter company.InteractionFilter) ([]*entity.Interaction, error) {
+	f := &listFilter{}
+	if filter.CompanyID != "" {
+		f.add("company_id = ?", filter.CompanyID)
+	}
+	if filter.Officer != "" {
+		f.add("officer = ?", filter.Officer)
+	}
+	if filter.Type != "" {
+		f.add("type = ?", filter.Type)
+	}
+	if filter.Since != nil {
+		f.add("occurred_at >= ?", *filter.Since)
+	}
+	if filter.Until != nil {
+		f.add("occurred_at < ?", *filter.Until)
+	}
+
+	rows, err := r.db.Query(`SELECT `+interactionColumns+` FROM interactions`+f.where()+` ORDER BY occurred_at DESC, created_at DESC, rowid DESC`, f.args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	interactions := []*entity.Interaction{}
+	for rows.Next() {
+		interaction, err := scanInteraction(rows)
+		if err != nil {
+			return nil, err
+		}
+		interactions = append(interactions, interaction)
+	}
+	return interactions, rows.Err()
+}
@@ -0,0 +1,171 @@
+package sqlite
+
+import (
+	"backend/companyd/entity"
+	"backend/companyd/usecase/company"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// sortExpression returns the SQL a listing is ordered by. Nullable text
+// columns are coalesced so that keyset comparisons never meet a NULL.
+func sortExpression(key string) string {
+	switch key {
+	case "is_contacted", "created_at", "updated_at":
+		return key
+	case "package":
+		return "COALESCE(package_amount, -1)"
+	case "last_interaction_at":
+		// The zero time.Time, matching company.SortValue.
+		return "COALESCE(last_interaction_at, '" + formatTime(time.Time{}) + "')"
+	default:
+		return "COALESCE(" + key + ", '')"
+	}
+}
+
+// bindValue converts a filter or cursor value to its stored representation.
+func bindValue(v interface{}) interface{} {
+	if t, ok := v.(time.Time); ok {
+		return formatTime(t)
+	}
+	return v
+}
+
+type listFilter struct {
+	clauses []string
+	args    []interface{}
+}
+
+func (f *listFilter) add(clause string, args ...interface{}) {
+	f.clauses = append(f.clauses, clause)
+	for _, arg := range args {
+		f.args = append(f.args, bindValue(arg))
+	}
+}
+
+func (f *listFilter) where() string {
+	if len(f.clauses) == 0 {
+		return ""
+	}
+	return " WHERE " + strings.Join(f.clauses, " AND ")
+}
+
+func queryFilter(q company.ListQuery) *listFilter {
+	f := &listFilter{}
+	f.add("deleted_at IS NULL")
+	switch q.Archived {
+	case company.ArchivedExclude:
+		f.add("archived_at IS NULL")
+	case company.ArchivedOnly:
+		f.add("archived_at IS NOT NULL")
+	}
+	if q.Drive != "" {
+		f.add("drive = ?", q.Drive)
+	}
+	if q.TypeOfDrive != "" {
+		f.add("type_of_drive = ?", q.TypeOfDrive)
+	}
+	if q.IsContacted != nil {
+		f.add("is_contacted = ?", *q.IsContacted)
+	}
+	if q.PipelineStatus != "" {
+		f.add("pipeline_status = ?", q.PipelineStatus)
+	}
+	if q.Season != "" {
+		f.add("season = ?", q.Season)
+	}
+	if q.Officer != "" {
+		f.add(officerFilter, q.Officer)
+	}
+	for _, tag := range q.Tags {
+		f.add("EXISTS (SELECT 1 FROM json_each(companies.tags) WHERE value = ?)", tag)
+	}
+	for _, field := range q.Fields {
+		if field.Multi {
+			f.add("EXISTS (SELECT 1 FROM json_each(companies.custom_fields) f, json_each(f.value) o WHERE f.key = ? AND o.value = ?)", field.Key, field.Value)
+		} else {
+			f.add("EXISTS (SELECT 1 FROM json_each(companies.custom_fields) WHERE key = ? AND value = ?)", field.Key, field.Value)
+		}
+	}
+	if currency := company.PackageBoundsCurrency(q); currency != "" {
+		f.add("package_currency = ?", currency)
+	}
+	if q.PackageMin != nil {
+		f.add("package_amount >= ?", *q.PackageMin)
+	}
+	if q.PackageMax != nil {
+		f.add("package_amount <= ?", *q.PackageMax)
+	}
+	if q.PackageNeedsReview != nil {
+		f.add("package_needs_review = ?", *q.PackageNeedsReview)
+	}
+	if q.CreatedAfter != nil {
+		f.add("created_at >= ?", *q.CreatedAfter)
+	}
+	if q.CreatedBefore != nil {
+		f.add("created_at < ?", *q.CreatedBefore)
+	}
+	if q.UpdatedAfter != nil {
+		f.add("updated_at >= ?", *q.UpdatedAfter)
+	}
+	if q.UpdatedBefore != nil {
+		f.add("updated_at < ?", *q.UpdatedBefore)
+	}
+	if q.LastInteractionAfter != nil {
+		f.add("last_interaction_at >= ?", *q.LastInteractionAfter)
+	}
+	if q.LastInteractionBefore != nil {
+		f.add("(last_interaction_at IS NULL OR last_interaction_at < ?)", *q.LastInteractionBefore)
+	}
+	return f
+}
+
+func (r *Repository) QueryCompanies(q company.ListQuery) (*company.CompanyPage, error) {
+	sortKey := q.Sort
+	if sortKey == "" {
+		sortKey = company.DefaultSort
+	}
+	expr := sortExpression(sortKey)
+	direction, comparison := "ASC", ">"
+	if q.Desc {
+		direction, comparison = "DESC", "<"
+	}
+
+	f := queryFilter(q)
+	page := &company.CompanyPage{Companies: []*entity.Company{}}
+	if err := r.db.QueryRow(`SELECT COUNT(*) FROM companies`+f.where(), f.args...).Scan(&page.Total); err != nil {
+		return nil, err
+	}
+
+	if q.After != nil {
+		f.add("("+expr+", id) "+comparison+" (?, ?)", q.After.Value, q.After.ID)
+	}
+	query := `SELECT ` + companyColumns + ` FROM companies` + f.where() + ` ORDER BY ` + expr + ` ` + direction + `, id ` + direction
+	if q.Limit > 0 {
+		// Fetch one extra row to learn whether there is a next page.
+		query += ` LIMIT ` + strconv.Itoa(q.Limit+1)
+	}
+
+	rows, err := r.db.Query(query, f.args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		c, err := scanCompany(rows)
+		if err != nil {
+			return nil, err
+		}
+		page.Companies = append(page.Companies, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if q.Limit > 0 && len(page.Companies) > q.Limit {
+		page.Companies = page.Companies[:q.Limit]
+		page.NextCursor = company.EncodeCursor(q, page.Companies[len(page.Companies)-1])
+	}
+	return page, nil
+}
@@ -0,0 +1,171 @@
+package sqlite
+
+import (
+	"backend/companyd/entity"
+	"backend/companyd/usecase/company"
+	"database/sql"
+	"strings"
+	"time"
+)
+
+// assignedOfficerColumn reads Company.AssignedOfficer from company_officers
+// as a JSON array, primary officer first.
+const assignedOfficerColumn = `(SELECT json_group_array(u.username ORDER BY o.position) FROM company_officers o JOIN users u ON u.id = o.user_id WHERE o.company_id = companies.id)`
+
+// officerFilter matches the companies assigned to a username.
+const officerFilter = `EXISTS (SELECT 1 FROM company_officers o JOIN users u ON u.id = o.user_id WHERE o.company_id = companies.id AND u.username = ?)`
+
+// userIDs looks up the users with the given usernames.
+func userIDs(q interface {
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+}, usernames []string) (map[string]string, error) {
+	ids := map[string]string{}
+	if len(usernames) == 0 {
+		return ids, nil
+	}
+	args := make([]interface{}, len(usernames))
+	for i, username := range usernames {
+		args[i] = username
+	}
+	rows, err := q.Query(`SELECT id, username FROM users WHERE username IN (?`+strings.Repeat(", ?", len(usernames)-1)+`)`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var id, username string
+		if err := rows.Scan(&id, &username); err != nil {
+			return nil, err
+		}
+		ids[username] = id
+	}
+	return ids, rows.Err()
+}
+
+// setOfficers makes usernames the officers of a company, the first as its
+// primary officer. Officers who stay assigned keep their assigned_at and
+// assigned_by.
+func setOfficers(tx *sql.Tx, companyID string, usernames []string, assignedBy string) error {
+	usernames = company.UniqueOfficers(usernames)
+	ids, err := userIDs(tx, usernames)
+	if err != nil {
+		return err
+	}
+	var unknown []string
+	keep := map[string]bool{}
+	for _, username := range usernames {
+		if id, ok := ids[username]; ok {
+			keep[id] = true
+		} else {
+			unknown = append(unknown, username)
+		}
+	}
+	if len(unknown) > 0 {
+		return company.UnknownOfficersError(unknown)
+	}
+
+	rows, err := tx.Query(`SELECT user_id FROM company_officers WHERE company_id = ?`, companyID)
+	if err != nil {
+		return err
+	}
+	var dropped []string
+	for rows.Next() {
+		var userID string
+		if err := rows.Scan(&userID); err != nil {
+			rows.Close()
+			return err
+		}
+		if !keep[userID] {
+			dropped = append(dropped, userID)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	for _, userID := range dropped {
+		if _, err := tx.Exec(`DELETE FROM company_officers WHERE company_id = ? AND user_id = ?`, companyID, userID); err != nil {
+			return err
+		}
+	}
+
+	// Demote everyone first: a company has at most one primary officer.
+	if _, err := tx.Exec(`UPDATE company_officers SET role = ? WHERE company_id = ?`, company.OfficerSecondary, companyID); err != nil {
+		return err
+	}
+	now := formatTime(time.Now())
+	for i, username := range usernames {
+		_, err := tx.Exec(`
+			INSERT INTO company_officers (company_id, user_id, role, position, assigned_at, assigned_by)
+			VALUES (?, ?, ?, ?, ?, ?)
+			ON CONFLICT (company_id, user_id) DO UPDATE SET role = excluded.role, position = excluded.position`,
+			companyID, ids[username], company.OfficerRole(i), i, now, assignedBy)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// UnknownOfficers returns the usernames that are not users.
+func (r *Repository) UnknownOfficers(usernames []string) ([]string, error) {
+	usernames = company.UniqueOfficers(usernames)
+	ids, err := userIDs(r.db, usernames)
+	if err != nil {
+		return nil, err
+	}
+	var unknown []string
+	for _, username := range usernames {
+		if _, ok := ids[username]; !ok {
+			unknown = append(unknown, username)
+		}
+	}
+	return unknown, nil
+}
+
+func (r *Repository) ListCompanyOfficers(companyID string) ([]*entity.OfficerAssignment, error) {
+	rows, err := r.db.Query(`
+		SELECT o.user_id, u.username, o.role, o.assigned_at, o.assigned_by
+		FROM company_officers o JOIN users u ON u.id = o.user_id
+		WHERE o.company_id = ?
+		ORDER BY o.position`, companyID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	officers := []*entity.OfficerAssignment{}
+	for rows.Next() {
+		var officer entity.OfficerAssignment
+		var assignedAt string
+		if err := rows.Scan(&officer.UserID, &officer.Username, &officer.Role, &assignedAt, &officer.AssignedBy); err != nil {
+			return nil, err
+		}
+		if officer.AssignedAt, err = time.Parse(timeLayout, assignedAt); err != nil {
+			return nil, err
+		}
+		officers = append(officers, &officer)
+	}
+	return officers, rows.Err()
+}
+
+func (r *Repository) ListOfficerUsernames() ([]string, error) {
+	rows, err := r.db.Query(`
+		SELECT username FROM users
+		WHERE lower(role) = lower(?)
+		ORDER BY username`, company.OfficerUserRole)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	usernames := []string{}
+	for rows.Next() {
+		var username string
+		if err := rows.Scan(&username); err != nil {
+			return nil, err
+		}
+		usernames = append(usernames, username)
+	}
+	return usernames, rows.Err()
+}
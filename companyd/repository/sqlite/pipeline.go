@@ -0,0 +1,59 @@
+package sqlite
+
+import (
+	"backend/companyd/entity"
+	"backend/companyd/usecase/company"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const transitionColumns = `id, company_id, from_status, to_status, changed_by, changed_at`
+
+// recordTransition records, in tx, a company moving from one pipeline stage
+// to another.
+func recordTransition(tx *sql.Tx, companyID, from, to, by string) error {
+	return recordTransitionAt(tx, companyID, from, to, by, formatTime(time.Now()))
+}
+
+func recordTransitionAt(tx *sql.Tx, companyID, from, to, by, at string) error {
+	_, err := tx.Exec(`
+		INSERT INTO company_status_transitions (id, company_id, from_status, to_status, changed_by, changed_at)
+		VALUES (?, ?, ?, ?, ?, ?)`,
+		uuid.NewString(), companyID, from, to, by, at)
+	return err
+}
+
+func (r *Repository) ListStatusTransitions(filter company.StatusTransitionFilter) ([]*entity.StatusTransition, error) {
+	f := &listFilter{}
+	if filter.CompanyID != "" {
+		f.add("company_id = ?", filter.CompanyID)
+	}
+	if filter.Season != "" {
+		f.add("company_id IN (SELECT id FROM companies WHERE season = ? AND deleted_at IS NULL)", filter.Season)
+	}
+	if filter.Before != nil {
+		f.add("changed_at < ?", formatTime(*filter.Before))
+	}
+
+	rows, err := r.db.Query(`SELECT `+transitionColumns+` FROM company_status_transitions`+f.where()+` ORDER BY changed_at, rowid`, f.args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	transitions := []*entity.StatusTransition{}
+	for rows.Next() {
+		var t entity.StatusTransition
+		var changedAt string
+		if err := rows.Scan(&t.ID, &t.CompanyID, &t.From, &t.To, &t.ChangedBy, &changedAt); err != nil {
+			return nil, err
+		}
+		if t.ChangedAt, err = time.Parse(timeLayout, changedAt); err != nil {
+			return nil, err
+		}
+		transitions = append(transitions, &t)
+	}
+	return transitions, rows.Err()
+}
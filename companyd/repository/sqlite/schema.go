@@ -0,0 +1,760 @@
+package sqlite
+
+import (
+	"backend/companyd/entity"
+	"backend/companyd/usecase/company"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+)
+
+// schema mirrors the company tables in init.sql. UUIDs and timestamps are
+// generated in Go, and the legacy assigned_officer is stored as a JSON array.
+// company_officers references the users table of userd's SQLite repository.
+const schema = `
+CREATE TABLE IF NOT EXISTS companies (
+    id                TEXT PRIMARY KEY,
+    company_name      TEXT,
+    company_address   TEXT,
+    drive             TEXT,
+    type_of_drive     TEXT,
+    follow_up         TEXT,
+    is_contacted      BOOLEAN NOT NULL DEFAULT 0,
+    remarks           TEXT,
+    contact_details   TEXT,
+    hr1_details       TEXT,
+    hr2_details       TEXT,
+    package           TEXT,
+    assigned_officer  TEXT NOT NULL DEFAULT '[]',
+    version           INTEGER NOT NULL DEFAULT 1,
+    package_amount    REAL,
+    package_base      REAL,
+    package_variable  REAL,
+    package_stipend   REAL,
+    package_currency  TEXT NOT NULL DEFAULT '',
+    package_unit      TEXT NOT NULL DEFAULT '',
+    package_min       REAL,
+    package_max       REAL,
+    package_needs_review BOOLEAN NOT NULL DEFAULT 0,
+    last_interaction_at  TEXT,
+    last_interaction_outcome TEXT NOT NULL DEFAULT '',
+    archived_at       TEXT,
+    archived_by       TEXT NOT NULL DEFAULT '',
+    deleted_at        TEXT,
+    deleted_by        TEXT NOT NULL DEFAULT '',
+    created_at        TEXT NOT NULL,
+    updated_at        TEXT NOT NULL,
+    pipeline_status   TEXT NOT NULL DEFAULT 'prospect',
+    season            TEXT NOT NULL DEFAULT '',
+    carried_from      TEXT REFERENCES companies(id) ON DELETE SET NULL,
+    custom_fields     TEXT NOT NULL DEFAULT '{}',
+    tags              TEXT NOT NULL DEFAULT '[]'
+);
+
+CREATE TABLE IF NOT EXISTS companies_temp (
+    id                TEXT PRIMARY KEY,
+    company_id        TEXT REFERENCES companies(id),
+    company_name      TEXT,
+    company_address   TEXT,
+    drive             TEXT,
+    type_of_drive     TEXT,
+    follow_up         TEXT,
+    is_contacted      BOOLEAN NOT NULL DEFAULT 0,
+    remarks           TEXT,
+    contact_details   TEXT,
+    hr1_details       TEXT,
+    hr2_details       TEXT,
+    package           TEXT,
+    assigned_officer  TEXT NOT NULL DEFAULT '[]',
+    status            TEXT DEFAULT 'pending',
+    base_version      INTEGER,
+    created_by        TEXT,
+    created_at        TEXT NOT NULL,
+    updated_at        TEXT NOT NULL,
+    season            TEXT NOT NULL DEFAULT ''
+);
+
+CREATE TABLE IF NOT EXISTS events (
+    id          TEXT PRIMARY KEY,
+    date        TEXT NOT NULL,
+    type        TEXT NOT NULL,
+    title       TEXT NOT NULL,
+    description TEXT,
+    company_id  TEXT REFERENCES companies(id) ON DELETE SET NULL,
+    created_by  TEXT NOT NULL,
+    created_at  TEXT NOT NULL,
+    season      TEXT NOT NULL DEFAULT ''
+);
+
+CREATE TABLE IF NOT EXISTS contacts (
+    id          TEXT PRIMARY KEY,
+    company_id  TEXT NOT NULL REFERENCES companies(id) ON DELETE CASCADE,
+    name        TEXT NOT NULL,
+    designation TEXT NOT NULL DEFAULT '',
+    email       TEXT NOT NULL DEFAULT '',
+    phone       TEXT NOT NULL DEFAULT '',
+    linkedin    TEXT NOT NULL DEFAULT '',
+    is_primary  BOOLEAN NOT NULL DEFAULT 0,
+    notes       TEXT NOT NULL DEFAULT '',
+    created_at  TEXT NOT NULL,
+    updated_at  TEXT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS follow_ups (
+    id           TEXT PRIMARY KEY,
+    company_id   TEXT NOT NULL REFERENCES companies(id) ON DELETE CASCADE,
+    officer      TEXT NOT NULL,
+    due_at       TEXT NOT NULL,
+    note         TEXT NOT NULL DEFAULT '',
+    status       TEXT NOT NULL DEFAULT 'open',
+    completed_by TEXT NOT NULL DEFAULT '',
+    completed_at TEXT,
+    reminded_at  TEXT,
+    created_at   TEXT NOT NULL,
+    updated_at   TEXT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS notifications (
+    id           TEXT PRIMARY KEY,
+    recipient    TEXT NOT NULL,
+    follow_up_id TEXT REFERENCES follow_ups(id) ON DELETE CASCADE,
+    company_id   TEXT REFERENCES companies(id) ON DELETE CASCADE,
+    message      TEXT NOT NULL,
+    read         BOOLEAN NOT NULL DEFAULT 0,
+    created_at   TEXT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS interactions (
+    id          TEXT PRIMARY KEY,
+    company_id  TEXT NOT NULL REFERENCES companies(id) ON DELETE CASCADE,
+    type        TEXT NOT NULL,
+    occurred_at TEXT NOT NULL,
+    officer     TEXT NOT NULL,
+    outcome     TEXT NOT NULL DEFAULT '',
+    notes       TEXT NOT NULL DEFAULT '',
+    next_step   TEXT NOT NULL DEFAULT '',
+    created_at  TEXT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS company_history (
+    id          TEXT PRIMARY KEY,
+    company_id  TEXT NOT NULL REFERENCES companies(id) ON DELETE CASCADE,
+    version     INTEGER NOT NULL,
+    changed_by  TEXT NOT NULL DEFAULT '',
+    source      TEXT NOT NULL,
+    proposal_id TEXT NOT NULL DEFAULT '',
+    reverted_to INTEGER NOT NULL DEFAULT 0,
+    merged_from TEXT NOT NULL DEFAULT '',
+    snapshot    TEXT NOT NULL,
+    created_at  TEXT NOT NULL,
+    UNIQUE (company_id, version)
+);
+
+CREATE TABLE IF NOT EXISTS company_officers (
+    company_id  TEXT NOT NULL REFERENCES companies(id) ON DELETE CASCADE,
+    user_id     TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    role        TEXT NOT NULL CHECK (role IN ('primary', 'secondary')),
+    position    INTEGER NOT NULL,
+    assigned_at TEXT NOT NULL,
+    assigned_by TEXT NOT NULL DEFAULT '',
+    PRIMARY KEY (company_id, user_id)
+);
+
+CREATE TABLE IF NOT EXISTS drives (
+    id          TEXT PRIMARY KEY,
+    company_id  TEXT NOT NULL REFERENCES companies(id) ON DELETE CASCADE,
+    type        TEXT NOT NULL CHECK (type IN ('on-campus', 'off-campus', 'pool', 'virtual')),
+    season      TEXT NOT NULL DEFAULT '',
+    starts_on   TEXT,
+    ends_on     TEXT,
+    roles       TEXT NOT NULL DEFAULT '[]',
+    package     TEXT NOT NULL DEFAULT '',
+    status      TEXT NOT NULL DEFAULT 'planned',
+    notes       TEXT NOT NULL DEFAULT '',
+    created_at  TEXT NOT NULL,
+    updated_at  TEXT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS company_status_transitions (
+    id          TEXT PRIMARY KEY,
+    company_id  TEXT NOT NULL REFERENCES companies(id) ON DELETE CASCADE,
+    from_status TEXT NOT NULL DEFAULT '',
+    to_status   TEXT NOT NULL,
+    changed_by  TEXT NOT NULL DEFAULT '',
+    changed_at  TEXT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS seasons (
+    name        TEXT PRIMARY KEY,
+    active      BOOLEAN NOT NULL DEFAULT 0,
+    created_at  TEXT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS custom_fields (
+    id          TEXT PRIMARY KEY,
+    key         TEXT NOT NULL UNIQUE,
+    label       TEXT NOT NULL,
+    type        TEXT NOT NULL,
+    options     TEXT NOT NULL DEFAULT '[]',
+    required    BOOLEAN NOT NULL DEFAULT 0,
+    created_at  TEXT NOT NULL,
+    updated_at  TEXT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS attachments (
+    id           TEXT PRIMARY KEY,
+    company_id   TEXT NOT NULL REFERENCES companies(id) ON DELETE CASCADE,
+    filename     TEXT NOT NULL,
+    content_type TEXT NOT NULL,
+    size         INTEGER NOT NULL,
+    checksum     TEXT NOT NULL,
+    storage_key  TEXT NOT NULL UNIQUE,
+    uploaded_by  TEXT NOT NULL DEFAULT '',
+    created_at   TEXT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS schema_migrations (
+    name        TEXT PRIMARY KEY,
+    applied_at  TEXT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_companies_name ON companies(company_name);
+CREATE INDEX IF NOT EXISTS idx_companies_drive ON companies(drive);
+CREATE INDEX IF NOT EXISTS idx_companies_is_contacted ON companies(is_contacted);
+CREATE INDEX IF NOT EXISTS idx_companies_type_of_drive ON companies(type_of_drive);
+CREATE INDEX IF NOT EXISTS idx_companies_created_at ON companies(created_at, id);
+CREATE INDEX IF NOT EXISTS idx_companies_updated_at ON companies(updated_at, id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_company_officers_primary ON company_officers(company_id) WHERE role = 'primary';
+CREATE INDEX IF NOT EXISTS idx_company_officers_user_id ON company_officers(user_id);
+CREATE INDEX IF NOT EXISTS idx_drives_company_id ON drives(company_id, starts_on);
+CREATE INDEX IF NOT EXISTS idx_drives_season ON drives(season, starts_on);
+CREATE INDEX IF NOT EXISTS idx_company_status_transitions_company_id ON company_status_transitions(company_id, changed_at);
+CREATE INDEX IF NOT EXISTS idx_company_status_transitions_changed_at ON company_status_transitions(changed_at);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_seasons_active ON seasons(active) WHERE active;
+CREATE INDEX IF NOT EXISTS idx_attachments_company_id ON attachments(company_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_events_date ON events(date);
+CREATE INDEX IF NOT EXISTS idx_events_type ON events(type);
+CREATE INDEX IF NOT EXISTS idx_contacts_company_id ON contacts(company_id);
+CREATE INDEX IF NOT EXISTS idx_contacts_email ON contacts(lower(email));
+CREATE UNIQUE INDEX IF NOT EXISTS idx_contacts_primary ON contacts(company_id) WHERE is_primary;
+CREATE INDEX IF NOT EXISTS idx_follow_ups_company_id ON follow_ups(company_id);
+CREATE INDEX IF NOT EXISTS idx_follow_ups_officer_due ON follow_ups(officer, due_at) WHERE status = 'open';
+CREATE INDEX IF NOT EXISTS idx_follow_ups_reminder ON follow_ups(due_at) WHERE status = 'open' AND reminded_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_notifications_recipient ON notifications(recipient, created_at);
+CREATE INDEX IF NOT EXISTS idx_interactions_company_occurred ON interactions(company_id, occurred_at DESC);
+CREATE INDEX IF NOT EXISTS idx_interactions_officer ON interactions(officer, occurred_at DESC);
+`
+
+// addedIndexes cover columns in addedColumns, so they run after the ALTERs.
+const addedIndexes = `
+CREATE INDEX IF NOT EXISTS idx_companies_package_amount ON companies(package_amount);
+CREATE INDEX IF NOT EXISTS idx_companies_package_needs_review ON companies(id) WHERE package_needs_review;
+CREATE INDEX IF NOT EXISTS idx_companies_last_interaction_at ON companies(last_interaction_at, id);
+CREATE INDEX IF NOT EXISTS idx_companies_deleted_at ON companies(deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_events_company_id ON events(company_id, date);
+CREATE INDEX IF NOT EXISTS idx_companies_pipeline_status ON companies(pipeline_status);
+CREATE INDEX IF NOT EXISTS idx_companies_season ON companies(season, created_at);
+CREATE INDEX IF NOT EXISTS idx_companies_carried_from ON companies(carried_from);
+CREATE INDEX IF NOT EXISTS idx_companies_temp_season ON companies_temp(season);
+CREATE INDEX IF NOT EXISTS idx_events_season ON events(season, date);
+`
+
+// columns added after the first release, applied to existing database files.
+var addedColumns = []struct{ table, column, definition string }{
+	{"companies", "version", "INTEGER NOT NULL DEFAULT 1"},
+	{"companies_temp", "base_version", "INTEGER"},
+	{"companies", "package_amount", "REAL"},
+	{"companies", "package_base", "REAL"},
+	{"companies", "package_variable", "REAL"},
+	{"companies", "package_stipend", "REAL"},
+	{"companies", "package_currency", "TEXT NOT NULL DEFAULT ''"},
+	{"companies", "package_unit", "TEXT NOT NULL DEFAULT ''"},
+	{"companies", "package_min", "REAL"},
+	{"companies", "package_max", "REAL"},
+	{"companies", "package_needs_review", "BOOLEAN NOT NULL DEFAULT 0"},
+	{"companies", "last_interaction_at", "TEXT"},
+	{"companies", "last_interaction_outcome", "TEXT NOT NULL DEFAULT ''"},
+	{"companies", "archived_at", "TEXT"},
+	{"companies", "archived_by", "TEXT NOT NULL DEFAULT ''"},
+	{"companies", "deleted_at", "TEXT"},
+	{"companies", "deleted_by", "TEXT NOT NULL DEFAULT ''"},
+	{"company_history", "merged_from", "TEXT NOT NULL DEFAULT ''"},
+	{"events", "company_id", "TEXT REFERENCES companies(id) ON DELETE SET NULL"},
+	{"companies", "pipeline_status", "TEXT NOT NULL DEFAULT 'prospect'"},
+	{"companies", "season", "TEXT NOT NULL DEFAULT ''"},
+	{"companies", "carried_from", "TEXT REFERENCES companies(id) ON DELETE SET NULL"},
+	{"companies_temp", "season", "TEXT NOT NULL DEFAULT ''"},
+	{"events", "season", "TEXT NOT NULL DEFAULT ''"},
+	{"companies", "custom_fields", "TEXT NOT NULL DEFAULT '{}'"},
+	{"companies", "tags", "TEXT NOT NULL DEFAULT '[]'"},
+}
+
+// Migrate creates the company tables if they do not exist yet, adds any
+// columns missing from older database files and runs pending data migrations.
+func Migrate(db *sql.DB) error {
+	if _, err := db.Exec(schema); err != nil {
+		return err
+	}
+	for _, c := range addedColumns {
+		if err := addColumnIfMissing(db, c.table, c.column, c.definition); err != nil {
+			return err
+		}
+	}
+	if _, err := db.Exec(addedIndexes); err != nil {
+		return err
+	}
+	return runDataMigrations(db)
+}
+
+// dataMigrations rewrite existing rows. Each runs once per database, recorded
+// by name in schema_migrations; append new ones, never reorder or rename.
+var dataMigrations = []struct {
+	name string
+	run  func(tx *sql.Tx) error
+}{
+	{"0001_import_contacts", importContacts},
+	{"0002_structure_packages", structurePackages},
+	{"0003_import_follow_ups", importFollowUps},
+	{"0004_import_remarks", importRemarks},
+	{"0005_record_company_history", recordBaselines},
+	{"0006_assign_officers", assignOfficers},
+	{"0007_import_drives", importDrives},
+	{"0008_record_pipeline_status", recordPipelineStatus},
+	{"0009_assign_seasons", assignSeasons},
+	{"0010_normalize_proposal_statuses", normalizeProposalStatuses},
+}
+
+func runDataMigrations(db *sql.DB) error {
+	for _, m := range dataMigrations {
+		if err := runDataMigration(db, m.name, m.run); err != nil {
+			return fmt.Errorf("migration %s: %w", m.name, err)
+		}
+	}
+	return nil
+}
+
+func runDataMigration(db *sql.DB, name string, run func(tx *sql.Tx) error) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`INSERT OR IGNORE INTO schema_migrations (name, applied_at) VALUES (?, ?)`, name, formatTime(time.Now()))
+	if err != nil {
+		return err
+	}
+	if n, err := result.RowsAffected(); err != nil || n == 0 {
+		return err
+	}
+	if err := run(tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// importContacts parses the free-text HR fields of companies that have no
+// contacts yet into contact rows. The text fields themselves are kept.
+func importContacts(tx *sql.Tx) error {
+	rows, err := tx.Query(`SELECT ` + legacyCompanyColumns + ` FROM companies WHERE NOT EXISTS (SELECT 1 FROM contacts WHERE contacts.company_id = companies.id)`)
+	if err != nil {
+		return err
+	}
+	var companies []*entity.Company
+	for rows.Next() {
+		c, err := scanCompany(rows)
+		if err != nil {
+			rows.Close()
+			return err
+		}
+		companies = append(companies, c)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	now := formatTime(time.Now())
+	for _, c := range companies {
+		for _, contact := range company.ImportContacts(c) {
+			if _, err := insertContact(tx, contact, now); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// structurePackages parses every free-text package into the structured
+// compensation columns, replacing the first-number package_amount. Packages
+// it cannot read are flagged with package_needs_review.
+func structurePackages(tx *sql.Tx) error {
+	rows, err := tx.Query(`SELECT id, COALESCE(package, '') FROM companies`)
+	if err != nil {
+		return err
+	}
+	packages := map[string]string{}
+	for rows.Next() {
+		var id, pkg string
+		if err := rows.Scan(&id, &pkg); err != nil {
+			rows.Close()
+			return err
+		}
+		packages[id] = pkg
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for id, pkg := range packages {
+		if err := setCompensation(tx, id, company.ParseCompensation(pkg)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// importFollowUps turns the free-text follow_up of companies that have no
+// follow-ups yet into dated follow-ups. The text field itself is kept.
+func importFollowUps(tx *sql.Tx) error {
+	rows, err := tx.Query(`SELECT ` + legacyCompanyColumns + ` FROM companies WHERE NOT EXISTS (SELECT 1 FROM follow_ups WHERE follow_ups.company_id = companies.id)`)
+	if err != nil {
+		return err
+	}
+	var companies []*entity.Company
+	for rows.Next() {
+		c, err := scanCompany(rows)
+		if err != nil {
+			rows.Close()
+			return err
+		}
+		companies = append(companies, c)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for _, c := range companies {
+		if followUp, ok := company.ImportFollowUp(c, now); ok {
+			if _, err := insertFollowUp(tx, followUp, formatTime(now)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// importRemarks starts the interaction timeline of companies that have none
+// with their free-text remarks. The remarks field itself is kept.
+func importRemarks(tx *sql.Tx) error {
+	rows, err := tx.Query(`SELECT ` + legacyCompanyColumns + ` FROM companies WHERE NOT EXISTS (SELECT 1 FROM interactions WHERE interactions.company_id = companies.id)`)
+	if err != nil {
+		return err
+	}
+	var companies []*entity.Company
+	for rows.Next() {
+		c, err := scanCompany(rows)
+		if err != nil {
+			rows.Close()
+			return err
+		}
+		companies = append(companies, c)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	now := formatTime(time.Now())
+	for _, c := range companies {
+		if interaction, ok := company.ImportRemarks(c); ok {
+			if _, err := insertInteraction(tx, interaction, now); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// recordBaselines starts the history of companies that have none with their
+// current version, so later changes can be diffed against it.
+func recordBaselines(tx *sql.Tx) error {
+	rows, err := tx.Query(`SELECT ` + legacyCompanyColumns + ` FROM companies WHERE NOT EXISTS (SELECT 1 FROM company_history WHERE company_history.company_id = companies.id)`)
+	if err != nil {
+		return err
+	}
+	var companies []*entity.Company
+	for rows.Next() {
+		c, err := scanCompany(rows)
+		if err != nil {
+			rows.Close()
+			return err
+		}
+		companies = append(companies, c)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, c := range companies {
+		if err := recordRevision(tx, c, entity.CompanyChange{Source: company.RevisionBaseline}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addColumnIfMissing stands in for ADD COLUMN IF NOT EXISTS, which SQLite
+// does not support.
+func addColumnIfMissing(db *sql.DB, table, column, definition string) error {
+	var count int
+	err := db.QueryRow(`SELECT COUNT(*) FROM pragma_table_info(?) WHERE name = ?`, table, column).Scan(&count)
+	if err != nil || count > 0 {
+		return err
+	}
+	_, err = db.Exec(`ALTER TABLE ` + table + ` ADD COLUMN ` + column + ` ` + definition)
+	return err
+}
+
+// assignOfficers copies the assigned_officer array of companies that have no
+// company_officers rows into that table, dated when the company was last
+// updated. Usernames that are not users are logged and dropped; the array
+// itself is left as it was.
+func assignOfficers(tx *sql.Tx) error {
+	rows, err := tx.Query(`
+		SELECT id, assigned_officer, updated_at FROM companies
+		WHERE assigned_officer <> '[]'
+		AND NOT EXISTS (SELECT 1 FROM company_officers WHERE company_officers.company_id = companies.id)`)
+	if err != nil {
+		return err
+	}
+	type assignment struct {
+		companyID string
+		officers  []string
+		at        string
+	}
+	var assignments []assignment
+	for rows.Next() {
+		var a assignment
+		var officers string
+		if err := rows.Scan(&a.companyID, &officers, &a.at); err != nil {
+			rows.Close()
+			return err
+		}
+		if err := json.Unmarshal([]byte(officers), &a.officers); err != nil {
+			rows.Close()
+			return err
+		}
+		assignments = append(assignments, a)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, a := range assignments {
+		officers := company.UniqueOfficers(a.officers)
+		ids, err := userIDs(tx, officers)
+		if err != nil {
+			return err
+		}
+		position := 0
+		for _, username := range officers {
+			userID, ok := ids[username]
+			if !ok {
+				log.Printf("Company %s: officer %q is not a user; not assigned", a.companyID, username)
+				continue
+			}
+			_, err := tx.Exec(`
+				INSERT INTO company_officers (company_id, user_id, role, position, assigned_at, assigned_by)
+				VALUES (?, ?, ?, ?, ?, ?)`,
+				a.companyID, userID, company.OfficerRole(position), position, a.at, company.MigrationAssigner)
+			if err != nil {
+				return err
+			}
+			position++
+		}
+	}
+	return nil
+}
+
+// importDrives turns the drive and type_of_drive of companies that have no
+// drives yet into one drive each. The text fields themselves are kept.
+func importDrives(tx *sql.Tx) error {
+	rows, err := tx.Query(`
+		SELECT id, COALESCE(drive, ''), COALESCE(type_of_drive, ''), COALESCE(package, '') FROM companies
+		WHERE NOT EXISTS (SELECT 1 FROM drives WHERE drives.company_id = companies.id)`)
+	if err != nil {
+		return err
+	}
+	var drives []entity.Drive
+	for rows.Next() {
+		var id, drive, typeOfDrive, pkg string
+		if err := rows.Scan(&id, &drive, &typeOfDrive, &pkg); err != nil {
+			rows.Close()
+			return err
+		}
+		if imported, ok := company.ImportDrive(id, drive, typeOfDrive, pkg); ok {
+			drives = append(drives, imported)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	now := formatTime(time.Now())
+	for _, drive := range drives {
+		if _, err := insertDrive(tx, drive, now); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// recordPipelineStatus moves contacted companies that have no status
+// transitions yet to the contacted stage, and starts their transitions with
+// the stage they are at, dated when the company was created.
+func recordPipelineStatus(tx *sql.Tx) error {
+	noTransitions := `NOT EXISTS (SELECT 1 FROM company_status_transitions WHERE company_status_transitions.company_id = companies.id)`
+	if _, err := tx.Exec(`UPDATE companies SET pipeline_status = ? WHERE is_contacted AND `+noTransitions, company.StageContacted); err != nil {
+		return err
+	}
+	rows, err := tx.Query(`SELECT id, pipeline_status, created_at FROM companies WHERE ` + noTransitions)
+	if err != nil {
+		return err
+	}
+	type start struct{ companyID, status, at string }
+	var starts []start
+	for rows.Next() {
+		var s start
+		if err := rows.Scan(&s.companyID, &s.status, &s.at); err != nil {
+			rows.Close()
+			return err
+		}
+		starts = append(starts, s)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, s := range starts {
+		if err := recordTransitionAt(tx, s.companyID, "", s.status, "", s.at); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// assignSeasons files companies, proposals and events that have no season
+// yet under one. A company belongs to the season of its latest drive, or
+// else the season it was created in; a proposal to its company's season and
+// an event to the season its date falls in.
+func assignSeasons(tx *sql.Tx) error {
+	rows, err := tx.Query(`
+		SELECT id, created_at,
+			COALESCE((SELECT MAX(season) FROM drives WHERE drives.company_id = companies.id AND season <> ''), '')
+		FROM companies WHERE season = ''`)
+	if err != nil {
+		return err
+	}
+	seasons := map[string]string{}
+	for rows.Next() {
+		var id, createdAt, driveSeason string
+		if err := rows.Scan(&id, &createdAt, &driveSeason); err != nil {
+			rows.Close()
+			return err
+		}
+		seasons[id] = driveSeason
+		if driveSeason == "" {
+			created, err := time.Parse(timeLayout, createdAt)
+			if err != nil {
+				rows.Close()
+				return err
+			}
+			seasons[id] = company.SeasonAt(created)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	for id, season := range seasons {
+		if _, err := tx.Exec(`UPDATE companies SET season = ? WHERE id = ?`, season, id); err != nil {
+			return err
+		}
+	}
+
+	_, err = tx.Exec(`
+		UPDATE companies_temp SET season = (SELECT season FROM companies WHERE companies.id = companies_temp.company_id)
+		WHERE season = '' AND company_id IN (SELECT id FROM companies)`)
+	if err != nil {
+		return err
+	}
+
+	rows, err = tx.Query(`SELECT id, date FROM events WHERE season = ''`)
+	if err != nil {
+		return err
+	}
+	events := map[string]string{}
+	for rows.Next() {
+		var id, date string
+		if err := rows.Scan(&id, &date); err != nil {
+			rows.Close()
+			return err
+		}
+		parsed, err := time.Parse(timeLayout, date)
+		if err != nil {
+			rows.Close()
+			return err
+		}
+		events[id] = company.SeasonAt(parsed)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	for id, season := range events {
+		if _, err := tx.Exec(`UPDATE events SET season = ? WHERE id = ?`, season, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// normalizeProposalStatuses lowercases the statuses clients could set to
+// anything before proposals had a lifecycle, treating a missing one as
+// pending. Any other status is left as it is and allows no actions.
+func normalizeProposalStatuses(tx *sql.Tx) error {
+	_, err := tx.Exec(`
+		UPDATE companies_temp SET status = CASE
+			WHEN TRIM(COALESCE(status, '')) = '' THEN 'pending'
+			ELSE LOWER(TRIM(status))
+		END`)
+	return err
+}
+
+// timeLayout is fixed width so that ORDER BY on the TEXT column sorts
+// chronologically. Microsecond precision matches Postgres.
+const timeLayout = "2006-01-02T15:04:05.000000Z"
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format(timeLayout)
+}
+
+// displayTime renders a stored timestamp the way database/sql renders a
+// Postgres TIMESTAMPTZ scanned into a string.
+func displayTime(stored string) string {
+	t, err := time.Parse(timeLayout, stored)
+	if err != nil {
+		return stored
+	}
+	return t.Format(time.RFC3339Nano)
+}
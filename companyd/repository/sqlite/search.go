@@ -0,0 +1,32 @@
+package sqlite
+
+import (
+	"backend/companyd/entity"
+	"backend/companyd/usecase/company"
+)
+
+// SearchCompanies ranks companies in Go with company.MatchCompanies. A
+// single-machine database is small enough that scanning the visible rows is
+// cheaper than maintaining an FTS index alongside the table.
+func (r *Repository) SearchCompanies(q company.SearchQuery) ([]*entity.CompanySearchResult, error) {
+	f := queryFilter(company.ListQuery{Officer: q.Officer, Archived: company.ArchivedInclude})
+
+	rows, err := r.db.Query(`SELECT `+companyColumns+` FROM companies`+f.where(), f.args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var visible []*entity.Company
+	for rows.Next() {
+		c, err := scanCompany(rows)
+		if err != nil {
+			return nil, err
+		}
+		visible = append(visible, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return company.MatchCompanies(visible, company.SearchTerms(q.Text), q.Limit), nil
+}
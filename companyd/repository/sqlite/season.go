@@ -0,0 +1,125 @@
+package sqlite
+
+import (
+	"backend/companyd/entity"
+	"backend/companyd/usecase/company"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// seasonNames are the seasons recorded in seasons and those companies
+// belong to.
+const seasonNames = `
+	SELECT name, active FROM seasons
+	UNION
+	SELECT DISTINCT season, 0 FROM companies
+	WHERE season <> '' AND season NOT IN (SELECT name FROM seasons)`
+
+func (r *Repository) ListSeasons() ([]*entity.Season, error) {
+	rows, err := r.db.Query(`
+		SELECT s.name, s.active, COUNT(c.id)
+		FROM (` + seasonNames + `) s
+		LEFT JOIN companies c ON c.season = s.name AND c.deleted_at IS NULL
+		GROUP BY s.name, s.active
+		ORDER BY s.name`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	seasons := []*entity.Season{}
+	for rows.Next() {
+		var season entity.Season
+		if err := rows.Scan(&season.Name, &season.Active, &season.Companies); err != nil {
+			return nil, err
+		}
+		seasons = append(seasons, &season)
+	}
+	return seasons, rows.Err()
+}
+
+func (r *Repository) SetActiveSeason(season string) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`UPDATE seasons SET active = 0 WHERE active AND name <> ?`, season); err != nil {
+		return err
+	}
+	_, err = tx.Exec(`
+		INSERT INTO seasons (name, active, created_at) VALUES (?, 1, ?)
+		ON CONFLICT (name) DO UPDATE SET active = 1`, season, formatTime(time.Now()))
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *Repository) RolloverCompanies(ids []string, season, by string) ([]*entity.Company, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	now := formatTime(time.Now())
+	if _, err := tx.Exec(`INSERT OR IGNORE INTO seasons (name, created_at) VALUES (?, ?)`, season, now); err != nil {
+		return nil, err
+	}
+	change := entity.CompanyChange{ChangedBy: by, Source: company.RevisionRollover}
+	carried := make([]*entity.Company, 0, len(ids))
+	for _, id := range ids {
+		original, err := scanCompany(tx.QueryRow(`SELECT `+companyColumns+` FROM companies WHERE id = ? AND deleted_at IS NULL`, id))
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, company.ErrUnknownCompany
+		}
+		if err != nil {
+			return nil, err
+		}
+		contacts, err := listContactsIn(tx, original.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		args := []interface{}{uuid.NewString(), original.CompanyName, original.CompanyAddress, original.Drive, original.TypeOfDrive, "", false, original.Remarks, original.ContactDetails, original.HR1Details, original.HR2Details, original.Package, now, now}
+		copied, err := insertCompanyWith(tx, args, company.StageProspect, season, original.ID, original.Compensation, original.CustomFields, original.Tags, original.AssignedOfficer, by)
+		if err != nil {
+			return nil, err
+		}
+		for _, contact := range contacts {
+			contact.CompanyID = copied.ID
+			if _, err := insertContact(tx, *contact, now); err != nil {
+				return nil, err
+			}
+		}
+		if err := recordRevision(tx, copied, change); err != nil {
+			return nil, err
+		}
+		carried = append(carried, copied)
+	}
+	return carried, tx.Commit()
+}
+
+// listContactsIn returns a company's contacts within tx, oldest first.
+func listContactsIn(tx *sql.Tx, companyID string) ([]*entity.Contact, error) {
+	rows, err := tx.Query(`SELECT `+contactColumns+` FROM contacts WHERE company_id = ? ORDER BY created_at, rowid`, companyID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var contacts []*entity.Contact
+	for rows.Next() {
+		contact, err := scanContact(rows)
+		if err != nil {
+			return nil, err
+		}
+		contacts = append(contacts, contact)
+	}
+	return contacts, rows.Err()
+}
@@ -0,0 +1,374 @@
+package company
+
+import (
+	"backend/companyd/entity"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// Weights of what the assignment engine weighs up. They add up to 1, so
+// scores are between 0 and 1.
+const (
+	loadWeight           = 0.5
+	specialisationWeight = 0.2
+	relationshipWeight   = 0.3
+)
+
+// Outcomes of applying a rebalance move.
+const (
+	// MoveApplied means the company was handed over.
+	MoveApplied = "applied"
+	// MoveStale means the company changed after the plan was made, so the
+	// move was skipped; preview a new plan.
+	MoveStale = "stale"
+	// MoveNotFound means the company has been deleted.
+	MoveNotFound = "not_found"
+	// MoveRejected means the move was invalid, for example because its
+	// officer is no longer a user.
+	MoveRejected = "rejected"
+)
+
+// workload is how the active season's companies, less those archived or in
+// the trash, are shared among officers.
+type workload struct {
+	// officers are the users with the Officer role, alphabetically.
+	officers  []string
+	companies []*entity.Company
+	load      map[string]int
+	// byType counts each officer's companies by normalised type of drive.
+	byType map[string]map[string]int
+}
+
+func (s *Service) workload() (*workload, error) {
+	officers, err := s.repo.ListOfficerUsernames()
+	if err != nil {
+		return nil, err
+	}
+	companies, err := s.repo.ListCompanies()
+	if err != nil {
+		return nil, err
+	}
+	season, err := s.ActiveSeason()
+	if err != nil {
+		return nil, err
+	}
+	companies = inSeason(companies, season)
+	w := &workload{officers: officers, load: map[string]int{}, byType: map[string]map[string]int{}}
+	for _, officer := range officers {
+		w.load[officer] = 0
+	}
+	for _, c := range companies {
+		if c.ArchivedAt != nil {
+			continue
+		}
+		w.companies = append(w.companies, c)
+		for _, officer := range UniqueOfficers(c.AssignedOfficer) {
+			w.load[officer]++
+			if w.byType[officer] == nil {
+				w.byType[officer] = map[string]int{}
+			}
+			w.byType[officer][driveType(c.TypeOfDrive)]++
+		}
+	}
+	return w, nil
+}
+
+// average is the mean load of the officers.
+func (w *workload) average() float64 {
+	if len(w.officers) == 0 {
+		return 0
+	}
+	total := 0
+	for _, officer := range w.officers {
+		total += w.load[officer]
+	}
+	return float64(total) / float64(len(w.officers))
+}
+
+func driveType(typeOfDrive string) string {
+	return strings.ToLower(strings.TrimSpace(typeOfDrive))
+}
+
+// rank scores every officer not already assigned to target, best fit
+// first. related gives the reason each officer has a past relationship with
+// the company.
+func (w *workload) rank(target *entity.Company, related map[string]string) []*entity.OfficerSuggestion {
+	maxLoad := 0
+	for _, officer := range w.officers {
+		if w.load[officer] > maxLoad {
+			maxLoad = w.load[officer]
+		}
+	}
+	average := w.average()
+	typ := driveType(target.TypeOfDrive)
+
+	suggestions := []*entity.OfficerSuggestion{}
+	for _, officer := range w.officers {
+		if containsOfficer(target.AssignedOfficer, officer) {
+			continue
+		}
+		load := w.load[officer]
+		loadScore := 1.0
+		if maxLoad > 0 {
+			loadScore = float64(maxLoad-load) / float64(maxLoad)
+		}
+		reasons := []string{fmt.Sprintf("has %d active companies against an average of %.1f", load, average)}
+
+		specialisation := 0.0
+		if same := w.byType[officer][typ]; typ != "" && same > 0 {
+			specialisation = float64(same) / float64(load)
+			reasons = append(reasons, fmt.Sprintf("%d of their %d companies are %s drives", same, load, target.TypeOfDrive))
+		}
+
+		relationship := 0.0
+		if why, ok := related[officer]; ok {
+			relationship = 1
+			reasons = append(reasons, why)
+		}
+
+		score := loadWeight*loadScore + specialisationWeight*specialisation + relationshipWeight*relationship
+		suggestions = append(suggestions, &entity.OfficerSuggestion{
+			Username: officer,
+			Score:    math.Round(score*1000) / 1000,
+			Load:     load,
+			Reasons:  reasons,
+		})
+	}
+	sort.SliceStable(suggestions, func(i, j int) bool {
+		if suggestions[i].Score != suggestions[j].Score {
+			return suggestions[i].Score > suggestions[j].Score
+		}
+		return suggestions[i].Load < suggestions[j].Load
+	})
+	return suggestions
+}
+
+// related finds the officers who have dealt with target before: those who
+// logged interactions with it, were once assigned to it, or look after a
+// company that is probably the same recruiter. A target without an ID is
+// a company about to be created and only has duplicates.
+func (s *Service) related(target *entity.Company) (map[string]string, error) {
+	related := map[string]string{}
+	if target.ID != "" {
+		interactions, err := s.repo.ListInteractions(InteractionFilter{CompanyID: target.ID})
+		if err != nil {
+			return nil, err
+		}
+		for _, i := range interactions {
+			if _, ok := related[i.Officer]; !ok && i.Officer != "" {
+				related[i.Officer] = "has logged interactions with this company"
+			}
+		}
+		revisions, err := s.repo.ListCompanyRevisions(target.ID)
+		if err != nil {
+			return nil, err
+		}
+		for _, revision := range revisions {
+			for _, officer := range revision.Snapshot.AssignedOfficer {
+				if _, ok := related[officer]; !ok {
+					related[officer] = "was assigned to this company before"
+				}
+			}
+		}
+	}
+	duplicates, err := s.FindDuplicates(target.CompanyName, target.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, match := range duplicates {
+		for _, officer := range match.Company.AssignedOfficer {
+			if _, ok := related[officer]; !ok {
+				related[officer] = fmt.Sprintf("looks after %s, which may be the same company", match.Company.CompanyName)
+			}
+		}
+	}
+	return related, nil
+}
+
+func (s *Service) suggest(target *entity.Company) ([]*entity.OfficerSuggestion, error) {
+	w, err := s.workload()
+	if err != nil {
+		return nil, err
+	}
+	related, err := s.related(target)
+	if err != nil {
+		return nil, err
+	}
+	return w.rank(target, related), nil
+}
+
+// SuggestOfficers ranks the officers who could take on a company, best fit
+// first, leaving out those already assigned to it.
+func (s *Service) SuggestOfficers(companyID string) ([]*entity.OfficerSuggestion, error) {
+	target, err := s.repo.GetCompany(companyID)
+	if err != nil {
+		return nil, err
+	}
+	return s.suggest(target)
+}
+
+// SuggestOfficersFor ranks the officers who could take on a company that is
+// about to be created.
+func (s *Service) SuggestOfficersFor(companyName, typeOfDrive string) ([]*entity.OfficerSuggestion, error) {
+	return s.suggest(&entity.Company{CompanyName: companyName, TypeOfDrive: typeOfDrive})
+}
+
+// AssignOfficer makes the best suggested officer the primary officer of a
+// company still at version. Officers already assigned stay on as
+// secondary officers.
+func (s *Service) AssignOfficer(id string, version int, by string) (*entity.Company, *entity.OfficerSuggestion, error) {
+	suggestions, err := s.SuggestOfficers(id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(suggestions) == 0 {
+		return nil, nil, ErrNoCandidate
+	}
+	current, err := s.repo.GetCompany(id)
+	if err != nil {
+		return nil, nil, err
+	}
+	best := suggestions[0]
+	officers := append([]string{best.Username}, current.AssignedOfficer...)
+	updated, err := s.UpdateCompany(id, version, entity.CompanyUpdate{AssignedOfficer: &officers}, by)
+	if err != nil {
+		return nil, nil, err
+	}
+	return updated, best, nil
+}
+
+// PlanRebalance works out which companies to hand from the most loaded
+// officers to the least loaded until no officer has more than the target
+// load, the fair share rounded up, or no more moves help. Each company moves
+// at most once, preferring companies where the overloaded officer is only a
+// secondary officer and then ones of a type of drive the new officer
+// already handles. Officers who are not users with the Officer role are
+// left alone.
+func (s *Service) PlanRebalance() (*entity.RebalancePlan, error) {
+	w, err := s.workload()
+	if err != nil {
+		return nil, err
+	}
+	plan := &entity.RebalancePlan{Before: map[string]int{}, After: map[string]int{}, Moves: []*entity.RebalanceMove{}}
+	if len(w.officers) == 0 {
+		return plan, nil
+	}
+	plan.TargetLoad = int(math.Ceil(w.average()))
+	load := map[string]int{}
+	for _, officer := range w.officers {
+		plan.Before[officer] = w.load[officer]
+		load[officer] = w.load[officer]
+	}
+
+	sorted := append([]*entity.Company(nil), w.companies...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return strings.ToLower(sorted[i].CompanyName) < strings.ToLower(sorted[j].CompanyName)
+	})
+	moved := map[string]bool{}
+	// pick chooses the company to hand from one officer to another, or nil.
+	pick := func(from, to string) *entity.Company {
+		var best *entity.Company
+		bestRank := -1
+		for _, c := range sorted {
+			if moved[c.ID] || !containsOfficer(c.AssignedOfficer, from) || containsOfficer(c.AssignedOfficer, to) {
+				continue
+			}
+			rank := 0
+			if c.AssignedOfficer[0] != from {
+				rank += 2
+			}
+			if w.byType[to][driveType(c.TypeOfDrive)] > 0 {
+				rank++
+			}
+			if rank > bestRank {
+				best, bestRank = c, rank
+			}
+		}
+		return best
+	}
+
+	for {
+		byLoad := append([]string(nil), w.officers...)
+		sort.SliceStable(byLoad, func(i, j int) bool { return load[byLoad[i]] < load[byLoad[j]] })
+		var move *entity.RebalanceMove
+		for i := len(byLoad) - 1; i >= 0 && move == nil; i-- {
+			from := byLoad[i]
+			if load[from] <= plan.TargetLoad {
+				break
+			}
+			for _, to := range byLoad {
+				if load[from]-load[to] <= 1 {
+					break
+				}
+				if c := pick(from, to); c != nil {
+					move = &entity.RebalanceMove{
+						CompanyID:   c.ID,
+						CompanyName: c.CompanyName,
+						Version:     c.Version,
+						From:        from,
+						To:          to,
+						Reason:      fmt.Sprintf("%s has %d active companies and %s has %d, against a target of %d", from, load[from], to, load[to], plan.TargetLoad),
+					}
+					break
+				}
+			}
+		}
+		if move == nil {
+			break
+		}
+		plan.Moves = append(plan.Moves, move)
+		moved[move.CompanyID] = true
+		load[move.From]--
+		load[move.To]++
+	}
+
+	for _, officer := range w.officers {
+		plan.After[officer] = load[officer]
+	}
+	return plan, nil
+}
+
+// ApplyRebalance applies moves, typically from PlanRebalance, one at a
+// time. A move whose company has changed since it was planned is skipped as
+// stale rather than failing the rest.
+func (s *Service) ApplyRebalance(moves []entity.RebalanceMove, by string) ([]*entity.RebalanceResult, error) {
+	results := make([]*entity.RebalanceResult, 0, len(moves))
+	for _, move := range moves {
+		result := &entity.RebalanceResult{RebalanceMove: move, Status: MoveApplied}
+		results = append(results, result)
+
+		current, err := s.repo.GetCompany(move.CompanyID)
+		if errors.Is(err, ErrNotFound) {
+			result.Status = MoveNotFound
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if current.Version != move.Version || !containsOfficer(current.AssignedOfficer, move.From) || containsOfficer(current.AssignedOfficer, move.To) {
+			result.Status = MoveStale
+			continue
+		}
+		officers := make([]string, len(current.AssignedOfficer))
+		for i, officer := range current.AssignedOfficer {
+			if officer == move.From {
+				officer = move.To
+			}
+			officers[i] = officer
+		}
+		_, err = s.UpdateCompany(move.CompanyID, move.Version, entity.CompanyUpdate{AssignedOfficer: &officers}, by)
+		switch {
+		case errors.Is(err, ErrVersionMismatch):
+			result.Status = MoveStale
+		case errors.Is(err, ErrUnknownOfficer):
+			result.Status = MoveRejected
+			result.Error = err.Error()
+		case err != nil:
+			return nil, err
+		}
+	}
+	return results, nil
+}
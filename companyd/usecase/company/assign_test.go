@@ -0,0 +1,279 @@
+package company_test
+
+import (
+	"backend/companyd/entity"
+	"backend/companyd/repository/memory"
+	"backend/companyd/usecase/company"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const (
+	activeSeason   = "2026-27"
+	previousSeason = "2025-26"
+)
+
+// newAssignService returns a service over a repository whose active season
+// is activeSeason and which has a user with the Officer role for each of
+// officers.
+func newAssignService(t *testing.T, officers ...string) (*memory.Repository, company.Usecase) {
+	t.Helper()
+	repo := memory.NewCompanyRepository()
+	if err := repo.SetActiveSeason(activeSeason); err != nil {
+		t.Fatal(err)
+	}
+	for _, officer := range officers {
+		repo.AddUser(officer, company.OfficerUserRole)
+	}
+	return repo, company.NewService(repo)
+}
+
+func addCompany(t *testing.T, repo *memory.Repository, name, typeOfDrive, season string, officers ...string) *entity.Company {
+	t.Helper()
+	c, err := repo.CreateCompany(name, "", "", typeOfDrive, "", "true", "", "", "", "", "", officers, entity.Compensation{}, nil, nil, season)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+// ranking describes suggestions as "username:score" in order.
+func ranking(suggestions []*entity.OfficerSuggestion) string {
+	parts := make([]string, len(suggestions))
+	for i, s := range suggestions {
+		parts[i] = fmt.Sprintf("%s:%g", s.Username, s.Score)
+	}
+	return strings.Join(parts, " ")
+}
+
+func TestSuggestOfficers(t *testing.T) {
+	repo, service := newAssignService(t, "alice", "bob", "carol", "dave")
+	addCompany(t, repo, "Accenture", "on-campus", activeSeason, "alice")
+	cognizant := addCompany(t, repo, "Cognizant", "on-campus", activeSeason, "alice")
+	infosys := addCompany(t, repo, "Infosys", "off-campus", activeSeason, "carol")
+	infosys, err := service.UpdateCompany(infosys.ID, infosys.Version, entity.CompanyUpdate{AssignedOfficer: &[]string{"bob"}}, "manager")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.CreateInteraction(entity.Interaction{CompanyID: cognizant.ID, Type: "call", Officer: "dave"}); err != nil {
+		t.Fatal(err)
+	}
+	// Last season's companies do not count towards anyone's load.
+	addCompany(t, repo, "Wipro", "on-campus", previousSeason, "carol")
+
+	// alice has 2 on-campus companies and bob 1 off-campus one, so the load
+	// scores are 0, 0.5, 1 and 1 out of a weight of 0.5. A matching type
+	// of drive adds up to 0.2 and a past relationship 0.3. Equal scores go
+	// to the less loaded officer, then alphabetically.
+	cases := []struct {
+		name    string
+		suggest func() ([]*entity.OfficerSuggestion, error)
+		want    string
+	}{
+		{
+			"load and type of drive",
+			func() ([]*entity.OfficerSuggestion, error) { return service.SuggestOfficersFor("Zoho", " On-Campus ") },
+			"carol:0.5 dave:0.5 bob:0.25 alice:0.2",
+		},
+		{
+			"tie broken by load",
+			func() ([]*entity.OfficerSuggestion, error) {
+				return service.SuggestOfficersFor("Accenture Ltd", "on-campus")
+			},
+			"carol:0.5 dave:0.5 alice:0.5 bob:0.25",
+		},
+		{
+			"duplicate company",
+			func() ([]*entity.OfficerSuggestion, error) {
+				return service.SuggestOfficersFor("Infosys Limited", "off-campus")
+			},
+			"bob:0.75 carol:0.5 dave:0.5 alice:0",
+		},
+		{
+			"logged interactions",
+			func() ([]*entity.OfficerSuggestion, error) { return service.SuggestOfficers(cognizant.ID) },
+			"dave:0.8 carol:0.5 bob:0.25",
+		},
+		{
+			"assigned before",
+			func() ([]*entity.OfficerSuggestion, error) { return service.SuggestOfficers(infosys.ID) },
+			"carol:0.8 dave:0.5 alice:0",
+		},
+	}
+	for _, c := range cases {
+		suggestions, err := c.suggest()
+		if err != nil {
+			t.Errorf("%s: %v", c.name, err)
+			continue
+		}
+		if got := ranking(suggestions); got != c.want {
+			t.Errorf("%s: ranking = %s, want %s", c.name, got, c.want)
+		}
+	}
+}
+
+// describeMoves describes moves as "company:from>to" in order.
+func describeMoves(moves []*entity.RebalanceMove) string {
+	parts := make([]string, len(moves))
+	for i, m := range moves {
+		parts[i] = fmt.Sprintf("%s:%s>%s", m.CompanyName, m.From, m.To)
+	}
+	return strings.Join(parts, " ")
+}
+
+func TestPlanRebalance(t *testing.T) {
+	type assigned struct {
+		name, typeOfDrive, season string
+		officers                  []string
+	}
+	cases := []struct {
+		name      string
+		officers  []string
+		companies []assigned
+		target    int
+		moves     string
+		after     map[string]int
+	}{
+		{
+			name:   "no officers",
+			target: 0,
+			after:  map[string]int{},
+		},
+		{
+			name:     "already balanced",
+			officers: []string{"alice", "bob"},
+			companies: []assigned{
+				{"Accenture", "on-campus", activeSeason, []string{"alice"}},
+				{"Infosys", "on-campus", activeSeason, []string{"alice"}},
+				{"TCS", "on-campus", activeSeason, []string{"bob"}},
+			},
+			target: 2,
+			after:  map[string]int{"alice": 2, "bob": 1},
+		},
+		{
+			// Last season's companies and users who are not officers are
+			// left out, and companies otherwise move alphabetically.
+			name:     "down to the target",
+			officers: []string{"alice", "bob"},
+			companies: []assigned{
+				{"TCS", "on-campus", activeSeason, []string{"alice"}},
+				{"Cognizant", "on-campus", activeSeason, []string{"alice"}},
+				{"Accenture", "on-campus", activeSeason, []string{"alice"}},
+				{"Wipro", "on-campus", previousSeason, []string{"alice"}},
+				{"Zoho", "on-campus", activeSeason, []string{"manager"}},
+			},
+			target: 2,
+			moves:  "Accenture:alice>bob",
+			after:  map[string]int{"alice": 2, "bob": 1},
+		},
+		{
+			name:     "secondary officer first",
+			officers: []string{"alice", "bob", "carol"},
+			companies: []assigned{
+				{"Accenture", "on-campus", activeSeason, []string{"alice"}},
+				{"Cognizant", "on-campus", activeSeason, []string{"alice"}},
+				{"Infosys", "on-campus", activeSeason, []string{"carol", "alice"}},
+				{"TCS", "on-campus", activeSeason, []string{"alice"}},
+			},
+			target: 2,
+			moves:  "Infosys:alice>bob Accenture:alice>bob",
+			after:  map[string]int{"alice": 2, "bob": 2, "carol": 1},
+		},
+		{
+			name:     "type of drive the new officer handles",
+			officers: []string{"alice", "bob"},
+			companies: []assigned{
+				{"Accenture", "on-campus", activeSeason, []string{"alice"}},
+				{"Cognizant", "off-campus", activeSeason, []string{"alice"}},
+				{"Infosys", "on-campus", activeSeason, []string{"alice"}},
+				{"TCS", "off-campus", activeSeason, []string{"alice"}},
+				{"Wipro", "Off-Campus", activeSeason, []string{"bob"}},
+			},
+			target: 3,
+			moves:  "Cognizant:alice>bob",
+			after:  map[string]int{"alice": 3, "bob": 2},
+		},
+	}
+	for _, c := range cases {
+		repo, service := newAssignService(t, c.officers...)
+		repo.AddUser("manager", "Manager")
+		for _, a := range c.companies {
+			addCompany(t, repo, a.name, a.typeOfDrive, a.season, a.officers...)
+		}
+		plan, err := service.PlanRebalance()
+		if err != nil {
+			t.Errorf("%s: %v", c.name, err)
+			continue
+		}
+		if plan.TargetLoad != c.target {
+			t.Errorf("%s: target load = %d, want %d", c.name, plan.TargetLoad, c.target)
+		}
+		if got := describeMoves(plan.Moves); got != c.moves {
+			t.Errorf("%s: moves = %q, want %q", c.name, got, c.moves)
+		}
+		if !reflect.DeepEqual(plan.After, c.after) {
+			t.Errorf("%s: loads after = %v, want %v", c.name, plan.After, c.after)
+		}
+	}
+}
+
+func TestApplyRebalance(t *testing.T) {
+	repo, service := newAssignService(t, "alice", "bob", "carol")
+	accenture := addCompany(t, repo, "Accenture", "on-campus", activeSeason, "alice")
+	cognizant := addCompany(t, repo, "Cognizant", "on-campus", activeSeason, "alice")
+	infosys := addCompany(t, repo, "Infosys", "on-campus", activeSeason, "carol", "alice")
+	tcs := addCompany(t, repo, "TCS", "on-campus", activeSeason, "alice", "bob")
+	remarks := "met HR"
+	edited, err := service.UpdateCompany(cognizant.ID, cognizant.Version, entity.CompanyUpdate{Remarks: &remarks}, "alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		name     string
+		move     entity.RebalanceMove
+		status   string
+		officers []string // the company's officers afterwards
+	}{
+		{"edited since the plan", entity.RebalanceMove{CompanyID: cognizant.ID, Version: cognizant.Version, From: "alice", To: "bob"}, company.MoveStale, []string{"alice"}},
+		{"from no longer assigned", entity.RebalanceMove{CompanyID: edited.ID, Version: edited.Version, From: "bob", To: "carol"}, company.MoveStale, []string{"alice"}},
+		{"to already assigned", entity.RebalanceMove{CompanyID: tcs.ID, Version: tcs.Version, From: "alice", To: "bob"}, company.MoveStale, []string{"alice", "bob"}},
+		{"deleted company", entity.RebalanceMove{CompanyID: "00000000-0000-0000-0000-000000000000", Version: 1, From: "alice", To: "bob"}, company.MoveNotFound, nil},
+		{"to not a user", entity.RebalanceMove{CompanyID: edited.ID, Version: edited.Version, From: "alice", To: "ghost"}, company.MoveRejected, []string{"alice"}},
+		{"applied", entity.RebalanceMove{CompanyID: accenture.ID, Version: accenture.Version, From: "alice", To: "bob"}, company.MoveApplied, []string{"bob"}},
+		{"keeps the officer's place", entity.RebalanceMove{CompanyID: infosys.ID, Version: infosys.Version, From: "alice", To: "bob"}, company.MoveApplied, []string{"carol", "bob"}},
+	}
+	moves := make([]entity.RebalanceMove, len(cases))
+	for i, c := range cases {
+		moves[i] = c.move
+	}
+	// Moves are applied one by one, so the skipped ones do not stop the
+	// rest.
+	results, err := service.ApplyRebalance(moves, "manager")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != len(cases) {
+		t.Fatalf("got %d results, want %d", len(results), len(cases))
+	}
+	for i, c := range cases {
+		if results[i].Status != c.status || results[i].CompanyID != c.move.CompanyID {
+			t.Errorf("%s: result = %+v, want %s", c.name, results[i], c.status)
+		}
+		if c.status == company.MoveRejected && results[i].Error == "" {
+			t.Errorf("%s: rejected move has no error", c.name)
+		}
+		if c.officers == nil {
+			continue
+		}
+		current, err := repo.GetCompany(c.move.CompanyID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(current.AssignedOfficer, c.officers) {
+			t.Errorf("%s: officers = %v, want %v", c.name, current.AssignedOfficer, c.officers)
+		}
+	}
+}
@@ -0,0 +1,269 @@
+package company
+
+import (
+	"backend/companyd/entity"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"log"
+	"net/http"
+	"path"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/google/uuid"
+)
+
+// BlobStore keeps the contents of attachments under keys the service
+// chooses. Keys are relative slash-separated paths such as
+// "<company id>/<uuid>". The local filesystem store is one implementation;
+// an S3-compatible store only needs these three methods.
+type BlobStore interface {
+	// Put stores everything read from r under key, replacing what was
+	// there. A failed Put must not leave partial contents under key.
+	Put(key string, r io.Reader) error
+	// Get opens the contents stored under key, returning ErrNotFound when
+	// there are none.
+	Get(key string) (io.ReadCloser, error)
+	// Delete removes the contents under key. Deleting a missing key does
+	// nothing.
+	Delete(key string) error
+}
+
+// AttachmentUpload is a document to attach to a company.
+type AttachmentUpload struct {
+	CompanyID string
+	Filename  string
+	// Checksum, when set, is the SHA-256 of the contents in hex as the
+	// client computed it; the upload fails with ErrChecksumMismatch when the
+	// contents received differ.
+	Checksum   string
+	UploadedBy string
+	Content    io.Reader
+}
+
+// sniffLength is how much of an upload http.DetectContentType looks at.
+const sniffLength = 512
+
+// maxFilenameLength limits attachment names, in characters.
+const maxFilenameLength = 255
+
+var checksumPattern = regexp.MustCompile(`^[0-9a-f]{64}$`)
+
+// officeTypes are the content types of Office Open XML documents, which
+// sniff as plain zip archives, by extension.
+var officeTypes = map[string]string{
+	".docx": "application/vnd.openxmlformats-officedocument.wordprocessingml.document",
+	".xlsx": "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+	".pptx": "application/vnd.openxmlformats-officedocument.presentationml.presentation",
+}
+
+// legacyOfficeTypes are the content types of pre-2007 Office documents,
+// which are OLE compound files, by extension.
+var legacyOfficeTypes = map[string]string{
+	".doc": "application/msword",
+	".xls": "application/vnd.ms-excel",
+	".ppt": "application/vnd.ms-powerpoint",
+}
+
+// oleSignature starts every OLE compound file.
+var oleSignature = []byte{0xD0, 0xCF, 0x11, 0xE0, 0xA1, 0xB1, 0x1A, 0xE1}
+
+// AttachmentContentType sniffs the content type of an attachment from the
+// start of its contents. PDFs, PNG and JPEG images, plain text and Word,
+// Excel and PowerPoint documents are allowed; the file name's extension
+// only tells Office documents apart, since they sniff as zip archives or
+// OLE files. It reports false for anything else, HTML included.
+func AttachmentContentType(filename string, head []byte) (string, bool) {
+	ext := strings.ToLower(path.Ext(filename))
+	detected := http.DetectContentType(head)
+	switch {
+	case detected == "application/pdf", detected == "image/png", detected == "image/jpeg":
+		return detected, true
+	case strings.HasPrefix(detected, "text/plain"):
+		return detected, true
+	case detected == "application/zip":
+		contentType, ok := officeTypes[ext]
+		return contentType, ok
+	case bytes.HasPrefix(head, oleSignature):
+		contentType, ok := legacyOfficeTypes[ext]
+		return contentType, ok
+	}
+	return "", false
+}
+
+// attachmentFilename keeps the last element of a client's file name, which
+// may be a full Windows or Unix path, without control characters.
+func attachmentFilename(name string) (string, error) {
+	if i := strings.LastIndexAny(name, `/\`); i >= 0 {
+		name = name[i+1:]
+	}
+	name = strings.TrimSpace(strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, name))
+	if name == "" || name == "." || name == ".." {
+		return "", fmt.Errorf("%w: a file name is required", ErrInvalidAttachment)
+	}
+	if len([]rune(name)) > maxFilenameLength {
+		return "", fmt.Errorf("%w: file names are limited to %d characters", ErrInvalidAttachment, maxFilenameLength)
+	}
+	return name, nil
+}
+
+// digest hashes and counts what is written to it.
+type digest struct {
+	hash hash.Hash
+	size int64
+}
+
+func (d *digest) Write(p []byte) (int, error) {
+	d.size += int64(len(p))
+	return d.hash.Write(p)
+}
+
+// UploadAttachment stores an attachment's contents and records it with its
+// company. The content type is sniffed from the contents, and the size and
+// checksum are measured while storing them; contents that turn out too
+// large or not to match the client's checksum are removed again.
+func (s *Service) UploadAttachment(upload AttachmentUpload) (*entity.Attachment, error) {
+	if s.blobs == nil {
+		return nil, ErrNoBlobStore
+	}
+	filename, err := attachmentFilename(upload.Filename)
+	if err != nil {
+		return nil, err
+	}
+	checksum := strings.ToLower(strings.TrimSpace(upload.Checksum))
+	if checksum != "" && !checksumPattern.MatchString(checksum) {
+		return nil, fmt.Errorf("%w: checksum must be a SHA-256 in hex", ErrInvalidAttachment)
+	}
+	if err := s.requireCompany(upload.CompanyID); err != nil {
+		return nil, err
+	}
+
+	head := make([]byte, sniffLength)
+	n, err := io.ReadFull(upload.Content, head)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return nil, err
+	}
+	head = head[:n]
+	if n == 0 {
+		return nil, fmt.Errorf("%w: the file is empty", ErrInvalidAttachment)
+	}
+	contentType, ok := AttachmentContentType(filename, head)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s is not a PDF, image, text or Office document", ErrUnsupportedAttachment, filename)
+	}
+
+	content := io.MultiReader(bytes.NewReader(head), upload.Content)
+	if s.maxAttachmentSize > 0 {
+		// One byte over the limit is enough to tell the file is too large.
+		content = io.LimitReader(content, s.maxAttachmentSize+1)
+	}
+	sum := &digest{hash: sha256.New()}
+	key := upload.CompanyID + "/" + uuid.NewString()
+	if err := s.blobs.Put(key, io.TeeReader(content, sum)); err != nil {
+		return nil, err
+	}
+	if s.maxAttachmentSize > 0 && sum.size > s.maxAttachmentSize {
+		s.removeBlob(key)
+		return nil, fmt.Errorf("%w: the limit is %d bytes", ErrAttachmentTooLarge, s.maxAttachmentSize)
+	}
+	attachment := entity.Attachment{
+		CompanyID:   upload.CompanyID,
+		Filename:    filename,
+		ContentType: contentType,
+		Size:        sum.size,
+		Checksum:    hex.EncodeToString(sum.hash.Sum(nil)),
+		StorageKey:  key,
+		UploadedBy:  upload.UploadedBy,
+	}
+	if checksum != "" && checksum != attachment.Checksum {
+		s.removeBlob(key)
+		return nil, ErrChecksumMismatch
+	}
+
+	created, err := s.repo.CreateAttachment(attachment)
+	if err != nil {
+		s.removeBlob(key)
+		return nil, err
+	}
+	return created, nil
+}
+
+// MaxAttachmentSize is the size limit of attachments in bytes, or 0 when
+// there is none.
+func (s *Service) MaxAttachmentSize() int64 {
+	return s.maxAttachmentSize
+}
+
+func (s *Service) GetAttachment(id string) (*entity.Attachment, error) {
+	return s.repo.GetAttachment(id)
+}
+
+func (s *Service) ListAttachments(companyID string) ([]*entity.Attachment, error) {
+	return s.repo.ListAttachments(companyID)
+}
+
+// OpenAttachment returns an attachment with its contents, which the caller
+// must close.
+func (s *Service) OpenAttachment(id string) (*entity.Attachment, io.ReadCloser, error) {
+	if s.blobs == nil {
+		return nil, nil, ErrNoBlobStore
+	}
+	attachment, err := s.repo.GetAttachment(id)
+	if err != nil {
+		return nil, nil, err
+	}
+	contents, err := s.blobs.Get(attachment.StorageKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	return attachment, contents, nil
+}
+
+// DeleteAttachment forgets an attachment, then removes its contents. An
+// attachment is never left pointing at missing contents; contents that
+// cannot be removed are only logged.
+func (s *Service) DeleteAttachment(id string) error {
+	attachment, err := s.repo.GetAttachment(id)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.DeleteAttachment(id); err != nil {
+		return err
+	}
+	s.removeBlob(attachment.StorageKey)
+	return nil
+}
+
+func (s *Service) removeBlob(key string) {
+	if s.blobs == nil {
+		return
+	}
+	if err := s.blobs.Delete(key); err != nil {
+		log.Printf("Error removing attachment contents %s: %v", key, err)
+	}
+}
+
+// removePurgedBlobs removes the contents of attachments listed before a
+// purge whose company the purge deleted. Attachments of a company restored
+// in the meantime still exist and keep theirs.
+func (s *Service) removePurgedBlobs(attachments []*entity.Attachment) {
+	for _, attachment := range attachments {
+		_, err := s.repo.GetAttachment(attachment.ID)
+		if errors.Is(err, ErrNotFound) {
+			s.removeBlob(attachment.StorageKey)
+		} else if err != nil {
+			log.Printf("Error checking purged attachment %s: %v", attachment.ID, err)
+		}
+	}
+}
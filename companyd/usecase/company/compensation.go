@@ -0,0 +1,348 @@
+package company
+
+import (
+	"backend/companyd/entity"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Compensation units. LPA amounts are lakhs (100,000) of the currency per
+// annum, the usual way Indian packages are quoted.
+const (
+	UnitLPA     = "LPA"
+	UnitAnnual  = "annual"
+	UnitMonthly = "monthly"
+)
+
+// DefaultCurrency is assumed when a package names no currency.
+const DefaultCurrency = "INR"
+
+var (
+	amountPattern   = regexp.MustCompile(`(\d[\d,]*(?:\.\d+)?)\s*(k\b|thousand|lpa|lakhs?|lacs?|l\b|cr\b|crores?|mn\b|million|m\b)?`)
+	segmentPattern  = regexp.MustCompile(`\s*(?:\+|;|,\s+|\band\b|\bplus\b|\bwith\b)\s*`)
+	rangePattern    = regexp.MustCompile(`\d\s*[a-z]*\s*(?:-|–|\bto\b)\s*\d`)
+	monthlyPattern  = regexp.MustCompile(`per\s*month|/\s*(?:month|mo|m)\b|\bp\.?m\b|monthly|\ba month`)
+	variablePattern = regexp.MustCompile(`variable|bonus|incentive`)
+	currencyCode    = regexp.MustCompile(`^[A-Z]{3}$`)
+)
+
+// currencySigns maps how currencies appear in package text to ISO codes.
+var currencySigns = []struct{ sign, code string }{
+	{"$", "USD"}, {"usd", "USD"},
+	{"€", "EUR"}, {"eur", "EUR"},
+	{"£", "GBP"}, {"gbp", "GBP"},
+}
+
+// multipliers scale an amount by the word that follows it.
+var multipliers = map[string]float64{
+	"k": 1e3, "thousand": 1e3,
+	"l": 1e5, "lpa": 1e5, "lakh": 1e5, "lakhs": 1e5, "lac": 1e5, "lacs": 1e5,
+	"m": 1e6, "mn": 1e6, "million": 1e6,
+	"cr": 1e7, "crore": 1e7, "crores": 1e7,
+}
+
+type parsedAmount struct {
+	value      float64
+	multiplier float64 // 0 when the text gave none
+}
+
+// ParseCompensation makes a best-effort structured package out of free text
+// such as "10 LPA", "8-12 LPA", "12,00,000 INR", "10 LPA + 2 LPA variable" or
+// "25k/month stipend". Text it cannot read is returned with NeedsReview set,
+// keeping whatever figures it did recognise. Empty text and placeholders such
+// as "NA" mean no package.
+func ParseCompensation(text string) entity.Compensation {
+	t := strings.ToLower(strings.TrimSpace(text))
+	if placeholders[t] {
+		return entity.Compensation{}
+	}
+
+	c := entity.Compensation{Currency: DefaultCurrency}
+	for _, cs := range currencySigns {
+		if strings.Contains(t, cs.sign) {
+			c.Currency = cs.code
+			break
+		}
+	}
+
+	segments := segmentPattern.Split(t, -1)
+	amounts := make([][]parsedAmount, len(segments))
+	for i, segment := range segments {
+		for _, m := range amountPattern.FindAllStringSubmatch(segment, -1) {
+			value, err := strconv.ParseFloat(strings.ReplaceAll(m[1], ",", ""), 64)
+			if err != nil {
+				continue
+			}
+			amounts[i] = append(amounts[i], parsedAmount{value, multipliers[m[2]]})
+		}
+	}
+	inheritMultipliers(amounts)
+
+	var base, variable, stipend []float64
+	var baseMonthly, variableMonthly, ranged bool
+	found := false
+	for i, segment := range segments {
+		if len(amounts[i]) == 0 {
+			continue
+		}
+		found = true
+		monthly := monthlyPattern.MatchString(segment)
+		values := make([]float64, len(amounts[i]))
+		for j, a := range amounts[i] {
+			values[j] = absoluteAmount(a, c.Currency, monthly)
+		}
+		isRange := len(values) == 2 && rangePattern.MatchString(segment)
+		if len(values) > 2 || (len(values) == 2 && !isRange) {
+			c.NeedsReview = true
+			continue
+		}
+
+		switch {
+		case strings.Contains(segment, "stipend"):
+			if stipend != nil {
+				c.NeedsReview = true
+				continue
+			}
+			stipend = values
+			if !monthly && amounts[i][0].multiplier >= 1e5 {
+				// "Stipend 3 LPA" is an annual figure.
+				for j := range stipend {
+					stipend[j] /= 12
+				}
+			}
+		case variablePattern.MatchString(segment):
+			if variable != nil || isRange {
+				c.NeedsReview = true
+				continue
+			}
+			variable, variableMonthly = values, monthly
+		default:
+			if base != nil {
+				c.NeedsReview = true
+				continue
+			}
+			base, baseMonthly, ranged = values, monthly, isRange
+		}
+	}
+	if !found {
+		return entity.Compensation{NeedsReview: true}
+	}
+
+	if stipend != nil {
+		// A stipend range is quoted at its lower bound.
+		c.Stipend = amountPtr(stipend[0])
+	}
+	if base == nil && variable == nil {
+		c.Unit = UnitMonthly
+		return c
+	}
+
+	// Quote everything per month only when every figure is monthly;
+	// otherwise convert monthly figures to annual ones.
+	allMonthly := (base == nil || baseMonthly) && (variable == nil || variableMonthly)
+	convert := func(v float64, monthly bool) *float64 {
+		if allMonthly {
+			return amountPtr(v)
+		}
+		if monthly {
+			v *= 12
+		}
+		if c.Currency == DefaultCurrency {
+			v /= 1e5
+		}
+		return amountPtr(v)
+	}
+	switch {
+	case allMonthly:
+		c.Unit = UnitMonthly
+	case c.Currency == DefaultCurrency:
+		c.Unit = UnitLPA
+	default:
+		c.Unit = UnitAnnual
+	}
+	if ranged {
+		c.Min, c.Max = convert(base[0], baseMonthly), convert(base[1], baseMonthly)
+	} else if base != nil {
+		c.Base = convert(base[0], baseMonthly)
+	}
+	if variable != nil {
+		c.Variable = convert(variable[0], variableMonthly)
+	}
+	return c
+}
+
+// inheritMultipliers gives amounts without a multiplier the one of the next
+// amount in the same segment ("8-12 LPA"), or else of the previous amount
+// ("8 LPA fixed + 2 variable").
+func inheritMultipliers(amounts [][]parsedAmount) {
+	var previous float64
+	for _, segment := range amounts {
+		for j := range segment {
+			if segment[j].multiplier != 0 {
+				previous = segment[j].multiplier
+				continue
+			}
+			for k := j + 1; k < len(segment); k++ {
+				if segment[k].multiplier != 0 {
+					segment[j].multiplier = segment[k].multiplier
+					break
+				}
+			}
+			if segment[j].multiplier == 0 {
+				segment[j].multiplier = previous
+			}
+		}
+	}
+}
+
+// absoluteAmount scales a to currency units. A bare rupee figure below 1000
+// that is not monthly can only be lakhs: nobody is paid 12 rupees a year.
+func absoluteAmount(a parsedAmount, currency string, monthly bool) float64 {
+	switch {
+	case a.multiplier != 0:
+		return a.value * a.multiplier
+	case currency == DefaultCurrency && !monthly && a.value < 1000:
+		return a.value * 1e5
+	default:
+		return a.value
+	}
+}
+
+func amountPtr(v float64) *float64 {
+	rounded := math.Round(v*1e4) / 1e4
+	return &rounded
+}
+
+// NormalizeCompensation fills in the default currency and unit of a package
+// entered by hand, and clears NeedsReview since a person supplied it.
+func NormalizeCompensation(c entity.Compensation) entity.Compensation {
+	c = c.Copy()
+	c.Currency = strings.ToUpper(strings.TrimSpace(c.Currency))
+	c.NeedsReview = false
+	if !hasAmounts(c) {
+		return c
+	}
+	if c.Currency == "" {
+		c.Currency = DefaultCurrency
+	}
+	if c.Unit == "" {
+		c.Unit = UnitAnnual
+		if c.Currency == DefaultCurrency {
+			c.Unit = UnitLPA
+		}
+	}
+	return c
+}
+
+func hasAmounts(c entity.Compensation) bool {
+	return c.Base != nil || c.Variable != nil || c.Stipend != nil || c.Min != nil || c.Max != nil
+}
+
+// ValidateCompensation reports every problem with a package entered by hand.
+// The error wraps ErrInvalidCompensation.
+func ValidateCompensation(c entity.Compensation) error {
+	var problems []string
+	if c.Currency != "" && !currencyCode.MatchString(c.Currency) {
+		problems = append(problems, "currency must be a three-letter code such as INR")
+	}
+	switch c.Unit {
+	case "", UnitLPA, UnitAnnual, UnitMonthly:
+	default:
+		problems = append(problems, fmt.Sprintf("unit must be %s, %s or %s", UnitLPA, UnitAnnual, UnitMonthly))
+	}
+	for _, a := range []struct {
+		name  string
+		value *float64
+	}{{"base", c.Base}, {"variable", c.Variable}, {"stipend", c.Stipend}, {"min", c.Min}, {"max", c.Max}} {
+		if a.value != nil && (*a.value < 0 || math.IsNaN(*a.value) || math.IsInf(*a.value, 0)) {
+			problems = append(problems, a.name+" must not be negative")
+		}
+	}
+	if (c.Min == nil) != (c.Max == nil) {
+		problems = append(problems, "min and max must be given together")
+	} else if c.Min != nil && *c.Min > *c.Max {
+		problems = append(problems, "min must not exceed max")
+	}
+	if c.Base != nil && c.Min != nil {
+		problems = append(problems, "give either base or a min-max range, not both")
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("%w: %s", ErrInvalidCompensation, strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// lakhsPerAnnum converts an amount quoted per unit to lakhs per annum.
+func lakhsPerAnnum(amount float64, unit string) float64 {
+	switch unit {
+	case UnitAnnual:
+		return amount / 1e5
+	case UnitMonthly:
+		return amount * 12 / 1e5
+	default:
+		return amount
+	}
+}
+
+// AnnualCTC is the cost to company in lakhs of the package's currency per
+// annum: base plus variable pay, or the middle of the range. Stipends are not
+// CTC. It is the value stored in package_amount and used by the package
+// filters and sort.
+func AnnualCTC(c entity.Compensation) (float64, bool) {
+	var amount float64
+	switch {
+	case c.Base != nil:
+		amount = *c.Base
+	case c.Min != nil && c.Max != nil:
+		amount = (*c.Min + *c.Max) / 2
+	case c.Min != nil:
+		amount = *c.Min
+	case c.Max != nil:
+		amount = *c.Max
+	default:
+		return 0, false
+	}
+	if c.Variable != nil {
+		amount += *c.Variable
+	}
+	return math.Round(lakhsPerAnnum(amount, c.Unit)*1e4) / 1e4, true
+}
+
+// FormatCompensation renders a package as display text, such as
+// "10 LPA + 2 LPA variable" or "INR 25000/month stipend".
+func FormatCompensation(c entity.Compensation) string {
+	number := func(v float64) string {
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	withUnit := func(amount string) string {
+		switch c.Unit {
+		case UnitLPA:
+			if c.Currency != DefaultCurrency {
+				return c.Currency + " " + amount + " LPA"
+			}
+			return amount + " LPA"
+		case UnitMonthly:
+			return c.Currency + " " + amount + "/month"
+		default:
+			return c.Currency + " " + amount + "/year"
+		}
+	}
+
+	var parts []string
+	switch {
+	case c.Base != nil:
+		parts = append(parts, withUnit(number(*c.Base)))
+	case c.Min != nil && c.Max != nil:
+		parts = append(parts, withUnit(number(*c.Min)+"-"+number(*c.Max)))
+	}
+	if c.Variable != nil {
+		parts = append(parts, withUnit(number(*c.Variable))+" variable")
+	}
+	if c.Stipend != nil {
+		parts = append(parts, c.Currency+" "+number(*c.Stipend)+"/month stipend")
+	}
+	return strings.Join(parts, " + ")
+}
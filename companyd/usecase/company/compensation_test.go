@@ -0,0 +1,96 @@
+package company
+
+import (
+	"backend/companyd/entity"
+	"reflect"
+	"testing"
+)
+
+func amount(v float64) *float64 {
+	return &v
+}
+
+func TestParseCompensation(t *testing.T) {
+	cases := []struct {
+		text string
+		want entity.Compensation
+	}{
+		// LPA and CTC.
+		{"10 LPA", entity.Compensation{Base: amount(10), Currency: "INR", Unit: UnitLPA}},
+		{"CTC 12 LPA", entity.Compensation{Base: amount(12), Currency: "INR", Unit: UnitLPA}},
+		{"12 CTC", entity.Compensation{Base: amount(12), Currency: "INR", Unit: UnitLPA}},
+		{"12,00,000 INR", entity.Compensation{Base: amount(12), Currency: "INR", Unit: UnitLPA}},
+		{"1.2 Cr", entity.Compensation{Base: amount(120), Currency: "INR", Unit: UnitLPA}},
+		{"10 LPA + 2 LPA variable", entity.Compensation{Base: amount(10), Variable: amount(2), Currency: "INR", Unit: UnitLPA}},
+		{"8 LPA fixed + 2 variable", entity.Compensation{Base: amount(8), Variable: amount(2), Currency: "INR", Unit: UnitLPA}},
+		{"30000 per month", entity.Compensation{Base: amount(30000), Currency: "INR", Unit: UnitMonthly}},
+
+		// Ranges.
+		{"8-12 LPA", entity.Compensation{Min: amount(8), Max: amount(12), Currency: "INR", Unit: UnitLPA}},
+		{"8 to 12 lakhs", entity.Compensation{Min: amount(8), Max: amount(12), Currency: "INR", Unit: UnitLPA}},
+
+		// Stipends, per month.
+		{"25k/month stipend", entity.Compensation{Stipend: amount(25000), Currency: "INR", Unit: UnitMonthly}},
+		{"Stipend 3 LPA", entity.Compensation{Stipend: amount(25000), Currency: "INR", Unit: UnitMonthly}},
+		{"stipend 15-20k per month", entity.Compensation{Stipend: amount(15000), Currency: "INR", Unit: UnitMonthly}},
+		{"stipend 20000 per month + 6 LPA", entity.Compensation{Base: amount(6), Stipend: amount(20000), Currency: "INR", Unit: UnitLPA}},
+
+		// Other currencies.
+		{"$120,000", entity.Compensation{Base: amount(120000), Currency: "USD", Unit: UnitAnnual}},
+		{"€ 50k", entity.Compensation{Base: amount(50000), Currency: "EUR", Unit: UnitAnnual}},
+		{"£40,000 per annum", entity.Compensation{Base: amount(40000), Currency: "GBP", Unit: UnitAnnual}},
+		{"USD 10k/month", entity.Compensation{Base: amount(10000), Currency: "USD", Unit: UnitMonthly}},
+
+		// No package.
+		{"", entity.Compensation{}},
+		{"NA", entity.Compensation{}},
+		{"-", entity.Compensation{}},
+
+		// Unreadable text, keeping what was recognised.
+		{"Competitive", entity.Compensation{NeedsReview: true}},
+		{"10 LPA + 12 LPA", entity.Compensation{Base: amount(10), Currency: "INR", Unit: UnitLPA, NeedsReview: true}},
+		{"10 LPA, 12 LPA, 14 LPA", entity.Compensation{Base: amount(10), Currency: "INR", Unit: UnitLPA, NeedsReview: true}},
+	}
+	for _, c := range cases {
+		if got := ParseCompensation(c.text); !reflect.DeepEqual(got, c.want) {
+			t.Errorf("ParseCompensation(%q) = %s, want %s", c.text, describe(got), describe(c.want))
+		}
+	}
+}
+
+// describe prints a package with its amounts rather than their addresses.
+func describe(c entity.Compensation) string {
+	if c.NeedsReview && !hasAmounts(c) {
+		return "needs review"
+	}
+	text := FormatCompensation(c)
+	if c.NeedsReview {
+		text += " (needs review)"
+	}
+	return text
+}
+
+func TestPercentile(t *testing.T) {
+	cases := []struct {
+		values []float64
+		p      float64
+		want   float64
+	}{
+		{nil, 0.5, 0},
+		{[]float64{7}, 0, 7},
+		{[]float64{7}, 0.5, 7},
+		{[]float64{7}, 0.9, 7},
+		{[]float64{1, 2, 3, 4}, 0, 1},
+		{[]float64{1, 2, 3, 4}, 0.25, 1.75},
+		{[]float64{1, 2, 3, 4}, 0.5, 2.5},
+		{[]float64{1, 2, 3, 4}, 0.9, 3.7},
+		{[]float64{1, 2, 3, 4}, 1, 4},
+		{[]float64{6, 8, 12}, 0.5, 8},
+		{[]float64{1, 2, 4}, 1.0 / 3, 1.67},
+	}
+	for _, c := range cases {
+		if got := percentile(c.values, c.p); got != c.want {
+			t.Errorf("percentile(%v, %v) = %v, want %v", c.values, c.p, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package company
+
+import (
+	"backend/companyd/entity"
+	"regexp"
+	"strings"
+)
+
+// ContactFilter selects contacts. Empty fields do not filter.
+type ContactFilter struct {
+	CompanyIDs []string
+	// Email matches case-insensitively, to find one person across companies.
+	Email string
+}
+
+var (
+	emailPattern    = regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`)
+	linkedInPattern = regexp.MustCompile(`(?i)(https?://)?([a-z]{2,3}\.)?linkedin\.com/[^\s,;|]+`)
+	phonePattern    = regexp.MustCompile(`\+?[0-9][0-9 ()\-]{7,}[0-9]`)
+	fieldSeparator  = regexp.MustCompile(`\s*(?:[,;|\n/]|\s-\s)\s*`)
+	labelPattern    = regexp.MustCompile(`(?i)^(name|hr|designation|role|email|e-mail|mail|phone|mobile|mob|ph|contact|linkedin)\s*[:.\-]\s*`)
+)
+
+// placeholders are free-text values that mean "no contact".
+var placeholders = map[string]bool{"": true, "-": true, "na": true, "n/a": true, "nil": true, "none": true, "null": true, "tbd": true}
+
+// ParseContact makes a best-effort Contact out of free text such as
+// "Priya Sharma, Talent Acquisition, priya@infosys.com, +91 98765 43210".
+// Emails, phone numbers and LinkedIn URLs are recognised by shape; of the
+// remaining comma-separated parts, the first is taken as the name and the
+// second as the designation. It reports false when text holds no contact.
+func ParseContact(text string) (entity.Contact, bool) {
+	text = strings.TrimSpace(text)
+	if placeholders[strings.ToLower(text)] {
+		return entity.Contact{}, false
+	}
+
+	var contact entity.Contact
+	rest := text
+	if m := linkedInPattern.FindString(rest); m != "" {
+		contact.LinkedIn = m
+		rest = strings.Replace(rest, m, " ", 1)
+	}
+	if m := emailPattern.FindString(rest); m != "" {
+		contact.Email = strings.ToLower(m)
+		rest = strings.Replace(rest, m, " ", 1)
+	}
+	if m := phonePattern.FindString(rest); m != "" {
+		contact.Phone = strings.TrimSpace(m)
+		rest = strings.Replace(rest, m, " ", 1)
+	}
+
+	var words []string
+	for _, part := range fieldSeparator.Split(rest, -1) {
+		part = strings.TrimSpace(labelPattern.ReplaceAllString(strings.TrimSpace(part), ""))
+		if part != "" && strings.IndexFunc(part, isLetter) >= 0 {
+			words = append(words, part)
+		}
+	}
+	if len(words) > 0 {
+		contact.Name = words[0]
+	}
+	if len(words) > 1 {
+		contact.Designation = words[1]
+	}
+
+	if contact.Name == "" && contact.Email == "" && contact.Phone == "" && contact.LinkedIn == "" {
+		return entity.Contact{}, false
+	}
+	if contact.Name == "" {
+		contact.Name = fallbackName(contact)
+	}
+	return contact, true
+}
+
+func isLetter(r rune) bool {
+	return (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z')
+}
+
+func fallbackName(c entity.Contact) string {
+	if c.Email != "" {
+		return c.Email[:strings.Index(c.Email, "@")]
+	}
+	return "HR contact"
+}
+
+// ImportContacts turns a company's free-text hr1_details, hr2_details and
+// contact_details into contacts. The source text is kept in Notes so nothing
+// is lost when parsing guesses wrong. The first contact found is primary.
+func ImportContacts(c *entity.Company) []entity.Contact {
+	sources := []struct{ column, text string }{
+		{"hr1_details", c.HR1Details},
+		{"hr2_details", c.HR2Details},
+		{"contact_details", c.ContactDetails},
+	}
+
+	var contacts []entity.Contact
+	for _, source := range sources {
+		contact, ok := ParseContact(source.text)
+		if !ok {
+			continue
+		}
+		contact.CompanyID = c.ID
+		contact.IsPrimary = len(contacts) == 0
+		contact.Notes = "Imported from " + source.column + ": " + strings.TrimSpace(source.text)
+		contacts = append(contacts, contact)
+	}
+	return contacts
+}
@@ -0,0 +1,226 @@
+package company
+
+import (
+	"backend/companyd/entity"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Drive types.
+const (
+	DriveOnCampus  = "on-campus"
+	DriveOffCampus = "off-campus"
+	DrivePool      = "pool"
+	DriveVirtual   = "virtual"
+)
+
+// Drive statuses. A drive starts out planned.
+const (
+	DrivePlanned   = "planned"
+	DriveScheduled = "scheduled"
+	DriveOngoing   = "ongoing"
+	DriveCompleted = "completed"
+	DriveCancelled = "cancelled"
+)
+
+// DriveTypes and DriveStatuses list the valid values, for messages.
+var (
+	DriveTypes    = []string{DriveOnCampus, DriveOffCampus, DrivePool, DriveVirtual}
+	DriveStatuses = []string{DrivePlanned, DriveScheduled, DriveOngoing, DriveCompleted, DriveCancelled}
+)
+
+// IsDriveType reports whether t is a drive type.
+func IsDriveType(t string) bool {
+	switch t {
+	case DriveOnCampus, DriveOffCampus, DrivePool, DriveVirtual:
+		return true
+	}
+	return false
+}
+
+// IsDriveStatus reports whether status is a drive status.
+func IsDriveStatus(status string) bool {
+	switch status {
+	case DrivePlanned, DriveScheduled, DriveOngoing, DriveCompleted, DriveCancelled:
+		return true
+	}
+	return false
+}
+
+// NormalizeDriveType reads a free-text type of drive such as "On Campus" or
+// "Pool Campus". It reports false for text that names no drive type.
+func NormalizeDriveType(text string) (string, bool) {
+	t := strings.ToLower(strings.TrimSpace(text))
+	t = strings.NewReplacer(" ", "-", "_", "-").Replace(t)
+	switch {
+	case IsDriveType(t):
+		return t, true
+	case strings.HasPrefix(t, "pool"):
+		return DrivePool, true
+	case strings.HasPrefix(t, "oncampus"), strings.HasPrefix(t, "on-campus"), t == "campus":
+		return DriveOnCampus, true
+	case strings.HasPrefix(t, "offcampus"), strings.HasPrefix(t, "off-campus"):
+		return DriveOffCampus, true
+	case t == "online", strings.HasPrefix(t, "virtual"), strings.HasPrefix(t, "remote"):
+		return DriveVirtual, true
+	}
+	return "", false
+}
+
+var (
+	seasonPattern = regexp.MustCompile(`^(\d{4})\s*[-/–]\s*(\d{2}|\d{4})$`)
+	yearPattern   = regexp.MustCompile(`\b(20\d{2})\b`)
+)
+
+// ParseSeason reads an academic year such as "2026-27", "2026-2027" or
+// "2026/27" and returns it as "2026-27". It reports false for anything
+// else, including years that do not follow each other.
+func ParseSeason(text string) (string, bool) {
+	m := seasonPattern.FindStringSubmatch(strings.TrimSpace(text))
+	if m == nil {
+		return "", false
+	}
+	start, _ := strconv.Atoi(m[1])
+	end, _ := strconv.Atoi(m[2])
+	if len(m[2]) == 2 {
+		end += start / 100 * 100
+		if end < start {
+			end += 100
+		}
+	}
+	if end != start+1 {
+		return "", false
+	}
+	return SeasonOf(start), true
+}
+
+// SeasonOf is the academic year starting in year, such as "2026-27".
+func SeasonOf(year int) string {
+	return fmt.Sprintf("%d-%02d", year, (year+1)%100)
+}
+
+// SeasonAt is the academic year under way at t. Seasons run from July to
+// June.
+func SeasonAt(t time.Time) string {
+	t = t.UTC()
+	if t.Month() < time.July {
+		return SeasonOf(t.Year() - 1)
+	}
+	return SeasonOf(t.Year())
+}
+
+// SeasonBounds returns when a season, as returned by ParseSeason, starts and
+// when the next one does: 1 July, midnight UTC.
+func SeasonBounds(season string) (time.Time, time.Time) {
+	year, _ := strconv.Atoi(season[:4])
+	from := time.Date(year, time.July, 1, 0, 0, 0, 0, time.UTC)
+	return from, from.AddDate(1, 0, 0)
+}
+
+// DriveFilter selects drives, soonest first. Empty fields do not filter.
+type DriveFilter struct {
+	CompanyID string
+	Season    string
+	Type      string
+	Status    string
+}
+
+// Matches reports whether d passes the filter, for repositories that filter
+// in Go.
+func (filter DriveFilter) Matches(d *entity.Drive) bool {
+	switch {
+	case filter.CompanyID != "" && d.CompanyID != filter.CompanyID:
+		return false
+	case filter.Season != "" && d.Season != filter.Season:
+		return false
+	case filter.Type != "" && d.Type != filter.Type:
+		return false
+	case filter.Status != "" && d.Status != filter.Status:
+		return false
+	}
+	return true
+}
+
+// ImportDrive turns a company's free-text drive and type_of_drive into a
+// drive. A drive that names an academic year, such as "2026-27", becomes the
+// season; a lone year, such as "2026", is taken as the graduating year, so
+// its season is the one before. Unrecognised types become on-campus drives,
+// and whatever could not be read is kept in the notes. It reports false
+// when both are empty.
+func ImportDrive(companyID, drive, typeOfDrive, pkg string) (entity.Drive, bool) {
+	drive, typeOfDrive = strings.TrimSpace(drive), strings.TrimSpace(typeOfDrive)
+	if drive == "" && typeOfDrive == "" {
+		return entity.Drive{}, false
+	}
+	imported := entity.Drive{
+		CompanyID: companyID,
+		Type:      DriveOnCampus,
+		Package:   strings.TrimSpace(pkg),
+		Status:    DrivePlanned,
+		Roles:     []string{},
+	}
+	var notes []string
+	if t, ok := NormalizeDriveType(typeOfDrive); ok {
+		imported.Type = t
+	} else if typeOfDrive != "" {
+		notes = append(notes, "Imported from type_of_drive: "+typeOfDrive)
+	}
+	if season, ok := ParseSeason(drive); ok {
+		imported.Season = season
+	} else if m := yearPattern.FindStringSubmatch(drive); m != nil {
+		year, _ := strconv.Atoi(m[1])
+		imported.Season = SeasonOf(year - 1)
+	}
+	if drive != "" && imported.Season != drive {
+		notes = append(notes, "Imported from drive: "+drive)
+	}
+	imported.Notes = strings.Join(notes, "\n")
+	return imported, true
+}
+
+// CreateDrive adds a drive to an existing company. Drives start out
+// planned unless a status is given.
+func (s *Service) CreateDrive(drive entity.Drive) (*entity.Drive, error) {
+	if err := s.requireCompany(drive.CompanyID); err != nil {
+		return nil, err
+	}
+	if drive.Status == "" {
+		drive.Status = DrivePlanned
+	}
+	if drive.Roles == nil {
+		drive.Roles = []string{}
+	}
+	return s.repo.CreateDrive(drive)
+}
+
+func (s *Service) GetDrive(id string) (*entity.Drive, error) {
+	return s.repo.GetDrive(id)
+}
+
+func (s *Service) ListDrives(filter DriveFilter) ([]*entity.Drive, error) {
+	return s.repo.ListDrives(filter)
+}
+
+// UpdateDrive replaces a drive's details. The drive stays with its company,
+// and keeps its status unless a new one is given.
+func (s *Service) UpdateDrive(id string, drive entity.Drive) (*entity.Drive, error) {
+	current, err := s.repo.GetDrive(id)
+	if err != nil {
+		return nil, err
+	}
+	drive.CompanyID = current.CompanyID
+	if drive.Status == "" {
+		drive.Status = current.Status
+	}
+	if drive.Roles == nil {
+		drive.Roles = []string{}
+	}
+	return s.repo.UpdateDrive(id, drive)
+}
+
+func (s *Service) DeleteDrive(id string) error {
+	return s.repo.DeleteDrive(id)
+}
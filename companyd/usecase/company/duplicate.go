@@ -0,0 +1,201 @@
+package company
+
+import (
+	"backend/companyd/entity"
+	"sort"
+	"strings"
+)
+
+// DuplicateThreshold is the minimum name similarity for two companies to be
+// reported as likely duplicates. It accepts a typo such as "Infosis" for
+// "Infosys" but not two companies sharing one word, such as "Tata Motors"
+// and "Tata Steel".
+const DuplicateThreshold = 0.4
+
+// legalSuffixes are words that distinguish the legal form of a company rather
+// than the company, and are dropped from normalised names.
+var legalSuffixes = map[string]bool{
+	"the": true, "ltd": true, "limited": true, "pvt": true, "private": true,
+	"inc": true, "incorporated": true, "corp": true, "corporation": true,
+	"co": true, "company": true, "llp": true, "llc": true, "plc": true,
+}
+
+// NormalizeCompanyName lower-cases name, drops punctuation and legal forms,
+// so that "Infosys", "Infosys Ltd" and "INFOSYS LIMITED" are all "infosys".
+func NormalizeCompanyName(name string) string {
+	var words []string
+	for _, w := range SearchTerms(name) {
+		if !legalSuffixes[w] {
+			words = append(words, w)
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+// NameSimilarity scores how alike two company names are, from 0 to 1. Names
+// that normalise to the same text score 1; otherwise the score is the share
+// of their word trigrams in common, as pg_trgm's similarity computes it.
+func NameSimilarity(a, b string) float64 {
+	a, b = NormalizeCompanyName(a), NormalizeCompanyName(b)
+	if a == "" || b == "" {
+		return 0
+	}
+	if a == b {
+		return 1
+	}
+	at, bt := nameTrigrams(a), nameTrigrams(b)
+	shared := 0
+	for t := range at {
+		if bt[t] {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(at)+len(bt)-shared)
+}
+
+func nameTrigrams(name string) map[string]bool {
+	set := map[string]bool{}
+	for _, w := range strings.Fields(name) {
+		for t := range trigrams(w) {
+			set[t] = true
+		}
+	}
+	return set
+}
+
+// FindDuplicates returns the companies whose names are likely duplicates of
+// name, most similar first.
+func FindDuplicates(name string, companies []*entity.Company) []*entity.DuplicateMatch {
+	matches := []*entity.DuplicateMatch{}
+	for _, c := range companies {
+		if similarity := NameSimilarity(name, c.CompanyName); similarity >= DuplicateThreshold {
+			matches = append(matches, &entity.DuplicateMatch{Company: c, Similarity: similarity})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].Similarity != matches[j].Similarity {
+			return matches[i].Similarity > matches[j].Similarity
+		}
+		return matches[i].Company.CreatedAt < matches[j].Company.CreatedAt
+	})
+	return matches
+}
+
+// GroupDuplicates partitions companies into groups of likely duplicates.
+// Similarity is transitive within a group: if A is like B and B like C, all
+// three are grouped. Companies with no duplicate are left out. Groups are
+// ordered by their oldest company.
+func GroupDuplicates(companies []*entity.Company) []*entity.DuplicateGroup {
+	sorted := append([]*entity.Company{}, companies...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].CreatedAt < sorted[j].CreatedAt
+	})
+
+	// Union-find over company indexes, rooted at the oldest company.
+	parent := make([]int, len(sorted))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+	for i := range sorted {
+		for j := i + 1; j < len(sorted); j++ {
+			if NameSimilarity(sorted[i].CompanyName, sorted[j].CompanyName) < DuplicateThreshold {
+				continue
+			}
+			if ri, rj := find(i), find(j); ri != rj {
+				if ri < rj {
+					parent[rj] = ri
+				} else {
+					parent[ri] = rj
+				}
+			}
+		}
+	}
+
+	byRoot := map[int]*entity.DuplicateGroup{}
+	groups := []*entity.DuplicateGroup{}
+	for i, c := range sorted {
+		root := find(i)
+		group, ok := byRoot[root]
+		if !ok {
+			group = &entity.DuplicateGroup{}
+			byRoot[root] = group
+			groups = append(groups, group)
+		}
+		group.Companies = append(group.Companies, c)
+	}
+
+	duplicates := []*entity.DuplicateGroup{}
+	for _, group := range groups {
+		if len(group.Companies) > 1 {
+			duplicates = append(duplicates, group)
+		}
+	}
+	return duplicates
+}
+
+// MergeFields combines the editable fields of duplicate into survivor. The
+// survivor's values win; empty ones are filled from the duplicate. Remarks
+// from both are kept, the assigned officers and tags are the union of both,
+// and custom fields the survivor has no value for take the duplicate's. The
+// pipeline status is left to the service, which knows the pipeline.
+func MergeFields(survivor, duplicate *entity.Company) entity.CompanyUpdate {
+	merged := entity.NewCompanySnapshot(survivor)
+	from := entity.NewCompanySnapshot(duplicate)
+
+	fill := func(dst *string, src string) {
+		if strings.TrimSpace(*dst) == "" {
+			*dst = src
+		}
+	}
+	fill(&merged.CompanyAddress, from.CompanyAddress)
+	fill(&merged.Drive, from.Drive)
+	fill(&merged.TypeOfDrive, from.TypeOfDrive)
+	fill(&merged.FollowUp, from.FollowUp)
+	fill(&merged.ContactDetails, from.ContactDetails)
+	fill(&merged.HR1Details, from.HR1Details)
+	fill(&merged.HR2Details, from.HR2Details)
+	if strings.TrimSpace(merged.Package) == "" {
+		merged.Package = from.Package
+		merged.Compensation = from.Compensation
+	}
+
+	if remarks := strings.TrimSpace(from.Remarks); remarks != "" && remarks != strings.TrimSpace(merged.Remarks) {
+		if strings.TrimSpace(merged.Remarks) == "" {
+			merged.Remarks = from.Remarks
+		} else {
+			merged.Remarks += "\n" + from.Remarks
+		}
+	}
+	for _, officer := range from.AssignedOfficer {
+		if !containsOfficer(merged.AssignedOfficer, officer) {
+			merged.AssignedOfficer = append(merged.AssignedOfficer, officer)
+		}
+	}
+	for key, value := range from.CustomFields {
+		if _, ok := merged.CustomFields[key]; !ok {
+			merged.CustomFields[key] = value
+		}
+	}
+	for _, tag := range from.Tags {
+		if !containsString(merged.Tags, tag) {
+			merged.Tags = append(merged.Tags, tag)
+		}
+	}
+	return merged.Update()
+}
+
+func containsOfficer(officers []string, officer string) bool {
+	for _, o := range officers {
+		if o == officer {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,88 @@
+package company
+
+import "errors"
+
+var (
+	// ErrNotFound is returned when the requested company or proposal does not exist.
+	ErrNotFound = errors.New("not found")
+	// ErrVersionMismatch is returned when a company has been modified since the
+	// version the caller based its update on.
+	ErrVersionMismatch = errors.New("company has been modified by someone else")
+	// ErrStaleProposal is returned when approving a proposal whose company has
+	// changed since the proposal was created.
+	ErrStaleProposal = errors.New("company has been modified since this change was proposed")
+	// ErrUnknownCompany is returned when a record refers to a company that
+	// does not exist.
+	ErrUnknownCompany = errors.New("company does not exist")
+	// ErrInvalidCompensation is returned for a structured package with
+	// impossible figures, such as a negative amount or min above max.
+	ErrInvalidCompensation = errors.New("invalid compensation")
+	// ErrNoOfficer is returned for a follow-up that names no officer when its
+	// company has no assigned officer to default to.
+	ErrNoOfficer = errors.New("follow-up needs an officer and the company has none assigned")
+	// ErrFutureInteraction is returned when logging an interaction that has
+	// not happened yet; schedule a follow-up instead.
+	ErrFutureInteraction = errors.New("occurredAt must not be in the future")
+	// ErrUnknownVersion is returned for a version missing from a company's
+	// history.
+	ErrUnknownVersion = errors.New("version not found in the company's history")
+	// ErrSelfMerge is returned when merging a company into itself.
+	ErrSelfMerge = errors.New("a company cannot be merged into itself")
+	// ErrUnknownOfficer is returned when assigning a company to a username
+	// that is not a user. The error names the unknown usernames.
+	ErrUnknownOfficer = errors.New("officer is not a user")
+	// ErrNoCandidate is returned when assigning an officer automatically and
+	// every user with the Officer role is already assigned, or there are
+	// none.
+	ErrNoCandidate = errors.New("no officer left to assign")
+	// ErrUnknownStage is returned when moving a company to a stage the
+	// pipeline does not have.
+	ErrUnknownStage = errors.New("not a stage of the pipeline")
+	// ErrInvalidTransition is returned when moving a company to a stage its
+	// current stage does not lead to; see TransitionError.
+	ErrInvalidTransition = errors.New("transition not allowed by the pipeline")
+	// ErrUnknownProposalStatus is returned when setting a proposal to a
+	// status that is not one of ProposalStatuses.
+	ErrUnknownProposalStatus = errors.New("not a proposal status")
+	// ErrInvalidProposalTransition is returned when moving a proposal to a
+	// status its current status does not lead to, such as approving a
+	// rejected proposal; see ProposalTransitionError.
+	ErrInvalidProposalTransition = errors.New("transition not allowed for this proposal")
+	// ErrInvalidImport is returned when committing an import with invalid
+	// rows. Nothing is created; the import report says what to fix.
+	ErrInvalidImport = errors.New("import has invalid rows; nothing was imported")
+	// ErrInvalidSeason is returned for a season that is not an academic year
+	// such as 2026-27.
+	ErrInvalidSeason = errors.New("season must be an academic year such as 2026-27")
+	// ErrInvalidRollover is returned when rolling companies over into a
+	// season that does not come after theirs.
+	ErrInvalidRollover = errors.New("companies can only be rolled over into a later season")
+	// ErrInvalidCustomField is returned for a custom field definition with a
+	// malformed key, an unknown type or no options to choose from.
+	ErrInvalidCustomField = errors.New("invalid custom field")
+	// ErrFieldExists is returned when defining a custom field with the key
+	// of an existing one.
+	ErrFieldExists = errors.New("a custom field with this key already exists")
+	// ErrInvalidCustomValue is returned for a company's custom field value
+	// that the field's type or options do not allow, a value for a field
+	// that does not exist, or a missing required value. The error names the
+	// field.
+	ErrInvalidCustomValue = errors.New("invalid custom field value")
+	// ErrInvalidTag is returned for tags that are too long or too many.
+	ErrInvalidTag = errors.New("invalid tags")
+	// ErrInvalidAttachment is returned for an attachment without a file
+	// name or without contents.
+	ErrInvalidAttachment = errors.New("invalid attachment")
+	// ErrAttachmentTooLarge is returned for an attachment over the size
+	// limit. Nothing is kept.
+	ErrAttachmentTooLarge = errors.New("attachment is too large")
+	// ErrUnsupportedAttachment is returned for an attachment whose contents
+	// are not a document or image type that attachments allow.
+	ErrUnsupportedAttachment = errors.New("attachment type is not allowed")
+	// ErrChecksumMismatch is returned when an attachment's contents do not
+	// match the checksum the client sent. Nothing is kept.
+	ErrChecksumMismatch = errors.New("attachment does not match its checksum")
+	// ErrNoBlobStore is returned for attachments when the service has no
+	// blob store to keep them in.
+	ErrNoBlobStore = errors.New("attachment storage is not configured")
+)
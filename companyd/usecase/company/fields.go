@@ -0,0 +1,397 @@
+package company
+
+import (
+	"backend/companyd/entity"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Custom field types.
+const (
+	FieldText        = "text"
+	FieldNumber      = "number"
+	FieldDate        = "date"
+	FieldEnum        = "enum"
+	FieldMultiSelect = "multi_select"
+)
+
+// FieldTypes lists the custom field types, for messages.
+var FieldTypes = []string{FieldText, FieldNumber, FieldDate, FieldEnum, FieldMultiSelect}
+
+// IsFieldType reports whether t is a custom field type.
+func IsFieldType(t string) bool {
+	for _, fieldType := range FieldTypes {
+		if t == fieldType {
+			return true
+		}
+	}
+	return false
+}
+
+// Limits on custom fields and tags, so that they stay short labels rather
+// than free text.
+const (
+	maxFieldOptions = 100
+	maxOptionLength = 100
+	maxTextValue    = 1000
+	maxTagLength    = 40
+	maxTags         = 20
+)
+
+var fieldKeyPattern = regexp.MustCompile(`^[a-z][a-z0-9_]{0,39}$`)
+
+// NormalizeCustomField checks the definition of a custom field and tidies
+// its label and options. Options only apply to enum and multi_select
+// fields, which need at least one.
+func NormalizeCustomField(field entity.CustomField) (entity.CustomField, error) {
+	field.Key = strings.TrimSpace(field.Key)
+	field.Label = strings.TrimSpace(field.Label)
+	field.Type = strings.TrimSpace(field.Type)
+	if !fieldKeyPattern.MatchString(field.Key) {
+		return field, fmt.Errorf("%w: key must be 1 to 40 lowercase letters, digits or underscores, starting with a letter", ErrInvalidCustomField)
+	}
+	if field.Label == "" {
+		field.Label = field.Key
+	}
+	if !IsFieldType(field.Type) {
+		return field, fmt.Errorf("%w: type must be one of %s", ErrInvalidCustomField, strings.Join(FieldTypes, ", "))
+	}
+
+	options := []string{}
+	if field.Type == FieldEnum || field.Type == FieldMultiSelect {
+		seen := map[string]bool{}
+		for _, option := range field.Options {
+			option = strings.TrimSpace(option)
+			if option == "" || seen[strings.ToLower(option)] {
+				continue
+			}
+			if len(option) > maxOptionLength {
+				return field, fmt.Errorf("%w: options must be at most %d characters", ErrInvalidCustomField, maxOptionLength)
+			}
+			seen[strings.ToLower(option)] = true
+			options = append(options, option)
+		}
+		if len(options) == 0 {
+			return field, fmt.Errorf("%w: %s fields need options", ErrInvalidCustomField, field.Type)
+		}
+		if len(options) > maxFieldOptions {
+			return field, fmt.Errorf("%w: at most %d options", ErrInvalidCustomField, maxFieldOptions)
+		}
+	}
+	field.Options = options
+	return field, nil
+}
+
+// NormalizeCustomValues checks values against the custom fields and returns
+// them in their stored form: text trimmed, numbers as float64, dates as
+// YYYY-MM-DD and options spelt as defined. Numbers and dates may be given
+// as strings. Empty values are dropped, and every required field must have
+// one.
+func NormalizeCustomValues(fields []*entity.CustomField, values entity.CustomValues) (entity.CustomValues, error) {
+	byKey := map[string]*entity.CustomField{}
+	for _, field := range fields {
+		byKey[field.Key] = field
+	}
+	normalized := entity.CustomValues{}
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		field := byKey[key]
+		if field == nil {
+			return nil, fmt.Errorf("%w: %q is not a custom field", ErrInvalidCustomValue, key)
+		}
+		value, err := normalizeCustomValue(field, values[key])
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s %s", ErrInvalidCustomValue, key, err.Error())
+		}
+		if value != nil {
+			normalized[key] = value
+		}
+	}
+	for _, field := range fields {
+		if _, ok := normalized[field.Key]; field.Required && !ok {
+			return nil, fmt.Errorf("%w: %s is required", ErrInvalidCustomValue, field.Key)
+		}
+	}
+	return normalized, nil
+}
+
+// normalizeCustomValue returns value in its stored form, or nil when it is
+// empty. The error completes a sentence that starts with the field's key.
+func normalizeCustomValue(field *entity.CustomField, value interface{}) (interface{}, error) {
+	if value == nil {
+		return nil, nil
+	}
+	switch field.Type {
+	case FieldNumber:
+		switch v := value.(type) {
+		case float64:
+			return v, nil
+		case int:
+			return float64(v), nil
+		case string:
+			if strings.TrimSpace(v) == "" {
+				return nil, nil
+			}
+			if n, err := strconv.ParseFloat(strings.TrimSpace(v), 64); err == nil {
+				return n, nil
+			}
+		}
+		return nil, fmt.Errorf("must be a number")
+	case FieldMultiSelect:
+		var list []string
+		switch v := value.(type) {
+		case []string:
+			list = v
+		case []interface{}:
+			for _, item := range v {
+				s, ok := item.(string)
+				if !ok {
+					return nil, fmt.Errorf("must be a list of options")
+				}
+				list = append(list, s)
+			}
+		case string:
+			// A single choice, as given in a filter.
+			list = []string{v}
+		default:
+			return nil, fmt.Errorf("must be a list of options")
+		}
+		chosen := []string{}
+		for _, item := range list {
+			if strings.TrimSpace(item) == "" {
+				continue
+			}
+			option, ok := findOption(field.Options, item)
+			if !ok {
+				return nil, fmt.Errorf("must be among %s", strings.Join(field.Options, ", "))
+			}
+			if !containsString(chosen, option) {
+				chosen = append(chosen, option)
+			}
+		}
+		if len(chosen) == 0 {
+			return nil, nil
+		}
+		return chosen, nil
+	}
+
+	text, ok := value.(string)
+	if !ok {
+		return nil, fmt.Errorf("must be a string")
+	}
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return nil, nil
+	}
+	switch field.Type {
+	case FieldDate:
+		if t, err := time.Parse("2006-01-02", text); err == nil {
+			return t.Format("2006-01-02"), nil
+		}
+		if t, err := time.Parse(time.RFC3339, text); err == nil {
+			return t.UTC().Format("2006-01-02"), nil
+		}
+		return nil, fmt.Errorf("must be a YYYY-MM-DD date")
+	case FieldEnum:
+		option, ok := findOption(field.Options, text)
+		if !ok {
+			return nil, fmt.Errorf("must be one of %s", strings.Join(field.Options, ", "))
+		}
+		return option, nil
+	default:
+		if len(text) > maxTextValue {
+			return nil, fmt.Errorf("must be at most %d characters", maxTextValue)
+		}
+		return text, nil
+	}
+}
+
+// findOption matches text to an option regardless of case.
+func findOption(options []string, text string) (string, bool) {
+	text = strings.TrimSpace(text)
+	for _, option := range options {
+		if strings.EqualFold(option, text) {
+			return option, true
+		}
+	}
+	return "", false
+}
+
+// hasCustomField reports whether a field with key is among fields.
+func hasCustomField(fields []*entity.CustomField, key string) bool {
+	for _, field := range fields {
+		if field.Key == key {
+			return true
+		}
+	}
+	return false
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// NormalizeTags lowercases tags and collapses their whitespace, dropping
+// empty and repeated ones but keeping their order.
+func NormalizeTags(tags []string) ([]string, error) {
+	normalized := []string{}
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.Join(strings.Fields(tag), " "))
+		if tag == "" || containsString(normalized, tag) {
+			continue
+		}
+		if len(tag) > maxTagLength {
+			return nil, fmt.Errorf("%w: tags must be at most %d characters", ErrInvalidTag, maxTagLength)
+		}
+		normalized = append(normalized, tag)
+	}
+	if len(normalized) > maxTags {
+		return nil, fmt.Errorf("%w: a company can have at most %d tags", ErrInvalidTag, maxTags)
+	}
+	return normalized, nil
+}
+
+// FieldFilter selects the companies whose custom field Key has Value; for a
+// multi_select field, Value is one option the company must have chosen.
+// Parse the filter from a request as Value text, then resolve it with
+// Service.QueryCompanies, which fills in Multi and the stored form of Value.
+type FieldFilter struct {
+	Key   string
+	Value interface{}
+	Multi bool
+}
+
+// Matches reports whether values pass the filter, for repositories that
+// filter in Go.
+func (f FieldFilter) Matches(values entity.CustomValues) bool {
+	value, ok := values[f.Key]
+	if !ok {
+		return false
+	}
+	if f.Multi {
+		list, _ := value.([]string)
+		option, _ := f.Value.(string)
+		return containsString(list, option)
+	}
+	return value == f.Value
+}
+
+// resolveFieldFilters checks filters against the custom fields and puts
+// their values in stored form.
+func resolveFieldFilters(fields []*entity.CustomField, filters []FieldFilter) ([]FieldFilter, error) {
+	byKey := map[string]*entity.CustomField{}
+	for _, field := range fields {
+		byKey[field.Key] = field
+	}
+	resolved := make([]FieldFilter, len(filters))
+	for i, filter := range filters {
+		field := byKey[filter.Key]
+		if field == nil {
+			return nil, fmt.Errorf("%w: %q is not a custom field", ErrInvalidCustomValue, filter.Key)
+		}
+		value, err := normalizeCustomValue(field, filter.Value)
+		if err == nil && value == nil {
+			err = fmt.Errorf("needs a value")
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: field.%s %s", ErrInvalidCustomValue, filter.Key, err.Error())
+		}
+		resolved[i] = FieldFilter{Key: filter.Key, Value: value, Multi: field.Type == FieldMultiSelect}
+		if list, ok := value.([]string); ok {
+			resolved[i].Value = list[0]
+		}
+	}
+	return resolved, nil
+}
+
+// FormatCustomValue writes a custom field value as text, for exports.
+func FormatCustomValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case []string:
+		return strings.Join(v, ", ")
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// ListCustomFields returns the custom fields in the order they were
+// defined.
+func (s *Service) ListCustomFields() ([]*entity.CustomField, error) {
+	return s.repo.ListCustomFields()
+}
+
+// CreateCustomField defines a new custom field; its key must be unused.
+func (s *Service) CreateCustomField(field entity.CustomField) (*entity.CustomField, error) {
+	field, err := NormalizeCustomField(field)
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.CreateCustomField(field)
+}
+
+// UpdateCustomField changes a custom field's label, options and whether it
+// is required; its key and type stay as they are. Values that are no longer
+// among the options are kept until the company is next edited.
+func (s *Service) UpdateCustomField(id string, field entity.CustomField) (*entity.CustomField, error) {
+	current, err := s.repo.GetCustomField(id)
+	if err != nil {
+		return nil, err
+	}
+	field.Key, field.Type = current.Key, current.Type
+	if field, err = NormalizeCustomField(field); err != nil {
+		return nil, err
+	}
+	return s.repo.UpdateCustomField(id, field)
+}
+
+// DeleteCustomField removes a custom field along with every company's value
+// for it.
+func (s *Service) DeleteCustomField(id string) error {
+	return s.repo.DeleteCustomField(id)
+}
+
+// ListTags returns every tag in use with the number of companies carrying
+// it, alphabetically.
+func (s *Service) ListTags() ([]*entity.TagCount, error) {
+	return s.repo.ListTags()
+}
+
+// normalizeUpdate checks the custom field values and tags an update sets.
+func (s *Service) normalizeUpdate(update *entity.CompanyUpdate) error {
+	if update.CustomFields != nil {
+		fields, err := s.repo.ListCustomFields()
+		if err != nil {
+			return err
+		}
+		values, err := NormalizeCustomValues(fields, *update.CustomFields)
+		if err != nil {
+			return err
+		}
+		update.CustomFields = &values
+	}
+	if update.Tags != nil {
+		tags, err := NormalizeTags(*update.Tags)
+		if err != nil {
+			return err
+		}
+		update.Tags = &tags
+	}
+	return nil
+}
@@ -0,0 +1,142 @@
+package company
+
+import (
+	"backend/companyd/entity"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Follow-up statuses. Only open follow-ups are due, overdue or reminded.
+const (
+	FollowUpOpen      = "open"
+	FollowUpDone      = "done"
+	FollowUpCancelled = "cancelled"
+)
+
+// IsFollowUpStatus reports whether status is a follow-up status.
+func IsFollowUpStatus(status string) bool {
+	return status == FollowUpOpen || status == FollowUpDone || status == FollowUpCancelled
+}
+
+// FollowUpFilter selects follow-ups, ordered by due time. Empty fields do not
+// filter. DueAfter is inclusive and DueBefore exclusive, like the created_
+// and updated_ ranges of ListQuery.
+type FollowUpFilter struct {
+	CompanyID string
+	Officer   string
+	Status    string
+	DueAfter  *time.Time
+	DueBefore *time.Time
+}
+
+// Matches reports whether f passes the filter, for repositories that filter
+// in Go.
+func (filter FollowUpFilter) Matches(f *entity.FollowUp) bool {
+	switch {
+	case filter.CompanyID != "" && f.CompanyID != filter.CompanyID:
+		return false
+	case filter.Officer != "" && f.Officer != filter.Officer:
+		return false
+	case filter.Status != "" && f.Status != filter.Status:
+		return false
+	case filter.DueAfter != nil && f.DueAt.Before(*filter.DueAfter):
+		return false
+	case filter.DueBefore != nil && !f.DueAt.Before(*filter.DueBefore):
+		return false
+	}
+	return true
+}
+
+// ReminderMessage is the notification text sent to the officer of a
+// follow-up that is about to fall due, or already has.
+func ReminderMessage(companyName string, f *entity.FollowUp, now time.Time) string {
+	when := "is due"
+	if f.DueAt.Before(now) {
+		when = "was due"
+	}
+	message := "Follow-up with " + companyName + " " + when + " " + f.DueAt.UTC().Format("2 Jan 2006 15:04 MST")
+	if f.Note != "" {
+		message += ": " + f.Note
+	}
+	return message
+}
+
+var (
+	isoDatePattern     = regexp.MustCompile(`\b(\d{4})-(\d{1,2})-(\d{1,2})\b`)
+	numericDatePattern = regexp.MustCompile(`\b(\d{1,2})[/.\-](\d{1,2})[/.\-](\d{4}|\d{2})\b`)
+	dayMonthPattern    = regexp.MustCompile(`\b(\d{1,2})(?:st|nd|rd|th)?\s+(jan|feb|mar|apr|may|jun|jul|aug|sep|oct|nov|dec)[a-z]*\.?,?\s+(\d{4})\b`)
+	monthDayPattern    = regexp.MustCompile(`\b(jan|feb|mar|apr|may|jun|jul|aug|sep|oct|nov|dec)[a-z]*\.?\s+(\d{1,2})(?:st|nd|rd|th)?,?\s+(\d{4})\b`)
+	monthAbbreviations = []string{"jan", "feb", "mar", "apr", "may", "jun", "jul", "aug", "sep", "oct", "nov", "dec"}
+)
+
+// ParseFollowUpDate finds a calendar date in free text such as "call on
+// 2026-03-14", "14/03/2026" (day first) or "14th March 2026". The date is
+// returned as midnight UTC. It reports false when the text holds no date;
+// relative dates such as "next Tuesday" cannot be resolved without knowing
+// when they were written.
+func ParseFollowUpDate(text string) (time.Time, bool) {
+	t := strings.ToLower(text)
+	if m := isoDatePattern.FindStringSubmatch(t); m != nil {
+		return calendarDate(m[1], m[2], m[3])
+	}
+	if m := numericDatePattern.FindStringSubmatch(t); m != nil {
+		year := m[3]
+		if len(year) == 2 {
+			year = "20" + year
+		}
+		return calendarDate(year, m[2], m[1])
+	}
+	if m := dayMonthPattern.FindStringSubmatch(t); m != nil {
+		return calendarDate(m[3], monthNumber(m[2]), m[1])
+	}
+	if m := monthDayPattern.FindStringSubmatch(t); m != nil {
+		return calendarDate(m[3], monthNumber(m[1]), m[2])
+	}
+	return time.Time{}, false
+}
+
+func monthNumber(abbreviation string) string {
+	for i, m := range monthAbbreviations {
+		if m == abbreviation {
+			return strconv.Itoa(i + 1)
+		}
+	}
+	return "0"
+}
+
+// calendarDate rejects dates such as 31/02 that time.Date would roll over.
+func calendarDate(year, month, day string) (time.Time, bool) {
+	y, _ := strconv.Atoi(year)
+	m, _ := strconv.Atoi(month)
+	d, _ := strconv.Atoi(day)
+	date := time.Date(y, time.Month(m), d, 0, 0, 0, 0, time.UTC)
+	if date.Year() != y || int(date.Month()) != m || date.Day() != d {
+		return time.Time{}, false
+	}
+	return date, true
+}
+
+// ImportFollowUp turns a company's free-text follow_up into an open
+// follow-up for its first assigned officer, due on the date found in the
+// text or else at now so that it shows up as overdue straight away. The text
+// is kept in Note. It reports false for placeholders and for companies
+// without an officer to own the task.
+func ImportFollowUp(c *entity.Company, now time.Time) (entity.FollowUp, bool) {
+	text := strings.TrimSpace(c.FollowUp)
+	if placeholders[strings.ToLower(text)] || len(c.AssignedOfficer) == 0 {
+		return entity.FollowUp{}, false
+	}
+	due, ok := ParseFollowUpDate(text)
+	if !ok {
+		due = now
+	}
+	return entity.FollowUp{
+		CompanyID: c.ID,
+		Officer:   c.AssignedOfficer[0],
+		DueAt:     due,
+		Note:      "Imported from follow_up: " + text,
+		Status:    FollowUpOpen,
+	}, true
+}
@@ -0,0 +1,91 @@
+package company
+
+import (
+	"backend/companyd/entity"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Sources of a company revision.
+const (
+	RevisionCreate   = "create"
+	RevisionEdit     = "edit"
+	RevisionProposal = "proposal"
+	RevisionRevert   = "revert"
+	RevisionMerge    = "merge"
+	RevisionImport   = "import"
+	RevisionBaseline = "baseline"
+	RevisionStatus   = "status"
+	RevisionRollover = "rollover"
+)
+
+// DiffSnapshots lists the fields that differ between before and after, in
+// the order they appear in CompanySnapshot. Custom fields are compared one
+// by one and named like customFields.sector. Snapshots recorded before
+// custom fields and tags existed have none.
+func DiffSnapshots(before, after entity.CompanySnapshot) []entity.FieldChange {
+	if before.Tags == nil {
+		before.Tags = []string{}
+	}
+	if after.Tags == nil {
+		after.Tags = []string{}
+	}
+	changes := []entity.FieldChange{}
+	b, a := reflect.ValueOf(before), reflect.ValueOf(after)
+	for i := 0; i < b.NumField(); i++ {
+		field := strings.Split(b.Type().Field(i).Tag.Get("json"), ",")[0]
+		if field == "customFields" {
+			changes = append(changes, diffCustomValues(before.CustomFields, after.CustomFields)...)
+			continue
+		}
+		bv, av := b.Field(i).Interface(), a.Field(i).Interface()
+		if reflect.DeepEqual(bv, av) {
+			continue
+		}
+		changes = append(changes, entity.FieldChange{Field: field, Before: bv, After: av})
+	}
+	return changes
+}
+
+// diffCustomValues lists the custom fields that differ, by key.
+func diffCustomValues(before, after entity.CustomValues) []entity.FieldChange {
+	var keys []string
+	for key := range before {
+		keys = append(keys, key)
+	}
+	for key := range after {
+		if _, ok := before[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	var changes []entity.FieldChange
+	for _, key := range keys {
+		if !reflect.DeepEqual(before[key], after[key]) {
+			changes = append(changes, entity.FieldChange{Field: "customFields." + key, Before: before[key], After: after[key]})
+		}
+	}
+	return changes
+}
+
+// withChanges fills in Changes on revisions sorted by ascending version.
+func withChanges(revisions []*entity.CompanyRevision) {
+	for i, revision := range revisions {
+		if i == 0 {
+			revision.Changes = []entity.FieldChange{}
+			continue
+		}
+		revision.Changes = DiffSnapshots(revisions[i-1].Snapshot, revision.Snapshot)
+	}
+}
+
+// findRevision returns the revision at version, or nil.
+func findRevision(revisions []*entity.CompanyRevision, version int) *entity.CompanyRevision {
+	for _, revision := range revisions {
+		if revision.Version == version {
+			return revision
+		}
+	}
+	return nil
+}
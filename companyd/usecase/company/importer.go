@@ -0,0 +1,203 @@
+package company
+
+import (
+	"backend/companyd/entity"
+	"fmt"
+	"strings"
+)
+
+// MaxImportRows bounds the number of data rows in one import.
+const MaxImportRows = 5000
+
+// ImportFields are the company fields a spreadsheet column can be mapped to,
+// named as in Company's JSON.
+var ImportFields = []string{
+	"companyName", "companyAddress", "drive", "typeOfDrive", "followUp", "isContacted",
+	"remarks", "contactDetails", "hr1Details", "hr2Details", "package", "assignedOfficer",
+}
+
+// IsImportField reports whether a column can be mapped to field.
+func IsImportField(field string) bool {
+	for _, f := range ImportFields {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
+
+// ImportRow is one data row of a spreadsheet, keyed by import field.
+type ImportRow struct {
+	Line   int
+	Values map[string]string
+}
+
+// ImportOptions controls ImportCompanies.
+type ImportOptions struct {
+	// Commit creates the companies; otherwise the rows are only previewed.
+	Commit bool
+	// SkipDuplicates leaves out rows whose name equals an existing
+	// company's once normalised.
+	SkipDuplicates bool
+	ImportedBy     string
+}
+
+// ValidateImportRow converts a spreadsheet row to company fields. Blank
+// cells leave a field empty. assignedOfficer is a list separated by commas,
+// semicolons or pipes.
+func ValidateImportRow(row ImportRow) *entity.ImportRowResult {
+	result := &entity.ImportRowResult{
+		Line:       row.Line,
+		Errors:     []entity.ImportError{},
+		Warnings:   []string{},
+		Duplicates: []*entity.DuplicateMatch{},
+	}
+	value := func(field string) string {
+		return strings.TrimSpace(row.Values[field])
+	}
+	fail := func(field, message string) {
+		result.Errors = append(result.Errors, entity.ImportError{Field: field, Value: row.Values[field], Message: message})
+	}
+
+	c := &result.Company
+	c.CompanyName = value("companyName")
+	c.CompanyAddress = value("companyAddress")
+	c.Drive = value("drive")
+	c.TypeOfDrive = value("typeOfDrive")
+	c.FollowUp = value("followUp")
+	c.Remarks = value("remarks")
+	c.ContactDetails = value("contactDetails")
+	c.HR1Details = value("hr1Details")
+	c.HR2Details = value("hr2Details")
+	c.Package = value("package")
+	c.AssignedOfficer = []string{}
+
+	if c.CompanyName == "" {
+		fail("companyName", "companyName is required")
+	}
+	if v := value("isContacted"); v != "" {
+		contacted, ok := parseImportBool(v)
+		if !ok {
+			fail("isContacted", "isContacted must be yes or no")
+		}
+		c.IsContacted = contacted
+	}
+	for _, officer := range strings.FieldsFunc(value("assignedOfficer"), func(r rune) bool {
+		return r == ',' || r == ';' || r == '|'
+	}) {
+		if officer = strings.TrimSpace(officer); officer != "" && !containsOfficer(c.AssignedOfficer, officer) {
+			c.AssignedOfficer = append(c.AssignedOfficer, officer)
+		}
+	}
+	c.Compensation = ParseCompensation(c.Package)
+	if c.Compensation.NeedsReview {
+		result.Warnings = append(result.Warnings, fmt.Sprintf("package %q could not be parsed and will be flagged for review", c.Package))
+	}
+	return result
+}
+
+func parseImportBool(v string) (bool, bool) {
+	switch strings.ToLower(v) {
+	case "true", "t", "yes", "y", "1":
+		return true, true
+	case "false", "f", "no", "n", "0":
+		return false, true
+	}
+	return false, false
+}
+
+// ImportCompanies validates rows and looks for duplicates among the active
+// season's companies and earlier rows. Unless opts.Commit is set nothing is
+// written; committed companies join the active season.
+// A commit is all-or-nothing: with any invalid row it creates nothing and
+// returns the report with ErrInvalidImport.
+func (s *Service) ImportCompanies(rows []ImportRow, opts ImportOptions) (*entity.ImportReport, error) {
+	companies, err := s.repo.ListCompanies()
+	if err != nil {
+		return nil, err
+	}
+	season, err := s.ActiveSeason()
+	if err != nil {
+		return nil, err
+	}
+	existing := inSeason(companies, season)
+
+	results := make([]*entity.ImportRowResult, len(rows))
+	var officers []string
+	for i, row := range rows {
+		results[i] = ValidateImportRow(row)
+		officers = append(officers, results[i].Company.AssignedOfficer...)
+	}
+	unknown := map[string]bool{}
+	if len(officers) > 0 {
+		names, err := s.repo.UnknownOfficers(UniqueOfficers(officers))
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range names {
+			unknown[name] = true
+		}
+	}
+
+	report := &entity.ImportReport{Total: len(rows), Rows: []*entity.ImportRowResult{}}
+	firstLine := map[string]int{}
+	for i, row := range rows {
+		result := results[i]
+		for _, officer := range result.Company.AssignedOfficer {
+			if unknown[officer] {
+				result.Errors = append(result.Errors, entity.ImportError{
+					Field:   "assignedOfficer",
+					Value:   row.Values["assignedOfficer"],
+					Message: fmt.Sprintf("%s is not a user", officer),
+				})
+			}
+		}
+		if name := NormalizeCompanyName(result.Company.CompanyName); name != "" {
+			if line, ok := firstLine[name]; ok {
+				result.Errors = append(result.Errors, entity.ImportError{
+					Field:   "companyName",
+					Value:   row.Values["companyName"],
+					Message: fmt.Sprintf("same company as line %d", line),
+				})
+			} else {
+				firstLine[name] = row.Line
+			}
+			result.Duplicates = FindDuplicates(result.Company.CompanyName, existing)
+		}
+		if len(result.Errors) > 0 {
+			report.Invalid++
+		} else if opts.SkipDuplicates && len(result.Duplicates) > 0 && result.Duplicates[0].Similarity == 1 {
+			result.Skipped = true
+			report.Skipped++
+		}
+		report.Rows = append(report.Rows, result)
+	}
+
+	if !opts.Commit {
+		return report, nil
+	}
+	if report.Invalid > 0 {
+		return report, ErrInvalidImport
+	}
+
+	var snapshots []entity.CompanySnapshot
+	var imported []*entity.ImportRowResult
+	for _, result := range report.Rows {
+		if !result.Skipped {
+			snapshots = append(snapshots, result.Company)
+			imported = append(imported, result)
+		}
+	}
+	if len(snapshots) > 0 {
+		created, err := s.repo.ImportCompanies(snapshots, season, opts.ImportedBy)
+		if err != nil {
+			return nil, err
+		}
+		for i, c := range created {
+			imported[i].ID = c.ID
+		}
+	}
+	report.Created = len(snapshots)
+	report.Committed = true
+	return report, nil
+}
@@ -0,0 +1,96 @@
+package company
+
+import (
+	"backend/companyd/entity"
+	"strings"
+	"time"
+)
+
+// Interaction types.
+const (
+	InteractionCall    = "call"
+	InteractionEmail   = "email"
+	InteractionMeeting = "meeting"
+	InteractionVisit   = "visit"
+	InteractionOther   = "other"
+)
+
+// InteractionTypes lists the accepted interaction types.
+var InteractionTypes = []string{InteractionCall, InteractionEmail, InteractionMeeting, InteractionVisit, InteractionOther}
+
+// IsInteractionType reports whether t is an accepted interaction type.
+func IsInteractionType(t string) bool {
+	for _, v := range InteractionTypes {
+		if v == t {
+			return true
+		}
+	}
+	return false
+}
+
+// interactionClockSkew is how far in the future an interaction may be dated,
+// so that clients with slightly fast clocks can log one as it happens.
+const interactionClockSkew = 5 * time.Minute
+
+// InteractionFilter selects interactions. Empty fields do not filter; Since
+// is inclusive and Until exclusive.
+type InteractionFilter struct {
+	CompanyID string
+	Officer   string
+	Type      string
+	Since     *time.Time
+	Until     *time.Time
+}
+
+// Matches reports whether i passes the filter.
+func (f InteractionFilter) Matches(i *entity.Interaction) bool {
+	if f.CompanyID != "" && i.CompanyID != f.CompanyID {
+		return false
+	}
+	if f.Officer != "" && i.Officer != f.Officer {
+		return false
+	}
+	if f.Type != "" && i.Type != f.Type {
+		return false
+	}
+	if f.Since != nil && i.OccurredAt.Before(*f.Since) {
+		return false
+	}
+	if f.Until != nil && !i.OccurredAt.Before(*f.Until) {
+		return false
+	}
+	return true
+}
+
+// IsLaterInteraction reports whether an interaction at t supersedes the
+// company's current last interaction, which it does when it happened at the
+// same time or later. Backdated entries leave the summary alone.
+func IsLaterInteraction(c *entity.Company, t time.Time) bool {
+	return c.LastInteractionAt == nil || !t.Before(*c.LastInteractionAt)
+}
+
+// ImportRemarks turns the free-text remarks of c into the first entry of its
+// timeline, dated when the company was last updated since that is the
+// latest the remarks can have been written. It reports false for empty or
+// placeholder remarks.
+func ImportRemarks(c *entity.Company) (entity.Interaction, bool) {
+	text := strings.TrimSpace(c.Remarks)
+	if placeholders[strings.ToLower(text)] {
+		return entity.Interaction{}, false
+	}
+	occurredAt, err := time.Parse(time.RFC3339Nano, c.UpdatedAt)
+	if err != nil {
+		return entity.Interaction{}, false
+	}
+	officer := ""
+	if len(c.AssignedOfficer) > 0 {
+		officer = c.AssignedOfficer[0]
+	}
+	return entity.Interaction{
+		CompanyID:  c.ID,
+		Type:       InteractionOther,
+		OccurredAt: occurredAt.UTC(),
+		Officer:    officer,
+		Notes:      "Imported from remarks: " + text,
+	}, true
+}
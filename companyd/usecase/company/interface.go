@@ -2,20 +2,126 @@ package company
 
 import (
 	"backend/companyd/entity"
+	"io"
+	"time"
 )
 
 type Repository interface {
-	CreateCompany(companyName, companyAddress, drive, typeOfDrive, followUp, isContacted, remarks, contactDetails, hr1Details, hr2Details, pkg string, assignedOfficer []string) (*entity.Company, error)
+	CreateCompany(companyName, companyAddress, drive, typeOfDrive, followUp, isContacted, remarks, contactDetails, hr1Details, hr2Details, pkg string, assignedOfficer []string, compensation entity.Compensation, customFields entity.CustomValues, tags []string, season string) (*entity.Company, error)
 	ListCompanies() ([]*entity.Company, error)
-	DeleteCompany(id string) error
-	UpdateCompany(id, companyName, companyAddress, drive, typeOfDrive, followUp, isContacted, remarks, contactDetails, hr1Details, hr2Details, pkg string, assignedOfficer []string) (*entity.Company, error)
+	QueryCompanies(query ListQuery) (*CompanyPage, error)
+	SearchCompanies(query SearchQuery) ([]*entity.CompanySearchResult, error)
+	GetCompany(id string) (*entity.Company, error)
+	// ImportCompanies creates every company in season in one transaction,
+	// in order, recording importedBy in their history.
+	ImportCompanies(companies []entity.CompanySnapshot, season, importedBy string) ([]*entity.Company, error)
+	// DeleteCompany moves a company to the trash. Deleting a company that is
+	// missing or already in the trash does nothing.
+	DeleteCompany(id string, deletedBy string) error
+	RestoreCompany(id string) (*entity.Company, error)
+	SetCompanyArchived(id string, archived bool, by string) (*entity.Company, error)
+	// ListDeletedCompanies returns the trash, most recently deleted first.
+	ListDeletedCompanies() ([]*entity.Company, error)
+	// PurgeCompanies permanently deletes companies moved to the trash
+	// before deletedBefore, with everything that belongs to them.
+	PurgeCompanies(deletedBefore time.Time) (int, error)
+	// UpdateCompany applies update and records the new version in the
+	// company's history, attributed as change says.
+	UpdateCompany(id string, version int, update entity.CompanyUpdate, change entity.CompanyChange) (*entity.Company, error)
 	ListCompaniesByUsername(username string) ([]*entity.Company, error)
+	// ListCompanyOfficers returns a company's officer assignments, primary
+	// first.
+	ListCompanyOfficers(companyID string) ([]*entity.OfficerAssignment, error)
+	// UnknownOfficers returns the usernames that are not users, in order.
+	UnknownOfficers(usernames []string) ([]string, error)
+	// ListOfficerUsernames returns the users with the Officer role, who can
+	// be assigned companies automatically, alphabetically.
+	ListOfficerUsernames() ([]string, error)
 	CreateCompanyTemp(companyId, companyName, companyAddress, drive, typeOfDrive, followUp, isContacted, remarks, contactDetails, hr1Details, hr2Details, pkg string, assignedOfficer []string, createdBy string) (*entity.CompanyTemp, error)
-	ListCompanyTemps() ([]*entity.CompanyTemp, error)
-	UpdateCompanyTempStatus(id string, status string) error
-	ApproveCompanyTemp(id string) error
-	CreateEvent(date, eventType, title, description, createdBy string) (*entity.Event, error)
-	ListEvents() ([]*entity.Event, error)
+	// ListCompanyTemps returns the proposals for companies of season, or
+	// every proposal when season is empty, newest first.
+	ListCompanyTemps(season string) ([]*entity.CompanyTemp, error)
+	GetCompanyTemp(id string) (*entity.CompanyTemp, error)
+	// UpdateCompanyTempStatus moves a proposal from status from to status
+	// to. It returns ErrNotFound unless the proposal exists and is still at
+	// from.
+	UpdateCompanyTempStatus(id string, from, to string) error
+	// ApproveCompanyTemp applies a pending proposal to its company, marks it
+	// approved and marks the company's other pending proposals superseded,
+	// all in one transaction. A proposal that is no longer pending returns a
+	// ProposalTransitionError and changes nothing.
+	ApproveCompanyTemp(id string, approvedBy string) error
+	// MergeCompanies applies update to the survivor if it is still at
+	// version, then moves the duplicate's contacts, pending proposals,
+	// follow-ups, notifications, interactions and attachments to the
+	// survivor and deletes the duplicate with its history, all in one
+	// transaction. The duplicate's primary contact is demoted when the
+	// survivor has one, and moved proposals are based on version so they
+	// count as stale.
+	MergeCompanies(survivorID, duplicateID string, version int, update entity.CompanyUpdate, change entity.CompanyChange) (*entity.Company, error)
+	// ListCompanyRevisions returns a company's history, oldest version first.
+	ListCompanyRevisions(companyID string) ([]*entity.CompanyRevision, error)
+	ListStatusTransitions(filter StatusTransitionFilter) ([]*entity.StatusTransition, error)
+	// CreateEvent files the event under the season its date falls in.
+	CreateEvent(date, eventType, title, description, companyID, createdBy string) (*entity.Event, error)
+	// ListEvents returns the events of season, or every event when season
+	// is empty, latest first.
+	ListEvents(season string) ([]*entity.Event, error)
+	CreateContact(contact entity.Contact) (*entity.Contact, error)
+	GetContact(id string) (*entity.Contact, error)
+	ListContacts(filter ContactFilter) ([]*entity.Contact, error)
+	UpdateContact(id string, contact entity.Contact) (*entity.Contact, error)
+	DeleteContact(id string) error
+	CreateDrive(drive entity.Drive) (*entity.Drive, error)
+	GetDrive(id string) (*entity.Drive, error)
+	ListDrives(filter DriveFilter) ([]*entity.Drive, error)
+	UpdateDrive(id string, drive entity.Drive) (*entity.Drive, error)
+	DeleteDrive(id string) error
+	// ListSeasons returns the seasons set active or rolled over into and
+	// those companies belong to, in order, with their company counts.
+	ListSeasons() ([]*entity.Season, error)
+	// SetActiveSeason makes season the only active one.
+	SetActiveSeason(season string) error
+	// RolloverCompanies copies each company into season in one
+	// transaction, with its officers and contacts, at the first stage of
+	// the pipeline and with no follow-up, and returns the copies in order.
+	// The copies are carried from the originals, attributed to by.
+	RolloverCompanies(ids []string, season, by string) ([]*entity.Company, error)
+	// ListCustomFields returns the custom fields, oldest first.
+	ListCustomFields() ([]*entity.CustomField, error)
+	GetCustomField(id string) (*entity.CustomField, error)
+	// CreateCustomField returns ErrFieldExists when the key is taken.
+	CreateCustomField(field entity.CustomField) (*entity.CustomField, error)
+	// UpdateCustomField changes the label, options and required flag.
+	UpdateCustomField(id string, field entity.CustomField) (*entity.CustomField, error)
+	// DeleteCustomField removes the field and every company's value for it
+	// in one transaction.
+	DeleteCustomField(id string) error
+	// ListTags counts the companies outside the trash carrying each tag,
+	// alphabetically.
+	ListTags() ([]*entity.TagCount, error)
+	CreateAttachment(attachment entity.Attachment) (*entity.Attachment, error)
+	GetAttachment(id string) (*entity.Attachment, error)
+	// ListAttachments returns a company's attachments, newest first.
+	ListAttachments(companyID string) ([]*entity.Attachment, error)
+	// ListTrashedAttachments returns the attachments of companies moved to
+	// the trash before deletedBefore, which PurgeCompanies would delete.
+	ListTrashedAttachments(deletedBefore time.Time) ([]*entity.Attachment, error)
+	DeleteAttachment(id string) error
+	CreateFollowUp(followUp entity.FollowUp) (*entity.FollowUp, error)
+	GetFollowUp(id string) (*entity.FollowUp, error)
+	ListFollowUps(filter FollowUpFilter) ([]*entity.FollowUp, error)
+	UpdateFollowUp(id string, followUp entity.FollowUp) (*entity.FollowUp, error)
+	DeleteFollowUp(id string) error
+	// CreateReminders notifies the officers of open follow-ups due before
+	// dueBefore that have not been reminded yet, and marks them reminded.
+	CreateReminders(dueBefore time.Time) ([]*entity.Notification, error)
+	ListNotifications(recipient string, unreadOnly bool) ([]*entity.Notification, error)
+	MarkNotificationRead(id, recipient string) error
+	// CreateInteraction appends to a company's timeline and, unless the
+	// interaction is backdated, updates the company's last interaction.
+	CreateInteraction(interaction entity.Interaction) (*entity.Interaction, error)
+	ListInteractions(filter InteractionFilter) ([]*entity.Interaction, error)
 }
 
 type Writer interface {
@@ -31,17 +137,26 @@ type Writer interface {
 		hr2Details string,
 		pkg string,
 		assignedOfficer []string,
+		compensation *entity.Compensation,
+		customFields entity.CustomValues,
+		tags []string,
 	) (*entity.Company, error)
-	DeleteCompany(id string) error
-	ApproveCompanyTemp(id string, status string) error
-	UpdateCompany(id, companyName, companyAddress, drive, typeOfDrive, followUp, isContacted, remarks, contactDetails, hr1Details, hr2Details, pkg string, assignedOfficer []string) (*entity.Company, error)
-	CreateEvent(date, eventType, title, description, createdBy string) (*entity.Event, error)
+	DeleteCompany(id string, by string) error
+	ApproveCompanyTemp(id string, by string) error
+	UpdateCompany(id string, version int, update entity.CompanyUpdate, by string) (*entity.Company, error)
+	RevertCompany(id string, version, to int, by string) (*entity.Company, error)
+	MergeCompanies(survivorID string, version int, duplicateID, by string) (*entity.Company, error)
+	CreateEvent(date, eventType, title, description, companyID, createdBy string) (*entity.Event, error)
 }
 
 type Reader interface {
 	ListCompanies() ([]*entity.Company, error)
+	QueryCompanies(query ListQuery) (*CompanyPage, error)
+	SearchCompanies(query SearchQuery) ([]*entity.CompanySearchResult, error)
+	PackageStats(query PackageStatsQuery) ([]*entity.PackageStats, error)
+	GetCompany(id string) (*entity.Company, error)
 	ListCompaniesByUsername(username string) ([]*entity.Company, error)
-	ListEvents() ([]*entity.Event, error)
+	ListEvents(season string) ([]*entity.Event, error)
 }
 
 type Usecase interface {
@@ -57,15 +172,86 @@ type Usecase interface {
 		hr2Details string,
 		pkg string,
 		assignedOfficer []string,
+		compensation *entity.Compensation,
+		customFields entity.CustomValues,
+		tags []string,
 	) (*entity.Company, error)
 	ListCompanies() ([]*entity.Company, error)
-	DeleteCompany(id string) error
-	UpdateCompany(id, companyName, companyAddress, drive, typeOfDrive, followUp, isContacted, remarks, contactDetails, hr1Details, hr2Details, pkg string, assignedOfficer []string) (*entity.Company, error)
+	QueryCompanies(query ListQuery) (*CompanyPage, error)
+	SearchCompanies(query SearchQuery) ([]*entity.CompanySearchResult, error)
+	PackageStats(query PackageStatsQuery) ([]*entity.PackageStats, error)
+	GetCompany(id string) (*entity.Company, error)
+	DeleteCompany(id string, by string) error
+	RestoreCompany(id string) (*entity.Company, error)
+	ArchiveCompany(id string, by string) (*entity.Company, error)
+	UnarchiveCompany(id string) (*entity.Company, error)
+	ListDeletedCompanies() ([]*entity.Company, error)
+	PurgeDeletedCompanies(retention time.Duration) (int, error)
+	UpdateCompany(id string, version int, update entity.CompanyUpdate, by string) (*entity.Company, error)
 	ListCompaniesByUsername(username string) ([]*entity.Company, error)
+	OfficerPortfolio(username string, within time.Duration) (*entity.OfficerPortfolio, error)
+	ListCompanyOfficers(companyID string) ([]*entity.OfficerAssignment, error)
+	SuggestOfficers(companyID string) ([]*entity.OfficerSuggestion, error)
+	SuggestOfficersFor(companyName, typeOfDrive string) ([]*entity.OfficerSuggestion, error)
+	AssignOfficer(id string, version int, by string) (*entity.Company, *entity.OfficerSuggestion, error)
+	PlanRebalance() (*entity.RebalancePlan, error)
+	ApplyRebalance(moves []entity.RebalanceMove, by string) ([]*entity.RebalanceResult, error)
 	CreateCompanyTemp(companyId, companyName, companyAddress, drive, typeOfDrive, followUp, isContacted, remarks, contactDetails, hr1Details, hr2Details, pkg string, assignedOfficer []string, createdBy string) (*entity.CompanyTemp, error)
-	ListCompanyTemps() ([]*entity.CompanyTemp, error)
+	ListCompanyTemps(season string) ([]*entity.CompanyTemp, error)
 	UpdateCompanyTempStatus(id string, status string) error
-	ApproveCompanyTemp(id string) error
-	CreateEvent(date, eventType, title, description, createdBy string) (*entity.Event, error)
-	ListEvents() ([]*entity.Event, error)
+	ApproveCompanyTemp(id string, by string) error
+	CompanyHistory(id string) ([]*entity.CompanyRevision, error)
+	DiffCompany(id string, from, to int) ([]entity.FieldChange, error)
+	RevertCompany(id string, version, to int, by string) (*entity.Company, error)
+	FindDuplicates(name, excludeID string) ([]*entity.DuplicateMatch, error)
+	DuplicateReport() ([]*entity.DuplicateGroup, error)
+	MergeCompanies(survivorID string, version int, duplicateID, by string) (*entity.Company, error)
+	ImportCompanies(rows []ImportRow, opts ImportOptions) (*entity.ImportReport, error)
+	CreateEvent(date, eventType, title, description, companyID, createdBy string) (*entity.Event, error)
+	ListEvents(season string) ([]*entity.Event, error)
+	CreateContact(contact entity.Contact) (*entity.Contact, error)
+	GetContact(id string) (*entity.Contact, error)
+	ListContacts(filter ContactFilter) ([]*entity.Contact, error)
+	UpdateContact(id string, contact entity.Contact) (*entity.Contact, error)
+	DeleteContact(id string) error
+	CreateDrive(drive entity.Drive) (*entity.Drive, error)
+	GetDrive(id string) (*entity.Drive, error)
+	ListDrives(filter DriveFilter) ([]*entity.Drive, error)
+	UpdateDrive(id string, drive entity.Drive) (*entity.Drive, error)
+	DeleteDrive(id string) error
+	CreateFollowUp(followUp entity.FollowUp) (*entity.FollowUp, error)
+	GetFollowUp(id string) (*entity.FollowUp, error)
+	ListFollowUps(filter FollowUpFilter) ([]*entity.FollowUp, error)
+	UpdateFollowUp(id string, followUp entity.FollowUp, by string) (*entity.FollowUp, error)
+	CompleteFollowUp(id, by string) (*entity.FollowUp, error)
+	DeleteFollowUp(id string) error
+	DueFollowUps(officer string, within time.Duration) ([]*entity.FollowUp, error)
+	OverdueFollowUps(officer string) ([]*entity.FollowUp, error)
+	SendReminders(lead time.Duration) ([]*entity.Notification, error)
+	ListNotifications(recipient string, unreadOnly bool) ([]*entity.Notification, error)
+	MarkNotificationRead(id, recipient string) error
+	CreateInteraction(interaction entity.Interaction) (*entity.Interaction, error)
+	ListInteractions(filter InteractionFilter) ([]*entity.Interaction, error)
+	Pipeline() *entity.Pipeline
+	// ChangeStatus moves a company to another pipeline stage, recording the
+	// transition.
+	ChangeStatus(id string, version int, status, by string) (*entity.Company, error)
+	StatusHistory(id string) (*entity.PipelineHistory, error)
+	Funnel(season string) (*entity.Funnel, error)
+	ActiveSeason() (string, error)
+	ListSeasons() ([]*entity.Season, error)
+	SetActiveSeason(season string) (*entity.Season, error)
+	RolloverSeason(from, to string, companyIDs []string, by string) (*entity.SeasonRollover, error)
+	CompareSeasons(seasons []string) (*entity.SeasonComparison, error)
+	ListCustomFields() ([]*entity.CustomField, error)
+	CreateCustomField(field entity.CustomField) (*entity.CustomField, error)
+	UpdateCustomField(id string, field entity.CustomField) (*entity.CustomField, error)
+	DeleteCustomField(id string) error
+	ListTags() ([]*entity.TagCount, error)
+	UploadAttachment(upload AttachmentUpload) (*entity.Attachment, error)
+	MaxAttachmentSize() int64
+	GetAttachment(id string) (*entity.Attachment, error)
+	ListAttachments(companyID string) ([]*entity.Attachment, error)
+	OpenAttachment(id string) (*entity.Attachment, io.ReadCloser, error)
+	DeleteAttachment(id string) error
 }
@@ -0,0 +1,255 @@
+package company
+
+import (
+	"backend/companyd/entity"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// ErrInvalidCursor is returned for a cursor that cannot be decoded or that
+// was issued for a different sort order.
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// ListQuery filters, orders and pages the company list. The zero value lists
+// every company in creation order.
+type ListQuery struct {
+	Drive       string
+	TypeOfDrive string
+	IsContacted *bool
+	// PipelineStatus selects companies at one stage of the pipeline.
+	PipelineStatus string
+	// Season selects the companies of one season; empty lists every season.
+	Season  string
+	Officer string
+	// Tags selects the companies carrying every one of them, and Fields
+	// those matching every filter.
+	Tags   []string
+	Fields []FieldFilter
+	// PackageMin and PackageMax bound AnnualCTC, in lakhs per annum of
+	// PackageCurrency. Amounts in different currencies cannot be compared,
+	// so the bounds only match packages in that currency; empty means
+	// DefaultCurrency.
+	PackageMin      *float64
+	PackageMax      *float64
+	PackageCurrency string
+	// PackageNeedsReview selects companies by Compensation.NeedsReview.
+	PackageNeedsReview *bool
+	CreatedAfter       *time.Time
+	CreatedBefore      *time.Time
+	UpdatedAfter       *time.Time
+	UpdatedBefore      *time.Time
+	// LastInteractionBefore also matches companies with no interactions,
+	// so that it finds every company neglected since then.
+	LastInteractionAfter  *time.Time
+	LastInteractionBefore *time.Time
+	// Archived is ArchivedExclude, ArchivedInclude or ArchivedOnly. Deleted
+	// companies are never listed.
+	Archived string
+
+	// Sort is a column accepted by IsSortKey; empty means created_at.
+	Sort string
+	Desc bool
+
+	// Limit caps the page size; 0 returns every match.
+	Limit int
+	After *Cursor
+}
+
+// Values of ListQuery.Archived.
+const (
+	ArchivedExclude = ""
+	ArchivedInclude = "include"
+	ArchivedOnly    = "only"
+)
+
+// IsArchivedFilter reports whether v is a valid ListQuery.Archived.
+func IsArchivedFilter(v string) bool {
+	return v == ArchivedExclude || v == ArchivedInclude || v == ArchivedOnly
+}
+
+// PackageBoundsCurrency is the currency the package bounds of q apply to,
+// or empty when q has none.
+func PackageBoundsCurrency(q ListQuery) string {
+	switch {
+	case q.PackageMin == nil && q.PackageMax == nil:
+		return ""
+	case q.PackageCurrency == "":
+		return DefaultCurrency
+	}
+	return q.PackageCurrency
+}
+
+// MatchesArchived reports whether c is listed under the Archived filter.
+func MatchesArchived(filter string, c *entity.Company) bool {
+	switch filter {
+	case ArchivedInclude:
+		return true
+	case ArchivedOnly:
+		return c.ArchivedAt != nil
+	default:
+		return c.ArchivedAt == nil
+	}
+}
+
+// CompanyPage is one page of a company listing.
+type CompanyPage struct {
+	Companies []*entity.Company
+	// Total counts every company matching the filters, across all pages.
+	Total int
+	// NextCursor is empty on the last page.
+	NextCursor string
+}
+
+// Cursor marks the last row of a page: its sort value and, to break ties,
+// its ID.
+type Cursor struct {
+	Sort  string
+	Desc  bool
+	Value interface{}
+	ID    string
+}
+
+type sortKind int
+
+const (
+	sortText sortKind = iota
+	sortBool
+	sortNumber
+	sortTime
+)
+
+// sortKeys are the columns a listing can be ordered by, named as in the
+// database.
+var sortKeys = map[string]sortKind{
+	"company_name":    sortText,
+	"company_address": sortText,
+	"drive":           sortText,
+	"type_of_drive":   sortText,
+	"follow_up":       sortText,
+	"is_contacted":    sortBool,
+	"remarks":         sortText,
+	"contact_details": sortText,
+	"hr1_details":     sortText,
+	"hr2_details":     sortText,
+	"package":         sortNumber,
+	"created_at":      sortTime,
+	"updated_at":      sortTime,
+	// Companies without interactions sort as the zero time, first in
+	// ascending order.
+	"last_interaction_at": sortTime,
+}
+
+// DefaultSort orders listings by creation time.
+const DefaultSort = "created_at"
+
+// IsSortKey reports whether key names a sortable column.
+func IsSortKey(key string) bool {
+	_, ok := sortKeys[key]
+	return ok
+}
+
+// SortValue returns the value c is ordered by under key. Text sorts treat a
+// missing value as "", and package sorts treat a company without a CTC as -1,
+// matching the COALESCE used by the SQL repositories.
+func SortValue(key string, c *entity.Company) interface{} {
+	switch key {
+	case "company_name":
+		return c.CompanyName
+	case "company_address":
+		return c.CompanyAddress
+	case "drive":
+		return c.Drive
+	case "type_of_drive":
+		return c.TypeOfDrive
+	case "follow_up":
+		return c.FollowUp
+	case "is_contacted":
+		return c.IsContacted
+	case "remarks":
+		return c.Remarks
+	case "contact_details":
+		return c.ContactDetails
+	case "hr1_details":
+		return c.HR1Details
+	case "hr2_details":
+		return c.HR2Details
+	case "package":
+		if amount, ok := AnnualCTC(c.Compensation); ok {
+			return amount
+		}
+		return float64(-1)
+	case "updated_at":
+		t, _ := time.Parse(time.RFC3339Nano, c.UpdatedAt)
+		return t
+	case "last_interaction_at":
+		if c.LastInteractionAt == nil {
+			return time.Time{}
+		}
+		return c.LastInteractionAt.UTC()
+	default:
+		t, _ := time.Parse(time.RFC3339Nano, c.CreatedAt)
+		return t
+	}
+}
+
+type cursorJSON struct {
+	Sort  string          `json:"s"`
+	Desc  bool            `json:"d,omitempty"`
+	Value json.RawMessage `json:"v"`
+	ID    string          `json:"id"`
+}
+
+// EncodeCursor returns an opaque cursor pointing just past last in the order
+// described by q.
+func EncodeCursor(q ListQuery, last *entity.Company) string {
+	sort := q.Sort
+	if sort == "" {
+		sort = DefaultSort
+	}
+	value, _ := json.Marshal(SortValue(sort, last))
+	data, _ := json.Marshal(cursorJSON{Sort: sort, Desc: q.Desc, Value: value, ID: last.ID})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeCursor parses a cursor produced by EncodeCursor, restoring its value
+// to the Go type of the sort column.
+func DecodeCursor(s string) (*Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var raw cursorJSON
+	if err := json.Unmarshal(data, &raw); err != nil || raw.ID == "" {
+		return nil, ErrInvalidCursor
+	}
+	kind, ok := sortKeys[raw.Sort]
+	if !ok {
+		return nil, ErrInvalidCursor
+	}
+
+	cursor := &Cursor{Sort: raw.Sort, Desc: raw.Desc, ID: raw.ID}
+	switch kind {
+	case sortText:
+		var v string
+		err = json.Unmarshal(raw.Value, &v)
+		cursor.Value = v
+	case sortBool:
+		var v bool
+		err = json.Unmarshal(raw.Value, &v)
+		cursor.Value = v
+	case sortNumber:
+		var v float64
+		err = json.Unmarshal(raw.Value, &v)
+		cursor.Value = v
+	case sortTime:
+		var v time.Time
+		err = json.Unmarshal(raw.Value, &v)
+		cursor.Value = v
+	}
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	return cursor, nil
+}
@@ -0,0 +1,73 @@
+package company
+
+import (
+	"backend/companyd/entity"
+	"fmt"
+	"strings"
+)
+
+// Roles of an officer assignment. The first officer of a company is its
+// primary officer.
+const (
+	OfficerPrimary   = "primary"
+	OfficerSecondary = "secondary"
+)
+
+// OfficerUserRole is the role of the users the assignment engine assigns
+// companies to.
+const OfficerUserRole = "Officer"
+
+// MigrationAssigner is the assigned_by of the assignments copied from the
+// assigned_officer array.
+const MigrationAssigner = "migration"
+
+// OfficerRole is the role of the officer at position i of a company's
+// officers.
+func OfficerRole(i int) string {
+	if i == 0 {
+		return OfficerPrimary
+	}
+	return OfficerSecondary
+}
+
+// UniqueOfficers drops repeated usernames, keeping the first of each.
+func UniqueOfficers(usernames []string) []string {
+	unique := make([]string, 0, len(usernames))
+	for _, username := range usernames {
+		if !containsOfficer(unique, username) {
+			unique = append(unique, username)
+		}
+	}
+	return unique
+}
+
+// UnknownOfficersError wraps ErrUnknownOfficer with the usernames that are
+// not users.
+func UnknownOfficersError(usernames []string) error {
+	return fmt.Errorf("%w: %s", ErrUnknownOfficer, strings.Join(usernames, ", "))
+}
+
+// checkOfficers returns an UnknownOfficersError unless every username is a
+// user.
+func (s *Service) checkOfficers(usernames []string) error {
+	if len(usernames) == 0 {
+		return nil
+	}
+	unknown, err := s.repo.UnknownOfficers(usernames)
+	if err != nil {
+		return err
+	}
+	if len(unknown) > 0 {
+		return UnknownOfficersError(unknown)
+	}
+	return nil
+}
+
+// ListCompanyOfficers returns who is assigned to a company, when and by
+// whom.
+func (s *Service) ListCompanyOfficers(companyID string) ([]*entity.OfficerAssignment, error) {
+	if _, err := s.repo.GetCompany(companyID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListCompanyOfficers(companyID)
+}
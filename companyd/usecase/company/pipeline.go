@@ -0,0 +1,310 @@
+package company
+
+import (
+	"backend/companyd/entity"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Pipeline stages of the default pipeline. A configured pipeline must start
+// with StageProspect and include StageContacted, since isContacted and
+// companies created as contacted map onto them.
+const (
+	StageProspect       = "prospect"
+	StageContacted      = "contacted"
+	StageInterested     = "interested"
+	StageJDReceived     = "jd_received"
+	StageDriveScheduled = "drive_scheduled"
+	StageCompleted      = "completed"
+	StageNotInterested  = "not_interested"
+)
+
+// DefaultPipeline moves companies one stage at a time from prospect to
+// completed. A company can stop being interested at any stage before
+// completing, and be approached again later.
+func DefaultPipeline() *entity.Pipeline {
+	return &entity.Pipeline{
+		Stages: []string{StageProspect, StageContacted, StageInterested, StageJDReceived, StageDriveScheduled, StageCompleted},
+		Exits:  []string{StageNotInterested},
+		Transitions: map[string][]string{
+			StageProspect:       {StageContacted, StageNotInterested},
+			StageContacted:      {StageInterested, StageNotInterested},
+			StageInterested:     {StageJDReceived, StageNotInterested},
+			StageJDReceived:     {StageDriveScheduled, StageNotInterested},
+			StageDriveScheduled: {StageCompleted, StageNotInterested},
+			StageCompleted:      {},
+			StageNotInterested:  {StageProspect, StageContacted},
+		},
+	}
+}
+
+// StatusTransitionFilter selects status transitions, oldest first. Empty
+// fields do not filter; Before keeps transitions made before it, and Season
+// those of the companies of that season that are not in the trash.
+type StatusTransitionFilter struct {
+	CompanyID string
+	Season    string
+	Before    *time.Time
+}
+
+var stagePattern = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// NewPipeline checks a configured pipeline. Stages are in funnel order and
+// must start with prospect; exits leave the funnel. Transitions may leave
+// out stages that cannot move on.
+func NewPipeline(stages, exits []string, transitions map[string][]string) (*entity.Pipeline, error) {
+	if len(stages) == 0 || stages[0] != StageProspect {
+		return nil, fmt.Errorf("pipeline: the first stage must be %s", StageProspect)
+	}
+	seen := map[string]bool{}
+	for _, stage := range append(append([]string{}, stages...), exits...) {
+		if !stagePattern.MatchString(stage) {
+			return nil, fmt.Errorf("pipeline: stage %q must be lowercase letters, digits and underscores", stage)
+		}
+		if seen[stage] {
+			return nil, fmt.Errorf("pipeline: stage %q is listed twice", stage)
+		}
+		seen[stage] = true
+	}
+	if !containsStage(stages, StageContacted) {
+		return nil, fmt.Errorf("pipeline: the stages must include %s", StageContacted)
+	}
+
+	pipeline := &entity.Pipeline{
+		Stages:      append([]string{}, stages...),
+		Exits:       append([]string{}, exits...),
+		Transitions: map[string][]string{},
+	}
+	for from, targets := range transitions {
+		if !seen[from] {
+			return nil, fmt.Errorf("pipeline: transitions from unknown stage %q", from)
+		}
+		for _, to := range targets {
+			if !seen[to] {
+				return nil, fmt.Errorf("pipeline: transition from %s to unknown stage %q", from, to)
+			}
+			if to == from {
+				return nil, fmt.Errorf("pipeline: stage %s cannot move to itself", from)
+			}
+		}
+		pipeline.Transitions[from] = append([]string{}, targets...)
+	}
+	for stage := range seen {
+		if pipeline.Transitions[stage] == nil {
+			pipeline.Transitions[stage] = []string{}
+		}
+	}
+	return pipeline, nil
+}
+
+// InitialStage is the stage a new company starts at.
+func InitialStage(contacted bool) string {
+	if contacted {
+		return StageContacted
+	}
+	return StageProspect
+}
+
+// IsContactedStage reports whether a company at stage has been contacted,
+// which is every stage but the first.
+func IsContactedStage(stage string) bool {
+	return stage != StageProspect
+}
+
+func containsStage(stages []string, stage string) bool {
+	for _, s := range stages {
+		if s == stage {
+			return true
+		}
+	}
+	return false
+}
+
+// TransitionError is returned when moving a company to a stage its current
+// stage does not lead to. It wraps ErrInvalidTransition.
+type TransitionError struct {
+	From    string
+	To      string
+	Allowed []string
+}
+
+func (e *TransitionError) Error() string {
+	if len(e.Allowed) == 0 {
+		return fmt.Sprintf("a company cannot move on from %s", e.From)
+	}
+	return fmt.Sprintf("a company cannot move from %s to %s; it can move to %s", e.From, e.To, strings.Join(e.Allowed, ", "))
+}
+
+func (e *TransitionError) Unwrap() error {
+	return ErrInvalidTransition
+}
+
+// Pipeline returns the pipeline companies move through.
+func (s *Service) Pipeline() *entity.Pipeline {
+	return s.pipeline
+}
+
+// isStage reports whether stage is a stage or exit of the pipeline.
+func (s *Service) isStage(stage string) bool {
+	return containsStage(s.pipeline.Stages, stage) || containsStage(s.pipeline.Exits, stage)
+}
+
+// stageRank orders stages by how far along the funnel they are: funnel
+// stages in order, after exits and stages the pipeline no longer has.
+func (s *Service) stageRank(stage string) int {
+	for i, st := range s.pipeline.Stages {
+		if st == stage {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// allowedStages lists the stages a company at from can move to. A company
+// at a stage the pipeline no longer has may move to any stage.
+func (s *Service) allowedStages(from string) []string {
+	if !s.isStage(from) {
+		return append(append([]string{}, s.pipeline.Stages...), s.pipeline.Exits...)
+	}
+	return append([]string{}, s.pipeline.Transitions[from]...)
+}
+
+// ChangeStatus moves a company still at version to another pipeline stage,
+// if its current stage leads there. It returns ErrUnknownStage for a stage
+// the pipeline does not have and a *TransitionError for a move it does not
+// allow.
+func (s *Service) ChangeStatus(id string, version int, status, by string) (*entity.Company, error) {
+	if !s.isStage(status) {
+		return nil, ErrUnknownStage
+	}
+	current, err := s.repo.GetCompany(id)
+	if err != nil {
+		return nil, err
+	}
+	if current.Version != version {
+		return nil, ErrVersionMismatch
+	}
+	if allowed := s.allowedStages(current.PipelineStatus); !containsStage(allowed, status) {
+		return nil, &TransitionError{From: current.PipelineStatus, To: status, Allowed: allowed}
+	}
+	contacted := IsContactedStage(status)
+	update := entity.CompanyUpdate{PipelineStatus: &status, IsContacted: &contacted}
+	company, err := s.repo.UpdateCompany(id, version, update, entity.CompanyChange{ChangedBy: by, Source: RevisionStatus})
+	if err != nil {
+		return nil, err
+	}
+	return company, s.attachContacts(company)
+}
+
+// StatusHistory returns a company's stage, the stages it can move to and
+// its transitions, oldest first.
+func (s *Service) StatusHistory(id string) (*entity.PipelineHistory, error) {
+	current, err := s.repo.GetCompany(id)
+	if err != nil {
+		return nil, err
+	}
+	transitions, err := s.repo.ListStatusTransitions(StatusTransitionFilter{CompanyID: id})
+	if err != nil {
+		return nil, err
+	}
+	return &entity.PipelineHistory{
+		Status:      current.PipelineStatus,
+		Allowed:     s.allowedStages(current.PipelineStatus),
+		Transitions: transitions,
+	}, nil
+}
+
+// Funnel reports how far the companies of season got through the pipeline,
+// for the active season when season is empty. A company belongs to the
+// season it was created in or carried into, however late it changed stage;
+// its transitions only tell which stages it went through. Companies in the
+// trash are left out.
+func (s *Service) Funnel(season string) (*entity.Funnel, error) {
+	if season == "" {
+		active, err := s.ActiveSeason()
+		if err != nil {
+			return nil, err
+		}
+		season = active
+	}
+	from, to := SeasonBounds(season)
+	page, err := s.repo.QueryCompanies(ListQuery{Season: season, Archived: ArchivedInclude})
+	if err != nil {
+		return nil, err
+	}
+	transitions, err := s.repo.ListStatusTransitions(StatusTransitionFilter{Season: season})
+	if err != nil {
+		return nil, err
+	}
+	byCompany := map[string][]*entity.StatusTransition{}
+	for _, t := range transitions {
+		byCompany[t.CompanyID] = append(byCompany[t.CompanyID], t)
+	}
+
+	funnel := &entity.Funnel{Season: season, From: from, To: to, Stages: []entity.FunnelStage{}}
+	index := map[string]int{}
+	for _, stage := range s.pipeline.Stages {
+		index[stage] = len(funnel.Stages)
+		funnel.Stages = append(funnel.Stages, entity.FunnelStage{Stage: stage})
+	}
+	for _, stage := range s.pipeline.Exits {
+		index[stage] = len(funnel.Stages)
+		funnel.Stages = append(funnel.Stages, entity.FunnelStage{Stage: stage, Exit: true})
+	}
+	funnelStages := len(s.pipeline.Stages)
+
+	for _, c := range page.Companies {
+		funnel.Companies++
+
+		history := byCompany[c.ID]
+		sort.SliceStable(history, func(i, j int) bool {
+			return history[i].ChangedAt.Before(history[j].ChangedAt)
+		})
+		furthest := -1
+		exits := map[string]bool{}
+		for _, t := range history {
+			i, ok := index[t.To]
+			if !ok {
+				continue
+			}
+			if i < funnelStages && i > furthest {
+				furthest = i
+			}
+			if i >= funnelStages {
+				exits[t.To] = true
+				if j, ok := index[t.From]; ok && j < funnelStages {
+					funnel.Stages[j].Dropped++
+				}
+			}
+		}
+		// The company's own stage counts even when no transition to it was
+		// recorded.
+		if i, ok := index[c.PipelineStatus]; ok {
+			if i < funnelStages && i > furthest {
+				furthest = i
+			}
+			if i >= funnelStages {
+				exits[c.PipelineStatus] = true
+			}
+			funnel.Stages[i].Current++
+		}
+		for i := 0; i <= furthest; i++ {
+			funnel.Stages[i].Reached++
+		}
+		for stage := range exits {
+			funnel.Stages[index[stage]].Reached++
+		}
+	}
+
+	for i := 1; i < funnelStages; i++ {
+		if previous := funnel.Stages[i-1].Reached; previous > 0 {
+			rate := math.Round(float64(funnel.Stages[i].Reached)/float64(previous)*1000) / 1000
+			funnel.Stages[i].ConversionRate = &rate
+		}
+	}
+	return funnel, nil
+}
@@ -0,0 +1,153 @@
+package company_test
+
+import (
+	"backend/companyd/entity"
+	"backend/companyd/repository/memory"
+	"backend/companyd/usecase/company"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNewPipelineRejectsInvalidConfig(t *testing.T) {
+	stages := []string{"prospect", "contacted", "interested"}
+	cases := []struct {
+		name        string
+		stages      []string
+		exits       []string
+		transitions map[string][]string
+		want        string
+	}{
+		{"no stages", nil, nil, nil, "first stage must be prospect"},
+		{"first stage not prospect", []string{"contacted", "prospect"}, nil, nil, "first stage must be prospect"},
+		{"no contacted", []string{"prospect", "interested"}, nil, nil, "must include contacted"},
+		{"malformed stage", []string{"prospect", "contacted", "JD Received"}, nil, nil, `stage "JD Received" must be lowercase`},
+		{"malformed exit", stages, []string{"not-interested"}, nil, `stage "not-interested" must be lowercase`},
+		{"duplicate stage", []string{"prospect", "contacted", "contacted"}, nil, nil, `stage "contacted" is listed twice`},
+		{"exit also a stage", stages, []string{"interested"}, nil, `stage "interested" is listed twice`},
+		{"transition from unknown stage", stages, nil, map[string][]string{"hired": {"prospect"}}, `transitions from unknown stage "hired"`},
+		{"transition to unknown stage", stages, nil, map[string][]string{"prospect": {"hired"}}, `transition from prospect to unknown stage "hired"`},
+		{"transition to itself", stages, nil, map[string][]string{"contacted": {"contacted"}}, "stage contacted cannot move to itself"},
+	}
+	for _, c := range cases {
+		_, err := company.NewPipeline(c.stages, c.exits, c.transitions)
+		if err == nil || !strings.Contains(err.Error(), c.want) {
+			t.Errorf("%s: err = %v, want one containing %q", c.name, err, c.want)
+		}
+	}
+}
+
+func TestNewPipeline(t *testing.T) {
+	stages := []string{"prospect", "contacted", "placed"}
+	exits := []string{"declined"}
+	pipeline, err := company.NewPipeline(stages, exits, map[string][]string{
+		"prospect":  {"contacted", "declined"},
+		"contacted": {"placed", "declined"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Stages that cannot move on get an empty list rather than none.
+	want := map[string][]string{
+		"prospect":  {"contacted", "declined"},
+		"contacted": {"placed", "declined"},
+		"placed":    {},
+		"declined":  {},
+	}
+	if !reflect.DeepEqual(pipeline.Transitions, want) {
+		t.Errorf("transitions = %v, want %v", pipeline.Transitions, want)
+	}
+	stages[2] = "hired"
+	if pipeline.Stages[2] != "placed" {
+		t.Errorf("pipeline shares its stages with the config")
+	}
+
+	if _, err := company.NewPipeline(company.DefaultPipeline().Stages, company.DefaultPipeline().Exits, company.DefaultPipeline().Transitions); err != nil {
+		t.Errorf("default pipeline: %v", err)
+	}
+}
+
+func TestChangeStatus(t *testing.T) {
+	repo := memory.NewCompanyRepository()
+	service := company.NewServiceWithPipeline(repo, company.DefaultPipeline())
+	created, err := repo.CreateCompany("Infosys", "", "", "", "", "false", "", "", "", "", "", nil, entity.Compensation{}, nil, nil, "2026-27")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created.PipelineStatus != company.StageProspect {
+		t.Fatalf("new company at %q, want prospect", created.PipelineStatus)
+	}
+
+	// Each step starts where the last successful one left the company.
+	steps := []struct {
+		to      string
+		allowed []string // for a forbidden move, the stages it lists instead
+		err     error
+	}{
+		{to: "hired", err: company.ErrUnknownStage},
+		{to: company.StageInterested, allowed: []string{company.StageContacted, company.StageNotInterested}, err: company.ErrInvalidTransition},
+		{to: company.StageContacted},
+		{to: company.StageContacted, allowed: []string{company.StageInterested, company.StageNotInterested}, err: company.ErrInvalidTransition},
+		{to: company.StageCompleted, allowed: []string{company.StageInterested, company.StageNotInterested}, err: company.ErrInvalidTransition},
+		{to: company.StageInterested},
+		{to: company.StageNotInterested},
+		{to: company.StageInterested, allowed: []string{company.StageProspect, company.StageContacted}, err: company.ErrInvalidTransition},
+		{to: company.StageContacted},
+	}
+	current := created
+	for _, step := range steps {
+		from := current.PipelineStatus
+		moved, err := service.ChangeStatus(current.ID, current.Version, step.to, "alice")
+		if step.err == nil {
+			if err != nil {
+				t.Fatalf("%s -> %s: %v", from, step.to, err)
+			}
+			if moved.PipelineStatus != step.to || moved.IsContacted != company.IsContactedStage(step.to) || moved.Version != current.Version+1 {
+				t.Errorf("%s -> %s: company = %+v", from, step.to, moved)
+			}
+			current = moved
+			continue
+		}
+		if !errors.Is(err, step.err) {
+			t.Errorf("%s -> %s: err = %v, want %v", from, step.to, err, step.err)
+			continue
+		}
+		var transition *company.TransitionError
+		if step.allowed != nil && (!errors.As(err, &transition) || transition.From != from || transition.To != step.to || !reflect.DeepEqual(transition.Allowed, step.allowed)) {
+			t.Errorf("%s -> %s: err = %#v, want allowed %v", from, step.to, err, step.allowed)
+		}
+	}
+
+	if _, err := service.ChangeStatus(current.ID, current.Version-1, company.StageInterested, "alice"); !errors.Is(err, company.ErrVersionMismatch) {
+		t.Errorf("stale version: err = %v, want ErrVersionMismatch", err)
+	}
+}
+
+func TestChangeStatusFromRemovedStage(t *testing.T) {
+	repo := memory.NewCompanyRepository()
+	created, err := repo.CreateCompany("Infosys", "", "", "", "", "true", "", "", "", "", "", nil, entity.Compensation{}, nil, nil, "2026-27")
+	if err != nil {
+		t.Fatal(err)
+	}
+	moved, err := company.NewServiceWithPipeline(repo, company.DefaultPipeline()).ChangeStatus(created.ID, created.Version, company.StageInterested, "alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A pipeline without the interested stage lets the company move anywhere.
+	pipeline, err := company.NewPipeline([]string{company.StageProspect, company.StageContacted, company.StageCompleted}, nil, map[string][]string{
+		company.StageProspect:  {company.StageContacted},
+		company.StageContacted: {company.StageCompleted},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	moved, err = company.NewServiceWithPipeline(repo, pipeline).ChangeStatus(moved.ID, moved.Version, company.StageCompleted, "alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if moved.PipelineStatus != company.StageCompleted {
+		t.Errorf("company at %q, want completed", moved.PipelineStatus)
+	}
+}
@@ -0,0 +1,96 @@
+package company
+
+import (
+	"backend/companyd/entity"
+	"sort"
+	"time"
+)
+
+// OfficerPortfolio gathers an officer's active companies of the active
+// season by pipeline stage, with every stage of the pipeline listed even
+// when empty, their overdue follow-ups, the proposals they submitted for
+// the season that are still pending and the events tied to their companies
+// within the next within. It returns ErrNotFound when username is not a
+// user.
+func (s *Service) OfficerPortfolio(username string, within time.Duration) (*entity.OfficerPortfolio, error) {
+	unknown, err := s.repo.UnknownOfficers([]string{username})
+	if err != nil {
+		return nil, err
+	}
+	if len(unknown) > 0 {
+		return nil, ErrNotFound
+	}
+
+	season, err := s.ActiveSeason()
+	if err != nil {
+		return nil, err
+	}
+	companies, err := s.ListCompaniesByUsername(username)
+	if err != nil {
+		return nil, err
+	}
+	companies = inSeason(companies, season)
+	portfolio := &entity.OfficerPortfolio{
+		Officer:          username,
+		Companies:        map[string][]*entity.Company{},
+		PendingProposals: []*entity.CompanyTemp{},
+		UpcomingEvents:   []*entity.Event{},
+	}
+	for _, stage := range append(append([]string{}, s.pipeline.Stages...), s.pipeline.Exits...) {
+		portfolio.Companies[stage] = []*entity.Company{}
+	}
+	ids := map[string]bool{}
+	contacted := 0
+	for _, c := range companies {
+		portfolio.Companies[c.PipelineStatus] = append(portfolio.Companies[c.PipelineStatus], c)
+		ids[c.ID] = true
+		if c.IsContacted {
+			contacted++
+		}
+	}
+
+	if portfolio.OverdueFollowUps, err = s.OverdueFollowUps(username); err != nil {
+		return nil, err
+	}
+	if portfolio.OverdueFollowUps == nil {
+		portfolio.OverdueFollowUps = []*entity.FollowUp{}
+	}
+
+	temps, err := s.repo.ListCompanyTemps(season)
+	if err != nil {
+		return nil, err
+	}
+	for _, temp := range temps {
+		if temp.CreatedBy == username && temp.Status == ProposalPending {
+			portfolio.PendingProposals = append(portfolio.PendingProposals, temp)
+		}
+	}
+
+	events, err := s.repo.ListEvents("")
+	if err != nil {
+		return nil, err
+	}
+	now := s.now()
+	dates := map[*entity.Event]time.Time{}
+	for _, event := range events {
+		date, err := time.Parse(time.RFC3339, event.Date)
+		if err != nil || !ids[event.CompanyID] || date.Before(now) || date.After(now.Add(within)) {
+			continue
+		}
+		dates[event] = date
+		portfolio.UpcomingEvents = append(portfolio.UpcomingEvents, event)
+	}
+	sort.SliceStable(portfolio.UpcomingEvents, func(i, j int) bool {
+		return dates[portfolio.UpcomingEvents[i]].Before(dates[portfolio.UpcomingEvents[j]])
+	})
+
+	portfolio.Stats = entity.PortfolioStats{
+		Companies:        len(companies),
+		Contacted:        contacted,
+		NotContacted:     len(companies) - contacted,
+		OverdueFollowUps: len(portfolio.OverdueFollowUps),
+		PendingProposals: len(portfolio.PendingProposals),
+		UpcomingEvents:   len(portfolio.UpcomingEvents),
+	}
+	return portfolio, nil
+}
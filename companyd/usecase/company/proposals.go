@@ -0,0 +1,126 @@
+package company
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// The statuses of a proposal. Every proposal starts out pending and leaves
+// it exactly once: approving applies it, rejecting or withdrawing drops it,
+// and approving another proposal for the same company supersedes it, since
+// it was based on the company as it was before.
+const (
+	ProposalPending    = "pending"
+	ProposalApproved   = "approved"
+	ProposalRejected   = "rejected"
+	ProposalWithdrawn  = "withdrawn"
+	ProposalSuperseded = "superseded"
+)
+
+// ProposalStatuses lists every status a proposal can have.
+var ProposalStatuses = []string{ProposalPending, ProposalApproved, ProposalRejected, ProposalWithdrawn, ProposalSuperseded}
+
+// The actions that move a pending proposal on, as listed in a proposal's
+// allowed actions. Approving has its own endpoint; rejecting and withdrawing
+// set the status.
+const (
+	ActionApprove  = "approve"
+	ActionReject   = "reject"
+	ActionWithdraw = "withdraw"
+)
+
+// settableStatuses are the statuses a pending proposal can be given
+// directly. It only becomes approved by being approved, and superseded by
+// another proposal being approved.
+var settableStatuses = []string{ProposalRejected, ProposalWithdrawn}
+
+// IsProposalStatus reports whether status is one of ProposalStatuses.
+func IsProposalStatus(status string) bool {
+	return containsString(ProposalStatuses, status)
+}
+
+// ProposalActions returns what can still be done with a proposal at status:
+// everything while it is pending, and nothing once it has left pending.
+func ProposalActions(status string) []string {
+	if status != ProposalPending {
+		return []string{}
+	}
+	return []string{ActionApprove, ActionReject, ActionWithdraw}
+}
+
+// ProposalTransitionError is returned when moving a proposal to a status
+// its current status does not lead to. It wraps
+// ErrInvalidProposalTransition.
+type ProposalTransitionError struct {
+	From    string
+	To      string
+	Allowed []string
+}
+
+// NewProposalTransitionError reports that a proposal at from cannot move to
+// to, listing the actions it allows instead.
+func NewProposalTransitionError(from, to string) *ProposalTransitionError {
+	return &ProposalTransitionError{From: from, To: to, Allowed: ProposalActions(from)}
+}
+
+func (e *ProposalTransitionError) Error() string {
+	switch {
+	case len(e.Allowed) == 0:
+		return fmt.Sprintf("the proposal is already %s; only pending proposals can be approved, rejected or withdrawn", e.From)
+	case e.To == ProposalApproved:
+		return "a proposal becomes approved by approving it, not by setting its status"
+	}
+	return fmt.Sprintf("a %s proposal cannot be set to %s; it can be set to %s", e.From, e.To, strings.Join(settableStatuses, " or "))
+}
+
+func (e *ProposalTransitionError) Unwrap() error {
+	return ErrInvalidProposalTransition
+}
+
+// UpdateCompanyTempStatus rejects or withdraws a pending proposal. It
+// returns ErrUnknownProposalStatus for a status that is not one of
+// ProposalStatuses and a ProposalTransitionError for one the proposal
+// cannot move to, including when someone else moved it first.
+func (s *Service) UpdateCompanyTempStatus(id string, status string) error {
+	status = strings.ToLower(strings.TrimSpace(status))
+	if !IsProposalStatus(status) {
+		return fmt.Errorf("%w: %q; use %s", ErrUnknownProposalStatus, status, strings.Join(settableStatuses, " or "))
+	}
+	temp, err := s.repo.GetCompanyTemp(id)
+	if err != nil {
+		return err
+	}
+	if temp.Status != ProposalPending || !containsString(settableStatuses, status) {
+		return NewProposalTransitionError(temp.Status, status)
+	}
+	err = s.repo.UpdateCompanyTempStatus(id, ProposalPending, status)
+	if errors.Is(err, ErrNotFound) {
+		return s.proposalMoved(id, status)
+	}
+	return err
+}
+
+// ApproveCompanyTemp applies a pending proposal, recording by as its
+// approver, and supersedes the company's other pending proposals. A
+// proposal that is no longer pending returns a ProposalTransitionError.
+func (s *Service) ApproveCompanyTemp(id string, by string) error {
+	temp, err := s.repo.GetCompanyTemp(id)
+	if err != nil {
+		return err
+	}
+	if temp.Status != ProposalPending {
+		return NewProposalTransitionError(temp.Status, ProposalApproved)
+	}
+	return s.repo.ApproveCompanyTemp(id, by)
+}
+
+// proposalMoved explains why a proposal that was pending a moment ago could
+// not be moved to status: it has been moved on or deleted since.
+func (s *Service) proposalMoved(id, status string) error {
+	current, err := s.repo.GetCompanyTemp(id)
+	if err != nil {
+		return err
+	}
+	return NewProposalTransitionError(current.Status, status)
+}
@@ -0,0 +1,45 @@
+package company
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// PurgeScheduler periodically empties the trash of companies deleted longer
+// ago than its retention period. Purging is idempotent, so several servers
+// can run a scheduler against the same database.
+type PurgeScheduler struct {
+	service   Usecase
+	interval  time.Duration
+	retention time.Duration
+}
+
+func NewPurgeScheduler(service Usecase, interval, retention time.Duration) *PurgeScheduler {
+	return &PurgeScheduler{service: service, interval: interval, retention: retention}
+}
+
+// Run purges every interval until ctx is cancelled, starting straight away.
+func (s *PurgeScheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	for {
+		s.purge()
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *PurgeScheduler) purge() {
+	purged, err := s.service.PurgeDeletedCompanies(s.retention)
+	if err != nil {
+		log.Printf("Error purging deleted companies: %v", err)
+		return
+	}
+	if purged > 0 {
+		log.Printf("Purged %d companies from the trash", purged)
+	}
+}
@@ -0,0 +1,47 @@
+package company
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// ReminderScheduler periodically notifies officers of follow-ups that fall
+// due within its lead time. Each follow-up is reminded once, so several
+// servers can run a scheduler against the same database.
+type ReminderScheduler struct {
+	service  Usecase
+	interval time.Duration
+	lead     time.Duration
+}
+
+func NewReminderScheduler(service Usecase, interval, lead time.Duration) *ReminderScheduler {
+	return &ReminderScheduler{service: service, interval: interval, lead: lead}
+}
+
+// Run sends reminders every interval until ctx is cancelled. The first batch
+// goes out straight away, so reminders that came due while the server was
+// down are not held back for a whole interval.
+func (s *ReminderScheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	for {
+		s.sendReminders()
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *ReminderScheduler) sendReminders() {
+	sent, err := s.service.SendReminders(s.lead)
+	if err != nil {
+		log.Printf("Error sending follow-up reminders: %v", err)
+		return
+	}
+	if len(sent) > 0 {
+		log.Printf("Sent %d follow-up reminders", len(sent))
+	}
+}
@@ -0,0 +1,215 @@
+package company
+
+import (
+	"backend/companyd/entity"
+	"errors"
+	"html"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// ErrEmptySearch is returned when a search query has no searchable words.
+var ErrEmptySearch = errors.New("search query has no words")
+
+// SearchQuery describes a company search.
+type SearchQuery struct {
+	Text string
+	// Officer, when set, limits results to companies assigned to that user.
+	Officer string
+	Limit   int
+}
+
+// FuzzyThreshold is the minimum word similarity for a typo-tolerant match. It
+// equals pg_trgm's default word_similarity_threshold.
+const FuzzyThreshold = 0.6
+
+// SearchTerms splits text into lower-case words, the same way the Postgres
+// repository builds its prefix tsquery.
+func SearchTerms(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// searchField is a searchable column with its ts_rank weight.
+type searchField struct {
+	text   string
+	weight float64
+}
+
+// searchFields lists the columns of the search document with the weights
+// Postgres gives to classes A, B and C.
+func searchFields(c *entity.Company) []searchField {
+	return []searchField{
+		{c.CompanyName, 1.0},
+		{c.CompanyAddress, 0.4},
+		{c.Remarks, 0.2},
+		{c.ContactDetails, 0.2},
+		{c.HR1Details, 0.2},
+		{c.HR2Details, 0.2},
+	}
+}
+
+// MatchCompanies searches companies in Go, for repositories without a
+// full-text engine. Every term must prefix-match a word of the document; when
+// nothing matches, it falls back to trigram similarity so that typos still
+// find results. Results are ordered by rank, best first.
+func MatchCompanies(companies []*entity.Company, terms []string, limit int) []*entity.CompanySearchResult {
+	var results []*entity.CompanySearchResult
+	for _, c := range companies {
+		if rank, snippet, ok := fullTextMatch(c, terms); ok {
+			results = append(results, &entity.CompanySearchResult{Company: c, Rank: rank, Snippet: snippet})
+		}
+	}
+	if len(results) == 0 {
+		for _, c := range companies {
+			if similarity := documentSimilarity(c, terms); similarity >= FuzzyThreshold {
+				results = append(results, &entity.CompanySearchResult{Company: c, Rank: similarity, Snippet: html.EscapeString(c.CompanyName), Fuzzy: true})
+			}
+		}
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Rank != results[j].Rank {
+			return results[i].Rank > results[j].Rank
+		}
+		if results[i].Company.CompanyName != results[j].Company.CompanyName {
+			return results[i].Company.CompanyName < results[j].Company.CompanyName
+		}
+		return results[i].Company.ID < results[j].Company.ID
+	})
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+	return results
+}
+
+func fullTextMatch(c *entity.Company, terms []string) (float64, string, bool) {
+	fields := searchFields(c)
+	var rank float64
+	for _, term := range terms {
+		best := 0.0
+		for _, f := range fields {
+			if f.weight > best && containsPrefix(SearchTerms(f.text), term) {
+				best = f.weight
+			}
+		}
+		if best == 0 {
+			return 0, "", false
+		}
+		rank += best
+	}
+
+	var fragments []string
+	for _, f := range fields {
+		if fragment, ok := highlight(f.text, terms); ok && len(fragments) < 2 {
+			fragments = append(fragments, fragment)
+		}
+	}
+	return rank / float64(len(terms)), strings.Join(fragments, " … "), true
+}
+
+func containsPrefix(words []string, term string) bool {
+	for _, w := range words {
+		if strings.HasPrefix(w, term) {
+			return true
+		}
+	}
+	return false
+}
+
+// snippetWords is roughly ts_headline's MaxWords.
+const snippetWords = 20
+
+// highlight wraps words of text that start with one of terms in <mark> tags,
+// trimming long text to a window around the first match.
+func highlight(text string, terms []string) (string, bool) {
+	words := strings.Fields(text)
+	first := -1
+	for i, w := range words {
+		marked := false
+		for _, token := range SearchTerms(w) {
+			if matchesAnyTerm(token, terms) {
+				marked = true
+				break
+			}
+		}
+		words[i] = html.EscapeString(w)
+		if marked {
+			words[i] = "<mark>" + words[i] + "</mark>"
+			if first < 0 {
+				first = i
+			}
+		}
+	}
+	if first < 0 {
+		return "", false
+	}
+
+	start := first - snippetWords/4
+	if start < 0 {
+		start = 0
+	}
+	end := start + snippetWords
+	if end > len(words) {
+		end = len(words)
+	}
+	return strings.Join(words[start:end], " "), true
+}
+
+func matchesAnyTerm(word string, terms []string) bool {
+	for _, term := range terms {
+		if strings.HasPrefix(word, term) {
+			return true
+		}
+	}
+	return false
+}
+
+// documentSimilarity averages, over the query terms, the best trigram word
+// similarity against any word of the document.
+func documentSimilarity(c *entity.Company, terms []string) float64 {
+	var words []string
+	for _, f := range searchFields(c) {
+		words = append(words, SearchTerms(f.text)...)
+	}
+
+	var total float64
+	for _, term := range terms {
+		best := 0.0
+		for _, w := range words {
+			if s := wordSimilarity(term, w); s > best {
+				best = s
+			}
+		}
+		total += best
+	}
+	return total / float64(len(terms))
+}
+
+// wordSimilarity is the share of term's trigrams that also occur in word,
+// using pg_trgm's padding of two spaces before and one after each word.
+func wordSimilarity(term, word string) float64 {
+	termTrigrams := trigrams(term)
+	if len(termTrigrams) == 0 {
+		return 0
+	}
+	wordTrigrams := trigrams(word)
+	shared := 0
+	for t := range termTrigrams {
+		if wordTrigrams[t] {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(termTrigrams))
+}
+
+func trigrams(word string) map[string]bool {
+	padded := []rune("  " + word + " ")
+	set := map[string]bool{}
+	for i := 0; i+3 <= len(padded); i++ {
+		set[string(padded[i:i+3])] = true
+	}
+	return set
+}
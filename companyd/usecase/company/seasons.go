@@ -0,0 +1,278 @@
+package company
+
+import (
+	"backend/companyd/entity"
+	"sort"
+)
+
+// AllSeasons, given as a season filter, lists every season.
+const AllSeasons = "all"
+
+// NextSeason is the season after season.
+func NextSeason(season string) string {
+	_, to := SeasonBounds(season)
+	return SeasonAt(to)
+}
+
+// PreviousSeason is the season before season.
+func PreviousSeason(season string) string {
+	from, _ := SeasonBounds(season)
+	return SeasonAt(from.AddDate(0, 0, -1))
+}
+
+// ActiveSeason is the season set active, or the season under way when none
+// has been.
+func (s *Service) ActiveSeason() (string, error) {
+	seasons, err := s.repo.ListSeasons()
+	if err != nil {
+		return "", err
+	}
+	for _, season := range seasons {
+		if season.Active {
+			return season.Name, nil
+		}
+	}
+	return SeasonAt(s.now()), nil
+}
+
+// ListSeasons returns every season that was set active, rolled over into or
+// has companies, in order. The active season is always listed.
+func (s *Service) ListSeasons() ([]*entity.Season, error) {
+	seasons, err := s.repo.ListSeasons()
+	if err != nil {
+		return nil, err
+	}
+	active := false
+	for _, season := range seasons {
+		active = active || season.Active
+	}
+	if !active {
+		current := SeasonAt(s.now())
+		found := false
+		for _, season := range seasons {
+			if season.Name == current {
+				season.Active, found = true, true
+			}
+		}
+		if !found {
+			seasons = append(seasons, &entity.Season{Name: current, Active: true})
+			sort.Slice(seasons, func(i, j int) bool { return seasons[i].Name < seasons[j].Name })
+		}
+	}
+	for _, season := range seasons {
+		season.From, season.To = SeasonBounds(season.Name)
+	}
+	return seasons, nil
+}
+
+// SetActiveSeason makes season, as read by ParseSeason, the one listings
+// and new companies default to.
+func (s *Service) SetActiveSeason(season string) (*entity.Season, error) {
+	name, ok := ParseSeason(season)
+	if !ok {
+		return nil, ErrInvalidSeason
+	}
+	if err := s.repo.SetActiveSeason(name); err != nil {
+		return nil, err
+	}
+	seasons, err := s.ListSeasons()
+	if err != nil {
+		return nil, err
+	}
+	for _, season := range seasons {
+		if season.Name == name {
+			return season, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+// RolloverSeason carries the companies of one season forward into a later
+// one: each is copied with its details, officers and contacts, and starts
+// the new season at the first stage of the pipeline with no follow-up.
+// companyIDs limits the rollover to some of the season's companies; empty,
+// every company that is not archived is carried. Companies already carried
+// into the new season are skipped, so a rollover can be repeated.
+func (s *Service) RolloverSeason(from, to string, companyIDs []string, by string) (*entity.SeasonRollover, error) {
+	from, okFrom := ParseSeason(from)
+	to, okTo := ParseSeason(to)
+	if !okFrom || !okTo {
+		return nil, ErrInvalidSeason
+	}
+	if to <= from {
+		return nil, ErrInvalidRollover
+	}
+
+	companies, err := s.repo.ListCompanies()
+	if err != nil {
+		return nil, err
+	}
+	carried := map[string]bool{}
+	fromSeason := map[string]*entity.Company{}
+	var order []string
+	for _, c := range companies {
+		switch c.Season {
+		case to:
+			if c.CarriedFrom != "" {
+				carried[c.CarriedFrom] = true
+			}
+		case from:
+			fromSeason[c.ID] = c
+			if len(companyIDs) == 0 && c.ArchivedAt == nil {
+				order = append(order, c.ID)
+			}
+		}
+	}
+	if len(companyIDs) > 0 {
+		for _, id := range companyIDs {
+			if fromSeason[id] == nil {
+				return nil, ErrUnknownCompany
+			}
+			order = append(order, id)
+		}
+	}
+
+	rollover := &entity.SeasonRollover{From: from, To: to, Carried: []*entity.Company{}, Skipped: []string{}}
+	var ids []string
+	for _, id := range order {
+		if carried[id] {
+			rollover.Skipped = append(rollover.Skipped, id)
+			continue
+		}
+		carried[id] = true
+		ids = append(ids, id)
+	}
+	if len(ids) == 0 {
+		return rollover, nil
+	}
+	created, err := s.repo.RolloverCompanies(ids, to, by)
+	if err != nil {
+		return nil, err
+	}
+	rollover.Carried = created
+	return rollover, s.attachContacts(created...)
+}
+
+// CompareSeasons reports on each season, as read by ParseSeason, and how
+// each differs from the one before it.
+func (s *Service) CompareSeasons(seasons []string) (*entity.SeasonComparison, error) {
+	names := make([]string, len(seasons))
+	for i, season := range seasons {
+		name, ok := ParseSeason(season)
+		if !ok {
+			return nil, ErrInvalidSeason
+		}
+		names[i] = name
+	}
+	active, err := s.ActiveSeason()
+	if err != nil {
+		return nil, err
+	}
+
+	companies, err := s.repo.ListCompanies()
+	if err != nil {
+		return nil, err
+	}
+	drives, err := s.repo.ListDrives(DriveFilter{})
+	if err != nil {
+		return nil, err
+	}
+	events, err := s.repo.ListEvents("")
+	if err != nil {
+		return nil, err
+	}
+	temps, err := s.repo.ListCompanyTemps("")
+	if err != nil {
+		return nil, err
+	}
+
+	reports := map[string]*entity.SeasonReport{}
+	packages := map[string][]float64{}
+	for _, name := range names {
+		report := &entity.SeasonReport{Season: name, Active: name == active, Stages: map[string]int{}}
+		for _, stage := range append(append([]string{}, s.pipeline.Stages...), s.pipeline.Exits...) {
+			report.Stages[stage] = 0
+		}
+		reports[name] = report
+	}
+	seasonOf := map[string]string{}
+	for _, c := range companies {
+		seasonOf[c.ID] = c.Season
+		report := reports[c.Season]
+		if report == nil {
+			continue
+		}
+		report.Companies++
+		report.Stages[c.PipelineStatus]++
+		if c.CarriedFrom != "" {
+			report.CarriedForward++
+		}
+		if c.IsContacted {
+			report.Contacted++
+		}
+		// Packages in other currencies cannot share a median with these.
+		if ctc, ok := packageFigure(c.Compensation, PackageStatsQuery{Metric: MetricCTC, Currency: DefaultCurrency}); ok {
+			packages[c.Season] = append(packages[c.Season], ctc)
+		}
+	}
+	for _, d := range drives {
+		if report := reports[d.Season]; report != nil {
+			report.Drives++
+		}
+	}
+	for _, e := range events {
+		if report := reports[e.Season]; report != nil {
+			report.Events++
+		}
+	}
+	for _, t := range temps {
+		if report := reports[t.Season]; report != nil {
+			report.Proposals++
+		}
+	}
+	for name, values := range packages {
+		sort.Float64s(values)
+		median := percentile(values, 0.5)
+		reports[name].MedianPackage = &median
+	}
+
+	comparison := &entity.SeasonComparison{Seasons: []*entity.SeasonReport{}, Changes: []entity.SeasonChange{}}
+	for i, name := range names {
+		report := reports[name]
+		comparison.Seasons = append(comparison.Seasons, report)
+		if i == 0 {
+			continue
+		}
+		previous := reports[names[i-1]]
+		change := entity.SeasonChange{
+			From:      previous.Season,
+			To:        report.Season,
+			Companies: report.Companies - previous.Companies,
+			Contacted: report.Contacted - previous.Contacted,
+			Drives:    report.Drives - previous.Drives,
+			Events:    report.Events - previous.Events,
+		}
+		for _, c := range companies {
+			if c.Season == report.Season && c.CarriedFrom != "" && seasonOf[c.CarriedFrom] == previous.Season {
+				change.Retained++
+			}
+		}
+		if report.MedianPackage != nil && previous.MedianPackage != nil {
+			delta := round2(*report.MedianPackage - *previous.MedianPackage)
+			change.MedianPackage = &delta
+		}
+		comparison.Changes = append(comparison.Changes, change)
+	}
+	return comparison, nil
+}
+
+// inSeason keeps the companies of season.
+func inSeason(companies []*entity.Company, season string) []*entity.Company {
+	kept := []*entity.Company{}
+	for _, c := range companies {
+		if c.Season == season {
+			kept = append(kept, c)
+		}
+	}
+	return kept
+}
@@ -2,14 +2,34 @@ package company
 
 import (
 	"backend/companyd/entity"
+	"errors"
+	"time"
 )
 
 type Service struct {
-	repo Repository
+	repo     Repository
+	pipeline *entity.Pipeline
+	// blobs keeps attachment contents, up to maxAttachmentSize bytes each.
+	blobs             BlobStore
+	maxAttachmentSize int64
+	now               func() time.Time
 }
 
 func NewService(repo Repository) Usecase {
-	return &Service{repo: repo}
+	return NewServiceWithPipeline(repo, DefaultPipeline())
+}
+
+// NewServiceWithPipeline returns a service whose companies move through
+// pipeline, typically one checked by NewPipeline.
+func NewServiceWithPipeline(repo Repository, pipeline *entity.Pipeline) Usecase {
+	return NewServiceWithAttachments(repo, pipeline, nil, 0)
+}
+
+// NewServiceWithAttachments returns a service that keeps company
+// attachments of up to maxSize bytes in blobs. Without blobs, uploading
+// and downloading attachments fail with ErrNoBlobStore.
+func NewServiceWithAttachments(repo Repository, pipeline *entity.Pipeline, blobs BlobStore, maxSize int64) Usecase {
+	return &Service{repo: repo, pipeline: pipeline, blobs: blobs, maxAttachmentSize: maxSize, now: time.Now}
 }
 
 func (s *Service) CreateCompany(companyName,
@@ -24,7 +44,29 @@ func (s *Service) CreateCompany(companyName,
 	hr2Details,
 	pkg string,
 	assignedOfficer []string,
+	compensation *entity.Compensation,
+	customFields entity.CustomValues,
+	tags []string,
 ) (*entity.Company, error) {
+	resolved, err := resolveCompensation(&pkg, compensation)
+	if err != nil {
+		return nil, err
+	}
+	// Required custom fields must be given from the start.
+	if customFields == nil {
+		customFields = entity.CustomValues{}
+	}
+	if tags == nil {
+		tags = []string{}
+	}
+	details := entity.CompanyUpdate{CustomFields: &customFields, Tags: &tags}
+	if err := s.normalizeUpdate(&details); err != nil {
+		return nil, err
+	}
+	season, err := s.ActiveSeason()
+	if err != nil {
+		return nil, err
+	}
 	company, err := s.repo.CreateCompany(companyName,
 		companyAddress,
 		drive,
@@ -36,54 +78,581 @@ func (s *Service) CreateCompany(companyName,
 		hr1Details,
 		hr2Details,
 		pkg,
-		assignedOfficer)
+		assignedOfficer,
+		resolved,
+		*details.CustomFields,
+		*details.Tags,
+		season)
 	if err != nil {
 		return nil, err
 	}
 
+	company.Contacts = []*entity.Contact{}
 	return company, nil
 }
 
-func (s *Service) DeleteCompany(id string) error {
-	return s.repo.DeleteCompany(id)
+// DeleteCompany moves a company to the trash on behalf of by.
+func (s *Service) DeleteCompany(id string, by string) error {
+	return s.repo.DeleteCompany(id, by)
+}
+
+// RestoreCompany takes a company back out of the trash.
+func (s *Service) RestoreCompany(id string) (*entity.Company, error) {
+	company, err := s.repo.RestoreCompany(id)
+	if err != nil {
+		return nil, err
+	}
+	return company, s.attachContacts(company)
+}
+
+// ArchiveCompany marks a company that has stopped recruiting.
+func (s *Service) ArchiveCompany(id string, by string) (*entity.Company, error) {
+	company, err := s.repo.SetCompanyArchived(id, true, by)
+	if err != nil {
+		return nil, err
+	}
+	return company, s.attachContacts(company)
+}
+
+func (s *Service) UnarchiveCompany(id string) (*entity.Company, error) {
+	company, err := s.repo.SetCompanyArchived(id, false, "")
+	if err != nil {
+		return nil, err
+	}
+	return company, s.attachContacts(company)
+}
+
+func (s *Service) ListDeletedCompanies() ([]*entity.Company, error) {
+	companies, err := s.repo.ListDeletedCompanies()
+	if err != nil {
+		return nil, err
+	}
+	return companies, s.attachContacts(companies...)
+}
+
+// PurgeDeletedCompanies permanently deletes companies that have been in the
+// trash for longer than retention, and the contents of their attachments.
+func (s *Service) PurgeDeletedCompanies(retention time.Duration) (int, error) {
+	deletedBefore := s.now().Add(-retention)
+	attachments, err := s.repo.ListTrashedAttachments(deletedBefore)
+	if err != nil {
+		return 0, err
+	}
+	purged, err := s.repo.PurgeCompanies(deletedBefore)
+	if err != nil {
+		return 0, err
+	}
+	s.removePurgedBlobs(attachments)
+	return purged, nil
 }
 
 func (s *Service) ListCompanies() ([]*entity.Company, error) {
-	return s.repo.ListCompanies()
+	companies, err := s.repo.ListCompanies()
+	if err != nil {
+		return nil, err
+	}
+	return companies, s.attachContacts(companies...)
 }
 
-func (s *Service) UpdateCompany(id, companyName, companyAddress, drive, typeOfDrive, followUp, isContacted, remarks, contactDetails, hr1Details, hr2Details, pkg string, assignedOfficer []string) (*entity.Company, error) {
-	company, err := s.repo.UpdateCompany(id, companyName, companyAddress, drive, typeOfDrive, followUp, isContacted, remarks, contactDetails, hr1Details, hr2Details, pkg, assignedOfficer)
+// QueryCompanies lists the companies matching query. Custom field filters
+// naming unknown fields or values the fields do not allow are rejected with
+// ErrInvalidCustomValue.
+func (s *Service) QueryCompanies(query ListQuery) (*CompanyPage, error) {
+	if len(query.Fields) > 0 {
+		fields, err := s.repo.ListCustomFields()
+		if err != nil {
+			return nil, err
+		}
+		if query.Fields, err = resolveFieldFilters(fields, query.Fields); err != nil {
+			return nil, err
+		}
+	}
+	page, err := s.repo.QueryCompanies(query)
 	if err != nil {
 		return nil, err
 	}
-	return company, nil
+	return page, s.attachContacts(page.Companies...)
+}
+
+// PackageStats summarises the packages of the companies selected by query,
+// those of the active season when query names none.
+func (s *Service) PackageStats(query PackageStatsQuery) ([]*entity.PackageStats, error) {
+	season := query.Season
+	switch season {
+	case "":
+		active, err := s.ActiveSeason()
+		if err != nil {
+			return nil, err
+		}
+		season = active
+	case AllSeasons:
+		season = ""
+	}
+	// Archived companies stopped recruiting, but their packages still count.
+	page, err := s.repo.QueryCompanies(ListQuery{Season: season, TypeOfDrive: query.TypeOfDrive, Archived: ArchivedInclude})
+	if err != nil {
+		return nil, err
+	}
+	return SummarisePackages(page.Companies, query), nil
+}
+
+// DefaultSearchLimit caps search results when the caller gives no limit.
+const DefaultSearchLimit = 20
+
+func (s *Service) SearchCompanies(query SearchQuery) ([]*entity.CompanySearchResult, error) {
+	if len(SearchTerms(query.Text)) == 0 {
+		return nil, ErrEmptySearch
+	}
+	if query.Limit <= 0 {
+		query.Limit = DefaultSearchLimit
+	}
+	results, err := s.repo.SearchCompanies(query)
+	if err != nil {
+		return nil, err
+	}
+	companies := make([]*entity.Company, len(results))
+	for i, result := range results {
+		companies[i] = result.Company
+	}
+	return results, s.attachContacts(companies...)
+}
+
+func (s *Service) GetCompany(id string) (*entity.Company, error) {
+	company, err := s.repo.GetCompany(id)
+	if err != nil {
+		return nil, err
+	}
+	return company, s.attachContacts(company)
+}
+
+// UpdateCompany applies a direct edit made by by, which may be empty when
+// the caller is not known.
+func (s *Service) UpdateCompany(id string, version int, update entity.CompanyUpdate, by string) (*entity.Company, error) {
+	if update.Package != nil || update.Compensation != nil {
+		if update.Package == nil {
+			update.Package = new(string)
+		}
+		resolved, err := resolveCompensation(update.Package, update.Compensation)
+		if err != nil {
+			return nil, err
+		}
+		update.Compensation = &resolved
+	}
+	if err := s.normalizeUpdate(&update); err != nil {
+		return nil, err
+	}
+	// The pipeline status only moves through ChangeStatus.
+	update.IsContacted, update.PipelineStatus = nil, nil
+	company, err := s.repo.UpdateCompany(id, version, update, entity.CompanyChange{ChangedBy: by, Source: RevisionEdit})
+	if err != nil {
+		return nil, err
+	}
+	return company, s.attachContacts(company)
+}
+
+// CompanyHistory returns every recorded version of a company, newest first,
+// each with the fields changed from the version before it.
+func (s *Service) CompanyHistory(id string) ([]*entity.CompanyRevision, error) {
+	revisions, err := s.companyRevisions(id)
+	if err != nil {
+		return nil, err
+	}
+	withChanges(revisions)
+	for i, j := 0, len(revisions)-1; i < j; i, j = i+1, j-1 {
+		revisions[i], revisions[j] = revisions[j], revisions[i]
+	}
+	return revisions, nil
+}
+
+// DiffCompany lists the fields that differ between two recorded versions of
+// a company. from may be later than to.
+func (s *Service) DiffCompany(id string, from, to int) ([]entity.FieldChange, error) {
+	revisions, err := s.companyRevisions(id)
+	if err != nil {
+		return nil, err
+	}
+	before, after := findRevision(revisions, from), findRevision(revisions, to)
+	if before == nil || after == nil {
+		return nil, ErrUnknownVersion
+	}
+	return DiffSnapshots(before.Snapshot, after.Snapshot), nil
+}
+
+// RevertCompany restores the fields of a company as they were at version to.
+// The revert is itself a new version, so it can be undone the same way;
+// version is the current one, as for UpdateCompany.
+func (s *Service) RevertCompany(id string, version, to int, by string) (*entity.Company, error) {
+	revisions, err := s.companyRevisions(id)
+	if err != nil {
+		return nil, err
+	}
+	target := findRevision(revisions, to)
+	if target == nil {
+		return nil, ErrUnknownVersion
+	}
+	update := target.Snapshot.Update()
+	// Values of custom fields deleted since are not brought back.
+	fields, err := s.repo.ListCustomFields()
+	if err != nil {
+		return nil, err
+	}
+	for key := range *update.CustomFields {
+		if !hasCustomField(fields, key) {
+			delete(*update.CustomFields, key)
+		}
+	}
+	change := entity.CompanyChange{ChangedBy: by, Source: RevisionRevert, RevertedTo: to}
+	company, err := s.repo.UpdateCompany(id, version, update, change)
+	if err != nil {
+		return nil, err
+	}
+	return company, s.attachContacts(company)
+}
+
+// FindDuplicates lists the companies whose names are likely duplicates of
+// name, leaving out excludeID so that a new company does not match itself.
+func (s *Service) FindDuplicates(name, excludeID string) ([]*entity.DuplicateMatch, error) {
+	companies, err := s.repo.ListCompanies()
+	if err != nil {
+		return nil, err
+	}
+	// Only companies of the same season are duplicates; a company carried
+	// forward shares its name with last season's.
+	season, err := s.ActiveSeason()
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range companies {
+		if c.ID == excludeID {
+			season = c.Season
+		}
+	}
+	candidates := []*entity.Company{}
+	for _, c := range inSeason(companies, season) {
+		if c.ID != excludeID {
+			candidates = append(candidates, c)
+		}
+	}
+	return FindDuplicates(name, candidates), nil
+}
+
+// DuplicateReport groups every company with its likely duplicates in the
+// same season.
+func (s *Service) DuplicateReport() ([]*entity.DuplicateGroup, error) {
+	companies, err := s.repo.ListCompanies()
+	if err != nil {
+		return nil, err
+	}
+	var seasons []string
+	seen := map[string]bool{}
+	for _, c := range companies {
+		if !seen[c.Season] {
+			seen[c.Season] = true
+			seasons = append(seasons, c.Season)
+		}
+	}
+	groups := []*entity.DuplicateGroup{}
+	for _, season := range seasons {
+		groups = append(groups, GroupDuplicates(inSeason(companies, season))...)
+	}
+	return groups, nil
+}
+
+// MergeCompanies folds duplicateID into survivorID, which must still be at
+// version, and deletes the duplicate. See MergeFields for how the fields are
+// combined.
+func (s *Service) MergeCompanies(survivorID string, version int, duplicateID, by string) (*entity.Company, error) {
+	if survivorID == duplicateID {
+		return nil, ErrSelfMerge
+	}
+	survivor, err := s.repo.GetCompany(survivorID)
+	if err != nil {
+		return nil, err
+	}
+	duplicate, err := s.repo.GetCompany(duplicateID)
+	if errors.Is(err, ErrNotFound) {
+		return nil, ErrUnknownCompany
+	}
+	if err != nil {
+		return nil, err
+	}
+	update := MergeFields(survivor, duplicate)
+	// The merged company is as far along the pipeline as either was.
+	if s.stageRank(duplicate.PipelineStatus) > s.stageRank(survivor.PipelineStatus) {
+		contacted := IsContactedStage(duplicate.PipelineStatus)
+		update.PipelineStatus = &duplicate.PipelineStatus
+		update.IsContacted = &contacted
+	}
+	change := entity.CompanyChange{ChangedBy: by, Source: RevisionMerge, MergedFrom: duplicateID}
+	merged, err := s.repo.MergeCompanies(survivorID, duplicateID, version, update, change)
+	if err != nil {
+		return nil, err
+	}
+	return merged, s.attachContacts(merged)
+}
+
+// companyRevisions returns a company's history, oldest first, or
+// ErrNotFound for an unknown company.
+func (s *Service) companyRevisions(id string) ([]*entity.CompanyRevision, error) {
+	revisions, err := s.repo.ListCompanyRevisions(id)
+	if err != nil {
+		return nil, err
+	}
+	if len(revisions) == 0 {
+		if _, err := s.repo.GetCompany(id); err != nil {
+			return nil, err
+		}
+	}
+	return revisions, nil
 }
 
 func (s *Service) ListCompaniesByUsername(username string) ([]*entity.Company, error) {
-	return s.repo.ListCompaniesByUsername(username)
+	companies, err := s.repo.ListCompaniesByUsername(username)
+	if err != nil {
+		return nil, err
+	}
+	return companies, s.attachContacts(companies...)
 }
 
+// CreateCompanyTemp rejects proposals that assign the company to someone
+// who is not a user.
 func (s *Service) CreateCompanyTemp(companyId, companyName, companyAddress, drive, typeOfDrive, followUp, isContacted, remarks, contactDetails, hr1Details, hr2Details, pkg string, assignedOfficer []string, createdBy string) (*entity.CompanyTemp, error) {
+	if err := s.checkOfficers(assignedOfficer); err != nil {
+		return nil, err
+	}
 	return s.repo.CreateCompanyTemp(companyId, companyName, companyAddress, drive, typeOfDrive, followUp, isContacted, remarks, contactDetails, hr1Details, hr2Details, pkg, assignedOfficer, createdBy)
 }
 
-func (s *Service) ListCompanyTemps() ([]*entity.CompanyTemp, error) {
-	return s.repo.ListCompanyTemps()
+// ListCompanyTemps returns the proposals for companies of season, or every
+// proposal when season is empty.
+func (s *Service) ListCompanyTemps(season string) ([]*entity.CompanyTemp, error) {
+	return s.repo.ListCompanyTemps(season)
 }
 
-func (s *Service) UpdateCompanyTempStatus(id string, status string) error {
-	return s.repo.UpdateCompanyTempStatus(id, status)
+// CreateEvent adds a calendar event, tied to a company when companyID is
+// not empty.
+func (s *Service) CreateEvent(date, eventType, title, description, companyID, createdBy string) (*entity.Event, error) {
+	if companyID != "" {
+		if err := s.requireCompany(companyID); err != nil {
+			return nil, err
+		}
+	}
+	return s.repo.CreateEvent(date, eventType, title, description, companyID, createdBy)
 }
 
-func (s *Service) ApproveCompanyTemp(id string) error {
-	return s.repo.ApproveCompanyTemp(id)
+// ListEvents returns the events of season, or every event when season is
+// empty.
+func (s *Service) ListEvents(season string) ([]*entity.Event, error) {
+	return s.repo.ListEvents(season)
 }
 
-func (s *Service) CreateEvent(date, eventType, title, description, createdBy string) (*entity.Event, error) {
-	return s.repo.CreateEvent(date, eventType, title, description, createdBy)
+// attachContacts loads the contacts of companies in one query and sets
+// Contacts on each, primary contact first.
+func (s *Service) attachContacts(companies ...*entity.Company) error {
+	if len(companies) == 0 {
+		return nil
+	}
+	ids := make([]string, len(companies))
+	byCompany := make(map[string][]*entity.Contact, len(companies))
+	for i, company := range companies {
+		ids[i] = company.ID
+	}
+
+	contacts, err := s.repo.ListContacts(ContactFilter{CompanyIDs: ids})
+	if err != nil {
+		return err
+	}
+	for _, contact := range contacts {
+		byCompany[contact.CompanyID] = append(byCompany[contact.CompanyID], contact)
+	}
+	for _, company := range companies {
+		company.Contacts = byCompany[company.ID]
+		if company.Contacts == nil {
+			company.Contacts = []*entity.Contact{}
+		}
+	}
+	return nil
 }
 
-func (s *Service) ListEvents() ([]*entity.Event, error) {
-	return s.repo.ListEvents()
+// CreateContact adds a contact to an existing company. Making it primary
+// demotes the company's previous primary contact.
+func (s *Service) CreateContact(contact entity.Contact) (*entity.Contact, error) {
+	if err := s.requireCompany(contact.CompanyID); err != nil {
+		return nil, err
+	}
+	return s.repo.CreateContact(contact)
+}
+
+func (s *Service) GetContact(id string) (*entity.Contact, error) {
+	return s.repo.GetContact(id)
+}
+
+func (s *Service) ListContacts(filter ContactFilter) ([]*entity.Contact, error) {
+	return s.repo.ListContacts(filter)
+}
+
+// UpdateContact replaces a contact. Changing CompanyID moves the person to
+// another company, for example when they change jobs.
+func (s *Service) UpdateContact(id string, contact entity.Contact) (*entity.Contact, error) {
+	if err := s.requireCompany(contact.CompanyID); err != nil {
+		return nil, err
+	}
+	return s.repo.UpdateContact(id, contact)
+}
+
+// requireCompany reports ErrUnknownCompany when id names no company.
+func (s *Service) requireCompany(id string) error {
+	_, err := s.repo.GetCompany(id)
+	if errors.Is(err, ErrNotFound) {
+		return ErrUnknownCompany
+	}
+	return err
+}
+
+func (s *Service) DeleteContact(id string) error {
+	return s.repo.DeleteContact(id)
+}
+
+// CreateFollowUp schedules a follow-up with an existing company. Without an
+// officer, it goes to the company's first assigned officer.
+func (s *Service) CreateFollowUp(followUp entity.FollowUp) (*entity.FollowUp, error) {
+	found, err := s.repo.GetCompany(followUp.CompanyID)
+	if errors.Is(err, ErrNotFound) {
+		return nil, ErrUnknownCompany
+	}
+	if err != nil {
+		return nil, err
+	}
+	if followUp.Officer == "" {
+		if len(found.AssignedOfficer) == 0 {
+			return nil, ErrNoOfficer
+		}
+		followUp.Officer = found.AssignedOfficer[0]
+	}
+	followUp.Status = FollowUpOpen
+	followUp.CompletedBy, followUp.CompletedAt, followUp.RemindedAt = "", nil, nil
+	return s.repo.CreateFollowUp(followUp)
+}
+
+func (s *Service) GetFollowUp(id string) (*entity.FollowUp, error) {
+	return s.repo.GetFollowUp(id)
+}
+
+func (s *Service) ListFollowUps(filter FollowUpFilter) ([]*entity.FollowUp, error) {
+	return s.repo.ListFollowUps(filter)
+}
+
+// UpdateFollowUp changes the officer, due time, note and status of a
+// follow-up; it stays with its company. Moving the due time re-arms the
+// reminder. Marking it done records by as the person who completed it, and
+// reopening it clears that again.
+func (s *Service) UpdateFollowUp(id string, followUp entity.FollowUp, by string) (*entity.FollowUp, error) {
+	current, err := s.repo.GetFollowUp(id)
+	if err != nil {
+		return nil, err
+	}
+	if followUp.Officer == "" {
+		followUp.Officer = current.Officer
+	}
+	if followUp.Status == "" {
+		followUp.Status = current.Status
+	}
+	followUp.CompanyID = current.CompanyID
+
+	followUp.RemindedAt = current.RemindedAt
+	if !followUp.DueAt.Equal(current.DueAt) {
+		followUp.RemindedAt = nil
+	}
+	switch {
+	case followUp.Status != FollowUpDone:
+		followUp.CompletedBy, followUp.CompletedAt = "", nil
+	case current.Status == FollowUpDone:
+		followUp.CompletedBy, followUp.CompletedAt = current.CompletedBy, current.CompletedAt
+	default:
+		now := s.now().UTC()
+		followUp.CompletedBy, followUp.CompletedAt = by, &now
+	}
+	return s.repo.UpdateFollowUp(id, followUp)
+}
+
+// CompleteFollowUp marks a follow-up done by the given user.
+func (s *Service) CompleteFollowUp(id, by string) (*entity.FollowUp, error) {
+	current, err := s.repo.GetFollowUp(id)
+	if err != nil {
+		return nil, err
+	}
+	followUp := *current
+	followUp.Status = FollowUpDone
+	return s.UpdateFollowUp(id, followUp, by)
+}
+
+func (s *Service) DeleteFollowUp(id string) error {
+	return s.repo.DeleteFollowUp(id)
+}
+
+// DueFollowUps lists the open follow-ups falling due within the given time
+// from now. An empty officer means every officer.
+func (s *Service) DueFollowUps(officer string, within time.Duration) ([]*entity.FollowUp, error) {
+	now := s.now()
+	until := now.Add(within)
+	return s.repo.ListFollowUps(FollowUpFilter{Officer: officer, Status: FollowUpOpen, DueAfter: &now, DueBefore: &until})
+}
+
+// OverdueFollowUps lists the open follow-ups whose due time has passed. An
+// empty officer means every officer.
+func (s *Service) OverdueFollowUps(officer string) ([]*entity.FollowUp, error) {
+	now := s.now()
+	return s.repo.ListFollowUps(FollowUpFilter{Officer: officer, Status: FollowUpOpen, DueBefore: &now})
+}
+
+// SendReminders notifies officers of their open follow-ups that fall due
+// within lead, once per follow-up.
+func (s *Service) SendReminders(lead time.Duration) ([]*entity.Notification, error) {
+	return s.repo.CreateReminders(s.now().Add(lead))
+}
+
+func (s *Service) ListNotifications(recipient string, unreadOnly bool) ([]*entity.Notification, error) {
+	return s.repo.ListNotifications(recipient, unreadOnly)
+}
+
+func (s *Service) MarkNotificationRead(id, recipient string) error {
+	return s.repo.MarkNotificationRead(id, recipient)
+}
+
+// CreateInteraction logs an interaction with an existing company. Without an
+// occurredAt it is logged as happening now.
+func (s *Service) CreateInteraction(interaction entity.Interaction) (*entity.Interaction, error) {
+	if err := s.requireCompany(interaction.CompanyID); err != nil {
+		return nil, err
+	}
+	now := s.now().UTC()
+	if interaction.OccurredAt.IsZero() {
+		interaction.OccurredAt = now
+	}
+	if interaction.OccurredAt.After(now.Add(interactionClockSkew)) {
+		return nil, ErrFutureInteraction
+	}
+	interaction.OccurredAt = interaction.OccurredAt.UTC()
+	return s.repo.CreateInteraction(interaction)
+}
+
+func (s *Service) ListInteractions(filter InteractionFilter) ([]*entity.Interaction, error) {
+	return s.repo.ListInteractions(filter)
+}
+
+// resolveCompensation keeps the package text and its structured form in
+// step. Without a structured package, the text is parsed. With one, it is
+// validated and, when the text is empty, rendered into *pkg.
+func resolveCompensation(pkg *string, compensation *entity.Compensation) (entity.Compensation, error) {
+	if compensation == nil {
+		return ParseCompensation(*pkg), nil
+	}
+	resolved := NormalizeCompensation(*compensation)
+	if err := ValidateCompensation(resolved); err != nil {
+		return entity.Compensation{}, err
+	}
+	if *pkg == "" {
+		*pkg = FormatCompensation(resolved)
+	}
+	return resolved, nil
 }
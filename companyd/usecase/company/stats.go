@@ -0,0 +1,155 @@
+package company
+
+import (
+	"backend/companyd/entity"
+	"math"
+	"sort"
+)
+
+// Figures PackageStats can summarise.
+const (
+	MetricCTC     = "ctc"
+	MetricStipend = "stipend"
+)
+
+// Columns PackageStats can group by.
+const (
+	GroupByTypeOfDrive = "type_of_drive"
+	GroupBySeason      = "season"
+)
+
+// PackageStatsQuery selects the companies to summarise and how to group them.
+// An empty TypeOfDrive does not filter; no GroupBy gives a single overall
+// group.
+type PackageStatsQuery struct {
+	TypeOfDrive string
+	// Season is a season such as 2026-27, AllSeasons for every season, or
+	// empty for the active one.
+	Season  string
+	GroupBy []string
+	// Metric is MetricCTC or MetricStipend; empty means MetricCTC.
+	Metric string
+	// Currency limits the figures to packages in one currency, since they
+	// cannot be compared across currencies. Empty means DefaultCurrency.
+	Currency string
+}
+
+// IsPackageMetric reports whether metric names a figure PackageStats can
+// summarise.
+func IsPackageMetric(metric string) bool {
+	return metric == MetricCTC || metric == MetricStipend
+}
+
+// IsPackageGroup reports whether key names a column PackageStats can group by.
+func IsPackageGroup(key string) bool {
+	return key == GroupByTypeOfDrive || key == GroupBySeason
+}
+
+// SummarisePackages computes the distribution of q.Metric over companies for
+// each group, ordered by type of drive and then season.
+func SummarisePackages(companies []*entity.Company, q PackageStatsQuery) []*entity.PackageStats {
+	if q.Metric == "" {
+		q.Metric = MetricCTC
+	}
+	if q.Currency == "" {
+		q.Currency = DefaultCurrency
+	}
+	var byType, bySeason bool
+	for _, key := range q.GroupBy {
+		byType = byType || key == GroupByTypeOfDrive
+		bySeason = bySeason || key == GroupBySeason
+	}
+
+	type groupKey struct{ typeOfDrive, season string }
+	groups := map[groupKey]*entity.PackageStats{}
+	values := map[groupKey][]float64{}
+	for _, c := range companies {
+		var key groupKey
+		if byType {
+			key.typeOfDrive = c.TypeOfDrive
+		}
+		if bySeason {
+			key.season = c.Season
+		}
+		stats, ok := groups[key]
+		if !ok {
+			stats = &entity.PackageStats{}
+			if byType {
+				stats.TypeOfDrive = &key.typeOfDrive
+			}
+			if bySeason {
+				stats.Season = &key.season
+			}
+			groups[key] = stats
+		}
+
+		if c.Compensation.NeedsReview {
+			stats.NeedsReview++
+		}
+		if value, ok := packageFigure(c.Compensation, q); ok {
+			values[key] = append(values[key], value)
+		}
+	}
+
+	result := make([]*entity.PackageStats, 0, len(groups))
+	for key, stats := range groups {
+		summarise(stats, values[key])
+		result = append(result, stats)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		a, b := result[i], result[j]
+		if byType && *a.TypeOfDrive != *b.TypeOfDrive {
+			return *a.TypeOfDrive < *b.TypeOfDrive
+		}
+		return bySeason && *a.Season < *b.Season
+	})
+	return result
+}
+
+func packageFigure(c entity.Compensation, q PackageStatsQuery) (float64, bool) {
+	if c.Currency != q.Currency {
+		return 0, false
+	}
+	if q.Metric == MetricStipend {
+		if c.Stipend == nil {
+			return 0, false
+		}
+		return *c.Stipend, true
+	}
+	return AnnualCTC(c)
+}
+
+func summarise(stats *entity.PackageStats, values []float64) {
+	stats.Count = len(values)
+	if len(values) == 0 {
+		return
+	}
+	sort.Float64s(values)
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	stats.Mean = round2(sum / float64(len(values)))
+	stats.Median = percentile(values, 0.5)
+	stats.P25 = percentile(values, 0.25)
+	stats.P75 = percentile(values, 0.75)
+	stats.P90 = percentile(values, 0.9)
+	stats.Min = values[0]
+	stats.Max = values[len(values)-1]
+}
+
+// percentile interpolates linearly between the closest ranks of the sorted
+// values, like Postgres's percentile_cont. It is 0 for no values.
+func percentile(sorted []float64, p float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	pos := p * float64(len(sorted)-1)
+	lower := int(math.Floor(pos))
+	upper := int(math.Ceil(pos))
+	return round2(sorted[lower] + (sorted[upper]-sorted[lower])*(pos-float64(lower)))
+}
+
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
@@ -0,0 +1,429 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// Config holds every setting the server needs at startup. Values are
+// resolved in order: built-in defaults, the optional file named by
+// CONFIG_FILE, environment variables and finally `<KEY>_FILE` secrets.
+type Config struct {
+	Server      ServerConfig     `yaml:"server" toml:"server"`
+	Database    DatabaseConfig   `yaml:"database" toml:"database"`
+	CORS        CORSConfig       `yaml:"cors" toml:"cors"`
+	Reminders   ReminderConfig   `yaml:"reminders" toml:"reminders"`
+	Trash       TrashConfig      `yaml:"trash" toml:"trash"`
+	Pipeline    PipelineConfig   `yaml:"pipeline" toml:"pipeline"`
+	Attachments AttachmentConfig `yaml:"attachments" toml:"attachments"`
+}
+
+type ServerConfig struct {
+	Host string `yaml:"host" toml:"host"`
+	Port int    `yaml:"port" toml:"port"`
+}
+
+type DatabaseConfig struct {
+	Driver          string        `yaml:"driver" toml:"driver"`
+	Path            string        `yaml:"path" toml:"path"`
+	Host            string        `yaml:"host" toml:"host"`
+	Port            int           `yaml:"port" toml:"port"`
+	User            string        `yaml:"user" toml:"user"`
+	Password        string        `yaml:"password" toml:"password"`
+	Name            string        `yaml:"name" toml:"name"`
+	SSLMode         string        `yaml:"sslmode" toml:"sslmode"`
+	SSLRootCert     string        `yaml:"sslrootcert" toml:"sslrootcert"`
+	SSLCert         string        `yaml:"sslcert" toml:"sslcert"`
+	SSLKey          string        `yaml:"sslkey" toml:"sslkey"`
+	MaxOpenConns    int           `yaml:"max_open_conns" toml:"max_open_conns"`
+	MaxIdleConns    int           `yaml:"max_idle_conns" toml:"max_idle_conns"`
+	ConnMaxLifetime time.Duration `yaml:"conn_max_lifetime" toml:"conn_max_lifetime"`
+	ConnMaxIdleTime time.Duration `yaml:"conn_max_idle_time" toml:"conn_max_idle_time"`
+	ConnectAttempts int           `yaml:"connect_attempts" toml:"connect_attempts"`
+}
+
+type CORSConfig struct {
+	AllowedOrigins []string `yaml:"allowed_origins" toml:"allowed_origins"`
+}
+
+// ReminderConfig controls the follow-up reminder scheduler. An Interval of
+// zero disables it.
+type ReminderConfig struct {
+	Interval time.Duration `yaml:"interval" toml:"interval"`
+	Lead     time.Duration `yaml:"lead" toml:"lead"`
+}
+
+// TrashConfig controls how long deleted companies stay restorable. A
+// PurgeInterval of zero disables purging.
+type TrashConfig struct {
+	Retention     time.Duration `yaml:"retention" toml:"retention"`
+	PurgeInterval time.Duration `yaml:"purge_interval" toml:"purge_interval"`
+}
+
+// PipelineConfig replaces the default recruitment pipeline. It can only be
+// set in the configuration file; without Stages the default pipeline is
+// used.
+type PipelineConfig struct {
+	Stages      []string            `yaml:"stages" toml:"stages"`
+	Exits       []string            `yaml:"exits" toml:"exits"`
+	Transitions map[string][]string `yaml:"transitions" toml:"transitions"`
+}
+
+// AttachmentConfig says where company attachments are kept and how large
+// they may be. Storage is the blob store to use; only the local filesystem,
+// under Dir, is built in.
+type AttachmentConfig struct {
+	Storage   string `yaml:"storage" toml:"storage"`
+	Dir       string `yaml:"dir" toml:"dir"`
+	MaxSizeMB int    `yaml:"max_size_mb" toml:"max_size_mb"`
+}
+
+// MaxSize is the attachment size limit in bytes.
+func (a AttachmentConfig) MaxSize() int64 {
+	return int64(a.MaxSizeMB) << 20
+}
+
+const (
+	DriverPostgres = "postgres"
+	DriverSQLite   = "sqlite"
+)
+
+var validDrivers = []string{DriverPostgres, DriverSQLite}
+
+const StorageLocal = "local"
+
+var validStorages = []string{StorageLocal}
+
+var validSSLModes = []string{"disable", "allow", "prefer", "require", "verify-ca", "verify-full"}
+
+// Default returns the configuration used when nothing else is provided.
+func Default() *Config {
+	return &Config{
+		Server: ServerConfig{
+			Host: "0.0.0.0",
+			Port: 8080,
+		},
+		Database: DatabaseConfig{
+			Driver:          DriverPostgres,
+			Path:            "placement.db",
+			Host:            "localhost",
+			Port:            5432,
+			User:            "myuser",
+			Password:        "mypassword",
+			Name:            "myapp",
+			SSLMode:         "disable",
+			MaxOpenConns:    25,
+			MaxIdleConns:    5,
+			ConnMaxLifetime: 30 * time.Minute,
+			ConnMaxIdleTime: 5 * time.Minute,
+			ConnectAttempts: 10,
+		},
+		CORS: CORSConfig{
+			AllowedOrigins: []string{
+				"https://0f22-2402-3a80-1325-cd70-dd05-94a2-213-dd84.ngrok-free.app",
+				"https://place-pro-platform-88.vercel.app",
+				"https://localhost:8081",
+				"http://localhost:8081",
+			},
+		},
+		Reminders: ReminderConfig{
+			Interval: 5 * time.Minute,
+			Lead:     24 * time.Hour,
+		},
+		Trash: TrashConfig{
+			Retention:     30 * 24 * time.Hour,
+			PurgeInterval: time.Hour,
+		},
+		Attachments: AttachmentConfig{
+			Storage:   StorageLocal,
+			Dir:       "attachments",
+			MaxSizeMB: 10,
+		},
+	}
+}
+
+// Load builds the configuration from the process environment and validates it.
+func Load() (*Config, error) {
+	return load(os.LookupEnv)
+}
+
+func load(lookup func(string) (string, bool)) (*Config, error) {
+	cfg := Default()
+
+	if path, ok := lookup("CONFIG_FILE"); ok && path != "" {
+		if err := cfg.loadFile(path); err != nil {
+			return nil, err
+		}
+	}
+
+	env := envReader{lookup: lookup}
+	env.str("SERVER_HOST", &cfg.Server.Host)
+	env.int("PORT", &cfg.Server.Port)
+
+	env.str("DB_DRIVER", &cfg.Database.Driver)
+	env.str("DB_PATH", &cfg.Database.Path)
+	env.str("DB_HOST", &cfg.Database.Host)
+	env.int("DB_PORT", &cfg.Database.Port)
+	env.str("DB_USER", &cfg.Database.User)
+	env.str("DB_PASSWORD", &cfg.Database.Password)
+	env.str("DB_NAME", &cfg.Database.Name)
+	env.str("DB_SSLMODE", &cfg.Database.SSLMode)
+	env.str("DB_SSLROOTCERT", &cfg.Database.SSLRootCert)
+	env.str("DB_SSLCERT", &cfg.Database.SSLCert)
+	env.str("DB_SSLKEY", &cfg.Database.SSLKey)
+	env.int("DB_MAX_OPEN_CONNS", &cfg.Database.MaxOpenConns)
+	env.int("DB_MAX_IDLE_CONNS", &cfg.Database.MaxIdleConns)
+	env.duration("DB_CONN_MAX_LIFETIME", &cfg.Database.ConnMaxLifetime)
+	env.duration("DB_CONN_MAX_IDLE_TIME", &cfg.Database.ConnMaxIdleTime)
+	env.int("DB_CONNECT_ATTEMPTS", &cfg.Database.ConnectAttempts)
+
+	env.list("CORS_ALLOWED_ORIGINS", &cfg.CORS.AllowedOrigins)
+
+	env.duration("FOLLOWUP_REMINDER_INTERVAL", &cfg.Reminders.Interval)
+	env.duration("FOLLOWUP_REMINDER_LEAD", &cfg.Reminders.Lead)
+
+	env.duration("TRASH_RETENTION", &cfg.Trash.Retention)
+	env.duration("TRASH_PURGE_INTERVAL", &cfg.Trash.PurgeInterval)
+
+	env.str("ATTACHMENT_STORAGE", &cfg.Attachments.Storage)
+	env.str("ATTACHMENT_DIR", &cfg.Attachments.Dir)
+	env.int("ATTACHMENT_MAX_SIZE_MB", &cfg.Attachments.MaxSizeMB)
+
+	// Settings that did not parse keep their earlier value, so validating
+	// anyway reports the remaining problems alongside them.
+	if errs := append(env.errs, cfg.validate()...); len(errs) > 0 {
+		return nil, fmt.Errorf("invalid configuration:\n  %s", joinErrors(errs))
+	}
+	return cfg, nil
+}
+
+func (c *Config) loadFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("reading config file: %w", err)
+	}
+
+	// Decoding on top of the defaults keeps any key the file omits.
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, c)
+	case ".toml":
+		_, err = toml.Decode(string(data), c)
+	default:
+		return fmt.Errorf("config file %s: unsupported extension, use .yaml, .yml or .toml", path)
+	}
+	if err != nil {
+		return fmt.Errorf("parsing config file %s: %w", path, err)
+	}
+	return nil
+}
+
+// Validate reports every invalid setting at once so operators can fix the
+// configuration in a single pass.
+func (c *Config) Validate() error {
+	if errs := c.validate(); len(errs) > 0 {
+		return fmt.Errorf("invalid configuration:\n  %s", joinErrors(errs))
+	}
+	return nil
+}
+
+func (c *Config) validate() []error {
+	var errs []error
+
+	if c.Server.Port < 1 || c.Server.Port > 65535 {
+		errs = append(errs, fmt.Errorf("PORT must be between 1 and 65535, got %d", c.Server.Port))
+	}
+
+	db := c.Database
+	switch db.Driver {
+	case DriverPostgres:
+		errs = append(errs, db.validatePostgres()...)
+	case DriverSQLite:
+		if db.Path == "" {
+			errs = append(errs, errors.New("DB_PATH is required when DB_DRIVER is sqlite"))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("DB_DRIVER must be one of %s, got %q", strings.Join(validDrivers, ", "), db.Driver))
+	}
+	if db.MaxOpenConns < 0 {
+		errs = append(errs, fmt.Errorf("DB_MAX_OPEN_CONNS must not be negative, got %d", db.MaxOpenConns))
+	}
+	if db.MaxIdleConns < 0 {
+		errs = append(errs, fmt.Errorf("DB_MAX_IDLE_CONNS must not be negative, got %d", db.MaxIdleConns))
+	}
+	if db.MaxOpenConns > 0 && db.MaxIdleConns > db.MaxOpenConns {
+		errs = append(errs, fmt.Errorf("DB_MAX_IDLE_CONNS (%d) must not exceed DB_MAX_OPEN_CONNS (%d)", db.MaxIdleConns, db.MaxOpenConns))
+	}
+	if db.ConnMaxLifetime < 0 {
+		errs = append(errs, errors.New("DB_CONN_MAX_LIFETIME must not be negative"))
+	}
+	if db.ConnMaxIdleTime < 0 {
+		errs = append(errs, errors.New("DB_CONN_MAX_IDLE_TIME must not be negative"))
+	}
+	if db.ConnectAttempts < 1 {
+		errs = append(errs, fmt.Errorf("DB_CONNECT_ATTEMPTS must be at least 1, got %d", db.ConnectAttempts))
+	}
+
+	for _, origin := range c.CORS.AllowedOrigins {
+		if !strings.HasPrefix(origin, "http://") && !strings.HasPrefix(origin, "https://") {
+			errs = append(errs, fmt.Errorf("CORS_ALLOWED_ORIGINS entry %q must start with http:// or https://", origin))
+		}
+	}
+
+	if c.Reminders.Interval < 0 {
+		errs = append(errs, errors.New("FOLLOWUP_REMINDER_INTERVAL must not be negative"))
+	}
+	if c.Reminders.Lead < 0 {
+		errs = append(errs, errors.New("FOLLOWUP_REMINDER_LEAD must not be negative"))
+	}
+	if c.Trash.Retention < 0 {
+		errs = append(errs, errors.New("TRASH_RETENTION must not be negative"))
+	}
+	if c.Trash.PurgeInterval < 0 {
+		errs = append(errs, errors.New("TRASH_PURGE_INTERVAL must not be negative"))
+	}
+
+	if !contains(validStorages, c.Attachments.Storage) {
+		errs = append(errs, fmt.Errorf("ATTACHMENT_STORAGE must be one of %s, got %q", strings.Join(validStorages, ", "), c.Attachments.Storage))
+	}
+	if c.Attachments.Storage == StorageLocal && c.Attachments.Dir == "" {
+		errs = append(errs, errors.New("ATTACHMENT_DIR is required when ATTACHMENT_STORAGE is local"))
+	}
+	if c.Attachments.MaxSizeMB < 1 {
+		errs = append(errs, fmt.Errorf("ATTACHMENT_MAX_SIZE_MB must be at least 1, got %d", c.Attachments.MaxSizeMB))
+	}
+	return errs
+}
+
+func (d DatabaseConfig) validatePostgres() []error {
+	var errs []error
+	if d.Host == "" {
+		errs = append(errs, errors.New("DB_HOST is required"))
+	}
+	if d.Port < 1 || d.Port > 65535 {
+		errs = append(errs, fmt.Errorf("DB_PORT must be between 1 and 65535, got %d", d.Port))
+	}
+	if d.User == "" {
+		errs = append(errs, errors.New("DB_USER is required"))
+	}
+	if d.Name == "" {
+		errs = append(errs, errors.New("DB_NAME is required"))
+	}
+	if !contains(validSSLModes, d.SSLMode) {
+		errs = append(errs, fmt.Errorf("DB_SSLMODE must be one of %s, got %q", strings.Join(validSSLModes, ", "), d.SSLMode))
+	}
+	if (d.SSLMode == "verify-ca" || d.SSLMode == "verify-full") && d.SSLRootCert == "" {
+		errs = append(errs, fmt.Errorf("DB_SSLROOTCERT is required when DB_SSLMODE is %s", d.SSLMode))
+	}
+	if (d.SSLCert == "") != (d.SSLKey == "") {
+		errs = append(errs, errors.New("DB_SSLCERT and DB_SSLKEY must be set together"))
+	}
+	return errs
+}
+
+// DSN returns the connection string for the configured driver.
+func (d DatabaseConfig) DSN() string {
+	if d.Driver == DriverSQLite {
+		// Foreign keys are off by default in SQLite; the busy timeout lets
+		// concurrent requests wait for the single writer instead of failing.
+		return "file:" + d.Path + "?_foreign_keys=on&_busy_timeout=5000&_journal_mode=WAL"
+	}
+
+	parts := []string{
+		"host=" + quoteDSN(d.Host),
+		fmt.Sprintf("port=%d", d.Port),
+		"user=" + quoteDSN(d.User),
+		"password=" + quoteDSN(d.Password),
+		"dbname=" + quoteDSN(d.Name),
+		"sslmode=" + quoteDSN(d.SSLMode),
+	}
+	if d.SSLRootCert != "" {
+		parts = append(parts, "sslrootcert="+quoteDSN(d.SSLRootCert))
+	}
+	if d.SSLCert != "" {
+		parts = append(parts, "sslcert="+quoteDSN(d.SSLCert))
+	}
+	if d.SSLKey != "" {
+		parts = append(parts, "sslkey="+quoteDSN(d.SSLKey))
+	}
+	return strings.Join(parts, " ")
+}
+
+// Addr returns the host:port the HTTP server listens on.
+func (s ServerConfig) Addr() string {
+	return fmt.Sprintf("%s:%d", s.Host, s.Port)
+}
+
+// String prints the effective configuration with secrets redacted.
+func (c *Config) String() string {
+	var b strings.Builder
+	db := c.Database
+	fmt.Fprintf(&b, "server.addr=%s\n", c.Server.Addr())
+	fmt.Fprintf(&b, "database.driver=%s\n", db.Driver)
+	if db.Driver == DriverSQLite {
+		fmt.Fprintf(&b, "database.path=%s\n", db.Path)
+	}
+	fmt.Fprintf(&b, "database.host=%s\n", db.Host)
+	fmt.Fprintf(&b, "database.port=%d\n", db.Port)
+	fmt.Fprintf(&b, "database.user=%s\n", db.User)
+	fmt.Fprintf(&b, "database.password=%s\n", redact(db.Password))
+	fmt.Fprintf(&b, "database.name=%s\n", db.Name)
+	fmt.Fprintf(&b, "database.sslmode=%s\n", db.SSLMode)
+	fmt.Fprintf(&b, "database.sslrootcert=%s\n", db.SSLRootCert)
+	fmt.Fprintf(&b, "database.sslcert=%s\n", db.SSLCert)
+	fmt.Fprintf(&b, "database.sslkey=%s\n", redact(db.SSLKey))
+	fmt.Fprintf(&b, "database.max_open_conns=%d\n", db.MaxOpenConns)
+	fmt.Fprintf(&b, "database.max_idle_conns=%d\n", db.MaxIdleConns)
+	fmt.Fprintf(&b, "database.conn_max_lifetime=%s\n", db.ConnMaxLifetime)
+	fmt.Fprintf(&b, "database.conn_max_idle_time=%s\n", db.ConnMaxIdleTime)
+	fmt.Fprintf(&b, "database.connect_attempts=%d\n", db.ConnectAttempts)
+	fmt.Fprintf(&b, "cors.allowed_origins=%s\n", strings.Join(c.CORS.AllowedOrigins, ","))
+	fmt.Fprintf(&b, "reminders.interval=%s\n", c.Reminders.Interval)
+	fmt.Fprintf(&b, "reminders.lead=%s\n", c.Reminders.Lead)
+	fmt.Fprintf(&b, "trash.retention=%s\n", c.Trash.Retention)
+	fmt.Fprintf(&b, "trash.purge_interval=%s\n", c.Trash.PurgeInterval)
+	fmt.Fprintf(&b, "attachments.storage=%s\n", c.Attachments.Storage)
+	fmt.Fprintf(&b, "attachments.dir=%s\n", c.Attachments.Dir)
+	fmt.Fprintf(&b, "attachments.max_size_mb=%d", c.Attachments.MaxSizeMB)
+	return b.String()
+}
+
+func redact(secret string) string {
+	if secret == "" {
+		return ""
+	}
+	return "********"
+}
+
+func quoteDSN(value string) string {
+	if value != "" && !strings.ContainsAny(value, ` '\`) {
+		return value
+	}
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	value = strings.ReplaceAll(value, `'`, `\'`)
+	return "'" + value + "'"
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func joinErrors(errs []error) string {
+	msgs := make([]string, len(errs))
+	for i, err := range errs {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "\n  ")
+}
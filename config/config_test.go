@@ -0,0 +1,301 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// env is a fake environment for load.
+type env map[string]string
+
+func (e env) lookup(key string) (string, bool) {
+	value, ok := e[key]
+	return value, ok
+}
+
+// writeFile writes content to name in a temporary directory and returns its
+// path.
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadDefaults(t *testing.T) {
+	cfg, err := load(env{}.lookup)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(cfg, Default()) {
+		t.Errorf("load without settings = %+v, want the defaults", cfg)
+	}
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	files := []struct{ name, content string }{
+		{"config.yaml", "server:\n  port: 9000\ndatabase:\n  host: db.internal\n  user: file\n  password: from-file\n"},
+		{"config.yml", "server:\n  port: 9000\ndatabase:\n  host: db.internal\n  user: file\n  password: from-file\n"},
+		{"config.toml", "[server]\nport = 9000\n\n[database]\nhost = \"db.internal\"\nuser = \"file\"\npassword = \"from-file\"\n"},
+	}
+	for _, f := range files {
+		cfg, err := load(env{
+			"CONFIG_FILE":      writeFile(t, f.name, f.content),
+			"DB_USER":          "env",
+			"DB_NAME":          "",
+			"DB_PASSWORD_FILE": writeFile(t, "db_password", "from-secret\n"),
+		}.lookup)
+		if err != nil {
+			t.Errorf("%s: %v", f.name, err)
+			continue
+		}
+		db := cfg.Database
+		// The file overrides the defaults, the environment the file, and a
+		// secret the file too. Empty variables are ignored.
+		if cfg.Server.Port != 9000 || db.Host != "db.internal" || db.User != "env" || db.Password != "from-secret" {
+			t.Errorf("%s: server = %+v, database = %+v", f.name, cfg.Server, db)
+		}
+		if cfg.Server.Host != "0.0.0.0" || db.Port != 5432 || db.Name != "myapp" {
+			t.Errorf("%s: lost defaults the file does not set: server = %+v, database = %+v", f.name, cfg.Server, db)
+		}
+	}
+}
+
+func TestLoadEnv(t *testing.T) {
+	cfg, err := load(env{
+		"PORT":                   " 9100 ",
+		"DB_DRIVER":              "sqlite",
+		"DB_PATH":                "/var/lib/placement.db",
+		"DB_CONN_MAX_LIFETIME":   "1h",
+		"CORS_ALLOWED_ORIGINS":   "https://a.example, ,https://b.example",
+		"ATTACHMENT_MAX_SIZE_MB": "25",
+	}.lookup)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Server.Port != 9100 || cfg.Database.Driver != DriverSQLite || cfg.Database.Path != "/var/lib/placement.db" || cfg.Database.ConnMaxLifetime.Hours() != 1 {
+		t.Errorf("unexpected config: %+v", cfg)
+	}
+	if want := []string{"https://a.example", "https://b.example"}; !reflect.DeepEqual(cfg.CORS.AllowedOrigins, want) {
+		t.Errorf("allowed origins = %v, want %v", cfg.CORS.AllowedOrigins, want)
+	}
+	if cfg.Attachments.MaxSize() != 25<<20 {
+		t.Errorf("max attachment size = %d", cfg.Attachments.MaxSize())
+	}
+}
+
+func TestLoadSecretFiles(t *testing.T) {
+	cases := []struct {
+		name     string
+		content  string
+		password string
+	}{
+		{"trailing newline", "s3cret\n", "s3cret"},
+		{"windows newline", "s3cret\r\n", "s3cret"},
+		{"inner whitespace kept", " s3 cret\n\n", " s3 cret"},
+	}
+	for _, c := range cases {
+		cfg, err := load(env{"DB_PASSWORD_FILE": writeFile(t, "db_password", c.content)}.lookup)
+		if err != nil {
+			t.Errorf("%s: %v", c.name, err)
+			continue
+		}
+		if cfg.Database.Password != c.password {
+			t.Errorf("%s: password = %q, want %q", c.name, cfg.Database.Password, c.password)
+		}
+	}
+
+	// Any key can come from a file, and is parsed like the variable.
+	cfg, err := load(env{"DB_PORT_FILE": writeFile(t, "db_port", "6543\n")}.lookup)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Database.Port != 6543 {
+		t.Errorf("port = %d, want 6543", cfg.Database.Port)
+	}
+
+	_, err = load(env{"DB_PASSWORD_FILE": filepath.Join(t.TempDir(), "missing")}.lookup)
+	if err == nil || !strings.Contains(err.Error(), "DB_PASSWORD_FILE: ") {
+		t.Errorf("missing secret: err = %v", err)
+	}
+}
+
+func TestLoadFileErrors(t *testing.T) {
+	cases := []struct {
+		name string
+		path string
+		want string
+	}{
+		{"missing", filepath.Join(t.TempDir(), "config.yaml"), "reading config file"},
+		{"unsupported extension", writeFile(t, "config.json", "{}"), "unsupported extension"},
+		{"malformed", writeFile(t, "config.yaml", "server: [port"), "parsing config file"},
+	}
+	for _, c := range cases {
+		_, err := load(env{"CONFIG_FILE": c.path}.lookup)
+		if err == nil || !strings.Contains(err.Error(), c.want) {
+			t.Errorf("%s: err = %v, want one containing %q", c.name, err, c.want)
+		}
+	}
+}
+
+func TestLoadCollectsErrors(t *testing.T) {
+	_, err := load(env{
+		"PORT":                       "80a",
+		"DB_PASSWORD":                "s3cret",
+		"DB_PASSWORD_FILE":           writeFile(t, "db_password", "s3cret"),
+		"DB_CONN_MAX_IDLE_TIME":      "5 minutes",
+		"DB_SSLMODE":                 "verify-full",
+		"DB_SSLCERT":                 "/certs/client.crt",
+		"DB_MAX_IDLE_CONNS":          "30",
+		"CORS_ALLOWED_ORIGINS":       "localhost:8081",
+		"FOLLOWUP_REMINDER_INTERVAL": "-1m",
+		"ATTACHMENT_STORAGE":         "s3",
+		"ATTACHMENT_MAX_SIZE_MB":     "0",
+	}.lookup)
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	want := []string{
+		`PORT must be an integer, got "80a"`,
+		"DB_PASSWORD and DB_PASSWORD_FILE are mutually exclusive",
+		`DB_CONN_MAX_IDLE_TIME must be a duration such as 30s or 5m, got "5 minutes"`,
+		"DB_SSLROOTCERT is required when DB_SSLMODE is verify-full",
+		"DB_SSLCERT and DB_SSLKEY must be set together",
+		"DB_MAX_IDLE_CONNS (30) must not exceed DB_MAX_OPEN_CONNS (25)",
+		`CORS_ALLOWED_ORIGINS entry "localhost:8081" must start with http:// or https://`,
+		"FOLLOWUP_REMINDER_INTERVAL must not be negative",
+		`ATTACHMENT_STORAGE must be one of local, got "s3"`,
+		"ATTACHMENT_MAX_SIZE_MB must be at least 1, got 0",
+	}
+	lines := strings.Split(err.Error(), "\n  ")
+	if lines[0] != "invalid configuration:" || !reflect.DeepEqual(lines[1:], want) {
+		t.Errorf("err =\n%v\nwant each of\n  %s", err, strings.Join(want, "\n  "))
+	}
+}
+
+func TestValidate(t *testing.T) {
+	cases := []struct {
+		name   string
+		modify func(*Config)
+		want   []string
+	}{
+		{"defaults", func(*Config) {}, nil},
+		{"sqlite ignores postgres settings", func(c *Config) {
+			c.Database.Driver = DriverSQLite
+			c.Database.Host, c.Database.User, c.Database.SSLMode = "", "", "on"
+		}, nil},
+		{"sqlite without a path", func(c *Config) {
+			c.Database.Driver, c.Database.Path = DriverSQLite, ""
+		}, []string{"DB_PATH is required when DB_DRIVER is sqlite"}},
+		{"unknown driver", func(c *Config) {
+			c.Database.Driver = "mysql"
+		}, []string{`DB_DRIVER must be one of postgres, sqlite, got "mysql"`}},
+		{"postgres", func(c *Config) {
+			c.Server.Port = 0
+			c.Database.Host, c.Database.Port, c.Database.User, c.Database.Name = "", 70000, "", ""
+			c.Database.SSLMode = "on"
+		}, []string{
+			"PORT must be between 1 and 65535, got 0",
+			"DB_HOST is required",
+			"DB_PORT must be between 1 and 65535, got 70000",
+			"DB_USER is required",
+			"DB_NAME is required",
+			`DB_SSLMODE must be one of disable, allow, prefer, require, verify-ca, verify-full, got "on"`,
+		}},
+		{"unlimited open connections", func(c *Config) {
+			c.Database.MaxOpenConns, c.Database.MaxIdleConns = 0, 50
+		}, nil},
+		{"negative settings", func(c *Config) {
+			c.Database.MaxOpenConns, c.Database.MaxIdleConns = -1, -1
+			c.Database.ConnMaxLifetime, c.Database.ConnMaxIdleTime = -1, -1
+			c.Database.ConnectAttempts = 0
+			c.Reminders.Lead, c.Trash.Retention, c.Trash.PurgeInterval = -1, -1, -1
+		}, []string{
+			"DB_MAX_OPEN_CONNS must not be negative, got -1",
+			"DB_MAX_IDLE_CONNS must not be negative, got -1",
+			"DB_CONN_MAX_LIFETIME must not be negative",
+			"DB_CONN_MAX_IDLE_TIME must not be negative",
+			"DB_CONNECT_ATTEMPTS must be at least 1, got 0",
+			"FOLLOWUP_REMINDER_LEAD must not be negative",
+			"TRASH_RETENTION must not be negative",
+			"TRASH_PURGE_INTERVAL must not be negative",
+		}},
+		{"local storage without a directory", func(c *Config) {
+			c.Attachments.Dir = ""
+		}, []string{"ATTACHMENT_DIR is required when ATTACHMENT_STORAGE is local"}},
+	}
+	for _, c := range cases {
+		cfg := Default()
+		c.modify(cfg)
+		err := cfg.Validate()
+		if c.want == nil {
+			if err != nil {
+				t.Errorf("%s: %v", c.name, err)
+			}
+			continue
+		}
+		if want := "invalid configuration:\n  " + strings.Join(c.want, "\n  "); err == nil || err.Error() != want {
+			t.Errorf("%s: err =\n%v\nwant\n%s", c.name, err, want)
+		}
+	}
+}
+
+func TestStringRedactsSecrets(t *testing.T) {
+	cfg, err := load(env{
+		"DB_PASSWORD_FILE": writeFile(t, "db_password", "hunter2\n"),
+		"DB_SSLCERT":       "/certs/client.crt",
+		"DB_SSLKEY":        "/certs/client.key",
+	}.lookup)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := cfg.String()
+	if strings.Contains(s, "hunter2") || strings.Contains(s, "client.key") {
+		t.Errorf("String() leaks a secret:\n%s", s)
+	}
+	for _, want := range []string{"database.password=********\n", "database.sslkey=********\n", "database.sslcert=/certs/client.crt\n", "server.addr=0.0.0.0:8080\n"} {
+		if !strings.Contains(s, want) {
+			t.Errorf("String() does not contain %q:\n%s", want, s)
+		}
+	}
+
+	cfg.Database.Password = ""
+	if !strings.Contains(cfg.String(), "database.password=\n") {
+		t.Errorf("an empty password is not shown as empty:\n%s", cfg.String())
+	}
+}
+
+func TestDSN(t *testing.T) {
+	postgres := Default().Database
+	cases := []struct {
+		name   string
+		modify func(*DatabaseConfig)
+		want   string
+	}{
+		{"defaults", func(*DatabaseConfig) {}, "host=localhost port=5432 user=myuser password=mypassword dbname=myapp sslmode=disable"},
+		{"quoted values", func(d *DatabaseConfig) {
+			d.User = "o'brien"
+			d.Password = `p@ss word\`
+			d.Name = ""
+		}, `host=localhost port=5432 user='o\'brien' password='p@ss word\\' dbname='' sslmode=disable`},
+		{"certificates", func(d *DatabaseConfig) {
+			d.SSLMode = "verify-full"
+			d.SSLRootCert, d.SSLCert, d.SSLKey = "/certs/root.crt", "/certs/client.crt", "/my certs/client.key"
+		}, "host=localhost port=5432 user=myuser password=mypassword dbname=myapp sslmode=verify-full sslrootcert=/certs/root.crt sslcert=/certs/client.crt sslkey='/my certs/client.key'"},
+		{"sqlite", func(d *DatabaseConfig) {
+			d.Driver, d.Path = DriverSQLite, "/var/lib/placement.db"
+		}, "file:/var/lib/placement.db?_foreign_keys=on&_busy_timeout=5000&_journal_mode=WAL"},
+	}
+	for _, c := range cases {
+		d := postgres
+		c.modify(&d)
+		if got := d.DSN(); got != c.want {
+			t.Errorf("%s: DSN = %s, want %s", c.name, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// envReader overlays environment variables onto a Config. Every key can also
+// be supplied as `<KEY>_FILE` pointing at a file holding the value, which is
+// how Docker and Kubernetes mount secrets. Parse errors are collected rather
+// than returned so that all of them can be reported together.
+type envReader struct {
+	lookup func(string) (string, bool)
+	errs   []error
+}
+
+func (e *envReader) value(key string) (string, bool) {
+	value, hasValue := e.lookup(key)
+	path, hasFile := e.lookup(key + "_FILE")
+	hasValue = hasValue && value != ""
+	hasFile = hasFile && path != ""
+
+	switch {
+	case hasValue && hasFile:
+		e.errs = append(e.errs, fmt.Errorf("%s and %s_FILE are mutually exclusive", key, key))
+		return "", false
+	case hasFile:
+		data, err := os.ReadFile(path)
+		if err != nil {
+			e.errs = append(e.errs, fmt.Errorf("%s_FILE: %w", key, err))
+			return "", false
+		}
+		return strings.TrimRight(string(data), "\r\n"), true
+	case hasValue:
+		return value, true
+	}
+	return "", false
+}
+
+func (e *envReader) str(key string, dst *string) {
+	if value, ok := e.value(key); ok {
+		*dst = value
+	}
+}
+
+func (e *envReader) int(key string, dst *int) {
+	value, ok := e.value(key)
+	if !ok {
+		return
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil {
+		e.errs = append(e.errs, fmt.Errorf("%s must be an integer, got %q", key, value))
+		return
+	}
+	*dst = n
+}
+
+func (e *envReader) duration(key string, dst *time.Duration) {
+	value, ok := e.value(key)
+	if !ok {
+		return
+	}
+	d, err := time.ParseDuration(strings.TrimSpace(value))
+	if err != nil {
+		e.errs = append(e.errs, fmt.Errorf("%s must be a duration such as 30s or 5m, got %q", key, value))
+		return
+	}
+	*dst = d
+}
+
+func (e *envReader) list(key string, dst *[]string) {
+	value, ok := e.value(key)
+	if !ok {
+		return
+	}
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	*dst = items
+}
@@ -1,5 +1,7 @@
 -- Create extension for UUID generation
 CREATE EXTENSION IF NOT EXISTS "uuid-ossp";
+-- Trigram matching for typo-tolerant company search
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
 
 -- Create users table if it doesn't exist
 CREATE TABLE IF NOT EXISTS users (
@@ -30,6 +32,7 @@ CREATE TABLE IF NOT EXISTS companies (
     hr2_details       TEXT,
     package           TEXT,
     assigned_officer  TEXT[] DEFAULT '{}',
+    version           INTEGER NOT NULL DEFAULT 1,
     created_at        TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
     updated_at        TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
 );
@@ -50,6 +53,7 @@ CREATE TABLE IF NOT EXISTS companies_temp (
     package           TEXT,
     assigned_officer  TEXT[] DEFAULT '{}',
     status            TEXT DEFAULT 'pending',
+    base_version      INTEGER,
     created_by        TEXT,
     created_at        TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
     updated_at        TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
@@ -65,15 +69,288 @@ CREATE TABLE IF NOT EXISTS events (
     created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
 );
 
+-- Structured company contacts, replacing the free-text HR fields
+CREATE TABLE IF NOT EXISTS contacts (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    company_id   UUID NOT NULL REFERENCES companies(id) ON DELETE CASCADE,
+    name         TEXT NOT NULL,
+    designation  TEXT NOT NULL DEFAULT '',
+    email        TEXT NOT NULL DEFAULT '',
+    phone        TEXT NOT NULL DEFAULT '',
+    linkedin     TEXT NOT NULL DEFAULT '',
+    is_primary   BOOLEAN NOT NULL DEFAULT false,
+    notes        TEXT NOT NULL DEFAULT '',
+    created_at   TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at   TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Dated follow-ups with a company, replacing the free-text follow_up field
+CREATE TABLE IF NOT EXISTS follow_ups (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    company_id   UUID NOT NULL REFERENCES companies(id) ON DELETE CASCADE,
+    officer      TEXT NOT NULL,
+    due_at       TIMESTAMP WITH TIME ZONE NOT NULL,
+    note         TEXT NOT NULL DEFAULT '',
+    status       TEXT NOT NULL DEFAULT 'open',
+    completed_by TEXT NOT NULL DEFAULT '',
+    completed_at TIMESTAMP WITH TIME ZONE,
+    reminded_at  TIMESTAMP WITH TIME ZONE,
+    created_at   TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at   TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Messages for one user, such as follow-up reminders
+CREATE TABLE IF NOT EXISTS notifications (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    recipient    TEXT NOT NULL,
+    follow_up_id UUID REFERENCES follow_ups(id) ON DELETE CASCADE,
+    company_id   UUID REFERENCES companies(id) ON DELETE CASCADE,
+    message      TEXT NOT NULL,
+    read         BOOLEAN NOT NULL DEFAULT false,
+    created_at   TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Append-only log of calls, emails, meetings and visits with a company
+CREATE TABLE IF NOT EXISTS interactions (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    company_id   UUID NOT NULL REFERENCES companies(id) ON DELETE CASCADE,
+    type         TEXT NOT NULL,
+    occurred_at  TIMESTAMP WITH TIME ZONE NOT NULL,
+    officer      TEXT NOT NULL,
+    outcome      TEXT NOT NULL DEFAULT '',
+    notes        TEXT NOT NULL DEFAULT '',
+    next_step    TEXT NOT NULL DEFAULT '',
+    created_at   TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Every version of a company: who made it, how, and the editable fields as
+-- they were (see entity.CompanySnapshot)
+CREATE TABLE IF NOT EXISTS company_history (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    company_id   UUID NOT NULL REFERENCES companies(id) ON DELETE CASCADE,
+    version      INTEGER NOT NULL,
+    changed_by   TEXT NOT NULL DEFAULT '',
+    source       TEXT NOT NULL,
+    proposal_id  TEXT NOT NULL DEFAULT '',
+    reverted_to  INTEGER NOT NULL DEFAULT 0,
+    merged_from  TEXT NOT NULL DEFAULT '',
+    snapshot     JSONB NOT NULL,
+    created_at   TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (company_id, version)
+);
+
+-- Data migrations already applied by the server (see companyd/repository/migrate.go)
+CREATE TABLE IF NOT EXISTS schema_migrations (
+    name TEXT PRIMARY KEY,
+    applied_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Optimistic concurrency columns for databases created before they existed
+ALTER TABLE companies ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE companies_temp ADD COLUMN IF NOT EXISTS base_version INTEGER;
+
+-- Structured compensation parsed from or rendered into the free-text
+-- package, which stays as the display string. package_amount is the annual
+-- CTC in lakhs (company.AnnualCTC), written by the server and used for range
+-- filters and sorting.
+ALTER TABLE companies
+    ADD COLUMN IF NOT EXISTS package_base NUMERIC,
+    ADD COLUMN IF NOT EXISTS package_variable NUMERIC,
+    ADD COLUMN IF NOT EXISTS package_stipend NUMERIC,
+    ADD COLUMN IF NOT EXISTS package_currency TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS package_unit TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS package_min NUMERIC,
+    ADD COLUMN IF NOT EXISTS package_max NUMERIC,
+    ADD COLUMN IF NOT EXISTS package_needs_review BOOLEAN NOT NULL DEFAULT false,
+    ADD COLUMN IF NOT EXISTS package_amount NUMERIC;
+
+-- The latest entry of each company's interaction timeline, kept in step by
+-- the server so that /company/list can filter and sort on it.
+ALTER TABLE companies
+    ADD COLUMN IF NOT EXISTS last_interaction_at TIMESTAMP WITH TIME ZONE,
+    ADD COLUMN IF NOT EXISTS last_interaction_outcome TEXT NOT NULL DEFAULT '';
+
+-- Archived companies have stopped recruiting; deleted ones are in the trash
+-- until the server purges them.
+ALTER TABLE companies
+    ADD COLUMN IF NOT EXISTS archived_at TIMESTAMP WITH TIME ZONE,
+    ADD COLUMN IF NOT EXISTS archived_by TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP WITH TIME ZONE,
+    ADD COLUMN IF NOT EXISTS deleted_by TEXT NOT NULL DEFAULT '';
+
+-- The officers assigned to each company, primary officer first. This
+-- replaces companies.assigned_officer, which migration 0006_assign_officers
+-- copies from and which is no longer written. Deleting a user unassigns them.
+CREATE TABLE IF NOT EXISTS company_officers (
+    company_id  UUID NOT NULL REFERENCES companies(id) ON DELETE CASCADE,
+    user_id     UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    role        TEXT NOT NULL CHECK (role IN ('primary', 'secondary')),
+    position    INTEGER NOT NULL,
+    assigned_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    assigned_by TEXT NOT NULL DEFAULT '',
+    PRIMARY KEY (company_id, user_id)
+);
+
+-- A company's recruitment drives. This replaces companies.drive and
+-- companies.type_of_drive, which migration 0007_import_drives copies from.
+CREATE TABLE IF NOT EXISTS drives (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    company_id  UUID NOT NULL REFERENCES companies(id) ON DELETE CASCADE,
+    type        TEXT NOT NULL CHECK (type IN ('on-campus', 'off-campus', 'pool', 'virtual')),
+    season      TEXT NOT NULL DEFAULT '',
+    starts_on   TIMESTAMP WITH TIME ZONE,
+    ends_on     TIMESTAMP WITH TIME ZONE,
+    roles       TEXT[] NOT NULL DEFAULT '{}',
+    package     TEXT NOT NULL DEFAULT '',
+    status      TEXT NOT NULL DEFAULT 'planned',
+    notes       TEXT NOT NULL DEFAULT '',
+    created_at  TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at  TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- A company's stage in the recruitment pipeline, which replaces
+-- is_contacted; is_contacted is kept in step for older clients. Every move
+-- between stages is recorded in company_status_transitions, starting with
+-- the stage the company was created at, whose from_status is empty.
+-- Migration 0008_record_pipeline_status starts existing companies off.
+ALTER TABLE companies ADD COLUMN IF NOT EXISTS pipeline_status TEXT NOT NULL DEFAULT 'prospect';
+
+CREATE TABLE IF NOT EXISTS company_status_transitions (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    company_id  UUID NOT NULL REFERENCES companies(id) ON DELETE CASCADE,
+    from_status TEXT NOT NULL DEFAULT '',
+    to_status   TEXT NOT NULL,
+    changed_by  TEXT NOT NULL DEFAULT '',
+    changed_at  TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Academic seasons such as 2026-27. Companies, proposals and events each
+-- belong to one; the active season is the one listings default to and new
+-- companies join. A company carried forward into a later season points at
+-- the one it was copied from. Migration 0009_assign_seasons files existing
+-- rows under a season.
+CREATE TABLE IF NOT EXISTS seasons (
+    name       TEXT PRIMARY KEY,
+    active     BOOLEAN NOT NULL DEFAULT false,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+ALTER TABLE companies
+    ADD COLUMN IF NOT EXISTS season TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS carried_from UUID REFERENCES companies(id) ON DELETE SET NULL;
+ALTER TABLE companies_temp ADD COLUMN IF NOT EXISTS season TEXT NOT NULL DEFAULT '';
+ALTER TABLE events ADD COLUMN IF NOT EXISTS season TEXT NOT NULL DEFAULT '';
+
+-- Company attributes defined by admins rather than by the schema. Each
+-- company keeps its values in companies.custom_fields, keyed by
+-- custom_fields.key, alongside free-form tags.
+CREATE TABLE IF NOT EXISTS custom_fields (
+    id         UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    key        TEXT NOT NULL UNIQUE,
+    label      TEXT NOT NULL,
+    type       TEXT NOT NULL,
+    options    TEXT[] NOT NULL DEFAULT '{}',
+    required   BOOLEAN NOT NULL DEFAULT false,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+ALTER TABLE companies
+    ADD COLUMN IF NOT EXISTS custom_fields JSONB NOT NULL DEFAULT '{}',
+    ADD COLUMN IF NOT EXISTS tags TEXT[] NOT NULL DEFAULT '{}';
+
+-- Documents kept with a company, such as job descriptions and MoUs. The
+-- contents live in the configured blob store under storage_key; purging a
+-- company deletes its rows here and the server removes the contents.
+CREATE TABLE IF NOT EXISTS attachments (
+    id           UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    company_id   UUID NOT NULL REFERENCES companies(id) ON DELETE CASCADE,
+    filename     TEXT NOT NULL,
+    content_type TEXT NOT NULL,
+    size         BIGINT NOT NULL,
+    checksum     TEXT NOT NULL,
+    storage_key  TEXT NOT NULL UNIQUE,
+    uploaded_by  TEXT NOT NULL DEFAULT '',
+    created_at   TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Weighted full-text document for /company/search: name (A), address (B),
+-- then remarks, contact and HR details (C).
+ALTER TABLE companies ADD COLUMN IF NOT EXISTS search_vector tsvector
+    GENERATED ALWAYS AS (
+        setweight(to_tsvector('english', coalesce(company_name, '')), 'A') ||
+        setweight(to_tsvector('english', coalesce(company_address, '')), 'B') ||
+        setweight(to_tsvector('english', coalesce(remarks, '') || ' ' || coalesce(contact_details, '') || ' ' || coalesce(hr1_details, '') || ' ' || coalesce(hr2_details, '')), 'C')
+    ) STORED;
+
 -- Create indexes for companies table
 CREATE INDEX IF NOT EXISTS idx_companies_name ON companies(company_name);
 CREATE INDEX IF NOT EXISTS idx_companies_drive ON companies(drive);
 CREATE INDEX IF NOT EXISTS idx_companies_is_contacted ON companies(is_contacted);
+CREATE INDEX IF NOT EXISTS idx_companies_pipeline_status ON companies(pipeline_status);
+CREATE INDEX IF NOT EXISTS idx_companies_type_of_drive ON companies(type_of_drive);
+CREATE INDEX IF NOT EXISTS idx_companies_package_amount ON companies(package_amount);
+CREATE INDEX IF NOT EXISTS idx_companies_package_needs_review ON companies(id) WHERE package_needs_review;
+CREATE INDEX IF NOT EXISTS idx_companies_created_at ON companies(created_at, id);
+CREATE INDEX IF NOT EXISTS idx_companies_updated_at ON companies(updated_at, id);
+CREATE INDEX IF NOT EXISTS idx_companies_last_interaction_at ON companies(last_interaction_at, id);
+CREATE INDEX IF NOT EXISTS idx_companies_deleted_at ON companies(deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_companies_search ON companies USING GIN (search_vector);
+-- Must match searchDocument in companyd/repository/search.go.
+CREATE INDEX IF NOT EXISTS idx_companies_search_trgm ON companies USING GIN ((coalesce(company_name, '') || ' ' || coalesce(company_address, '') || ' ' || coalesce(remarks, '') || ' ' || coalesce(contact_details, '') || ' ' || coalesce(hr1_details, '') || ' ' || coalesce(hr2_details, '')) gin_trgm_ops);
+
+-- Create indexes for company_officers: a company has at most one primary
+-- officer, and each officer's companies are looked up by user
+CREATE UNIQUE INDEX IF NOT EXISTS idx_company_officers_primary ON company_officers(company_id) WHERE role = 'primary';
+CREATE INDEX IF NOT EXISTS idx_company_officers_user_id ON company_officers(user_id);
+
+-- Create indexes for drives: each company's drives and each season's
+CREATE INDEX IF NOT EXISTS idx_drives_company_id ON drives(company_id, starts_on);
+CREATE INDEX IF NOT EXISTS idx_drives_season ON drives(season, starts_on);
+
+-- Create indexes for status transitions: each company's history and the
+-- transitions of a season
+CREATE INDEX IF NOT EXISTS idx_company_status_transitions_company_id ON company_status_transitions(company_id, changed_at);
+CREATE INDEX IF NOT EXISTS idx_company_status_transitions_changed_at ON company_status_transitions(changed_at);
+
+-- Create indexes for seasons: at most one is active, and each season's
+-- companies, proposals and events are looked up by season
+CREATE UNIQUE INDEX IF NOT EXISTS idx_seasons_active ON seasons(active) WHERE active;
+CREATE INDEX IF NOT EXISTS idx_companies_season ON companies(season, created_at);
+CREATE INDEX IF NOT EXISTS idx_companies_carried_from ON companies(carried_from);
+CREATE INDEX IF NOT EXISTS idx_companies_temp_season ON companies_temp(season);
+
+-- Create indexes for the custom field and tag filters of /company/list
+CREATE INDEX IF NOT EXISTS idx_companies_custom_fields ON companies USING GIN (custom_fields jsonb_path_ops);
+CREATE INDEX IF NOT EXISTS idx_companies_tags ON companies USING GIN (tags);
+
+-- Create indexes for attachments: each company's, newest first
+CREATE INDEX IF NOT EXISTS idx_attachments_company_id ON attachments(company_id, created_at DESC);
+
+-- Create indexes for contacts table; a company has at most one primary contact
+CREATE INDEX IF NOT EXISTS idx_contacts_company_id ON contacts(company_id);
+CREATE INDEX IF NOT EXISTS idx_contacts_email ON contacts(lower(email));
+CREATE UNIQUE INDEX IF NOT EXISTS idx_contacts_primary ON contacts(company_id) WHERE is_primary;
+
+-- Create indexes for follow-ups: the per-officer due views and the reminder scheduler
+CREATE INDEX IF NOT EXISTS idx_follow_ups_company_id ON follow_ups(company_id);
+CREATE INDEX IF NOT EXISTS idx_follow_ups_officer_due ON follow_ups(officer, due_at) WHERE status = 'open';
+CREATE INDEX IF NOT EXISTS idx_follow_ups_reminder ON follow_ups(due_at) WHERE status = 'open' AND reminded_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_notifications_recipient ON notifications(recipient, created_at);
+
+-- Create indexes for interactions: each company's timeline, newest first
+CREATE INDEX IF NOT EXISTS idx_interactions_company_occurred ON interactions(company_id, occurred_at DESC);
+CREATE INDEX IF NOT EXISTS idx_interactions_officer ON interactions(officer, occurred_at DESC);
 
 -- Create indexes for events table
 CREATE INDEX IF NOT EXISTS idx_events_date ON events(date);
 CREATE INDEX IF NOT EXISTS idx_events_type ON events(type);
 
+-- Events may be tied to a company; they outlive it
+ALTER TABLE events ADD COLUMN IF NOT EXISTS company_id UUID REFERENCES companies(id) ON DELETE SET NULL;
+CREATE INDEX IF NOT EXISTS idx_events_company_id ON events(company_id, date);
+CREATE INDEX IF NOT EXISTS idx_events_season ON events(season, date);
+
 -- Insert initial sample data
 INSERT INTO users (username, email, role, password, created_at) VALUES 
     ('admin', 'admin@company.com', 'Admin', 'password', '2024-01-01T00:00:00Z'),
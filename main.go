@@ -3,12 +3,19 @@ package main
 import (
 	companyHandler "backend/companyd/handler"
 	companyRepo "backend/companyd/repository"
+	"backend/companyd/repository/localfs"
+	companySQLite "backend/companyd/repository/sqlite"
 	"backend/companyd/usecase/company"
+	"backend/config"
 	userHandler "backend/userd/handler"
 	"backend/userd/repository"
+	userSQLite "backend/userd/repository/sqlite"
 	"backend/userd/usecase/user"
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"io/fs"
 	"log"
 	"net/http"
 	"os"
@@ -16,64 +23,154 @@ import (
 
 	"github.com/gorilla/mux"
 	_ "github.com/lib/pq"
+	_ "github.com/mattn/go-sqlite3"
 )
 
 func main() {
-	// Database connection
-	var err error
-	var db *sql.DB
-	dbHost := getEnv("DB_HOST", "localhost")
-	dbPort := getEnv("DB_PORT", "5432")
-	dbUser := getEnv("DB_USER", "myuser")
-	dbPassword := getEnv("DB_PASSWORD", "mypassword")
-	dbName := getEnv("DB_NAME", "myapp")
-
-	psqlInfo := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
-		dbHost, dbPort, dbUser, dbPassword, dbName)
-
-	// Retry connection with exponential backoff
-	for i := 0; i < 10; i++ {
-		db, err = sql.Open("postgres", psqlInfo)
-		if err != nil {
-			log.Printf("Failed to open database: %v", err)
-			time.Sleep(time.Duration(i) * time.Second)
-			continue
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("Effective configuration:\n%s", cfg)
+
+	db, err := openDatabase(cfg.Database)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	log.Printf("Successfully connected to %s!", cfg.Database.Driver)
+
+	var userdb user.Repository
+	var companydb company.Repository
+	switch cfg.Database.Driver {
+	case config.DriverSQLite:
+		if err := userSQLite.Migrate(db); err != nil {
+			log.Fatalf("Failed to migrate user tables: %v", err)
+		}
+		if err := companySQLite.Migrate(db); err != nil {
+			log.Fatalf("Failed to migrate company tables: %v", err)
 		}
+		userdb = userSQLite.NewRepository(db)
+		companydb = companySQLite.NewCompanyRepository(db)
+	default:
+		if err := applySchema(db, schemaFile); err != nil {
+			log.Fatalf("Failed to apply %s: %v", schemaFile, err)
+		}
+		if err := companyRepo.Migrate(db); err != nil {
+			log.Fatalf("Failed to migrate company tables: %v", err)
+		}
+		userdb = repository.NewRepository(db)
+		companydb = companyRepo.NewCompanyRepository(db)
+	}
 
-		err = db.Ping()
+	pipeline := company.DefaultPipeline()
+	if len(cfg.Pipeline.Stages) > 0 {
+		pipeline, err = company.NewPipeline(cfg.Pipeline.Stages, cfg.Pipeline.Exits, cfg.Pipeline.Transitions)
 		if err != nil {
-			log.Printf("Failed to connect to database: %v (attempt %d/10)", err, i+1)
-			time.Sleep(time.Duration(i) * time.Second)
-			continue
+			log.Fatal(err)
 		}
-		break
 	}
-
+	blobs, err := openBlobStore(cfg.Attachments)
 	if err != nil {
-		log.Fatal("Could not connect to database after 10 attempts")
+		log.Fatal(err)
+	}
+	companyService := company.NewServiceWithAttachments(companydb, pipeline, blobs, cfg.Attachments.MaxSize())
+	router := newRouter(user.NewService(userdb), companyService, cfg.CORS.AllowedOrigins)
+
+	// Notify officers of follow-ups falling due
+	if cfg.Reminders.Interval > 0 {
+		scheduler := company.NewReminderScheduler(companyService, cfg.Reminders.Interval, cfg.Reminders.Lead)
+		go scheduler.Run(context.Background())
+	}
+
+	// Purge companies that have been in the trash past the retention period
+	if cfg.Trash.PurgeInterval > 0 {
+		purger := company.NewPurgeScheduler(companyService, cfg.Trash.PurgeInterval, cfg.Trash.Retention)
+		go purger.Run(context.Background())
 	}
 
-	log.Println("Successfully connected to PostgreSQL!")
+	// Start server
+	serverAddr := cfg.Server.Addr()
+	log.Printf("Server starting on %s", serverAddr)
+	log.Fatal(http.ListenAndServe(serverAddr, router))
+}
 
+// newRouter mounts the user and company handlers on one router. Both add a
+// CORS middleware; the user one runs first and answers every preflight.
+func newRouter(userService user.Usecase, companyService company.Usecase, allowedOrigins []string) *mux.Router {
 	// Create a new Gorilla Mux router
 	router := mux.NewRouter()
 
 	// Add logging middleware
 	router.Use(loggingMiddleware)
 
-	userdb := repository.NewRepository(db)
 	// Register handlers with CORS middleware
-	userHandler.RegisterHandlers(user.NewService(userdb), router)
+	userHandler.RegisterHandlers(userService, router, allowedOrigins)
 
-	companydb := companyRepo.NewCompanyRepository(db)
 	// Register handlers with CORS middleware
-	companyHandler.RegisterHandlers(company.NewService(companydb), router)
+	companyHandler.RegisterHandlers(companyService, router, allowedOrigins)
+	return router
+}
 
-	// Start server
-	port := getEnv("PORT", "8080")
-	serverAddr := fmt.Sprintf("0.0.0.0:%s", port)
-	log.Printf("Server starting on %s", serverAddr)
-	log.Fatal(http.ListenAndServe(serverAddr, router))
+func openDatabase(cfg config.DatabaseConfig) (*sql.DB, error) {
+	driverName := "postgres"
+	if cfg.Driver == config.DriverSQLite {
+		driverName = "sqlite3"
+	}
+
+	db, err := sql.Open(driverName, cfg.DSN())
+	if err != nil {
+		return nil, err
+	}
+	db.SetMaxOpenConns(cfg.MaxOpenConns)
+	db.SetMaxIdleConns(cfg.MaxIdleConns)
+	db.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+	db.SetConnMaxIdleTime(cfg.ConnMaxIdleTime)
+
+	// Retry connection with linear backoff
+	for i := 0; i < cfg.ConnectAttempts; i++ {
+		err = db.Ping()
+		if err == nil {
+			return db, nil
+		}
+		log.Printf("Failed to connect to database: %v (attempt %d/%d)", err, i+1, cfg.ConnectAttempts)
+		time.Sleep(time.Duration(i) * time.Second)
+	}
+
+	db.Close()
+	return nil, fmt.Errorf("could not connect to database after %d attempts: %w", cfg.ConnectAttempts, err)
+}
+
+// openBlobStore opens the store attachment contents are kept in. Only the
+// local filesystem is built in; an S3-compatible store would be another
+// company.BlobStore chosen here.
+func openBlobStore(cfg config.AttachmentConfig) (company.BlobStore, error) {
+	switch cfg.Storage {
+	case config.StorageLocal:
+		return localfs.NewBlobStore(cfg.Dir)
+	default:
+		return nil, fmt.Errorf("unsupported attachment storage %q", cfg.Storage)
+	}
+}
+
+// schemaFile is the Postgres schema, copied next to the binary by
+// Dockerfile.golang. The database container only runs it on an empty volume,
+// so the server re-applies it to pick up tables and columns added since.
+const schemaFile = "init.sql"
+
+// applySchema runs the idempotent statements in path. A missing file is
+// skipped, for deployments that manage the schema themselves.
+func applySchema(db *sql.DB, path string) error {
+	ddl, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		log.Printf("%s not found, skipping schema update", path)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	_, err = db.Exec(string(ddl))
+	return err
 }
 
 func loggingMiddleware(next http.Handler) http.Handler {
@@ -82,10 +179,3 @@ func loggingMiddleware(next http.Handler) http.Handler {
 		next.ServeHTTP(w, r)
 	})
 }
-
-func getEnv(key, defaultValue string) string {
-	if value := os.Getenv(key); value != "" {
-		return value
-	}
-	return defaultValue
-}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	companyMemory "backend/companyd/repository/memory"
+	"backend/companyd/usecase/company"
+	userMemory "backend/userd/repository/memory"
+	"backend/userd/usecase/user"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const testOrigin = "http://localhost:8081"
+
+const testID = "00000000-0000-0000-0000-000000000042"
+
+func newTestRouter() http.Handler {
+	return newRouter(user.NewService(userMemory.NewRepository()), company.NewService(companyMemory.NewCompanyRepository()), []string{testOrigin})
+}
+
+// preflight sends the OPTIONS request a browser makes before method on path
+// with headers, and returns the response.
+func preflight(router http.Handler, method, path string, headers ...string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodOptions, path, nil)
+	req.Header.Set("Origin", testOrigin)
+	req.Header.Set("Access-Control-Request-Method", method)
+	req.Header.Set("Access-Control-Request-Headers", strings.Join(headers, ", "))
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec
+}
+
+// allows reports whether the comma-separated list in header of rec names
+// value, ignoring case as browsers do.
+func allows(rec *httptest.ResponseRecorder, header, value string) bool {
+	for _, item := range strings.Split(rec.Header().Get(header), ",") {
+		if strings.EqualFold(strings.TrimSpace(item), value) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestCORSPreflight(t *testing.T) {
+	router := newTestRouter()
+	cases := []struct {
+		method  string
+		path    string
+		headers []string
+	}{
+		{http.MethodPut, "/company/update/"+testID, []string{"Content-Type", "If-Match"}},
+		{http.MethodPatch, "/company/"+testID, []string{"Content-Type", "If-Match", "X-Username"}},
+		{http.MethodPut, "/company/"+testID+"/status", []string{"Content-Type", "X-Username", "X-User-Role"}},
+		{http.MethodGet, "/company/rebalance", []string{"X-Username", "X-User-Role"}},
+	}
+	for _, c := range cases {
+		rec := preflight(router, c.method, c.path, c.headers...)
+		if rec.Code != http.StatusOK || rec.Header().Get("Access-Control-Allow-Origin") != testOrigin {
+			t.Errorf("%s %s: status %d, origin %q", c.method, c.path, rec.Code, rec.Header().Get("Access-Control-Allow-Origin"))
+		}
+		if !allows(rec, "Access-Control-Allow-Methods", c.method) {
+			t.Errorf("%s %s: methods %q", c.method, c.path, rec.Header().Get("Access-Control-Allow-Methods"))
+		}
+		for _, header := range c.headers {
+			if !allows(rec, "Access-Control-Allow-Headers", header) {
+				t.Errorf("%s %s: headers %q leave out %s", c.method, c.path, rec.Header().Get("Access-Control-Allow-Headers"), header)
+			}
+		}
+	}
+}
+
+func TestCORSExposesETag(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/company/health", nil)
+	req.Header.Set("Origin", testOrigin)
+	rec := httptest.NewRecorder()
+	newTestRouter().ServeHTTP(rec, req)
+	if !allows(rec, "Access-Control-Expose-Headers", "ETag") {
+		t.Errorf("exposed headers %q leave out ETag", rec.Header().Get("Access-Control-Expose-Headers"))
+	}
+}
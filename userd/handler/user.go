@@ -6,45 +6,20 @@ import (
 	"encoding/json"
 	"log"
 	"net/http"
-	"os"
 	"regexp"
-	"strings"
 
 	"github.com/gorilla/mux"
 )
 
-// Helper function to get allowed origins from environment variable
-func getAllowedOrigins() []string {
-	// Default origins if environment variable is not set
-	defaultOrigins := []string{}
-
-	// Get from environment variable
-	envOrigins := os.Getenv("CORS_ALLOWED_ORIGINS")
-	if envOrigins == "" {
-		return defaultOrigins
-	}
-
-	// Split by comma and trim whitespace
-	origins := strings.Split(envOrigins, ",")
-	for i, origin := range origins {
-		origins[i] = strings.TrimSpace(origin)
-	}
-
-	return origins
-}
-
 // CORS middleware
-func enableCORS(next http.HandlerFunc) http.HandlerFunc {
+func enableCORS(allowedOrigins []string, next http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		log.Printf("Received request: %s %s", r.Method, r.URL.Path)
 
 		// Get the origin from the request
 		origin := r.Header.Get("Origin")
 
-		// List of allowed origins from environment variable
-		allowedOrigins := getAllowedOrigins()
-
-		// Check if the origin is in the allowed list
+		// Check if the origin is in the configured allowed list
 		for _, allowedOrigin := range allowedOrigins {
 			if origin == allowedOrigin {
 				w.Header().Set("Access-Control-Allow-Origin", origin)
@@ -57,8 +32,11 @@ func enableCORS(next http.HandlerFunc) http.HandlerFunc {
 			w.Header().Set("Access-Control-Allow-Origin", origin)
 		}
 
-		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Requested-With, ngrok-skip-browser-warning")
+		// This middleware is mounted first and answers the preflights of
+		// the company routes too, so these lists match companyd's.
+		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
+		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Requested-With, ngrok-skip-browser-warning, If-Match, X-Username, X-User-Role")
+		w.Header().Set("Access-Control-Expose-Headers", "ETag, X-Total-Count, X-Next-Cursor, Link, Content-Disposition")
 		w.Header().Set("Access-Control-Allow-Credentials", "true")
 		w.Header().Set("Access-Control-Max-Age", "3600")
 		w.Header().Set("Content-Type", "application/json")
@@ -254,11 +232,11 @@ func DeleteUser(service user.Usecase, w http.ResponseWriter, r *http.Request) {
 	})
 }
 
-func RegisterHandlers(service user.Usecase, router *mux.Router) {
+func RegisterHandlers(service user.Usecase, router *mux.Router, allowedOrigins []string) {
 	// Add CORS middleware to all routes
 	router.Use(func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			enableCORS(next.ServeHTTP)(w, r)
+			enableCORS(allowedOrigins, next.ServeHTTP)(w, r)
 		})
 	})
 
@@ -0,0 +1,184 @@
+package userHandler
+
+import (
+	"backend/userd/entity"
+	userPresenter "backend/userd/presenter"
+	"backend/userd/repository/memory"
+	"backend/userd/usecase/user"
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+func newTestRouter(t *testing.T) *mux.Router {
+	t.Helper()
+	router := mux.NewRouter()
+	RegisterHandlers(user.NewService(memory.NewRepository()), router, []string{"http://localhost:8081"})
+	return router
+}
+
+func doRequest(t *testing.T, router http.Handler, method, path string, body interface{}) *httptest.ResponseRecorder {
+	t.Helper()
+	var reader *bytes.Reader
+	switch b := body.(type) {
+	case nil:
+		reader = bytes.NewReader(nil)
+	case string:
+		reader = bytes.NewReader([]byte(b))
+	default:
+		data, err := json.Marshal(b)
+		if err != nil {
+			t.Fatalf("marshal request body: %v", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+	req := httptest.NewRequest(method, path, reader)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec
+}
+
+func decode(t *testing.T, rec *httptest.ResponseRecorder, v interface{}) {
+	t.Helper()
+	if err := json.Unmarshal(rec.Body.Bytes(), v); err != nil {
+		t.Fatalf("decode response %q: %v", rec.Body.String(), err)
+	}
+}
+
+func expectStatus(t *testing.T, rec *httptest.ResponseRecorder, status int) {
+	t.Helper()
+	if rec.Code != status {
+		t.Fatalf("status = %d, want %d; body: %s", rec.Code, status, rec.Body.String())
+	}
+}
+
+func createUser(t *testing.T, router http.Handler, username, role string) *entity.User {
+	t.Helper()
+	rec := doRequest(t, router, http.MethodPost, "/user/create", userPresenter.CreateRequest{
+		Username: username,
+		Password: "password",
+		Email:    username + "@company.com",
+		Role:     role,
+	})
+	expectStatus(t, rec, http.StatusOK)
+	var created entity.User
+	decode(t, rec, &created)
+	return &created
+}
+
+func TestUserHealth(t *testing.T) {
+	router := newTestRouter(t)
+	rec := doRequest(t, router, http.MethodGet, "/user/health", nil)
+	expectStatus(t, rec, http.StatusOK)
+
+	var body map[string]string
+	decode(t, rec, &body)
+	if body["status"] != "running" {
+		t.Errorf("status = %q, want running", body["status"])
+	}
+}
+
+func TestCORSReflectsUnknownOrigin(t *testing.T) {
+	router := newTestRouter(t)
+	req := httptest.NewRequest(http.MethodOptions, "/user/login", nil)
+	req.Header.Set("Origin", "https://other.example")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	expectStatus(t, rec, http.StatusOK)
+	if got := rec.Header().Get("Access-Control-Allow-Origin"); got != "https://other.example" {
+		t.Errorf("Access-Control-Allow-Origin = %q, want request origin", got)
+	}
+}
+
+func TestUserDBTest(t *testing.T) {
+	router := newTestRouter(t)
+	createUser(t, router, "admin", "Admin")
+
+	rec := doRequest(t, router, http.MethodGet, "/user/dbtest", nil)
+	expectStatus(t, rec, http.StatusOK)
+	var body struct {
+		UserCount int `json:"userCount"`
+	}
+	decode(t, rec, &body)
+	if body.UserCount != 1 {
+		t.Errorf("userCount = %d, want 1", body.UserCount)
+	}
+}
+
+func TestCreateUser(t *testing.T) {
+	router := newTestRouter(t)
+	created := createUser(t, router, "officer", "Officer")
+	if created.ID == "" || created.Username != "officer" || created.Role != "Officer" {
+		t.Errorf("unexpected user: %+v", created)
+	}
+	if created.Password != "" {
+		t.Error("password must not be returned")
+	}
+
+	rec := doRequest(t, router, http.MethodPost, "/user/create", userPresenter.CreateRequest{
+		Username: "officer", Password: "x", Email: "other@company.com", Role: "Officer",
+	})
+	expectStatus(t, rec, http.StatusInternalServerError)
+
+	rec = doRequest(t, router, http.MethodPost, "/user/create", "{")
+	expectStatus(t, rec, http.StatusBadRequest)
+}
+
+func TestUserLogin(t *testing.T) {
+	router := newTestRouter(t)
+	createUser(t, router, "manager", "Manager")
+
+	rec := doRequest(t, router, http.MethodPost, "/user/login", userPresenter.LoginRequest{Username: "manager", Password: "password"})
+	expectStatus(t, rec, http.StatusOK)
+	var loggedIn entity.User
+	decode(t, rec, &loggedIn)
+	if loggedIn.Username != "manager" || loggedIn.Role != "Manager" || loggedIn.Password != "" {
+		t.Errorf("unexpected login response: %+v", loggedIn)
+	}
+
+	rec = doRequest(t, router, http.MethodPost, "/user/login", userPresenter.LoginRequest{Username: "manager", Password: "wrong"})
+	expectStatus(t, rec, http.StatusUnauthorized)
+
+	rec = doRequest(t, router, http.MethodPost, "/user/login", userPresenter.LoginRequest{Username: "nobody", Password: "password"})
+	expectStatus(t, rec, http.StatusUnauthorized)
+
+	rec = doRequest(t, router, http.MethodPost, "/user/login", "{")
+	expectStatus(t, rec, http.StatusBadRequest)
+}
+
+func TestListUser(t *testing.T) {
+	router := newTestRouter(t)
+	createUser(t, router, "admin", "Admin")
+	createUser(t, router, "officer", "Officer")
+
+	rec := doRequest(t, router, http.MethodGet, "/user/list", nil)
+	expectStatus(t, rec, http.StatusOK)
+	var users []*entity.User
+	decode(t, rec, &users)
+	if len(users) != 2 {
+		t.Fatalf("expected 2 users, got %d", len(users))
+	}
+}
+
+func TestDeleteUser(t *testing.T) {
+	router := newTestRouter(t)
+	created := createUser(t, router, "officer", "Officer")
+
+	rec := doRequest(t, router, http.MethodDelete, "/user/delete/"+created.ID, nil)
+	expectStatus(t, rec, http.StatusOK)
+
+	rec = doRequest(t, router, http.MethodGet, "/user/list", nil)
+	var users []*entity.User
+	decode(t, rec, &users)
+	if len(users) != 0 {
+		t.Errorf("expected user to be deleted, still have %d", len(users))
+	}
+
+	rec = doRequest(t, router, http.MethodDelete, "/user/delete/not-a-uuid", nil)
+	expectStatus(t, rec, http.StatusBadRequest)
+}
@@ -0,0 +1,100 @@
+// Package contract holds the behaviour every user.Repository implementation
+// must share. Each backend runs it from its own tests.
+package contract
+
+import (
+	"backend/userd/usecase/user"
+	"testing"
+)
+
+// Run exercises repo-independent semantics against a fresh, empty repository
+// returned by newRepo for every subtest.
+func Run(t *testing.T, newRepo func(t *testing.T) user.Repository) {
+	tests := []struct {
+		name string
+		fn   func(t *testing.T, repo user.Repository)
+	}{
+		{"CreateAndGetUser", testCreateAndGetUser},
+		{"UniqueUsernameAndEmail", testUniqueUsernameAndEmail},
+		{"GetMissingUser", testGetMissingUser},
+		{"ListAndDeleteUsers", testListAndDeleteUsers},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fn(t, newRepo(t))
+		})
+	}
+}
+
+func testCreateAndGetUser(t *testing.T, repo user.Repository) {
+	created, err := repo.CreateUser("officer", "secret", "officer@company.com", "Officer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created.ID == "" || created.CreatedAt == "" {
+		t.Errorf("expected generated id and created_at, got %+v", created)
+	}
+	if created.Password != "" {
+		t.Error("CreateUser must not return the password")
+	}
+
+	found, err := repo.GetUserByUsername("officer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if found.ID != created.ID || found.Email != "officer@company.com" || found.Role != "Officer" || found.Password != "secret" {
+		t.Errorf("unexpected user: %+v", found)
+	}
+}
+
+func testUniqueUsernameAndEmail(t *testing.T, repo user.Repository) {
+	if _, err := repo.CreateUser("officer", "secret", "officer@company.com", "Officer"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.CreateUser("officer", "secret", "other@company.com", "Officer"); err == nil {
+		t.Error("expected duplicate username to be rejected")
+	}
+	if _, err := repo.CreateUser("other", "secret", "officer@company.com", "Officer"); err == nil {
+		t.Error("expected duplicate email to be rejected")
+	}
+}
+
+func testGetMissingUser(t *testing.T, repo user.Repository) {
+	if _, err := repo.GetUserByUsername("nobody"); err == nil {
+		t.Error("expected error for missing user")
+	}
+}
+
+func testListAndDeleteUsers(t *testing.T, repo user.Repository) {
+	first, err := repo.CreateUser("admin", "secret", "admin@company.com", "Admin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.CreateUser("manager", "secret", "manager@company.com", "Manager"); err != nil {
+		t.Fatal(err)
+	}
+
+	users, err := repo.ListUser()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(users) != 2 {
+		t.Fatalf("expected 2 users, got %d", len(users))
+	}
+	for _, u := range users {
+		if u.Password != "" {
+			t.Errorf("ListUser must not return passwords, got one for %s", u.Username)
+		}
+	}
+
+	if err := repo.DeleteUser(first.ID); err != nil {
+		t.Fatal(err)
+	}
+	users, err = repo.ListUser()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(users) != 1 || users[0].Username != "manager" {
+		t.Errorf("expected only manager to remain, got %+v", users)
+	}
+}
@@ -0,0 +1,94 @@
+package memory
+
+import (
+	"backend/userd/entity"
+	"backend/userd/usecase/user"
+	"database/sql"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Repository is an in-memory implementation of user.Repository with the same
+// uniqueness rules as the users table.
+type Repository struct {
+	mu    sync.Mutex
+	users []*entity.User
+	now   func() time.Time
+}
+
+var _ user.Repository = (*Repository)(nil)
+
+func NewRepository() *Repository {
+	return &Repository{now: time.Now}
+}
+
+func (r *Repository) CreateUser(username, password, email, role string) (*entity.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, user := range r.users {
+		if user.Username == username {
+			return nil, fmt.Errorf("duplicate key value violates unique constraint \"users_username_key\"")
+		}
+		if user.Email == email {
+			return nil, fmt.Errorf("duplicate key value violates unique constraint \"users_email_key\"")
+		}
+	}
+
+	user := &entity.User{
+		ID:        uuid.NewString(),
+		Username:  username,
+		Email:     email,
+		Role:      role,
+		Password:  password,
+		CreatedAt: r.now().UTC().Format(time.RFC3339Nano),
+	}
+	r.users = append(r.users, user)
+
+	// RETURNING does not include the password.
+	created := *user
+	created.Password = ""
+	return &created, nil
+}
+
+func (r *Repository) GetUserByUsername(username string) (*entity.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, user := range r.users {
+		if user.Username == username {
+			found := *user
+			return &found, nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (r *Repository) ListUser() ([]*entity.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var users []*entity.User
+	for _, user := range r.users {
+		listed := *user
+		listed.Password = ""
+		users = append(users, &listed)
+	}
+	return users, nil
+}
+
+func (r *Repository) DeleteUser(id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, user := range r.users {
+		if user.ID == id {
+			r.users = append(r.users[:i], r.users[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
@@ -0,0 +1,13 @@
+package memory
+
+import (
+	"backend/userd/repository/contract"
+	"backend/userd/usecase/user"
+	"testing"
+)
+
+func TestRepositoryContract(t *testing.T) {
+	contract.Run(t, func(t *testing.T) user.Repository {
+		return NewRepository()
+	})
+}
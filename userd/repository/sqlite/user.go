@@ -0,0 +1,115 @@
+package sqlite
+
+import (
+	"backend/userd/entity"
+	"backend/userd/usecase/user"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// schema mirrors the users table in init.sql.
+const schema = `
+CREATE TABLE IF NOT EXISTS users (
+    id         TEXT PRIMARY KEY,
+    username   VARCHAR(100) NOT NULL UNIQUE,
+    email      VARCHAR(100) NOT NULL UNIQUE,
+    role       VARCHAR(100) NOT NULL,
+    password   VARCHAR(100) NOT NULL,
+    created_at TEXT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_users_role ON users(role);
+`
+
+// seedUsers matches the sample users inserted by init.sql.
+var seedUsers = []struct{ username, email, role string }{
+	{"admin", "admin@company.com", "Admin"},
+	{"manager", "manager@company.com", "Manager"},
+	{"officer", "officer@company.com", "Officer"},
+}
+
+// Migrate creates the users table if it does not exist yet and inserts the
+// sample users.
+func Migrate(db *sql.DB) error {
+	if _, err := db.Exec(schema); err != nil {
+		return err
+	}
+	for _, seed := range seedUsers {
+		_, err := db.Exec(`
+			INSERT INTO users (id, username, email, role, password, created_at)
+			VALUES (?, ?, ?, ?, 'password', '2024-01-01T00:00:00Z')
+			ON CONFLICT (username) DO NOTHING`,
+			uuid.NewString(), seed.username, seed.email, seed.role)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Repository is the SQLite implementation of user.Repository.
+type Repository struct {
+	db *sql.DB
+}
+
+var _ user.Repository = (*Repository)(nil)
+
+func NewRepository(db *sql.DB) *Repository {
+	return &Repository{db: db}
+}
+
+func (r *Repository) CreateUser(username, password, email, role string) (*entity.User, error) {
+	query := `
+		INSERT INTO users (id, username, password, email, role, created_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+		RETURNING id, username, email, role, created_at`
+
+	now := time.Now().UTC().Format(time.RFC3339Nano)
+	row := r.db.QueryRow(query, uuid.NewString(), username, password, email, role, now)
+
+	var user entity.User
+	err := row.Scan(&user.ID, &user.Username, &user.Email, &user.Role, &user.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (r *Repository) GetUserByUsername(username string) (*entity.User, error) {
+	query := `
+		SELECT id, username, email, role, password, created_at
+		FROM users
+		WHERE username = ?`
+
+	var user entity.User
+	err := r.db.QueryRow(query, username).Scan(&user.ID, &user.Username, &user.Email, &user.Role, &user.Password, &user.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (r *Repository) ListUser() ([]*entity.User, error) {
+	rows, err := r.db.Query(`SELECT id, username, email, role, created_at FROM users`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var users []*entity.User
+	for rows.Next() {
+		var user entity.User
+		if err := rows.Scan(&user.ID, &user.Username, &user.Email, &user.Role, &user.CreatedAt); err != nil {
+			return nil, err
+		}
+		users = append(users, &user)
+	}
+	return users, rows.Err()
+}
+
+func (r *Repository) DeleteUser(id string) error {
+	_, err := r.db.Exec(`DELETE FROM users WHERE id = ?`, id)
+	return err
+}
@@ -0,0 +1,28 @@
+package sqlite
+
+import (
+	"backend/userd/repository/contract"
+	"backend/userd/usecase/user"
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func TestRepositoryContract(t *testing.T) {
+	contract.Run(t, func(t *testing.T) user.Repository {
+		db, err := sql.Open("sqlite3", "file:"+filepath.Join(t.TempDir(), "test.db"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { db.Close() })
+		if err := Migrate(db); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := db.Exec(`DELETE FROM users`); err != nil {
+			t.Fatal(err)
+		}
+		return NewRepository(db)
+	})
+}
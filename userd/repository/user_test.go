@@ -0,0 +1,37 @@
+package repository
+
+import (
+	"backend/userd/repository/contract"
+	"backend/userd/usecase/user"
+	"database/sql"
+	"os"
+	"testing"
+
+	_ "github.com/lib/pq"
+)
+
+func TestRepositoryContract(t *testing.T) {
+	dsn := os.Getenv("TEST_POSTGRES_DSN")
+	if dsn == "" {
+		t.Skip("TEST_POSTGRES_DSN not set")
+	}
+	contract.Run(t, func(t *testing.T) user.Repository {
+		db, err := sql.Open("postgres", dsn)
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { db.Close() })
+
+		schema, err := os.ReadFile("../../init.sql")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := db.Exec(string(schema)); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := db.Exec(`TRUNCATE users CASCADE`); err != nil {
+			t.Fatal(err)
+		}
+		return NewRepository(db)
+	})
+}